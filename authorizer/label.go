@@ -126,18 +126,61 @@ func (s *LabelService) FindLabels(ctx context.Context, filter influxdb.LabelFilt
 }
 
 // FindResourceLabels retrieves all labels belonging to the filtering resource if the authorizer on context has read access to it.
-// Then it filters the list down to only the labels that are authorized.
-func (s *LabelService) FindResourceLabels(ctx context.Context, filter influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+// Then it filters the list down to only the labels that are authorized. A page of labels is filled from the following pages of
+// the underlying service when some of its labels are not authorized.
+func (s *LabelService) FindResourceLabels(ctx context.Context, filter influxdb.LabelMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.Label, error) {
 	if err := authorizeLabelMappingAction(ctx, influxdb.ReadAction, filter.ResourceID, filter.ResourceType); err != nil {
 		return nil, err
 	}
 
-	ls, err := s.s.FindResourceLabels(ctx, filter)
-	if err != nil {
-		return nil, err
+	if len(opt) == 0 || opt[0].Limit <= 0 {
+		ls, err := s.s.FindResourceLabels(ctx, filter, opt...)
+		if err != nil {
+			return nil, err
+		}
+		return readableLabels(ctx, ls)
 	}
 
-	labels := ls[:0]
+	offset, limit := opt[0].Offset, opt[0].Limit
+	page := influxdb.FindOptions{Limit: limit, After: opt[0].After}
+	var labels []*influxdb.Label
+	for {
+		ls, err := s.s.FindResourceLabels(ctx, filter, page)
+		if err != nil {
+			return nil, err
+		}
+		if page.After != nil && len(ls) > 0 && ls[len(ls)-1].ID == *page.After {
+			// the service does not page after an ID, the page was read
+			return labels, nil
+		}
+
+		readable, err := readableLabels(ctx, ls)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range readable {
+			if offset > 0 {
+				offset--
+				continue
+			}
+			labels = append(labels, l)
+			if len(labels) == limit {
+				return labels, nil
+			}
+		}
+
+		if len(ls) < page.Limit {
+			return labels, nil
+		}
+
+		after := ls[len(ls)-1].ID
+		page.After = &after
+	}
+}
+
+// readableLabels returns the labels the authorizer on context can read.
+func readableLabels(ctx context.Context, ls []*influxdb.Label) ([]*influxdb.Label, error) {
+	labels := make([]*influxdb.Label, 0, len(ls))
 	for _, l := range ls {
 		err := authorizeReadLabel(ctx, l.OrgID, l.ID)
 		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
@@ -0,0 +1,98 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.MaintenanceWindowService = (*MaintenanceWindowService)(nil)
+
+// MaintenanceWindowService wraps an influxdb.MaintenanceWindowService and
+// authorizes actions against it appropriately. Maintenance windows are
+// authorized by their org, like the checks they mute.
+type MaintenanceWindowService struct {
+	s influxdb.MaintenanceWindowService
+}
+
+// NewMaintenanceWindowService constructs an instance of an authorizing
+// maintenance window service.
+func NewMaintenanceWindowService(s influxdb.MaintenanceWindowService) *MaintenanceWindowService {
+	return &MaintenanceWindowService{s: s}
+}
+
+// FindMaintenanceWindowByID checks to see if the authorizer on context has read
+// access to the org of the maintenance window.
+func (s *MaintenanceWindowService) FindMaintenanceWindowByID(ctx context.Context, id influxdb.ID) (*influxdb.MaintenanceWindow, error) {
+	w, err := s.s.FindMaintenanceWindowByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadOrg(ctx, w.OrgID); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// FindMaintenanceWindows retrieves all maintenance windows that match the
+// provided filter and then filters the list down to only the resources that
+// are authorized.
+func (s *MaintenanceWindowService) FindMaintenanceWindows(ctx context.Context, filter influxdb.MaintenanceWindowFilter) ([]*influxdb.MaintenanceWindow, error) {
+	ws, err := s.s.FindMaintenanceWindows(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	windows := ws[:0]
+	for _, w := range ws {
+		if err := authorizeReadOrg(ctx, w.OrgID); err == nil {
+			windows = append(windows, w)
+		}
+	}
+
+	return windows, nil
+}
+
+// CreateMaintenanceWindow checks to see if the authorizer on context has write
+// access to the org of the maintenance window.
+func (s *MaintenanceWindowService) CreateMaintenanceWindow(ctx context.Context, w *influxdb.MaintenanceWindow) error {
+	if err := authorizeWriteOrg(ctx, w.OrgID); err != nil {
+		return err
+	}
+
+	return s.s.CreateMaintenanceWindow(ctx, w)
+}
+
+// UpdateMaintenanceWindow checks to see if the authorizer on context has write
+// access to the org of the maintenance window.
+func (s *MaintenanceWindowService) UpdateMaintenanceWindow(ctx context.Context, id influxdb.ID, upd *influxdb.MaintenanceWindow) (*influxdb.MaintenanceWindow, error) {
+	w, err := s.s.FindMaintenanceWindowByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteOrg(ctx, w.OrgID); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateMaintenanceWindow(ctx, id, upd)
+}
+
+// DeleteMaintenanceWindow checks to see if the authorizer on context has write
+// access to the org of the maintenance window.
+func (s *MaintenanceWindowService) DeleteMaintenanceWindow(ctx context.Context, id influxdb.ID) error {
+	w, err := s.s.FindMaintenanceWindowByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteOrg(ctx, w.OrgID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteMaintenanceWindow(ctx, id)
+}
@@ -0,0 +1,136 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestMaintenanceWindowService_FindMaintenanceWindowByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		wantErr    error
+	}{
+		{
+			name: "authorized to access id",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.OrgsResourceType,
+					ID:   influxdbtesting.IDPtr(10),
+				},
+			},
+		},
+		{
+			name: "unauthorized to access id",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.OrgsResourceType,
+					ID:   influxdbtesting.IDPtr(2),
+				},
+			},
+			wantErr: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000a is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewMaintenanceWindowService()
+			m.FindMaintenanceWindowByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.MaintenanceWindow, error) {
+				return &influxdb.MaintenanceWindow{ID: id, OrgID: 10}, nil
+			}
+			s := authorizer.NewMaintenanceWindowService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			_, err := s.FindMaintenanceWindowByID(ctx, 1)
+			influxdbtesting.ErrorsEqual(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMaintenanceWindowService_FindMaintenanceWindows(t *testing.T) {
+	m := mock.NewMaintenanceWindowService()
+	m.FindMaintenanceWindowsFn = func(context.Context, influxdb.MaintenanceWindowFilter) ([]*influxdb.MaintenanceWindow, error) {
+		return []*influxdb.MaintenanceWindow{
+			{ID: 1, OrgID: 10},
+			{ID: 2, OrgID: 10},
+			{ID: 3, OrgID: 11},
+		}, nil
+	}
+	s := authorizer.NewMaintenanceWindowService(m)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{{
+		Action: "read",
+		Resource: influxdb.Resource{
+			Type: influxdb.OrgsResourceType,
+			ID:   influxdbtesting.IDPtr(10),
+		},
+	}}})
+
+	ws, err := s.FindMaintenanceWindows(ctx, influxdb.MaintenanceWindowFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ws) != 2 || ws[0].ID != 1 || ws[1].ID != 2 {
+		t.Errorf("unexpected maintenance windows: %+v", ws)
+	}
+}
+
+func TestMaintenanceWindowService_DeleteMaintenanceWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		wantErr    error
+	}{
+		{
+			name: "authorized to delete",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type: influxdb.OrgsResourceType,
+					ID:   influxdbtesting.IDPtr(10),
+				},
+			},
+		},
+		{
+			name: "unauthorized to delete",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.OrgsResourceType,
+					ID:   influxdbtesting.IDPtr(10),
+				},
+			},
+			wantErr: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewMaintenanceWindowService()
+			m.FindMaintenanceWindowByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.MaintenanceWindow, error) {
+				return &influxdb.MaintenanceWindow{ID: id, OrgID: 10}, nil
+			}
+			s := authorizer.NewMaintenanceWindowService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			err := s.DeleteMaintenanceWindow(ctx, 1)
+			influxdbtesting.ErrorsEqual(t, err, tt.wantErr)
+		})
+	}
+}
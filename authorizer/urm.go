@@ -52,17 +52,77 @@ func authorizeWriteURM(ctx context.Context, rt influxdb.ResourceType, orgID, id
 	return nil
 }
 
+// FindUserResourceMappings returns the mappings of the filter the authorizer on
+// context can read. A page of mappings is filled from the following pages of
+// the underlying service when some of its mappings cannot be read, the mappings
+// of a resource or of a user are paged after the last mapping of the page.
 func (s *URMService) FindUserResourceMappings(ctx context.Context, filter influxdb.UserResourceMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.UserResourceMapping, int, error) {
-	urms, _, err := s.s.FindUserResourceMappings(ctx, filter, opt...)
-	if err != nil {
-		return nil, 0, err
+	if len(opt) == 0 || opt[0].Limit <= 0 || (!filter.ResourceID.Valid() && !filter.UserID.Valid()) {
+		urms, _, err := s.s.FindUserResourceMappings(ctx, filter, opt...)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		mappings, err := s.readableURMs(ctx, urms)
+		if err != nil {
+			return nil, 0, err
+		}
+		return mappings, len(mappings), nil
 	}
 
-	mappings := urms[:0]
+	offset, limit := opt[0].Offset, opt[0].Limit
+	page := influxdb.FindOptions{Limit: limit, After: opt[0].After}
+	var mappings []*influxdb.UserResourceMapping
+	for {
+		urms, _, err := s.s.FindUserResourceMappings(ctx, filter, page)
+		if err != nil {
+			return nil, 0, err
+		}
+		if page.After != nil && len(urms) > 0 && urmAfter(filter, urms[len(urms)-1]) == *page.After {
+			// the service does not page after an ID, the page was read
+			return mappings, len(mappings), nil
+		}
+
+		readable, err := s.readableURMs(ctx, urms)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, urm := range readable {
+			if offset > 0 {
+				offset--
+				continue
+			}
+			mappings = append(mappings, urm)
+			if len(mappings) == limit {
+				return mappings, len(mappings), nil
+			}
+		}
+
+		if len(urms) < page.Limit {
+			return mappings, len(mappings), nil
+		}
+
+		after := urmAfter(filter, urms[len(urms)-1])
+		page.After = &after
+	}
+}
+
+// urmAfter returns the ID the mappings of the filter are paged after: the user
+// of the mapping for the mappings of a resource, or else its resource.
+func urmAfter(filter influxdb.UserResourceMappingFilter, m *influxdb.UserResourceMapping) influxdb.ID {
+	if filter.ResourceID.Valid() {
+		return m.UserID
+	}
+	return m.ResourceID
+}
+
+// readableURMs returns the mappings the authorizer on context can read.
+func (s *URMService) readableURMs(ctx context.Context, urms []*influxdb.UserResourceMapping) ([]*influxdb.UserResourceMapping, error) {
+	mappings := make([]*influxdb.UserResourceMapping, 0, len(urms))
 	for _, urm := range urms {
 		orgID, err := s.orgService.FindResourceOrganizationID(ctx, urm.ResourceType, urm.ResourceID)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 
 		if err := authorizeReadURM(ctx, urm.ResourceType, orgID, urm.ResourceID); err != nil {
@@ -72,7 +132,7 @@ func (s *URMService) FindUserResourceMappings(ctx context.Context, filter influx
 		mappings = append(mappings, urm)
 	}
 
-	return mappings, len(mappings), nil
+	return mappings, nil
 }
 
 func (s *URMService) CreateUserResourceMapping(ctx context.Context, m *influxdb.UserResourceMapping) error {
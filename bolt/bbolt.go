@@ -30,6 +30,10 @@ type Client struct {
 	IDGenerator    platform.IDGenerator
 	TokenGenerator platform.TokenGenerator
 	platform.TimeGenerator
+
+	// DetailedMetrics collects the stats of each boltdb bucket along with
+	// the totals.
+	DetailedMetrics bool
 }
 
 // NewClient returns an instance of a Client.
@@ -523,6 +523,10 @@ func (c *Client) updateBucket(ctx context.Context, tx *bolt.Tx, id platform.ID,
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.MeasurementRetentionPolicies != nil {
+		b.MeasurementRetentionPolicies = *upd.MeasurementRetentionPolicies
+	}
+
 	if upd.Description != nil {
 		b.Description = *upd.Description
 	}
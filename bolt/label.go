@@ -141,7 +141,7 @@ func decodeLabelMappingKey(key []byte) (resourceID influxdb.ID, labelID influxdb
 	return resourceID, labelID, nil
 }
 
-func (c *Client) FindResourceLabels(ctx context.Context, filter influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+func (c *Client) FindResourceLabels(ctx context.Context, filter influxdb.LabelMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.Label, error) {
 	if !filter.ResourceID.Valid() {
 		return nil, &influxdb.Error{Code: influxdb.EInvalid, Msg: "filter requires a valid resource id", Err: influxdb.ErrInvalidID}
 	}
@@ -52,6 +52,16 @@ var (
 		"boltdb_reads_total",
 		"Total number of boltdb reads",
 		nil, nil)
+
+	boltBucketKeysDesc = prometheus.NewDesc(
+		"boltdb_bucket_keys",
+		"Number of keys of each boltdb bucket, only collected with detailed metrics",
+		[]string{"bucket"}, nil)
+
+	boltBucketBytesDesc = prometheus.NewDesc(
+		"boltdb_bucket_bytes",
+		"Bytes in use by each boltdb bucket, only collected with detailed metrics",
+		[]string{"bucket"}, nil)
 )
 
 // Describe returns all descriptions of the collector.
@@ -65,6 +75,8 @@ func (c *Client) Describe(ch chan<- *prometheus.Desc) {
 	ch <- telegrafsDesc
 	ch <- boltWritesDesc
 	ch <- boltReadsDesc
+	ch <- boltBucketKeysDesc
+	ch <- boltBucketBytesDesc
 }
 
 // Collect returns the current state of all metrics of the collector.
@@ -139,4 +151,31 @@ func (c *Client) Collect(ch chan<- prometheus.Metric) {
 		prometheus.CounterValue,
 		float64(telegrafs),
 	)
+
+	if c.DetailedMetrics {
+		c.collectBuckets(ch)
+	}
+}
+
+// collectBuckets collects the stats of each top level boltdb bucket, there
+// is a series per bucket.
+func (c *Client) collectBuckets(ch chan<- prometheus.Metric) {
+	_ = c.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			stats := b.Stats()
+			ch <- prometheus.MustNewConstMetric(
+				boltBucketKeysDesc,
+				prometheus.GaugeValue,
+				float64(stats.KeyN),
+				string(name),
+			)
+			ch <- prometheus.MustNewConstMetric(
+				boltBucketBytesDesc,
+				prometheus.GaugeValue,
+				float64(stats.LeafInuse+stats.BranchInuse),
+				string(name),
+			)
+			return nil
+		})
+	})
 }
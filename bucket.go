@@ -2,6 +2,7 @@ package influxdb
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -40,9 +41,55 @@ type Bucket struct {
 	Description         string        `json:"description"`
 	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
 	RetentionPeriod     time.Duration `json:"retentionPeriod"`
+	// MeasurementRetentionPolicies expire individual measurements of the
+	// bucket before the bucket's own retention period has passed.
+	MeasurementRetentionPolicies []MeasurementRetentionPolicy `json:"measurementRetentionPolicies,omitempty"`
 	CRUDLog
 }
 
+// MeasurementRetentionPolicy expires the data of a single measurement within a
+// bucket once it is older than the policy's retention period.
+type MeasurementRetentionPolicy struct {
+	Measurement     string        `json:"measurement"`
+	RetentionPeriod time.Duration `json:"retentionPeriod"`
+}
+
+// ValidMeasurementRetentionPolicies validates the measurement retention policies
+// of the bucket. Each measurement may have a single policy, whose retention period
+// is at least one second and no longer than the retention period of the bucket.
+func (b *Bucket) ValidMeasurementRetentionPolicies() error {
+	seen := make(map[string]bool, len(b.MeasurementRetentionPolicies))
+	for _, p := range b.MeasurementRetentionPolicies {
+		if p.Measurement == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "measurement retention policy requires a measurement",
+			}
+		}
+		if seen[p.Measurement] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("measurement %q has more than one retention policy", p.Measurement),
+			}
+		}
+		seen[p.Measurement] = true
+
+		if p.RetentionPeriod < time.Second {
+			return &Error{
+				Code: EUnprocessableEntity,
+				Msg:  fmt.Sprintf("retention period of measurement %q must be greater than or equal to one second", p.Measurement),
+			}
+		}
+		if b.RetentionPeriod != InfiniteRetention && p.RetentionPeriod > b.RetentionPeriod {
+			return &Error{
+				Code: EUnprocessableEntity,
+				Msg:  fmt.Sprintf("retention period of measurement %q must not exceed the bucket retention period", p.Measurement),
+			}
+		}
+	}
+	return nil
+}
+
 // BucketType differentiates system buckets from user buckets.
 type BucketType int
 
@@ -102,6 +149,8 @@ type BucketUpdate struct {
 	Name            *string        `json:"name,omitempty"`
 	Description     *string        `json:"description,omitempty"`
 	RetentionPeriod *time.Duration `json:"retentionPeriod,omitempty"`
+
+	MeasurementRetentionPolicies *[]MeasurementRetentionPolicy `json:"measurementRetentionPolicies,omitempty"`
 }
 
 // BucketFilter represents a set of filter that restrict the returned results.
@@ -49,13 +49,15 @@ type cmdPkgBuilder struct {
 		force string
 	}
 	exportOpts struct {
-		resourceType string
-		buckets      string
-		dashboards   string
-		endpoints    string
-		labels       string
-		telegrafs    string
-		variables    string
+		resourceType  string
+		buckets       string
+		dashboards    string
+		endpoints     string
+		labels        string
+		scrapers      string
+		telegrafs     string
+		variables     string
+		formatQueries bool
 	}
 }
 
@@ -227,8 +229,10 @@ func (b *cmdPkgBuilder) cmdPkgExport() *cobra.Command {
 	cmd.Flags().StringVar(&b.exportOpts.dashboards, "dashboards", "", "List of dashboard ids comma separated")
 	cmd.Flags().StringVar(&b.exportOpts.endpoints, "endpoints", "", "List of notification endpoint ids comma separated")
 	cmd.Flags().StringVar(&b.exportOpts.labels, "labels", "", "List of label ids comma separated")
+	cmd.Flags().StringVar(&b.exportOpts.scrapers, "scraper-targets", "", "List of scraper target ids comma separated")
 	cmd.Flags().StringVar(&b.exportOpts.telegrafs, "telegraf-configs", "", "List of telegraf config ids comma separated")
 	cmd.Flags().StringVar(&b.exportOpts.variables, "variables", "", "List of variable ids comma separated")
+	cmd.Flags().BoolVar(&b.exportOpts.formatQueries, "format-queries", false, "Format the flux queries of exported dashboards")
 
 	cmd.RunE = b.pkgExportRunEFn()
 
@@ -243,6 +247,9 @@ func (b *cmdPkgBuilder) pkgExportRunEFn() func(*cobra.Command, []string) error {
 		}
 
 		opts := []pkger.CreatePkgSetFn{pkger.CreateWithMetadata(b.meta)}
+		if b.exportOpts.formatQueries {
+			opts = append(opts, pkger.CreateWithFormattedQueries())
+		}
 
 		resTypes := []struct {
 			kind   pkger.Kind
@@ -252,6 +259,7 @@ func (b *cmdPkgBuilder) pkgExportRunEFn() func(*cobra.Command, []string) error {
 			{kind: pkger.KindDashboard, idStrs: strings.Split(b.exportOpts.dashboards, ",")},
 			{kind: pkger.KindNotificationEndpoint, idStrs: strings.Split(b.exportOpts.endpoints, ",")},
 			{kind: pkger.KindLabel, idStrs: strings.Split(b.exportOpts.labels, ",")},
+			{kind: pkger.KindScraperTarget, idStrs: strings.Split(b.exportOpts.scrapers, ",")},
 			{kind: pkger.KindTelegraf, idStrs: strings.Split(b.exportOpts.telegrafs, ",")},
 			{kind: pkger.KindVariable, idStrs: strings.Split(b.exportOpts.variables, ",")},
 		}
@@ -299,6 +307,7 @@ func (b *cmdPkgBuilder) cmdPkgExportAll() *cobra.Command {
 	cmd.Flags().StringVarP(&b.meta.Name, "name", "n", "", "name for new pkg")
 	cmd.Flags().StringVarP(&b.meta.Description, "description", "d", "", "description for new pkg")
 	cmd.Flags().StringVarP(&b.meta.Version, "version", "v", "", "version for new pkg")
+	cmd.Flags().BoolVar(&b.exportOpts.formatQueries, "format-queries", false, "Format the flux queries of exported dashboards")
 
 	cmd.RunE = b.pkgExportAllRunEFn()
 
@@ -313,6 +322,9 @@ func (b *cmdPkgBuilder) pkgExportAllRunEFn() func(*cobra.Command, []string) erro
 		}
 
 		opts := []pkger.CreatePkgSetFn{pkger.CreateWithMetadata(b.meta)}
+		if b.exportOpts.formatQueries {
+			opts = append(opts, pkger.CreateWithFormattedQueries())
+		}
 
 		orgID, err := b.org.getID(orgSVC)
 		if err != nil {
@@ -649,6 +661,42 @@ func (b *cmdPkgBuilder) printPkgDiff(diff pkger.Diff) {
 		})
 	}
 
+	if scrapers := diff.ScraperTargets; len(scrapers) > 0 {
+		headers := []string{"New", "ID", "Name", "Type", "URL", "Bucket"}
+		tablePrintFn("SCRAPER TARGETS", headers, len(scrapers), func(i int) []string {
+			st := scrapers[i]
+			var old pkger.DiffScraperTargetValues
+			if st.Old != nil {
+				old = *st.Old
+			}
+			return []string{
+				boolDiff(st.IsNew()),
+				st.ID.String(),
+				st.Name,
+				diffLn(st.IsNew(), string(old.Type), string(st.New.Type)),
+				diffLn(st.IsNew(), old.URL, st.New.URL),
+				diffLn(st.IsNew(), old.Bucket, st.New.Bucket),
+			}
+		})
+	}
+
+	if tasks := diff.Tasks; len(tasks) > 0 {
+		headers := []string{"New", "Name", "Description", "Schedule"}
+		tablePrintFn("TASKS", headers, len(tasks), func(i int) []string {
+			t := tasks[i]
+			schedule := t.New.Every
+			if t.New.Cron != "" {
+				schedule = t.New.Cron
+			}
+			return []string{
+				boolDiff(true),
+				t.Name,
+				green(t.New.Description),
+				green(schedule),
+			}
+		})
+	}
+
 	if teles := diff.Telegrafs; len(diff.Telegrafs) > 0 {
 		headers := []string{"New", "Name", "Description"}
 		tablePrintFn("TELEGRAF CONFIGS", headers, len(teles), func(i int) []string {
@@ -661,6 +709,32 @@ func (b *cmdPkgBuilder) printPkgDiff(diff pkger.Diff) {
 		})
 	}
 
+	if len(diff.Conflicts) > 0 {
+		headers := []string{"Kind", "Name", "Reason"}
+		tablePrintFn("CONFLICTS", headers, len(diff.Conflicts), func(i int) []string {
+			c := diff.Conflicts[i]
+			return []string{
+				red(c.Kind.String()),
+				c.Name,
+				c.Reason,
+			}
+		})
+	}
+
+	if len(diff.Warnings) > 0 {
+		headers := []string{"Kind", "Name", "Chart", "Bucket", "Warning"}
+		tablePrintFn("WARNINGS", headers, len(diff.Warnings), func(i int) []string {
+			w := diff.Warnings[i]
+			return []string{
+				w.Kind.String(),
+				w.Name,
+				w.Chart,
+				w.Bucket,
+				w.Message,
+			}
+		})
+	}
+
 	if len(diff.LabelMappings) > 0 {
 		headers := []string{"New", "Resource Type", "Resource Name", "Resource ID", "Label Name", "Label ID"}
 		tablePrintFn("LABEL MAPPINGS", headers, len(diff.LabelMappings), func(i int) []string {
@@ -741,6 +815,38 @@ func (b *cmdPkgBuilder) printPkgSummary(sum pkger.Summary) {
 		})
 	}
 
+	if scrapers := sum.ScraperTargets; len(scrapers) > 0 {
+		headers := []string{"ID", "Name", "Type", "URL", "Bucket"}
+		tablePrintFn("SCRAPER TARGETS", headers, len(scrapers), func(i int) []string {
+			st := scrapers[i]
+			return []string{
+				st.ID.String(),
+				st.Name,
+				string(st.Type),
+				st.URL,
+				st.Bucket,
+			}
+		})
+	}
+
+	if tasks := sum.Tasks; len(tasks) > 0 {
+		headers := []string{"ID", "Name", "Description", "Schedule", "Status"}
+		tablePrintFn("TASKS", headers, len(tasks), func(i int) []string {
+			t := tasks[i]
+			schedule := t.Every
+			if t.Cron != "" {
+				schedule = t.Cron
+			}
+			return []string{
+				t.ID.String(),
+				t.Name,
+				t.Description,
+				schedule,
+				string(t.Status),
+			}
+		})
+	}
+
 	if teles := sum.TelegrafConfigs; len(teles) > 0 {
 		headers := []string{"ID", "Name", "Description"}
 		tablePrintFn("TELEGRAF CONFIGS", headers, len(teles), func(i int) []string {
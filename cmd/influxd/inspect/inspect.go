@@ -24,6 +24,9 @@ func NewCommand() *cobra.Command {
 		NewVerifySeriesFileCommand(),
 		NewDumpWALCommand(),
 		NewDumpTSICommand(),
+		NewMigrateCQCommand(),
+		NewVerifyBucketsCommand(),
+		NewKVCommand(),
 	}
 
 	base.AddCommand(subCommands...)
@@ -0,0 +1,267 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/spf13/cobra"
+)
+
+// kvOpenTimeout is how long the bolt file lock is waited on before the file is
+// reported as in use.
+const kvOpenTimeout = time.Second
+
+var kvFlags = struct {
+	// Standard input/output, overridden for testing.
+	Stdin  io.Reader
+	Stdout io.Writer
+
+	boltPath string
+	bucket   string
+	key      string
+	hex      bool
+
+	value       string
+	write       bool
+	backupFirst bool
+}{
+	Stdin:  os.Stdin,
+	Stdout: os.Stdout,
+}
+
+// NewKVCommand returns a new instance of the kv command.
+func NewKVCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kv",
+		Short: "Read and repair the documents of the bolt store",
+		Long: `
+These commands read, write and delete the values of the bolt store by bucket
+and key, for the repair of a resource the server cannot read or remove, i.e.
+a dashboard with corrupted JSON. Values that are JSON are printed indented.
+
+The bolt store is only opened for writes with --write, --backup-first copies
+it aside before it is written to.
+
+influxd must not be running while these commands are run, a bolt store locked
+by a running server is refused.`,
+		Args: cobra.NoArgs,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(err)
+	}
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&kvFlags.boltPath, "bolt-path", filepath.Join(dir, "influxd.bolt"), "Path to the bolt store")
+	flags.StringVar(&kvFlags.bucket, "bucket", "", "Name of the bolt bucket of the key (required)")
+	flags.StringVar(&kvFlags.key, "key", "", "The key of the value (required)")
+	flags.BoolVar(&kvFlags.hex, "hex", false, "Decode --key as hex, for keys that are not printable")
+
+	get := &cobra.Command{
+		Use:   "get",
+		Short: "Print the value of a key",
+		Args:  cobra.NoArgs,
+		RunE:  runKVGet,
+	}
+
+	put := &cobra.Command{
+		Use:   "put",
+		Short: "Write the value of a key",
+		Long: `
+Writes the value of --value, or of stdin when it is not provided, to the key.
+A value that is JSON is written compacted, so the indented output of get can
+be edited and written back as is.`,
+		Args: cobra.NoArgs,
+		RunE: runKVPut,
+	}
+	put.Flags().StringVar(&kvFlags.value, "value", "", "The value to write, read from stdin when not provided")
+
+	del := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a key",
+		Args:  cobra.NoArgs,
+		RunE:  runKVDelete,
+	}
+
+	for _, c := range []*cobra.Command{put, del} {
+		c.Flags().BoolVar(&kvFlags.write, "write", false, "Open the bolt store for writes, it is read only otherwise")
+		c.Flags().BoolVar(&kvFlags.backupFirst, "backup-first", false, "Copy the bolt store aside before it is written to")
+	}
+
+	cmd.AddCommand(get, put, del)
+	return cmd
+}
+
+func runKVGet(cmd *cobra.Command, args []string) error {
+	key, err := kvKey()
+	if err != nil {
+		return err
+	}
+
+	db, err := openKV(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var value []byte
+	err = db.View(func(tx *bolt.Tx) error {
+		b, err := kvBucket(tx)
+		if err != nil {
+			return err
+		}
+		v := b.Get(key)
+		if v == nil {
+			return kvKeyNotFoundErr()
+		}
+		// the value is only valid for the life of the tx.
+		value = append([]byte(nil), v...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if json.Valid(value) {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, value, "", "  "); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		value = buf.Bytes()
+	}
+	_, err = kvFlags.Stdout.Write(value)
+	return err
+}
+
+func runKVPut(cmd *cobra.Command, args []string) error {
+	key, err := kvKey()
+	if err != nil {
+		return err
+	}
+
+	value := []byte(kvFlags.value)
+	if kvFlags.value == "" {
+		if value, err = ioutil.ReadAll(kvFlags.Stdin); err != nil {
+			return err
+		}
+	}
+	if json.Valid(value) {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, value); err != nil {
+			return err
+		}
+		value = buf.Bytes()
+	}
+
+	return updateKV(func(tx *bolt.Tx) error {
+		b, err := kvBucket(tx)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, value)
+	})
+}
+
+func runKVDelete(cmd *cobra.Command, args []string) error {
+	key, err := kvKey()
+	if err != nil {
+		return err
+	}
+
+	return updateKV(func(tx *bolt.Tx) error {
+		b, err := kvBucket(tx)
+		if err != nil {
+			return err
+		}
+		if b.Get(key) == nil {
+			return kvKeyNotFoundErr()
+		}
+		return b.Delete(key)
+	})
+}
+
+// kvKey returns the key of the flags.
+func kvKey() ([]byte, error) {
+	if kvFlags.bucket == "" {
+		return nil, errors.New("--bucket is required")
+	}
+	if kvFlags.key == "" {
+		return nil, errors.New("--key is required")
+	}
+	if !kvFlags.hex {
+		return []byte(kvFlags.key), nil
+	}
+	key, err := hex.DecodeString(kvFlags.key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex key %q: %v", kvFlags.key, err)
+	}
+	return key, nil
+}
+
+func kvBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	b := tx.Bucket([]byte(kvFlags.bucket))
+	if b == nil {
+		return nil, fmt.Errorf("bucket %q not found", kvFlags.bucket)
+	}
+	return b, nil
+}
+
+func kvKeyNotFoundErr() error {
+	return fmt.Errorf("key %q not found in bucket %q", kvFlags.key, kvFlags.bucket)
+}
+
+// openKV opens the bolt store, it is refused when a running server holds its
+// lock.
+func openKV(readOnly bool) (*bolt.DB, error) {
+	if _, err := os.Stat(kvFlags.boltPath); err != nil {
+		return nil, fmt.Errorf("bolt store: %v", err)
+	}
+	db, err := bolt.Open(kvFlags.boltPath, 0600, &bolt.Options{
+		Timeout:  kvOpenTimeout,
+		ReadOnly: readOnly,
+	})
+	if err == bolt.ErrTimeout {
+		return nil, fmt.Errorf("bolt store %s is locked, influxd must be stopped first", kvFlags.boltPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to open bolt store: %v", err)
+	}
+	return db, nil
+}
+
+// updateKV writes to the bolt store with fn, after copying it aside with
+// --backup-first.
+func updateKV(fn func(tx *bolt.Tx) error) error {
+	if !kvFlags.write {
+		return errors.New("the bolt store is opened read only, --write is required to modify it")
+	}
+
+	db, err := openKV(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if kvFlags.backupFirst {
+		path := kvFlags.boltPath + "." + time.Now().UTC().Format("20060102T150405Z") + ".bak"
+		if err := db.View(func(tx *bolt.Tx) error {
+			return tx.CopyFile(path, 0600)
+		}); err != nil {
+			return fmt.Errorf("unable to back up bolt store: %v", err)
+		}
+		fmt.Fprintf(kvFlags.Stdout, "Backed up bolt store to %s\n", path)
+	}
+
+	return db.Update(fn)
+}
@@ -0,0 +1,250 @@
+package inspect
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bolt "github.com/coreos/bbolt"
+)
+
+const (
+	kvTestBucket = "dashboardsv2"
+	kvTestKey    = "0000000000000001"
+	kvTestValue  = `{"id":"0000000000000001","name":"dash_1"}`
+)
+
+// newKVTestFile returns the path of a bolt store holding the test value and
+// a value of a binary key.
+func newKVTestFile(t *testing.T) (string, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "influxd-inspect-kv-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "influxd.bolt")
+
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucket([]byte(kvTestBucket))
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte(kvTestKey), []byte(kvTestValue)); err != nil {
+			return err
+		}
+		return b.Put([]byte{0x00, 0xff}, []byte("binary"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return path, func() { os.RemoveAll(dir) }
+}
+
+// runKV runs the kv command with args, returning its output.
+func runKV(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	cmd := NewKVCommand()
+	cmd.SetArgs(args)
+	cmd.SetOutput(ioutil.Discard)
+
+	var stdout bytes.Buffer
+	kvFlags.Stdin = strings.NewReader(stdin)
+	kvFlags.Stdout = &stdout
+	defer func() {
+		kvFlags.Stdin = os.Stdin
+		kvFlags.Stdout = os.Stdout
+	}()
+
+	err := cmd.Execute()
+	return stdout.String(), err
+}
+
+func TestKV_Get(t *testing.T) {
+	path, cleanup := newKVTestFile(t)
+	defer cleanup()
+
+	t.Run("json is indented", func(t *testing.T) {
+		out, err := runKV(t, "", "get", "--bolt-path", path, "--bucket", kvTestBucket, "--key", kvTestKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp := "{\n  \"id\": \"0000000000000001\",\n  \"name\": \"dash_1\"\n}\n"
+		if out != exp {
+			t.Fatalf("got %q, exp %q", out, exp)
+		}
+	})
+
+	t.Run("hex key", func(t *testing.T) {
+		out, err := runKV(t, "", "get", "--bolt-path", path, "--bucket", kvTestBucket, "--key", "00ff", "--hex")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != "binary" {
+			t.Fatalf("got %q, exp %q", out, "binary")
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, err := runKV(t, "", "get", "--bolt-path", path, "--bucket", kvTestBucket, "--key", "missing")
+		if err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Fatalf("got error %v, exp key not found", err)
+		}
+	})
+
+	t.Run("missing bucket", func(t *testing.T) {
+		_, err := runKV(t, "", "get", "--bolt-path", path, "--bucket", "missing", "--key", kvTestKey)
+		if err == nil || !strings.Contains(err.Error(), `bucket "missing" not found`) {
+			t.Fatalf("got error %v, exp bucket not found", err)
+		}
+	})
+
+	t.Run("locked by a running server", func(t *testing.T) {
+		db, err := bolt.Open(path, 0600, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		_, err = runKV(t, "", "get", "--bolt-path", path, "--bucket", kvTestBucket, "--key", kvTestKey)
+		if err == nil || !strings.Contains(err.Error(), "is locked") {
+			t.Fatalf("got error %v, exp the bolt store to be locked", err)
+		}
+	})
+}
+
+func TestKV_PutDelete(t *testing.T) {
+	path, cleanup := newKVTestFile(t)
+	defer cleanup()
+
+	get := func(t *testing.T) (string, error) {
+		t.Helper()
+		return runKV(t, "", "get", "--bolt-path", path, "--bucket", kvTestBucket, "--key", kvTestKey)
+	}
+
+	t.Run("read only without write", func(t *testing.T) {
+		_, err := runKV(t, "", "put", "--bolt-path", path, "--bucket", kvTestBucket, "--key", kvTestKey, "--value", "x")
+		if err == nil || !strings.Contains(err.Error(), "--write is required") {
+			t.Fatalf("got error %v, exp --write to be required", err)
+		}
+		_, err = runKV(t, "", "delete", "--bolt-path", path, "--bucket", kvTestBucket, "--key", kvTestKey)
+		if err == nil || !strings.Contains(err.Error(), "--write is required") {
+			t.Fatalf("got error %v, exp --write to be required", err)
+		}
+		if _, err := get(t); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("put of the indented output of get", func(t *testing.T) {
+		out, err := get(t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		edited := strings.Replace(out, "dash_1", "dash_2", 1)
+
+		_, err = runKV(t, edited, "put", "--bolt-path", path, "--bucket", kvTestBucket, "--key", kvTestKey, "--write")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := get(t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != edited {
+			t.Fatalf("got %q, exp %q", got, edited)
+		}
+
+		db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		err = db.View(func(tx *bolt.Tx) error {
+			v := tx.Bucket([]byte(kvTestBucket)).Get([]byte(kvTestKey))
+			if exp := `{"id":"0000000000000001","name":"dash_2"}`; string(v) != exp {
+				t.Errorf("got stored value %q, exp the compacted %q", v, exp)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("put of a new key", func(t *testing.T) {
+		_, err := runKV(t, "", "put", "--bolt-path", path, "--bucket", kvTestBucket, "--key", "new", "--value", "not json", "--write")
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := runKV(t, "", "get", "--bolt-path", path, "--bucket", kvTestBucket, "--key", "new")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != "not json" {
+			t.Fatalf("got %q, exp %q", out, "not json")
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		_, err := runKV(t, "", "delete", "--bolt-path", path, "--bucket", kvTestBucket, "--key", kvTestKey, "--write")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := get(t); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Fatalf("got error %v, exp key not found", err)
+		}
+
+		_, err = runKV(t, "", "delete", "--bolt-path", path, "--bucket", kvTestBucket, "--key", kvTestKey, "--write")
+		if err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Fatalf("got error %v, exp key not found", err)
+		}
+	})
+}
+
+func TestKV_BackupFirst(t *testing.T) {
+	path, cleanup := newKVTestFile(t)
+	defer cleanup()
+
+	out, err := runKV(t, "", "delete", "--bolt-path", path, "--bucket", kvTestBucket, "--key", kvTestKey, "--write", "--backup-first")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := filepath.Glob(path + ".*.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("got backups %v, exp 1", backups)
+	}
+	if !strings.Contains(out, backups[0]) {
+		t.Fatalf("got output %q, exp the backup path %s", out, backups[0])
+	}
+
+	// the backup holds the value deleted.
+	got, err := runKV(t, "", "get", "--bolt-path", backups[0], "--bucket", kvTestBucket, "--key", kvTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "dash_1") {
+		t.Fatalf("got %q from the backup, exp the deleted value", got)
+	}
+
+	if _, err := runKV(t, "", "get", "--bolt-path", path, "--bucket", kvTestBucket, "--key", kvTestKey); err == nil {
+		t.Fatal("expected the key to be deleted")
+	}
+}
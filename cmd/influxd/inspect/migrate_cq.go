@@ -0,0 +1,199 @@
+package inspect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/pkger"
+	"github.com/influxdata/influxdb/task/cq"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var migrateCQFlags = struct {
+	// Standard input/output, overridden for testing.
+	Stdin  io.Reader
+	Stdout io.Writer
+
+	File   string
+	OutPkg string
+	DryRun bool
+
+	Host       string
+	Token      string
+	Org        string
+	OrgID      string
+	SkipVerify bool
+}{
+	Stdin:  os.Stdin,
+	Stdout: os.Stdout,
+}
+
+// NewMigrateCQCommand returns a new instance of the migrate-cq command.
+func NewMigrateCQCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-cq",
+		Short: "Migrates InfluxDB 1.x continuous queries to tasks",
+		Long: `
+This command translates InfluxDB 1.x continuous queries into Flux tasks.
+
+The continuous queries are read from a file, or stdin, as either
+CREATE CONTINUOUS QUERY statements or the JSON output of
+SHOW CONTINUOUS QUERIES. The bucket of a 1.x database and retention
+policy is named db/rp.
+
+By default the tasks are created in the organization provided. With
+--out-pkg the tasks are written to a pkg instead, to be applied with
+influx pkg, and with --dry-run the scripts are only printed. The pkg
+is encoded as JSON when its path has a .json extension, as YAML
+otherwise.
+
+A continuous query that cannot be translated is reported and skipped,
+it does not prevent the others from being migrated.`,
+		Args: cobra.NoArgs,
+		RunE: runMigrateCQ,
+	}
+
+	cmd.Flags().StringVarP(&migrateCQFlags.File, "file", "f", "", "Path to the continuous queries, reads stdin when not provided")
+	cmd.Flags().StringVar(&migrateCQFlags.OutPkg, "out-pkg", "", "Path to write a pkg of the tasks to instead of creating the tasks")
+	cmd.Flags().BoolVar(&migrateCQFlags.DryRun, "dry-run", false, "Print the translated tasks without creating them")
+	cmd.Flags().StringVar(&migrateCQFlags.Host, "host", "http://localhost:9999", "HTTP address of the server to create the tasks in")
+	cmd.Flags().StringVarP(&migrateCQFlags.Token, "token", "t", "", "API token used to create the tasks")
+	cmd.Flags().StringVarP(&migrateCQFlags.Org, "org", "o", "", "The name of the organization that owns the tasks")
+	cmd.Flags().StringVar(&migrateCQFlags.OrgID, "org-id", "", "The ID of the organization that owns the tasks")
+	cmd.Flags().BoolVar(&migrateCQFlags.SkipVerify, "skip-verify", false, "Skip TLS certificate verification")
+
+	return cmd
+}
+
+func runMigrateCQ(cmd *cobra.Command, args []string) error {
+	flags := migrateCQFlags
+
+	create := !flags.DryRun && flags.OutPkg == ""
+	if create && flags.Org == "" && flags.OrgID == "" {
+		return errors.New("must specify one of --org or --org-id to create the tasks")
+	}
+
+	var orgID influxdb.ID
+	if flags.OrgID != "" {
+		if err := orgID.DecodeFromString(flags.OrgID); err != nil {
+			return fmt.Errorf("invalid org ID %q: %v", flags.OrgID, err)
+		}
+	}
+
+	r := flags.Stdin
+	if flags.File != "" {
+		f, err := os.Open(flags.File)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	cqs, err := cq.Parse(r)
+	if err != nil {
+		return fmt.Errorf("failed to parse continuous queries: %v", err)
+	}
+
+	taskSVC := &http.TaskService{
+		Addr:               flags.Host,
+		Token:              flags.Token,
+		InsecureSkipVerify: flags.SkipVerify,
+	}
+
+	pkg := &pkger.Pkg{
+		APIVersion: pkger.APIVersion,
+		Kind:       pkger.KindPackage,
+		Metadata: pkger.Metadata{
+			Name:        "migrated_continuous_queries",
+			Version:     "1",
+			Description: "Tasks migrated from InfluxDB 1.x continuous queries",
+		},
+	}
+
+	var failed int
+	for _, res := range cq.TranslateAll(cqs) {
+		name := res.ContinuousQuery.Database + "." + res.ContinuousQuery.Name
+		if res.Err != nil {
+			failed++
+			fmt.Fprintf(flags.Stdout, "SKIPPED %s: %v\n", name, res.Err)
+			continue
+		}
+
+		switch {
+		case flags.DryRun:
+			fmt.Fprintf(flags.Stdout, "-- %s\n%s\n", name, res.Task.Flux)
+		case flags.OutPkg != "":
+			pkg.Spec.Resources = append(pkg.Spec.Resources, pkger.Resource{
+				"kind":        pkger.KindTask.String(),
+				"name":        res.Task.Name,
+				"description": "Migrated from continuous query " + name,
+				"every":       res.Task.Every,
+				"query":       res.Task.Query,
+			})
+			fmt.Fprintf(flags.Stdout, "ADDED %s: task %s\n", name, res.Task.Name)
+		default:
+			t, err := taskSVC.CreateTask(context.Background(), influxdb.TaskCreate{
+				Flux:           res.Task.Flux,
+				Description:    "Migrated from continuous query " + name,
+				OrganizationID: orgID,
+				Organization:   flags.Org,
+			})
+			if err != nil {
+				failed++
+				fmt.Fprintf(flags.Stdout, "FAILED %s: %v\n", name, err)
+				continue
+			}
+			fmt.Fprintf(flags.Stdout, "CREATED %s: task %s\n", name, t.ID)
+		}
+	}
+
+	if flags.OutPkg != "" && len(pkg.Spec.Resources) > 0 {
+		if err := writeMigratedPkg(flags.OutPkg, pkg); err != nil {
+			return err
+		}
+		fmt.Fprintf(flags.Stdout, "WROTE %d tasks: %s\n", len(pkg.Spec.Resources), flags.OutPkg)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d continuous queries were not migrated", failed, len(cqs))
+	}
+	return nil
+}
+
+// writeMigratedPkg validates the pkg of the migrated tasks and writes it to
+// the path, encoded as JSON for a .json path and YAML otherwise.
+func writeMigratedPkg(path string, pkg *pkger.Pkg) error {
+	if err := pkg.Validate(); err != nil {
+		return fmt.Errorf("invalid pkg of migrated tasks: %v", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var enc interface {
+		Encode(interface{}) error
+	}
+	if filepath.Ext(path) == ".json" {
+		jsonEnc := json.NewEncoder(f)
+		jsonEnc.SetIndent("", "\t")
+		enc = jsonEnc
+	} else {
+		enc = yaml.NewEncoder(f)
+	}
+	if err := enc.Encode(pkg); err != nil {
+		return err
+	}
+	return f.Close()
+}
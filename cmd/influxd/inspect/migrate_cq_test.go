@@ -0,0 +1,69 @@
+package inspect
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkger"
+)
+
+func TestMigrateCQ_OutPkg(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influxd-inspect-migrate-cq-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cqs := `CREATE CONTINUOUS QUERY cq_mean ON db BEGIN SELECT mean(a) INTO m2 FROM m GROUP BY time(1h) END;
+CREATE CONTINUOUS QUERY cq_regex ON db BEGIN SELECT mean(a) INTO m2 FROM /cpu.*/ GROUP BY time(1h) END`
+
+	for _, ext := range []string{".yml", ".json"} {
+		t.Run(ext, func(t *testing.T) {
+			var stdout bytes.Buffer
+			path := filepath.Join(dir, "tasks"+ext)
+
+			prev := migrateCQFlags
+			defer func() { migrateCQFlags = prev }()
+			migrateCQFlags.Stdin = strings.NewReader(cqs)
+			migrateCQFlags.Stdout = &stdout
+			migrateCQFlags.OutPkg = path
+
+			// the unsupported continuous query is reported, the other is
+			// still written to the pkg
+			if err := runMigrateCQ(nil, nil); err == nil {
+				t.Fatal("expected an error for the unsupported continuous query")
+			}
+			if !strings.Contains(stdout.String(), "SKIPPED db.cq_regex") {
+				t.Errorf("expected the regex continuous query to be skipped, got:\n%s", stdout.String())
+			}
+
+			enc := pkger.EncodingYAML
+			if ext == ".json" {
+				enc = pkger.EncodingJSON
+			}
+			pkg, err := pkger.Parse(enc, pkger.FromFile(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tasks := pkg.Summary().Tasks
+			if len(tasks) != 1 {
+				t.Fatalf("expected 1 task, got %d", len(tasks))
+			}
+			task := tasks[0]
+			if task.Name != "cq_mean" {
+				t.Errorf("unexpected task name %q", task.Name)
+			}
+			if task.Every != "1h" {
+				t.Errorf("unexpected task every %q", task.Every)
+			}
+			if !strings.HasPrefix(task.Query, `from(bucket: "db/autogen")`) {
+				t.Errorf("unexpected task query:\n%s", task.Query)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var verifyBucketsFlags = struct {
+	// Standard output, overridden for testing.
+	Stdout io.Writer
+
+	boltPath   string
+	enginePath string
+	fix        bool
+}{
+	Stdout: os.Stdout,
+}
+
+// NewVerifyBucketsCommand returns a new instance of the verify-buckets command.
+func NewVerifyBucketsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-buckets",
+		Short: "Check the buckets of the bolt store against the data of the engine",
+		Long: `
+This command compares the buckets of the bolt store with the buckets the
+storage engine holds data for, i.e. after restoring backups of the two that
+were taken at different times. Only the metadata of each is read.
+
+Buckets the engine holds data for that do not exist in the bolt store are
+orphaned, their data is never queried and the disk it occupies is never
+reclaimed. With --fix the data of the orphaned buckets is deleted.
+
+Buckets of the bolt store the engine holds no data for are reported as empty.
+A bucket that has not been written to is empty, as is a bucket whose data was
+lost. There is nothing to repair for them.
+
+influxd must not be running while this command is run.`,
+		Args: cobra.NoArgs,
+		RunE: runVerifyBuckets,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(err)
+	}
+	cmd.Flags().StringVar(&verifyBucketsFlags.boltPath, "bolt-path", filepath.Join(dir, "influxd.bolt"), "Path to the bolt store")
+	cmd.Flags().StringVar(&verifyBucketsFlags.enginePath, "engine-path", filepath.Join(dir, "engine"), "Path to the storage engine files")
+	cmd.Flags().BoolVar(&verifyBucketsFlags.fix, "fix", false, "Delete the data of the orphaned buckets")
+
+	return cmd
+}
+
+func runVerifyBuckets(cmd *cobra.Command, args []string) error {
+	flags := verifyBucketsFlags
+	ctx := context.Background()
+
+	if _, err := os.Stat(flags.boltPath); err != nil {
+		return fmt.Errorf("bolt store: %v", err)
+	}
+	store := bolt.NewKVStore(zap.NewNop(), flags.boltPath)
+	if err := store.Open(ctx); err != nil {
+		return err
+	}
+	defer store.Close()
+
+	engine := storage.NewEngine(flags.enginePath, storage.NewConfig())
+	if err := engine.Open(ctx); err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	c, err := storage.CheckBucketConsistency(ctx, engine, kv.NewService(zap.NewNop(), store))
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(flags.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "Status\tOrganization ID\tBucket ID")
+	for _, ref := range c.Orphaned {
+		fmt.Fprintf(tw, "orphaned\t%s\t%s\n", ref.OrgID, ref.BucketID)
+	}
+	for _, ref := range c.Empty {
+		fmt.Fprintf(tw, "empty\t%s\t%s\n", ref.OrgID, ref.BucketID)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(flags.Stdout, "\n%d orphaned, %d empty buckets\n", len(c.Orphaned), len(c.Empty))
+
+	if !flags.fix || c.Consistent() {
+		return nil
+	}
+	if err := storage.DeleteOrphanedBuckets(ctx, engine, c.Orphaned); err != nil {
+		return err
+	}
+	fmt.Fprintf(flags.Stdout, "Deleted the data of %d orphaned buckets\n", len(c.Orphaned))
+	return nil
+}
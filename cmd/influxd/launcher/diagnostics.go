@@ -0,0 +1,217 @@
+package launcher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/prometheus/common/expfmt"
+	"go.uber.org/zap"
+)
+
+const (
+	// diagnosticsLogLines is the number of the last log lines kept for a
+	// diagnostic bundle.
+	diagnosticsLogLines = 1000
+
+	diagnosticsFilePrefix = "influxd-diagnostics-"
+	diagnosticsFileSuffix = ".txt"
+)
+
+// redactedFlags are the substrings of the names of the options whose values
+// are never written to a diagnostic bundle.
+var redactedFlags = []string{"token", "password"}
+
+// logRing keeps the last lines written to it.
+type logRing struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+func newLogRing(n int) *logRing {
+	return &logRing{lines: make([]string, n)}
+}
+
+// Write keeps the line written, the logger writes one entry per call.
+func (r *logRing) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lines[r.next] = string(p)
+	r.next = (r.next + 1) % len(r.lines)
+	if r.next == 0 {
+		r.full = true
+	}
+	return len(p), nil
+}
+
+// Lines returns the lines kept, oldest first.
+func (r *logRing) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]string(nil), r.lines[:r.next]...)
+	}
+	return append(append([]string(nil), r.lines[r.next:]...), r.lines[:r.next]...)
+}
+
+// handlePanic writes a diagnostic bundle of the panic recovered and then
+// panics again with it. It must be deferred.
+func (m *Launcher) handlePanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	// net/http aborts handlers with this panic on purpose
+	if r != nethttp.ErrAbortHandler {
+		m.writeDiagnostics(r)
+	}
+	panic(r)
+}
+
+// panicMW writes a diagnostic bundle of a panic of a handler.
+func (m *Launcher) panicMW(next nethttp.Handler) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		defer m.handlePanic()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// diagnosticsPath returns the directory the diagnostic bundles are written to.
+func (m *Launcher) diagnosticsPath() string {
+	if m.diagnosticsDir != "" {
+		return m.diagnosticsDir
+	}
+	return filepath.Join(m.enginePath, "diagnostics")
+}
+
+// writeDiagnostics writes a diagnostic bundle of the panic and removes the
+// bundles beyond the retention count. Failures are logged, the panic must
+// not be masked by them.
+func (m *Launcher) writeDiagnostics(recovered interface{}) {
+	dir := m.diagnosticsPath()
+	path := filepath.Join(dir, diagnosticsFilePrefix+time.Now().UTC().Format("20060102T150405.000000000Z")+diagnosticsFileSuffix)
+
+	err := func() error {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w := bufio.NewWriter(f)
+		m.writeDiagnosticBundle(w, recovered)
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		return f.Sync()
+	}()
+	if err != nil {
+		m.log.Error("Failed to write diagnostic bundle", zap.String("path", path), zap.Error(err))
+		return
+	}
+	m.log.Error("Panic, wrote diagnostic bundle", zap.String("path", path), zap.Any("panic", recovered))
+
+	if err := removeOldDiagnostics(dir, m.diagnosticsRetention); err != nil {
+		m.log.Warn("Failed to remove old diagnostic bundles", zap.Error(err))
+	}
+}
+
+func (m *Launcher) writeDiagnosticBundle(w io.Writer, recovered interface{}) {
+	info := platform.GetBuildInfo()
+	fmt.Fprintf(w, "panic: %v\n", recovered)
+	fmt.Fprintf(w, "time: %s\n", time.Now().UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(w, "version: %s\ncommit: %s\nbuild_date: %s\n", info.Version, info.Commit, info.Date)
+
+	fmt.Fprint(w, "\n== config ==\n")
+	for _, o := range m.opts {
+		fmt.Fprintf(w, "%s = %s\n", o.Flag, redactOpt(o.Flag, o.DestP))
+	}
+
+	fmt.Fprint(w, "\n== goroutines ==\n")
+	if err := pprof.Lookup("goroutine").WriteTo(w, 2); err != nil {
+		fmt.Fprintf(w, "failed to write goroutines: %v\n", err)
+	}
+
+	fmt.Fprint(w, "\n== logs ==\n")
+	if m.logRing != nil {
+		for _, l := range m.logRing.Lines() {
+			fmt.Fprint(w, l)
+		}
+	}
+
+	fmt.Fprint(w, "\n== metrics ==\n")
+	if m.reg == nil {
+		return
+	}
+	mfs, err := m.reg.Gather()
+	if err != nil {
+		fmt.Fprintf(w, "failed to gather metrics: %v\n", err)
+	}
+	for _, mf := range mfs {
+		if _, err := expfmt.MetricFamilyToText(w, mf); err != nil {
+			fmt.Fprintf(w, "failed to write metric %s: %v\n", mf.GetName(), err)
+		}
+	}
+}
+
+// redactOpt returns the value of the option, redacted if it is a secret.
+func redactOpt(flag string, destP interface{}) string {
+	v := fmt.Sprint(reflect.ValueOf(destP).Elem().Interface())
+	for _, s := range redactedFlags {
+		if strings.Contains(flag, s) && v != "" {
+			return "[REDACTED]"
+		}
+	}
+	return v
+}
+
+// removeOldDiagnostics removes all but the newest retention bundles of the
+// directory.
+func removeOldDiagnostics(dir string, retention int) error {
+	if retention <= 0 {
+		return nil
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, fi := range fis {
+		name := fi.Name()
+		if strings.HasPrefix(name, diagnosticsFilePrefix) && strings.HasSuffix(name, diagnosticsFileSuffix) {
+			names = append(names, name)
+		}
+	}
+	if len(names) <= retention {
+		return nil
+	}
+
+	// the names are timestamped, they sort oldest first
+	sort.Strings(names)
+	for _, name := range names[:len(names)-retention] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
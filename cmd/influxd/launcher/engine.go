@@ -28,6 +28,7 @@ type Engine interface {
 	readservice.Viewer
 	storage.PointsWriter
 	storage.BucketDeleter
+	storage.BucketIndex
 	prom.PrometheusCollector
 
 	SeriesCardinality() int64
@@ -103,6 +104,11 @@ func (t *TemporaryEngine) Close() error {
 	return err
 }
 
+// Config returns the config the engine is opened with.
+func (t *TemporaryEngine) Config() storage.Config {
+	return t.config
+}
+
 // WritePoints stores points into the storage engine.
 func (t *TemporaryEngine) WritePoints(ctx context.Context, points []models.Point) error {
 	return t.engine.WritePoints(ctx, points)
@@ -124,6 +130,11 @@ func (t *TemporaryEngine) DeleteBucket(ctx context.Context, orgID, bucketID infl
 	return t.engine.DeleteBucket(ctx, orgID, bucketID)
 }
 
+// ForEachBucket calls fn with the org and bucket of every bucket the engine holds series for.
+func (t *TemporaryEngine) ForEachBucket(fn func(orgID, bucketID influxdb.ID) error) error {
+	return t.engine.ForEachBucket(fn)
+}
+
 // WithLogger sets the logger on the engine. It must be called before Open.
 func (t *TemporaryEngine) WithLogger(log *zap.Logger) {
 	t.log = log.With(zap.String("service", "temporary_engine"))
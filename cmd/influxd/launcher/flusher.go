@@ -4,6 +4,9 @@ import (
 	"context"
 
 	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap"
 )
 
 type flushers []http.Flusher
@@ -13,3 +16,34 @@ func (f flushers) Flush(ctx context.Context) {
 		flusher.Flush(ctx)
 	}
 }
+
+// Reset resets the flushers which are resetters and flushes the others.
+func (f flushers) Reset(ctx context.Context) {
+	for _, flusher := range []http.Flusher(f) {
+		if r, ok := flusher.(http.Resetter); ok {
+			r.Reset(ctx)
+			continue
+		}
+		flusher.Flush(ctx)
+	}
+}
+
+// memoryStoreFlusher flushes the in memory kv store. A reset drops the
+// buckets of the store, the kv service is initialized anew to create the
+// buckets it reads.
+type memoryStoreFlusher struct {
+	log   *zap.Logger
+	store *inmem.KVStore
+	svc   *kv.Service
+}
+
+func (f *memoryStoreFlusher) Flush(ctx context.Context) {
+	f.store.Flush(ctx)
+}
+
+func (f *memoryStoreFlusher) Reset(ctx context.Context) {
+	f.store.Reset(ctx)
+	if err := f.svc.Initialize(ctx); err != nil {
+		f.log.Error("Failed to initialize kv service after a reset", zap.Error(err))
+	}
+}
@@ -3,15 +3,16 @@ package launcher
 import (
 	"context"
 	"crypto/tls"
-	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net"
 	nethttp "net/http"
-	_ "net/http/pprof" // needed to add pprof to our binary.
+	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -33,9 +34,11 @@ import (
 	"github.com/influxdata/influxdb/kv"
 	influxlogger "github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/nats"
+	"github.com/influxdata/influxdb/notification/rule"
 	"github.com/influxdata/influxdb/pkger"
 	infprom "github.com/influxdata/influxdb/prometheus"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/cache"
 	"github.com/influxdata/influxdb/query/control"
 	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
 	"github.com/influxdata/influxdb/snowflake"
@@ -49,6 +52,7 @@ import (
 	"github.com/influxdata/influxdb/task/backend/middleware"
 	"github.com/influxdata/influxdb/task/backend/scheduler"
 	"github.com/influxdata/influxdb/telemetry"
+	"github.com/influxdata/influxdb/toml"
 	_ "github.com/influxdata/influxdb/tsdb/tsi1" // needed for tsi1
 	_ "github.com/influxdata/influxdb/tsdb/tsm1" // needed for tsm1
 	"github.com/influxdata/influxdb/vault"
@@ -56,7 +60,6 @@ import (
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
-	jaegerconfig "github.com/uber/jaeger-client-go/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -71,8 +74,19 @@ const (
 	LogTracing = "log"
 	// JaegerTracing enables tracing via the Jaeger client library
 	JaegerTracing = "jaeger"
+
+	// BasicMetrics leaves out the expensive and the high-cardinality metrics
+	// added along with it, the metrics collected before are unchanged
+	BasicMetrics = "basic"
+	// FullMetrics collects the GC pauses, the mutex contentions and the
+	// high-cardinality metrics of bolt
+	FullMetrics = "full"
 )
 
+// fullMetricsMutexProfileFraction is the rate of the mutex profile with full
+// metrics, on average 1 in 10 contentions are recorded.
+const fullMetricsMutexProfileFraction = 10
+
 // NewCommand creates the command to run influxdb.
 func NewCommand() *cobra.Command {
 	l := NewLauncher()
@@ -94,7 +108,8 @@ func NewCommand() *cobra.Command {
 			var wg sync.WaitGroup
 			if !l.ReportingDisabled() {
 				reporter := telemetry.NewReporter(l.Log(), l.Registry())
-				reporter.Interval = 8 * time.Hour
+				reporter.Interval = l.telemetryInterval
+				reporter.Pusher.URL = l.telemetryEndpoint
 				wg.Add(1)
 				go func() {
 					defer wg.Done()
@@ -104,10 +119,12 @@ func NewCommand() *cobra.Command {
 
 			<-ctx.Done()
 
-			// Attempt clean shutdown.
-			ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			// Attempt clean shutdown, the context is already done.
+			ctx, cancel := context.WithTimeout(context.Background(), l.shutdownTimeout)
 			defer cancel()
-			l.Shutdown(ctx)
+			if err := l.Shutdown(ctx); err != nil {
+				l.Log().Warn("Shutdown did not complete cleanly", zap.Error(err))
+			}
 			wg.Wait()
 		},
 	}
@@ -132,18 +149,160 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: zapcore.InfoLevel.String(),
 			Desc:    "supported log levels are debug, info, and error",
 		},
+		{
+			DestP: &l.logFile,
+			Flag:  "log-file",
+			Desc:  "path of the file to log to in place of stdout, the file is rotated by its size",
+		},
+		{
+			DestP:   &l.logMaxSize,
+			Flag:    "log-max-size",
+			Default: 100,
+			Desc:    "size in megabytes the log file is rotated at, 0 never rotates",
+		},
+		{
+			DestP:   &l.logMaxAge,
+			Flag:    "log-max-age",
+			Default: time.Duration(0),
+			Desc:    "age the rotated log files are removed at, 0 keeps them",
+		},
+		{
+			DestP:   &l.logMaxBackups,
+			Flag:    "log-max-backups",
+			Default: 0,
+			Desc:    "number of rotated log files kept, 0 keeps all of them",
+		},
+		{
+			DestP: &l.logFileTee,
+			Flag:  "log-file-tee",
+			Desc:  "log to stdout as well as to the log file",
+		},
 		{
 			DestP:   &l.tracingType,
 			Flag:    "tracing-type",
 			Default: "",
 			Desc:    fmt.Sprintf("supported tracing types are %s, %s", LogTracing, JaegerTracing),
 		},
+		{
+			DestP:   &l.tracingSampleRate,
+			Flag:    "tracing-sample-rate",
+			Default: float64(-1),
+			Desc:    "fraction of the traces sampled, from 0.0 to 1.0, a trace started by the caller is always sampled. unless negative, the jaeger sampler is a probabilistic sampler of the rate",
+		},
+		{
+			DestP: &l.jaeger.agentHostPort,
+			Flag:  "jaeger-agent-host-port",
+			Desc:  "host:port of the jaeger agent the spans are sent to, overrides JAEGER_AGENT_HOST and JAEGER_AGENT_PORT",
+		},
+		{
+			DestP: &l.jaeger.collectorEndpoint,
+			Flag:  "jaeger-collector-endpoint",
+			Desc:  "URL of the jaeger collector the spans are sent to in place of an agent, overrides JAEGER_ENDPOINT",
+		},
+		{
+			DestP: &l.jaeger.serviceName,
+			Flag:  "jaeger-service-name",
+			Desc:  "service name of the spans, overrides JAEGER_SERVICE_NAME, defaults to influxd",
+		},
+		{
+			DestP: &l.jaeger.samplerType,
+			Flag:  "jaeger-sampler-type",
+			Desc:  "jaeger sampler type, one of const, probabilistic, ratelimiting and remote, overrides JAEGER_SAMPLER_TYPE",
+		},
+		{
+			DestP:   &l.jaeger.samplerParam,
+			Flag:    "jaeger-sampler-param",
+			Default: float64(-1),
+			Desc:    "parameter of the jaeger sampler, overrides JAEGER_SAMPLER_PARAM unless negative",
+		},
 		{
 			DestP:   &l.httpBindAddress,
 			Flag:    "http-bind-address",
 			Default: ":9999",
 			Desc:    "bind address for the REST HTTP API",
 		},
+		{
+			DestP:   &l.metricsBindAddress,
+			Flag:    "metrics-bind-address",
+			Default: "",
+			Desc:    "bind address for the /metrics and /debug endpoints, served apart from the REST HTTP API when set",
+		},
+		{
+			DestP:   &l.metricsDetail,
+			Flag:    "metrics-detail",
+			Default: BasicMetrics,
+			Desc:    fmt.Sprintf("detail of the metrics, %s leaves out the GC pause histogram, the mutex contentions and the per-bucket series of bolt, %s collects them", BasicMetrics, FullMetrics),
+		},
+		{
+			DestP:   &l.pprofEnabled,
+			Flag:    "pprof-enabled",
+			Default: true,
+			Desc:    "serve the go profiles at /debug/pprof",
+		},
+		{
+			DestP:   &l.profilesEnabled,
+			Flag:    "profiles-enabled",
+			Default: false,
+			Desc:    "serve a tar.gz bundle of the go profiles to operator tokens at /debug/profiles/all, on the http-bind-address even with a metrics-bind-address",
+		},
+		{
+			DestP:   &l.httpRequestTimeout,
+			Flag:    "http-request-timeout",
+			Default: time.Duration(0),
+			Desc:    "timeout of REST HTTP API requests, query, write and delete requests are never timed out. 0 disables the timeout",
+		},
+		{
+			DestP:   &l.httpReadTimeout,
+			Flag:    "http-read-timeout",
+			Default: 30 * time.Second,
+			Desc:    "maximum duration the REST HTTP API server waits to read a request, its headers and body. 0 disables the timeout",
+		},
+		{
+			DestP:   &l.httpReadHeaderTimeout,
+			Flag:    "http-read-header-timeout",
+			Default: time.Duration(0),
+			Desc:    "maximum duration the REST HTTP API server waits to read the headers of a request. 0, the default, falls back to the http-read-timeout",
+		},
+		{
+			DestP:   &l.httpWriteTimeout,
+			Flag:    "http-write-timeout",
+			Default: 10 * time.Minute,
+			Desc:    "maximum duration the REST HTTP API server waits to write a response, which bounds how long queries run. the default gives queries of 5 minutes time to complete. 0 disables the timeout",
+		},
+		{
+			DestP:   &l.httpIdleTimeout,
+			Flag:    "http-idle-timeout",
+			Default: 3 * time.Minute,
+			Desc:    "maximum duration the REST HTTP API server keeps an idle keep-alive connection open. 0 disables the timeout",
+		},
+		{
+			DestP: &l.httpAdvertisedURL,
+			Flag:  "http-advertised-url",
+			Desc:  "url clients and telegraf agents reach the REST HTTP API at, for example https://influxdb.example.com:9999. defaults to the host of each request",
+		},
+		{
+			DestP:   &l.natsPort,
+			Flag:    "nats-port",
+			Default: 4222,
+			Desc:    "port of the embedded NATS streaming server. 0 picks any free port",
+		},
+		{
+			DestP: &l.diagnosticsDir,
+			Flag:  "diagnostics-dir",
+			Desc:  "directory a diagnostic bundle is written to when influxd panics. defaults to the diagnostics directory of the engine path",
+		},
+		{
+			DestP:   &l.diagnosticsRetention,
+			Flag:    "diagnostics-retention",
+			Default: 5,
+			Desc:    "number of the newest diagnostic bundles kept. 0 keeps all",
+		},
+		{
+			DestP:   &l.shutdownTimeout,
+			Flag:    "shutdown-timeout",
+			Default: 30 * time.Second,
+			Desc:    "time in-flight requests, task runs and queries are given to drain on shutdown before the stores are closed",
+		},
 		{
 			DestP:   &l.boltPath,
 			Flag:    "bolt-path",
@@ -157,15 +316,16 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 		},
 		{
 			DestP:   &l.storeType,
-			Flag:    "store",
+			Flag:    "store-type",
 			Default: "bolt",
 			Desc:    "backing store for REST resources (bolt or memory)",
+			Aliases: []string{"store"},
 		},
 		{
 			DestP:   &l.testing,
 			Flag:    "e2e-testing",
 			Default: false,
-			Desc:    "add /debug/flush endpoint to clear stores; used for end-to-end tests",
+			Desc:    "add /debug/flush endpoint to clear stores, /debug/flush?reset=true drops the buckets of the memory store; used for end-to-end tests",
 		},
 		{
 			DestP:   &l.enginePath,
@@ -173,6 +333,24 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: filepath.Join(dir, "engine"),
 			Desc:    "path to persistent engine files",
 		},
+		{
+			DestP:   &l.storageRetentionInterval,
+			Flag:    "storage-retention-check-interval",
+			Default: storage.DefaultRetentionInterval,
+			Desc:    "how often the storage engine deletes the data of the buckets older than their retention period",
+		},
+		{
+			DestP:   &l.bucketCheckDisabled,
+			Flag:    "bucket-consistency-check-disabled",
+			Default: false,
+			Desc:    "disable comparing the buckets of the bolt store with the buckets the engine holds data for on startup",
+		},
+		{
+			DestP:   &l.bucketCheckFix,
+			Flag:    "bucket-consistency-fix",
+			Default: false,
+			Desc:    "delete the data the engine holds for buckets that do not exist in the bolt store, found by the startup consistency check, in the background",
+		},
 		{
 			DestP:   &l.secretStore,
 			Flag:    "secret-store",
@@ -183,7 +361,19 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			DestP:   &l.reportingDisabled,
 			Flag:    "reporting-disabled",
 			Default: false,
-			Desc:    "disable sending telemetry data to https://telemetry.influxdata.com every 8 hours",
+			Desc:    "disable sending telemetry data, including the anonymous usage of templates, to the telemetry endpoint every telemetry interval",
+		},
+		{
+			DestP:   &l.telemetryInterval,
+			Flag:    "telemetry-interval",
+			Default: 8 * time.Hour,
+			Desc:    "interval at which telemetry data is sent, at least 1m outside of dev builds",
+		},
+		{
+			DestP:   &l.telemetryEndpoint,
+			Flag:    "telemetry-endpoint",
+			Default: telemetry.DefaultURL,
+			Desc:    "URL of the prometheus push gateway telemetry data is sent to",
 		},
 		{
 			DestP:   &l.sessionLength,
@@ -197,6 +387,12 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: false,
 			Desc:    "disables automatically extending session ttl on request",
 		},
+		{
+			DestP:   &l.variableMaxValues,
+			Flag:    "variable-max-values",
+			Default: platform.DefaultVariableMaxValues,
+			Desc:    "maximum number of values of a constant or map variable",
+		},
 		{
 			DestP: &vaultConfig.Address,
 			Flag:  "vault-addr",
@@ -259,15 +455,119 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: "",
 			Desc:    "TLS key for HTTPs",
 		},
+		{
+			DestP:   &l.httpTLSMinVersion,
+			Flag:    "tls-min-version",
+			Default: "1.2",
+			Desc:    "minimum TLS version accepted for HTTPs, one of 1.0, 1.1, 1.2 or 1.3",
+		},
+		{
+			DestP:   &l.httpTLSStrictCiphers,
+			Flag:    "tls-strict-ciphers",
+			Default: false,
+			Desc:    "restrict the TLS cipher suites and curves for HTTPs to the AEAD suites with forward secrecy",
+		},
+		{
+			DestP:   &l.httpTLSClientCA,
+			Flag:    "tls-client-ca",
+			Default: "",
+			Desc:    "PEM-encoded CA bundle for HTTPs, clients must present a certificate signed by it",
+		},
+		{
+			DestP:   &l.lenientIDDecoding,
+			Flag:    "lenient-id-decoding",
+			Default: false,
+			Desc:    "accept decimal IDs where an ID is not a valid 16 character hex string",
+		},
 		{
 			DestP:   &l.EnableNewScheduler,
-			Flag:    "feature-enable-new-scheduler",
+			Flag:    "new-scheduler-enabled",
 			Default: false,
 			Desc:    "feature flag that enables using the new treescheduler",
+			Aliases: []string{"feature-enable-new-scheduler"},
+		},
+		{
+			DestP:   &l.queryConcurrency,
+			Flag:    "query-concurrency",
+			Default: 10,
+			Desc:    "number of queries allowed to execute concurrently",
+		},
+		{
+			DestP:   &l.queryMemoryBytes,
+			Flag:    "query-memory-bytes",
+			Default: 0,
+			Desc:    "maximum number of bytes of memory a query is allowed to use at any given time. 0 does not limit the memory",
+		},
+		{
+			DestP:   &l.queryQueueSize,
+			Flag:    "query-queue-size",
+			Default: 10,
+			Desc:    "number of queries allowed to be awaiting execution before new queries are rejected",
+		},
+		{
+			DestP:   &l.queryCacheEnabled,
+			Flag:    "query-cache-enabled",
+			Default: false,
+			Desc:    "cache the results of repeated queries over time ranges that ended in the past",
+		},
+		{
+			DestP:   &l.queryCacheConfig.MaxBytes,
+			Flag:    "query-cache-max-bytes",
+			Default: cache.DefaultMaxBytes,
+			Desc:    "total size of the cached query results",
+		},
+		{
+			DestP:   &l.queryCacheConfig.TTL,
+			Flag:    "query-cache-ttl",
+			Default: cache.DefaultTTL,
+			Desc:    "how long a cached query result is served",
+		},
+		{
+			DestP:   &l.queryCacheConfig.MinAge,
+			Flag:    "query-cache-min-age",
+			Default: cache.DefaultMinAge,
+			Desc:    "how far in the past the time range of a query must end for its result to be cached",
 		},
 	}
 
 	cli.BindOptions(cmd, opts)
+	l.opts = opts
+	cmd.Flags().StringVar(&l.configPath, "config", os.Getenv("INFLUXD_CONFIG_PATH"), "path to a TOML, YAML or JSON config file setting the options by their flag names, defaults to $INFLUXD_CONFIG_PATH")
+	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
+		aliasUses, err := cli.ResolveAliases(cmd, "influxd", opts)
+		if err != nil {
+			return err
+		}
+		l.aliasUses = aliasUses
+
+		// a SIGHUP reloads the log level of the options, reading the config
+		// file again.
+		logLevel := l.logLevel
+		l.reloadLogLevel = func() (string, error) {
+			level := logLevel
+			if l.configPath == "" {
+				return level, nil
+			}
+			for _, o := range opts {
+				if o.Flag == "log-level" {
+					o.DestP = &level
+					_, err := cli.LoadConfig(cmd, "influxd", l.configPath, []cli.Opt{o})
+					return level, err
+				}
+			}
+			return level, nil
+		}
+
+		if l.configPath == "" {
+			return nil
+		}
+		unknown, err := cli.LoadConfig(cmd, "influxd", l.configPath, opts)
+		if err != nil {
+			return err
+		}
+		l.unknownConfigKeys = unknown
+		return nil
+	}
 	cmd.AddCommand(inspect.NewCommand())
 
 }
@@ -283,31 +583,88 @@ type Launcher struct {
 	testing              bool
 	sessionLength        int // in minutes
 	sessionRenewDisabled bool
+	variableMaxValues    int
+
+	lenientIDDecoding bool
 
 	logLevel          string
+	level             zap.AtomicLevel
+	reloadLogLevel    func() (string, error)
+	logFile           string
+	logMaxSize        int
+	logMaxAge         time.Duration
+	logMaxBackups     int
+	logFileTee        bool
+	logSink           *influxlogger.RotatingFile
 	tracingType       string
+	tracingSampleRate float64
+	jaeger            jaegerOptions
+	tracing           *tracingSettings
 	reportingDisabled bool
-
-	httpBindAddress string
-	boltPath        string
-	enginePath      string
-	secretStore     string
+	telemetryInterval time.Duration
+	telemetryEndpoint string
+
+	configPath        string
+	unknownConfigKeys []string
+	aliasUses         []cli.AliasUse
+	opts              []cli.Opt
+
+	shutdownTimeout time.Duration
+
+	diagnosticsDir       string
+	diagnosticsRetention int
+	logRing              *logRing
+
+	httpBindAddress       string
+	httpRequestTimeout    time.Duration
+	httpReadTimeout       time.Duration
+	httpReadHeaderTimeout time.Duration
+	httpWriteTimeout      time.Duration
+	httpIdleTimeout       time.Duration
+	httpAdvertisedURL     string
+	metricsBindAddress    string
+	metricsDetail         string
+	pprofEnabled          bool
+	profilesEnabled       bool
+	boltPath              string
+	enginePath            string
+	secretStore           string
+
+	bucketCheckDisabled bool
+	bucketCheckFix      bool
+
+	storageRetentionInterval time.Duration
 
 	boltClient    *bolt.Client
 	kvService     *kv.Service
 	engine        Engine
 	StorageConfig storage.Config
 
-	queryController *control.Controller
+	queryController   *control.Controller
+	queryConcurrency  int
+	queryMemoryBytes  int
+	queryQueueSize    int
+	queryCacheEnabled bool
+	queryCacheConfig  cache.Config
 
 	httpPort    int
 	httpServer  *nethttp.Server
 	httpTLSCert string
 	httpTLSKey  string
 
+	httpTLSMinVersion    string
+	httpTLSStrictCiphers bool
+	httpTLSClientCA      string
+
+	metricsPort   int
+	metricsServer *nethttp.Server
+
 	natsServer *nats.Server
 	natsPort   int
 
+	// readiness is served at /ready, it tracks the subsystems started.
+	readiness *readiness
+
 	EnableNewScheduler bool
 	scheduler          *taskbackend.TickScheduler
 	treeScheduler      *scheduler.TreeScheduler
@@ -321,16 +678,53 @@ type Launcher struct {
 	Stdout     io.Writer
 	Stderr     io.Writer
 	apibackend *http.APIBackend
+	pkgerSVC   pkger.SVC
+
+	preRunFns        []PreRunFn
+	resourceHandlers []http.ResourceHandler
+}
+
+// PreRunFn is called by the Launcher once all services are wired together but
+// before the HTTP listener is started.
+type PreRunFn func(ctx context.Context, m *Launcher) error
+
+// OptFn is a functional option for the Launcher.
+type OptFn func(m *Launcher)
+
+// WithPreRun registers a hook that is run after the services are wired, but
+// before the HTTP listener starts. Embedders of the Launcher can use this to
+// seed data or to register additional resource handlers with
+// RegisterResourceHandler. An error returned from the hook stops the Launcher
+// from starting.
+func WithPreRun(fn PreRunFn) OptFn {
+	return func(m *Launcher) {
+		m.preRunFns = append(m.preRunFns, fn)
+	}
 }
 
 // NewLauncher returns a new instance of Launcher connected to standard in/out/err.
-func NewLauncher() *Launcher {
-	return &Launcher{
+func NewLauncher(opts ...OptFn) *Launcher {
+	m := &Launcher{
 		Stdin:         os.Stdin,
 		Stdout:        os.Stdout,
 		Stderr:        os.Stderr,
 		StorageConfig: storage.NewConfig(),
+
+		queryCacheConfig: cache.NewConfig(),
+
+		readiness: newReadiness(readyCheckKV, readyCheckEngine, readyCheckScheduler, readyCheckNATS),
+	}
+	for _, o := range opts {
+		o(m)
 	}
+	return m
+}
+
+// RegisterResourceHandler registers a resource handler to be served by the API
+// handler alongside the platform's own. It must be called before the HTTP listener
+// is started, i.e. from within a PreRunFn.
+func (m *Launcher) RegisterResourceHandler(h http.ResourceHandler) {
+	m.resourceHandlers = append(m.resourceHandlers, h)
 }
 
 // Running returns true if the main Launcher has started running.
@@ -358,11 +752,40 @@ func (m *Launcher) URL() string {
 	return fmt.Sprintf("http://127.0.0.1:%d", m.httpPort)
 }
 
+// MetricsURL returns the URL to connect to the metrics HTTP server, empty when
+// the metrics are served by the HTTP server of the API.
+func (m *Launcher) MetricsURL() string {
+	if m.metricsServer == nil {
+		return ""
+	}
+	return fmt.Sprintf("http://127.0.0.1:%d", m.metricsPort)
+}
+
+// resolvePort returns the port, or a free port picked by the OS if it is 0.
+func resolvePort(port int) (int, error) {
+	if port != 0 {
+		return port, nil
+	}
+
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
 // NatsURL returns the URL to connection to the NATS server.
 func (m *Launcher) NatsURL() string {
 	return fmt.Sprintf("http://127.0.0.1:%d", m.natsPort)
 }
 
+// HTTPServer returns the HTTP server of the REST API. It should only be
+// called for end-to-end testing purposes.
+func (m *Launcher) HTTPServer() *nethttp.Server {
+	return m.httpServer
+}
+
 // Engine returns a reference to the storage engine. It should only be called
 // for end-to-end testing purposes.
 func (m *Launcher) Engine() Engine {
@@ -370,36 +793,70 @@ func (m *Launcher) Engine() Engine {
 }
 
 // Shutdown shuts down the HTTP server and waits for all services to clean up.
-func (m *Launcher) Shutdown(ctx context.Context) {
-	m.httpServer.Shutdown(ctx)
+// The stores are closed even if a service did not drain before the context is
+// done, the services that did not are returned in the error.
+func (m *Launcher) Shutdown(ctx context.Context) error {
+	// the node reports not ready before its listeners close, so it is taken
+	// out of the load balancer rotation while it drains.
+	m.readiness.stop()
+
+	var undrained []string
+	drain := func(ctx context.Context, service string, stop func() error) {
+		if !m.drain(ctx, service, stop) {
+			undrained = append(undrained, service)
+		}
+	}
 
-	m.log.Info("Stopping", zap.String("service", "task"))
-	if m.EnableNewScheduler {
-		m.treeScheduler.Stop()
-	} else {
-		m.scheduler.Stop()
+	// in-flight requests and task runs are drained first, they may still
+	// read and write the stores closed after them. The requests are given
+	// a share of the budget, the task runs and queries the bulk of it.
+	httpCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		httpCtx, cancel = context.WithTimeout(ctx, time.Until(deadline)/shutdownHTTPShare)
+		defer cancel()
 	}
+	drain(httpCtx, "http", func() error {
+		err := m.httpServer.Shutdown(httpCtx)
+		if m.metricsServer != nil {
+			if merr := m.metricsServer.Shutdown(httpCtx); err == nil {
+				err = merr
+			}
+		}
+		return err
+	})
+
+	drain(ctx, "task", func() error {
+		if m.EnableNewScheduler {
+			m.treeScheduler.Stop()
+		} else {
+			m.scheduler.Stop()
+		}
+		return nil
+	})
+
+	drain(ctx, "query", func() error {
+		return m.queryController.Shutdown(ctx)
+	})
 
 	m.log.Info("Stopping", zap.String("service", "nats"))
 	m.natsServer.Close()
 
+	drain(ctx, "background", func() error {
+		m.wg.Wait()
+		return nil
+	})
+
 	m.log.Info("Stopping", zap.String("service", "bolt"))
 	if err := m.boltClient.Close(); err != nil {
 		m.log.Info("Failed closing bolt", zap.Error(err))
 	}
 
-	m.log.Info("Stopping", zap.String("service", "query"))
-	if err := m.queryController.Shutdown(ctx); err != nil && err != context.Canceled {
-		m.log.Info("Failed closing query service", zap.Error(err))
-	}
-
 	m.log.Info("Stopping", zap.String("service", "storage-engine"))
 	if err := m.engine.Close(); err != nil {
 		m.log.Error("Failed to close engine", zap.Error(err))
 	}
 
-	m.wg.Wait()
-
 	if m.jaegerTracerCloser != nil {
 		if err := m.jaegerTracerCloser.Close(); err != nil {
 			m.log.Warn("Failed to closer Jaeger tracer", zap.Error(err))
@@ -407,6 +864,177 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 	}
 
 	m.log.Sync()
+	if m.logSink != nil {
+		if err := m.logSink.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close log file: %v\n", err)
+		}
+	}
+
+	if len(undrained) > 0 {
+		return fmt.Errorf("services did not drain in time: %s", strings.Join(undrained, ", "))
+	}
+	return nil
+}
+
+// shutdownHTTPShare is the divisor of the shutdown budget the in-flight
+// requests are given to drain.
+const shutdownHTTPShare = 5
+
+// drain stops the service, waiting for it until the context is done. A
+// service that did not drain in time is logged and left to stop on its own,
+// false is returned for it.
+func (m *Launcher) drain(ctx context.Context, service string, stop func() error) bool {
+	m.log.Info("Stopping", zap.String("service", service))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- stop()
+	}()
+
+	select {
+	case err := <-done:
+		if err == context.DeadlineExceeded {
+			m.log.Warn("Service did not drain in time", zap.String("service", service))
+			return false
+		} else if err != nil && err != context.Canceled {
+			m.log.Info("Failed to stop service", zap.String("service", service), zap.Error(err))
+		}
+		return true
+	case <-ctx.Done():
+		m.log.Warn("Service did not drain in time", zap.String("service", service))
+		return false
+	}
+}
+
+// checkBucketConsistency logs the buckets the bolt store and the engine disagree
+// on. The data of the buckets missing from the bolt store is deleted from the
+// engine in the background when enabled, it does not hold up the startup.
+func (m *Launcher) checkBucketConsistency(ctx context.Context, finder storage.BucketFinder) {
+	log := m.log.With(zap.String("service", "bucket-consistency"))
+
+	c, err := storage.CheckBucketConsistency(ctx, m.engine, finder)
+	if err != nil {
+		log.Error("Failed to check bucket consistency", zap.Error(err))
+		return
+	}
+	for _, ref := range c.Orphaned {
+		log.Warn("Engine holds data for a bucket that does not exist",
+			zap.String("org_id", ref.OrgID.String()),
+			zap.String("bucket_id", ref.BucketID.String()),
+		)
+	}
+	log.Info("Checked bucket consistency", zap.Int("orphaned", len(c.Orphaned)), zap.Int("empty", len(c.Empty)))
+
+	if c.Consistent() || !m.bucketCheckFix {
+		return
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer m.handlePanic()
+		if err := storage.DeleteOrphanedBuckets(ctx, m.engine, c.Orphaned); err != nil {
+			log.Error("Failed to delete orphaned buckets", zap.Error(err))
+			return
+		}
+		log.Info("Deleted orphaned buckets", zap.Int("orphaned", len(c.Orphaned)))
+	}()
+}
+
+// maintenanceWindowCleanupInterval is the interval expired maintenance windows
+// are deleted at.
+const maintenanceWindowCleanupInterval = time.Hour
+
+// cleanupMaintenanceWindows deletes the expired maintenance windows until the
+// context is done.
+func (m *Launcher) cleanupMaintenanceWindows(ctx context.Context, log *zap.Logger) {
+	ticker := time.NewTicker(maintenanceWindowCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := m.kvService.DeleteExpiredMaintenanceWindows(ctx, time.Now().UTC())
+		if err != nil {
+			log.Error("Failed to delete expired maintenance windows", zap.Error(err))
+		} else if n > 0 {
+			log.Info("Deleted expired maintenance windows", zap.Int("count", n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// queryControllerConfig returns the limits of the query controller, or an
+// error if the options are invalid.
+func (m *Launcher) queryControllerConfig() (control.Config, error) {
+	if m.queryConcurrency <= 0 {
+		return control.Config{}, fmt.Errorf("query-concurrency must be positive, got %d", m.queryConcurrency)
+	}
+	if m.queryQueueSize <= 0 {
+		return control.Config{}, fmt.Errorf("query-queue-size must be positive, got %d", m.queryQueueSize)
+	}
+	if m.queryMemoryBytes < 0 {
+		return control.Config{}, fmt.Errorf("query-memory-bytes must not be negative, got %d", m.queryMemoryBytes)
+	}
+
+	memoryBytes := int64(m.queryMemoryBytes)
+	if memoryBytes == 0 {
+		memoryBytes = math.MaxInt64
+	}
+	c := control.Config{
+		ConcurrencyQuota:         m.queryConcurrency,
+		MemoryBytesQuotaPerQuery: memoryBytes,
+		QueueSize:                m.queryQueueSize,
+	}
+	if err := c.Validate(); err != nil {
+		return control.Config{}, fmt.Errorf("invalid query controller limits: %v", err)
+	}
+	return c, nil
+}
+
+// logQueryControllerLimits logs the limits the query controller runs with,
+// after the controller has filled in its defaults. A memory limit of 0 is
+// logged for memory that is not limited.
+func logQueryControllerLimits(log *zap.Logger, c control.Config) {
+	unlimited := func(n int64) int64 {
+		if n == math.MaxInt64 {
+			return 0
+		}
+		return n
+	}
+	log.Info("Query controller limits",
+		zap.Int("concurrency_quota", c.ConcurrencyQuota),
+		zap.Int("queue_size", c.QueueSize),
+		zap.Int64("initial_memory_bytes_quota_per_query", unlimited(c.InitialMemoryBytesQuotaPerQuery)),
+		zap.Int64("memory_bytes_quota_per_query", unlimited(c.MemoryBytesQuotaPerQuery)),
+		zap.Int64("max_memory_bytes", unlimited(c.MaxMemoryBytes)),
+	)
+}
+
+// minTelemetryInterval is the shortest telemetry interval outside of dev
+// builds, so a misconfigured server does not flood the telemetry endpoint.
+const minTelemetryInterval = time.Minute
+
+// validateTelemetry validates the telemetry options. They are only used if
+// reporting is enabled.
+func (m *Launcher) validateTelemetry(info platform.BuildInfo) error {
+	if m.reportingDisabled {
+		return nil
+	}
+	if m.telemetryInterval <= 0 {
+		return fmt.Errorf("telemetry-interval must be positive, got %s", m.telemetryInterval)
+	}
+	devBuild := info.Version == "" || info.Version == "dev"
+	if !devBuild && m.telemetryInterval < minTelemetryInterval {
+		return fmt.Errorf("telemetry-interval must be at least %s, got %s", minTelemetryInterval, m.telemetryInterval)
+	}
+	if _, err := url.Parse(m.telemetryEndpoint); err != nil || m.telemetryEndpoint == "" {
+		return fmt.Errorf("telemetry-endpoint must be a URL, got %q", m.telemetryEndpoint)
+	}
+	return nil
 }
 
 // Cancel executes the context cancel on the program. Used for testing.
@@ -440,47 +1068,134 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return fmt.Errorf("unknown log level; supported levels are debug, info, and error")
 	}
 
-	// Create top level logger
+	queryConfig, err := m.queryControllerConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := m.validateTelemetry(platform.GetBuildInfo()); err != nil {
+		return err
+	}
+
+	if err := m.validateTLS(); err != nil {
+		return err
+	}
+
+	// Create top level logger, its last lines are kept for a diagnostic bundle.
+	// The format is resolved from stdout alone, the lines kept are not
+	// written to a terminal.
+	m.level = zap.NewAtomicLevelAt(lvl)
 	logconf := &influxlogger.Config{
-		Format: "auto",
-		Level:  lvl,
+		Format: "logfmt",
+		Level:  m.level,
+	}
+	m.logRing = newLogRing(diagnosticsLogLines)
+	sinks := []zapcore.WriteSyncer{zapcore.AddSync(m.logRing)}
+	if m.logFile == "" || m.logFileTee {
+		sinks = append(sinks, zapcore.AddSync(m.Stdout))
+		if m.logFile == "" && influxlogger.IsTerminal(m.Stdout) {
+			logconf.Format = "console"
+		}
 	}
-	m.log, err = logconf.New(m.Stdout)
+	if m.logFile != "" {
+		if m.logMaxSize < 0 || m.logMaxBackups < 0 || m.logMaxAge < 0 {
+			return fmt.Errorf("log-max-size, log-max-age and log-max-backups must not be negative")
+		}
+		m.logSink = &influxlogger.RotatingFile{
+			Path:       m.logFile,
+			MaxSize:    int64(m.logMaxSize) * 1024 * 1024,
+			MaxAge:     m.logMaxAge,
+			MaxBackups: m.logMaxBackups,
+		}
+		sinks = append(sinks, m.logSink)
+	}
+	// the sinks are synced with the logger, a failed write to one of them
+	// does not stop the others from being written.
+	m.log, err = logconf.New(zapcore.NewMultiWriteSyncer(sinks...))
 	if err != nil {
 		return err
 	}
 
+	platform.SetStrictIDDecoding(!m.lenientIDDecoding)
+
 	info := platform.GetBuildInfo()
 	m.log.Info("Welcome to InfluxDB",
 		zap.String("version", info.Version),
 		zap.String("commit", info.Commit),
 		zap.String("build_date", info.Date),
 	)
+	for _, u := range m.aliasUses {
+		m.log.Warn("Deprecated option set, use its replacement",
+			zap.String("option", u.Alias),
+			zap.String("replacement", u.Replacement),
+		)
+	}
+	if len(m.unknownConfigKeys) > 0 {
+		m.log.Warn("Ignoring unknown keys in config file",
+			zap.String("path", m.configPath),
+			zap.Strings("keys", m.unknownConfigKeys),
+		)
+	}
+
+	if m.reloadLogLevel == nil {
+		m.reloadLogLevel = func() (string, error) { return m.logLevel, nil }
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer m.handlePanic()
+		m.reloadLogLevelOnSignal(ctx)
+	}()
+
+	if m.tracingSampleRate > 1 {
+		return fmt.Errorf("invalid tracing-sample-rate %v; the rate is a fraction between 0.0 and 1.0", m.tracingSampleRate)
+	}
+
+	if m.metricsDetail != BasicMetrics && m.metricsDetail != FullMetrics {
+		return fmt.Errorf("invalid metrics-detail %q; supported details are %s, %s", m.metricsDetail, BasicMetrics, FullMetrics)
+	}
+
+	if m.storageRetentionInterval <= 0 {
+		return fmt.Errorf("invalid storage-retention-check-interval %s; the interval must be positive", m.storageRetentionInterval)
+	}
 
 	switch m.tracingType {
 	case LogTracing:
-		m.log.Info("Tracing via zap logging")
-		tracer := pzap.NewTracer(m.log, snowflake.NewIDGenerator())
+		rate := m.tracingSampleRate
+		if rate < 0 {
+			rate = 1
+		}
+		m.log.Info("Tracing via zap logging", zap.Float64("sample_rate", rate))
+		tracer := pzap.NewSampledTracer(m.log, snowflake.NewIDGenerator(), rate)
 		opentracing.SetGlobalTracer(tracer)
+		m.tracing = newLogTracingSettings(rate)
 
 	case JaegerTracing:
-		m.log.Info("Tracing via Jaeger")
-		cfg, err := jaegerconfig.FromEnv()
+		// tracing was asked for, running without it is an error.
+		cfg, err := m.jaegerConfig()
 		if err != nil {
-			m.log.Error("Failed to get Jaeger client config from environment variables", zap.Error(err))
-			break
+			return err
 		}
 		tracer, closer, err := cfg.NewTracer()
 		if err != nil {
-			m.log.Error("Failed to instantiate Jaeger tracer", zap.Error(err))
-			break
+			return fmt.Errorf("failed to instantiate Jaeger tracer: %v", err)
 		}
 		opentracing.SetGlobalTracer(tracer)
 		m.jaegerTracerCloser = closer
+
+		m.tracing = newJaegerTracingSettings(cfg)
+		m.log.Info("Tracing via Jaeger",
+			zap.String("service_name", m.tracing.ServiceName),
+			zap.String("sampler_type", m.tracing.SamplerType),
+			zap.Float64("sampler_param", m.tracing.SamplerParam),
+			zap.String("agent_host_port", m.tracing.AgentHostPort),
+			zap.String("collector_endpoint", m.tracing.CollectorEndpoint),
+		)
 	}
 
 	m.boltClient = bolt.NewClient(m.log.With(zap.String("service", "bolt")))
 	m.boltClient.Path = m.boltPath
+	m.boltClient.DetailedMetrics = m.metricsDetail == FullMetrics
 
 	if err := m.boltClient.Open(ctx); err != nil {
 		m.log.Error("Failed opening bolt", zap.Error(err))
@@ -488,7 +1203,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	serviceConfig := kv.ServiceConfig{
-		SessionLength: time.Duration(m.sessionLength) * time.Minute,
+		SessionLength:     time.Duration(m.sessionLength) * time.Minute,
+		VariableMaxValues: m.variableMaxValues,
 	}
 
 	flushers := flushers{}
@@ -504,7 +1220,11 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		store := inmem.NewKVStore()
 		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), store, serviceConfig)
 		if m.testing {
-			flushers = append(flushers, store)
+			flushers = append(flushers, &memoryStoreFlusher{
+				log:   m.log.With(zap.String("service", "kvstore-memory")),
+				store: store,
+				svc:   m.kvService,
+			})
 		}
 	default:
 		err := fmt.Errorf("unknown store type %s; expected bolt or memory", m.storeType)
@@ -512,17 +1232,26 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	m.readiness.begin(readyCheckKV)
 	if err := m.kvService.Initialize(ctx); err != nil {
 		m.log.Error("Failed to initialize kv service", zap.Error(err))
+		m.readiness.fail(readyCheckKV, err)
 		return err
 	}
+	m.readiness.pass(readyCheckKV)
 
 	m.reg = prom.NewRegistry(m.log.With(zap.String("service", "prom_registry")))
 	m.reg.MustRegister(
 		prometheus.NewGoCollector(),
+		infprom.NewProcessCollector(),
 		infprom.NewInfluxCollector(m.boltClient, info),
 	)
+	if m.metricsDetail == FullMetrics {
+		runtime.SetMutexProfileFraction(fullMetricsMutexProfileFraction)
+		m.reg.MustRegister(infprom.NewRuntimeCollector())
+	}
 	m.reg.MustRegister(m.boltClient)
+	m.reg.MustRegister(kv.NewResourceCounter(m.kvService))
 
 	var (
 		orgSvc                    platform.OrganizationService             = m.kvService
@@ -572,6 +1301,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	m.StorageConfig.RetentionInterval = toml.Duration(m.storageRetentionInterval)
+
 	if m.testing {
 		// the testing engine will write/read into a temporary directory
 		engine := NewTemporaryEngine(m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
@@ -581,29 +1312,37 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
 	}
 	m.engine.WithLogger(m.log)
+	m.readiness.begin(readyCheckEngine)
 	if err := m.engine.Open(ctx); err != nil {
 		m.log.Error("Failed to open engine", zap.Error(err))
+		m.readiness.fail(readyCheckEngine, err)
 		return err
 	}
+	m.readiness.pass(readyCheckEngine)
 	// The Engine's metrics must be registered after it opens.
 	m.reg.MustRegister(m.engine.PrometheusCollectors()...)
 
+	if !m.bucketCheckDisabled {
+		m.checkBucketConsistency(ctx, bucketSvc)
+	}
+
 	var (
 		deleteService platform.DeleteService = m.engine
 		pointsWriter  storage.PointsWriter   = m.engine
+		queryCache    *cache.Cache
 	)
-
-	// TODO(cwolff): Figure out a good default per-query memory limit:
-	//   https://github.com/influxdata/influxdb/issues/13642
-	const (
-		concurrencyQuota         = 10
-		memoryBytesQuotaPerQuery = math.MaxInt64
-		QueueSize                = 10
-	)
+	if m.queryCacheEnabled {
+		// every write and delete goes through the cache to invalidate the
+		// results it overlaps, including the writes of queries.
+		queryCache = cache.New(m.log.With(zap.String("service", "query-cache")), m.queryCacheConfig)
+		m.reg.MustRegister(queryCache.PrometheusCollectors()...)
+		deleteService = queryCache.WrapDeleteService(deleteService)
+		pointsWriter = queryCache.WrapPointsWriter(pointsWriter)
+	}
 
 	deps, err := influxdb.NewDependencies(
 		reads.NewReader(readservice.NewStore(m.engine)),
-		m.engine,
+		pointsWriter,
 		authorizer.NewBucketService(bucketSvc),
 		authorizer.NewOrgService(orgSvc),
 		authorizer.NewSecretService(secretSvc),
@@ -614,25 +1353,33 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
-	m.queryController, err = control.New(control.Config{
-		ConcurrencyQuota:         concurrencyQuota,
-		MemoryBytesQuotaPerQuery: int64(memoryBytesQuotaPerQuery),
-		QueueSize:                QueueSize,
-		Logger:                   m.log.With(zap.String("service", "storage-reads")),
-		ExecutorDependencies:     []flux.Dependency{deps},
-	})
+	// capture the notifications sent by notification rule tasks in the rule's history
+	if fdeps, ok := deps.FluxDeps.(flux.Deps); ok {
+		fdeps.Deps.HTTPClient = rule.NewHistoryHTTPClient(fdeps.Deps.HTTPClient)
+		fdeps.Deps.SecretService = rule.NewHistorySecretService(fdeps.Deps.SecretService)
+		deps.FluxDeps = fdeps
+	}
+
+	queryConfig.Logger = m.log.With(zap.String("service", "storage-reads"))
+	queryConfig.ExecutorDependencies = []flux.Dependency{deps}
+	m.queryController, err = control.New(queryConfig)
 	if err != nil {
 		m.log.Error("Failed to create query controller", zap.Error(err))
 		return err
 	}
+	logQueryControllerLimits(m.log, m.queryController.Config())
 
 	m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
 
-	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
+	var storageQueryService query.ProxyQueryService = readservice.NewProxyQueryService(m.queryController)
+	if queryCache != nil {
+		storageQueryService = cache.NewProxyQueryService(storageQueryService, bucketSvc, queryCache)
+	}
 	var taskSvc platform.TaskService
 	{
 		// create the task stack:
 		// validation(coordinator(analyticalstore(kv.Service)))
+		m.readiness.begin(readyCheckScheduler)
 		combinedTaskService := taskbackend.NewAnalyticalStorage(m.log.With(zap.String("service", "task-analytical-store")), m.kvService, m.kvService, m.kvService, pointsWriter, query.QueryServiceBridge{AsyncQueryService: m.queryController})
 		if m.EnableNewScheduler {
 			executor, executorMetrics := taskexecutor.NewExecutor(
@@ -642,6 +1389,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 				combinedTaskService,
 				combinedTaskService,
 			)
+			executor.SetNotificationRuleHistoryService(m.kvService)
+			executor.SetMaintenanceWindowMuter(m.kvService)
 			m.reg.MustRegister(executorMetrics.PrometheusCollectors()...)
 			schLogger := m.log.With(zap.String("service", "task-scheduler"))
 
@@ -660,6 +1409,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 				m.log.Fatal("could not start task scheduler", zap.Error(err))
 			}
 			m.treeScheduler = sch
+			m.readiness.pass(readyCheckScheduler)
 			m.reg.MustRegister(sm.PrometheusCollectors()...)
 			coordLogger := m.log.With(zap.String("service", "task-coordinator"))
 			taskCoord := coordinator.NewCoordinator(
@@ -685,10 +1435,13 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 			// define the executor and build analytical storage middleware
 			executor := taskexecutor.NewAsyncQueryServiceExecutor(m.log.With(zap.String("service", "task-executor")), m.queryController, authSvc, combinedTaskService)
+			taskexecutor.AddNotificationRuleHistoryService(executor, m.kvService)
+			taskexecutor.AddMaintenanceWindowMuter(executor, m.kvService)
 
 			// create the scheduler
 			m.scheduler = taskbackend.NewScheduler(m.log.With(zap.String("svc", "taskd/scheduler")), combinedTaskService, executor, time.Now().UTC().Unix(), taskbackend.WithTicker(ctx, 100*time.Millisecond))
 			m.scheduler.Start(ctx)
+			m.readiness.pass(readyCheckScheduler)
 			m.reg.MustRegister(m.scheduler.PrometheusCollectors()...)
 
 			logger := m.log.With(zap.String("service", "task-coordinator"))
@@ -720,43 +1473,26 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 	// NATS streaming server
 	natsOpts := nats.NewDefaultServerOptions()
-	nextPort := int64(4222)
-
-	// Welcome to ghetto land. It doesn't seem possible to tell NATS to initialise
-	// a random port. In some integration-style tests, this launcher gets initialised
-	// multiple times, and sometimes the port from the previous instantiation is
-	// still open.
-	//
-	// This atrocity checks if the port is free, and if it's not, moves on to the
-	// next one.
-	var total int
-	for {
-		l, err := net.Listen("tcp", fmt.Sprintf(":%d", nextPort))
-		if err == nil {
-			if err := l.Close(); err != nil {
-				return err
-			}
-			break
-		}
-		time.Sleep(time.Second)
-		nextPort++
-		total++
-		if total > 50 {
-			return errors.New("unable to find free port for Nats server")
-		}
+	natsPort, err := resolvePort(m.natsPort)
+	if err != nil {
+		m.log.Error("Failed to find free port for nats streaming server", zap.Error(err))
+		return err
 	}
-	natsOpts.Port = int(nextPort)
+	natsOpts.Port = natsPort
 	m.natsServer = nats.NewServer(&natsOpts)
-	m.natsPort = int(nextPort)
+	m.natsPort = natsPort
 
+	m.readiness.begin(readyCheckNATS)
 	if err := m.natsServer.Open(); err != nil {
 		m.log.Error("Failed to start nats streaming server", zap.Error(err))
+		m.readiness.fail(readyCheckNATS, err)
 		return err
 	}
 
 	publisher := nats.NewAsyncPublisher(m.log, fmt.Sprintf("nats-publisher-%d", m.natsPort), m.NatsURL())
 	if err := publisher.Open(); err != nil {
 		m.log.Error("Failed to connect to streaming server", zap.Error(err))
+		m.readiness.fail(readyCheckNATS, err)
 		return err
 	}
 
@@ -764,8 +1500,10 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	subscriber := nats.NewQueueSubscriber(fmt.Sprintf("nats-subscriber-%d", m.natsPort), m.NatsURL())
 	if err := subscriber.Open(); err != nil {
 		m.log.Error("Failed to connect to streaming server", zap.Error(err))
+		m.readiness.fail(readyCheckNATS, err)
 		return err
 	}
+	m.readiness.pass(readyCheckNATS)
 
 	subscriber.Subscribe(gather.MetricsSubject, "metrics", gather.NewRecorderHandler(m.log, gather.PointWriter{Writer: pointsWriter}))
 	scraperScheduler, err := gather.NewScheduler(m.log, 10, scraperTargetSvc, publisher, subscriber, 10*time.Second, 30*time.Second)
@@ -777,6 +1515,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	m.wg.Add(1)
 	go func(log *zap.Logger) {
 		defer m.wg.Done()
+		defer m.handlePanic()
 		log = log.With(zap.String("service", "scraper"))
 		if err := scraperScheduler.Run(ctx); err != nil {
 			log.Error("Failed scraper service", zap.Error(err))
@@ -784,8 +1523,29 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		log.Info("Stopping")
 	}(m.log)
 
+	m.wg.Add(1)
+	go func(log *zap.Logger) {
+		defer m.wg.Done()
+		defer m.handlePanic()
+		m.cleanupMaintenanceWindows(ctx, log.With(zap.String("service", "maintenance-windows")))
+	}(m.log)
+
+	userActivity := http.NewUserActivityRecorder(m.kvService, http.DefaultUserActivityInterval)
+	m.wg.Add(1)
+	go func(log *zap.Logger) {
+		defer m.wg.Done()
+		defer m.handlePanic()
+		userActivity.Run(ctx, log.With(zap.String("service", "user-activity")), time.Minute)
+	}(m.log)
+
+	// the write timeout runs from the end of the request headers, it bounds
+	// queries as well, its default gives a 5 minute query time to respond.
 	m.httpServer = &nethttp.Server{
-		Addr: m.httpBindAddress,
+		Addr:              m.httpBindAddress,
+		ReadTimeout:       m.httpReadTimeout,
+		ReadHeaderTimeout: m.httpReadHeaderTimeout,
+		WriteTimeout:      m.httpWriteTimeout,
+		IdleTimeout:       m.httpIdleTimeout,
 	}
 
 	m.apibackend = &http.APIBackend{
@@ -793,6 +1553,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		HTTPErrorHandler:     http.ErrorHandler(0),
 		Logger:               m.log,
 		SessionRenewDisabled: m.sessionRenewDisabled,
+		RequestTimeout:       m.httpRequestTimeout,
+		AdvertisedURL:        m.httpAdvertisedURL,
 		NewBucketService:     source.NewBucketService,
 		NewQueryService:      source.NewQueryService,
 		PointsWriter:         pointsWriter,
@@ -819,6 +1581,10 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		TaskService:                     taskSvc,
 		TelegrafService:                 telegrafSvc,
 		NotificationRuleStore:           notificationRuleSvc,
+		NotificationRuleHistoryService:  m.kvService,
+		MaintenanceWindowService:        m.kvService,
+		UserActivityService:             m.kvService,
+		UserActivityRecorder:            userActivity,
 		NotificationEndpointService:     endpoints.NewService(notificationEndpointStore, secretSvc, userResourceSvc, orgSvc),
 		CheckService:                    checkSvc,
 		ScraperTargetStoreService:       scraperTargetSvc,
@@ -833,44 +1599,108 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 	m.reg.MustRegister(m.apibackend.PrometheusCollectors()...)
 
+	pkgerOpts := []pkger.ServiceSetterFn{
+		pkger.WithLogger(m.log.With(zap.String("service", "pkger"))),
+	}
+	if !m.reportingDisabled {
+		templateUsage := telemetry.NewTemplateUsage()
+		m.reg.MustRegister(templateUsage.PrometheusCollectors()...)
+		pkgerOpts = append(pkgerOpts, pkger.WithUsageReporter(templateUsage))
+	}
+
 	var pkgSVC pkger.SVC
 	{
 		b := m.apibackend
-		pkgSVC = pkger.NewService(
-			pkger.WithLogger(m.log.With(zap.String("service", "pkger"))),
+		svc := pkger.NewService(append(pkgerOpts,
 			pkger.WithBucketSVC(authorizer.NewBucketService(b.BucketService)),
+			pkger.WithCheckSVC(authorizer.NewCheckService(b.CheckService, b.UserResourceMappingService, b.OrganizationService)),
 			pkger.WithDashboardSVC(authorizer.NewDashboardService(b.DashboardService)),
 			pkger.WithLabelSVC(authorizer.NewLabelService(b.LabelService)),
 			pkger.WithNoticationEndpointSVC(authorizer.NewNotificationEndpointService(b.NotificationEndpointService, b.UserResourceMappingService, b.OrganizationService)),
+			pkger.WithNotificationRuleSVC(authorizer.NewNotificationRuleStore(b.NotificationRuleStore, b.UserResourceMappingService, b.OrganizationService)),
+			pkger.WithScraperTargetSVC(authorizer.NewScraperTargetStoreService(b.ScraperTargetStoreService, b.UserResourceMappingService, b.OrganizationService)),
 			pkger.WithSecretSVC(authorizer.NewSecretService(b.SecretService)),
+			pkger.WithTaskSVC(b.TaskService),
 			pkger.WithTelegrafSVC(authorizer.NewTelegrafConfigService(b.TelegrafService, b.UserResourceMappingService)),
 			pkger.WithVariableSVC(authorizer.NewVariableService(b.VariableService)),
-		)
+			pkger.WithOrganizationOperationLog(m.kvService),
+		)...)
+		m.reg.MustRegister(svc.PrometheusCollectors()...)
+		pkgSVC = svc
 	}
 
+	m.pkgerSVC = pkgSVC
+
 	var pkgHTTPServer *http.HandlerPkg
 	{
 		pkgServerLogger := m.log.With(zap.String("handler", "pkger"))
 		pkgHTTPServer = http.NewHandlerPkg(pkgServerLogger, m.apibackend.HTTPErrorHandler, pkgSVC)
 	}
 
+	for _, fn := range m.preRunFns {
+		if err := fn(ctx, m); err != nil {
+			m.log.Error("Failed pre run hook", zap.Error(err))
+			return err
+		}
+	}
+
+	resourceHandlerOpts := []http.APIHandlerOptFn{http.WithResourceHandler(pkgHTTPServer)}
+	for _, h := range m.resourceHandlers {
+		resourceHandlerOpts = append(resourceHandlerOpts, http.WithResourceHandler(h))
+	}
+
 	// HTTP server
-	var platformHandler nethttp.Handler = http.NewPlatformHandler(m.apibackend, http.WithResourceHandler(pkgHTTPServer))
+	var platformHandler nethttp.Handler = http.NewPlatformHandler(m.apibackend, resourceHandlerOpts...)
 	m.reg.MustRegister(platformHandler.(*http.PlatformHandler).PrometheusCollectors()...)
 	httpLogger := m.log.With(zap.String("service", "http"))
-	if logconf.Level == zap.DebugLevel {
-		platformHandler = http.LoggingMW(httpLogger)(platformHandler)
-	}
+	// the requests are logged while the log level is debug.
+	platformHandler = debugLoggingMW(m.level, httpLogger, platformHandler)
 
 	handler := http.NewHandlerFromRegistry(httpLogger, "platform", m.reg)
 	handler.Handler = platformHandler
+	handler.ReadyHandler = m.readiness
+	if !m.pprofEnabled {
+		handler.DebugHandler = nethttp.NotFoundHandler()
+	}
+	debugHandler := nethttp.NewServeMux()
+	if !m.reportingDisabled {
+		// the telemetry pending the next report can be inspected at /debug/telemetry.
+		debugHandler.Handle("/debug/telemetry", telemetry.DebugHandler(m.reg))
+	}
+	// the tracing in use can be inspected at /debug/tracing.
+	debugHandler.Handle(tracingDebugPath, tracingDebugHandler(m.tracing))
+	// the log level can be read and set at /debug/log-level, it is set with an
+	// operator token.
+	debugHandler.Handle(logLevelDebugPath, m.logLevelHandler(httpLogger.With(zap.String("handler", "log-level"))))
+	debugHandler.Handle("/", handler.DebugHandler)
+	handler.DebugHandler = debugHandler
 
-	m.httpServer.Handler = handler
 	// If we are in testing mode we allow all data to be flushed and removed.
 	if m.testing {
-		m.httpServer.Handler = http.DebugFlush(ctx, handler, flushers)
+		handler.DebugHandler = http.DebugPanic(http.DebugFlush(ctx, handler.DebugHandler, flushers))
+	}
+
+	// the metrics and debug endpoints are moved off the API when they have
+	// their own bind address, the registry still backs the metrics.
+	if m.metricsBindAddress != "" {
+		if err := m.runMetricsServer(handler.MetricsHandler, handler.DebugHandler); err != nil {
+			return err
+		}
+		handler.MetricsHandler = nethttp.NotFoundHandler()
+		handler.DebugHandler = nethttp.NotFoundHandler()
 	}
 
+	// the profile bundles are authenticated, they stay on the API when the
+	// debug endpoints are moved off it.
+	if m.profilesEnabled {
+		profilesHandler := nethttp.NewServeMux()
+		profilesHandler.Handle(profilesDebugPath, m.profileBundleHandler(httpLogger.With(zap.String("handler", "profiles"))))
+		profilesHandler.Handle("/", handler.DebugHandler)
+		handler.DebugHandler = profilesHandler
+	}
+
+	m.httpServer.Handler = m.panicMW(handler)
+
 	ln, err := net.Listen("tcp", m.httpBindAddress)
 	if err != nil {
 		httpLogger.Error("failed http listener", zap.Error(err))
@@ -892,7 +1722,13 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		}
 		transport = "https"
 
-		m.httpServer.TLSConfig = &tls.Config{}
+		m.httpServer.TLSConfig, err = m.tlsConfig()
+		if err != nil {
+			httpLogger.Error("failed to configure TLS", zap.Error(err))
+			httpLogger.Info("Stopping")
+			return err
+		}
+		logTLSConfig(httpLogger, m.httpTLSMinVersion, m.httpServer.TLSConfig)
 	}
 
 	if addr, ok := ln.Addr().(*net.TCPAddr); ok {
@@ -902,6 +1738,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	m.wg.Add(1)
 	go func(log *zap.Logger) {
 		defer m.wg.Done()
+		defer m.handlePanic()
 		log.Info("Listening", zap.String("transport", transport), zap.String("addr", m.httpBindAddress), zap.Int("port", m.httpPort))
 
 		if cer.Certificate != nil {
@@ -969,3 +1806,44 @@ func (m *Launcher) TaskScheduler() taskbackend.Scheduler {
 func (m *Launcher) KeyValueService() *kv.Service {
 	return m.kvService
 }
+
+// DashboardService returns the internal dashboard service.
+func (m *Launcher) DashboardService() platform.DashboardService {
+	return m.apibackend.DashboardService
+}
+
+// LabelService returns the internal label service.
+func (m *Launcher) LabelService() platform.LabelService {
+	return m.apibackend.LabelService
+}
+
+// CheckService returns the internal check service.
+func (m *Launcher) CheckService() platform.CheckService {
+	return m.apibackend.CheckService
+}
+
+// NotificationEndpointService returns the internal notification endpoint service.
+func (m *Launcher) NotificationEndpointService() platform.NotificationEndpointService {
+	return m.apibackend.NotificationEndpointService
+}
+
+// NotificationRuleService returns the internal notification rule service.
+func (m *Launcher) NotificationRuleService() platform.NotificationRuleStore {
+	return m.apibackend.NotificationRuleStore
+}
+
+// TelegrafService returns the internal telegraf config service.
+func (m *Launcher) TelegrafService() platform.TelegrafConfigStore {
+	return m.apibackend.TelegrafService
+}
+
+// VariableService returns the internal variable service.
+func (m *Launcher) VariableService() platform.VariableService {
+	return m.apibackend.VariableService
+}
+
+// PkgerService returns the internal pkger service. Its services are wrapped by
+// an authorizer, as for the http api, so calls require an authorizer on the ctx.
+func (m *Launcher) PkgerService() pkger.SVC {
+	return m.pkgerSVC
+}
@@ -48,8 +48,8 @@ type TestLauncher struct {
 }
 
 // NewTestLauncher returns a new instance of TestLauncher.
-func NewTestLauncher() *TestLauncher {
-	l := &TestLauncher{Launcher: NewLauncher()}
+func NewTestLauncher(opts ...OptFn) *TestLauncher {
+	l := &TestLauncher{Launcher: NewLauncher(opts...)}
 	l.Launcher.Stdin = &l.Stdin
 	l.Launcher.Stdout = &l.Stdout
 	l.Launcher.Stderr = &l.Stderr
@@ -82,6 +82,7 @@ func (tl *TestLauncher) Run(ctx context.Context, args ...string) error {
 	args = append(args, "--bolt-path", filepath.Join(tl.Path, "influxd.bolt"))
 	args = append(args, "--engine-path", filepath.Join(tl.Path, "engine"))
 	args = append(args, "--http-bind-address", "127.0.0.1:0")
+	args = append(args, "--nats-port", "0")
 	args = append(args, "--log-level", "debug")
 	return tl.Launcher.Run(ctx, args...)
 }
@@ -89,8 +90,11 @@ func (tl *TestLauncher) Run(ctx context.Context, args ...string) error {
 // Shutdown stops the program and cleans up temporary paths.
 func (tl *TestLauncher) Shutdown(ctx context.Context) error {
 	tl.Cancel()
-	tl.Launcher.Shutdown(ctx)
-	return os.RemoveAll(tl.Path)
+	err := tl.Launcher.Shutdown(ctx)
+	if rerr := os.RemoveAll(tl.Path); err == nil {
+		err = rerr
+	}
+	return err
 }
 
 // ShutdownOrFail stops the program and cleans up temporary paths. Fail on error.
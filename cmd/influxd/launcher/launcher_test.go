@@ -1,16 +1,35 @@
 package launcher_test
 
 import (
+	"archive/tar"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/influxdata/flux/parser"
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/cmd/influxd/launcher"
+	icontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/check"
+	"github.com/influxdata/influxdb/pkger"
 	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 // Default context.
@@ -139,3 +158,861 @@ func TestLauncher_SetupWithUsers(t *testing.T) {
 		t.Fatalf("unexpected 2 users: %#+v", exp)
 	}
 }
+
+func TestLauncher_PreRun(t *testing.T) {
+	var labelSVC platform.LabelService
+	l := launcher.NewTestLauncher(launcher.WithPreRun(func(ctx context.Context, m *launcher.Launcher) error {
+		labelSVC = m.LabelService()
+		m.RegisterResourceHandler(&labelCountHandler{labelSVC: labelSVC})
+		return nil
+	}))
+	if err := l.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	require.NotNil(t, labelSVC)
+	require.NotNil(t, l.Launcher.PkgerService())
+	require.NotNil(t, l.Launcher.TelegrafService())
+	require.NotNil(t, l.Launcher.NotificationEndpointService())
+
+	err := labelSVC.CreateLabel(ctx, &platform.Label{OrgID: l.Org.ID, Name: "label_1"})
+	require.NoError(t, err)
+
+	t.Run("custom resource handler is served", func(t *testing.T) {
+		req := l.NewHTTPRequestOrFail(t, "GET", "/api/v2/labelcount", l.Auth.Token, "")
+		resp, err := nethttp.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, nethttp.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Count int `json:"count"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, 1, body.Count)
+	})
+
+	t.Run("pkger is served alongside custom handlers", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		pkg, err := l.PkgerService(t).CreatePkg(ctx, pkger.CreateWithMetadata(pkger.Metadata{
+			Name:    "pkg_name",
+			Version: "1",
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "pkg_name", pkg.Metadata.Name)
+	})
+}
+
+func TestLauncher_TemplateTelemetry(t *testing.T) {
+	getTelemetry := func(t *testing.T, l *launcher.TestLauncher) (int, string) {
+		t.Helper()
+
+		resp, err := nethttp.Get(l.URL() + "/debug/telemetry")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("applied templates are pending in the telemetry", func(t *testing.T) {
+		l := launcher.RunTestLauncherOrFail(t, ctx)
+		l.SetupOrFail(t)
+		defer l.ShutdownOrFail(t, ctx)
+
+		_, err := l.PkgerService(t).Apply(ctx, l.Org.ID, l.User.ID, newPkg(t))
+		require.NoError(t, err)
+
+		code, body := getTelemetry(t, l)
+		require.Equal(t, nethttp.StatusOK, code)
+		assert.Contains(t, body, "influxdb_templates_applied_total")
+		assert.Contains(t, body, `kind="bucket"`)
+		assert.NotContains(t, body, "pkg_name")
+		assert.NotContains(t, body, l.Org.ID.String())
+	})
+
+	t.Run("reporting disabled", func(t *testing.T) {
+		l := launcher.RunTestLauncherOrFail(t, ctx, "--reporting-disabled")
+		l.SetupOrFail(t)
+		defer l.ShutdownOrFail(t, ctx)
+
+		_, err := l.PkgerService(t).Apply(ctx, l.Org.ID, l.User.ID, newPkg(t))
+		require.NoError(t, err)
+
+		code, _ := getTelemetry(t, l)
+		assert.Equal(t, nethttp.StatusNotFound, code)
+
+		resp, err := nethttp.Get(l.URL() + "/metrics")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		metrics, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.NotContains(t, string(metrics), "influxdb_templates_applied_total")
+	})
+}
+
+func TestLauncher_CheckService(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	every, err := parser.ParseDuration("1m")
+	require.NoError(t, err)
+
+	c := &check.Deadman{
+		Base: check.Base{
+			Name:                  "check_1",
+			OrgID:                 l.Org.ID,
+			Every:                 (*notification.Duration)(every),
+			StatusMessageTemplate: "msg",
+			Query: platform.DashboardQuery{
+				Text: `from(bucket: "BUCKET") |> range(start: -1m) |> filter(fn: (r) => r._field == "usage_user")`,
+				BuilderConfig: platform.BuilderConfig{
+					Tags: []struct {
+						Key    string   `json:"key"`
+						Values []string `json:"values"`
+					}{
+						{Key: "_field", Values: []string{"usage_user"}},
+					},
+				},
+			},
+		},
+		TimeSince: (*notification.Duration)(every),
+		StaleTime: (*notification.Duration)(every),
+		Level:     notification.Critical,
+	}
+	err = l.Launcher.CheckService().CreateCheck(ctx, platform.CheckCreate{Check: c, Status: platform.Active}, l.User.ID)
+	require.NoError(t, err)
+
+	req := l.NewHTTPRequestOrFail(t, "GET", "/api/v2/checks/"+c.ID.String(), l.Auth.Token, "")
+	resp, err := nethttp.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, nethttp.StatusOK, resp.StatusCode)
+
+	var got struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	assert.Equal(t, c.ID.String(), got.ID)
+	assert.Equal(t, "check_1", got.Name)
+}
+
+func TestLauncher_PkgerService(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	newBucketPkg := func(t *testing.T) *pkger.Pkg {
+		t.Helper()
+
+		pkg, err := pkger.Parse(pkger.EncodingYAML, pkger.FromString(`apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Bucket
+      name: rucket_1
+      description: desc_1
+`))
+		require.NoError(t, err)
+		return pkg
+	}
+
+	// the services of the pkger service are wrapped by an authorizer, as they
+	// are for the requests of the http api.
+	svc := l.Launcher.PkgerService()
+
+	t.Run("applies a pkg", func(t *testing.T) {
+		authCtx := icontext.SetAuthorizer(ctx, l.Auth)
+
+		sum, err := svc.Apply(authCtx, l.Org.ID, l.User.ID, newBucketPkg(t))
+		require.NoError(t, err)
+		require.Len(t, sum.Buckets, 1)
+
+		b, err := l.Launcher.BucketService().FindBucketByName(ctx, l.Org.ID, "rucket_1")
+		require.NoError(t, err)
+		assert.Equal(t, platform.ID(sum.Buckets[0].ID), b.ID)
+		assert.Equal(t, "desc_1", b.Description)
+	})
+
+	t.Run("dry run reports a token lacking bucket write", func(t *testing.T) {
+		p, err := platform.NewPermission(platform.ReadAction, platform.BucketsResourceType, l.Org.ID)
+		require.NoError(t, err)
+		authCtx := icontext.SetAuthorizer(ctx, &platform.Authorization{
+			Status:      platform.Active,
+			OrgID:       l.Org.ID,
+			UserID:      l.User.ID,
+			Permissions: []platform.Permission{*p},
+		})
+
+		_, _, err = svc.DryRun(authCtx, l.Org.ID, l.User.ID, newBucketPkg(t))
+		require.Error(t, err)
+		assert.Equal(t, platform.EUnauthorized, platform.ErrorCode(err))
+		assert.Contains(t, err.Error(), "write:orgs/"+l.Org.ID.String()+"/buckets")
+	})
+}
+
+func TestLauncher_HTTPReadTimeout(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--http-read-timeout", "500ms")
+	defer l.ShutdownOrFail(t, ctx)
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(l.URL(), "http://"))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// a slow client that never finishes sending the headers of its request
+	_, err = conn.Write([]byte("GET /api/v2/setup HTTP/1.1\r\nHost: localhost\r\n"))
+	require.NoError(t, err)
+
+	start := time.Now()
+	require.NoError(t, conn.SetReadDeadline(start.Add(10*time.Second)))
+	_, err = ioutil.ReadAll(conn)
+	require.NoError(t, err, "exp the server to close the connection")
+	assert.True(t, time.Since(start) < 5*time.Second, "exp the request to be cut off after the read timeout, took %s", time.Since(start))
+}
+
+func TestLauncher_HTTPTimeouts(t *testing.T) {
+	t.Run("defaults give a 5 minute query time to complete", func(t *testing.T) {
+		l := launcher.RunTestLauncherOrFail(t, ctx)
+		defer l.ShutdownOrFail(t, ctx)
+
+		s := l.Launcher.HTTPServer()
+		assert.Equal(t, 30*time.Second, s.ReadTimeout)
+		assert.Equal(t, time.Duration(0), s.ReadHeaderTimeout, "exp the header reads to fall back to the read timeout")
+		assert.Equal(t, 3*time.Minute, s.IdleTimeout)
+		assert.True(t, s.WriteTimeout > 5*time.Minute, "exp the write timeout to outlast a 5 minute query, got %s", s.WriteTimeout)
+	})
+
+	t.Run("a query outlasting the read and idle timeouts completes", func(t *testing.T) {
+		// the timeouts are scaled down, the query only has to complete within
+		// the write timeout.
+		l := launcher.RunTestLauncherOrFail(t, ctx,
+			"--http-read-header-timeout", "100ms",
+			"--http-read-timeout", "200ms",
+			"--http-idle-timeout", "200ms",
+			"--http-write-timeout", "1m",
+		)
+		defer l.ShutdownOrFail(t, ctx)
+		l.SetupOrFail(t)
+		l.WritePointsOrFail(t, fmt.Sprintf(`m,k=v1 f=1i %d`, time.Now().UnixNano()))
+
+		qs := `from(bucket:"BUCKET") |> range(start:-5m) |> sleep(duration: 1s) |> keep(columns: ["_value"])`
+		start := time.Now()
+		res := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, qs)
+		assert.True(t, time.Since(start) >= time.Second, "exp the query to run for the duration of the sleep")
+		assert.Contains(t, res, ",_result,0,1")
+	})
+}
+
+func TestLauncher_NatsPort(t *testing.T) {
+	l1 := launcher.RunTestLauncherOrFail(t, ctx)
+	defer l1.ShutdownOrFail(t, ctx)
+	l2 := launcher.RunTestLauncherOrFail(t, ctx)
+	defer l2.ShutdownOrFail(t, ctx)
+
+	assert.NotEqual(t, l1.NatsURL(), l2.NatsURL())
+	for _, u := range []string{l1.NatsURL(), l2.NatsURL()} {
+		conn, err := net.Dial("tcp", strings.TrimPrefix(u, "http://"))
+		require.NoError(t, err, "exp nats to listen at %s", u)
+		conn.Close()
+	}
+}
+
+func TestLauncher_PanicDiagnostics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influxd-diagnostics-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l := launcher.RunTestLauncherOrFail(t, ctx,
+		"--e2e-testing",
+		"--diagnostics-dir", dir,
+		"--vault-token", "not_a_real_token",
+	)
+	defer l.ShutdownOrFail(t, ctx)
+
+	_, err = nethttp.Get(l.URL() + "/debug/panic")
+	require.Error(t, err, "exp the connection of the panicking request to be closed")
+
+	fis, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, fis, 1)
+	assert.True(t, strings.HasPrefix(fis[0].Name(), "influxd-diagnostics-"))
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, fis[0].Name()))
+	require.NoError(t, err)
+	bundle := string(b)
+
+	for _, s := range []string{
+		"panic: debug panic",
+		"version: ",
+		"diagnostics-dir = " + dir,
+		"vault-token = [REDACTED]",
+		"== goroutines ==",
+		"http.DebugPanic",
+		"Welcome to InfluxDB",
+		"== metrics ==",
+		"# TYPE ",
+	} {
+		assert.Contains(t, bundle, s)
+	}
+	assert.NotContains(t, bundle, "not_a_real_token")
+}
+
+func TestLauncher_ShutdownDrainsRequests(t *testing.T) {
+	h := &slowHandler{
+		started: make(chan struct{}),
+		delay:   500 * time.Millisecond,
+	}
+	l := launcher.NewTestLauncher(launcher.WithPreRun(func(ctx context.Context, m *launcher.Launcher) error {
+		m.RegisterResourceHandler(h)
+		return nil
+	}))
+	require.NoError(t, l.Run(ctx))
+	l.SetupOrFail(t)
+
+	type result struct {
+		code int
+		err  error
+	}
+	done := make(chan result, 1)
+	req := l.NewHTTPRequestOrFail(t, "GET", h.Prefix(), l.Auth.Token, "")
+	go func() {
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		resp.Body.Close()
+		done <- result{code: resp.StatusCode}
+	}()
+	<-h.started
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	require.NoError(t, l.Shutdown(shutdownCtx))
+	assert.True(t, time.Since(start) < 5*time.Second, "exp shutdown within the timeout, took %s", time.Since(start))
+
+	res := <-done
+	require.NoError(t, res.err, "exp the in-flight request to complete")
+	assert.Equal(t, nethttp.StatusOK, res.code)
+}
+
+func TestLauncher_ShutdownReportsUndrained(t *testing.T) {
+	h := &slowHandler{
+		started: make(chan struct{}),
+		delay:   2 * time.Second,
+	}
+	l := launcher.NewTestLauncher(launcher.WithPreRun(func(ctx context.Context, m *launcher.Launcher) error {
+		m.RegisterResourceHandler(h)
+		return nil
+	}))
+	require.NoError(t, l.Run(ctx))
+	l.SetupOrFail(t)
+
+	req := l.NewHTTPRequestOrFail(t, "GET", h.Prefix(), l.Auth.Token, "")
+	go func() {
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-h.started
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	err := l.Shutdown(shutdownCtx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http")
+}
+
+// slowHandler takes its delay to respond.
+type slowHandler struct {
+	once    sync.Once
+	started chan struct{}
+	delay   time.Duration
+}
+
+func (h *slowHandler) Prefix() string {
+	return "/api/v2/slow"
+}
+
+func (h *slowHandler) ServeHTTP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	h.once.Do(func() { close(h.started) })
+	time.Sleep(h.delay)
+	w.WriteHeader(nethttp.StatusOK)
+}
+
+func TestLauncher_TelemetryOptions(t *testing.T) {
+	info := platform.GetBuildInfo()
+	defer platform.SetBuildInfo(info.Version, info.Commit, info.Date)
+
+	run := func(args ...string) error {
+		l := launcher.NewTestLauncher()
+		defer os.RemoveAll(l.Path)
+		return l.Run(ctx, args...)
+	}
+
+	platform.SetBuildInfo("2.0.0", "abc123", "2020-01-01")
+	err := run("--telemetry-interval", "10s")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "telemetry-interval")
+
+	err = run("--telemetry-interval", "0s")
+	require.Error(t, err)
+
+	// reporting-disabled is the master switch, the options are not used
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--reporting-disabled", "--telemetry-interval", "10s")
+	l.ShutdownOrFail(t, ctx)
+
+	// dev builds may report as often as they like
+	platform.SetBuildInfo("dev", "", "")
+	l = launcher.RunTestLauncherOrFail(t, ctx, "--telemetry-interval", "10s", "--telemetry-endpoint", "http://127.0.0.1:1/metrics/job/influxdb")
+	l.ShutdownOrFail(t, ctx)
+}
+
+func TestLauncher_TLSOptions(t *testing.T) {
+	run := func(args ...string) error {
+		l := launcher.NewTestLauncher()
+		defer os.RemoveAll(l.Path)
+		return l.Run(ctx, args...)
+	}
+
+	err := run("--tls-min-version", "1.4")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tls-min-version")
+
+	err = run("--tls-client-ca", "ca.pem")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tls-client-ca")
+
+	// the TLS options are validated, but not used, without a cert and key
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--tls-min-version", "1.3", "--tls-strict-ciphers")
+	l.ShutdownOrFail(t, ctx)
+}
+
+func TestLauncher_MetricsBindAddress(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--metrics-bind-address", "127.0.0.1:0")
+	defer l.ShutdownOrFail(t, ctx)
+
+	get := func(url string) int {
+		t.Helper()
+		resp, err := nethttp.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.NotEmpty(t, l.MetricsURL())
+	assert.Equal(t, nethttp.StatusOK, get(l.MetricsURL()+"/metrics"))
+	assert.Equal(t, nethttp.StatusOK, get(l.MetricsURL()+"/debug/pprof/"))
+	assert.Equal(t, nethttp.StatusNotFound, get(l.MetricsURL()+"/api/v2"))
+
+	assert.Equal(t, nethttp.StatusNotFound, get(l.URL()+"/metrics"))
+	assert.Equal(t, nethttp.StatusNotFound, get(l.URL()+"/debug/pprof/"))
+	assert.Equal(t, nethttp.StatusOK, get(l.URL()+"/health"))
+}
+
+func TestLauncher_Pprof(t *testing.T) {
+	get := func(url string) int {
+		t.Helper()
+		resp, err := nethttp.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	assert.Equal(t, nethttp.StatusOK, get(l.URL()+"/debug/pprof/"))
+	l.ShutdownOrFail(t, ctx)
+
+	l = launcher.RunTestLauncherOrFail(t, ctx, "--pprof-enabled=false", "--metrics-bind-address", "127.0.0.1:0")
+	defer l.ShutdownOrFail(t, ctx)
+	assert.Equal(t, nethttp.StatusNotFound, get(l.URL()+"/debug/pprof/"))
+	assert.Equal(t, nethttp.StatusNotFound, get(l.MetricsURL()+"/debug/pprof/"))
+	assert.Equal(t, nethttp.StatusNotFound, get(l.MetricsURL()+"/debug/pprof/heap"))
+	assert.Equal(t, nethttp.StatusOK, get(l.MetricsURL()+"/metrics"))
+}
+
+func TestLauncher_QueryControllerLimits(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx,
+		"--query-concurrency", "3",
+		"--query-memory-bytes", "1048576",
+		"--query-queue-size", "7",
+	)
+	defer l.ShutdownOrFail(t, ctx)
+
+	c := l.QueryController().Config()
+	assert.Equal(t, 3, c.ConcurrencyQuota)
+	assert.Equal(t, int64(1048576), c.MemoryBytesQuotaPerQuery)
+	assert.Equal(t, 7, c.QueueSize)
+
+	for _, args := range [][]string{
+		{"--query-concurrency", "2", "--query-queue-size", "0"},
+		{"--query-concurrency", "0"},
+		{"--query-memory-bytes", "-1"},
+	} {
+		l := launcher.NewTestLauncher()
+		err := l.Run(ctx, args...)
+		os.RemoveAll(l.Path)
+		assert.Error(t, err, "exp %v to be invalid", args)
+	}
+}
+
+func TestLauncher_Ready(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	defer l.ShutdownOrFail(t, ctx)
+
+	resp, err := nethttp.Get(l.URL() + "/ready")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, nethttp.StatusOK, resp.StatusCode)
+
+	var ready struct {
+		Status string `json:"status"`
+		Checks []struct {
+			Name     string `json:"name"`
+			Status   string `json:"status"`
+			Duration string `json:"duration"`
+		} `json:"checks"`
+		Failing []string `json:"failing"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ready))
+	assert.Equal(t, "ready", ready.Status)
+	assert.Empty(t, ready.Failing)
+
+	var names []string
+	for _, c := range ready.Checks {
+		names = append(names, c.Name)
+		assert.Equal(t, "pass", c.Status, c.Name)
+		assert.NotEmpty(t, c.Duration, c.Name)
+	}
+	assert.Equal(t, []string{"kv", "engine", "task_scheduler", "nats"}, names)
+}
+
+func TestLauncher_JaegerTracing(t *testing.T) {
+	run := func(args ...string) error {
+		l := launcher.NewTestLauncher()
+		defer os.RemoveAll(l.Path)
+		return l.Run(ctx, args...)
+	}
+
+	err := run("--tracing-type", "jaeger", "--jaeger-sampler-type", "sometimes")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "jaeger-sampler-type")
+
+	err = run("--tracing-type", "jaeger", "--jaeger-agent-host-port", "localhost")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "jaeger-agent-host-port")
+
+	l := launcher.RunTestLauncherOrFail(t, ctx,
+		"--tracing-type", "jaeger",
+		"--jaeger-service-name", "influxd-test",
+		"--jaeger-sampler-type", "const",
+		"--jaeger-sampler-param", "1",
+		"--jaeger-agent-host-port", "127.0.0.1:6831",
+	)
+	defer l.ShutdownOrFail(t, ctx)
+
+	resp, err := nethttp.Get(l.URL() + "/debug/tracing")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, nethttp.StatusOK, resp.StatusCode)
+
+	var settings map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&settings))
+	assert.Equal(t, "jaeger", settings["type"])
+	assert.Equal(t, "influxd-test", settings["serviceName"])
+	assert.Equal(t, "const", settings["samplerType"])
+	assert.Equal(t, float64(1), settings["samplerParam"])
+	assert.Equal(t, "127.0.0.1:6831", settings["agentHostPort"])
+}
+
+func TestLauncher_TracingSampleRate(t *testing.T) {
+	run := func(args ...string) error {
+		l := launcher.NewTestLauncher()
+		defer os.RemoveAll(l.Path)
+		return l.Run(ctx, args...)
+	}
+
+	err := run("--tracing-type", "log", "--tracing-sample-rate", "1.5")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tracing-sample-rate")
+
+	err = run("--tracing-type", "jaeger", "--tracing-sample-rate", "0.5", "--jaeger-sampler-type", "const")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tracing-sample-rate")
+
+	settings := func(t *testing.T, args ...string) map[string]interface{} {
+		t.Helper()
+
+		l := launcher.RunTestLauncherOrFail(t, ctx, args...)
+		defer l.ShutdownOrFail(t, ctx)
+
+		resp, err := nethttp.Get(l.URL() + "/debug/tracing")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var settings map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&settings))
+		return settings
+	}
+
+	s := settings(t, "--tracing-type", "log", "--tracing-sample-rate", "0.25")
+	assert.Equal(t, "log", s["type"])
+	assert.Equal(t, 0.25, s["samplerParam"])
+
+	s = settings(t, "--tracing-type", "jaeger", "--tracing-sample-rate", "0.25", "--jaeger-agent-host-port", "127.0.0.1:6831")
+	assert.Equal(t, "probabilistic", s["samplerType"])
+	assert.Equal(t, 0.25, s["samplerParam"])
+}
+
+func TestLauncher_ProfileBundle(t *testing.T) {
+	// the bundle is served on the API with the debug endpoints moved off it
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--profiles-enabled", "--metrics-bind-address", "127.0.0.1:0")
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	get := func(token, duration string) (*nethttp.Response, error) {
+		req, err := l.NewHTTPRequest("GET", "/debug/profiles/all?duration="+duration, token, "")
+		if err != nil {
+			return nil, err
+		}
+		return nethttp.DefaultClient.Do(req)
+	}
+	status := func(token, duration string) int {
+		t.Helper()
+		resp, err := get(token, duration)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, nethttp.StatusUnauthorized, status("", "1s"))
+
+	readToken := &platform.Authorization{
+		OrgID:  l.Org.ID,
+		UserID: l.User.ID,
+		Permissions: []platform.Permission{{
+			Action:   platform.ReadAction,
+			Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &l.Org.ID},
+		}},
+	}
+	require.NoError(t, l.AuthorizationService(t).CreateAuthorization(ctx, readToken))
+	assert.Equal(t, nethttp.StatusForbidden, status(readToken.Token, "1s"))
+	assert.Equal(t, nethttp.StatusBadRequest, status(l.Auth.Token, "forever"))
+
+	type result struct {
+		resp *nethttp.Response
+		err  error
+	}
+	first := make(chan result)
+	go func() {
+		resp, err := get(l.Auth.Token, "2s")
+		first <- result{resp: resp, err: err}
+	}()
+
+	// a second bundle is refused while the first is collected
+	time.Sleep(500 * time.Millisecond)
+	assert.Equal(t, nethttp.StatusUnprocessableEntity, status(l.Auth.Token, "1s"))
+
+	res := <-first
+	require.NoError(t, res.err)
+	defer res.resp.Body.Close()
+	require.Equal(t, nethttp.StatusOK, res.resp.StatusCode)
+	assert.Equal(t, "application/gzip", res.resp.Header.Get("Content-Type"))
+	assert.Contains(t, res.resp.Header.Get("Content-Disposition"), "attachment")
+
+	gr, err := gzip.NewReader(res.resp.Body)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+		assert.NotZero(t, hdr.Size, hdr.Name)
+	}
+	assert.Equal(t, []string{"build.txt", "cpu.pprof", "heap.pprof", "goroutine.pprof", "block.pprof", "mutex.pprof"}, names)
+
+	// the lock is released once the bundle is written
+	assert.Equal(t, nethttp.StatusOK, status(l.Auth.Token, "100ms"))
+}
+
+func TestLauncher_LogLevel(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	do := func(method, token, body string) (int, string) {
+		t.Helper()
+		req, err := l.NewHTTPRequest(method, "/debug/log-level", token, body)
+		require.NoError(t, err)
+		resp, err := nethttp.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var level struct {
+			Level string `json:"level"`
+		}
+		if resp.StatusCode == nethttp.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&level))
+		}
+		return resp.StatusCode, level.Level
+	}
+
+	// the test launcher logs at debug, the level is read without a token
+	code, level := do("GET", "", "")
+	require.Equal(t, nethttp.StatusOK, code)
+	assert.Equal(t, "debug", level)
+
+	// setting the level requires an operator token
+	code, _ = do("POST", "", `{"level": "error"}`)
+	assert.Equal(t, nethttp.StatusUnauthorized, code)
+
+	readToken := &platform.Authorization{
+		OrgID:  l.Org.ID,
+		UserID: l.User.ID,
+		Permissions: []platform.Permission{{
+			Action:   platform.ReadAction,
+			Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &l.Org.ID},
+		}},
+	}
+	require.NoError(t, l.AuthorizationService(t).CreateAuthorization(ctx, readToken))
+	code, _ = do("PUT", readToken.Token, `{"level": "error"}`)
+	assert.Equal(t, nethttp.StatusForbidden, code)
+
+	code, level = do("GET", "", "")
+	require.Equal(t, nethttp.StatusOK, code)
+	assert.Equal(t, "debug", level)
+
+	// the level of the loggers derived from the launcher logger is swapped
+	log := l.Log().With(zap.String("service", "test"))
+	code, level = do("POST", l.Auth.Token, `{"level": "error"}`)
+	require.Equal(t, nethttp.StatusOK, code)
+	assert.Equal(t, "error", level)
+	assert.False(t, log.Core().Enabled(zap.InfoLevel))
+	assert.True(t, log.Core().Enabled(zap.ErrorLevel))
+
+	for _, unsupported := range []string{"verbose", "warn", "dpanic", "panic", "fatal"} {
+		code, _ = do("POST", l.Auth.Token, `{"level": "`+unsupported+`"}`)
+		assert.Equal(t, nethttp.StatusBadRequest, code, unsupported)
+	}
+	code, _ = do("DELETE", l.Auth.Token, "")
+	assert.Equal(t, nethttp.StatusMethodNotAllowed, code)
+
+	code, level = do("GET", "", "")
+	require.Equal(t, nethttp.StatusOK, code)
+	assert.Equal(t, "error", level)
+
+	code, level = do("PUT", l.Auth.Token, `{"level": "info"}`)
+	require.Equal(t, nethttp.StatusOK, code)
+	assert.Equal(t, "info", level)
+	assert.True(t, log.Core().Enabled(zap.InfoLevel))
+	assert.False(t, log.Core().Enabled(zap.DebugLevel))
+}
+
+func TestLauncher_MetricsDetail(t *testing.T) {
+	// the families of the go runtime, the process and bolt, the families of
+	// the other collectors depend on what the launcher did before gathering.
+	families := func(t *testing.T, args ...string) []string {
+		t.Helper()
+		l := launcher.RunTestLauncherOrFail(t, ctx, args...)
+		defer l.ShutdownOrFail(t, ctx)
+
+		mfs, err := l.Registry().Gather()
+		require.NoError(t, err)
+		var names []string
+		for _, mf := range mfs {
+			name := mf.GetName()
+			if strings.HasPrefix(name, "go_") || strings.HasPrefix(name, "boltdb_") ||
+				name == "process_cpu_seconds_total" || name == "process_start_time_seconds" {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+
+	basic := []string{
+		"boltdb_reads_total",
+		"boltdb_writes_total",
+		"go_gc_duration_seconds",
+		"go_goroutines",
+		"go_info",
+		"go_memstats_alloc_bytes",
+		"go_memstats_alloc_bytes_total",
+		"go_memstats_buck_hash_sys_bytes",
+		"go_memstats_frees_total",
+		"go_memstats_gc_cpu_fraction",
+		"go_memstats_gc_sys_bytes",
+		"go_memstats_heap_alloc_bytes",
+		"go_memstats_heap_idle_bytes",
+		"go_memstats_heap_inuse_bytes",
+		"go_memstats_heap_objects",
+		"go_memstats_heap_released_bytes",
+		"go_memstats_heap_sys_bytes",
+		"go_memstats_last_gc_time_seconds",
+		"go_memstats_lookups_total",
+		"go_memstats_mallocs_total",
+		"go_memstats_mcache_inuse_bytes",
+		"go_memstats_mcache_sys_bytes",
+		"go_memstats_mspan_inuse_bytes",
+		"go_memstats_mspan_sys_bytes",
+		"go_memstats_next_gc_bytes",
+		"go_memstats_other_sys_bytes",
+		"go_memstats_stack_inuse_bytes",
+		"go_memstats_stack_sys_bytes",
+		"go_memstats_sys_bytes",
+		"go_threads",
+		"process_cpu_seconds_total",
+		"process_start_time_seconds",
+	}
+	full := append([]string{
+		"boltdb_bucket_bytes",
+		"boltdb_bucket_keys",
+		"go_gc_pause_seconds",
+		"go_mutex_contentions_total",
+	}, basic...)
+	sort.Strings(full)
+
+	assert.Equal(t, basic, families(t))
+	assert.Equal(t, basic, families(t, "--metrics-detail", "basic"))
+	assert.Equal(t, full, families(t, "--metrics-detail", "full"))
+
+	l := launcher.NewTestLauncher()
+	err := l.Run(ctx, "--metrics-detail", "verbose")
+	os.RemoveAll(l.Path)
+	assert.Error(t, err)
+}
+
+type labelCountHandler struct {
+	labelSVC platform.LabelService
+}
+
+func (h *labelCountHandler) Prefix() string {
+	return "/api/v2/labelcount"
+}
+
+func (h *labelCountHandler) ServeHTTP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	labels, err := h.labelSVC.FindLabels(r.Context(), platform.LabelFilter{})
+	if err != nil {
+		w.WriteHeader(nethttp.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(map[string]int{"count": len(labels)})
+}
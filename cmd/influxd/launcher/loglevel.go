@@ -0,0 +1,166 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/http"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// logLevelDebugPath is the debug endpoint serving and setting the log level.
+const logLevelDebugPath = "/debug/log-level"
+
+// logLevelErr is the error of a log level that is not supported.
+func logLevelErr(level string) error {
+	return &platform.Error{
+		Code: platform.EInvalid,
+		Msg:  fmt.Sprintf("unknown log level %q; supported levels are debug, info, and error", level),
+	}
+}
+
+// setLogLevel swaps the level of the logger and of all the loggers derived
+// from it. The change is logged at info, before the swap when the new level
+// leaves info out.
+func (m *Launcher) setLogLevel(level string, fields ...zap.Field) error {
+	var lvl zapcore.Level
+	if err := lvl.Set(level); err != nil {
+		return logLevelErr(level)
+	}
+	switch lvl {
+	case zapcore.DebugLevel, zapcore.InfoLevel, zapcore.ErrorLevel:
+	default:
+		return logLevelErr(level)
+	}
+
+	prev := m.level.Level()
+	fields = append(fields, zap.Stringer("previous_level", prev), zap.Stringer("level", lvl))
+	if !lvl.Enabled(zapcore.InfoLevel) {
+		m.log.Info("Changing log level", fields...)
+	}
+	m.level.SetLevel(lvl)
+	if lvl.Enabled(zapcore.InfoLevel) {
+		m.log.Info("Changed log level", fields...)
+	}
+	return nil
+}
+
+// reloadLogLevelOnSignal reloads the log level of the options whenever the
+// process receives a SIGHUP, until ctx is done. The level of the config file
+// is read again, it applies unless the level is set by a flag or env var.
+func (m *Launcher) reloadLogLevelOnSignal(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	defer signal.Stop(sigCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+		}
+
+		level, err := m.reloadLogLevel()
+		if err == nil {
+			err = m.setLogLevel(level, zap.String("source", "signal"), zap.String("signal", "SIGHUP"))
+		}
+		if err != nil {
+			m.log.Error("Failed to reload log level", zap.Error(err))
+		}
+	}
+}
+
+// logLevelHandler serves the log level, a POST or PUT of {"level": "debug"}
+// sets it. The level is read without a token, as are the other debug
+// endpoints. Setting it requires an operator token, the debug level logs the
+// bodies of the requests.
+type logLevelHandler struct {
+	m            *Launcher
+	errorHandler platform.HTTPErrorHandler
+
+	// set is the authenticated handler of the requests setting the level.
+	set nethttp.Handler
+}
+
+// logLevelHandler returns the handler of the log level, it authenticates the
+// requests setting the level itself so it can be served from any listener.
+func (m *Launcher) logLevelHandler(log *zap.Logger) nethttp.Handler {
+	h := &logLevelHandler{m: m, errorHandler: m.apibackend.HTTPErrorHandler}
+	h.set = m.authenticationHandler(log, nethttp.HandlerFunc(h.setLevel))
+	return h
+}
+
+func (h *logLevelHandler) ServeHTTP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	switch r.Method {
+	case nethttp.MethodGet:
+		h.writeLevel(w)
+	case nethttp.MethodPost, nethttp.MethodPut:
+		h.set.ServeHTTP(w, r)
+	default:
+		h.errorHandler.HandleHTTPError(r.Context(), &platform.Error{
+			Code: platform.EMethodNotAllowed,
+			Msg:  fmt.Sprintf("method %s is not allowed", r.Method),
+		}, w)
+	}
+}
+
+func (h *logLevelHandler) setLevel(w nethttp.ResponseWriter, r *nethttp.Request) {
+	ctx := r.Context()
+	auth, err := operatorAuthorizer(ctx)
+	if err != nil {
+		h.errorHandler.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req struct {
+		Level string `json:"level"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorHandler.HandleHTTPError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "failed to decode log level request",
+			Err:  err,
+		}, w)
+		return
+	}
+	err = h.m.setLogLevel(req.Level,
+		zap.String("source", "http"),
+		zap.Stringer("user_id", auth.GetUserID()),
+		zap.Stringer("authorizer_id", auth.Identifier()),
+		zap.String("authorizer_kind", auth.Kind()),
+		zap.String("remote_addr", r.RemoteAddr),
+		zap.String("user_agent", r.UserAgent()),
+	)
+	if err != nil {
+		h.errorHandler.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.writeLevel(w)
+}
+
+func (h *logLevelHandler) writeLevel(w nethttp.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(nethttp.StatusOK)
+	_ = json.NewEncoder(w).Encode(struct {
+		Level string `json:"level"`
+	}{Level: h.m.level.Level().String()})
+}
+
+// debugLoggingMW logs the requests to next while the log level is debug.
+func debugLoggingMW(level zap.AtomicLevel, log *zap.Logger, next nethttp.Handler) nethttp.Handler {
+	logged := http.LoggingMW(log)(next)
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if level.Enabled(zapcore.DebugLevel) {
+			logged.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
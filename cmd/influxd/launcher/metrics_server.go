@@ -0,0 +1,53 @@
+package launcher
+
+import (
+	"net"
+	nethttp "net/http"
+
+	"github.com/influxdata/influxdb/http"
+	"go.uber.org/zap"
+)
+
+// runMetricsServer serves the metrics and the debug endpoints on the metrics
+// bind address, so the API can be exposed publicly without exposing the
+// profiles and the internals of the process.
+func (m *Launcher) runMetricsServer(metrics, debug nethttp.Handler) error {
+	log := m.log.With(zap.String("service", "metrics-http"))
+
+	mux := nethttp.NewServeMux()
+	mux.Handle(http.MetricsPath, metrics)
+	mux.Handle(http.DebugPath+"/", debug)
+
+	ln, err := net.Listen("tcp", m.metricsBindAddress)
+	if err != nil {
+		log.Error("Failed metrics http listener", zap.Error(err))
+		return err
+	}
+	if addr, ok := ln.Addr().(*net.TCPAddr); ok {
+		m.metricsPort = addr.Port
+	}
+
+	// no write timeout, a CPU profile is written for as long as it is
+	// requested.
+	m.metricsServer = &nethttp.Server{
+		Addr:              m.metricsBindAddress,
+		Handler:           m.panicMW(mux),
+		ReadTimeout:       m.httpReadTimeout,
+		ReadHeaderTimeout: m.httpReadHeaderTimeout,
+		IdleTimeout:       m.httpIdleTimeout,
+	}
+
+	m.wg.Add(1)
+	go func(log *zap.Logger) {
+		defer m.wg.Done()
+		defer m.handlePanic()
+		log.Info("Listening", zap.String("transport", "http"), zap.String("addr", m.metricsBindAddress), zap.Int("port", m.metricsPort))
+
+		if err := m.metricsServer.Serve(ln); err != nethttp.ErrServerClosed {
+			log.Error("Failed metrics http service", zap.Error(err))
+		}
+		log.Info("Stopping")
+	}(log)
+
+	return nil
+}
@@ -460,6 +460,131 @@ spec:
 	})
 }
 
+func TestLauncher_Pkger_Remove(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	svc := pkger.NewService(
+		pkger.WithBucketSVC(l.BucketService(t)),
+		pkger.WithCheckSVC(l.Launcher.CheckService()),
+		pkger.WithDashboardSVC(l.DashboardService(t)),
+		pkger.WithLabelSVC(l.LabelService(t)),
+		pkger.WithNoticationEndpointSVC(l.NotificationEndpointService(t)),
+		pkger.WithNotificationRuleSVC(l.Launcher.NotificationRuleService()),
+		pkger.WithScraperTargetSVC(l.Launcher.KeyValueService()),
+		pkger.WithTaskSVC(l.Launcher.TaskService()),
+		pkger.WithTelegrafSVC(l.TelegrafService(t)),
+		pkger.WithVariableSVC(l.VariableService(t)),
+	)
+
+	// orgState is the names of the resources of the org, by kind.
+	orgState := func(t *testing.T) map[string][]string {
+		t.Helper()
+
+		state := make(map[string][]string)
+
+		bkts, _, err := l.BucketService(t).FindBuckets(ctx, influxdb.BucketFilter{OrganizationID: &l.Org.ID})
+		require.NoError(t, err)
+		for _, b := range bkts {
+			state["buckets"] = append(state["buckets"], b.Name)
+		}
+
+		labels, err := l.LabelService(t).FindLabels(ctx, influxdb.LabelFilter{OrgID: &l.Org.ID})
+		require.NoError(t, err)
+		for _, label := range labels {
+			state["labels"] = append(state["labels"], label.Name)
+		}
+
+		dashs, _, err := l.DashboardService(t).FindDashboards(ctx, influxdb.DashboardFilter{
+			OrganizationID: &l.Org.ID,
+		}, influxdb.DefaultDashboardFindOptions)
+		require.NoError(t, err)
+		for _, d := range dashs {
+			state["dashboards"] = append(state["dashboards"], d.Name)
+		}
+
+		endpoints, _, err := l.NotificationEndpointService(t).FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+			OrgID: &l.Org.ID,
+		})
+		require.NoError(t, err)
+		for _, e := range endpoints {
+			state["endpoints"] = append(state["endpoints"], e.GetName())
+		}
+
+		teles, _, err := l.TelegrafService(t).FindTelegrafConfigs(ctx, influxdb.TelegrafConfigFilter{
+			OrgID: &l.Org.ID,
+		})
+		require.NoError(t, err)
+		for _, tele := range teles {
+			state["telegrafs"] = append(state["telegrafs"], tele.Name)
+		}
+
+		vars, err := l.VariableService(t).FindVariables(ctx, influxdb.VariableFilter{OrganizationID: &l.Org.ID})
+		require.NoError(t, err)
+		for _, v := range vars {
+			state["variables"] = append(state["variables"], v.Name)
+		}
+
+		return state
+	}
+
+	t.Run("removing an applied package returns the org to its prior state", func(t *testing.T) {
+		prior := orgState(t)
+
+		_, err := svc.Apply(timedCtx(5*time.Second), l.Org.ID, l.User.ID, newPkg(t))
+		require.NoError(t, err)
+
+		sum, err := svc.Remove(timedCtx(5*time.Second), l.Org.ID, newPkg(t))
+		require.NoError(t, err)
+
+		assert.Empty(t, sum.Skipped)
+		assert.Len(t, sum.Buckets, 1)
+		assert.Len(t, sum.Dashboards, 1)
+		assert.Len(t, sum.Labels, 1)
+		assert.Len(t, sum.LabelMappings, 5)
+		assert.Len(t, sum.NotificationEndpoints, 1)
+		assert.Len(t, sum.TelegrafConfigs, 1)
+		assert.Len(t, sum.Variables, 1)
+
+		assert.Equal(t, prior, orgState(t))
+	})
+
+	t.Run("labels mapped to resources outside the package are not removed", func(t *testing.T) {
+		prior := orgState(t)
+
+		sum, err := svc.Apply(timedCtx(5*time.Second), l.Org.ID, l.User.ID, newPkg(t))
+		require.NoError(t, err)
+		require.Len(t, sum.Labels, 1)
+
+		err = l.LabelService(t).CreateLabelMapping(ctx, &influxdb.LabelMapping{
+			LabelID:      influxdb.ID(sum.Labels[0].ID),
+			ResourceID:   l.Bucket.ID,
+			ResourceType: influxdb.BucketsResourceType,
+		})
+		require.NoError(t, err)
+
+		removeSum, err := svc.Remove(timedCtx(5*time.Second), l.Org.ID, newPkg(t))
+		require.NoError(t, err)
+
+		require.Len(t, removeSum.Skipped, 1)
+		assert.Equal(t, pkger.KindLabel, removeSum.Skipped[0].Kind)
+		assert.Equal(t, "label_1", removeSum.Skipped[0].Name)
+		assert.Empty(t, removeSum.Labels)
+
+		labels, err := l.LabelService(t).FindResourceLabels(ctx, influxdb.LabelMappingFilter{
+			ResourceID:   l.Bucket.ID,
+			ResourceType: influxdb.BucketsResourceType,
+		})
+		require.NoError(t, err)
+		require.Len(t, labels, 1)
+		assert.Equal(t, "label_1", labels[0].Name)
+
+		prior["labels"] = append(prior["labels"], "label_1")
+		assert.Equal(t, prior, orgState(t))
+	})
+}
+
 func timedCtx(d time.Duration) context.Context {
 	ctx, cancel := context.WithTimeout(ctx, d)
 	var _ = cancel
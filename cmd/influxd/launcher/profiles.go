@@ -0,0 +1,236 @@
+package launcher
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"runtime"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	platcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// profilesDebugPath is the debug endpoint serving the profile bundles.
+	profilesDebugPath = "/debug/profiles/all"
+
+	defaultProfileBundleDuration = 30 * time.Second
+	maxProfileBundleDuration     = 5 * time.Minute
+)
+
+// bundleProfiles are the profiles of a bundle taken once the CPU profile is
+// collected.
+var bundleProfiles = []string{"heap", "goroutine", "block", "mutex"}
+
+// profileBundler serves a tar.gz bundle of the go profiles of the process to
+// operators, one bundle at a time.
+type profileBundler struct {
+	log          *zap.Logger
+	errorHandler platform.HTTPErrorHandler
+	writeTimeout time.Duration
+
+	// collecting is 1 while a bundle is collected.
+	collecting int32
+}
+
+// profileBundleHandler returns the handler of the profile bundles, it
+// authenticates the requests itself so it can be served from any listener.
+func (m *Launcher) profileBundleHandler(log *zap.Logger) nethttp.Handler {
+	return m.authenticationHandler(log, &profileBundler{
+		log:          log,
+		errorHandler: m.apibackend.HTTPErrorHandler,
+		writeTimeout: m.httpWriteTimeout,
+	})
+}
+
+// authenticationHandler authenticates the requests to next, for the handlers
+// served outside of the API.
+func (m *Launcher) authenticationHandler(log *zap.Logger, next nethttp.Handler) nethttp.Handler {
+	b := m.apibackend
+	h := http.NewAuthenticationHandler(log, b.HTTPErrorHandler)
+	h.AuthorizationService = b.AuthorizationService
+	h.SessionService = b.SessionService
+	h.SessionRenewDisabled = b.SessionRenewDisabled
+	h.UserService = b.UserService
+	h.Handler = next
+	return h
+}
+
+func (b *profileBundler) ServeHTTP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	ctx := r.Context()
+	if r.Method != nethttp.MethodGet {
+		b.errorHandler.HandleHTTPError(ctx, &platform.Error{
+			Code: platform.EMethodNotAllowed,
+			Msg:  fmt.Sprintf("method %s is not allowed", r.Method),
+		}, w)
+		return
+	}
+
+	auth, err := operatorAuthorizer(ctx)
+	if err != nil {
+		b.errorHandler.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	d, err := b.duration(r)
+	if err != nil {
+		b.errorHandler.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&b.collecting, 0, 1) {
+		b.errorHandler.HandleHTTPError(ctx, &platform.Error{
+			Code: platform.EConflict,
+			Msg:  "a profile bundle is already being collected",
+		}, w)
+		return
+	}
+	defer atomic.StoreInt32(&b.collecting, 0)
+
+	// the bundle is written whole before it is sent, a failed collection is
+	// reported rather than sent as a truncated archive.
+	var buf bytes.Buffer
+	if err := writeProfileBundle(ctx, &buf, d); err != nil {
+		b.errorHandler.HandleHTTPError(ctx, &platform.Error{
+			Code: platform.EInternal,
+			Msg:  "failed to collect profile bundle",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	b.log.Info("Wrote profile bundle",
+		zap.Stringer("authorizer_id", auth.Identifier()),
+		zap.String("authorizer_kind", auth.Kind()),
+		zap.Stringer("user_id", auth.GetUserID()),
+		zap.Duration("duration", d),
+		zap.Int("bytes", buf.Len()),
+	)
+
+	name := "influxd-profiles-" + time.Now().UTC().Format("20060102T150405Z") + ".tar.gz"
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.WriteHeader(nethttp.StatusOK)
+	_, _ = io.Copy(w, &buf)
+}
+
+// duration returns the duration of the CPU profile requested.
+func (b *profileBundler) duration(r *nethttp.Request) (time.Duration, error) {
+	v := r.URL.Query().Get("duration")
+	if v == "" {
+		return defaultProfileBundleDuration, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 || d > maxProfileBundleDuration {
+		return 0, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("invalid duration %q; a positive duration of at most %s is required", v, maxProfileBundleDuration),
+		}
+	}
+	if b.writeTimeout > 0 && d >= b.writeTimeout {
+		return 0, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("invalid duration %q; the duration must be shorter than the http-write-timeout of %s", v, b.writeTimeout),
+		}
+	}
+	return d, nil
+}
+
+// operatorAuthorizer returns the authorizer of the context if it holds all of
+// the operator permissions.
+func operatorAuthorizer(ctx context.Context) (platform.Authorizer, error) {
+	a, err := platcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range platform.OperPermissions() {
+		if !a.Allowed(p) {
+			return nil, &platform.Error{
+				Code: platform.EForbidden,
+				Msg:  "an operator token is required",
+			}
+		}
+	}
+	return a, nil
+}
+
+// bundleFile is a file of a profile bundle.
+type bundleFile struct {
+	name string
+	body []byte
+}
+
+// writeProfileBundle writes a tar.gz archive of the build info, a CPU profile
+// of duration d and the bundleProfiles.
+func writeProfileBundle(ctx context.Context, w io.Writer, d time.Duration) error {
+	// block and mutex events are recorded in full while a bundle is collected,
+	// the rate of the mutex profile of full metrics is restored after.
+	runtime.SetBlockProfileRate(1)
+	defer runtime.SetBlockProfileRate(0)
+	defer runtime.SetMutexProfileFraction(runtime.SetMutexProfileFraction(1))
+
+	start := time.Now().UTC()
+
+	var cpu bytes.Buffer
+	if err := pprof.StartCPUProfile(&cpu); err != nil {
+		return err
+	}
+	timer := time.NewTimer(d)
+	select {
+	case <-timer.C:
+		pprof.StopCPUProfile()
+	case <-ctx.Done():
+		timer.Stop()
+		pprof.StopCPUProfile()
+		return ctx.Err()
+	}
+
+	info := platform.GetBuildInfo()
+	var build bytes.Buffer
+	fmt.Fprintf(&build, "version: %s\ncommit: %s\nbuild_date: %s\n", info.Version, info.Commit, info.Date)
+	fmt.Fprintf(&build, "go_version: %s\nos: %s\narch: %s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&build, "time: %s\nduration: %s\n", start.Format(time.RFC3339Nano), d)
+
+	files := []bundleFile{
+		{name: "build.txt", body: build.Bytes()},
+		{name: "cpu.pprof", body: cpu.Bytes()},
+	}
+	for _, name := range bundleProfiles {
+		var buf bytes.Buffer
+		if err := pprof.Lookup(name).WriteTo(&buf, 0); err != nil {
+			return fmt.Errorf("failed to write %s profile: %v", name, err)
+		}
+		files = append(files, bundleFile{name: name + ".pprof", body: buf.Bytes()})
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name:    f.name,
+			Mode:    0600,
+			Size:    int64(len(f.body)),
+			ModTime: start,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.body); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
@@ -0,0 +1,143 @@
+package launcher
+
+import (
+	"encoding/json"
+	nethttp "net/http"
+	"sync"
+	"time"
+)
+
+// The subsystems the readiness of the launcher depends on.
+const (
+	readyCheckKV        = "kv"
+	readyCheckEngine    = "engine"
+	readyCheckScheduler = "task_scheduler"
+	readyCheckNATS      = "nats"
+)
+
+const (
+	readyStatusPass = "pass"
+	readyStatusFail = "fail"
+)
+
+// readyCheck is the state of a subsystem the readiness of the launcher
+// depends on.
+type readyCheck struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+	Duration string `json:"duration,omitempty"`
+
+	started time.Time
+}
+
+// readiness tracks the subsystems of the launcher, the launcher is ready
+// once they have all started and until it shuts down.
+type readiness struct {
+	mu       sync.RWMutex
+	start    time.Time
+	checks   []*readyCheck
+	stopping bool
+}
+
+func newReadiness(names ...string) *readiness {
+	r := &readiness{start: time.Now()}
+	for _, name := range names {
+		r.checks = append(r.checks, &readyCheck{
+			Name:    name,
+			Status:  readyStatusFail,
+			Message: "not started",
+		})
+	}
+	return r
+}
+
+func (r *readiness) check(name string) *readyCheck {
+	for _, c := range r.checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// begin records that the subsystem is starting.
+func (r *readiness) begin(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c := r.check(name); c != nil {
+		c.started = time.Now()
+		c.Message = "starting"
+	}
+}
+
+// pass records that the subsystem has started, along with how long it took.
+func (r *readiness) pass(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c := r.check(name); c != nil {
+		c.Status = readyStatusPass
+		c.Message = ""
+		if !c.started.IsZero() {
+			c.Duration = time.Since(c.started).String()
+		}
+	}
+}
+
+// fail records that the subsystem failed to start.
+func (r *readiness) fail(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c := r.check(name); c != nil {
+		c.Status = readyStatusFail
+		c.Message = err.Error()
+	}
+}
+
+// stop marks the launcher as not ready, whatever the state of its subsystems.
+func (r *readiness) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopping = true
+}
+
+// ServeHTTP responds with the checks of the subsystems, with a 503 listing
+// the failing checks until they all pass.
+func (r *readiness) ServeHTTP(w nethttp.ResponseWriter, req *nethttp.Request) {
+	r.mu.RLock()
+	status := struct {
+		Status  string       `json:"status"`
+		Start   time.Time    `json:"started"`
+		Up      string       `json:"up"`
+		Checks  []readyCheck `json:"checks"`
+		Failing []string     `json:"failing,omitempty"`
+	}{
+		Status: "ready",
+		Start:  r.start,
+		Up:     time.Since(r.start).String(),
+	}
+	for _, c := range r.checks {
+		status.Checks = append(status.Checks, *c)
+		if c.Status != readyStatusPass {
+			status.Failing = append(status.Failing, c.Name)
+		}
+	}
+	stopping := r.stopping
+	r.mu.RUnlock()
+
+	code := nethttp.StatusOK
+	switch {
+	case stopping:
+		status.Status = "shutting down"
+		code = nethttp.StatusServiceUnavailable
+	case len(status.Failing) > 0:
+		status.Status = "not ready"
+		code = nethttp.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	_ = enc.Encode(status)
+}
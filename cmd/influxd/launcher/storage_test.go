@@ -1,9 +1,11 @@
 package launcher_test
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	nethttp "net/http"
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
@@ -12,6 +14,7 @@ import (
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/cmd/influxd/launcher"
 	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/storage"
 	"github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 )
@@ -157,6 +160,60 @@ func TestLauncher_BucketDelete(t *testing.T) {
 	}
 }
 
+func TestLauncher_BucketConsistency(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	l.WritePointsOrFail(t, `m,k=v f=100i 946684800000000000`)
+
+	// the bucket is deleted from the bolt store alone, as if restored from an
+	// older backup than the engine, leaving its data orphaned.
+	orphaned := storage.BucketRef{OrgID: l.Org.ID, BucketID: l.Bucket.ID}
+	if err := l.KeyValueService().DeleteBucket(ctx, l.Bucket.ID); err != nil {
+		t.Fatal(err)
+	}
+	empty := &influxdb.Bucket{OrgID: l.Org.ID, Name: "empty"}
+	if err := l.KeyValueService().CreateBucket(ctx, empty); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := storage.CheckBucketConsistency(ctx, l.Launcher.Engine(), l.KeyValueService())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := c.Orphaned, []storage.BucketRef{orphaned}; !cmp.Equal(got, exp) {
+		t.Fatalf("unexpected orphaned buckets: %s", cmp.Diff(got, exp))
+	}
+	var found bool
+	for _, ref := range c.Empty {
+		found = found || ref.BucketID == empty.ID
+	}
+	if !found {
+		t.Fatalf("expected bucket %s to be empty, got %v", empty.ID, c.Empty)
+	}
+
+	// the orphaned data is deleted by the consistency check of the next startup
+	l.Cancel()
+	l.Launcher.Shutdown(ctx)
+
+	restarted := launcher.NewTestLauncher()
+	os.RemoveAll(restarted.Path)
+	restarted.Path = l.Path
+	if err := restarted.Run(ctx, "--bucket-consistency-fix"); err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.ShutdownOrFail(t, ctx)
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	for restarted.Launcher.Engine().SeriesCardinality() > 0 {
+		select {
+		case <-timeout.Done():
+			t.Fatalf("orphaned bucket %s was not deleted", orphaned.BucketID)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
 func TestStorage_CacheSnapshot_Size(t *testing.T) {
 	l := launcher.NewTestLauncher()
 	l.StorageConfig.Engine.Cache.SnapshotMemorySize = 10
@@ -247,3 +304,33 @@ func TestStorage_CacheSnapshot_Age(t *testing.T) {
 		t.Fatalf("got %d series in TSM files, expected %d", got, exp)
 	}
 }
+
+func TestStorage_RetentionCheckInterval(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		exp  time.Duration
+	}{
+		{exp: storage.DefaultRetentionInterval},
+		{args: []string{"--storage-retention-check-interval", "10m"}, exp: 10 * time.Minute},
+	} {
+		l := launcher.RunTestLauncherOrFail(t, ctx, tt.args...)
+		engine, ok := l.Launcher.Engine().(*launcher.TemporaryEngine)
+		if !ok {
+			l.ShutdownOrFail(t, ctx)
+			t.Fatalf("got engine %T, exp a *launcher.TemporaryEngine", l.Launcher.Engine())
+		}
+		if got, exp := engine.Config().RetentionInterval, toml.Duration(tt.exp); got != exp {
+			t.Errorf("args %v: got retention interval %s, exp %s", tt.args, got, exp)
+		}
+		l.ShutdownOrFail(t, ctx)
+	}
+
+	for _, interval := range []string{"0s", "-1m"} {
+		l := launcher.NewTestLauncher()
+		err := l.Run(ctx, "--storage-retention-check-interval", interval)
+		os.RemoveAll(l.Path)
+		if err == nil {
+			t.Errorf("exp interval %s to be invalid", interval)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package launcher
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// tlsVersions are the values of the tls-min-version option.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// strictCipherSuites are the cipher suites of the tls-strict-ciphers option,
+// the AEAD suites with forward secrecy. The suites of TLS 1.3 are not
+// configurable and are all strict.
+var strictCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+}
+
+// strictCurves are the curves of the tls-strict-ciphers option.
+var strictCurves = []tls.CurveID{tls.X25519, tls.CurveP256}
+
+// parseTLSVersion returns the TLS version of a tls-min-version value.
+func parseTLSVersion(v string) (uint16, error) {
+	version, ok := tlsVersions[v]
+	if !ok {
+		valid := make([]string, 0, len(tlsVersions))
+		for k := range tlsVersions {
+			valid = append(valid, k)
+		}
+		sort.Strings(valid)
+		return 0, fmt.Errorf("invalid tls-min-version %q; valid versions are %s", v, strings.Join(valid, ", "))
+	}
+	return version, nil
+}
+
+// validateTLS validates the TLS options, whether or not TLS is enabled, so a
+// misconfigured server fails before it starts.
+func (m *Launcher) validateTLS() error {
+	if _, err := parseTLSVersion(m.httpTLSMinVersion); err != nil {
+		return err
+	}
+	if m.httpTLSClientCA != "" && (m.httpTLSCert == "" || m.httpTLSKey == "") {
+		return fmt.Errorf("tls-client-ca requires tls-cert and tls-key")
+	}
+	return nil
+}
+
+// tlsConfig returns the TLS config of the HTTP server from the TLS options.
+func (m *Launcher) tlsConfig() (*tls.Config, error) {
+	version, err := parseTLSVersion(m.httpTLSMinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{MinVersion: version}
+	if m.httpTLSStrictCiphers {
+		config.CipherSuites = strictCipherSuites
+		config.CurvePreferences = strictCurves
+		config.PreferServerCipherSuites = true
+	}
+
+	if m.httpTLSClientCA != "" {
+		pem, err := ioutil.ReadFile(m.httpTLSClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls-client-ca %q: %v", m.httpTLSClientCA, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls-client-ca %q contains no PEM-encoded certificates", m.httpTLSClientCA)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// logTLSConfig logs the TLS policy of the HTTP server.
+func logTLSConfig(log *zap.Logger, minVersion string, config *tls.Config) {
+	log.Info("TLS policy",
+		zap.String("min_version", minVersion),
+		zap.Bool("strict_ciphers", config.CipherSuites != nil),
+		zap.Bool("client_cert_required", config.ClientAuth == tls.RequireAndVerifyClientCert),
+	)
+}
@@ -0,0 +1,149 @@
+package launcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"net/url"
+
+	jaeger "github.com/uber/jaeger-client-go"
+	jaegerconfig "github.com/uber/jaeger-client-go/config"
+)
+
+const (
+	// tracingDebugPath is the debug endpoint serving the tracing in use.
+	tracingDebugPath = "/debug/tracing"
+
+	defaultJaegerServiceName = "influxd"
+)
+
+// jaegerSamplerTypes are the values of the jaeger-sampler-type option.
+var jaegerSamplerTypes = map[string]bool{
+	jaeger.SamplerTypeConst:         true,
+	jaeger.SamplerTypeProbabilistic: true,
+	jaeger.SamplerTypeRateLimiting:  true,
+	jaeger.SamplerTypeRemote:        true,
+}
+
+// jaegerOptions are the jaeger options of the launcher, merged over the
+// jaeger environment variables.
+type jaegerOptions struct {
+	agentHostPort     string
+	collectorEndpoint string
+	serviceName       string
+	samplerType       string
+	samplerParam      float64
+}
+
+// tracingSettings are the tracing settings in use, they are logged at startup
+// and served at the tracing debug endpoint.
+type tracingSettings struct {
+	Type              string  `json:"type"`
+	ServiceName       string  `json:"serviceName,omitempty"`
+	SamplerType       string  `json:"samplerType,omitempty"`
+	SamplerParam      float64 `json:"samplerParam"`
+	AgentHostPort     string  `json:"agentHostPort,omitempty"`
+	CollectorEndpoint string  `json:"collectorEndpoint,omitempty"`
+}
+
+// newLogTracingSettings returns the settings of log tracing, the log tracer
+// samples traces probabilistically.
+func newLogTracingSettings(sampleRate float64) *tracingSettings {
+	return &tracingSettings{
+		Type:         LogTracing,
+		SamplerType:  jaeger.SamplerTypeProbabilistic,
+		SamplerParam: sampleRate,
+	}
+}
+
+func newJaegerTracingSettings(cfg *jaegerconfig.Configuration) *tracingSettings {
+	s := &tracingSettings{
+		Type:              JaegerTracing,
+		ServiceName:       cfg.ServiceName,
+		SamplerType:       cfg.Sampler.Type,
+		SamplerParam:      cfg.Sampler.Param,
+		AgentHostPort:     cfg.Reporter.LocalAgentHostPort,
+		CollectorEndpoint: cfg.Reporter.CollectorEndpoint,
+	}
+	// the client samples remotely when no sampler type is given
+	if s.SamplerType == "" {
+		s.SamplerType = jaeger.SamplerTypeRemote
+	}
+	return s
+}
+
+// jaegerConfig returns the jaeger config of the environment with the jaeger
+// options of the launcher merged over it. The tracing sample rate, when set,
+// takes over the sampler.
+func (m *Launcher) jaegerConfig() (*jaegerconfig.Configuration, error) {
+	cfg, err := jaegerconfig.FromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Jaeger client config from environment variables: %v", err)
+	}
+	if cfg.Sampler == nil {
+		cfg.Sampler = &jaegerconfig.SamplerConfig{}
+	}
+	if cfg.Reporter == nil {
+		cfg.Reporter = &jaegerconfig.ReporterConfig{}
+	}
+
+	o := m.jaeger
+	if o.serviceName != "" {
+		cfg.ServiceName = o.serviceName
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = defaultJaegerServiceName
+	}
+	if o.samplerType != "" {
+		cfg.Sampler.Type = o.samplerType
+	}
+	if o.samplerParam >= 0 {
+		cfg.Sampler.Param = o.samplerParam
+	}
+	if m.tracingSampleRate >= 0 {
+		if o.samplerType != "" && o.samplerType != jaeger.SamplerTypeProbabilistic {
+			return nil, fmt.Errorf("tracing-sample-rate requires a %s jaeger-sampler-type, got %q", jaeger.SamplerTypeProbabilistic, o.samplerType)
+		}
+		cfg.Sampler.Type = jaeger.SamplerTypeProbabilistic
+		cfg.Sampler.Param = m.tracingSampleRate
+	}
+	if o.agentHostPort != "" {
+		cfg.Reporter.LocalAgentHostPort = o.agentHostPort
+	}
+	if o.collectorEndpoint != "" {
+		cfg.Reporter.CollectorEndpoint = o.collectorEndpoint
+	}
+
+	if t := cfg.Sampler.Type; t != "" && !jaegerSamplerTypes[t] {
+		return nil, fmt.Errorf("invalid jaeger-sampler-type %q; valid types are %s, %s, %s, %s", t,
+			jaeger.SamplerTypeConst, jaeger.SamplerTypeProbabilistic, jaeger.SamplerTypeRateLimiting, jaeger.SamplerTypeRemote)
+	}
+	if cfg.Sampler.Type == jaeger.SamplerTypeProbabilistic && cfg.Sampler.Param > 1 {
+		return nil, fmt.Errorf("invalid jaeger-sampler-param %v; the probabilistic sampler takes a probability between 0 and 1", cfg.Sampler.Param)
+	}
+	if hp := cfg.Reporter.LocalAgentHostPort; hp != "" {
+		if _, _, err := net.SplitHostPort(hp); err != nil {
+			return nil, fmt.Errorf("invalid jaeger-agent-host-port %q: %v", hp, err)
+		}
+	}
+	if e := cfg.Reporter.CollectorEndpoint; e != "" {
+		if u, err := url.Parse(e); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid jaeger-collector-endpoint %q; an absolute URL is required", e)
+		}
+	}
+	return cfg, nil
+}
+
+// tracingDebugHandler serves the tracing settings in use, nil settings are
+// served as tracing being disabled.
+func tracingDebugHandler(s *tracingSettings) nethttp.Handler {
+	if s == nil {
+		s = &tracingSettings{Type: "none"}
+	}
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(nethttp.StatusOK)
+		_ = json.NewEncoder(w).Encode(s)
+	})
+}
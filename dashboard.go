@@ -85,6 +85,44 @@ type Dashboard struct {
 type DashboardMeta struct {
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+	// RefreshInterval is the interval at which the dashboard is auto-refreshed.
+	// A nil value indicates the dashboard is not auto-refreshed.
+	RefreshInterval *Duration `json:"refreshInterval,omitempty"`
+}
+
+// MinRefreshInterval is the smallest auto-refresh interval a dashboard or
+// cell may be configured with.
+const MinRefreshInterval = 5 * time.Second
+
+// ValidRefreshInterval returns an error if the refresh interval is provided
+// and is shorter than the MinRefreshInterval.
+func ValidRefreshInterval(d *Duration) *Error {
+	if d == nil || d.Duration == 0 {
+		return nil
+	}
+
+	if d.Duration < MinRefreshInterval {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("refresh interval must be at least %s", MinRefreshInterval),
+		}
+	}
+	return nil
+}
+
+// Valid returns an error if the dashboard or any of its cells are invalid.
+func (d *Dashboard) Valid() *Error {
+	if err := ValidRefreshInterval(d.Meta.RefreshInterval); err != nil {
+		return err
+	}
+
+	for _, c := range d.Cells {
+		if err := ValidRefreshInterval(c.RefreshInterval); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // DefaultDashboardFindOptions are the default find options for dashboards
@@ -190,6 +228,8 @@ type CellProperty struct {
 	Y int32 `json:"y"`
 	W int32 `json:"w"`
 	H int32 `json:"h"`
+	// RefreshInterval overrides the dashboard's refresh interval for the cell.
+	RefreshInterval *Duration `json:"refreshInterval,omitempty"`
 }
 
 // DashboardFilter is a filter for dashboards.
@@ -225,6 +265,9 @@ func (f DashboardFilter) QueryParams() map[string][]string {
 type DashboardUpdate struct {
 	Name        *string `json:"name"`
 	Description *string `json:"description"`
+	// RefreshInterval updates the dashboard's auto-refresh interval. A zero
+	// duration disables auto-refresh.
+	RefreshInterval *Duration `json:"refreshInterval"`
 }
 
 // Apply applies an update to a dashboard.
@@ -237,19 +280,30 @@ func (u DashboardUpdate) Apply(d *Dashboard) error {
 		d.Description = *u.Description
 	}
 
+	if u.RefreshInterval != nil {
+		d.Meta.RefreshInterval = refreshIntervalUpdate(*u.RefreshInterval)
+	}
+
 	return nil
 }
 
 // Valid returns an error if the dashboard update is invalid.
 func (u DashboardUpdate) Valid() *Error {
-	if u.Name == nil && u.Description == nil {
+	if u.Name == nil && u.Description == nil && u.RefreshInterval == nil {
 		return &Error{
 			Code: EInvalid,
 			Msg:  "must update at least one attribute",
 		}
 	}
 
-	return nil
+	return ValidRefreshInterval(u.RefreshInterval)
+}
+
+func refreshIntervalUpdate(d Duration) *Duration {
+	if d.Duration == 0 {
+		return nil
+	}
+	return &d
 }
 
 // AddDashboardCellOptions are options for adding a dashboard.
@@ -263,6 +317,9 @@ type CellUpdate struct {
 	Y *int32 `json:"y"`
 	W *int32 `json:"w"`
 	H *int32 `json:"h"`
+	// RefreshInterval updates the cell's refresh interval override. A zero
+	// duration removes the override.
+	RefreshInterval *Duration `json:"refreshInterval"`
 }
 
 // Apply applies an update to a Cell.
@@ -283,19 +340,23 @@ func (u CellUpdate) Apply(c *Cell) error {
 		c.H = *u.H
 	}
 
+	if u.RefreshInterval != nil {
+		c.RefreshInterval = refreshIntervalUpdate(*u.RefreshInterval)
+	}
+
 	return nil
 }
 
 // Valid returns an error if the cell update is invalid.
 func (u CellUpdate) Valid() *Error {
-	if u.H == nil && u.W == nil && u.Y == nil && u.X == nil {
+	if u.H == nil && u.W == nil && u.Y == nil && u.X == nil && u.RefreshInterval == nil {
 		return &Error{
 			Code: EInvalid,
 			Msg:  "must update at least one attribute",
 		}
 	}
 
-	return nil
+	return ValidRefreshInterval(u.RefreshInterval)
 }
 
 // ViewUpdate is a struct for updating Views.
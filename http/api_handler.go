@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/influxdata/influxdb"
@@ -27,6 +28,12 @@ type APIBackend struct {
 	Logger     *zap.Logger
 	influxdb.HTTPErrorHandler
 	SessionRenewDisabled bool
+	// RequestTimeout is the default timeout of API requests, the routes in
+	// apiRouteTimeouts override it. A zero timeout disables the default.
+	RequestTimeout time.Duration
+	// AdvertisedURL is the url clients and agents reach the server at, it
+	// defaults to the host of each request if empty.
+	AdvertisedURL string
 
 	NewBucketService func(*influxdb.Source) (influxdb.BucketService, error)
 	NewQueryService  func(*influxdb.Source) (query.ProxyQueryService, error)
@@ -64,7 +71,13 @@ type APIBackend struct {
 	OrgLookupService                authorizer.OrganizationService
 	DocumentService                 influxdb.DocumentService
 	NotificationRuleStore           influxdb.NotificationRuleStore
+	NotificationRuleHistoryService  influxdb.NotificationRuleHistoryService
+	MaintenanceWindowService        influxdb.MaintenanceWindowService
 	NotificationEndpointService     influxdb.NotificationEndpointService
+	UserActivityService             influxdb.UserActivityService
+
+	// UserActivityRecorder records the users authenticated, it is optional.
+	UserActivityRecorder *UserActivityRecorder
 }
 
 // PrometheusCollectors exposes the prometheus collectors associated with an APIBackend.
@@ -152,6 +165,10 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 		b.UserResourceMappingService, b.OrganizationService)
 	h.Mount(prefixNotificationRules, NewNotificationRuleHandler(b.Logger, notificationRuleBackend))
 
+	maintenanceWindowBackend := NewMaintenanceWindowBackend(b.Logger.With(zap.String("handler", "maintenance_window")), b)
+	maintenanceWindowBackend.MaintenanceWindowService = authorizer.NewMaintenanceWindowService(b.MaintenanceWindowService)
+	h.Mount(prefixMaintenanceWindows, NewMaintenanceWindowHandler(b.Logger, maintenanceWindowBackend))
+
 	orgBackend := NewOrgBackend(b.Logger.With(zap.String("handler", "org")), b)
 	orgBackend.OrganizationService = authorizer.NewOrgService(b.OrganizationService)
 	h.Mount(prefixOrganizations, NewOrgHandler(b.Logger, orgBackend))
@@ -184,6 +201,8 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 
 	telegrafBackend := NewTelegrafBackend(b.Logger.With(zap.String("handler", "telegraf")), b)
 	telegrafBackend.TelegrafService = authorizer.NewTelegrafConfigService(b.TelegrafService, b.UserResourceMappingService)
+	telegrafBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	telegrafBackend.AuthorizationService = authorizer.NewAuthorizationService(b.AuthorizationService)
 	h.Mount(prefixTelegrafPlugins, NewTelegrafHandler(b.Logger, telegrafBackend))
 	h.Mount(prefixTelegraf, NewTelegrafHandler(b.Logger, telegrafBackend))
 
@@ -207,6 +226,15 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	return h
 }
 
+// apiRouteTimeouts override the request timeout of the APIBackend by route.
+// Queries, writes and deletes legitimately run long and stream their bodies,
+// they are never timed out.
+var apiRouteTimeouts = []RouteTimeout{
+	{Prefix: prefixDelete, Exempt: true},
+	{Prefix: prefixQuery, Exempt: true},
+	{Prefix: prefixWrite, Exempt: true},
+}
+
 var apiLinks = map[string]interface{}{
 	// when adding new links, please take care to keep this list alphabetical
 	// as this makes it easier to verify values against the swagger document.
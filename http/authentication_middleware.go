@@ -25,6 +25,9 @@ type AuthenticationHandler struct {
 	TokenParser          *jsonweb.TokenParser
 	SessionRenewDisabled bool
 
+	// UserActivity records the users authenticated, it is optional.
+	UserActivity *UserActivityRecorder
+
 	// This is only really used for it's lookup method the specific http
 	// handler used to register routes does not matter.
 	noAuthRouter *httprouter.Router
@@ -113,6 +116,9 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 			InactiveUserError(ctx, h, w)
 			return
 		}
+		if h.UserActivity != nil {
+			h.UserActivity.Seen(auth.GetUserID())
+		}
 	}
 
 	ctx = platcontext.SetAuthorizer(ctx, auth)
@@ -139,6 +139,8 @@ type bucket struct {
 	Name                string          `json:"name"`
 	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
 	RetentionRules      []retentionRule `json:"retentionRules"`
+	// MeasurementRetentionRules expire individual measurements of the bucket.
+	MeasurementRetentionRules []measurementRetentionRule `json:"measurementRetentionRules,omitempty"`
 	influxdb.CRUDLog
 }
 
@@ -160,6 +162,42 @@ func (rr *retentionRule) RetentionPeriod() (time.Duration, error) {
 	return t, nil
 }
 
+// measurementRetentionRule is the retention rule for a single measurement of a bucket.
+type measurementRetentionRule struct {
+	Measurement  string `json:"measurement"`
+	EverySeconds int64  `json:"everySeconds"`
+}
+
+func toMeasurementRetentionPolicies(rules []measurementRetentionRule) []influxdb.MeasurementRetentionPolicy {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	policies := make([]influxdb.MeasurementRetentionPolicy, 0, len(rules))
+	for _, r := range rules {
+		policies = append(policies, influxdb.MeasurementRetentionPolicy{
+			Measurement:     r.Measurement,
+			RetentionPeriod: time.Duration(r.EverySeconds) * time.Second,
+		})
+	}
+	return policies
+}
+
+func newMeasurementRetentionRules(policies []influxdb.MeasurementRetentionPolicy) []measurementRetentionRule {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	rules := make([]measurementRetentionRule, 0, len(policies))
+	for _, p := range policies {
+		rules = append(rules, measurementRetentionRule{
+			Measurement:  p.Measurement,
+			EverySeconds: int64(p.RetentionPeriod.Round(time.Second) / time.Second),
+		})
+	}
+	return rules
+}
+
 func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 	if b == nil {
 		return nil, nil
@@ -179,14 +217,15 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 	}
 
 	return &influxdb.Bucket{
-		ID:                  b.ID,
-		OrgID:               b.OrgID,
-		Type:                influxdb.ParseBucketType(b.Type),
-		Description:         b.Description,
-		Name:                b.Name,
-		RetentionPolicyName: b.RetentionPolicyName,
-		RetentionPeriod:     d,
-		CRUDLog:             b.CRUDLog,
+		ID:                           b.ID,
+		OrgID:                        b.OrgID,
+		Type:                         influxdb.ParseBucketType(b.Type),
+		Description:                  b.Description,
+		Name:                         b.Name,
+		RetentionPolicyName:          b.RetentionPolicyName,
+		RetentionPeriod:              d,
+		MeasurementRetentionPolicies: toMeasurementRetentionPolicies(b.MeasurementRetentionRules),
+		CRUDLog:                      b.CRUDLog,
 	}, nil
 }
 
@@ -205,14 +244,15 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 	}
 
 	return &bucket{
-		ID:                  pb.ID,
-		OrgID:               pb.OrgID,
-		Type:                pb.Type.String(),
-		Name:                pb.Name,
-		Description:         pb.Description,
-		RetentionPolicyName: pb.RetentionPolicyName,
-		RetentionRules:      rules,
-		CRUDLog:             pb.CRUDLog,
+		ID:                        pb.ID,
+		OrgID:                     pb.OrgID,
+		Type:                      pb.Type.String(),
+		Name:                      pb.Name,
+		Description:               pb.Description,
+		RetentionPolicyName:       pb.RetentionPolicyName,
+		RetentionRules:            rules,
+		MeasurementRetentionRules: newMeasurementRetentionRules(pb.MeasurementRetentionPolicies),
+		CRUDLog:                   pb.CRUDLog,
 	}
 }
 
@@ -221,6 +261,8 @@ type bucketUpdate struct {
 	Name           *string         `json:"name,omitempty"`
 	Description    *string         `json:"description,omitempty"`
 	RetentionRules []retentionRule `json:"retentionRules,omitempty"`
+
+	MeasurementRetentionRules *[]measurementRetentionRule `json:"measurementRetentionRules,omitempty"`
 }
 
 func (b *bucketUpdate) toInfluxDB() (*influxdb.BucketUpdate, error) {
@@ -239,11 +281,19 @@ func (b *bucketUpdate) toInfluxDB() (*influxdb.BucketUpdate, error) {
 		}
 	}
 
-	return &influxdb.BucketUpdate{
+	upd := &influxdb.BucketUpdate{
 		Name:            b.Name,
 		Description:     b.Description,
 		RetentionPeriod: &d,
-	}, nil
+	}
+	if b.MeasurementRetentionRules != nil {
+		policies := toMeasurementRetentionPolicies(*b.MeasurementRetentionRules)
+		if policies == nil {
+			policies = []influxdb.MeasurementRetentionPolicy{}
+		}
+		upd.MeasurementRetentionPolicies = &policies
+	}
+	return upd, nil
 }
 
 func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
@@ -264,6 +314,14 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 			EverySeconds: d,
 		})
 	}
+
+	if pb.MeasurementRetentionPolicies != nil {
+		rules := newMeasurementRetentionRules(*pb.MeasurementRetentionPolicies)
+		if rules == nil {
+			rules = []measurementRetentionRule{}
+		}
+		up.MeasurementRetentionRules = &rules
+	}
 	return up
 }
 
@@ -351,6 +409,8 @@ type postBucketRequest struct {
 	Description         string          `json:"description"`
 	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
 	RetentionRules      []retentionRule `json:"retentionRules"`
+
+	MeasurementRetentionRules []measurementRetentionRule `json:"measurementRetentionRules,omitempty"`
 }
 
 func (b postBucketRequest) Validate() error {
@@ -377,12 +437,13 @@ func (b postBucketRequest) toInfluxDB() (*influxdb.Bucket, error) {
 	}
 
 	return &influxdb.Bucket{
-		OrgID:               b.OrgID,
-		Description:         b.Description,
-		Name:                b.Name,
-		Type:                influxdb.BucketTypeUser,
-		RetentionPolicyName: b.RetentionPolicyName,
-		RetentionPeriod:     dur,
+		OrgID:                        b.OrgID,
+		Description:                  b.Description,
+		Name:                         b.Name,
+		Type:                         influxdb.BucketTypeUser,
+		RetentionPolicyName:          b.RetentionPolicyName,
+		RetentionPeriod:              dur,
+		MeasurementRetentionPolicies: toMeasurementRetentionPolicies(b.MeasurementRetentionRules),
 	}, err
 }
 
@@ -1114,7 +1114,8 @@ func TestService_handleUpdateCheck(t *testing.T) {
 						OwnerID: 42,
 						OrgID:   influxTesting.MustIDBase16("020f755c3c082000"),
 					},
-					Level: notification.Critical,
+					StaleTime: mustDuration("1h"),
+					Level:     notification.Critical,
 				},
 			},
 			wants: wants{
@@ -1150,6 +1151,7 @@ func TestService_handleUpdateCheck(t *testing.T) {
             "text": ""
           },
           "reportZero": false,
+          "staleTime": "1h",
           "status": "active",
           "statusMessageTemplate": "",
           "tags": null,
@@ -1179,6 +1181,7 @@ func TestService_handleUpdateCheck(t *testing.T) {
 						OwnerID: 42,
 						OrgID:   influxTesting.MustIDBase16("020f755c3c082000"),
 					},
+					StaleTime: mustDuration("1h"),
 				},
 			},
 			wants: wants{
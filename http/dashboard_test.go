@@ -1425,7 +1425,7 @@ func TestService_handlePostDashboardCell(t *testing.T) {
 				body: `
 				{
 					"code": "invalid",
-					"message": "id must have a length of 16 bytes"
+					"message": "invalid ID \"fff\": must be a 16 character hex string"
 				}`,
 			},
 		},
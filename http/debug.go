@@ -10,11 +10,22 @@ type Flusher interface {
 	Flush(ctx context.Context)
 }
 
-// DebugFlush clears all services for testing.
+// Resetter resets a store to hold no data at all, where a Flusher may leave
+// the structure of the data, i.e. empty buckets, in place; used for testing.
+type Resetter interface {
+	Reset(ctx context.Context)
+}
+
+// DebugFlush clears all services for testing. A flush with the reset=true
+// query parameter resets the services when f is a Resetter.
 func DebugFlush(ctx context.Context, next http.Handler, f Flusher) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/debug/flush" {
-			f.Flush(ctx)
+			if rs, ok := f.(Resetter); ok && r.URL.Query().Get("reset") == "true" {
+				rs.Reset(ctx)
+			} else {
+				f.Flush(ctx)
+			}
 			w.Header().Set("Content-Type", "text/html; charset=utf-8")
 			w.WriteHeader(http.StatusOK)
 			return
@@ -22,3 +33,14 @@ func DebugFlush(ctx context.Context, next http.Handler, f Flusher) http.HandlerF
 		next.ServeHTTP(w, r)
 	})
 }
+
+// DebugPanic panics on requests of /debug/panic; used for testing the
+// handling of panics.
+func DebugPanic(next http.Handler) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/debug/panic" {
+			panic("debug panic")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
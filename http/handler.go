@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
-	_ "net/http/pprof" // used for debug pprof at the default path.
+	"net/http/pprof"
 	"strings"
 	"time"
 
@@ -25,6 +25,8 @@ const (
 	HealthPath = "/health"
 	// DebugPath exposes /debug/pprof for go debugging.
 	DebugPath = "/debug"
+	// PprofPath exposes the go profiles over /debug/pprof.
+	PprofPath = DebugPath + "/pprof"
 )
 
 // Handler provides basic handling of metrics, health and debug endpoints.
@@ -59,7 +61,7 @@ func NewHandler(name string) *Handler {
 	h := &Handler{
 		name:           name,
 		MetricsHandler: promhttp.Handler(),
-		DebugHandler:   http.DefaultServeMux,
+		DebugHandler:   NewPprofHandler(),
 	}
 	h.initMetrics()
 	return h
@@ -74,7 +76,7 @@ func NewHandlerFromRegistry(log *zap.Logger, name string, reg *prom.Registry) *H
 		MetricsHandler: reg.HTTPHandler(),
 		ReadyHandler:   http.HandlerFunc(ReadyHandler),
 		HealthHandler:  http.HandlerFunc(HealthHandler),
-		DebugHandler:   http.DefaultServeMux,
+		DebugHandler:   NewPprofHandler(),
 		log:            log,
 	}
 	h.initMetrics()
@@ -82,6 +84,19 @@ func NewHandlerFromRegistry(log *zap.Logger, name string, reg *prom.Registry) *H
 	return h
 }
 
+// NewPprofHandler returns a handler of the go profiles at /debug/pprof. The
+// profiles are mounted explicitly, the default mux is never served, so they are
+// only reachable from the handlers they are mounted on.
+func NewPprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PprofPath+"/", pprof.Index)
+	mux.HandleFunc(PprofPath+"/cmdline", pprof.Cmdline)
+	mux.HandleFunc(PprofPath+"/profile", pprof.Profile)
+	mux.HandleFunc(PprofPath+"/symbol", pprof.Symbol)
+	mux.HandleFunc(PprofPath+"/trace", pprof.Trace)
+	return mux
+}
+
 // ServeHTTP delegates a request to the appropriate subhandler.
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var span opentracing.Span
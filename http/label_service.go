@@ -331,19 +331,30 @@ func newGetLabelsHandler(b *LabelBackend) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		req, err := decodeGetLabelMappingsRequest(ctx, b.ResourceType)
+		req, err := decodeGetLabelMappingsRequest(ctx, r, b.ResourceType)
 		if err != nil {
 			b.HandleHTTPError(ctx, err, w)
 			return
 		}
 
-		labels, err := b.LabelService.FindResourceLabels(ctx, req.filter)
+		labels, err := b.LabelService.FindResourceLabels(ctx, req.filter, req.opts...)
 		if err != nil {
 			b.HandleHTTPError(ctx, err, w)
 			return
 		}
 
-		if err := encodeResponse(ctx, w, http.StatusOK, newLabelsResponse(labels)); err != nil {
+		res := newLabelsResponse(labels)
+		if len(req.opts) > 0 {
+			base := r.URL.Path
+			res.Links["self"] = base
+			if len(labels) > 0 {
+				if next := nextAfterLink(base, req.opts[0], len(labels), labels[len(labels)-1].ID); next != "" {
+					res.Links["next"] = next
+				}
+			}
+		}
+
+		if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
 			logEncodingError(b.log, r, err)
 			return
 		}
@@ -352,9 +363,10 @@ func newGetLabelsHandler(b *LabelBackend) http.HandlerFunc {
 
 type getLabelMappingsRequest struct {
 	filter influxdb.LabelMappingFilter
+	opts   []influxdb.FindOptions
 }
 
-func decodeGetLabelMappingsRequest(ctx context.Context, rt influxdb.ResourceType) (*getLabelMappingsRequest, error) {
+func decodeGetLabelMappingsRequest(ctx context.Context, r *http.Request, rt influxdb.ResourceType) (*getLabelMappingsRequest, error) {
 	req := &getLabelMappingsRequest{}
 
 	params := httprouter.ParamsFromContext(ctx)
@@ -373,6 +385,12 @@ func decodeGetLabelMappingsRequest(ctx context.Context, rt influxdb.ResourceType
 	req.filter.ResourceID = i
 	req.filter.ResourceType = rt
 
+	opts, err := decodeOptionalFindOptions(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req.opts = opts
+
 	return req, nil
 }
 
@@ -562,7 +580,7 @@ func (s *LabelService) FindLabels(ctx context.Context, filter influxdb.LabelFilt
 }
 
 // FindResourceLabels returns a list of labels, derived from a label mapping filter.
-func (s *LabelService) FindResourceLabels(ctx context.Context, filter influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+func (s *LabelService) FindResourceLabels(ctx context.Context, filter influxdb.LabelMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.Label, error) {
 	if err := filter.Valid(); err != nil {
 		return nil, err
 	}
@@ -570,6 +588,7 @@ func (s *LabelService) FindResourceLabels(ctx context.Context, filter influxdb.L
 	var r labelsResponse
 	err := s.Client.
 		Get(resourceIDPath(filter.ResourceType, filter.ResourceID, "labels")).
+		QueryParams(findOptionParams(opt...)...).
 		DecodeJSON(&r).
 		Do(ctx)
 	if err != nil {
@@ -0,0 +1,238 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+const prefixMaintenanceWindows = "/api/v2/maintenanceWindows"
+
+// MaintenanceWindowBackend is all services and associated parameters required to
+// construct the MaintenanceWindowHandler.
+type MaintenanceWindowBackend struct {
+	influxdb.HTTPErrorHandler
+	log                      *zap.Logger
+	MaintenanceWindowService influxdb.MaintenanceWindowService
+}
+
+// NewMaintenanceWindowBackend creates a backend used by the maintenance window handler.
+func NewMaintenanceWindowBackend(log *zap.Logger, b *APIBackend) *MaintenanceWindowBackend {
+	return &MaintenanceWindowBackend{
+		HTTPErrorHandler:         b.HTTPErrorHandler,
+		log:                      log,
+		MaintenanceWindowService: b.MaintenanceWindowService,
+	}
+}
+
+// MaintenanceWindowHandler is the handler for the maintenance window service.
+type MaintenanceWindowHandler struct {
+	*httprouter.Router
+
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	MaintenanceWindowService influxdb.MaintenanceWindowService
+}
+
+// NewMaintenanceWindowHandler creates a new MaintenanceWindowHandler.
+func NewMaintenanceWindowHandler(log *zap.Logger, b *MaintenanceWindowBackend) *MaintenanceWindowHandler {
+	h := &MaintenanceWindowHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		MaintenanceWindowService: b.MaintenanceWindowService,
+	}
+
+	entityPath := fmt.Sprintf("%s/:id", prefixMaintenanceWindows)
+
+	h.HandlerFunc("GET", prefixMaintenanceWindows, h.handleGetMaintenanceWindows)
+	h.HandlerFunc("POST", prefixMaintenanceWindows, h.handlePostMaintenanceWindow)
+	h.HandlerFunc("GET", entityPath, h.handleGetMaintenanceWindow)
+	h.HandlerFunc("PUT", entityPath, h.handlePutMaintenanceWindow)
+	h.HandlerFunc("DELETE", entityPath, h.handleDeleteMaintenanceWindow)
+
+	return h
+}
+
+type maintenanceWindowLinks struct {
+	Self string `json:"self"`
+	Org  string `json:"org"`
+}
+
+type maintenanceWindowResponse struct {
+	*influxdb.MaintenanceWindow
+	Links maintenanceWindowLinks `json:"links"`
+}
+
+func newMaintenanceWindowResponse(w *influxdb.MaintenanceWindow) maintenanceWindowResponse {
+	return maintenanceWindowResponse{
+		MaintenanceWindow: w,
+		Links: maintenanceWindowLinks{
+			Self: fmt.Sprintf("%s/%s", prefixMaintenanceWindows, w.ID),
+			Org:  fmt.Sprintf("/api/v2/orgs/%s", w.OrgID),
+		},
+	}
+}
+
+type maintenanceWindowsResponse struct {
+	MaintenanceWindows []maintenanceWindowResponse `json:"maintenanceWindows"`
+}
+
+func newMaintenanceWindowsResponse(ws []*influxdb.MaintenanceWindow) maintenanceWindowsResponse {
+	res := maintenanceWindowsResponse{
+		MaintenanceWindows: make([]maintenanceWindowResponse, 0, len(ws)),
+	}
+	for _, w := range ws {
+		res.MaintenanceWindows = append(res.MaintenanceWindows, newMaintenanceWindowResponse(w))
+	}
+	return res
+}
+
+func (h *MaintenanceWindowHandler) handleGetMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var filter influxdb.MaintenanceWindowFilter
+	if orgID := r.URL.Query().Get("orgID"); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		filter.OrgID = id
+	}
+
+	ws, err := h.MaintenanceWindowService.FindMaintenanceWindows(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Maintenance windows retrieved", zap.Int("count", len(ws)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newMaintenanceWindowsResponse(ws)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *MaintenanceWindowHandler) handleGetMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestMaintenanceWindowID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	mw, err := h.MaintenanceWindowService.FindMaintenanceWindowByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Maintenance window retrieved", zap.String("maintenanceWindow", fmt.Sprint(mw)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newMaintenanceWindowResponse(mw)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *MaintenanceWindowHandler) handlePostMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	mw, err := decodeMaintenanceWindow(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.MaintenanceWindowService.CreateMaintenanceWindow(ctx, mw); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Maintenance window created", zap.String("maintenanceWindow", fmt.Sprint(mw)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newMaintenanceWindowResponse(mw)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *MaintenanceWindowHandler) handlePutMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestMaintenanceWindowID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	upd, err := decodeMaintenanceWindow(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	mw, err := h.MaintenanceWindowService.UpdateMaintenanceWindow(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Maintenance window replaced", zap.String("maintenanceWindow", fmt.Sprint(mw)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newMaintenanceWindowResponse(mw)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *MaintenanceWindowHandler) handleDeleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestMaintenanceWindowID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.MaintenanceWindowService.DeleteMaintenanceWindow(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Maintenance window deleted", zap.String("maintenanceWindowID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func requestMaintenanceWindowID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	urlID := params.ByName("id")
+	if urlID == "" {
+		return influxdb.InvalidID(), &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	id, err := influxdb.IDFromString(urlID)
+	if err != nil {
+		return influxdb.InvalidID(), err
+	}
+	return *id, nil
+}
+
+// decodeMaintenanceWindow decodes the window of a request, the window is
+// validated by the service once its org is known.
+func decodeMaintenanceWindow(r *http.Request) (*influxdb.MaintenanceWindow, error) {
+	var mw influxdb.MaintenanceWindow
+	if err := json.NewDecoder(r.Body).Decode(&mw); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode maintenance window",
+			Err:  err,
+		}
+	}
+	return &mw, nil
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMaintenanceWindowHandler(t *testing.T) {
+	svc := mock.NewMaintenanceWindowService()
+	svc.CreateMaintenanceWindowFn = func(_ context.Context, w *influxdb.MaintenanceWindow) error {
+		w.ID = 1
+		return nil
+	}
+	svc.FindMaintenanceWindowsFn = func(_ context.Context, filter influxdb.MaintenanceWindowFilter) ([]*influxdb.MaintenanceWindow, error) {
+		if filter.OrgID == nil || *filter.OrgID != 2 {
+			t.Errorf("unexpected filter: %+v", filter)
+		}
+		return []*influxdb.MaintenanceWindow{{ID: 1, OrgID: 2, Name: "patching"}}, nil
+	}
+
+	h := NewMaintenanceWindowHandler(zaptest.NewLogger(t), &MaintenanceWindowBackend{
+		HTTPErrorHandler:         ErrorHandler(0),
+		log:                      zaptest.NewLogger(t),
+		MaintenanceWindowService: svc,
+	})
+
+	t.Run("creates a maintenance window", func(t *testing.T) {
+		start := time.Date(2020, 1, 6, 2, 0, 0, 0, time.UTC)
+		b, err := json.Marshal(influxdb.MaintenanceWindow{
+			OrgID:  2,
+			Name:   "patching",
+			Start:  start,
+			End:    start.Add(time.Hour),
+			Labels: []string{"staging"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, prefixMaintenanceWindows, bytes.NewReader(b)))
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("unexpected status code: %d: %s", w.Code, w.Body.String())
+		}
+		var res maintenanceWindowResponse
+		if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		if res.ID != 1 || res.Links.Self != "/api/v2/maintenanceWindows/0000000000000001" {
+			t.Errorf("unexpected response: %+v", res)
+		}
+	})
+
+	t.Run("finds the maintenance windows of an org", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, prefixMaintenanceWindows+"?orgID=0000000000000002", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: %d: %s", w.Code, w.Body.String())
+		}
+		var res maintenanceWindowsResponse
+		if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		if len(res.MaintenanceWindows) != 1 || res.MaintenanceWindows[0].Name != "patching" {
+			t.Errorf("unexpected response: %+v", res)
+		}
+	})
+}
@@ -24,13 +24,14 @@ type NotificationRuleBackend struct {
 	influxdb.HTTPErrorHandler
 	log *zap.Logger
 
-	NotificationRuleStore       influxdb.NotificationRuleStore
-	NotificationEndpointService influxdb.NotificationEndpointService
-	UserResourceMappingService  influxdb.UserResourceMappingService
-	LabelService                influxdb.LabelService
-	UserService                 influxdb.UserService
-	OrganizationService         influxdb.OrganizationService
-	TaskService                 influxdb.TaskService
+	NotificationRuleStore          influxdb.NotificationRuleStore
+	NotificationRuleHistoryService influxdb.NotificationRuleHistoryService
+	NotificationEndpointService    influxdb.NotificationEndpointService
+	UserResourceMappingService     influxdb.UserResourceMappingService
+	LabelService                   influxdb.LabelService
+	UserService                    influxdb.UserService
+	OrganizationService            influxdb.OrganizationService
+	TaskService                    influxdb.TaskService
 }
 
 // NewNotificationRuleBackend returns a new instance of NotificationRuleBackend.
@@ -39,13 +40,14 @@ func NewNotificationRuleBackend(log *zap.Logger, b *APIBackend) *NotificationRul
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              log,
 
-		NotificationRuleStore:       b.NotificationRuleStore,
-		NotificationEndpointService: b.NotificationEndpointService,
-		UserResourceMappingService:  b.UserResourceMappingService,
-		LabelService:                b.LabelService,
-		UserService:                 b.UserService,
-		OrganizationService:         b.OrganizationService,
-		TaskService:                 b.TaskService,
+		NotificationRuleStore:          b.NotificationRuleStore,
+		NotificationRuleHistoryService: b.NotificationRuleHistoryService,
+		NotificationEndpointService:    b.NotificationEndpointService,
+		UserResourceMappingService:     b.UserResourceMappingService,
+		LabelService:                   b.LabelService,
+		UserService:                    b.UserService,
+		OrganizationService:            b.OrganizationService,
+		TaskService:                    b.TaskService,
 	}
 }
 
@@ -55,19 +57,21 @@ type NotificationRuleHandler struct {
 	influxdb.HTTPErrorHandler
 	log *zap.Logger
 
-	NotificationRuleStore       influxdb.NotificationRuleStore
-	NotificationEndpointService influxdb.NotificationEndpointService
-	UserResourceMappingService  influxdb.UserResourceMappingService
-	LabelService                influxdb.LabelService
-	UserService                 influxdb.UserService
-	OrganizationService         influxdb.OrganizationService
-	TaskService                 influxdb.TaskService
+	NotificationRuleStore          influxdb.NotificationRuleStore
+	NotificationRuleHistoryService influxdb.NotificationRuleHistoryService
+	NotificationEndpointService    influxdb.NotificationEndpointService
+	UserResourceMappingService     influxdb.UserResourceMappingService
+	LabelService                   influxdb.LabelService
+	UserService                    influxdb.UserService
+	OrganizationService            influxdb.OrganizationService
+	TaskService                    influxdb.TaskService
 }
 
 const (
 	prefixNotificationRules          = "/api/v2/notificationRules"
 	notificationRulesIDPath          = "/api/v2/notificationRules/:id"
 	notificationRulesIDQueryPath     = "/api/v2/notificationRules/:id/query"
+	notificationRulesIDHistoryPath   = "/api/v2/notificationRules/:id/history"
 	notificationRulesIDMembersPath   = "/api/v2/notificationRules/:id/members"
 	notificationRulesIDMembersIDPath = "/api/v2/notificationRules/:id/members/:userID"
 	notificationRulesIDOwnersPath    = "/api/v2/notificationRules/:id/owners"
@@ -83,18 +87,20 @@ func NewNotificationRuleHandler(log *zap.Logger, b *NotificationRuleBackend) *No
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              log,
 
-		NotificationRuleStore:       b.NotificationRuleStore,
-		NotificationEndpointService: b.NotificationEndpointService,
-		UserResourceMappingService:  b.UserResourceMappingService,
-		LabelService:                b.LabelService,
-		UserService:                 b.UserService,
-		OrganizationService:         b.OrganizationService,
-		TaskService:                 b.TaskService,
+		NotificationRuleStore:          b.NotificationRuleStore,
+		NotificationRuleHistoryService: b.NotificationRuleHistoryService,
+		NotificationEndpointService:    b.NotificationEndpointService,
+		UserResourceMappingService:     b.UserResourceMappingService,
+		LabelService:                   b.LabelService,
+		UserService:                    b.UserService,
+		OrganizationService:            b.OrganizationService,
+		TaskService:                    b.TaskService,
 	}
 	h.HandlerFunc("POST", prefixNotificationRules, h.handlePostNotificationRule)
 	h.HandlerFunc("GET", prefixNotificationRules, h.handleGetNotificationRules)
 	h.HandlerFunc("GET", notificationRulesIDPath, h.handleGetNotificationRule)
 	h.HandlerFunc("GET", notificationRulesIDQueryPath, h.handleGetNotificationRuleQuery)
+	h.HandlerFunc("GET", notificationRulesIDHistoryPath, h.handleGetNotificationRuleHistory)
 	h.HandlerFunc("DELETE", notificationRulesIDPath, h.handleDeleteNotificationRule)
 	h.HandlerFunc("PUT", notificationRulesIDPath, h.handlePutNotificationRule)
 	h.HandlerFunc("PATCH", notificationRulesIDPath, h.handlePatchNotificationRule)
@@ -298,6 +304,44 @@ func (h *NotificationRuleHandler) handleGetNotificationRuleQuery(w http.Response
 	}
 }
 
+type notificationRuleHistoryResponse struct {
+	Links   map[string]string                        `json:"links"`
+	History []*influxdb.NotificationRuleHistoryEntry `json:"history"`
+}
+
+func (h *NotificationRuleHandler) handleGetNotificationRuleHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeGetNotificationRuleRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	// the rule is retrieved first to ensure the caller may read it
+	nr, err := h.NotificationRuleStore.FindNotificationRuleByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	history, err := h.NotificationRuleHistoryService.FindNotificationRuleHistory(ctx, nr.GetID())
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Notification rule history retrieved", zap.String("notificationRuleID", id.String()), zap.Int("entries", len(history)))
+
+	res := notificationRuleHistoryResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/notificationRules/%s/history", id),
+			"rule": fmt.Sprintf("/api/v2/notificationRules/%s", id),
+		},
+		History: history,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
 func (h *NotificationRuleHandler) handleGetNotificationRule(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	id, err := decodeGetNotificationRuleRequest(ctx, r)
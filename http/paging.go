@@ -64,9 +64,58 @@ func decodeFindOptions(ctx context.Context, r *http.Request) (*platform.FindOpti
 		opts.Descending = desc
 	}
 
+	if after := qp.Get("after"); after != "" {
+		id, err := platform.IDFromString(after)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "after is invalid",
+			}
+		}
+
+		opts.After = id
+	}
+
 	return opts, nil
 }
 
+// decodeOptionalFindOptions returns the find options of the request, or none
+// when it has no paging params, for the listings that are not paged unless
+// asked to be.
+func decodeOptionalFindOptions(ctx context.Context, r *http.Request) ([]platform.FindOptions, error) {
+	qp := r.URL.Query()
+	if qp.Get("limit") == "" && qp.Get("offset") == "" && qp.Get("after") == "" {
+		return nil, nil
+	}
+
+	opts, err := decodeFindOptions(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return []platform.FindOptions{*opts}, nil
+}
+
+// nextAfterLink returns the link to the page following the page of num
+// results ending with the ID last, or an empty string if it is the last page.
+func nextAfterLink(basePath string, opts platform.FindOptions, num int, last platform.ID) string {
+	if opts.Limit <= 0 || num < opts.Limit {
+		return ""
+	}
+
+	opts.Offset = 0
+	opts.After = &last
+	values := url.Values{}
+	for k, vs := range opts.QueryParams() {
+		for _, v := range vs {
+			if v != "" {
+				values.Add(k, v)
+			}
+		}
+	}
+	u := url.URL{Path: basePath, RawQuery: values.Encode()}
+	return u.String()
+}
+
 func findOptionParams(opts ...platform.FindOptions) [][2]string {
 	var out [][2]string
 	for _, o := range opts {
@@ -10,6 +10,7 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
 	pctx "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/pkg/httpc"
@@ -47,6 +48,8 @@ func NewHandlerPkg(log *zap.Logger, errHandler influxdb.HTTPErrorHandler, svc pk
 			Post("/", svr.createPkg)
 		r.With(middleware.SetHeader("Content-Type", "application/json; charset=utf-8")).
 			Post("/apply", svr.applyPkg)
+		r.With(middleware.SetHeader("Content-Type", "application/schema+json")).
+			Get("/schema", svr.getSchema)
 	}
 
 	svr.Router = r
@@ -66,6 +69,7 @@ type (
 		PkgVersion     string                  `json:"pkgVersion"`
 		OrgIDs         []string                `json:"orgIDs"`
 		Resources      []pkger.ResourceToClone `json:"resources"`
+		FormatQueries  bool                    `json:"formatQueries"`
 	}
 
 	// RespCreatePkg is a response body for the create pkg endpoint.
@@ -98,6 +102,9 @@ func (s *HandlerPkg) createPkg(w http.ResponseWriter, r *http.Request) {
 		}
 		opts = append(opts, pkger.CreateWithAllOrgResources(*orgID))
 	}
+	if reqBody.FormatQueries {
+		opts = append(opts, pkger.CreateWithFormattedQueries())
+	}
 
 	newPkg, err := s.svc.CreatePkg(r.Context(), opts...)
 	if err != nil {
@@ -126,6 +133,12 @@ type (
 		DryRun bool       `json:"dryRun" yaml:"dryRun"`
 		OrgID  string     `json:"orgID" yaml:"orgID"`
 		Pkg    *pkger.Pkg `json:"package" yaml:"package"`
+
+		// OrgIDs applies the pkg to each of the orgs instead of the one of
+		// OrgID, it requires an operator token.
+		OrgIDs []string `json:"orgIDs,omitempty" yaml:"orgIDs,omitempty"`
+
+		Secrets map[string]string `json:"secrets,omitempty" yaml:"secrets,omitempty"`
 	}
 
 	// RespApplyPkg is the response body for the apply pkg endpoint.
@@ -135,6 +148,21 @@ type (
 
 		Errors []pkger.ValidationErr `json:"errors,omitempty" yaml:"errors,omitempty"`
 	}
+
+	// RespApplyPkgOrgs is the response body for the apply pkg endpoint when
+	// the pkg is applied to multiple orgs, the outcome of each by org ID.
+	RespApplyPkgOrgs struct {
+		Orgs map[string]RespApplyPkgOrg `json:"orgs" yaml:"orgs"`
+
+		Errors []pkger.ValidationErr `json:"errors,omitempty" yaml:"errors,omitempty"`
+	}
+
+	// RespApplyPkgOrg is the outcome of applying a pkg to one of the orgs.
+	RespApplyPkgOrg struct {
+		Summary pkger.Summary `json:"summary" yaml:"summary"`
+		Code    string        `json:"code,omitempty" yaml:"code,omitempty"`
+		Error   string        `json:"error,omitempty" yaml:"error,omitempty"`
+	}
 )
 
 func (s *HandlerPkg) applyPkg(w http.ResponseWriter, r *http.Request) {
@@ -145,6 +173,11 @@ func (s *HandlerPkg) applyPkg(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(reqBody.OrgIDs) > 0 {
+		s.applyPkgToOrgs(w, r, reqBody)
+		return
+	}
+
 	orgID, err := influxdb.IDFromString(reqBody.OrgID)
 	if err != nil {
 		s.HandleHTTPError(r.Context(), &influxdb.Error{
@@ -161,8 +194,10 @@ func (s *HandlerPkg) applyPkg(w http.ResponseWriter, r *http.Request) {
 	}
 	userID := auth.GetUserID()
 
+	applyOpts := []pkger.ApplyOptFn{pkger.ApplyWithSecrets(reqBody.Secrets)}
+
 	parsedPkg := reqBody.Pkg
-	sum, diff, err := s.svc.DryRun(r.Context(), *orgID, userID, parsedPkg)
+	sum, diff, err := s.svc.DryRun(r.Context(), *orgID, userID, parsedPkg, applyOpts...)
 	if pkger.IsParseErr(err) {
 		s.encJSONResp(r.Context(), w, http.StatusUnprocessableEntity, RespApplyPkg{
 			Diff:    diff,
@@ -186,7 +221,7 @@ func (s *HandlerPkg) applyPkg(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sum, err = s.svc.Apply(r.Context(), *orgID, userID, parsedPkg)
+	sum, err = s.svc.Apply(r.Context(), *orgID, userID, parsedPkg, applyOpts...)
 	if err != nil && !pkger.IsParseErr(err) {
 		s.logger.Error("failed to apply pkg", zap.Error(err))
 		s.HandleHTTPError(r.Context(), err, w)
@@ -200,6 +235,86 @@ func (s *HandlerPkg) applyPkg(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// applyPkgToOrgs applies the pkg to each of the orgs of the request, it is
+// restricted to operator tokens.
+func (s *HandlerPkg) applyPkgToOrgs(w http.ResponseWriter, r *http.Request, reqBody ReqApplyPkg) {
+	ctx := r.Context()
+
+	if reqBody.DryRun {
+		s.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "a pkg applied to multiple orgs can not be dry run",
+		}, w)
+		return
+	}
+
+	orgIDs := make([]influxdb.ID, 0, len(reqBody.OrgIDs))
+	for _, id := range reqBody.OrgIDs {
+		orgID, err := influxdb.IDFromString(id)
+		if err != nil {
+			s.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid organization ID provided: %q", id),
+			}, w)
+			return
+		}
+		orgIDs = append(orgIDs, *orgID)
+	}
+
+	err := authorizer.IsAllowed(ctx, influxdb.Permission{
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.OrgsResourceType},
+	})
+	if err != nil {
+		s.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  "applying a pkg to multiple orgs requires an operator token",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	auth, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		s.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	results, err := s.svc.ApplyToOrgs(ctx, auth.GetUserID(), reqBody.Pkg, orgIDs, pkger.ApplyWithSecrets(reqBody.Secrets))
+	if pkger.IsParseErr(err) {
+		s.encJSONResp(ctx, w, http.StatusUnprocessableEntity, RespApplyPkgOrgs{
+			Errors: convertParseErr(err),
+		})
+		return
+	}
+	if err != nil {
+		s.logger.Error("failed to apply pkg to orgs", zap.Error(err))
+		s.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	code := http.StatusCreated
+	resp := RespApplyPkgOrgs{Orgs: make(map[string]RespApplyPkgOrg, len(results))}
+	for orgID, res := range results {
+		orgResp := RespApplyPkgOrg{Summary: res.Summary}
+		if res.Err != nil {
+			orgResp.Code = influxdb.ErrorCode(res.Err)
+			orgResp.Error = res.Err.Error()
+			code = http.StatusMultiStatus
+		}
+		resp.Orgs[orgID.String()] = orgResp
+	}
+	s.encJSONResp(ctx, w, code, resp)
+}
+
+// getSchema responds with the JSON Schema describing the pkg format.
+func (s *HandlerPkg) getSchema(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(pkger.Schema()); err != nil {
+		s.logger.Error("failed to write pkg schema", zap.Error(err))
+	}
+}
+
 type encoder interface {
 	Encode(interface{}) error
 }
@@ -268,6 +383,7 @@ func (s *PkgerService) CreatePkg(ctx context.Context, setters ...pkger.CreatePkg
 		PkgVersion:     opt.Metadata.Version,
 		OrgIDs:         orgIDs,
 		Resources:      opt.Resources,
+		FormatQueries:  opt.FormatQueries,
 	}
 
 	var newPkg RespCreatePkg
@@ -288,23 +404,31 @@ func (s *PkgerService) CreatePkg(ctx context.Context, setters ...pkger.CreatePkg
 // DryRun provides a dry run of the pkg application. The pkg will be marked verified
 // for later calls to Apply. This func will be run on an Apply if it has not been run
 // already.
-func (s *PkgerService) DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg) (pkger.Summary, pkger.Diff, error) {
-	return s.apply(ctx, orgID, pkg, true)
+func (s *PkgerService) DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg, opts ...pkger.ApplyOptFn) (pkger.Summary, pkger.Diff, error) {
+	return s.apply(ctx, orgID, pkg, true, opts...)
 }
 
 // Apply will apply all the resources identified in the provided pkg. The entire pkg will be applied
 // in its entirety. If a failure happens midway then the entire pkg will be rolled back to the state
 // from before the pkg were applied.
-func (s *PkgerService) Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg) (pkger.Summary, error) {
-	sum, _, err := s.apply(ctx, orgID, pkg, false)
+func (s *PkgerService) Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg, opts ...pkger.ApplyOptFn) (pkger.Summary, error) {
+	sum, _, err := s.apply(ctx, orgID, pkg, false, opts...)
 	return sum, err
 }
 
-func (s *PkgerService) apply(ctx context.Context, orgID influxdb.ID, pkg *pkger.Pkg, dryRun bool) (pkger.Summary, pkger.Diff, error) {
+func (s *PkgerService) apply(ctx context.Context, orgID influxdb.ID, pkg *pkger.Pkg, dryRun bool, opts ...pkger.ApplyOptFn) (pkger.Summary, pkger.Diff, error) {
+	var opt pkger.ApplyOpt
+	for _, o := range opts {
+		if err := o(&opt); err != nil {
+			return pkger.Summary{}, pkger.Diff{}, err
+		}
+	}
+
 	reqBody := ReqApplyPkg{
-		OrgID:  orgID.String(),
-		DryRun: dryRun,
-		Pkg:    pkg,
+		OrgID:   orgID.String(),
+		DryRun:  dryRun,
+		Pkg:     pkg,
+		Secrets: opt.MissingSecrets,
 	}
 
 	var resp RespApplyPkg
@@ -319,8 +443,56 @@ func (s *PkgerService) apply(ctx context.Context, orgID influxdb.ID, pkg *pkger.
 	return resp.Summary, resp.Diff, pkger.NewParseError(resp.Errors...)
 }
 
+// ApplyToOrgs applies the pkg to each of the orgs, it requires an operator token.
+func (s *PkgerService) ApplyToOrgs(ctx context.Context, userID influxdb.ID, pkg *pkger.Pkg, orgIDs []influxdb.ID, opts ...pkger.ApplyOptFn) (map[influxdb.ID]pkger.OrgApplyResult, error) {
+	var opt pkger.ApplyOpt
+	for _, o := range opts {
+		if err := o(&opt); err != nil {
+			return nil, err
+		}
+	}
+
+	reqBody := ReqApplyPkg{
+		Pkg:     pkg,
+		OrgIDs:  make([]string, 0, len(orgIDs)),
+		Secrets: opt.MissingSecrets,
+	}
+	for _, orgID := range orgIDs {
+		reqBody.OrgIDs = append(reqBody.OrgIDs, orgID.String())
+	}
+
+	var resp RespApplyPkgOrgs
+	err := s.Client.
+		PostJSON(reqBody, prefixPackages, "/apply").
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := pkger.NewParseError(resp.Errors...); err != nil {
+		return nil, err
+	}
+
+	results := make(map[influxdb.ID]pkger.OrgApplyResult, len(resp.Orgs))
+	for id, orgResp := range resp.Orgs {
+		orgID, err := influxdb.IDFromString(id)
+		if err != nil {
+			return nil, err
+		}
+		res := pkger.OrgApplyResult{Summary: orgResp.Summary}
+		if orgResp.Error != "" {
+			res.Err = &influxdb.Error{
+				Code: orgResp.Code,
+				Msg:  orgResp.Error,
+			}
+		}
+		results[*orgID] = res
+	}
+	return results, nil
+}
+
 func convertParseErr(err error) []pkger.ValidationErr {
-	pErr, ok := err.(pkger.ParseError)
+	pErr, ok := err.(*pkger.ParseError)
 	if !ok {
 		return nil
 	}
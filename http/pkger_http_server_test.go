@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/influxdata/influxdb"
@@ -69,6 +71,33 @@ func TestPkgerHTTPServer(t *testing.T) {
 				})
 
 		})
+
+		t.Run("returns the warnings of the pkg", func(t *testing.T) {
+			warnings := []string{`Dashboard "dash_1" references unknown variable "var_1"`}
+			svc := &fakeSVC{
+				CreatePkgFn: func(ctx context.Context, setters ...pkger.CreatePkgSetFn) (*pkger.Pkg, error) {
+					return &pkger.Pkg{
+						APIVersion: pkger.APIVersion,
+						Kind:       pkger.KindPackage,
+						Metadata:   pkger.Metadata{Name: "name1", Version: "v1"},
+						Warnings:   warnings,
+					}, nil
+				},
+			}
+			pkgHandler := fluxTTP.NewHandlerPkg(zap.NewNop(), fluxTTP.ErrorHandler(0), svc)
+			svr := newMountedHandler(pkgHandler, 1)
+
+			testttp.
+				PostJSON(t, "/api/v2/packages", fluxTTP.ReqCreatePkg{PkgName: "name1", PkgVersion: "v1"}).
+				Headers("Content-Type", "application/json").
+				Do(svr).
+				ExpectStatus(http.StatusOK).
+				ExpectBody(func(buf *bytes.Buffer) {
+					var resp fluxTTP.RespCreatePkg
+					decodeBody(t, buf, &resp)
+					assert.Equal(t, warnings, resp.Pkg.Warnings)
+				})
+		})
 	})
 
 	t.Run("dry run pkg", func(t *testing.T) {
@@ -89,7 +118,7 @@ func TestPkgerHTTPServer(t *testing.T) {
 			for _, tt := range tests {
 				fn := func(t *testing.T) {
 					svc := &fakeSVC{
-						DryRunFn: func(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg) (pkger.Summary, pkger.Diff, error) {
+						DryRunFn: func(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg, opts ...pkger.ApplyOptFn) (pkger.Summary, pkger.Diff, error) {
 							if err := pkg.Validate(); err != nil {
 								return pkger.Summary{}, pkger.Diff{}, err
 							}
@@ -147,7 +176,7 @@ func TestPkgerHTTPServer(t *testing.T) {
 			for _, tt := range tests {
 				fn := func(t *testing.T) {
 					svc := &fakeSVC{
-						DryRunFn: func(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg) (pkger.Summary, pkger.Diff, error) {
+						DryRunFn: func(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg, opts ...pkger.ApplyOptFn) (pkger.Summary, pkger.Diff, error) {
 							if err := pkg.Validate(); err != nil {
 								return pkger.Summary{}, pkger.Diff{}, err
 							}
@@ -188,7 +217,7 @@ func TestPkgerHTTPServer(t *testing.T) {
 
 	t.Run("apply a pkg", func(t *testing.T) {
 		svc := &fakeSVC{
-			DryRunFn: func(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg) (pkger.Summary, pkger.Diff, error) {
+			DryRunFn: func(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg, opts ...pkger.ApplyOptFn) (pkger.Summary, pkger.Diff, error) {
 				if err := pkg.Validate(); err != nil {
 					return pkger.Summary{}, pkger.Diff{}, err
 				}
@@ -201,7 +230,7 @@ func TestPkgerHTTPServer(t *testing.T) {
 				}
 				return sum, diff, nil
 			},
-			ApplyFn: func(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg) (pkger.Summary, error) {
+			ApplyFn: func(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg, opts ...pkger.ApplyOptFn) (pkger.Summary, error) {
 				return pkg.Summary(), nil
 			},
 		}
@@ -225,6 +254,82 @@ func TestPkgerHTTPServer(t *testing.T) {
 				assert.Nil(t, resp.Errors)
 			})
 	})
+
+	t.Run("apply a pkg to multiple orgs", func(t *testing.T) {
+		svc := &fakeSVC{
+			ApplyToOrgsFn: func(ctx context.Context, userID influxdb.ID, pkg *pkger.Pkg, orgIDs []influxdb.ID, opts ...pkger.ApplyOptFn) (map[influxdb.ID]pkger.OrgApplyResult, error) {
+				var opt pkger.ApplyOpt
+				for _, o := range opts {
+					if err := o(&opt); err != nil {
+						return nil, err
+					}
+				}
+				assert.Equal(t, map[string]string{"routing-key": "threeve"}, opt.MissingSecrets)
+				if err := pkg.Validate(); err != nil {
+					return nil, err
+				}
+
+				results := make(map[influxdb.ID]pkger.OrgApplyResult)
+				for _, orgID := range orgIDs {
+					if orgID == 2 {
+						results[orgID] = pkger.OrgApplyResult{Err: &influxdb.Error{Code: influxdb.EConflict, Msg: "bucket exists"}}
+						continue
+					}
+					results[orgID] = pkger.OrgApplyResult{Summary: pkg.Summary()}
+				}
+				return results, nil
+			},
+		}
+		pkgHandler := fluxTTP.NewHandlerPkg(zap.NewNop(), fluxTTP.ErrorHandler(0), svc)
+		reqBody := fluxTTP.ReqApplyPkg{
+			OrgIDs:  []string{influxdb.ID(1).String(), influxdb.ID(2).String()},
+			Pkg:     bucketPkg(t, pkger.EncodingJSON),
+			Secrets: map[string]string{"routing-key": "threeve"},
+		}
+
+		t.Run("with an operator token", func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Mount(pkgHandler.Prefix(), operatorAuthMW(1)(pkgHandler))
+
+			testttp.
+				PostJSON(t, "/api/v2/packages/apply", reqBody).
+				Do(r).
+				ExpectStatus(http.StatusMultiStatus).
+				ExpectBody(func(buf *bytes.Buffer) {
+					var resp fluxTTP.RespApplyPkgOrgs
+					decodeBody(t, buf, &resp)
+
+					require.Len(t, resp.Orgs, 2)
+					applied := resp.Orgs[influxdb.ID(1).String()]
+					assert.Len(t, applied.Summary.Buckets, 1)
+					assert.Empty(t, applied.Error)
+					failed := resp.Orgs[influxdb.ID(2).String()]
+					assert.Equal(t, influxdb.EConflict, failed.Code)
+					assert.Equal(t, "bucket exists", failed.Error)
+				})
+		})
+
+		t.Run("without an operator token", func(t *testing.T) {
+			testttp.
+				PostJSON(t, "/api/v2/packages/apply", reqBody).
+				Do(newMountedHandler(pkgHandler, 1)).
+				ExpectStatus(http.StatusForbidden)
+		})
+	})
+
+	t.Run("get pkg schema", func(t *testing.T) {
+		pkgHandler := fluxTTP.NewHandlerPkg(zap.NewNop(), fluxTTP.ErrorHandler(0), &fakeSVC{})
+		svr := newMountedHandler(pkgHandler, 1)
+
+		testttp.
+			Get(t, "/api/v2/packages/schema").
+			Do(svr).
+			ExpectStatus(http.StatusOK).
+			ExpectHeader("Content-Type", "application/schema+json").
+			ExpectBody(func(buf *bytes.Buffer) {
+				assert.JSONEq(t, string(pkger.Schema()), buf.String())
+			})
+	})
 }
 
 func bucketPkg(t *testing.T, encoding pkger.Encoding) *pkger.Pkg {
@@ -299,27 +404,178 @@ func decodeBody(t *testing.T, r io.Reader, v interface{}) {
 }
 
 type fakeSVC struct {
-	DryRunFn func(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg) (pkger.Summary, pkger.Diff, error)
-	ApplyFn  func(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg) (pkger.Summary, error)
+	CreatePkgFn func(ctx context.Context, setters ...pkger.CreatePkgSetFn) (*pkger.Pkg, error)
+	DryRunFn    func(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg, opts ...pkger.ApplyOptFn) (pkger.Summary, pkger.Diff, error)
+	ApplyFn     func(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg, opts ...pkger.ApplyOptFn) (pkger.Summary, error)
+
+	ApplyToOrgsFn func(ctx context.Context, userID influxdb.ID, pkg *pkger.Pkg, orgIDs []influxdb.ID, opts ...pkger.ApplyOptFn) (map[influxdb.ID]pkger.OrgApplyResult, error)
 }
 
 func (f *fakeSVC) CreatePkg(ctx context.Context, setters ...pkger.CreatePkgSetFn) (*pkger.Pkg, error) {
-	panic("not implemented")
+	if f.CreatePkgFn == nil {
+		panic("not implemented")
+	}
+	return f.CreatePkgFn(ctx, setters...)
 }
 
-func (f *fakeSVC) DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg) (pkger.Summary, pkger.Diff, error) {
+func (f *fakeSVC) DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg, opts ...pkger.ApplyOptFn) (pkger.Summary, pkger.Diff, error) {
 	if f.DryRunFn == nil {
 		panic("not implemented")
 	}
 
-	return f.DryRunFn(ctx, orgID, userID, pkg)
+	return f.DryRunFn(ctx, orgID, userID, pkg, opts...)
 }
 
-func (f *fakeSVC) Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg) (pkger.Summary, error) {
+func (f *fakeSVC) Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *pkger.Pkg, opts ...pkger.ApplyOptFn) (pkger.Summary, error) {
 	if f.ApplyFn == nil {
 		panic("not implemented")
 	}
-	return f.ApplyFn(ctx, orgID, userID, pkg)
+	return f.ApplyFn(ctx, orgID, userID, pkg, opts...)
+}
+
+func (f *fakeSVC) ApplyToOrgs(ctx context.Context, userID influxdb.ID, pkg *pkger.Pkg, orgIDs []influxdb.ID, opts ...pkger.ApplyOptFn) (map[influxdb.ID]pkger.OrgApplyResult, error) {
+	if f.ApplyToOrgsFn == nil {
+		panic("not implemented")
+	}
+	return f.ApplyToOrgsFn(ctx, userID, pkg, orgIDs, opts...)
+}
+
+func TestPkgerHTTPClientSVCs(t *testing.T) {
+	const token = "pkger-token"
+
+	var created []string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Token "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/buckets":
+			assert.Equal(t, "rucket_11", r.URL.Query().Get("name"))
+			w.Write([]byte(`{"buckets":[]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v2/buckets":
+			var b struct {
+				Name string `json:"name"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&b))
+			created = append(created, b.Name)
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"0000000000000003","orgID":"0000000000000001","type":"user","name":"rucket_11","retentionRules":[{"type":"expire","everySeconds":3600}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	opts, err := fluxTTP.PkgerHTTPClientSVCs(svr.URL, token, false)
+	require.NoError(t, err)
+	svc := pkger.NewService(opts...)
+
+	pkg, err := pkger.Parse(pkger.EncodingYAML, pkger.FromString(`
+apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName: pkg_name
+  pkgVersion: 1
+spec:
+  resources:
+    - kind: Bucket
+      name: rucket_11
+      retentionRules:
+        - type: expire
+          everySeconds: 3600
+`))
+	require.NoError(t, err)
+
+	_, diff, err := svc.DryRun(context.Background(), 1, 0, pkg)
+	require.NoError(t, err)
+	require.Len(t, diff.Buckets, 1)
+	assert.True(t, diff.Buckets[0].IsNew())
+
+	sum, err := svc.Apply(context.Background(), 1, 0, pkg)
+	require.NoError(t, err)
+	require.Len(t, sum.Buckets, 1)
+	assert.Equal(t, pkger.SafeID(3), sum.Buckets[0].ID)
+	assert.Equal(t, []string{"rucket_11"}, created)
+}
+
+func TestPkgerHTTPClientSVCs_Unsupported(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/orgs/0000000000000001/secrets":
+			w.Write([]byte(`{"secrets":["routing-key"]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/buckets":
+			w.Write([]byte(`{"buckets":[]}`))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	opts, err := fluxTTP.PkgerHTTPClientSVCs(svr.URL, "token", false)
+	require.NoError(t, err)
+	svc := pkger.NewService(opts...)
+
+	t.Run("secrets are looked up remotely", func(t *testing.T) {
+		pkg, err := pkger.Parse(pkger.EncodingYAML, pkger.FromString(`
+apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName: pkg_name
+  pkgVersion: 1
+spec:
+  resources:
+    - kind: Notification_Endpoint_Pager_Duty
+      name: pager_duty_notification_endpoint
+      url:  http://localhost:8080/orgs/7167eb6719fa34e5/alert-history
+      routingKey:
+        secretRef:
+          key: "routing-key"
+`))
+		require.NoError(t, err)
+
+		_, _, err = svc.DryRun(context.Background(), 1, 0, pkg)
+		require.NoError(t, err)
+	})
+
+	t.Run("checks error with method not allowed", func(t *testing.T) {
+		pkg, err := pkger.Parse(pkger.EncodingYAML, pkger.FromString(`
+apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName: pkg_name
+  pkgVersion: 1
+spec:
+  resources:
+    - kind: Bucket
+      name: rucket_1
+    - kind: Check_Threshold
+      name: check_1
+      bucket: rucket_1
+      field: usage_user
+      every: 1m
+      query: >
+        from(bucket: "rucket_1")
+          |> range(start: -1m)
+          |> filter(fn: (r) => r._field == "usage_user")
+      statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
+      thresholds:
+        - type: greater
+          level: CRIT
+          value: 50.0
+`))
+		require.NoError(t, err)
+
+		_, _, err = svc.DryRun(context.Background(), 1, 0, pkg)
+		require.Error(t, err)
+		assert.Equal(t, influxdb.EMethodNotAllowed, influxdb.ErrorCode(err))
+	})
 }
 
 func newMountedHandler(rh fluxTTP.ResourceHandler, userID influxdb.ID) chi.Router {
@@ -337,3 +593,17 @@ func authMW(userID influxdb.ID) func(http.Handler) http.Handler {
 		return http.HandlerFunc(fn)
 	}
 }
+
+func operatorAuthMW(userID influxdb.ID) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &influxdb.Session{
+				UserID:      userID,
+				ExpiresAt:   time.Now().Add(time.Hour),
+				Permissions: influxdb.OperPermissions(),
+			}))
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
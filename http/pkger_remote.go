@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/pkger"
+)
+
+// PkgerHTTPClientSVCs returns the options of a pkger.Service whose resource
+// services are HTTP clients of the remote influxd at the addr, authorized by
+// the token. It lets pkger.NewService dry run and apply pkgs against a remote
+// server instead of in-process services.
+//
+// Checks and notification rules have no HTTP client, a pkg with them fails its
+// dry run with a method not allowed error unless their services are provided
+// with the With*SVC options.
+func PkgerHTTPClientSVCs(addr, token string, insecureSkipVerify bool) ([]pkger.ServiceSetterFn, error) {
+	httpClient, err := NewHTTPClient(addr, token, insecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	scraperSVC := &pkgerScraperService{
+		ScraperService: &ScraperService{
+			Addr:               addr,
+			Token:              token,
+			InsecureSkipVerify: insecureSkipVerify,
+		},
+		UserResourceMappingService: &UserResourceMappingService{Client: httpClient},
+		OrganizationService:        &OrganizationService{Client: httpClient},
+	}
+
+	return []pkger.ServiceSetterFn{
+		pkger.WithBucketSVC(&BucketService{Client: httpClient}),
+		pkger.WithCheckSVC(&pkgerRemoteCheckService{
+			UserResourceMappingService: &UserResourceMappingService{Client: httpClient},
+			OrganizationService:        &OrganizationService{Client: httpClient},
+		}),
+		pkger.WithDashboardSVC(&DashboardService{Client: httpClient}),
+		pkger.WithLabelSVC(&LabelService{Client: httpClient}),
+		pkger.WithNoticationEndpointSVC(NewNotificationEndpointService(httpClient)),
+		pkger.WithNotificationRuleSVC(&pkgerRemoteRuleService{
+			UserResourceMappingService: &UserResourceMappingService{Client: httpClient},
+			OrganizationService:        &OrganizationService{Client: httpClient},
+		}),
+		pkger.WithScraperTargetSVC(scraperSVC),
+		pkger.WithSecretSVC(&SecretService{Client: httpClient}),
+		pkger.WithTelegrafSVC(NewTelegrafService(httpClient)),
+		pkger.WithVariableSVC(&VariableService{Client: httpClient}),
+	}, nil
+}
+
+// pkgerScraperService completes the scraper target client with the clients of
+// the services a ScraperTargetStoreService embeds.
+type pkgerScraperService struct {
+	*ScraperService
+	*UserResourceMappingService
+	*OrganizationService
+}
+
+var _ influxdb.ScraperTargetStoreService = (*pkgerScraperService)(nil)
+
+func errPkgerRemoteUnsupported(resource string) error {
+	return &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  resource + " are not supported by pkgs applied remotely",
+	}
+}
+
+// pkgerRemoteCheckService stands in for the missing HTTP client of checks, it
+// fails every call to the checks of a remote server.
+type pkgerRemoteCheckService struct {
+	*UserResourceMappingService
+	*OrganizationService
+}
+
+var _ influxdb.CheckService = (*pkgerRemoteCheckService)(nil)
+
+func (s *pkgerRemoteCheckService) FindCheckByID(ctx context.Context, id influxdb.ID) (influxdb.Check, error) {
+	return nil, errPkgerRemoteUnsupported("checks")
+}
+
+func (s *pkgerRemoteCheckService) FindCheck(ctx context.Context, filter influxdb.CheckFilter) (influxdb.Check, error) {
+	return nil, errPkgerRemoteUnsupported("checks")
+}
+
+func (s *pkgerRemoteCheckService) FindChecks(ctx context.Context, filter influxdb.CheckFilter, opt ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+	return nil, 0, errPkgerRemoteUnsupported("checks")
+}
+
+func (s *pkgerRemoteCheckService) CreateCheck(ctx context.Context, c influxdb.CheckCreate, userID influxdb.ID) error {
+	return errPkgerRemoteUnsupported("checks")
+}
+
+func (s *pkgerRemoteCheckService) UpdateCheck(ctx context.Context, id influxdb.ID, c influxdb.CheckCreate) (influxdb.Check, error) {
+	return nil, errPkgerRemoteUnsupported("checks")
+}
+
+func (s *pkgerRemoteCheckService) PatchCheck(ctx context.Context, id influxdb.ID, upd influxdb.CheckUpdate) (influxdb.Check, error) {
+	return nil, errPkgerRemoteUnsupported("checks")
+}
+
+func (s *pkgerRemoteCheckService) DeleteCheck(ctx context.Context, id influxdb.ID) error {
+	return errPkgerRemoteUnsupported("checks")
+}
+
+// pkgerRemoteRuleService stands in for the missing HTTP client of notification
+// rules, it fails every call to the notification rules of a remote server.
+type pkgerRemoteRuleService struct {
+	*UserResourceMappingService
+	*OrganizationService
+}
+
+var _ influxdb.NotificationRuleStore = (*pkgerRemoteRuleService)(nil)
+
+func (s *pkgerRemoteRuleService) FindNotificationRuleByID(ctx context.Context, id influxdb.ID) (influxdb.NotificationRule, error) {
+	return nil, errPkgerRemoteUnsupported("notification rules")
+}
+
+func (s *pkgerRemoteRuleService) FindNotificationRules(ctx context.Context, filter influxdb.NotificationRuleFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+	return nil, 0, errPkgerRemoteUnsupported("notification rules")
+}
+
+func (s *pkgerRemoteRuleService) CreateNotificationRule(ctx context.Context, nr influxdb.NotificationRuleCreate, userID influxdb.ID) error {
+	return errPkgerRemoteUnsupported("notification rules")
+}
+
+func (s *pkgerRemoteRuleService) UpdateNotificationRule(ctx context.Context, id influxdb.ID, nr influxdb.NotificationRuleCreate, userID influxdb.ID) (influxdb.NotificationRule, error) {
+	return nil, errPkgerRemoteUnsupported("notification rules")
+}
+
+func (s *pkgerRemoteRuleService) PatchNotificationRule(ctx context.Context, id influxdb.ID, upd influxdb.NotificationRuleUpdate) (influxdb.NotificationRule, error) {
+	return nil, errPkgerRemoteUnsupported("notification rules")
+}
+
+func (s *pkgerRemoteRuleService) DeleteNotificationRule(ctx context.Context, id influxdb.ID) error {
+	return errPkgerRemoteUnsupported("notification rules")
+}
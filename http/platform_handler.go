@@ -12,6 +12,8 @@ type PlatformHandler struct {
 	AssetHandler *AssetHandler
 	DocsHandler  http.HandlerFunc
 	APIHandler   http.Handler
+
+	timeouts *TimeoutHandler
 }
 
 func setCORSResponseHeaders(next http.Handler) http.Handler {
@@ -29,12 +31,15 @@ func setCORSResponseHeaders(next http.Handler) http.Handler {
 
 // NewPlatformHandler returns a platform handler that serves the API and associated assets.
 func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler {
+	timeouts := NewTimeoutHandler(b.HTTPErrorHandler, NewAPIHandler(b, opts...), b.RequestTimeout, apiRouteTimeouts...)
+
 	h := NewAuthenticationHandler(b.Logger, b.HTTPErrorHandler)
-	h.Handler = NewAPIHandler(b, opts...)
+	h.Handler = timeouts
 	h.AuthorizationService = b.AuthorizationService
 	h.SessionService = b.SessionService
 	h.SessionRenewDisabled = b.SessionRenewDisabled
 	h.UserService = b.UserService
+	h.UserActivity = b.UserActivityRecorder
 
 	h.RegisterNoAuthRoute("GET", "/api/v2")
 	h.RegisterNoAuthRoute("POST", "/api/v2/signin")
@@ -53,6 +58,7 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 		AssetHandler: assetHandler,
 		DocsHandler:  Redoc("/api/v2/swagger.json"),
 		APIHandler:   wrappedHandler,
+		timeouts:     timeouts,
 	}
 }
 
@@ -77,6 +83,5 @@ func (h *PlatformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // PrometheusCollectors satisfies the prom.PrometheusCollector interface.
 func (h *PlatformHandler) PrometheusCollectors() []prometheus.Collector {
-	// TODO: collect and return relevant metrics.
-	return nil
+	return h.timeouts.PrometheusCollectors()
 }
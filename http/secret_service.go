@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/httpc"
+)
+
+// SecretService connects to the secrets of the orgs of an influxd via HTTP.
+// The values of secrets are never returned over HTTP, only their keys can be
+// read back.
+type SecretService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.SecretService = (*SecretService)(nil)
+
+// LoadSecret is not supported over HTTP, the values of secrets cannot be read.
+func (s *SecretService) LoadSecret(ctx context.Context, orgID influxdb.ID, k string) (string, error) {
+	return "", &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  "the value of a secret cannot be loaded over HTTP",
+	}
+}
+
+// GetSecretKeys returns the keys of the secrets of the org.
+func (s *SecretService) GetSecretKeys(ctx context.Context, orgID influxdb.ID) ([]string, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var resp secretsResponse
+	err := s.Client.
+		Get(organizationIDPath(orgID), "secrets").
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Secrets, nil
+}
+
+// PutSecret stores the secret of the org, overwriting an existing value.
+func (s *SecretService) PutSecret(ctx context.Context, orgID influxdb.ID, k string, v string) error {
+	return s.PatchSecrets(ctx, orgID, map[string]string{k: v})
+}
+
+// PutSecrets puts all the secrets of the org, the secrets of the org that are
+// not provided are deleted.
+func (s *SecretService) PutSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	keys, err := s.GetSecretKeys(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.PatchSecrets(ctx, orgID, m); err != nil {
+		return err
+	}
+
+	var remove []string
+	for _, k := range keys {
+		if _, ok := m[k]; !ok {
+			remove = append(remove, k)
+		}
+	}
+	if len(remove) == 0 {
+		return nil
+	}
+	return s.DeleteSecret(ctx, orgID, remove...)
+}
+
+// PatchSecrets stores the provided secrets of the org, the other secrets of
+// the org are left as is.
+func (s *SecretService) PatchSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.Client.
+		PatchJSON(m, organizationIDPath(orgID), "secrets").
+		Do(ctx)
+}
+
+// DeleteSecret removes the secrets of the org.
+func (s *SecretService) DeleteSecret(ctx context.Context, orgID influxdb.ID, ks ...string) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	req := struct {
+		Secrets []string `json:"secrets"`
+	}{Secrets: ks}
+	return s.Client.
+		PostJSON(req, organizationIDPath(orgID), "secrets", "delete").
+		Do(ctx)
+}
@@ -170,6 +170,7 @@ type Task struct {
 	CreatedAt       string                 `json:"createdAt,omitempty"`
 	UpdatedAt       string                 `json:"updatedAt,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	Warnings        []string               `json:"warnings,omitempty"`
 }
 
 type taskResponse struct {
@@ -215,6 +216,7 @@ func NewFrontEndTask(t influxdb.Task) Task {
 		CreatedAt:       createdAt,
 		UpdatedAt:       updatedAt,
 		Metadata:        t.Metadata,
+		Warnings:        t.Warnings,
 	}
 }
 
@@ -27,6 +27,12 @@ type TelegrafBackend struct {
 	LabelService               platform.LabelService
 	UserService                platform.UserService
 	OrganizationService        platform.OrganizationService
+	BucketService              platform.BucketService
+	AuthorizationService       platform.AuthorizationService
+
+	// AdvertisedURL is the url agents reach the server at, substituted for
+	// the host of rendered configs. The host of the request is used if empty.
+	AdvertisedURL string
 }
 
 // NewTelegrafBackend returns a new instance of TelegrafBackend.
@@ -40,6 +46,9 @@ func NewTelegrafBackend(log *zap.Logger, b *APIBackend) *TelegrafBackend {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		OrganizationService:        b.OrganizationService,
+		BucketService:              b.BucketService,
+		AuthorizationService:       b.AuthorizationService,
+		AdvertisedURL:              b.AdvertisedURL,
 	}
 }
 
@@ -54,11 +63,18 @@ type TelegrafHandler struct {
 	LabelService               platform.LabelService
 	UserService                platform.UserService
 	OrganizationService        platform.OrganizationService
+	BucketService              platform.BucketService
+	AuthorizationService       platform.AuthorizationService
+
+	// AdvertisedURL is the url agents reach the server at, substituted for
+	// the host of rendered configs. The host of the request is used if empty.
+	AdvertisedURL string
 }
 
 const (
 	prefixTelegraf           = "/api/v2/telegrafs"
 	telegrafsIDPath          = "/api/v2/telegrafs/:id"
+	telegrafsIDRenderPath    = "/api/v2/telegrafs/:id/render"
 	telegrafsIDMembersPath   = "/api/v2/telegrafs/:id/members"
 	telegrafsIDMembersIDPath = "/api/v2/telegrafs/:id/members/:userID"
 	telegrafsIDOwnersPath    = "/api/v2/telegrafs/:id/owners"
@@ -82,12 +98,17 @@ func NewTelegrafHandler(log *zap.Logger, b *TelegrafBackend) *TelegrafHandler {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		OrganizationService:        b.OrganizationService,
+		BucketService:              b.BucketService,
+		AuthorizationService:       b.AuthorizationService,
+		AdvertisedURL:              b.AdvertisedURL,
 	}
 	h.HandlerFunc("POST", prefixTelegraf, h.handlePostTelegraf)
 	h.HandlerFunc("GET", prefixTelegraf, h.handleGetTelegrafs)
 	h.HandlerFunc("GET", telegrafsIDPath, h.handleGetTelegraf)
 	h.HandlerFunc("DELETE", telegrafsIDPath, h.handleDeleteTelegraf)
 	h.HandlerFunc("PUT", telegrafsIDPath, h.handlePutTelegraf)
+	h.HandlerFunc("GET", telegrafsIDRenderPath, h.handleGetTelegrafRender)
+	h.HandlerFunc("POST", telegrafsIDRenderPath, h.handlePostTelegrafRender)
 
 	h.HandlerFunc("GET", telegrafPluginsPath, h.handleGetTelegrafPlugins)
 
@@ -154,6 +175,7 @@ func (r *telegrafResponse) MarshalJSON() ([]byte, error) {
 		OrgID       platform.ID                  `json:"orgID,omitempty"`
 		Name        string                       `json:"name"`
 		Description string                       `json:"description"`
+		TokenID     *platform.ID                 `json:"tokenID,omitempty"`
 		Agent       platform.TelegrafAgentConfig `json:"agent"`
 		Plugins     []telegrafPluginEncode       `json:"plugins"`
 		Labels      []platform.Label             `json:"labels"`
@@ -171,6 +193,9 @@ func (r *telegrafResponse) MarshalJSON() ([]byte, error) {
 		Labels:      r.Labels,
 		Links:       r.Links,
 	}
+	if r.TokenID.Valid() {
+		tce.TokenID = &r.TokenID
+	}
 
 	for k, p := range r.Plugins {
 		tce.Plugins[k] = telegrafPluginEncode{
@@ -325,6 +350,140 @@ func (h *TelegrafHandler) handleGetTelegraf(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+type telegrafRenderRequest struct {
+	ID     platform.ID
+	Bucket string
+}
+
+func decodeTelegrafRenderRequest(ctx context.Context, r *http.Request) (*telegrafRenderRequest, error) {
+	id, err := decodeGetTelegrafRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &telegrafRenderRequest{
+		ID:     id,
+		Bucket: r.URL.Query().Get("bucket"),
+	}, nil
+}
+
+// handleGetTelegrafRender renders the toml of a telegraf config for an agent,
+// with its host, org and bucket placeholders substituted. The token is left
+// for the agent to read from its env.
+func (h *TelegrafHandler) handleGetTelegrafRender(w http.ResponseWriter, r *http.Request) {
+	h.renderTelegraf(w, r, false)
+}
+
+// handlePostTelegrafRender renders the toml of a telegraf config for an agent
+// like handleGetTelegrafRender does, with a token minted for the agent
+// substituted for the token placeholder.
+func (h *TelegrafHandler) handlePostTelegrafRender(w http.ResponseWriter, r *http.Request) {
+	h.renderTelegraf(w, r, true)
+}
+
+func (h *TelegrafHandler) renderTelegraf(w http.ResponseWriter, r *http.Request, mintToken bool) {
+	ctx := r.Context()
+	req, err := decodeTelegrafRenderRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	tc, err := h.TelegrafService.FindTelegrafConfigByID(ctx, req.ID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	org, err := h.OrganizationService.FindOrganizationByID(ctx, tc.OrgID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	values := map[string]string{
+		platform.TelegrafHostPlaceholder:   h.advertisedURL(r),
+		platform.TelegrafOrgPlaceholder:    org.Name,
+		platform.TelegrafBucketPlaceholder: req.Bucket,
+	}
+	if mintToken {
+		auth, err := h.mintTelegrafToken(ctx, tc, req.Bucket)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		values[platform.TelegrafTokenPlaceholder] = auth.Token
+	}
+	h.log.Debug("Telegraf rendered", zap.String("telegrafID", tc.ID.String()), zap.Bool("mintToken", mintToken))
+
+	w.Header().Set("Content-Type", "application/toml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(tc.Render(values)))
+}
+
+// advertisedURL returns the url agents reach the server at.
+func (h *TelegrafHandler) advertisedURL(r *http.Request) string {
+	if h.AdvertisedURL != "" {
+		return h.AdvertisedURL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// mintTelegrafToken creates a token for the user of the request that can only
+// write to the buckets of the config, and records its ID on the config for it
+// to be rotated later. The tokens minted for the config before are left as is.
+func (h *TelegrafHandler) mintTelegrafToken(ctx context.Context, tc *platform.TelegrafConfig, bucket string) (*platform.Authorization, error) {
+	a, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ps []platform.Permission
+	for _, name := range tc.OutputBuckets(bucket) {
+		b, err := h.BucketService.FindBucket(ctx, platform.BucketFilter{
+			OrganizationID: &tc.OrgID,
+			Name:           &name,
+		})
+		if err != nil {
+			return nil, err
+		}
+		p, err := platform.NewPermissionAtID(b.ID, platform.WriteAction, platform.BucketsResourceType, tc.OrgID)
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, *p)
+	}
+	if len(ps) == 0 {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "telegraf config has no influxdb_v2 output to mint a token for",
+		}
+	}
+
+	auth := &platform.Authorization{
+		OrgID:       tc.OrgID,
+		UserID:      a.GetUserID(),
+		Description: fmt.Sprintf("telegraf %s", tc.Name),
+		Permissions: ps,
+	}
+	if err := h.AuthorizationService.CreateAuthorization(ctx, auth); err != nil {
+		return nil, err
+	}
+
+	tc.TokenID = auth.ID
+	if _, err := h.TelegrafService.UpdateTelegrafConfig(ctx, tc.ID, tc, a.GetUserID()); err != nil {
+		if derr := h.AuthorizationService.DeleteAuthorization(ctx, auth.ID); derr != nil {
+			h.log.Info("Failed to remove token of telegraf", zap.Error(derr))
+		}
+		return nil, err
+	}
+	return auth, nil
+}
+
 func decodeTelegrafConfigFilter(ctx context.Context, r *http.Request) (*platform.TelegrafConfigFilter, error) {
 	f := &platform.TelegrafConfigFilter{}
 	urm, err := decodeUserResourceMappingFilter(ctx, r, platform.TelegrafsResourceType)
@@ -7,10 +7,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 
 	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/mock"
 	"github.com/influxdata/influxdb/telegraf/plugins/inputs"
 	"github.com/influxdata/influxdb/telegraf/plugins/outputs"
@@ -934,3 +937,202 @@ func Test_newTelegrafResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestTelegrafHandler_handleTelegrafRender(t *testing.T) {
+	newConfig := func() *platform.TelegrafConfig {
+		return &platform.TelegrafConfig{
+			ID:    platform.ID(1),
+			OrgID: platform.ID(2),
+			Name:  "my config",
+			Agent: platform.TelegrafAgentConfig{
+				Interval: 10000,
+			},
+			Plugins: []platform.TelegrafPlugin{
+				{
+					Config: &inputs.CPUStats{},
+				},
+				{
+					Config: &outputs.InfluxDBV2{
+						URLs:         []string{platform.TelegrafHostPlaceholder},
+						Token:        platform.TelegrafTokenPlaceholder,
+						Organization: platform.TelegrafOrgPlaceholder,
+						Bucket:       platform.TelegrafBucketPlaceholder,
+					},
+				},
+			},
+		}
+	}
+
+	type wants struct {
+		statusCode int
+		contains   []string
+		excludes   []string
+		tokenID    platform.ID
+	}
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		prevTokenID platform.ID
+		wants       wants
+	}{
+		{
+			name:   "token is left as an env reference",
+			method: "GET",
+			url:    "http://any.url/api/v2/telegrafs/0000000000000001/render?bucket=my_bucket",
+			wants: wants{
+				statusCode: http.StatusOK,
+				contains: []string{
+					`urls = ["https://influxdb.example.com:9999"]`,
+					`token = "$INFLUX_TOKEN"`,
+					`organization = "my_org"`,
+					`bucket = "my_bucket"`,
+				},
+				excludes: []string{"$INFLUX_HOST", "$INFLUX_ORG", "$INFLUX_BUCKET"},
+			},
+		},
+		{
+			name:   "get does not mint a token",
+			method: "GET",
+			url:    "http://any.url/api/v2/telegrafs/0000000000000001/render?bucket=my_bucket&mintToken=true",
+			wants: wants{
+				statusCode: http.StatusOK,
+				contains: []string{
+					`token = "$INFLUX_TOKEN"`,
+				},
+			},
+		},
+		{
+			name:   "minted token can only write to the buckets of the config",
+			method: "POST",
+			url:    "http://any.url/api/v2/telegrafs/0000000000000001/render?bucket=my_bucket",
+			wants: wants{
+				statusCode: http.StatusOK,
+				contains: []string{
+					`token = "minted_token"`,
+					`bucket = "my_bucket"`,
+				},
+				excludes: []string{"$INFLUX_TOKEN"},
+				tokenID:  platform.ID(5),
+			},
+		},
+		{
+			name:        "minting again records the new token without revoking the previous one",
+			method:      "POST",
+			url:         "http://any.url/api/v2/telegrafs/0000000000000001/render?bucket=my_bucket",
+			prevTokenID: platform.ID(6),
+			wants: wants{
+				statusCode: http.StatusOK,
+				contains: []string{
+					`token = "minted_token"`,
+				},
+				tokenID: platform.ID(5),
+			},
+		},
+		{
+			name:   "values are escaped as toml strings",
+			method: "GET",
+			url:    "http://any.url/api/v2/telegrafs/0000000000000001/render?bucket=" + url.QueryEscape("b\"\n[[outputs.file]]"),
+			wants: wants{
+				statusCode: http.StatusOK,
+				contains: []string{
+					`bucket = "b\"\n[[outputs.file]]"`,
+				},
+				excludes: []string{"\n[[outputs.file]]"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated *platform.TelegrafConfig
+			svc := mock.NewTelegrafConfigStore()
+			svc.FindTelegrafConfigByIDF = func(ctx context.Context, id platform.ID) (*platform.TelegrafConfig, error) {
+				tc := newConfig()
+				tc.TokenID = tt.prevTokenID
+				return tc, nil
+			}
+			svc.UpdateTelegrafConfigF = func(ctx context.Context, id platform.ID, tc *platform.TelegrafConfig, userID platform.ID) (*platform.TelegrafConfig, error) {
+				updated = tc
+				return tc, nil
+			}
+
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationByIDF = func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+				return &platform.Organization{ID: id, Name: "my_org"}, nil
+			}
+
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+				if *filter.Name != "my_bucket" || *filter.OrganizationID != platform.ID(2) {
+					return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+				}
+				return &platform.Bucket{ID: platform.ID(3), OrgID: platform.ID(2), Name: *filter.Name}, nil
+			}
+
+			var minted *platform.Authorization
+			auths := mock.NewAuthorizationService()
+			auths.CreateAuthorizationFn = func(ctx context.Context, a *platform.Authorization) error {
+				a.ID = platform.ID(5)
+				a.Token = "minted_token"
+				minted = a
+				return nil
+			}
+			var revoked []platform.ID
+			auths.DeleteAuthorizationFn = func(ctx context.Context, id platform.ID) error {
+				revoked = append(revoked, id)
+				return nil
+			}
+
+			telegrafBackend := NewMockTelegrafBackend(t)
+			telegrafBackend.HTTPErrorHandler = ErrorHandler(0)
+			telegrafBackend.TelegrafService = svc
+			telegrafBackend.OrganizationService = orgs
+			telegrafBackend.BucketService = buckets
+			telegrafBackend.AuthorizationService = auths
+			telegrafBackend.AdvertisedURL = "https://influxdb.example.com:9999"
+			h := NewTelegrafHandler(zaptest.NewLogger(t), telegrafBackend)
+
+			r := httptest.NewRequest(tt.method, tt.url, nil)
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Session{UserID: platform.ID(4)}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.wants.statusCode {
+				t.Fatalf("%s render = %v, want %v: %s", tt.method, res.StatusCode, tt.wants.statusCode, body)
+			}
+			for _, s := range tt.wants.contains {
+				if !strings.Contains(string(body), s) {
+					t.Errorf("exp rendered config to contain %q, got:\n%s", s, body)
+				}
+			}
+			for _, s := range tt.wants.excludes {
+				if strings.Contains(string(body), s) {
+					t.Errorf("exp rendered config not to contain %q, got:\n%s", s, body)
+				}
+			}
+
+			if !tt.wants.tokenID.Valid() {
+				if minted != nil || updated != nil {
+					t.Errorf("exp no token to be minted, got %v", minted)
+				}
+				return
+			}
+
+			if minted.UserID != platform.ID(4) || minted.OrgID != platform.ID(2) {
+				t.Errorf("unexpected owner of minted token: user %s, org %s", minted.UserID, minted.OrgID)
+			}
+			want, _ := platform.NewPermissionAtID(platform.ID(3), platform.WriteAction, platform.BucketsResourceType, platform.ID(2))
+			if !reflect.DeepEqual(minted.Permissions, []platform.Permission{*want}) {
+				t.Errorf("exp minted token to only write to the bucket, got %v", minted.Permissions)
+			}
+			if updated == nil || updated.TokenID != tt.wants.tokenID {
+				t.Errorf("exp token %s to be recorded on the config, got %v", tt.wants.tokenID, updated)
+			}
+			if len(revoked) != 0 {
+				t.Errorf("exp no tokens to be revoked, got %v", revoked)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RouteTimeout overrides the request timeout for the routes beneath a path prefix.
+// An exempt route is never timed out, i.e. a query that legitimately runs long or
+// a write that streams a large body.
+type RouteTimeout struct {
+	Prefix  string
+	Timeout time.Duration
+	Exempt  bool
+}
+
+// TimeoutHandler cancels the context of requests that exceed their deadline and
+// responds with a 503 and a structured timeout error. The response of a timed
+// request is buffered until the handler returns, so routes that stream their
+// responses should be exempt.
+type TimeoutHandler struct {
+	platform.HTTPErrorHandler
+
+	next           http.Handler
+	defaultTimeout time.Duration
+	routes         []RouteTimeout
+
+	timeouts *prometheus.CounterVec
+}
+
+// NewTimeoutHandler constructs a TimeoutHandler. Requests are timed out after
+// the default timeout, unless their path matches the prefix of a route, in which
+// case the route's timeout is used. The longest matching prefix wins. A zero
+// default timeout leaves requests without a route override untimed.
+func NewTimeoutHandler(h platform.HTTPErrorHandler, next http.Handler, defaultTimeout time.Duration, routes ...RouteTimeout) *TimeoutHandler {
+	return &TimeoutHandler{
+		HTTPErrorHandler: h,
+		next:             next,
+		defaultTimeout:   defaultTimeout,
+		routes:           routes,
+		timeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "http",
+			Subsystem: "api",
+			Name:      "request_timeouts_total",
+			Help:      "Number of http requests that exceeded their timeout",
+		}, []string{"route", "method"}),
+	}
+}
+
+// ServeHTTP serves the request with the timeout of its route.
+func (h *TimeoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, timeout := h.timeoutOf(r.URL.Path)
+	if timeout <= 0 {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	r = r.WithContext(ctx)
+
+	tw := &timeoutWriter{h: make(http.Header)}
+	done := make(chan struct{})
+	panicChan := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicChan <- p
+			}
+		}()
+		h.next.ServeHTTP(tw, r)
+		close(done)
+	}()
+
+	select {
+	case p := <-panicChan:
+		panic(p)
+	case <-done:
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		dst := w.Header()
+		for k, vv := range tw.h {
+			dst[k] = vv
+		}
+		if !tw.wroteHeader {
+			tw.code = http.StatusOK
+		}
+		w.WriteHeader(tw.code)
+		w.Write(tw.buf.Bytes())
+	case <-ctx.Done():
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		tw.timedOut = true
+		h.timeouts.WithLabelValues(route, r.Method).Inc()
+		h.HandleHTTPError(r.Context(), &platform.Error{
+			Code: platform.EUnavailable,
+			Msg:  fmt.Sprintf("request timed out after %s", timeout),
+		}, w)
+	}
+}
+
+// timeoutOf returns the route and timeout of the path. The route of a path
+// without an override is "default".
+func (h *TimeoutHandler) timeoutOf(path string) (string, time.Duration) {
+	var match *RouteTimeout
+	for i, rt := range h.routes {
+		if path != rt.Prefix && !strings.HasPrefix(path, strings.TrimSuffix(rt.Prefix, "/")+"/") {
+			continue
+		}
+		if match == nil || len(rt.Prefix) > len(match.Prefix) {
+			match = &h.routes[i]
+		}
+	}
+
+	switch {
+	case match == nil:
+		return "default", h.defaultTimeout
+	case match.Exempt:
+		return match.Prefix, 0
+	default:
+		return match.Prefix, match.Timeout
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (h *TimeoutHandler) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{h.timeouts}
+}
+
+// timeoutWriter buffers the response of a handler, the response is discarded
+// when the handler does not finish before the timeout.
+type timeoutWriter struct {
+	h   http.Header
+	buf bytes.Buffer
+
+	mu          sync.Mutex
+	timedOut    bool
+	wroteHeader bool
+	code        int
+}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.h }
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeader(http.StatusOK)
+	}
+	return tw.buf.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.writeHeader(code)
+}
+
+func (tw *timeoutWriter) writeHeader(code int) {
+	tw.wroteHeader = true
+	tw.code = code
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/prom"
+	"github.com/influxdata/influxdb/kit/prom/promtest"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTimeoutHandler(t *testing.T) {
+	// slow responds after the delay, unless the request context is done first
+	slow := func(delay time.Duration) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(delay):
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte("done"))
+			case <-r.Context().Done():
+			}
+		})
+	}
+
+	routes := []RouteTimeout{
+		{Prefix: "/api/v2/query", Exempt: true},
+		{Prefix: "/api/v2/packages", Timeout: time.Second},
+	}
+
+	t.Run("times out requests exceeding the default timeout", func(t *testing.T) {
+		h := NewTimeoutHandler(ErrorHandler(0), slow(time.Second), 10*time.Millisecond, routes...)
+		reg := prom.NewRegistry(zaptest.NewLogger(t))
+		reg.MustRegister(h.PrometheusCollectors()...)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/dashboards", nil))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("unexpected status code: %d", w.Code)
+		}
+		var pe platform.Error
+		if err := json.NewDecoder(w.Body).Decode(&pe); err != nil {
+			t.Fatal(err)
+		}
+		if pe.Code != platform.EUnavailable {
+			t.Errorf("unexpected error code: %q", pe.Code)
+		}
+		mfs := promtest.MustGather(t, reg)
+		m := promtest.MustFindMetric(t, mfs, "http_api_request_timeouts_total", map[string]string{"route": "default", "method": http.MethodGet})
+		if got := m.GetCounter().GetValue(); got != 1 {
+			t.Errorf("exp 1 timeout, got %v", got)
+		}
+	})
+
+	t.Run("exempt route outlives the default timeout", func(t *testing.T) {
+		h := NewTimeoutHandler(ErrorHandler(0), slow(50*time.Millisecond), 10*time.Millisecond, routes...)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v2/query/analyze", nil))
+
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("unexpected status code: %d", w.Code)
+		}
+		if body := w.Body.String(); body != "done" {
+			t.Errorf("unexpected body: %q", body)
+		}
+	})
+
+	t.Run("route timeout overrides the default timeout", func(t *testing.T) {
+		h := NewTimeoutHandler(ErrorHandler(0), slow(50*time.Millisecond), 10*time.Millisecond, routes...)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v2/packages", nil))
+
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("unexpected status code: %d", w.Code)
+		}
+	})
+
+	t.Run("prefix matches whole path segments", func(t *testing.T) {
+		h := NewTimeoutHandler(ErrorHandler(0), nil, time.Minute, routes...)
+
+		if route, _ := h.timeoutOf("/api/v2/queryable"); route != "default" {
+			t.Errorf("unexpected route: %q", route)
+		}
+		if route, timeout := h.timeoutOf("/api/v2/query"); route != "/api/v2/query" || timeout != 0 {
+			t.Errorf("unexpected route %q with timeout %s", route, timeout)
+		}
+	})
+}
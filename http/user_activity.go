@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+// DefaultUserActivityInterval is the least time between two records of the
+// last time a user was seen.
+const DefaultUserActivityInterval = time.Hour
+
+// UserActivityRecorder records the users seen by the authentication
+// middleware. A user is recorded at most once per interval, and the times
+// recorded are buffered in memory until they are flushed, so recording the
+// activity of users adds no write to the requests.
+type UserActivityRecorder struct {
+	svc      influxdb.UserActivityService
+	interval time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	recorded map[influxdb.ID]time.Time
+	pending  map[influxdb.ID]time.Time
+}
+
+// NewUserActivityRecorder constructs a recorder of the users seen, writing
+// them to the service.
+func NewUserActivityRecorder(svc influxdb.UserActivityService, interval time.Duration) *UserActivityRecorder {
+	return &UserActivityRecorder{
+		svc:      svc,
+		interval: interval,
+		now:      time.Now,
+		recorded: make(map[influxdb.ID]time.Time),
+		pending:  make(map[influxdb.ID]time.Time),
+	}
+}
+
+// Seen records the user was seen now, unless it was recorded less than the
+// interval ago.
+func (r *UserActivityRecorder) Seen(id influxdb.ID) {
+	now := r.now().UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if last, ok := r.recorded[id]; ok && now.Sub(last) < r.interval {
+		return
+	}
+	r.recorded[id] = now
+	r.pending[id] = now
+}
+
+// Flush writes the times buffered. The times that fail to be written are kept
+// for the next flush.
+func (r *UserActivityRecorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[influxdb.ID]time.Time)
+	r.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := r.svc.SetUsersLastSeen(ctx, pending); err != nil {
+		r.mu.Lock()
+		for id, t := range pending {
+			if newer, ok := r.pending[id]; !ok || t.After(newer) {
+				r.pending[id] = t
+			}
+		}
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes the times buffered every period until the ctx is done, and then
+// one last time.
+func (r *UserActivityRecorder) Run(ctx context.Context, log *zap.Logger, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.Flush(ctx); err != nil {
+				log.Warn("Failed to record user activity", zap.Error(err))
+			}
+		case <-ctx.Done():
+			if err := r.Flush(context.Background()); err != nil {
+				log.Warn("Failed to record user activity", zap.Error(err))
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+type fakeUserActivityService struct {
+	seen   map[influxdb.ID]time.Time
+	writes int
+	err    error
+}
+
+func (s *fakeUserActivityService) FindUsersLastSeen(ctx context.Context, ids []influxdb.ID) (map[influxdb.ID]time.Time, error) {
+	seen := make(map[influxdb.ID]time.Time)
+	for _, id := range ids {
+		if t, ok := s.seen[id]; ok {
+			seen[id] = t
+		}
+	}
+	return seen, nil
+}
+
+func (s *fakeUserActivityService) SetUsersLastSeen(ctx context.Context, seen map[influxdb.ID]time.Time) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.writes++
+	if s.seen == nil {
+		s.seen = make(map[influxdb.ID]time.Time)
+	}
+	for id, t := range seen {
+		s.seen[id] = t
+	}
+	return nil
+}
+
+func TestUserActivityRecorder_Seen(t *testing.T) {
+	svc := &fakeUserActivityService{}
+	r := NewUserActivityRecorder(svc, time.Hour)
+
+	start := time.Date(2019, 12, 1, 10, 0, 0, 0, time.UTC)
+	now := start
+	r.now = func() time.Time { return now }
+
+	r.Seen(1)
+	now = start.Add(30 * time.Minute)
+	r.Seen(1)
+	r.Seen(2)
+
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := map[influxdb.ID]time.Time{
+		1: start,
+		2: start.Add(30 * time.Minute),
+	}
+	if !reflect.DeepEqual(svc.seen, want) {
+		t.Fatalf("got last seen %v, want %v", svc.seen, want)
+	}
+
+	// user 1 is within the interval of its last record, nothing to write
+	now = start.Add(59 * time.Minute)
+	r.Seen(1)
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if svc.writes != 1 {
+		t.Fatalf("got %d writes, want 1", svc.writes)
+	}
+
+	now = start.Add(time.Hour)
+	r.Seen(1)
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := svc.seen[1]; !got.Equal(now) {
+		t.Fatalf("got last seen %v, want %v", got, now)
+	}
+	if svc.writes != 2 {
+		t.Fatalf("got %d writes, want 2", svc.writes)
+	}
+}
+
+func TestUserActivityRecorder_FlushError(t *testing.T) {
+	svc := &fakeUserActivityService{err: errors.New("write failed")}
+	r := NewUserActivityRecorder(svc, time.Hour)
+
+	now := time.Date(2019, 12, 1, 10, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	r.Seen(1)
+	if err := r.Flush(context.Background()); err == nil {
+		t.Fatal("expected the error of the service")
+	}
+
+	svc.err = nil
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := svc.seen[1]; !got.Equal(now) {
+		t.Fatalf("got last seen %v, want %v kept from the failed flush", got, now)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"time"
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb"
@@ -14,7 +15,8 @@ import (
 )
 
 type resourceUserResponse struct {
-	Role influxdb.UserType `json:"role"`
+	Role       influxdb.UserType `json:"role"`
+	LastSeenAt *time.Time        `json:"lastSeenAt,omitempty"`
 	*UserResponse
 }
 
@@ -30,7 +32,7 @@ type resourceUsersResponse struct {
 	Users []*resourceUserResponse `json:"users"`
 }
 
-func newResourceUsersResponse(opts influxdb.FindOptions, f influxdb.UserResourceMappingFilter, users []*influxdb.User) *resourceUsersResponse {
+func newResourceUsersResponse(opts influxdb.FindOptions, f influxdb.UserResourceMappingFilter, users []*influxdb.User, lastSeen map[influxdb.ID]time.Time) *resourceUsersResponse {
 	rs := resourceUsersResponse{
 		Links: map[string]string{
 			"self": fmt.Sprintf("/api/v2/%s/%s/%ss", f.ResourceType, f.ResourceID, f.UserType),
@@ -39,7 +41,11 @@ func newResourceUsersResponse(opts influxdb.FindOptions, f influxdb.UserResource
 	}
 
 	for _, user := range users {
-		rs.Users = append(rs.Users, newResourceUserResponse(user, f.UserType))
+		res := newResourceUserResponse(user, f.UserType)
+		if t, ok := lastSeen[user.ID]; ok {
+			res.LastSeenAt = &t
+		}
+		rs.Users = append(rs.Users, res)
 	}
 	return &rs
 }
@@ -55,6 +61,10 @@ type MemberBackend struct {
 
 	UserResourceMappingService influxdb.UserResourceMappingService
 	UserService                influxdb.UserService
+
+	// UserActivityService adds the last time the users were seen to the
+	// listings, it is optional.
+	UserActivityService influxdb.UserActivityService
 }
 
 // newPostMemberHandler returns a handler func for a POST to /members or /owners endpoints
@@ -147,8 +157,7 @@ func newGetMembersHandler(b MemberBackend) http.HandlerFunc {
 			UserType:     b.UserType,
 		}
 
-		opts := influxdb.FindOptions{}
-		mappings, _, err := b.UserResourceMappingService.FindUserResourceMappings(ctx, filter)
+		mappings, _, err := b.UserResourceMappingService.FindUserResourceMappings(ctx, filter, req.Opts...)
 		if err != nil {
 			b.HandleHTTPError(ctx, err, w)
 			return
@@ -164,12 +173,41 @@ func newGetMembersHandler(b MemberBackend) http.HandlerFunc {
 				b.HandleHTTPError(ctx, err, w)
 				return
 			}
+			if req.Status != nil && userStatus(user) != *req.Status {
+				continue
+			}
 
 			users = append(users, user)
 		}
 		b.log.Debug("Members/owners retrieved", zap.String("users", fmt.Sprint(users)))
 
-		if err := encodeResponse(ctx, w, http.StatusOK, newResourceUsersResponse(opts, filter, users)); err != nil {
+		var lastSeen map[influxdb.ID]time.Time
+		if b.UserActivityService != nil && len(users) > 0 {
+			ids := make([]influxdb.ID, 0, len(users))
+			for _, u := range users {
+				ids = append(ids, u.ID)
+			}
+			lastSeen, err = b.UserActivityService.FindUsersLastSeen(ctx, ids)
+			if err != nil {
+				b.HandleHTTPError(ctx, err, w)
+				return
+			}
+		}
+
+		var opts influxdb.FindOptions
+		if len(req.Opts) > 0 {
+			opts = req.Opts[0]
+		}
+		res := newResourceUsersResponse(opts, filter, users, lastSeen)
+		// the next page starts after the last mapping found, the mappings
+		// filtered out of the page included.
+		if len(req.Opts) > 0 && len(mappings) > 0 {
+			if next := nextAfterLink(res.Links["self"], opts, len(mappings), mappings[len(mappings)-1].UserID); next != "" {
+				res.Links["next"] = next
+			}
+		}
+
+		if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
 			b.HandleHTTPError(ctx, err, w)
 			return
 		}
@@ -179,6 +217,17 @@ func newGetMembersHandler(b MemberBackend) http.HandlerFunc {
 type getMembersRequest struct {
 	MemberID   influxdb.ID
 	ResourceID influxdb.ID
+	Status     *influxdb.Status
+	Opts       []influxdb.FindOptions
+}
+
+// userStatus returns the status of the user, users created before the status
+// existed have none and are active.
+func userStatus(u *influxdb.User) influxdb.Status {
+	if u.Status == "" {
+		return influxdb.Active
+	}
+	return u.Status
 }
 
 func decodeGetMembersRequest(ctx context.Context, r *http.Request) (*getMembersRequest, error) {
@@ -200,6 +249,20 @@ func decodeGetMembersRequest(ctx context.Context, r *http.Request) (*getMembersR
 		ResourceID: i,
 	}
 
+	if s := r.URL.Query().Get("status"); s != "" {
+		status := influxdb.Status(s)
+		if err := status.Valid(); err != nil {
+			return nil, err
+		}
+		req.Status = &status
+	}
+
+	opts, err := decodeOptionalFindOptions(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Opts = opts
+
 	return req, nil
 }
 
@@ -272,6 +335,7 @@ func (s *UserResourceMappingService) FindUserResourceMappings(ctx context.Contex
 	var results resourceUsersResponse
 	err := s.Client.
 		Get(resourceIDPath(f.ResourceType, f.ResourceID, string(f.UserType)+"s")).
+		QueryParams(findOptionParams(opt...)...).
 		DecodeJSON(&results).
 		Do(ctx)
 	if err != nil {
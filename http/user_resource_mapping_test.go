@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/influxdata/httprouter"
 	platform "github.com/influxdata/influxdb"
@@ -223,6 +224,126 @@ func TestUserResourceMappingService_GetMembersHandler(t *testing.T) {
 	}
 }
 
+func TestUserResourceMappingService_GetMembersHandler_Status(t *testing.T) {
+	lastSeen := time.Date(2019, 12, 1, 10, 0, 0, 0, time.UTC)
+	memberBackend := MemberBackend{
+		log:          zaptest.NewLogger(t),
+		ResourceType: platform.OrgsResourceType,
+		UserType:     platform.Member,
+		UserResourceMappingService: &mock.UserResourceMappingService{
+			FindMappingsFn: func(ctx context.Context, filter platform.UserResourceMappingFilter) ([]*platform.UserResourceMapping, int, error) {
+				ms := []*platform.UserResourceMapping{
+					{ResourceID: filter.ResourceID, ResourceType: filter.ResourceType, UserType: filter.UserType, UserID: 1},
+					{ResourceID: filter.ResourceID, ResourceType: filter.ResourceType, UserType: filter.UserType, UserID: 2},
+				}
+				return ms, len(ms), nil
+			},
+		},
+		UserService: &mock.UserService{
+			FindUserByIDFn: func(ctx context.Context, id platform.ID) (*platform.User, error) {
+				status := platform.Active
+				if id == 2 {
+					status = platform.Inactive
+				}
+				return &platform.User{ID: id, Name: fmt.Sprintf("user%s", id), Status: status}, nil
+			},
+		},
+		UserActivityService: &fakeUserActivityService{
+			seen: map[platform.ID]time.Time{1: lastSeen},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "active members with last seen time",
+			query:      "?status=active",
+			statusCode: http.StatusOK,
+			body: `
+{
+  "links": {
+    "self": "/api/v2/orgs/0000000000000099/members"
+  },
+  "users": [
+    {
+      "links": {
+        "logs": "/api/v2/users/0000000000000001/logs",
+        "self": "/api/v2/users/0000000000000001"
+      },
+      "id": "0000000000000001",
+      "name": "user0000000000000001",
+      "role": "member",
+      "status": "active",
+      "lastSeenAt": "2019-12-01T10:00:00Z"
+    }
+  ]
+}`,
+		},
+		{
+			name:       "inactive members",
+			query:      "?status=inactive",
+			statusCode: http.StatusOK,
+			body: `
+{
+  "links": {
+    "self": "/api/v2/orgs/0000000000000099/members"
+  },
+  "users": [
+    {
+      "links": {
+        "logs": "/api/v2/users/0000000000000002/logs",
+        "self": "/api/v2/users/0000000000000002"
+      },
+      "id": "0000000000000002",
+      "name": "user0000000000000002",
+      "role": "member",
+      "status": "inactive"
+    }
+  ]
+}`,
+		},
+		{
+			name:       "invalid status",
+			query:      "?status=gone",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "http://any.url"+tt.query, nil)
+			r = r.WithContext(context.WithValue(
+				context.TODO(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "0000000000000099",
+					},
+				}))
+
+			w := httptest.NewRecorder()
+			b := memberBackend
+			b.HTTPErrorHandler = ErrorHandler(0)
+			newGetMembersHandler(b).ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.statusCode {
+				t.Fatalf("GetMembersHandler() = %v, want %v: %s", res.StatusCode, tt.statusCode, body)
+			}
+			if eq, diff, _ := jsonEqual(string(body), tt.body); tt.body != "" && !eq {
+				t.Errorf("GetMembersHandler() = ***%s***", diff)
+			}
+		})
+	}
+}
+
 func TestUserResourceMappingService_PostMembersHandler(t *testing.T) {
 	type fields struct {
 		userService                platform.UserService
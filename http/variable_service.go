@@ -271,10 +271,7 @@ func decodePostVariableRequest(r *http.Request) (*postVariableRequest, error) {
 
 	err := json.NewDecoder(r.Body).Decode(m)
 	if err != nil {
-		return nil, &platform.Error{
-			Code: platform.EInvalid,
-			Msg:  err.Error(),
-		}
+		return nil, variableDecodeErr(err)
 	}
 
 	req := &postVariableRequest{
@@ -291,6 +288,18 @@ func decodePostVariableRequest(r *http.Request) (*postVariableRequest, error) {
 	return req, nil
 }
 
+// variableDecodeErr keeps the code of the errors of the variable arguments,
+// i.e. a duplicate map key is unprocessable rather than invalid.
+func variableDecodeErr(err error) error {
+	if pe, ok := err.(*platform.Error); ok {
+		return pe
+	}
+	return &platform.Error{
+		Code: platform.EInvalid,
+		Msg:  err.Error(),
+	}
+}
+
 func (h *VariableHandler) handlePatchVariable(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	req, err := decodePatchVariableRequest(ctx, r)
@@ -332,10 +341,7 @@ func decodePatchVariableRequest(ctx context.Context, r *http.Request) (*patchVar
 
 	err := json.NewDecoder(r.Body).Decode(u)
 	if err != nil {
-		return nil, &platform.Error{
-			Code: platform.EInvalid,
-			Msg:  err.Error(),
-		}
+		return nil, variableDecodeErr(err)
 	}
 
 	id, err := requestVariableID(ctx)
@@ -398,10 +404,7 @@ func decodePutVariableRequest(ctx context.Context, r *http.Request) (*putVariabl
 
 	err := json.NewDecoder(r.Body).Decode(m)
 	if err != nil {
-		return nil, &platform.Error{
-			Code: platform.EInvalid,
-			Err:  err,
-		}
+		return nil, variableDecodeErr(err)
 	}
 
 	req := &putVariableRequest{
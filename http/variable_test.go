@@ -421,7 +421,7 @@ func TestVariableService_handleGetVariable(t *testing.T) {
 			wants: wants{
 				statusCode:  400,
 				contentType: "application/json; charset=utf-8",
-				body:        `{"code":"invalid","message":"id must have a length of 16 bytes"}`,
+				body:        `{"code":"invalid","message":"invalid ID \"baz\": must be a 16 character hex string"}`,
 			},
 		},
 	}
@@ -563,6 +563,33 @@ func TestVariableService_handlePostVariable(t *testing.T) {
 				body:        `{"code":"invalid","message":"invalid character 'h' looking for beginning of value"}`,
 			},
 		},
+		{
+			name: "create a map variable with a duplicate key",
+			fields: fields{
+				&mock.VariableService{
+					CreateVariableF: func(ctx context.Context, m *platform.Variable) error {
+						return nil
+					},
+				},
+			},
+			args: args{
+				variable: `
+{
+  "name": "my-great-variable",
+  "orgID": "0000000000000001",
+  "arguments": {
+    "type": "map",
+    "values": {"a": "1", "b": "2", "a": "3"}
+  }
+}
+`,
+			},
+			wants: wants{
+				statusCode:  422,
+				contentType: "application/json; charset=utf-8",
+				body:        `{"code":"unprocessable entity","message":"duplicate variable map key \"a\""}`,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"time"
 
@@ -204,22 +203,45 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	// TODO(jeff): we should be publishing with the org and bucket instead of
 	// parsing, rewriting, and publishing, but the interface isn't quite there yet.
 	// be sure to remove this when it is there!
-	span, _ = tracing.StartSpanFromContextWithOperationName(ctx, "read request body")
-	data, err := ioutil.ReadAll(in)
-	span.LogKV("request_bytes", len(data))
-	span.Finish()
-	if err != nil {
-		log.Error("Error reading body", zap.Error(err))
-		h.HandleHTTPError(ctx, &influxdb.Error{
-			Code: influxdb.EInternal,
-			Op:   "http/handleWrite",
-			Msg:  fmt.Sprintf("unable to read data: %v", err),
-			Err:  err,
-		}, w)
-		return
+	//
+	// the body is parsed as it is read, the points are written in a single call
+	// once all of them parse so a body with invalid line protocol writes nothing.
+	span, _ = tracing.StartSpanFromContextWithOperationName(ctx, "reading and parsing")
+	encoded := tsdb.EncodeName(org.ID, bucket.ID)
+	mm := models.EscapeMeasurement(encoded[:])
+	parser := models.NewStreamParser(in, mm, time.Now(), req.Precision)
+	var points []models.Point
+	for {
+		batch, err := parser.Next()
+		if err == io.EOF {
+			break
+		}
+		if rerr, ok := err.(*models.StreamReadError); ok {
+			span.Finish()
+			log.Error("Error reading body", zap.Error(rerr.Err))
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInternal,
+				Op:   "http/handleWrite",
+				Msg:  fmt.Sprintf("unable to read data: %v", rerr.Err),
+				Err:  rerr.Err,
+			}, w)
+			return
+		}
+		if err != nil {
+			span.Finish()
+			log.Error("Error parsing points", zap.Error(err))
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  err.Error(),
+			}, w)
+			return
+		}
+		points = append(points, batch...)
 	}
+	requestBytes = parser.BytesRead()
+	span.LogKV("request_bytes", requestBytes, "values_total", len(points))
+	span.Finish()
 
-	requestBytes = len(data)
 	if requestBytes == 0 {
 		h.HandleHTTPError(ctx, &influxdb.Error{
 			Code: influxdb.EInvalid,
@@ -229,21 +251,6 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	span, _ = tracing.StartSpanFromContextWithOperationName(ctx, "encoding and parsing")
-	encoded := tsdb.EncodeName(org.ID, bucket.ID)
-	mm := models.EscapeMeasurement(encoded[:])
-	points, err := models.ParsePointsWithPrecision(data, mm, time.Now(), req.Precision)
-	span.LogKV("values_total", len(points))
-	span.Finish()
-	if err != nil {
-		log.Error("Error parsing points", zap.Error(err))
-		h.HandleHTTPError(ctx, &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Msg:  err.Error(),
-		}, w)
-		return
-	}
-
 	if err := h.PointsWriter.WritePoints(ctx, points); err != nil {
 		log.Error("Error writing points", zap.Error(err))
 		h.HandleHTTPError(ctx, &influxdb.Error{
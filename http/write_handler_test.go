@@ -15,6 +15,7 @@ import (
 	"github.com/influxdata/influxdb/http/metric"
 	httpmock "github.com/influxdata/influxdb/http/mock"
 	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
 	influxtesting "github.com/influxdata/influxdb/testing"
 	"go.uber.org/zap/zaptest"
 )
@@ -302,6 +303,60 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 	}
 }
 
+func TestWriteHandler_handleWrite_singleWrite(t *testing.T) {
+	const (
+		org    = "043e0780ee2b1000"
+		bucket = "04504b356e23b000"
+	)
+
+	// the body spans several batches of the stream parser, its points must
+	// still be written in a single call so a failed write writes none of them.
+	n := models.DefaultStreamBatchSize + 1
+	var body strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&body, "m1,t1=v%d f1=1\n", i)
+	}
+
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg(org), nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return testBucket(org, bucket), nil
+	}
+	pw := &mock.PointsWriter{Err: fmt.Errorf("error")}
+
+	b := &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zaptest.NewLogger(t),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter:        pw,
+		WriteEventRecorder:  &metric.NopEventRecorder{},
+	}
+	writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+	handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission(org, bucket))
+
+	r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write", strings.NewReader(body.String()))
+	params := r.URL.Query()
+	params.Set("org", org)
+	params.Set("bucket", bucket)
+	r.URL.RawQuery = params.Encode()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusInternalServerError; got != want {
+		t.Errorf("unexpected status code: got %d want %d", got, want)
+	}
+	if got, want := pw.WritePointsCalled(), 1; got != want {
+		t.Errorf("unexpected number of writes: got %d want %d", got, want)
+	}
+	if got, want := len(pw.Points), n; got != want {
+		t.Errorf("unexpected number of points: got %d want %d", got, want)
+	}
+}
+
 var DefaultErrorHandler = ErrorHandler(0)
 
 func bucketWritePermission(org, bucket string) *influxdb.Authorization {
@@ -3,8 +3,10 @@ package influxdb
 import (
 	"encoding/binary"
 	"encoding/hex"
+	"fmt"
 	"reflect"
 	"strconv"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -19,12 +21,36 @@ var (
 	}
 
 	// ErrInvalidIDLength is returned when an ID has the incorrect number of bytes.
+	//
+	// Deprecated: decoding an ID of the incorrect length returns an error
+	// echoing the ID and the formats it may be provided in.
 	ErrInvalidIDLength = &Error{
 		Code: EInvalid,
 		Msg:  "id must have a length of 16 bytes",
 	}
 )
 
+// strictIDDecoding is 1 when IDs are only decoded from their hex encoding, and
+// 0 when a decimal ID is decoded as well.
+var strictIDDecoding int32 = 1
+
+// SetStrictIDDecoding sets whether IDs are only decoded from their hex
+// encoding, the default. When it is disabled, a string that is not a valid hex
+// encoded ID is decoded as a decimal ID, i.e. "70000". A string that is valid
+// in both forms is always decoded as hex.
+func SetStrictIDDecoding(strict bool) {
+	var v int32
+	if strict {
+		v = 1
+	}
+	atomic.StoreInt32(&strictIDDecoding, v)
+}
+
+// StrictIDDecoding reports whether IDs are only decoded from their hex encoding.
+func StrictIDDecoding() bool {
+	return atomic.LoadInt32(&strictIDDecoding) == 1
+}
+
 // ID is a unique identifier.
 //
 // Its zero value is not a valid ID.
@@ -53,18 +79,16 @@ func InvalidID() ID {
 	return 0
 }
 
-// Decode parses b as a hex-encoded byte-slice-string.
+// Decode parses b as a hex-encoded byte-slice-string. When strict ID decoding
+// is disabled, b is parsed as a decimal number if it is not a valid hex-encoded
+// ID.
 //
 // It errors if the input byte slice does not have the correct length
 // or if it contains all zeros.
 func (i *ID) Decode(b []byte) error {
-	if len(b) != IDLength {
-		return ErrInvalidIDLength
-	}
-
-	res, err := strconv.ParseUint(unsafeBytesToString(b), 16, 64)
+	res, err := parseID(b)
 	if err != nil {
-		return ErrInvalidID
+		return err
 	}
 
 	if *i = ID(res); !i.Valid() {
@@ -73,6 +97,41 @@ func (i *ID) Decode(b []byte) error {
 	return nil
 }
 
+func parseID(b []byte) (uint64, error) {
+	if len(b) == IDLength {
+		if res, err := strconv.ParseUint(unsafeBytesToString(b), 16, 64); err == nil {
+			return res, nil
+		}
+	}
+
+	strict := StrictIDDecoding()
+	if !strict {
+		if res, err := strconv.ParseUint(unsafeBytesToString(b), 10, 64); err == nil {
+			return res, nil
+		}
+	}
+	return 0, invalidIDError(b, strict)
+}
+
+// maxInvalidIDEcho is the length an invalid ID is truncated to in its error.
+const maxInvalidIDEcho = 64
+
+func invalidIDError(b []byte, strict bool) error {
+	val := string(b)
+	if len(val) > maxInvalidIDEcho {
+		val = val[:maxInvalidIDEcho] + "..."
+	}
+
+	format := "a 16 character hex string"
+	if !strict {
+		format += " or a decimal number"
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("invalid ID %q: must be %s", val, format),
+	}
+}
+
 func unsafeBytesToString(in []byte) string {
 	src := *(*reflect.SliceHeader)(unsafe.Pointer(&in))
 	dst := reflect.StringHeader{
@@ -39,19 +39,25 @@ func TestIDFromString(t *testing.T) {
 			name:    "Should not be able to decode a non hex ID",
 			id:      "gggggggggggggggg",
 			wantErr: true,
-			err:     influxdb.ErrInvalidID.Error(),
+			err:     `invalid ID "gggggggggggggggg": must be a 16 character hex string`,
+		},
+		{
+			name:    "Should not be able to decode a decimal ID",
+			id:      "70000",
+			wantErr: true,
+			err:     `invalid ID "70000": must be a 16 character hex string`,
 		},
 		{
 			name:    "Should not be able to decode inputs with length less than 16 bytes",
 			id:      "abc",
 			wantErr: true,
-			err:     influxdb.ErrInvalidIDLength.Error(),
+			err:     `invalid ID "abc": must be a 16 character hex string`,
 		},
 		{
 			name:    "Should not be able to decode inputs with length greater than 16 bytes",
 			id:      "abcdabcdabcdabcd0",
 			wantErr: true,
-			err:     influxdb.ErrInvalidIDLength.Error(),
+			err:     `invalid ID "abcdabcdabcdabcd0": must be a 16 character hex string`,
 		},
 	}
 	for _, tt := range tests {
@@ -74,6 +80,84 @@ func TestIDFromString(t *testing.T) {
 	}
 }
 
+func TestIDFromString_Lenient(t *testing.T) {
+	influxdb.SetStrictIDDecoding(false)
+	defer influxdb.SetStrictIDDecoding(true)
+
+	tests := []struct {
+		name    string
+		id      string
+		want    influxdb.ID
+		wantErr string
+	}{
+		{
+			name: "hex ID",
+			id:   "020f755c3c082000",
+			want: platformtesting.MustIDBase16("020f755c3c082000"),
+		},
+		{
+			name: "decimal ID",
+			id:   "70000",
+			want: influxdb.ID(70000),
+		},
+		{
+			name: "string valid as both hex and decimal is decoded as hex",
+			id:   "1000000000000000",
+			want: influxdb.ID(0x1000000000000000),
+		},
+		{
+			name: "16 character decimal ID too large for hex is decoded as hex",
+			id:   "9999999999999999",
+			want: influxdb.ID(0x9999999999999999),
+		},
+		{
+			name: "17 character decimal ID",
+			id:   "10000000000000000",
+			want: influxdb.ID(10000000000000000),
+		},
+		{
+			name:    "zero decimal ID",
+			id:      "0",
+			wantErr: influxdb.ErrInvalidID.Error(),
+		},
+		{
+			name:    "decimal ID overflowing 64 bits",
+			id:      "18446744073709551616",
+			wantErr: `invalid ID "18446744073709551616": must be a 16 character hex string or a decimal number`,
+		},
+		{
+			name:    "negative decimal ID",
+			id:      "-1",
+			wantErr: `invalid ID "-1": must be a 16 character hex string or a decimal number`,
+		},
+		{
+			name:    "neither hex nor decimal",
+			id:      "baz",
+			wantErr: `invalid ID "baz": must be a 16 character hex string or a decimal number`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := influxdb.IDFromString(tt.id)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("IDFromString() errors out %v, want %q", err, tt.wantErr)
+				}
+				if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+					t.Errorf("IDFromString() error code %q, want %q", code, influxdb.EInvalid)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("IDFromString() unexpected error: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("IDFromString() outputs %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestDecodeFromString(t *testing.T) {
 	var id influxdb.ID
 	err := id.DecodeFromString("020f755c3c082000")
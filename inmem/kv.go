@@ -49,7 +49,8 @@ func (s *KVStore) Update(ctx context.Context, fn func(kv.Tx) error) error {
 	})
 }
 
-// Flush removes all data from the buckets.  Used for testing.
+// Flush removes all data from the buckets, the buckets themselves are kept
+// and still reported by Buckets. Used for testing.
 func (s *KVStore) Flush(ctx context.Context) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -58,6 +59,17 @@ func (s *KVStore) Flush(ctx context.Context) {
 	}
 }
 
+// Reset removes all of the buckets, along with their data, leaving the store
+// as it was created: where Flush leaves empty buckets, Reset leaves no
+// buckets at all. A bucket is only created anew by a writable transaction,
+// read only transactions fail to find it until then. Used for testing.
+func (s *KVStore) Reset(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buckets = map[string]*Bucket{}
+	s.ro = map[string]*bucket{}
+}
+
 // Buckets returns the names of all buckets within inmem.KVStore.
 func (s *KVStore) Buckets(ctx context.Context) [][]byte {
 	s.mu.RLock()
@@ -70,6 +82,30 @@ func (s *KVStore) Buckets(ctx context.Context) [][]byte {
 	return buckets
 }
 
+// KVStoreStats are the counts of the buckets and the keys of a KVStore.
+type KVStoreStats struct {
+	// Buckets is the number of buckets.
+	Buckets int
+	// Keys is the number of keys of each bucket by its name.
+	Keys map[string]int
+}
+
+// Stats returns the number of buckets and the number of keys of each bucket
+// of the store. It is cheap, the keys are not walked.
+func (s *KVStore) Stats() KVStoreStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := KVStoreStats{
+		Buckets: len(s.buckets),
+		Keys:    make(map[string]int, len(s.buckets)),
+	}
+	for name, b := range s.buckets {
+		stats.Keys[name] = b.btree.Len()
+	}
+	return stats
+}
+
 // Tx is an in memory transaction.
 // TODO: make transactions actually transactional
 type Tx struct {
@@ -125,7 +161,7 @@ type Bucket struct {
 }
 
 type bucket struct {
-	kv.Bucket
+	*Bucket
 }
 
 // Put wraps the put method of a kv bucket and ensures that the
@@ -155,8 +191,29 @@ func (i *item) Less(b btree.Item) bool {
 	return bytes.Compare(i.key, j.key) < 0
 }
 
-// Get retrieves the value at the provided key.
+// Get retrieves the value at the provided key. The value returned is a copy
+// of the stored value and is safe for the caller to modify.
 func (b *Bucket) Get(key []byte) ([]byte, error) {
+	j, err := b.get(key)
+	if err != nil {
+		return nil, err
+	}
+	return copyBytes(j.value), nil
+}
+
+// AppendValue appends the value at the provided key to dst and returns the
+// extended buffer. It is provided for hot paths that want to avoid allocating
+// a copy for every read, i.e. by reusing a buffer taken from a sync.Pool. As
+// with Get, the returned buffer never aliases the stored value.
+func (b *Bucket) AppendValue(dst, key []byte) ([]byte, error) {
+	j, err := b.get(key)
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, j.value...), nil
+}
+
+func (b *Bucket) get(key []byte) (*item, error) {
 	i := b.btree.Get(&item{key: key})
 
 	if i == nil {
@@ -168,12 +225,16 @@ func (b *Bucket) Get(key []byte) ([]byte, error) {
 		return nil, fmt.Errorf("error item is type %T not *item", i)
 	}
 
-	return j.value, nil
+	return j, nil
 }
 
-// Put sets the key value pair provided.
+// Put sets the key value pair provided. The key and value are copied, the
+// caller is free to reuse them once Put returns.
 func (b *Bucket) Put(key []byte, value []byte) error {
-	_ = b.btree.ReplaceOrInsert(&item{key: key, value: value})
+	_ = b.btree.ReplaceOrInsert(&item{
+		key:   copyBytes(key),
+		value: copyBytes(value),
+	})
 	return nil
 }
 
@@ -183,29 +244,37 @@ func (b *Bucket) Delete(key []byte) error {
 	return nil
 }
 
-// Cursor creates a static cursor from all entries in the database.
+// Cursor creates a static cursor from all entries in the database, in
+// descending key order when hinted to.
 func (b *Bucket) Cursor(opts ...kv.CursorHint) (kv.Cursor, error) {
 	var o kv.CursorHints
 	for _, opt := range opts {
 		opt(&o)
 	}
 
-	// TODO we should do this by using the Ascend/Descend methods that
-	//  the btree provides.
 	pairs, err := b.getAll(&o)
 	if err != nil {
 		return nil, err
 	}
 
+	if o.Descending {
+		return kv.NewStaticCursorDescending(pairs), nil
+	}
 	return kv.NewStaticCursor(pairs), nil
 }
 
+// getAll returns copies of all pairs matching the cursor hints. The matching
+// keys and values are copied into a single buffer, so that the copy costs one
+// allocation per cursor rather than two per pair.
 func (b *Bucket) getAll(o *kv.CursorHints) ([]kv.Pair, error) {
 	fn := o.PredicateFn
 
-	var pairs []kv.Pair
-	var err error
-	b.btree.Ascend(func(i btree.Item) bool {
+	var (
+		items []*item
+		size  int
+		err   error
+	)
+	iter := func(i btree.Item) bool {
 		j, ok := i.(*item)
 		if !ok {
 			err = fmt.Errorf("error item is type %T not *item", i)
@@ -213,15 +282,46 @@ func (b *Bucket) getAll(o *kv.CursorHints) ([]kv.Pair, error) {
 		}
 
 		if fn == nil || fn(j.key, j.value) {
-			pairs = append(pairs, kv.Pair{Key: j.key, Value: j.value})
+			items = append(items, j)
+			size += len(j.key) + len(j.value)
 		}
 
 		return true
-	})
+	}
+
+	// the pairs are collected in the order of the cursor
+	if o.Descending {
+		b.btree.Descend(iter)
+	} else {
+		b.btree.Ascend(iter)
+	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	buf := make([]byte, 0, size)
+	pairs := make([]kv.Pair, 0, len(items))
+	for _, j := range items {
+		buf = append(buf, j.key...)
+		key := buf[len(buf)-len(j.key) : len(buf) : len(buf)]
+		buf = append(buf, j.value...)
+		value := buf[len(buf)-len(j.value) : len(buf) : len(buf)]
+		pairs = append(pairs, kv.Pair{Key: key, Value: value})
+	}
+
 	return pairs, nil
 }
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
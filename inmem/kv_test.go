@@ -7,6 +7,7 @@ import (
 	"os"
 	"reflect"
 	"sort"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -84,6 +85,98 @@ func TestKVStore_Buckets(t *testing.T) {
 	}
 }
 
+func TestKVStore_Stats(t *testing.T) {
+	s := inmem.NewKVStore()
+	if got := s.Stats(); got.Buckets != 0 || len(got.Keys) != 0 {
+		t.Fatalf("KVStore.Stats() of an empty store = %+v", got)
+	}
+
+	err := s.Update(context.Background(), func(tx kv.Tx) error {
+		b1, err := tx.Bucket([]byte("b1"))
+		if err != nil {
+			return err
+		}
+		for _, k := range []string{"a", "b", "c", "d"} {
+			if err := b1.Put([]byte(k), []byte("v")); err != nil {
+				return err
+			}
+		}
+		// a put of an existing key is not a new key
+		if err := b1.Put([]byte("a"), []byte("v2")); err != nil {
+			return err
+		}
+		if err := b1.Delete([]byte("b")); err != nil {
+			return err
+		}
+
+		b2, err := tx.Bucket([]byte("b2"))
+		if err != nil {
+			return err
+		}
+		if err := b2.Put([]byte("a"), []byte("v")); err != nil {
+			return err
+		}
+		if err := b2.Delete([]byte("a")); err != nil {
+			return err
+		}
+
+		_, err = tx.Bucket([]byte("b3"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unable to setup store: %v", err)
+	}
+
+	want := inmem.KVStoreStats{
+		Buckets: 3,
+		Keys:    map[string]int{"b1": 3, "b2": 0, "b3": 0},
+	}
+	if got := s.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("KVStore.Stats() = %+v, want %+v", got, want)
+	}
+
+	s.Flush(context.Background())
+	want.Keys["b1"] = 0
+	if got := s.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("KVStore.Stats() after a flush = %+v, want %+v", got, want)
+	}
+}
+
+func TestKVStore_Reset(t *testing.T) {
+	s := inmem.NewKVStore()
+	fillBucket(t, s, "bucket", 10)
+
+	s.Flush(context.Background())
+	if got := len(s.Buckets(context.Background())); got != 1 {
+		t.Fatalf("expected a flush to keep the bucket, got %d buckets", got)
+	}
+
+	s.Reset(context.Background())
+	if got := s.Buckets(context.Background()); len(got) != 0 {
+		t.Fatalf("expected no buckets after a reset, got %q", got)
+	}
+
+	err := s.View(context.Background(), func(tx kv.Tx) error {
+		_, err := tx.Bucket([]byte("bucket"))
+		return err
+	})
+	if err != kv.ErrTxNotWritable {
+		t.Errorf("expected a read of a reset bucket to fail with %v, got %v", kv.ErrTxNotWritable, err)
+	}
+
+	// a writable transaction creates the bucket anew, empty
+	err = s.Update(context.Background(), func(tx kv.Tx) error {
+		_, err := tx.Bucket([]byte("bucket"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := s.Stats(); got.Buckets != 1 || got.Keys["bucket"] != 0 {
+		t.Errorf("expected the bucket to be created anew and empty, got %+v", got)
+	}
+}
+
 func TestKVStore_Bucket_CursorHintPredicate(t *testing.T) {
 	s := inmem.NewKVStore()
 
@@ -139,6 +232,186 @@ func TestKVStore_Bucket_CursorHintPredicate(t *testing.T) {
 	})
 }
 
+func TestKVStore_Bucket_CursorHintDescending(t *testing.T) {
+	s := inmem.NewKVStore()
+
+	err := s.Update(context.Background(), func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+		for _, k := range []string{"c", "a", "e", "b", "d"} {
+			if err := b.Put([]byte(k), []byte(k)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	keys := func(t *testing.T, hints ...kv.CursorHint) []string {
+		t.Helper()
+
+		var got []string
+		err := s.View(context.Background(), func(tx kv.Tx) error {
+			b, err := tx.Bucket([]byte("bucket"))
+			if err != nil {
+				return err
+			}
+
+			cur, err := b.Cursor(hints...)
+			if err != nil {
+				return err
+			}
+			for k, _ := cur.First(); len(k) > 0; k, _ = cur.Next() {
+				got = append(got, string(k))
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return got
+	}
+
+	notC := kv.WithCursorHintPredicate(func(key, _ []byte) bool {
+		return string(key) != "c"
+	})
+
+	tests := []struct {
+		name  string
+		hints []kv.CursorHint
+		exp   []string
+	}{
+		{
+			name: "ascending",
+			exp:  []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:  "descending",
+			hints: []kv.CursorHint{kv.WithCursorHintDescending()},
+			exp:   []string{"e", "d", "c", "b", "a"},
+		},
+		{
+			name:  "ascending with predicate",
+			hints: []kv.CursorHint{notC},
+			exp:   []string{"a", "b", "d", "e"},
+		},
+		{
+			name:  "descending with predicate",
+			hints: []kv.CursorHint{notC, kv.WithCursorHintDescending()},
+			exp:   []string{"e", "d", "b", "a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keys(t, tt.hints...); !cmp.Equal(got, tt.exp) {
+				t.Errorf("unexpected keys, -got/+exp\n%s", cmp.Diff(got, tt.exp))
+			}
+		})
+	}
+}
+
+func TestKVStore_Bucket_CopyOnRead(t *testing.T) {
+	s := inmem.NewKVStore()
+
+	key, value := []byte("key"), []byte("value")
+	err := s.Update(context.Background(), func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+		if err := b.Put(key, value); err != nil {
+			return err
+		}
+
+		// mutating the inputs after a put should not reach the store
+		copy(value, "XXXXX")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mutate := func(b []byte) {
+		for i := range b {
+			b[i] = 'X'
+		}
+	}
+
+	assertStored := func(t *testing.T) {
+		t.Helper()
+
+		_ = s.View(context.Background(), func(tx kv.Tx) error {
+			b, err := tx.Bucket([]byte("bucket"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := b.Get([]byte("key"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != "value" {
+				t.Errorf("stored value was modified: got %q", got)
+			}
+			return nil
+		})
+	}
+
+	t.Run("get", func(t *testing.T) {
+		_ = s.View(context.Background(), func(tx kv.Tx) error {
+			b, err := tx.Bucket([]byte("bucket"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			v, err := b.Get([]byte("key"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			mutate(v)
+			_ = append(v[:1], "appended"...)
+			return nil
+		})
+		assertStored(t)
+	})
+
+	t.Run("cursor", func(t *testing.T) {
+		openCursor(t, s, "bucket", func(cur kv.Cursor) {
+			for k, v := cur.First(); k != nil; k, v = cur.Next() {
+				mutate(k)
+				mutate(v)
+			}
+		})
+		assertStored(t)
+	})
+
+	t.Run("append value", func(t *testing.T) {
+		_ = s.View(context.Background(), func(tx kv.Tx) error {
+			b, err := tx.Bucket([]byte("bucket"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			v, err := b.(interface {
+				AppendValue(dst, key []byte) ([]byte, error)
+			}).AppendValue([]byte("prefix:"), []byte("key"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(v) != "prefix:value" {
+				t.Errorf("unexpected value: got %q", v)
+			}
+			mutate(v)
+			return nil
+		})
+		assertStored(t)
+	})
+}
+
 func openCursor(t testing.TB, s *inmem.KVStore, bucket string, fn func(cur kv.Cursor), hints ...kv.CursorHint) {
 	t.Helper()
 
@@ -198,6 +471,58 @@ func BenchmarkKVStore_Bucket_Cursor(b *testing.B) {
 	})
 }
 
+func BenchmarkKVStore_Bucket_Get(b *testing.B) {
+	s := inmem.NewKVStore()
+	bucket := "urm"
+	fillBucket(b, s, bucket, 0)
+
+	key := []byte("0000a700045660f0f70876000045660e")
+	pool := sync.Pool{
+		New: func() interface{} {
+			return make([]byte, 0, 64)
+		},
+	}
+
+	b.Run("get", func(b *testing.B) {
+		_ = s.View(context.Background(), func(tx kv.Tx) error {
+			bkt, err := tx.Bucket([]byte(bucket))
+			if err != nil {
+				b.Fatalf("unexpected error: %v", err)
+			}
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				_, _ = bkt.Get(key)
+			}
+			return nil
+		})
+	})
+
+	b.Run("append value with pooled buffer", func(b *testing.B) {
+		_ = s.View(context.Background(), func(tx kv.Tx) error {
+			bkt, err := tx.Bucket([]byte(bucket))
+			if err != nil {
+				b.Fatalf("unexpected error: %v", err)
+			}
+			appender := bkt.(interface {
+				AppendValue(dst, key []byte) ([]byte, error)
+			})
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				buf := pool.Get().([]byte)
+				buf, _ = appender.AppendValue(buf[:0], key)
+				pool.Put(buf)
+			}
+			return nil
+		})
+	})
+}
+
 const sourceFile = "kvdata/keys.txt"
 
 func fillBucket(t testing.TB, s *inmem.KVStore, bucket string, lines int64) {
@@ -109,7 +109,7 @@ func (s *Service) FindLabels(ctx context.Context, filter influxdb.LabelFilter, o
 }
 
 // FindResourceLabels returns a list of labels that are mapped to a resource.
-func (s *Service) FindResourceLabels(ctx context.Context, filter influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+func (s *Service) FindResourceLabels(ctx context.Context, filter influxdb.LabelMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.Label, error) {
 	filterFunc := func(mapping *influxdb.LabelMapping) bool {
 		return (filter.ResourceID.String() == mapping.ResourceID.String())
 	}
@@ -136,6 +136,9 @@ func (s *Service) UpdateTelegrafConfig(ctx context.Context, id platform.ID, tc *
 	tc.ID = id
 	// OrganizationID can not be updated
 	tc.OrgID = current.OrgID
+	if !tc.TokenID.Valid() {
+		tc.TokenID = current.TokenID
+	}
 	pErr = s.putTelegrafConfig(ctx, tc)
 	if pErr != nil {
 		pErr.Op = op
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// AliasUse is a deprecated alias of an option that was set.
+type AliasUse struct {
+	// Alias is the flag or the env var of the alias that was set.
+	Alias string
+	// Replacement is the flag or the env var of the option to use instead.
+	Replacement string
+}
+
+// bindAliases adds the aliases of the option as hidden flags setting the same
+// destination as its flag.
+func bindAliases(cmd *cobra.Command, o Opt) {
+	f := cmd.Flags().Lookup(o.Flag)
+	for _, alias := range o.Aliases {
+		cmd.Flags().AddFlag(&pflag.Flag{
+			Name:        alias,
+			Usage:       fmt.Sprintf("deprecated, use --%s", o.Flag),
+			Value:       f.Value,
+			DefValue:    f.DefValue,
+			NoOptDefVal: f.NoOptDefVal,
+			Hidden:      true,
+		})
+	}
+}
+
+// ResolveAliases sets the options whose deprecated aliases are set by a flag
+// or an env var. It must be called once the flags of the command are parsed,
+// and before LoadConfig.
+//
+// The name of an option takes precedence over its aliases. Setting an option
+// by both its name and an alias in the same way, both as flags or both as env
+// vars, is an error, it is ambiguous which of the values is meant.
+//
+// The aliases that were set are returned, so the caller can warn of them.
+func ResolveAliases(cmd *cobra.Command, envPrefix string, opts []Opt) ([]AliasUse, error) {
+	var uses []AliasUse
+	for _, o := range opts {
+		if len(o.Aliases) == 0 {
+			continue
+		}
+
+		flagSet := cmd.Flags().Changed(o.Flag)
+		env := envName(envPrefix, o.Flag)
+		envVal, envSet := os.LookupEnv(env)
+
+		var aliasFlag, aliasEnv, aliasEnvVal string
+		for _, alias := range o.Aliases {
+			if cmd.Flags().Changed(alias) {
+				if aliasFlag != "" || flagSet {
+					return nil, fmt.Errorf("flags --%s and --%s are both set; --%s is a deprecated alias of --%s, set only --%s", o.Flag, alias, alias, o.Flag, o.Flag)
+				}
+				aliasFlag = alias
+				uses = append(uses, AliasUse{Alias: "--" + alias, Replacement: "--" + o.Flag})
+			}
+
+			e := envName(envPrefix, alias)
+			if v, ok := os.LookupEnv(e); ok {
+				if aliasEnv != "" || envSet {
+					return nil, fmt.Errorf("env vars %s and %s are both set; %s is a deprecated alias of %s, set only %s", env, e, e, env, env)
+				}
+				aliasEnv, aliasEnvVal = e, v
+				uses = append(uses, AliasUse{Alias: e, Replacement: env})
+			}
+		}
+
+		switch {
+		case flagSet:
+			// the flag of the option wins over any alias.
+		case envSet && aliasFlag != "":
+			// the alias flag shares the destination of the option, the
+			// value of the env var it overwrote is restored.
+			if err := setOptString(o, envVal); err != nil {
+				return nil, fmt.Errorf("invalid value of env var %s: %v", env, err)
+			}
+		case envSet, aliasFlag != "":
+			// the env var of the option, or the alias flag, is already set.
+		case aliasEnv != "":
+			if err := setOptString(o, aliasEnvVal); err != nil {
+				return nil, fmt.Errorf("invalid value of env var %s: %v", aliasEnv, err)
+			}
+		}
+	}
+	return uses, nil
+}
+
+// isSet returns true if the option is set by a flag or an env var, by its
+// name or one of its aliases.
+func isSet(cmd *cobra.Command, envPrefix string, o Opt) bool {
+	for _, name := range append([]string{o.Flag}, o.Aliases...) {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+		if _, ok := os.LookupEnv(envName(envPrefix, name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// setOptString sets the destination of the option from the value of an env
+// var, parsed the way viper parses it.
+func setOptString(o Opt, v string) error {
+	switch destP := o.DestP.(type) {
+	case *string:
+		*destP = v
+	case *int:
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*destP = i
+	case *float64:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		*destP = f
+	case *bool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*destP = b
+	case *time.Duration:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*destP = d
+	case *[]string:
+		*destP = strings.Fields(v)
+	default:
+		panic(fmt.Errorf("unknown destination type %t", o.DestP))
+	}
+	return nil
+}
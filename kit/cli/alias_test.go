@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestResolveAliases(t *testing.T) {
+	const prefix = "clitestalias"
+
+	for _, tt := range []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		want     string
+		wantBool bool
+		wantUses []AliasUse
+		wantErr  bool
+	}{
+		{
+			name: "default",
+			want: "default",
+		},
+		{
+			name:     "alias flag",
+			args:     []string{"--old-name", "flag", "--old-bool"},
+			want:     "flag",
+			wantBool: true,
+			wantUses: []AliasUse{
+				{Alias: "--old-name", Replacement: "--new-name"},
+				{Alias: "--old-bool", Replacement: "--new-bool"},
+			},
+		},
+		{
+			name:     "alias env",
+			env:      map[string]string{"CLITESTALIAS_OLD_NAME": "env"},
+			want:     "env",
+			wantUses: []AliasUse{{Alias: "CLITESTALIAS_OLD_NAME", Replacement: "CLITESTALIAS_NEW_NAME"}},
+		},
+		{
+			name:     "flag over alias env",
+			args:     []string{"--new-name", "flag"},
+			env:      map[string]string{"CLITESTALIAS_OLD_NAME": "env"},
+			want:     "flag",
+			wantUses: []AliasUse{{Alias: "CLITESTALIAS_OLD_NAME", Replacement: "CLITESTALIAS_NEW_NAME"}},
+		},
+		{
+			name:     "env over alias flag",
+			args:     []string{"--old-name", "flag"},
+			env:      map[string]string{"CLITESTALIAS_NEW_NAME": "env"},
+			want:     "env",
+			wantUses: []AliasUse{{Alias: "--old-name", Replacement: "--new-name"}},
+		},
+		{
+			name:    "conflicting flags",
+			args:    []string{"--new-name", "a", "--old-name", "b"},
+			wantErr: true,
+		},
+		{
+			name: "conflicting env vars",
+			env: map[string]string{
+				"CLITESTALIAS_NEW_NAME": "a",
+				"CLITESTALIAS_OLD_NAME": "b",
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+				defer os.Unsetenv(k)
+			}
+
+			var (
+				name string
+				b    bool
+				uses []AliasUse
+			)
+			opts := []Opt{
+				{DestP: &name, Flag: "new-name", Default: "default", Aliases: []string{"old-name"}},
+				{DestP: &b, Flag: "new-bool", Aliases: []string{"old-bool"}},
+			}
+			cmd := NewCommand(&Program{
+				Run:  func() error { return nil },
+				Name: prefix,
+				Opts: opts,
+			})
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+			cmd.PreRunE = func(_ *cobra.Command, _ []string) error {
+				var err error
+				uses, err = ResolveAliases(cmd, prefix, opts)
+				return err
+			}
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error for an option set by its name and an alias")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if name != tt.want {
+				t.Errorf("got %q, want %q", name, tt.want)
+			}
+			if b != tt.wantBool {
+				t.Errorf("got bool %v, want %v", b, tt.wantBool)
+			}
+			if !reflect.DeepEqual(uses, tt.wantUses) {
+				t.Errorf("got uses %v, want %v", uses, tt.wantUses)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// LoadConfig sets the options from the config file at the path, a TOML, YAML
+// or JSON file by its extension whose keys are the names of the flags. It must
+// be called once the flags of the command are parsed, an option set by a flag
+// or an env var keeps its value, so the precedence of the sources is flag,
+// env var, config file, and then default.
+//
+// The keys of the file that are not an option are returned, sorted.
+func LoadConfig(cmd *cobra.Command, envPrefix, path string, opts []Opt) ([]string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open config file %q: %v", path, err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file %q: %v", path, err)
+	}
+
+	known := make(map[string]bool, len(opts))
+	for _, o := range opts {
+		known[o.Flag] = true
+
+		if !v.IsSet(o.Flag) || isSet(cmd, envPrefix, o) {
+			continue
+		}
+
+		switch destP := o.DestP.(type) {
+		case *string:
+			*destP = v.GetString(o.Flag)
+		case *int:
+			*destP = v.GetInt(o.Flag)
+		case *float64:
+			*destP = v.GetFloat64(o.Flag)
+		case *bool:
+			*destP = v.GetBool(o.Flag)
+		case *time.Duration:
+			*destP = v.GetDuration(o.Flag)
+		case *[]string:
+			*destP = v.GetStringSlice(o.Flag)
+		default:
+			panic(fmt.Errorf("unknown destination type %t", o.DestP))
+		}
+	}
+
+	var unknown []string
+	for _, k := range v.AllKeys() {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown, nil
+}
+
+// envName returns the env var of the flag, the way NewCommand configures viper
+// to look it up.
+func envName(prefix, flag string) string {
+	name := strings.ToUpper(strings.Replace(flag, "-", "_", -1))
+	if prefix == "" {
+		return name
+	}
+	return strings.ToUpper(prefix) + "_" + name
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.toml")
+	config := []byte(`
+from-file = "file"
+from-env = "file"
+from-flag = "file"
+number = 3
+timeout = "1m"
+tags = ["a", "b"]
+unknown-key = true
+`)
+	if err := ioutil.WriteFile(path, config, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	const prefix = "clitestconfig"
+	os.Setenv("CLITESTCONFIG_FROM_ENV", "env")
+	defer os.Unsetenv("CLITESTCONFIG_FROM_ENV")
+
+	var (
+		fromFile, fromEnv, fromFlag, fromDefault string
+		number                                   int
+		timeout                                  time.Duration
+		tags                                     []string
+		unknown                                  []string
+	)
+	opts := []Opt{
+		{DestP: &fromFile, Flag: "from-file", Default: "default"},
+		{DestP: &fromEnv, Flag: "from-env", Default: "default"},
+		{DestP: &fromFlag, Flag: "from-flag", Default: "default"},
+		{DestP: &fromDefault, Flag: "from-default", Default: "default"},
+		{DestP: &number, Flag: "number", Default: 1},
+		{DestP: &timeout, Flag: "timeout", Default: time.Second},
+		{DestP: &tags, Flag: "tags"},
+	}
+
+	cmd := NewCommand(&Program{
+		Run:  func() error { return nil },
+		Name: prefix,
+		Opts: opts,
+	})
+	cmd.PreRunE = func(_ *cobra.Command, _ []string) error {
+		unknown, err = LoadConfig(cmd, prefix, path, opts)
+		return err
+	}
+	cmd.SetArgs([]string{"--from-flag", "flag"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name      string
+		got, want interface{}
+	}{
+		{name: "file", got: fromFile, want: "file"},
+		{name: "env over file", got: fromEnv, want: "env"},
+		{name: "flag over file", got: fromFlag, want: "flag"},
+		{name: "default", got: fromDefault, want: "default"},
+		{name: "int", got: number, want: 3},
+		{name: "duration", got: timeout, want: time.Minute},
+		{name: "string slice", got: tags, want: []string{"a", "b"}},
+		{name: "unknown keys", got: unknown, want: []string{"unknown-key"}},
+	} {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfig_Missing(t *testing.T) {
+	cmd := NewCommand(&Program{Run: func() error { return nil }, Name: "clitestmissing"})
+	_, err := LoadConfig(cmd, "clitestmissing", "missing.toml", nil)
+	if err == nil {
+		t.Fatal("expected an error for a missing config file")
+	}
+}
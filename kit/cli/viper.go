@@ -15,6 +15,9 @@ type Opt struct {
 	Flag    string
 	Default interface{}
 	Desc    string
+	// Aliases are the deprecated names of the flag, they keep setting the
+	// option by flag or env var, see ResolveAliases.
+	Aliases []string
 }
 
 // NewOpt creates a new command line option.
@@ -83,6 +86,14 @@ func BindOptions(cmd *cobra.Command, opts []Opt) {
 			cmd.Flags().IntVar(destP, o.Flag, d, o.Desc)
 			mustBindPFlag(o.Flag, cmd)
 			*destP = viper.GetInt(o.Flag)
+		case *float64:
+			var d float64
+			if o.Default != nil {
+				d = o.Default.(float64)
+			}
+			cmd.Flags().Float64Var(destP, o.Flag, d, o.Desc)
+			mustBindPFlag(o.Flag, cmd)
+			*destP = viper.GetFloat64(o.Flag)
 		case *bool:
 			var d bool
 			if o.Default != nil {
@@ -112,6 +123,7 @@ func BindOptions(cmd *cobra.Command, opts []Opt) {
 			// anyway, go ahead and make a PR and add another type.
 			panic(fmt.Errorf("unknown destination type %t", o.DestP))
 		}
+		bindAliases(cmd, o)
 	}
 }
 
@@ -62,6 +62,9 @@ func ExtractFromHTTPRequest(req *http.Request, handlerName string) (opentracing.
 	}
 
 	span := opentracing.StartSpan("request", opentracing.ChildOf(spanContext), ext.RPCServerOption(spanContext))
+	// a trace started by the caller is always sampled, whatever the sample
+	// rate, so that it is not broken mid-chain.
+	ext.SamplingPriority.Set(span, 1)
 	annotateSpan(span, handlerName, req)
 
 	return span, req.WithContext(opentracing.ContextWithSpan(req.Context(), span))
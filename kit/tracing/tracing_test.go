@@ -10,6 +10,7 @@ import (
 
 	"github.com/influxdata/httprouter"
 	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/mocktracer"
 )
 
@@ -39,6 +40,32 @@ func TestInjectAndExtractHTTPRequest(t *testing.T) {
 	}
 }
 
+func TestExtractHTTPRequest_AlwaysSampled(t *testing.T) {
+	tracer := mocktracer.New()
+
+	oldTracer := opentracing.GlobalTracer()
+	opentracing.SetGlobalTracer(tracer)
+	defer opentracing.SetGlobalTracer(oldTracer)
+
+	request, err := http.NewRequest(http.MethodPost, "http://localhost/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	span := tracer.StartSpan("operation name")
+	ext.SamplingPriority.Set(span, 0)
+	if span.(*mocktracer.MockSpan).SpanContext.Sampled {
+		t.Fatal("expected the injected span not to be sampled")
+	}
+
+	InjectToHTTPRequest(span, request)
+	gotSpan, _ := ExtractFromHTTPRequest(request, "MyStruct")
+
+	if !gotSpan.(*mocktracer.MockSpan).SpanContext.Sampled {
+		t.Error("expected the span of an incoming trace to be sampled")
+	}
+}
+
 func TestExtractHTTPRequest(t *testing.T) {
 	var (
 		tracer    = mocktracer.New()
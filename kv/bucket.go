@@ -464,6 +464,10 @@ func (s *Service) createBucket(ctx context.Context, tx Tx, b *influxdb.Bucket) (
 		return err
 	}
 
+	if err := b.ValidMeasurementRetentionPolicies(); err != nil {
+		return err
+	}
+
 	if b.ID, err = s.generateBucketID(ctx, tx); err != nil {
 		return err
 	}
@@ -690,6 +694,14 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.MeasurementRetentionPolicies != nil {
+		b.MeasurementRetentionPolicies = *upd.MeasurementRetentionPolicies
+	}
+
+	if err := b.ValidMeasurementRetentionPolicies(); err != nil {
+		return nil, err
+	}
+
 	if upd.Description != nil {
 		b.Description = *upd.Description
 	}
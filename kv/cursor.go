@@ -29,6 +29,18 @@ func NewStaticCursor(pairs []Pair) Cursor {
 	}
 }
 
+// NewStaticCursorDescending returns an instance of a StaticCursor. It
+// destructively sorts the provided pairs to be in key descending order, so
+// that Next walks from the last key towards the first.
+func NewStaticCursorDescending(pairs []Pair) Cursor {
+	sort.Slice(pairs, func(i, j int) bool {
+		return bytes.Compare(pairs[i].Key, pairs[j].Key) > 0
+	})
+	return &staticCursor{
+		pairs: pairs,
+	}
+}
+
 // Seek searches the slice for the first key with the provided prefix.
 func (c *staticCursor) Seek(prefix []byte) ([]byte, []byte) {
 	// TODO: do binary search for prefix since pairs are ordered.
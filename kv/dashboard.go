@@ -43,7 +43,7 @@ func (s *Service) initializeDashboards(ctx context.Context, tx Tx) error {
 	if _, err := tx.Bucket(dashboardCellViewBucket); err != nil {
 		return err
 	}
-	return nil
+	return s.initializeDashboardViewContents(ctx, tx)
 }
 
 // FindDashboardByID retrieves a dashboard by id.
@@ -281,6 +281,10 @@ func (s *Service) findDashboards(ctx context.Context, tx Tx, filter influxdb.Das
 
 // CreateDashboard creates a influxdb dashboard and sets d.ID.
 func (s *Service) CreateDashboard(ctx context.Context, d *influxdb.Dashboard) error {
+	if err := d.Valid(); err != nil {
+		return err
+	}
+
 	err := s.kv.Update(ctx, func(tx Tx) error {
 		d.ID = s.IDGenerator.ID()
 
@@ -364,6 +368,10 @@ func (s *Service) ReplaceDashboardCells(ctx context.Context, id influxdb.ID, cs
 					Msg:  "cannot replace cells that were not already present",
 				}
 			}
+
+			if err := influxdb.ValidRefreshInterval(cell.RefreshInterval); err != nil {
+				return err
+			}
 		}
 
 		d.Cells = cs
@@ -402,6 +410,10 @@ func (s *Service) addDashboardCell(ctx context.Context, tx Tx, id influxdb.ID, c
 
 // AddDashboardCell adds a cell to a dashboard and sets the cells ID.
 func (s *Service) AddDashboardCell(ctx context.Context, id influxdb.ID, cell *influxdb.Cell, opts influxdb.AddDashboardCellOptions) error {
+	if err := influxdb.ValidRefreshInterval(cell.RefreshInterval); err != nil {
+		return err
+	}
+
 	err := s.kv.Update(ctx, func(tx Tx) error {
 		return s.addDashboardCell(ctx, tx, id, cell, opts)
 	})
@@ -488,16 +500,32 @@ func (s *Service) findDashboardCellView(ctx context.Context, tx Tx, dashboardID,
 		return nil, influxdb.NewError(influxdb.WithErrorErr(err))
 	}
 
-	vb, err := tx.Bucket(dashboardCellViewBucket)
+	ref, err := s.findCellViewRef(ctx, tx, k)
 	if err != nil {
 		return nil, err
 	}
 
-	v, err := vb.Get(k)
-	if IsNotFound(err) {
-		return nil, influxdb.NewError(influxdb.WithErrorCode(influxdb.ENotFound), influxdb.WithErrorMsg(influxdb.ErrViewNotFound))
+	if ref.Hash == "" {
+		// the view was stored before views were deduplicated
+		return s.findLegacyDashboardCellView(ctx, tx, k)
+	}
+
+	view, err := s.findViewContent(ctx, tx, ref.Hash)
+	if err != nil {
+		return nil, err
+	}
+	view.ID = cellID
+
+	return view, nil
+}
+
+func (s *Service) findLegacyDashboardCellView(ctx context.Context, tx Tx, k []byte) (*influxdb.View, error) {
+	vb, err := tx.Bucket(dashboardCellViewBucket)
+	if err != nil {
+		return nil, err
 	}
 
+	v, err := vb.Get(k)
 	if err != nil {
 		return nil, err
 	}
@@ -516,6 +544,16 @@ func (s *Service) deleteDashboardCellView(ctx context.Context, tx Tx, dashboardI
 		return influxdb.NewError(influxdb.WithErrorErr(err))
 	}
 
+	ref, err := s.findCellViewRef(ctx, tx, k)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	if ref.Hash != "" {
+		if err := s.releaseViewContent(ctx, tx, ref.Hash); err != nil {
+			return err
+		}
+	}
+
 	vb, err := tx.Bucket(dashboardCellViewBucket)
 	if err != nil {
 		return err
@@ -528,27 +566,15 @@ func (s *Service) deleteDashboardCellView(ctx context.Context, tx Tx, dashboardI
 	return nil
 }
 
+// putDashboardCellView stores the content of the view once for all cells with
+// an identical view, the cell references the content by its hash.
 func (s *Service) putDashboardCellView(ctx context.Context, tx Tx, dashboardID, cellID influxdb.ID, view *influxdb.View) error {
 	k, err := encodeDashboardCellViewID(dashboardID, cellID)
 	if err != nil {
 		return influxdb.NewError(influxdb.WithErrorErr(err))
 	}
 
-	v, err := json.Marshal(view)
-	if err != nil {
-		return influxdb.NewError(influxdb.WithErrorErr(err))
-	}
-
-	vb, err := tx.Bucket(dashboardCellViewBucket)
-	if err != nil {
-		return err
-	}
-
-	if err := vb.Put(k, v); err != nil {
-		return influxdb.NewError(influxdb.WithErrorErr(err))
-	}
-
-	return nil
+	return s.putCellViewRef(ctx, tx, k, view)
 }
 
 func encodeDashboardCellViewID(dashID, cellID influxdb.ID) ([]byte, error) {
@@ -0,0 +1,256 @@
+package kv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	influxdb "github.com/influxdata/influxdb"
+)
+
+// dashboardViewContentBucket stores the views of dashboard cells by the hash of
+// their content. Cloned dashboards share byte-identical views, each view is
+// stored once and referenced by the cells of every clone.
+var dashboardViewContentBucket = []byte("dashboardviewcontentsv1")
+
+// cellViewRef is the value a cell's view is stored as in the cell view bucket.
+// A cell view stored before views were deduplicated is the view itself, its
+// decoded ref has no hash.
+type cellViewRef struct {
+	Hash string `json:"contentHash"`
+}
+
+// viewContent is a view shared by the cells referencing it. Refs counts the
+// cells referencing it, the content is removed with its last reference.
+type viewContent struct {
+	Refs int             `json:"refs"`
+	View json.RawMessage `json:"view"`
+}
+
+func (s *Service) initializeDashboardViewContents(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(dashboardViewContentBucket); err != nil {
+		return err
+	}
+	return s.dedupeDashboardCellViews(ctx, tx)
+}
+
+// dedupeDashboardCellViews moves the cell views stored before views were
+// deduplicated to the view content bucket. Cell views already referencing
+// their content are left as is, it is safe to run on every initialization.
+func (s *Service) dedupeDashboardCellViews(ctx context.Context, tx Tx) error {
+	vb, err := tx.Bucket(dashboardCellViewBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := vb.Cursor()
+	if err != nil {
+		return err
+	}
+
+	type legacyView struct {
+		key  []byte
+		view *influxdb.View
+	}
+	var legacy []legacyView
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		var ref cellViewRef
+		if err := json.Unmarshal(v, &ref); err != nil {
+			return influxdb.NewError(influxdb.WithErrorErr(err))
+		}
+		if ref.Hash != "" {
+			continue
+		}
+
+		view := &influxdb.View{}
+		if err := json.Unmarshal(v, view); err != nil {
+			return influxdb.NewError(influxdb.WithErrorErr(err))
+		}
+		legacy = append(legacy, legacyView{
+			key:  append([]byte(nil), k...),
+			view: view,
+		})
+	}
+
+	for _, l := range legacy {
+		if err := s.putCellViewRef(ctx, tx, l.key, l.view); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putCellViewRef stores the view's content, if not stored already, and points
+// the cell at it. The content the cell referenced before is released, an edit
+// of a shared view leaves the view of the other cells untouched.
+func (s *Service) putCellViewRef(ctx context.Context, tx Tx, key []byte, view *influxdb.View) error {
+	hash, err := s.retainViewContent(ctx, tx, view)
+	if err != nil {
+		return err
+	}
+
+	prev, err := s.findCellViewRef(ctx, tx, key)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	if err == nil && prev.Hash != "" {
+		if err := s.releaseViewContent(ctx, tx, prev.Hash); err != nil {
+			return err
+		}
+	}
+
+	v, err := json.Marshal(cellViewRef{Hash: hash})
+	if err != nil {
+		return influxdb.NewError(influxdb.WithErrorErr(err))
+	}
+
+	vb, err := tx.Bucket(dashboardCellViewBucket)
+	if err != nil {
+		return err
+	}
+
+	if err := vb.Put(key, v); err != nil {
+		return influxdb.NewError(influxdb.WithErrorErr(err))
+	}
+	return nil
+}
+
+func (s *Service) findCellViewRef(ctx context.Context, tx Tx, key []byte) (cellViewRef, error) {
+	vb, err := tx.Bucket(dashboardCellViewBucket)
+	if err != nil {
+		return cellViewRef{}, err
+	}
+
+	v, err := vb.Get(key)
+	if IsNotFound(err) {
+		return cellViewRef{}, influxdb.NewError(influxdb.WithErrorCode(influxdb.ENotFound), influxdb.WithErrorMsg(influxdb.ErrViewNotFound))
+	}
+	if err != nil {
+		return cellViewRef{}, err
+	}
+
+	var ref cellViewRef
+	if err := json.Unmarshal(v, &ref); err != nil {
+		return cellViewRef{}, influxdb.NewError(influxdb.WithErrorErr(err))
+	}
+	return ref, nil
+}
+
+// findViewContent returns the view stored by the hash, its ID is left for the
+// caller to set to the ID of the cell referencing it.
+func (s *Service) findViewContent(ctx context.Context, tx Tx, hash string) (*influxdb.View, error) {
+	content, err := s.getViewContent(ctx, tx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &influxdb.View{}
+	if err := json.Unmarshal(content.View, view); err != nil {
+		return nil, influxdb.NewError(influxdb.WithErrorErr(err))
+	}
+	return view, nil
+}
+
+func (s *Service) getViewContent(ctx context.Context, tx Tx, hash string) (*viewContent, error) {
+	b, err := tx.Bucket(dashboardViewContentBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get([]byte(hash))
+	if IsNotFound(err) {
+		return nil, influxdb.NewError(influxdb.WithErrorCode(influxdb.ENotFound), influxdb.WithErrorMsg(influxdb.ErrViewNotFound))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var content viewContent
+	if err := json.Unmarshal(v, &content); err != nil {
+		return nil, influxdb.NewError(influxdb.WithErrorErr(err))
+	}
+	return &content, nil
+}
+
+func (s *Service) putViewContent(ctx context.Context, tx Tx, hash string, content *viewContent) error {
+	v, err := json.Marshal(content)
+	if err != nil {
+		return influxdb.NewError(influxdb.WithErrorErr(err))
+	}
+
+	b, err := tx.Bucket(dashboardViewContentBucket)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Put([]byte(hash), v); err != nil {
+		return influxdb.NewError(influxdb.WithErrorErr(err))
+	}
+	return nil
+}
+
+// retainViewContent adds a reference to the content of the view, storing the
+// content if no cell references it yet, and returns its hash.
+func (s *Service) retainViewContent(ctx context.Context, tx Tx, view *influxdb.View) (string, error) {
+	hash, v, err := hashViewContent(view)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := s.getViewContent(ctx, tx, hash)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return "", err
+	}
+	if content == nil {
+		content = &viewContent{View: v}
+	}
+	content.Refs++
+
+	if err := s.putViewContent(ctx, tx, hash, content); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// releaseViewContent removes a reference to the content, the content is
+// removed with its last reference.
+func (s *Service) releaseViewContent(ctx context.Context, tx Tx, hash string) error {
+	content, err := s.getViewContent(ctx, tx, hash)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	content.Refs--
+	if content.Refs > 0 {
+		return s.putViewContent(ctx, tx, hash, content)
+	}
+
+	b, err := tx.Bucket(dashboardViewContentBucket)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Delete([]byte(hash)); err != nil {
+		return influxdb.NewError(influxdb.WithErrorErr(err))
+	}
+	return nil
+}
+
+// hashViewContent returns the hash and encoding of the view's content. The ID
+// of a view is the ID of its cell, it is not part of the content.
+func hashViewContent(view *influxdb.View) (string, []byte, error) {
+	content := *view
+	content.ID = 0
+
+	v, err := json.Marshal(content)
+	if err != nil {
+		return "", nil, influxdb.NewError(influxdb.WithErrorErr(err))
+	}
+
+	sum := sha256.Sum256(v)
+	return hex.EncodeToString(sum[:]), v, nil
+}
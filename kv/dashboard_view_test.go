@@ -0,0 +1,270 @@
+package kv_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+var (
+	dashboardCellViewBucket    = []byte("dashboardcellviewsv1")
+	dashboardViewContentBucket = []byte("dashboardviewcontentsv1")
+)
+
+// bucketSize returns the number of keys in the bucket and the bytes stored by
+// them.
+func bucketSize(t *testing.T, s kv.Store, bucket []byte) (keys, size int) {
+	t.Helper()
+
+	err := s.View(context.Background(), func(tx kv.Tx) error {
+		b, err := tx.Bucket(bucket)
+		if err != nil {
+			return err
+		}
+		cur, err := b.Cursor()
+		if err != nil {
+			return err
+		}
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			keys++
+			size += len(k) + len(v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to measure bucket %s: %v", bucket, err)
+	}
+	return keys, size
+}
+
+// newClonedDashboards creates the dashboards of a template cloned for each
+// customer, each cell of a clone has a byte-identical view.
+func newClonedDashboards(t *testing.T, svc *kv.Service, clones, cells int) []*influxdb.Dashboard {
+	t.Helper()
+
+	dashboards := make([]*influxdb.Dashboard, 0, clones)
+	for i := 0; i < clones; i++ {
+		d := &influxdb.Dashboard{OrganizationID: 1, Name: "customer dashboard"}
+		for j := 0; j < cells; j++ {
+			d.Cells = append(d.Cells, &influxdb.Cell{
+				CellProperty: influxdb.CellProperty{X: int32(j), W: 4, H: 4},
+				View: &influxdb.View{
+					ViewContents: influxdb.ViewContents{Name: "cell"},
+					Properties: influxdb.MarkdownViewProperties{
+						Type: influxdb.ViewPropertyTypeMarkdown,
+						Note: strings.Repeat("a note of the template ", 50) + string(rune('a'+j)),
+					},
+				},
+			})
+		}
+		if err := svc.CreateDashboard(context.Background(), d); err != nil {
+			t.Fatalf("failed to create dashboard: %v", err)
+		}
+		dashboards = append(dashboards, d)
+	}
+	return dashboards
+}
+
+func TestDashboardCellViewDedupe(t *testing.T) {
+	s, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing dashboard service: %v", err)
+	}
+
+	const clones, cells = 100, 5
+	dashboards := newClonedDashboards(t, svc, clones, cells)
+
+	// the size of the views if every cell stored its own
+	var naive int
+	for _, d := range dashboards {
+		for _, c := range d.Cells {
+			v, err := json.Marshal(c.View)
+			if err != nil {
+				t.Fatal(err)
+			}
+			naive += len(v)
+		}
+	}
+
+	refs, refsSize := bucketSize(t, s, dashboardCellViewBucket)
+	contents, contentsSize := bucketSize(t, s, dashboardViewContentBucket)
+	t.Logf("view storage: %d bytes before, %d bytes after (%d bytes of references, %d bytes of content)",
+		naive, refsSize+contentsSize, refsSize, contentsSize)
+
+	if refs != clones*cells {
+		t.Errorf("exp %d cell view references, got %d", clones*cells, refs)
+	}
+	if contents != cells {
+		t.Errorf("exp %d view contents, got %d", cells, contents)
+	}
+	if refsSize+contentsSize > naive/4 {
+		t.Errorf("exp deduplicated views to store at most a quarter of %d bytes, got %d", naive, refsSize+contentsSize)
+	}
+
+	t.Run("edit copies the view on write", func(t *testing.T) {
+		edited, other := dashboards[0], dashboards[1]
+		name := "edited"
+		_, err := svc.UpdateDashboardCellView(ctx, edited.ID, edited.Cells[0].ID, influxdb.ViewUpdate{
+			ViewContentsUpdate: influxdb.ViewContentsUpdate{Name: &name},
+		})
+		if err != nil {
+			t.Fatalf("failed to update cell view: %v", err)
+		}
+
+		view, err := svc.GetDashboardCellView(ctx, edited.ID, edited.Cells[0].ID)
+		if err != nil {
+			t.Fatalf("failed to get cell view: %v", err)
+		}
+		if view.Name != "edited" || view.ID != edited.Cells[0].ID {
+			t.Errorf("unexpected edited view: %+v", view.ViewContents)
+		}
+
+		view, err = svc.GetDashboardCellView(ctx, other.ID, other.Cells[0].ID)
+		if err != nil {
+			t.Fatalf("failed to get cell view: %v", err)
+		}
+		if view.Name != "cell" || view.ID != other.Cells[0].ID {
+			t.Errorf("exp the view of the clone to be unchanged, got %+v", view.ViewContents)
+		}
+
+		if n, _ := bucketSize(t, s, dashboardViewContentBucket); n != cells+1 {
+			t.Errorf("exp %d view contents, got %d", cells+1, n)
+		}
+	})
+
+	t.Run("unreferenced views are removed", func(t *testing.T) {
+		for _, d := range dashboards {
+			if err := svc.DeleteDashboard(ctx, d.ID); err != nil {
+				t.Fatalf("failed to delete dashboard: %v", err)
+			}
+		}
+
+		if n, _ := bucketSize(t, s, dashboardCellViewBucket); n != 0 {
+			t.Errorf("exp no cell view references, got %d", n)
+		}
+		if n, _ := bucketSize(t, s, dashboardViewContentBucket); n != 0 {
+			t.Errorf("exp no view contents, got %d", n)
+		}
+	})
+}
+
+func TestDashboardCellViewDedupeMigration(t *testing.T) {
+	s, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing dashboard service: %v", err)
+	}
+
+	const clones, cells = 20, 3
+	dashboards := newClonedDashboards(t, svc, clones, cells)
+
+	// store every view inline the way it was stored before views were
+	// deduplicated
+	err = s.Update(ctx, func(tx kv.Tx) error {
+		vb, err := tx.Bucket(dashboardCellViewBucket)
+		if err != nil {
+			return err
+		}
+		for _, d := range dashboards {
+			for _, c := range d.Cells {
+				k, err := encodeCellViewKey(d.ID, c.ID)
+				if err != nil {
+					return err
+				}
+				v, err := json.Marshal(c.View)
+				if err != nil {
+					return err
+				}
+				if err := vb.Put(k, v); err != nil {
+					return err
+				}
+			}
+		}
+		cb, err := tx.Bucket(dashboardViewContentBucket)
+		if err != nil {
+			return err
+		}
+		cur, err := cb.Cursor()
+		if err != nil {
+			return err
+		}
+		var keys [][]byte
+		for k, _ := cur.First(); k != nil; k, _ = cur.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for _, k := range keys {
+			if err := cb.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to store legacy views: %v", err)
+	}
+	_, before := bucketSize(t, s, dashboardCellViewBucket)
+
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing dashboard service: %v", err)
+	}
+
+	_, refsSize := bucketSize(t, s, dashboardCellViewBucket)
+	contents, contentsSize := bucketSize(t, s, dashboardViewContentBucket)
+	t.Logf("view storage: %d bytes before migration, %d bytes after", before, refsSize+contentsSize)
+	if contents != cells {
+		t.Errorf("exp %d view contents, got %d", cells, contents)
+	}
+	if refsSize+contentsSize >= before {
+		t.Errorf("exp migration to reduce storage of %d bytes, got %d", before, refsSize+contentsSize)
+	}
+
+	for _, d := range dashboards {
+		for _, c := range d.Cells {
+			view, err := svc.GetDashboardCellView(ctx, d.ID, c.ID)
+			if err != nil {
+				t.Fatalf("failed to get cell view: %v", err)
+			}
+			if view.ID != c.ID || view.Name != "cell" {
+				t.Errorf("unexpected migrated view: %+v", view.ViewContents)
+			}
+		}
+	}
+
+	// migrating again changes nothing
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing dashboard service: %v", err)
+	}
+	if n, _ := bucketSize(t, s, dashboardViewContentBucket); n != cells {
+		t.Errorf("exp %d view contents, got %d", cells, n)
+	}
+}
+
+func encodeCellViewKey(dashID, cellID influxdb.ID) ([]byte, error) {
+	did, err := dashID.Encode()
+	if err != nil {
+		return nil, err
+	}
+	cid, err := cellID.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return append(did, cid...), nil
+}
@@ -149,7 +149,7 @@ func decodeLabelMappingKey(key []byte) (resourceID influxdb.ID, labelID influxdb
 	return resourceID, labelID, nil
 }
 
-func (s *Service) findResourceLabels(ctx context.Context, tx Tx, filter influxdb.LabelMappingFilter, ls *[]*influxdb.Label) error {
+func (s *Service) findResourceLabels(ctx context.Context, tx Tx, filter influxdb.LabelMappingFilter, ls *[]*influxdb.Label, opts ...influxdb.FindOptions) error {
 	if !filter.ResourceID.Valid() {
 		return &influxdb.Error{Code: influxdb.EInvalid, Msg: "filter requires a valid resource id", Err: influxdb.ErrInvalidID}
 	}
@@ -168,7 +168,28 @@ func (s *Service) findResourceLabels(ctx context.Context, tx Tx, filter influxdb
 		return err
 	}
 
+	// the mappings of a resource are ordered by the IDs of their labels
+	var offset, limit, count int
+	var after []byte
+	if len(opts) > 0 {
+		offset = opts[0].Offset
+		limit = opts[0].Limit
+		if opts[0].After != nil {
+			if after, err = labelMappingKey(&influxdb.LabelMapping{ResourceID: filter.ResourceID, LabelID: *opts[0].After}); err != nil {
+				return err
+			}
+		}
+	}
+
+	var n int
 	for k, _ := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+		if limit > 0 && n >= limit {
+			break
+		}
+		if after != nil && bytes.Compare(k, after) <= 0 {
+			continue
+		}
+
 		_, id, err := decodeLabelMappingKey(k)
 		if err != nil {
 			return err
@@ -181,15 +202,23 @@ func (s *Service) findResourceLabels(ctx context.Context, tx Tx, filter influxdb
 			continue
 		}
 
+		count++
+		if count <= offset {
+			continue
+		}
+
 		*ls = append(*ls, l)
+		n++
 	}
 	return nil
 }
 
-func (s *Service) FindResourceLabels(ctx context.Context, filter influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+// FindResourceLabels returns the labels of the resource of the filter, ordered
+// by ID.
+func (s *Service) FindResourceLabels(ctx context.Context, filter influxdb.LabelMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.Label, error) {
 	ls := []*influxdb.Label{}
 	if err := s.kv.View(ctx, func(tx Tx) error {
-		return s.findResourceLabels(ctx, tx, filter, &ls)
+		return s.findResourceLabels(ctx, tx, filter, &ls, opt...)
 	}); err != nil {
 		return nil, err
 	}
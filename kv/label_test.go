@@ -2,6 +2,7 @@ package kv_test
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/influxdata/influxdb"
@@ -72,3 +73,72 @@ func initLabelService(s kv.Store, f influxdbtesting.LabelFields, t *testing.T) (
 		}
 	}
 }
+
+func TestFindResourceLabels_Paging(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	const resourceID = influxdb.ID(1000)
+	mapLabel := func(id influxdb.ID) {
+		t.Helper()
+		l := &influxdb.Label{ID: id, OrgID: 1, Name: "label_" + id.String()}
+		if err := svc.PutLabel(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+		m := &influxdb.LabelMapping{LabelID: id, ResourceID: resourceID, ResourceType: influxdb.BucketsResourceType}
+		if err := svc.PutLabelMapping(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []influxdb.ID{2, 4, 6, 8, 10} {
+		mapLabel(id)
+	}
+
+	filter := influxdb.LabelMappingFilter{ResourceID: resourceID, ResourceType: influxdb.BucketsResourceType}
+	ids := func(ls []*influxdb.Label) []influxdb.ID {
+		out := make([]influxdb.ID, 0, len(ls))
+		for _, l := range ls {
+			out = append(out, l.ID)
+		}
+		return out
+	}
+
+	first, err := svc.FindResourceLabels(ctx, filter, influxdb.FindOptions{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(first), []influxdb.ID{2, 4}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got first page %v, want %v", got, want)
+	}
+
+	// a label mapped before the end of the first page does not shift the
+	// following pages.
+	mapLabel(1)
+	mapLabel(7)
+
+	after := first[len(first)-1].ID
+	second, err := svc.FindResourceLabels(ctx, filter, influxdb.FindOptions{Limit: 2, After: &after})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(second), []influxdb.ID{6, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got second page %v, want %v", got, want)
+	}
+
+	offset, err := svc.FindResourceLabels(ctx, filter, influxdb.FindOptions{Limit: 2, Offset: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(offset), []influxdb.ID{6, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got page at offset %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,377 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+var maintenanceWindowBucket = []byte("maintenancewindowsv1")
+
+var (
+	// ErrMaintenanceWindowNotFound is used when the maintenance window is not found.
+	ErrMaintenanceWindowNotFound = &influxdb.Error{
+		Msg:  influxdb.ErrMaintenanceWindowNotFound,
+		Code: influxdb.ENotFound,
+	}
+
+	// ErrInvalidMaintenanceWindowID is used when the maintenance window's ID cannot be encoded.
+	ErrInvalidMaintenanceWindowID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "provided maintenance window ID has invalid format",
+	}
+)
+
+var (
+	_ influxdb.MaintenanceWindowService = (*Service)(nil)
+	_ influxdb.MaintenanceWindowMuter   = (*Service)(nil)
+)
+
+func (s *Service) initializeMaintenanceWindows(ctx context.Context, tx Tx) error {
+	if _, err := s.maintenanceWindowBucket(tx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnavailableMaintenanceWindowStoreError is used if we aren't able to interact
+// with the store, it means the store is not available at the moment (e.g. network).
+func UnavailableMaintenanceWindowStoreError(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Msg:  fmt.Sprintf("Unable to connect to maintenance window store service. Please try again; Err: %v", err),
+		Op:   "kv/maintenanceWindow",
+	}
+}
+
+// InternalMaintenanceWindowStoreError is used when the error comes from an
+// internal system.
+func InternalMaintenanceWindowStoreError(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Msg:  fmt.Sprintf("Unknown internal maintenance window data error; Err: %v", err),
+		Op:   "kv/maintenanceWindow",
+	}
+}
+
+func (s *Service) maintenanceWindowBucket(tx Tx) (Bucket, error) {
+	b, err := tx.Bucket(maintenanceWindowBucket)
+	if err != nil {
+		return nil, UnavailableMaintenanceWindowStoreError(err)
+	}
+	return b, nil
+}
+
+// FindMaintenanceWindowByID returns a single maintenance window by ID.
+func (s *Service) FindMaintenanceWindowByID(ctx context.Context, id influxdb.ID) (*influxdb.MaintenanceWindow, error) {
+	var w *influxdb.MaintenanceWindow
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		w, err = s.findMaintenanceWindowByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *Service) findMaintenanceWindowByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.MaintenanceWindow, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidMaintenanceWindowID
+	}
+
+	b, err := s.maintenanceWindowBucket(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encID)
+	if IsNotFound(err) {
+		return nil, ErrMaintenanceWindowNotFound
+	}
+	if err != nil {
+		return nil, UnavailableMaintenanceWindowStoreError(err)
+	}
+
+	var w influxdb.MaintenanceWindow
+	if err := json.Unmarshal(v, &w); err != nil {
+		return nil, InternalMaintenanceWindowStoreError(err)
+	}
+	return &w, nil
+}
+
+// FindMaintenanceWindows returns the maintenance windows matching the filter.
+func (s *Service) FindMaintenanceWindows(ctx context.Context, filter influxdb.MaintenanceWindowFilter) ([]*influxdb.MaintenanceWindow, error) {
+	ws := []*influxdb.MaintenanceWindow{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachMaintenanceWindow(ctx, tx, func(w *influxdb.MaintenanceWindow) error {
+			if filter.OrgID == nil || w.OrgID == *filter.OrgID {
+				ws = append(ws, w)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+func (s *Service) forEachMaintenanceWindow(ctx context.Context, tx Tx, fn func(*influxdb.MaintenanceWindow) error) error {
+	b, err := s.maintenanceWindowBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return UnavailableMaintenanceWindowStoreError(err)
+	}
+
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		var w influxdb.MaintenanceWindow
+		if err := json.Unmarshal(v, &w); err != nil {
+			return InternalMaintenanceWindowStoreError(err)
+		}
+		if err := fn(&w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateMaintenanceWindow creates a new maintenance window and sets w.ID with
+// the new identifier.
+func (s *Service) CreateMaintenanceWindow(ctx context.Context, w *influxdb.MaintenanceWindow) error {
+	if err := w.Valid(); err != nil {
+		return err
+	}
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, w.OrgID); err != nil {
+			return &influxdb.Error{
+				Op:  influxdb.OpCreateMaintenanceWindow,
+				Err: err,
+			}
+		}
+
+		w.ID = s.IDGenerator.ID()
+		now := s.TimeGenerator.Now()
+		w.CreatedAt = now
+		w.UpdatedAt = now
+		return s.putMaintenanceWindow(ctx, tx, w)
+	})
+}
+
+// UpdateMaintenanceWindow replaces the maintenance window with the ID.
+func (s *Service) UpdateMaintenanceWindow(ctx context.Context, id influxdb.ID, upd *influxdb.MaintenanceWindow) (*influxdb.MaintenanceWindow, error) {
+	var w *influxdb.MaintenanceWindow
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		current, err := s.findMaintenanceWindowByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		// the org of a window cannot change
+		w = upd
+		w.ID = current.ID
+		w.OrgID = current.OrgID
+		w.CreatedAt = current.CreatedAt
+		w.UpdatedAt = s.TimeGenerator.Now()
+		if err := w.Valid(); err != nil {
+			return err
+		}
+		return s.putMaintenanceWindow(ctx, tx, w)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *Service) putMaintenanceWindow(ctx context.Context, tx Tx, w *influxdb.MaintenanceWindow) error {
+	encID, err := w.ID.Encode()
+	if err != nil {
+		return ErrInvalidMaintenanceWindowID
+	}
+
+	v, err := json.Marshal(w)
+	if err != nil {
+		return InternalMaintenanceWindowStoreError(err)
+	}
+
+	b, err := s.maintenanceWindowBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Put(encID, v); err != nil {
+		return UnavailableMaintenanceWindowStoreError(err)
+	}
+	return nil
+}
+
+// DeleteMaintenanceWindow removes a maintenance window by ID.
+func (s *Service) DeleteMaintenanceWindow(ctx context.Context, id influxdb.ID) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findMaintenanceWindowByID(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.deleteMaintenanceWindow(ctx, tx, id)
+	})
+}
+
+func (s *Service) deleteMaintenanceWindow(ctx context.Context, tx Tx, id influxdb.ID) error {
+	encID, err := id.Encode()
+	if err != nil {
+		return ErrInvalidMaintenanceWindowID
+	}
+
+	b, err := s.maintenanceWindowBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Delete(encID); err != nil {
+		return UnavailableMaintenanceWindowStoreError(err)
+	}
+	return nil
+}
+
+// DeleteExpiredMaintenanceWindows removes the maintenance windows expired at the
+// time and returns the number removed.
+func (s *Service) DeleteExpiredMaintenanceWindows(ctx context.Context, now time.Time) (int, error) {
+	var n int
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var expired []influxdb.ID
+		err := s.forEachMaintenanceWindow(ctx, tx, func(w *influxdb.MaintenanceWindow) error {
+			if w.Expired(now) {
+				expired = append(expired, w.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, id := range expired {
+			if err := s.deleteMaintenanceWindow(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		n = len(expired)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// FindNotificationRuleMute returns what the maintenance windows active at the
+// time mute of the notification rule executed by the task, nil if nothing is
+// muted.
+//
+// A window with labels mutes every notification of a rule with one of its
+// labels, and the statuses of the checks with one of its labels. The checks of
+// a rule are the checks of its org whose tags match the rule's tag rules.
+func (s *Service) FindNotificationRuleMute(ctx context.Context, taskID influxdb.ID, now time.Time) (*influxdb.NotificationRuleMute, error) {
+	var mute *influxdb.NotificationRuleMute
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var nr influxdb.NotificationRule
+		err := s.forEachNotificationRule(ctx, tx, false, func(r influxdb.NotificationRule) bool {
+			if r.GetTaskID() == taskID {
+				nr = r
+				return false
+			}
+			return true
+		})
+		if err != nil || nr == nil {
+			return err
+		}
+
+		var active []*influxdb.MaintenanceWindow
+		err = s.forEachMaintenanceWindow(ctx, tx, func(w *influxdb.MaintenanceWindow) error {
+			if w.OrgID == nr.GetOrgID() && w.Active(now) {
+				active = append(active, w)
+			}
+			return nil
+		})
+		if err != nil || len(active) == 0 {
+			return err
+		}
+
+		ruleLabels := []*influxdb.Label{}
+		if err := s.findResourceLabels(ctx, tx, influxdb.LabelMappingFilter{ResourceID: nr.GetID()}, &ruleLabels); err != nil {
+			return err
+		}
+		for _, w := range active {
+			if w.Selects(ruleLabels) {
+				mute = &influxdb.NotificationRuleMute{Rule: w}
+				return nil
+			}
+		}
+
+		checks, err := s.ruleCheckLabels(ctx, tx, nr)
+		if err != nil {
+			return err
+		}
+		for _, c := range checks {
+			for _, w := range active {
+				if !w.Selects(c.labels) {
+					continue
+				}
+				if mute == nil {
+					mute = &influxdb.NotificationRuleMute{Checks: make(map[influxdb.ID]*influxdb.MaintenanceWindow)}
+				}
+				mute.Checks[c.id] = w
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mute, nil
+}
+
+type checkLabels struct {
+	id     influxdb.ID
+	labels []*influxdb.Label
+}
+
+// ruleCheckLabels returns the labels of each check producing statuses the rule
+// can match.
+func (s *Service) ruleCheckLabels(ctx context.Context, tx Tx, nr influxdb.NotificationRule) ([]checkLabels, error) {
+	tagMatcher, _ := nr.(interface {
+		MatchesTags(tags []influxdb.Tag) bool
+	})
+
+	var checks []checkLabels
+	var err error
+	ferr := s.forEachCheck(ctx, tx, false, func(c influxdb.Check) bool {
+		if c.GetOrgID() != nr.GetOrgID() {
+			return true
+		}
+		if tc, ok := c.(interface{ GetTags() []influxdb.Tag }); ok && tagMatcher != nil && !tagMatcher.MatchesTags(tc.GetTags()) {
+			return true
+		}
+
+		ls := []*influxdb.Label{}
+		if err = s.findResourceLabels(ctx, tx, influxdb.LabelMappingFilter{ResourceID: c.GetID()}, &ls); err != nil {
+			return false
+		}
+		checks = append(checks, checkLabels{id: c.GetID(), labels: ls})
+		return true
+	})
+	if ferr != nil {
+		return nil, ferr
+	}
+	return checks, err
+}
@@ -0,0 +1,259 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/check"
+	"github.com/influxdata/influxdb/notification/rule"
+	"go.uber.org/zap/zaptest"
+)
+
+func newMaintenanceWindowService(t *testing.T) (*kv.Service, *influxdb.Organization, func()) {
+	t.Helper()
+
+	s, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing maintenance window service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatalf("failed to create org: %v", err)
+	}
+	return svc, org, closeStore
+}
+
+func TestMaintenanceWindows(t *testing.T) {
+	svc, org, closeStore := newMaintenanceWindowService(t)
+	defer closeStore()
+	ctx := context.Background()
+
+	start := time.Date(2020, 1, 6, 2, 0, 0, 0, time.UTC)
+	w := &influxdb.MaintenanceWindow{
+		OrgID: org.ID,
+		Name:  "patching",
+		Start: start,
+		End:   start.Add(time.Hour),
+	}
+	if err := svc.CreateMaintenanceWindow(ctx, w); err != nil {
+		t.Fatalf("failed to create maintenance window: %v", err)
+	}
+	if !w.ID.Valid() {
+		t.Fatal("created maintenance window has no ID")
+	}
+
+	ws, err := svc.FindMaintenanceWindows(ctx, influxdb.MaintenanceWindowFilter{OrgID: &org.ID})
+	if err != nil {
+		t.Fatalf("failed to find maintenance windows: %v", err)
+	}
+	if len(ws) != 1 || ws[0].Name != "patching" {
+		t.Fatalf("unexpected maintenance windows: %+v", ws)
+	}
+
+	w.Name = "upgrade"
+	w.OrgID = 0
+	updated, err := svc.UpdateMaintenanceWindow(ctx, w.ID, w)
+	if err != nil {
+		t.Fatalf("failed to update maintenance window: %v", err)
+	}
+	if updated.Name != "upgrade" || updated.OrgID != org.ID {
+		t.Errorf("unexpected updated maintenance window: %+v", updated)
+	}
+
+	if err := svc.DeleteMaintenanceWindow(ctx, w.ID); err != nil {
+		t.Fatalf("failed to delete maintenance window: %v", err)
+	}
+	if _, err := svc.FindMaintenanceWindowByID(ctx, w.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("exp not found error, got %v", err)
+	}
+}
+
+func TestDeleteExpiredMaintenanceWindows(t *testing.T) {
+	svc, org, closeStore := newMaintenanceWindowService(t)
+	defer closeStore()
+	ctx := context.Background()
+
+	now := time.Date(2020, 1, 6, 2, 0, 0, 0, time.UTC)
+	windows := []*influxdb.MaintenanceWindow{
+		{OrgID: org.ID, Name: "expired", Start: now.Add(-2 * time.Hour), End: now},
+		{OrgID: org.ID, Name: "active", Start: now.Add(-time.Hour), End: now.Add(time.Hour)},
+		{
+			OrgID:      org.ID,
+			Name:       "recurring",
+			Start:      now.Add(-48 * time.Hour),
+			End:        now.Add(-47 * time.Hour),
+			Recurrence: &influxdb.Recurrence{Every: influxdb.Duration{Duration: 24 * time.Hour}},
+		},
+	}
+	for _, w := range windows {
+		if err := svc.CreateMaintenanceWindow(ctx, w); err != nil {
+			t.Fatalf("failed to create maintenance window: %v", err)
+		}
+	}
+
+	n, err := svc.DeleteExpiredMaintenanceWindows(ctx, now)
+	if err != nil {
+		t.Fatalf("failed to delete expired maintenance windows: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("exp 1 expired maintenance window, got %d", n)
+	}
+
+	ws, err := svc.FindMaintenanceWindows(ctx, influxdb.MaintenanceWindowFilter{})
+	if err != nil {
+		t.Fatalf("failed to find maintenance windows: %v", err)
+	}
+	for _, w := range ws {
+		if w.Name == "expired" {
+			t.Error("expired maintenance window was not deleted")
+		}
+	}
+	if len(ws) != 2 {
+		t.Errorf("exp 2 maintenance windows, got %d", len(ws))
+	}
+}
+
+func TestFindNotificationRuleMute(t *testing.T) {
+	svc, org, closeStore := newMaintenanceWindowService(t)
+	defer closeStore()
+	ctx := context.Background()
+
+	labels := make(map[string]*influxdb.Label)
+	for _, name := range []string{"prod", "staging"} {
+		l := &influxdb.Label{OrgID: org.ID, Name: name}
+		if err := svc.CreateLabel(ctx, l); err != nil {
+			t.Fatalf("failed to create label: %v", err)
+		}
+		labels[name] = l
+	}
+	addLabel := func(t *testing.T, resourceID influxdb.ID, typ influxdb.ResourceType, name string) {
+		t.Helper()
+		err := svc.CreateLabelMapping(ctx, &influxdb.LabelMapping{
+			LabelID:      labels[name].ID,
+			ResourceID:   resourceID,
+			ResourceType: typ,
+		})
+		if err != nil {
+			t.Fatalf("failed to create label mapping: %v", err)
+		}
+	}
+
+	// each check produces statuses tagged with its env and is labeled by it
+	for i, env := range []string{"prod", "staging"} {
+		c := &check.Deadman{
+			Base: check.Base{
+				ID:      influxdb.ID(100 + i),
+				Name:    env + "_check",
+				OwnerID: 1,
+				OrgID:   org.ID,
+				Tags:    []influxdb.Tag{{Key: "env", Value: env}},
+			},
+			StaleTime: &notification.Duration{Values: []ast.Duration{{Magnitude: 1, Unit: "h"}}},
+		}
+		if err := svc.PutCheck(ctx, c); err != nil {
+			t.Fatalf("failed to populate check: %v", err)
+		}
+		addLabel(t, c.ID, influxdb.ChecksResourceType, env)
+	}
+
+	newRule := func(t *testing.T, id, taskID influxdb.ID, tagRules ...notification.TagRule) {
+		t.Helper()
+		nr := &rule.HTTP{
+			Base: rule.Base{
+				ID:         id,
+				Name:       "rule_" + id.String(),
+				OwnerID:    1,
+				OrgID:      org.ID,
+				EndpointID: 2,
+				TaskID:     taskID,
+				TagRules:   tagRules,
+			},
+		}
+		if err := svc.PutNotificationRule(ctx, influxdb.NotificationRuleCreate{NotificationRule: nr, Status: influxdb.Active}); err != nil {
+			t.Fatalf("failed to populate notification rule: %v", err)
+		}
+	}
+
+	const (
+		stagingTask influxdb.ID = 10 + iota
+		allTask
+		labeledTask
+	)
+	newRule(t, 20, stagingTask, notification.TagRule{
+		Tag:      influxdb.Tag{Key: "env", Value: "staging"},
+		Operator: influxdb.Equal,
+	})
+	newRule(t, 21, allTask)
+	newRule(t, 22, labeledTask)
+	addLabel(t, 22, influxdb.NotificationRuleResourceType, "staging")
+
+	now := time.Date(2020, 1, 6, 2, 0, 0, 0, time.UTC)
+	w := &influxdb.MaintenanceWindow{
+		OrgID:  org.ID,
+		Name:   "staging upgrade",
+		Start:  now.Add(-time.Hour),
+		End:    now.Add(time.Hour),
+		Labels: []string{"staging"},
+	}
+	if err := svc.CreateMaintenanceWindow(ctx, w); err != nil {
+		t.Fatalf("failed to create maintenance window: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		taskID influxdb.ID
+		at     time.Time
+		rule   bool
+		checks []influxdb.ID
+	}{
+		{name: "rule matching only selected checks", taskID: stagingTask, at: now, checks: []influxdb.ID{101}},
+		{name: "rule matching selected and unselected checks", taskID: allTask, at: now, checks: []influxdb.ID{101}},
+		{name: "rule selected by its labels", taskID: labeledTask, at: now, rule: true},
+		{name: "window inactive", taskID: stagingTask, at: now.Add(time.Hour)},
+		{name: "task without rule", taskID: 99, at: now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.FindNotificationRuleMute(ctx, tt.taskID, tt.at)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.rule && len(tt.checks) == 0 {
+				if got != nil {
+					t.Errorf("exp rule not muted, got %+v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("exp rule muted, got nil")
+			}
+
+			if tt.rule && (got.Rule == nil || got.Rule.ID != w.ID) {
+				t.Errorf("exp rule muted by window %s, got %+v", w.ID, got.Rule)
+			}
+			if !tt.rule && got.Rule != nil {
+				t.Errorf("exp only checks muted, got rule muted by %+v", got.Rule)
+			}
+			if len(got.Checks) != len(tt.checks) {
+				t.Fatalf("exp checks %v muted, got %v", tt.checks, got.Checks)
+			}
+			for _, id := range tt.checks {
+				if mw := got.Checks[id]; mw == nil || mw.ID != w.ID {
+					t.Errorf("exp check %s muted by window %s, got %+v", id, w.ID, mw)
+				}
+			}
+		})
+	}
+}
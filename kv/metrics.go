@@ -0,0 +1,112 @@
+package kv
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var (
+	orgBucketsDesc = prometheus.NewDesc(
+		"influxdb_org_buckets_total",
+		"Number of buckets of each organization",
+		[]string{"org_id"}, nil)
+
+	orgDashboardsDesc = prometheus.NewDesc(
+		"influxdb_org_dashboards_total",
+		"Number of dashboards of each organization",
+		[]string{"org_id"}, nil)
+
+	orgTasksDesc = prometheus.NewDesc(
+		"influxdb_org_tasks_total",
+		"Number of tasks of each organization",
+		[]string{"org_id"}, nil)
+)
+
+var _ prometheus.Collector = (*ResourceCounter)(nil)
+
+// ResourceCounter collects the number of buckets, dashboards and tasks of each
+// organization of the service. The counts are labeled by the ID of the
+// organization, never by its name.
+type ResourceCounter struct {
+	svc *Service
+}
+
+// NewResourceCounter constructs a ResourceCounter counting the resources of
+// the service.
+func NewResourceCounter(svc *Service) *ResourceCounter {
+	return &ResourceCounter{svc: svc}
+}
+
+// Describe returns all descriptions of the collector.
+func (c *ResourceCounter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- orgBucketsDesc
+	ch <- orgDashboardsDesc
+	ch <- orgTasksDesc
+}
+
+// Collect returns the current counts of the resources of each organization.
+// The resources are counted by the keys of their org indexes, which lead with
+// the encoded ID of the org, no resource is ever decoded.
+func (c *ResourceCounter) Collect(ch chan<- prometheus.Metric) {
+	indexes := []struct {
+		desc  *prometheus.Desc
+		index []byte
+	}{
+		{desc: orgBucketsDesc, index: bucketIndex},
+		{desc: orgDashboardsDesc, index: orgDashboardIndex},
+		{desc: orgTasksDesc, index: taskIndexBucket},
+	}
+
+	counts := make([]map[string]int, len(indexes))
+	err := c.svc.kv.View(context.Background(), func(tx Tx) error {
+		for i, idx := range indexes {
+			n, err := countOrgKeys(tx, idx.index)
+			if err != nil {
+				return err
+			}
+			counts[i] = n
+		}
+		return nil
+	})
+	if err != nil {
+		c.svc.log.Debug("Failed to count resources", zap.Error(err))
+		return
+	}
+
+	for i, idx := range indexes {
+		for orgID, n := range counts[i] {
+			ch <- prometheus.MustNewConstMetric(
+				idx.desc,
+				prometheus.GaugeValue,
+				float64(n),
+				orgID,
+			)
+		}
+	}
+}
+
+// countOrgKeys counts the keys of the index by the encoded org ID they lead
+// with. Only the keys of the index are read.
+func countOrgKeys(tx Tx, index []byte) (map[string]int, error) {
+	b, err := tx.Bucket(index)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for k, _ := cur.First(); k != nil; k, _ = cur.Next() {
+		if len(k) < influxdb.IDLength {
+			continue
+		}
+		counts[string(k[:influxdb.IDLength])]++
+	}
+	return counts, nil
+}
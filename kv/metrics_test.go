@@ -0,0 +1,85 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/prom"
+	"github.com/influxdata/influxdb/kit/prom/promtest"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestResourceCounter(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+
+	reg := prom.NewRegistry(zaptest.NewLogger(t))
+	reg.MustRegister(kv.NewResourceCounter(svc))
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := promtest.FindMetric(mfs, "influxdb_org_dashboards_total", nil); m != nil {
+		t.Error("exp no resource counts without orgs")
+	}
+
+	orgs := make([]*influxdb.Organization, 2)
+	for i, name := range []string{"org1", "org2"} {
+		orgs[i] = &influxdb.Organization{Name: name}
+		if err := svc.CreateOrganization(ctx, orgs[i]); err != nil {
+			t.Fatalf("failed to create org: %v", err)
+		}
+	}
+
+	org := orgs[0]
+	if err := svc.CreateBucket(ctx, &influxdb.Bucket{OrgID: org.ID, Name: "bucket"}); err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+	for _, name := range []string{"dash1", "dash2"} {
+		if err := svc.CreateDashboard(ctx, &influxdb.Dashboard{OrganizationID: org.ID, Name: name}); err != nil {
+			t.Fatalf("failed to create dashboard: %v", err)
+		}
+	}
+	_, err = svc.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           `option task = {name: "a task",every: 1h} from(bucket:"bucket") |> range(start:-1h)`,
+		OrganizationID: org.ID,
+		OwnerID:        1,
+	})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	mfs, err = reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := map[string]string{"org_id": org.ID.String()}
+	if got := promtest.MustFindMetric(t, mfs, "influxdb_org_buckets_total", labels).GetGauge().GetValue(); got < 1 {
+		t.Errorf("exp buckets of the org to be counted, got %v", got)
+	}
+	if got := promtest.MustFindMetric(t, mfs, "influxdb_org_dashboards_total", labels).GetGauge().GetValue(); got != 2 {
+		t.Errorf("exp 2 dashboards, got %v", got)
+	}
+	if got := promtest.MustFindMetric(t, mfs, "influxdb_org_tasks_total", labels).GetGauge().GetValue(); got != 1 {
+		t.Errorf("exp 1 task, got %v", got)
+	}
+
+	// an org without dashboards or tasks is not reported for them
+	other := map[string]string{"org_id": orgs[1].ID.String()}
+	if m := promtest.FindMetric(mfs, "influxdb_org_dashboards_total", other); m != nil {
+		t.Error("exp no dashboard count for an org without dashboards")
+	}
+}
@@ -480,6 +480,10 @@ func (s *Service) deleteNotificationRule(ctx context.Context, tx Tx, id influxdb
 		return err
 	}
 
+	if err := s.deleteNotificationRuleHistory(ctx, tx, r.GetTaskID()); err != nil {
+		return err
+	}
+
 	encodedID, err := id.Encode()
 	if err != nil {
 		return ErrInvalidNotificationRuleID
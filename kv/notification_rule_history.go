@@ -0,0 +1,189 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var notificationRuleHistoryBucket = []byte("notificationRuleHistoryv1")
+
+// MaxNotificationRuleHistory is the number of evaluations retained in the
+// history of a notification rule, older evaluations are removed as new ones are
+// added.
+const MaxNotificationRuleHistory = 50
+
+var _ influxdb.NotificationRuleHistoryService = (*Service)(nil)
+
+func (s *Service) initializeNotificationRuleHistory(ctx context.Context, tx Tx) error {
+	if _, err := s.notificationRuleHistoryBucket(tx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) notificationRuleHistoryBucket(tx Tx) (Bucket, error) {
+	b, err := tx.Bucket(notificationRuleHistoryBucket)
+	if err != nil {
+		return nil, UnavailableNotificationRuleStoreError(err)
+	}
+	return b, nil
+}
+
+// AddNotificationRuleHistory records the outcome of an evaluation of the
+// notification rule executed by the entry's task.
+func (s *Service) AddNotificationRuleHistory(ctx context.Context, e *influxdb.NotificationRuleHistoryEntry) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.addNotificationRuleHistory(ctx, tx, e)
+	})
+}
+
+func (s *Service) addNotificationRuleHistory(ctx context.Context, tx Tx, e *influxdb.NotificationRuleHistoryEntry) error {
+	prefix, err := e.TaskID.Encode()
+	if err != nil {
+		return ErrInvalidNotificationRuleID
+	}
+
+	runID, err := e.RunID.Encode()
+	if err != nil {
+		return ErrInvalidNotificationRuleID
+	}
+
+	v, err := json.Marshal(e)
+	if err != nil {
+		return InternalNotificationRuleStoreError(err)
+	}
+
+	b, err := s.notificationRuleHistoryBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Put(notificationRuleHistoryKey(prefix, e.Time.UnixNano(), runID), v); err != nil {
+		return UnavailableNotificationRuleStoreError(err)
+	}
+
+	keys, err := notificationRuleHistoryKeys(b, prefix)
+	if err != nil {
+		return err
+	}
+
+	// keys are in chronological order, the oldest are removed first
+	for len(keys) > MaxNotificationRuleHistory {
+		if err := b.Delete(keys[0]); err != nil {
+			return UnavailableNotificationRuleStoreError(err)
+		}
+		keys = keys[1:]
+	}
+	return nil
+}
+
+// FindNotificationRuleHistory returns the most recent evaluations of a
+// notification rule, newest first.
+func (s *Service) FindNotificationRuleHistory(ctx context.Context, ruleID influxdb.ID) ([]*influxdb.NotificationRuleHistoryEntry, error) {
+	var entries []*influxdb.NotificationRuleHistoryEntry
+	err := s.kv.View(ctx, func(tx Tx) error {
+		nr, err := s.findNotificationRuleByID(ctx, tx, ruleID)
+		if err != nil {
+			return err
+		}
+
+		entries, err = s.findNotificationRuleHistory(ctx, tx, nr.GetTaskID())
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			e.RuleID = ruleID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *Service) findNotificationRuleHistory(ctx context.Context, tx Tx, taskID influxdb.ID) ([]*influxdb.NotificationRuleHistoryEntry, error) {
+	prefix, err := taskID.Encode()
+	if err != nil {
+		return nil, ErrInvalidNotificationRuleID
+	}
+
+	b, err := s.notificationRuleHistoryBucket(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, UnavailableNotificationRuleStoreError(err)
+	}
+
+	entries := []*influxdb.NotificationRuleHistoryEntry{}
+	for k, v := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, v = cur.Next() {
+		var e influxdb.NotificationRuleHistoryEntry
+		if err := json.Unmarshal(v, &e); err != nil {
+			return nil, InternalNotificationRuleStoreError(err)
+		}
+		entries = append(entries, &e)
+	}
+
+	// newest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+func (s *Service) deleteNotificationRuleHistory(ctx context.Context, tx Tx, taskID influxdb.ID) error {
+	prefix, err := taskID.Encode()
+	if err != nil {
+		return ErrInvalidNotificationRuleID
+	}
+
+	b, err := s.notificationRuleHistoryBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := notificationRuleHistoryKeys(b, prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return UnavailableNotificationRuleStoreError(err)
+		}
+	}
+	return nil
+}
+
+func notificationRuleHistoryKeys(b Bucket, prefix []byte) ([][]byte, error) {
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, UnavailableNotificationRuleStoreError(err)
+	}
+
+	var keys [][]byte
+	for k, _ := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+	return keys, nil
+}
+
+// notificationRuleHistoryKey is the task ID followed by the big endian time of
+// the evaluation, so that the entries of a task sort chronologically. The run ID
+// breaks ties between evaluations at the same time.
+func notificationRuleHistoryKey(taskID []byte, unixNano int64, runID []byte) []byte {
+	k := make([]byte, 0, len(taskID)+8+len(runID))
+	k = append(k, taskID...)
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(unixNano))
+	k = append(k, ts[:]...)
+	return append(k, runID...)
+}
@@ -0,0 +1,79 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/notification/rule"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNotificationRuleHistory(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing notification rule service: %v", err)
+	}
+
+	const (
+		ruleID influxdb.ID = 1
+		taskID influxdb.ID = 2
+	)
+	nr := &rule.HTTP{
+		Base: rule.Base{
+			ID:         ruleID,
+			Name:       "rule_1",
+			OwnerID:    3,
+			OrgID:      4,
+			EndpointID: 5,
+			TaskID:     taskID,
+		},
+	}
+	if err := svc.PutNotificationRule(ctx, influxdb.NotificationRuleCreate{NotificationRule: nr, Status: influxdb.Active}); err != nil {
+		t.Fatalf("failed to populate notification rule: %v", err)
+	}
+
+	start := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	total := kv.MaxNotificationRuleHistory + 5
+	for i := 0; i < total; i++ {
+		err := svc.AddNotificationRuleHistory(ctx, &influxdb.NotificationRuleHistoryEntry{
+			TaskID:          taskID,
+			RunID:           influxdb.ID(i + 1),
+			Time:            start.Add(time.Duration(i) * time.Minute),
+			StatusesMatched: 1,
+			Notifications: []influxdb.NotificationAttempt{
+				{StatusCode: 500, Payload: `{"text":"crit"}`},
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to add notification rule history: %v", err)
+		}
+	}
+
+	entries, err := svc.FindNotificationRuleHistory(ctx, ruleID)
+	if err != nil {
+		t.Fatalf("failed to find notification rule history: %v", err)
+	}
+
+	if len(entries) != kv.MaxNotificationRuleHistory {
+		t.Fatalf("expected %d entries, got %d", kv.MaxNotificationRuleHistory, len(entries))
+	}
+	if newest := entries[0]; newest.RunID != influxdb.ID(total) || newest.RuleID != ruleID {
+		t.Errorf("expected newest entry to be run %d for rule %s, got run %s for rule %s", total, ruleID, newest.RunID, newest.RuleID)
+	}
+	if oldest := entries[len(entries)-1]; oldest.RunID != influxdb.ID(total-kv.MaxNotificationRuleHistory+1) {
+		t.Errorf("expected oldest entries to be removed, got oldest run %s", oldest.RunID)
+	}
+	if got := entries[0].Notifications; len(got) != 1 || got[0].StatusCode != 500 {
+		t.Errorf("unexpected notifications: %+v", got)
+	}
+}
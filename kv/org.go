@@ -36,6 +36,7 @@ var ErrFailureGeneratingID = &influxdb.Error{
 
 var _ influxdb.OrganizationService = (*Service)(nil)
 var _ influxdb.OrganizationOperationLogService = (*Service)(nil)
+var _ influxdb.OrganizationOperationLogRecorder = (*Service)(nil)
 
 func (s *Service) initializeOrgs(ctx context.Context, tx Tx) error {
 	if _, err := tx.Bucket(organizationBucket); err != nil {
@@ -579,6 +580,13 @@ func (s *Service) GetOrganizationOperationLog(ctx context.Context, id influxdb.I
 	return log, len(log), nil
 }
 
+// AddOrganizationOperationLogEntry adds an entry to the operation log of the organization.
+func (s *Service) AddOrganizationOperationLogEntry(ctx context.Context, id influxdb.ID, description string) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.appendOrganizationEventToLog(ctx, tx, id, description)
+	})
+}
+
 // TODO(desa): what do we want these to be?
 const (
 	organizationCreatedEvent = "Organization Created"
@@ -69,6 +69,9 @@ func NewService(log *zap.Logger, kv Store, configs ...ServiceConfig) *Service {
 type ServiceConfig struct {
 	SessionLength time.Duration
 	Clock         clock.Clock
+	// VariableMaxValues is the maximum number of values of a constant or map
+	// variable, it defaults to influxdb.DefaultVariableMaxValues.
+	VariableMaxValues int
 }
 
 // Initialize creates Buckets needed.
@@ -154,6 +157,18 @@ func (s *Service) Initialize(ctx context.Context) error {
 			return err
 		}
 
+		if err := s.initializeNotificationRuleHistory(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeMaintenanceWindows(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeUserActivity(ctx, tx); err != nil {
+			return err
+		}
+
 		return s.initializeUsers(ctx, tx)
 	})
 }
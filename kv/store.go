@@ -44,6 +44,7 @@ type CursorHints struct {
 	KeyPrefix   *string
 	KeyStart    *string
 	PredicateFn CursorPredicateFunc
+	Descending  bool
 }
 
 // CursorHint configures CursorHints
@@ -81,6 +82,19 @@ func WithCursorHintPredicate(f CursorPredicateFunc) CursorHint {
 	}
 }
 
+// WithCursorHintDescending is a hint to the store
+// that the caller is interested in reading keys in
+// descending order, so that Next walks from the last
+// key towards the first.
+//
+// Stores which do not support it return the keys in
+// ascending order.
+func WithCursorHintDescending() CursorHint {
+	return func(o *CursorHints) {
+		o.Descending = true
+	}
+}
+
 // Bucket is the abstraction used to perform get/put/delete/get-many operations
 // in a key value store.
 type Bucket interface {
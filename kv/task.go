@@ -3,6 +3,7 @@ package kv
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"strings"
 	"time"
 
@@ -35,6 +36,11 @@ var (
 var _ influxdb.TaskService = (*Service)(nil)
 var _ backend.TaskControlService = (*Service)(nil)
 
+// errNegativeOffset is returned when a task is created or updated with a
+// negative offset, such a task runs before the end of its time range and
+// can only ever miss data.
+var errNegativeOffset = errors.New("offset option must not be negative")
+
 type kvTask struct {
 	ID              influxdb.ID            `json:"id"`
 	Type            string                 `json:"type,omitempty"`
@@ -55,6 +61,7 @@ type kvTask struct {
 	CreatedAt       time.Time              `json:"createdAt,omitempty"`
 	UpdatedAt       time.Time              `json:"updatedAt,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	Warnings        []string               `json:"warnings,omitempty"`
 }
 
 func kvToInfluxTask(k *kvTask) *influxdb.Task {
@@ -78,6 +85,7 @@ func kvToInfluxTask(k *kvTask) *influxdb.Task {
 		CreatedAt:       k.CreatedAt,
 		UpdatedAt:       k.UpdatedAt,
 		Metadata:        k.Metadata,
+		Warnings:        k.Warnings,
 	}
 }
 
@@ -594,6 +602,7 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		CreatedAt:       createdAt,
 		LatestCompleted: createdAt,
 		LatestScheduled: createdAt,
+		Warnings:        options.Warnings(tc.Flux, opt),
 	}
 
 	if opt.Offset != nil {
@@ -601,6 +610,9 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		if err != nil {
 			return nil, influxdb.ErrTaskTimeParse(err)
 		}
+		if off < 0 {
+			return nil, influxdb.ErrTaskOptionParse(errNegativeOffset)
+		}
 		task.Offset = off
 
 	}
@@ -706,22 +718,26 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		}
 		task.Flux = *upd.Flux
 
-		options, err := options.FromScript(*upd.Flux)
+		opt, err := options.FromScript(*upd.Flux)
 		if err != nil {
 			return nil, influxdb.ErrTaskOptionParse(err)
 		}
-		task.Name = options.Name
-		task.Every = options.Every.String()
-		task.Cron = options.Cron
+		task.Name = opt.Name
+		task.Every = opt.Every.String()
+		task.Cron = opt.Cron
 
 		var off time.Duration
-		if options.Offset != nil {
-			off, err = time.ParseDuration(options.Offset.String())
+		if opt.Offset != nil {
+			off, err = time.ParseDuration(opt.Offset.String())
 			if err != nil {
 				return nil, influxdb.ErrTaskTimeParse(err)
 			}
 		}
+		if off < 0 {
+			return nil, influxdb.ErrTaskOptionParse(errNegativeOffset)
+		}
 		task.Offset = off
+		task.Warnings = options.Warnings(*upd.Flux, opt)
 		task.UpdatedAt = updatedAt
 	}
 
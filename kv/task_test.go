@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"reflect"
 	"testing"
 	"time"
 
@@ -371,6 +372,78 @@ func TestService_UpdateTask_InactiveToActive(t *testing.T) {
 	}
 }
 
+func TestService_TaskWarnings(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           `option task = {name: "a task",every: 1h} from(bucket:"test") |> range(start:-1h) |> to(bucket:"test", orgID:"0000000000000000")`,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+	})
+	if err != nil {
+		t.Fatal("CreateTask", err)
+	}
+	if len(task.Warnings) != 1 {
+		t.Fatalf("expected a warning for a task writing the bucket it reads with no offset, got %v", task.Warnings)
+	}
+
+	found, err := ts.Service.FindTaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatal("FindTaskByID", err)
+	}
+	if !reflect.DeepEqual(found.Warnings, task.Warnings) {
+		t.Fatalf("expected the warnings to be stored, got %v", found.Warnings)
+	}
+
+	flux := `option task = {name: "a task",every: 1h, offset: 10s} from(bucket:"test") |> range(start:-1h) |> to(bucket:"test", orgID:"0000000000000000")`
+	updated, err := ts.Service.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Flux: &flux})
+	if err != nil {
+		t.Fatal("UpdateTask", err)
+	}
+	if len(updated.Warnings) != 0 {
+		t.Fatalf("expected no warnings for a task with an offset, got %v", updated.Warnings)
+	}
+}
+
+func TestService_NegativeOffset(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	_, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           `option task = {name: "a task",every: 1h, offset: -1m} from(bucket:"test") |> range(start:-1h)`,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+	})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid error creating a task with a negative offset, got %v", err)
+	}
+
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           `option task = {name: "a task",every: 1h, offset: 1m} from(bucket:"test") |> range(start:-1h)`,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+	})
+	if err != nil {
+		t.Fatal("CreateTask", err)
+	}
+
+	flux := `option task = {name: "a task",every: 1h, offset: -1m} from(bucket:"test") |> range(start:-1h)`
+	if _, err := ts.Service.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Flux: &flux}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid error updating a task with a negative offset, got %v", err)
+	}
+}
+
 func TestTaskRunCancellation(t *testing.T) {
 	store, close, err := NewTestBoltStore(t)
 	if err != nil {
@@ -240,6 +240,10 @@ func (s *Service) updateTelegrafConfig(ctx context.Context, tx Tx, id influxdb.I
 	// ID and OrganizationID can not be updated
 	tc.ID = current.ID
 	tc.OrgID = current.OrgID
+	// a config updated without a token keeps the one minted for it
+	if !tc.TokenID.Valid() {
+		tc.TokenID = current.TokenID
+	}
 	err = s.putTelegrafConfig(ctx, tx, tc)
 	return tc, err
 }
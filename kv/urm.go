@@ -1,6 +1,7 @@
 package kv
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -86,7 +87,7 @@ func (s *Service) FindUserResourceMappings(ctx context.Context, filter influxdb.
 	var ms []*influxdb.UserResourceMapping
 	err := s.kv.View(ctx, func(tx Tx) error {
 		var err error
-		ms, err = s.findUserResourceMappings(ctx, tx, filter)
+		ms, err = s.findUserResourceMappings(ctx, tx, filter, opt...)
 		return err
 	})
 
@@ -97,6 +98,28 @@ func (s *Service) FindUserResourceMappings(ctx context.Context, filter influxdb.
 	return ms, len(ms), nil
 }
 
+// userResourceAfterKey returns the key of the mapping a page of the mappings
+// of the filter starts after. After is the ID of a user when the mappings are
+// of a resource, or the ID of a resource when they are of a user.
+func userResourceAfterKey(filter influxdb.UserResourceMappingFilter, after influxdb.ID) ([]byte, error) {
+	m := &influxdb.UserResourceMapping{
+		ResourceID: filter.ResourceID,
+		UserID:     filter.UserID,
+	}
+	switch {
+	case filter.ResourceID.Valid():
+		m.UserID = after
+	case filter.UserID.Valid():
+		m.ResourceID = after
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "paging user resource mappings after an ID requires a resource or a user filter",
+		}
+	}
+	return userResourceKey(m)
+}
+
 func userResourceMappingPredicate(filter influxdb.UserResourceMappingFilter) CursorPredicateFunc {
 	switch {
 	case filter.ResourceID.Valid() && filter.UserID.Valid():
@@ -122,14 +145,35 @@ func userResourceMappingPredicate(filter influxdb.UserResourceMappingFilter) Cur
 	}
 }
 
-func (s *Service) findUserResourceMappings(ctx context.Context, tx Tx, filter influxdb.UserResourceMappingFilter) ([]*influxdb.UserResourceMapping, error) {
+func (s *Service) findUserResourceMappings(ctx context.Context, tx Tx, filter influxdb.UserResourceMappingFilter, opts ...influxdb.FindOptions) ([]*influxdb.UserResourceMapping, error) {
+	var offset, limit, count int
+	var after []byte
+	if len(opts) > 0 {
+		offset = opts[0].Offset
+		limit = opts[0].Limit
+		if opts[0].After != nil {
+			var err error
+			if after, err = userResourceAfterKey(filter, *opts[0].After); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	ms := []*influxdb.UserResourceMapping{}
 	pred := userResourceMappingPredicate(filter)
 	filterFn := filterMappingsFn(filter)
-	err := s.forEachUserResourceMapping(ctx, tx, pred, func(m *influxdb.UserResourceMapping) bool {
+	err := s.forEachUserResourceMapping(ctx, tx, pred, after, func(m *influxdb.UserResourceMapping) bool {
 		if filterFn(m) {
-			ms = append(ms, m)
+			if count >= offset {
+				ms = append(ms, m)
+			}
+			count++
+		}
+
+		if limit > 0 && len(ms) >= limit {
+			return false
 		}
+
 		return true
 	})
 
@@ -235,7 +279,9 @@ func userResourceKey(m *influxdb.UserResourceMapping) ([]byte, error) {
 	return key, nil
 }
 
-func (s *Service) forEachUserResourceMapping(ctx context.Context, tx Tx, pred CursorPredicateFunc, fn func(*influxdb.UserResourceMapping) bool) error {
+// forEachUserResourceMapping calls fn with the mappings whose keys match the
+// predicate and sort after the key after, if any, until fn returns false.
+func (s *Service) forEachUserResourceMapping(ctx context.Context, tx Tx, pred CursorPredicateFunc, after []byte, fn func(*influxdb.UserResourceMapping) bool) error {
 	b, err := tx.Bucket(urmBucket)
 	if err != nil {
 		return UnavailableURMServiceError(err)
@@ -251,6 +297,10 @@ func (s *Service) forEachUserResourceMapping(ctx context.Context, tx Tx, pred Cu
 	}
 
 	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		if after != nil && bytes.Compare(k, after) <= 0 {
+			continue
+		}
+
 		m := &influxdb.UserResourceMapping{}
 		if err := json.Unmarshal(v, m); err != nil {
 			return CorruptURMError(err)
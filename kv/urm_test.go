@@ -2,6 +2,7 @@ package kv_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/influxdata/influxdb"
@@ -66,3 +67,109 @@ func initUserResourceMappingService(s kv.Store, f influxdbtesting.UserResourceFi
 		}
 	}
 }
+
+func TestUserResourceMappings_Paging(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		store func(t *testing.T) (kv.Store, func(), error)
+	}{
+		{name: "bolt", store: NewTestBoltStore},
+		{name: "inmem", store: NewTestInmemStore},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s, closeStore, err := tt.store(t)
+			if err != nil {
+				t.Fatalf("failed to create new kv store: %v", err)
+			}
+			defer closeStore()
+
+			svc := kv.NewService(zaptest.NewLogger(t), s)
+			ctx := context.Background()
+			if err := svc.Initialize(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			const resourceID = influxdb.ID(1000)
+			mapping := func(userID influxdb.ID) *influxdb.UserResourceMapping {
+				return &influxdb.UserResourceMapping{
+					ResourceID:   resourceID,
+					ResourceType: influxdb.DashboardsResourceType,
+					UserID:       userID,
+					UserType:     influxdb.Member,
+				}
+			}
+
+			// the users before paging starts have even IDs, the users
+			// mapped while paging have odd ones.
+			want := make(map[influxdb.ID]bool)
+			for id := influxdb.ID(2); id <= 40; id += 2 {
+				if err := svc.CreateUserResourceMapping(ctx, mapping(id)); err != nil {
+					t.Fatal(err)
+				}
+				want[id] = true
+			}
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for id := influxdb.ID(1); id < 40; id += 2 {
+					if err := svc.CreateUserResourceMapping(ctx, mapping(id)); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+
+			filter := influxdb.UserResourceMappingFilter{ResourceID: resourceID}
+			opts := influxdb.FindOptions{Limit: 3}
+			var last influxdb.ID
+			for {
+				page, _, err := svc.FindUserResourceMappings(ctx, filter, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(page) > opts.Limit {
+					t.Fatalf("got page of %d mappings, want at most %d", len(page), opts.Limit)
+				}
+				for _, m := range page {
+					if m.UserID <= last {
+						t.Fatalf("got user %s after user %s, the pages overlap", m.UserID, last)
+					}
+					last = m.UserID
+					delete(want, m.UserID)
+				}
+				if len(page) < opts.Limit {
+					break
+				}
+				after := page[len(page)-1].UserID
+				opts.After = &after
+			}
+			wg.Wait()
+
+			if len(want) > 0 {
+				t.Errorf("mappings of %d users were not in any page", len(want))
+			}
+		})
+	}
+}
+
+func TestUserResourceMappings_PagingRequiresFilter(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	after := influxdb.ID(1)
+	_, _, err = svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{}, influxdb.FindOptions{Limit: 10, After: &after})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("got error %v, want an invalid error", err)
+	}
+}
@@ -0,0 +1,110 @@
+package kv
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+var userLastSeenBucket = []byte("userlastseenv1")
+
+var _ influxdb.UserActivityService = (*Service)(nil)
+
+func (s *Service) initializeUserActivity(ctx context.Context, tx Tx) error {
+	_, err := tx.Bucket(userLastSeenBucket)
+	return err
+}
+
+// FindUsersLastSeen returns the last time each of the users was seen.
+func (s *Service) FindUsersLastSeen(ctx context.Context, ids []influxdb.ID) (map[influxdb.ID]time.Time, error) {
+	seen := make(map[influxdb.ID]time.Time, len(ids))
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(userLastSeenBucket)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			t, err := findUserLastSeen(b, id)
+			if err != nil {
+				return err
+			}
+			if !t.IsZero() {
+				seen[id] = t
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+	return seen, nil
+}
+
+// SetUsersLastSeen records the last time each of the users was seen, in a
+// single write.
+func (s *Service) SetUsersLastSeen(ctx context.Context, seen map[influxdb.ID]time.Time) error {
+	if len(seen) == 0 {
+		return nil
+	}
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(userLastSeenBucket)
+		if err != nil {
+			return err
+		}
+
+		for id, t := range seen {
+			last, err := findUserLastSeen(b, id)
+			if err != nil {
+				return err
+			}
+			if !t.After(last) {
+				continue
+			}
+
+			key, err := id.Encode()
+			if err != nil {
+				return err
+			}
+			v, err := t.UTC().MarshalText()
+			if err != nil {
+				return err
+			}
+			if err := b.Put(key, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func findUserLastSeen(b Bucket, id influxdb.ID) (time.Time, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	v, err := b.Get(key)
+	if IsNotFound(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var t time.Time
+	if err := t.UnmarshalText(v); err != nil {
+		return time.Time{}, err
+	}
+	return t, nil
+}
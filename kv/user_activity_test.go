@@ -0,0 +1,56 @@
+package kv_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestUsersLastSeen(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing user activity service: %v", err)
+	}
+
+	start := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	err = svc.SetUsersLastSeen(ctx, map[influxdb.ID]time.Time{
+		1: start.Add(time.Hour),
+		2: start,
+	})
+	if err != nil {
+		t.Fatalf("failed to set last seen: %v", err)
+	}
+
+	// the older time of user 1 is ignored
+	err = svc.SetUsersLastSeen(ctx, map[influxdb.ID]time.Time{
+		1: start,
+		2: start.Add(2 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("failed to set last seen: %v", err)
+	}
+
+	seen, err := svc.FindUsersLastSeen(ctx, []influxdb.ID{1, 2, 3})
+	if err != nil {
+		t.Fatalf("failed to find last seen: %v", err)
+	}
+	want := map[influxdb.ID]time.Time{
+		1: start.Add(time.Hour),
+		2: start.Add(2 * time.Hour),
+	}
+	if !reflect.DeepEqual(seen, want) {
+		t.Fatalf("got last seen %v, want %v", seen, want)
+	}
+}
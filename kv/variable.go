@@ -150,6 +150,7 @@ func (s *Service) forEachVariable(ctx context.Context, tx Tx, fn func(*influxdb.
 		if err := json.Unmarshal(v, m); err != nil {
 			return err
 		}
+		normalizeVariable(m)
 		if !fn(m) {
 			break
 		}
@@ -233,10 +234,38 @@ func (s *Service) findVariableByID(ctx context.Context, tx Tx, id influxdb.ID) (
 			Err: err,
 		}
 	}
+	normalizeVariable(variable)
 
 	return variable, nil
 }
 
+// normalizeVariable normalizes the values of a variable read from the store.
+// The variables stored before the values were normalized on write are
+// normalized as they are read, and stored normalized on their next update.
+func normalizeVariable(variable *influxdb.Variable) {
+	if variable.Arguments != nil {
+		variable.Arguments.Normalize()
+	}
+}
+
+// validVariableValues validates and normalizes the values of a variable being
+// written to the store.
+func (s *Service) validVariableValues(args *influxdb.VariableArguments) error {
+	if args == nil {
+		return nil
+	}
+
+	maxValues := s.Config.VariableMaxValues
+	if maxValues == 0 {
+		maxValues = influxdb.DefaultVariableMaxValues
+	}
+	if err := args.ValidValues(maxValues); err != nil {
+		return err
+	}
+	args.Normalize()
+	return nil
+}
+
 // CreateVariable creates a new variable and assigns it an ID
 func (s *Service) CreateVariable(ctx context.Context, variable *influxdb.Variable) error {
 	return s.kv.Update(ctx, func(tx Tx) error {
@@ -247,6 +276,10 @@ func (s *Service) CreateVariable(ctx context.Context, variable *influxdb.Variabl
 			}
 		}
 
+		if err := s.validVariableValues(variable.Arguments); err != nil {
+			return err
+		}
+
 		variable.Name = strings.TrimSpace(variable.Name)
 
 		if err := s.uniqueVariableName(ctx, tx, variable); err != nil {
@@ -274,6 +307,10 @@ func (s *Service) CreateVariable(ctx context.Context, variable *influxdb.Variabl
 // ReplaceVariable puts a variable in the store
 func (s *Service) ReplaceVariable(ctx context.Context, variable *influxdb.Variable) error {
 	return s.kv.Update(ctx, func(tx Tx) error {
+		if err := s.validVariableValues(variable.Arguments); err != nil {
+			return err
+		}
+
 		if err := s.putVariableOrgsIndex(ctx, tx, variable); err != nil {
 			return &influxdb.Error{
 				Err: err,
@@ -404,6 +441,10 @@ func (s *Service) UpdateVariable(ctx context.Context, id influxdb.ID, update *in
 
 		variable = m
 
+		if err := s.validVariableValues(update.Arguments); err != nil {
+			return err
+		}
+
 		if update.Name != "" {
 			update.Name = strings.TrimSpace(update.Name)
 
@@ -2,10 +2,12 @@ package kv_test
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
 	influxdbtesting "github.com/influxdata/influxdb/testing"
 	"go.uber.org/zap/zaptest"
 )
@@ -73,3 +75,88 @@ func initVariableService(s kv.Store, f influxdbtesting.VariableFields, t *testin
 
 	return svc, kv.OpPrefix, done
 }
+
+func TestVariableService_ConstantValues(t *testing.T) {
+	newVariable := func(values ...string) *influxdb.Variable {
+		return &influxdb.Variable{
+			OrganizationID: influxdb.ID(1),
+			Name:           "var",
+			Arguments: &influxdb.VariableArguments{
+				Type:   "constant",
+				Values: influxdb.VariableConstantValues(values),
+			},
+		}
+	}
+
+	newService := func(t *testing.T, maxValues int) (*kv.Service, func()) {
+		t.Helper()
+
+		s, closeStore, err := NewTestInmemStore(t)
+		if err != nil {
+			t.Fatalf("failed to create new kv store: %v", err)
+		}
+		svc := kv.NewService(zaptest.NewLogger(t), s, kv.ServiceConfig{
+			VariableMaxValues: maxValues,
+		})
+		svc.IDGenerator = mock.NewMockIDGenerator()
+		if err := svc.Initialize(context.Background()); err != nil {
+			t.Fatalf("error initializing variable service: %v", err)
+		}
+		return svc, closeStore
+	}
+
+	t.Run("values are stored sorted", func(t *testing.T) {
+		svc, done := newService(t, 0)
+		defer done()
+
+		ctx := context.Background()
+		v := newVariable("c", "a", "b")
+		if err := svc.CreateVariable(ctx, v); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := svc.FindVariableByID(ctx, v.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := influxdb.VariableConstantValues{"a", "b", "c"}
+		if !reflect.DeepEqual(got.Arguments.Values, want) {
+			t.Errorf("got = %v, want %v", got.Arguments.Values, want)
+		}
+	})
+
+	t.Run("duplicate values are rejected", func(t *testing.T) {
+		svc, done := newService(t, 0)
+		defer done()
+
+		err := svc.CreateVariable(context.Background(), newVariable("a", "b", "a"))
+		if code := influxdb.ErrorCode(err); code != influxdb.EUnprocessableEntity {
+			t.Errorf("unexpected error code: got=%q want=%q", code, influxdb.EUnprocessableEntity)
+		}
+	})
+
+	t.Run("values exceeding the max are rejected", func(t *testing.T) {
+		svc, done := newService(t, 2)
+		defer done()
+
+		ctx := context.Background()
+		err := svc.CreateVariable(ctx, newVariable("a", "b", "c"))
+		if code := influxdb.ErrorCode(err); code != influxdb.EUnprocessableEntity {
+			t.Errorf("unexpected error code: got=%q want=%q", code, influxdb.EUnprocessableEntity)
+		}
+
+		v := newVariable("a", "b")
+		if err := svc.CreateVariable(ctx, v); err != nil {
+			t.Fatal(err)
+		}
+		_, err = svc.UpdateVariable(ctx, v.ID, &influxdb.VariableUpdate{
+			Arguments: &influxdb.VariableArguments{
+				Type:   "constant",
+				Values: influxdb.VariableConstantValues{"a", "b", "c"},
+			},
+		})
+		if code := influxdb.ErrorCode(err); code != influxdb.EUnprocessableEntity {
+			t.Errorf("unexpected error code: got=%q want=%q", code, influxdb.EUnprocessableEntity)
+		}
+	})
+}
@@ -36,7 +36,7 @@ type LabelService interface {
 	FindLabels(ctx context.Context, filter LabelFilter, opt ...FindOptions) ([]*Label, error)
 
 	// FindResourceLabels returns a list of labels that belong to a resource
-	FindResourceLabels(ctx context.Context, filter LabelMappingFilter) ([]*Label, error)
+	FindResourceLabels(ctx context.Context, filter LabelMappingFilter, opt ...FindOptions) ([]*Label, error)
 
 	// CreateLabel creates a new label
 	CreateLabel(ctx context.Context, l *Label) error
@@ -0,0 +1,216 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the format of the time of rotation in the names of the
+// rotated files, it sorts oldest first.
+const backupTimeFormat = "20060102T150405.000"
+
+// RotatingFile is a log file rotated once it reaches its max size. A rotated
+// file keeps the name of the file with the time of its rotation inserted
+// before the extension, i.e. influxd-20200102T150405.000.log. The rotated
+// files older than the max age, or beyond the max backups, are removed.
+//
+// A failed rotation does not lose the logs, they keep being appended to the
+// file and the rotation is attempted again by the next write.
+type RotatingFile struct {
+	// Path is the path of the file, its directory is created as needed.
+	Path string
+	// MaxSize is the size in bytes the file is rotated at, 0 never rotates.
+	MaxSize int64
+	// MaxAge is the age the rotated files are removed at, 0 keeps them.
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files kept, 0 keeps all of them.
+	MaxBackups int
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	closed bool
+	now    func() time.Time
+}
+
+// Write appends p to the file, rotating it first when p would grow it beyond
+// its max size.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	if r.f == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.MaxSize {
+		if err := r.rotate(); err != nil {
+			// the file rotation failed on is still open, unless it could
+			// not be opened again.
+			if r.f == nil {
+				return 0, err
+			}
+			fmt.Fprintf(os.Stderr, "failed to rotate log file %s: %v\n", r.Path, err)
+		}
+	}
+
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Sync commits the file to stable storage.
+func (r *RotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return nil
+	}
+	return r.f.Sync()
+}
+
+// Close closes the file, the writes that follow fail.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+// open opens the file for appending, creating it if it does not exist.
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.Path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, fi.Size()
+	return nil
+}
+
+// rotate renames the file to its backup name and opens a new file. The file
+// is opened again when it cannot be renamed.
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+
+	renameErr := os.Rename(r.Path, r.backupName(r.timeNow()))
+	if err := r.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+	return r.removeOld()
+}
+
+// removeOld removes the rotated files beyond the max backups or the max age.
+func (r *RotatingFile) removeOld() error {
+	if r.MaxBackups <= 0 && r.MaxAge <= 0 {
+		return nil
+	}
+
+	backups, err := r.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	cutoff := r.timeNow().Add(-r.MaxAge)
+	for i, b := range backups {
+		tooMany := r.MaxBackups > 0 && len(backups)-i > r.MaxBackups
+		tooOld := r.MaxAge > 0 && b.rotatedAt.Before(cutoff)
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(b.path); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+type backupFile struct {
+	path      string
+	rotatedAt time.Time
+}
+
+// backups returns the rotated files of the file, oldest first.
+func (r *RotatingFile) backups() ([]backupFile, error) {
+	dir := filepath.Dir(r.Path)
+	prefix, ext := r.nameParts()
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backupFile
+	for _, fi := range fis {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		t, err := time.Parse(backupTimeFormat, ts)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), rotatedAt: t})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].rotatedAt.Before(backups[j].rotatedAt)
+	})
+	return backups, nil
+}
+
+func (r *RotatingFile) backupName(t time.Time) string {
+	prefix, ext := r.nameParts()
+	return filepath.Join(filepath.Dir(r.Path), prefix+t.UTC().Format(backupTimeFormat)+ext)
+}
+
+// nameParts returns the prefix and the extension of the names of the rotated
+// files.
+func (r *RotatingFile) nameParts() (string, string) {
+	name := filepath.Base(r.Path)
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-", ext
+}
+
+func (r *RotatingFile) timeNow() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
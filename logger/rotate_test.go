@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influxdb-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+	r := &RotatingFile{
+		Path:       filepath.Join(dir, "influxd.log"),
+		MaxSize:    10,
+		MaxBackups: 2,
+		now: func() time.Time {
+			now = now.Add(time.Second)
+			return now
+		},
+	}
+
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(r.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "dddddddd\n"; got != want {
+		t.Errorf("got file %q, want %q", got, want)
+	}
+
+	backups, err := r.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("got %d rotated files, want 2", len(backups))
+	}
+	for i, want := range []string{"bbbbbbbb\n", "cccccccc\n"} {
+		b, err := ioutil.ReadFile(backups[i].path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != want {
+			t.Errorf("got rotated file %d %q, want %q", i, b, want)
+		}
+	}
+
+	if _, err := r.Write([]byte("e")); err != os.ErrClosed {
+		t.Errorf("got error %v writing to a closed file, want %v", err, os.ErrClosed)
+	}
+}
+
+func TestRotatingFile_RotateFails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influxdb-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := &RotatingFile{Path: filepath.Join(dir, "influxd.log"), MaxSize: 10}
+	defer r.Close()
+
+	if _, err := r.Write([]byte("aaaaaaaa\n")); err != nil {
+		t.Fatal(err)
+	}
+	// a directory in place of the backup name fails the rename.
+	if err := os.Mkdir(r.backupName(time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	r.now = func() time.Time { return time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC) }
+
+	if _, err := r.Write([]byte("bbbbbbbb\n")); err != nil {
+		t.Fatalf("unexpected error writing after a failed rotation: %v", err)
+	}
+	b, err := ioutil.ReadFile(r.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "aaaaaaaa\nbbbbbbbb\n"; got != want {
+		t.Errorf("got file %q, want %q", got, want)
+	}
+}
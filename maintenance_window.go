@@ -0,0 +1,193 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrMaintenanceWindowNotFound is the error msg for a missing maintenance window.
+const ErrMaintenanceWindowNotFound = "maintenance window not found"
+
+// ops for maintenance window error.
+const (
+	OpFindMaintenanceWindowByID = "FindMaintenanceWindowByID"
+	OpFindMaintenanceWindows    = "FindMaintenanceWindows"
+	OpCreateMaintenanceWindow   = "CreateMaintenanceWindow"
+	OpUpdateMaintenanceWindow   = "UpdateMaintenanceWindow"
+	OpDeleteMaintenanceWindow   = "DeleteMaintenanceWindow"
+)
+
+// MaintenanceWindow mutes the notifications of an org during a time range, i.e.
+// planned maintenance. Checks keep recording their statuses while a window is
+// active, the notifications of the rules the window selects are suppressed.
+type MaintenanceWindow struct {
+	ID          ID     `json:"id,omitempty"`
+	OrgID       ID     `json:"orgID"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Start is inclusive and End is exclusive.
+	Start      time.Time   `json:"start"`
+	End        time.Time   `json:"end"`
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+	// Labels are the names of the labels selecting the checks and notification
+	// rules the window mutes. A window without labels mutes every rule of the org.
+	Labels []string `json:"labels,omitempty"`
+	CRUDLog
+}
+
+// Recurrence repeats a maintenance window every interval, until the optional
+// time. An occurrence starting at or after Until does not happen.
+type Recurrence struct {
+	Every Duration   `json:"every"`
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// Valid returns an error if the maintenance window is invalid.
+func (w *MaintenanceWindow) Valid() error {
+	if !w.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "maintenance window orgID is invalid",
+		}
+	}
+	if w.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "maintenance window name can't be empty",
+		}
+	}
+	if !w.End.After(w.Start) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "maintenance window end must be after its start",
+		}
+	}
+	if r := w.Recurrence; r != nil {
+		if r.Every.Duration < w.End.Sub(w.Start) {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "maintenance window recurrence must not be shorter than the window",
+			}
+		}
+		if r.Until != nil && !r.Until.After(w.Start) {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "maintenance window recurrence must end after the window's start",
+			}
+		}
+	}
+	return nil
+}
+
+// Active returns true if an occurrence of the window covers the time.
+func (w *MaintenanceWindow) Active(t time.Time) bool {
+	if t.Before(w.Start) {
+		return false
+	}
+	if w.Recurrence == nil {
+		return t.Before(w.End)
+	}
+
+	start := w.occurrence(t)
+	if w.Recurrence.Until != nil && !start.Before(*w.Recurrence.Until) {
+		return false
+	}
+	return t.Before(start.Add(w.End.Sub(w.Start)))
+}
+
+// Expired returns true if the window has no occurrence covering the time or
+// any later time. A window recurring without an end never expires.
+func (w *MaintenanceWindow) Expired(t time.Time) bool {
+	if w.Recurrence == nil {
+		return !t.Before(w.End)
+	}
+	if w.Recurrence.Until == nil {
+		return false
+	}
+
+	// the last occurrence is the latest starting before until
+	last := w.occurrence(w.Recurrence.Until.Add(-1))
+	return !t.Before(last.Add(w.End.Sub(w.Start)))
+}
+
+// occurrence returns the start of the latest occurrence starting at or before
+// the time, the time must not be before the start of the window.
+func (w *MaintenanceWindow) occurrence(t time.Time) time.Time {
+	every := w.Recurrence.Every.Duration
+	if every <= 0 {
+		return w.Start
+	}
+	n := t.Sub(w.Start) / every
+	return w.Start.Add(n * every)
+}
+
+// Selects returns true if the window mutes a resource with the labels.
+func (w *MaintenanceWindow) Selects(labels []*Label) bool {
+	if len(w.Labels) == 0 {
+		return true
+	}
+	for _, l := range labels {
+		for _, name := range w.Labels {
+			if l.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MuteReason is the annotation of the notifications the window suppresses.
+func (w *MaintenanceWindow) MuteReason() string {
+	return fmt.Sprintf("muted by window %s (%s)", w.Name, w.ID)
+}
+
+// MaintenanceWindowFilter represents a set of filters that restrict the
+// returned maintenance windows.
+type MaintenanceWindowFilter struct {
+	OrgID *ID
+}
+
+// MaintenanceWindowService manages maintenance windows.
+type MaintenanceWindowService interface {
+	// FindMaintenanceWindowByID returns a single maintenance window by ID.
+	FindMaintenanceWindowByID(ctx context.Context, id ID) (*MaintenanceWindow, error)
+
+	// FindMaintenanceWindows returns the maintenance windows matching the filter.
+	FindMaintenanceWindows(ctx context.Context, filter MaintenanceWindowFilter) ([]*MaintenanceWindow, error)
+
+	// CreateMaintenanceWindow creates a new maintenance window and sets w.ID
+	// with the new identifier.
+	CreateMaintenanceWindow(ctx context.Context, w *MaintenanceWindow) error
+
+	// UpdateMaintenanceWindow replaces the maintenance window with the ID.
+	UpdateMaintenanceWindow(ctx context.Context, id ID, w *MaintenanceWindow) (*MaintenanceWindow, error)
+
+	// DeleteMaintenanceWindow removes a maintenance window by ID.
+	DeleteMaintenanceWindow(ctx context.Context, id ID) error
+}
+
+// NotificationRuleMute is what the active maintenance windows mute of a
+// notification rule. A window selecting the rule mutes every notification of
+// the rule, a window selecting checks mutes the notifications of the statuses
+// of those checks, the series of the other checks are notified as usual.
+type NotificationRuleMute struct {
+	// Rule is the window muting every notification of the rule, nil if
+	// there is none.
+	Rule *MaintenanceWindow
+	// Checks are the windows muting the statuses of a check, by check.
+	Checks map[ID]*MaintenanceWindow
+}
+
+// Muted returns true if any notification of the rule is muted.
+func (m *NotificationRuleMute) Muted() bool {
+	return m != nil && (m.Rule != nil || len(m.Checks) > 0)
+}
+
+// MaintenanceWindowMuter finds the maintenance windows muting notification rules.
+type MaintenanceWindowMuter interface {
+	// FindNotificationRuleMute returns what the maintenance windows active at
+	// the time mute of the notification rule executed by the task, nil if
+	// nothing is muted.
+	FindNotificationRuleMute(ctx context.Context, taskID ID, now time.Time) (*NotificationRuleMute, error)
+}
@@ -0,0 +1,157 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestMaintenanceWindowActive(t *testing.T) {
+	start := time.Date(2020, 1, 6, 2, 0, 0, 0, time.UTC)
+	until := start.Add(14 * 24 * time.Hour)
+
+	once := &influxdb.MaintenanceWindow{
+		Start: start,
+		End:   start.Add(time.Hour),
+	}
+	daily := &influxdb.MaintenanceWindow{
+		Start:      start,
+		End:        start.Add(time.Hour),
+		Recurrence: &influxdb.Recurrence{Every: influxdb.Duration{Duration: 24 * time.Hour}},
+	}
+	weekly := &influxdb.MaintenanceWindow{
+		Start: start,
+		End:   start.Add(time.Hour),
+		Recurrence: &influxdb.Recurrence{
+			Every: influxdb.Duration{Duration: 7 * 24 * time.Hour},
+			Until: &until,
+		},
+	}
+
+	tests := []struct {
+		name   string
+		window *influxdb.MaintenanceWindow
+		at     time.Time
+		active bool
+	}{
+		{name: "before the start", window: once, at: start.Add(-time.Nanosecond)},
+		{name: "at the start", window: once, at: start, active: true},
+		{name: "before the end", window: once, at: start.Add(time.Hour - time.Nanosecond), active: true},
+		{name: "at the end", window: once, at: start.Add(time.Hour)},
+		{name: "at the start of a recurrence", window: daily, at: start.Add(72 * time.Hour), active: true},
+		{name: "before the end of a recurrence", window: daily, at: start.Add(73*time.Hour - time.Nanosecond), active: true},
+		{name: "at the end of a recurrence", window: daily, at: start.Add(73 * time.Hour)},
+		{name: "before the start of a recurrence", window: daily, at: start.Add(72*time.Hour - time.Nanosecond)},
+		{name: "during the last recurrence", window: weekly, at: start.Add(7*24*time.Hour + time.Minute), active: true},
+		{name: "during a recurrence starting at until", window: weekly, at: until.Add(time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.Active(tt.at); got != tt.active {
+				t.Errorf("exp active %v, got %v", tt.active, got)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindowExpired(t *testing.T) {
+	start := time.Date(2020, 1, 6, 2, 0, 0, 0, time.UTC)
+	until := start.Add(10 * 24 * time.Hour)
+
+	once := &influxdb.MaintenanceWindow{
+		Start: start,
+		End:   start.Add(time.Hour),
+	}
+	weekly := &influxdb.MaintenanceWindow{
+		Start: start,
+		End:   start.Add(time.Hour),
+		Recurrence: &influxdb.Recurrence{
+			Every: influxdb.Duration{Duration: 7 * 24 * time.Hour},
+			Until: &until,
+		},
+	}
+	forever := &influxdb.MaintenanceWindow{
+		Start:      start,
+		End:        start.Add(time.Hour),
+		Recurrence: &influxdb.Recurrence{Every: influxdb.Duration{Duration: time.Hour}},
+	}
+
+	if once.Expired(start.Add(time.Hour - time.Nanosecond)) {
+		t.Error("window expired before its end")
+	}
+	if !once.Expired(start.Add(time.Hour)) {
+		t.Error("window not expired at its end")
+	}
+	// the last occurrence starts a week after the start, before until
+	if weekly.Expired(start.Add(7*24*time.Hour + 30*time.Minute)) {
+		t.Error("recurring window expired during its last occurrence")
+	}
+	if !weekly.Expired(start.Add(7*24*time.Hour + time.Hour)) {
+		t.Error("recurring window not expired at the end of its last occurrence")
+	}
+	if forever.Expired(start.Add(365 * 24 * time.Hour)) {
+		t.Error("window recurring without end expired")
+	}
+}
+
+func TestMaintenanceWindowSelects(t *testing.T) {
+	prod := &influxdb.Label{Name: "prod"}
+	staging := &influxdb.Label{Name: "staging"}
+
+	all := &influxdb.MaintenanceWindow{}
+	selective := &influxdb.MaintenanceWindow{Labels: []string{"staging", "dev"}}
+
+	if !all.Selects(nil) || !all.Selects([]*influxdb.Label{prod}) {
+		t.Error("window without labels must select every resource")
+	}
+	if !selective.Selects([]*influxdb.Label{prod, staging}) {
+		t.Error("window must select a resource with one of its labels")
+	}
+	if selective.Selects([]*influxdb.Label{prod}) {
+		t.Error("window selected a resource without its labels")
+	}
+	if selective.Selects(nil) {
+		t.Error("window selected a resource without labels")
+	}
+}
+
+func TestMaintenanceWindowValid(t *testing.T) {
+	start := time.Date(2020, 1, 6, 2, 0, 0, 0, time.UTC)
+	valid := func() *influxdb.MaintenanceWindow {
+		return &influxdb.MaintenanceWindow{
+			OrgID: influxdb.ID(1),
+			Name:  "patching",
+			Start: start,
+			End:   start.Add(time.Hour),
+		}
+	}
+
+	if err := valid().Valid(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(w *influxdb.MaintenanceWindow)
+	}{
+		{name: "without a name", modify: func(w *influxdb.MaintenanceWindow) { w.Name = "" }},
+		{name: "without an org", modify: func(w *influxdb.MaintenanceWindow) { w.OrgID = 0 }},
+		{name: "ending at its start", modify: func(w *influxdb.MaintenanceWindow) { w.End = w.Start }},
+		{
+			name: "recurring more often than it lasts",
+			modify: func(w *influxdb.MaintenanceWindow) {
+				w.Recurrence = &influxdb.Recurrence{Every: influxdb.Duration{Duration: time.Minute}}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := valid()
+			tt.modify(w)
+			if err := w.Valid(); influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Errorf("exp invalid error, got %v", err)
+			}
+		})
+	}
+}
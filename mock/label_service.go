@@ -62,7 +62,7 @@ func (s *LabelService) FindLabels(ctx context.Context, filter platform.LabelFilt
 }
 
 // FindResourceLabels finds mappings that match a given filter.
-func (s *LabelService) FindResourceLabels(ctx context.Context, filter platform.LabelMappingFilter) ([]*platform.Label, error) {
+func (s *LabelService) FindResourceLabels(ctx context.Context, filter platform.LabelMappingFilter, opt ...platform.FindOptions) ([]*platform.Label, error) {
 	defer s.FindResourceLabelsCalls.IncrFn()()
 	return s.FindResourceLabelsFn(ctx, filter)
 }
@@ -0,0 +1,59 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.MaintenanceWindowService = (*MaintenanceWindowService)(nil)
+
+// MaintenanceWindowService is a mock implementation of an influxdb.MaintenanceWindowService.
+type MaintenanceWindowService struct {
+	FindMaintenanceWindowByIDFn func(context.Context, influxdb.ID) (*influxdb.MaintenanceWindow, error)
+	FindMaintenanceWindowsFn    func(context.Context, influxdb.MaintenanceWindowFilter) ([]*influxdb.MaintenanceWindow, error)
+	CreateMaintenanceWindowFn   func(context.Context, *influxdb.MaintenanceWindow) error
+	UpdateMaintenanceWindowFn   func(context.Context, influxdb.ID, *influxdb.MaintenanceWindow) (*influxdb.MaintenanceWindow, error)
+	DeleteMaintenanceWindowFn   func(context.Context, influxdb.ID) error
+}
+
+// NewMaintenanceWindowService returns a mock MaintenanceWindowService where its
+// methods will return zero values.
+func NewMaintenanceWindowService() *MaintenanceWindowService {
+	return &MaintenanceWindowService{
+		FindMaintenanceWindowByIDFn: func(context.Context, influxdb.ID) (*influxdb.MaintenanceWindow, error) { return nil, nil },
+		FindMaintenanceWindowsFn: func(context.Context, influxdb.MaintenanceWindowFilter) ([]*influxdb.MaintenanceWindow, error) {
+			return nil, nil
+		},
+		CreateMaintenanceWindowFn: func(context.Context, *influxdb.MaintenanceWindow) error { return nil },
+		UpdateMaintenanceWindowFn: func(_ context.Context, _ influxdb.ID, w *influxdb.MaintenanceWindow) (*influxdb.MaintenanceWindow, error) {
+			return w, nil
+		},
+		DeleteMaintenanceWindowFn: func(context.Context, influxdb.ID) error { return nil },
+	}
+}
+
+// FindMaintenanceWindowByID returns a single maintenance window by ID.
+func (s *MaintenanceWindowService) FindMaintenanceWindowByID(ctx context.Context, id influxdb.ID) (*influxdb.MaintenanceWindow, error) {
+	return s.FindMaintenanceWindowByIDFn(ctx, id)
+}
+
+// FindMaintenanceWindows returns the maintenance windows matching the filter.
+func (s *MaintenanceWindowService) FindMaintenanceWindows(ctx context.Context, filter influxdb.MaintenanceWindowFilter) ([]*influxdb.MaintenanceWindow, error) {
+	return s.FindMaintenanceWindowsFn(ctx, filter)
+}
+
+// CreateMaintenanceWindow creates a new maintenance window.
+func (s *MaintenanceWindowService) CreateMaintenanceWindow(ctx context.Context, w *influxdb.MaintenanceWindow) error {
+	return s.CreateMaintenanceWindowFn(ctx, w)
+}
+
+// UpdateMaintenanceWindow replaces the maintenance window with the ID.
+func (s *MaintenanceWindowService) UpdateMaintenanceWindow(ctx context.Context, id influxdb.ID, w *influxdb.MaintenanceWindow) (*influxdb.MaintenanceWindow, error) {
+	return s.UpdateMaintenanceWindowFn(ctx, id, w)
+}
+
+// DeleteMaintenanceWindow removes a maintenance window by ID.
+func (s *MaintenanceWindowService) DeleteMaintenanceWindow(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteMaintenanceWindowFn(ctx, id)
+}
@@ -20,6 +20,27 @@ type NotificationRuleStore struct {
 	DeleteNotificationRuleF   func(ctx context.Context, id influxdb.ID) error
 }
 
+// NewNotificationRuleStore returns a mock NotificationRuleStore where its
+// methods will return zero values.
+func NewNotificationRuleStore() *NotificationRuleStore {
+	return &NotificationRuleStore{
+		OrganizationService:        *NewOrganizationService(),
+		UserResourceMappingService: *NewUserResourceMappingService(),
+		FindNotificationRuleByIDF:  func(context.Context, influxdb.ID) (influxdb.NotificationRule, error) { return nil, nil },
+		FindNotificationRulesF: func(context.Context, influxdb.NotificationRuleFilter, ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+			return nil, 0, nil
+		},
+		CreateNotificationRuleF: func(context.Context, influxdb.NotificationRuleCreate, influxdb.ID) error { return nil },
+		UpdateNotificationRuleF: func(context.Context, influxdb.ID, influxdb.NotificationRuleCreate, influxdb.ID) (influxdb.NotificationRule, error) {
+			return nil, nil
+		},
+		PatchNotificationRuleF: func(context.Context, influxdb.ID, influxdb.NotificationRuleUpdate) (influxdb.NotificationRule, error) {
+			return nil, nil
+		},
+		DeleteNotificationRuleF: func(context.Context, influxdb.ID) error { return nil },
+	}
+}
+
 // FindNotificationRuleByID returns a single telegraf config by ID.
 func (s *NotificationRuleStore) FindNotificationRuleByID(ctx context.Context, id influxdb.ID) (influxdb.NotificationRule, error) {
 	return s.FindNotificationRuleByIDF(ctx, id)
@@ -12,34 +12,65 @@ var _ platform.ScraperTargetStoreService = &ScraperTargetStoreService{}
 type ScraperTargetStoreService struct {
 	UserResourceMappingService
 	OrganizationService
-	ListTargetsF   func(ctx context.Context, filter platform.ScraperTargetFilter) ([]platform.ScraperTarget, error)
-	AddTargetF     func(ctx context.Context, t *platform.ScraperTarget, userID platform.ID) error
-	GetTargetByIDF func(ctx context.Context, id platform.ID) (*platform.ScraperTarget, error)
-	RemoveTargetF  func(ctx context.Context, id platform.ID) error
-	UpdateTargetF  func(ctx context.Context, t *platform.ScraperTarget, userID platform.ID) (*platform.ScraperTarget, error)
+	ListTargetsF       func(ctx context.Context, filter platform.ScraperTargetFilter) ([]platform.ScraperTarget, error)
+	ListTargetsCalls   SafeCount
+	AddTargetF         func(ctx context.Context, t *platform.ScraperTarget, userID platform.ID) error
+	AddTargetCalls     SafeCount
+	GetTargetByIDF     func(ctx context.Context, id platform.ID) (*platform.ScraperTarget, error)
+	GetTargetByIDCalls SafeCount
+	RemoveTargetF      func(ctx context.Context, id platform.ID) error
+	RemoveTargetCalls  SafeCount
+	UpdateTargetF      func(ctx context.Context, t *platform.ScraperTarget, userID platform.ID) (*platform.ScraperTarget, error)
+	UpdateTargetCalls  SafeCount
+}
+
+// NewScraperTargetStoreService constructs a new fake ScraperTargetStoreService.
+func NewScraperTargetStoreService() *ScraperTargetStoreService {
+	return &ScraperTargetStoreService{
+		ListTargetsF: func(ctx context.Context, filter platform.ScraperTargetFilter) ([]platform.ScraperTarget, error) {
+			return nil, nil
+		},
+		AddTargetF: func(ctx context.Context, t *platform.ScraperTarget, userID platform.ID) error {
+			return nil
+		},
+		GetTargetByIDF: func(ctx context.Context, id platform.ID) (*platform.ScraperTarget, error) {
+			return nil, nil
+		},
+		RemoveTargetF: func(ctx context.Context, id platform.ID) error {
+			return nil
+		},
+		UpdateTargetF: func(ctx context.Context, t *platform.ScraperTarget, userID platform.ID) (*platform.ScraperTarget, error) {
+			return t, nil
+		},
+	}
 }
 
 // ListTargets lists all the scraper targets.
 func (s *ScraperTargetStoreService) ListTargets(ctx context.Context, filter platform.ScraperTargetFilter) ([]platform.ScraperTarget, error) {
+	defer s.ListTargetsCalls.IncrFn()()
 	return s.ListTargetsF(ctx, filter)
 }
 
 // AddTarget adds a scraper target.
 func (s *ScraperTargetStoreService) AddTarget(ctx context.Context, t *platform.ScraperTarget, userID platform.ID) error {
+	defer s.AddTargetCalls.IncrFn()()
 	return s.AddTargetF(ctx, t, userID)
 }
 
 // GetTargetByID retrieves a scraper target by id.
 func (s *ScraperTargetStoreService) GetTargetByID(ctx context.Context, id platform.ID) (*platform.ScraperTarget, error) {
+	defer s.GetTargetByIDCalls.IncrFn()()
 	return s.GetTargetByIDF(ctx, id)
 }
 
 // RemoveTarget deletes a scraper target.
 func (s *ScraperTargetStoreService) RemoveTarget(ctx context.Context, id platform.ID) error {
+	defer s.RemoveTargetCalls.IncrFn()()
 	return s.RemoveTargetF(ctx, id)
 }
 
 // UpdateTarget updates a scraper target.
 func (s *ScraperTargetStoreService) UpdateTarget(ctx context.Context, t *platform.ScraperTarget, userID platform.ID) (*platform.ScraperTarget, error) {
+	defer s.UpdateTargetCalls.IncrFn()()
 	return s.UpdateTargetF(ctx, t, userID)
 }
@@ -384,42 +384,75 @@ func parsePointsWithPrecision(buf []byte, mm []byte, defaultTime time.Time, prec
 		pos, block = scanLine(buf, pos)
 		pos++
 
+		block = trimLine(block)
 		if len(block) == 0 {
 			continue
 		}
 
-		// lines which start with '#' are comments
-		start := skipWhitespace(block, 0)
-
-		// If line is all whitespace, just skip it
-		if start >= len(block) {
-			continue
-		}
-
-		if block[start] == '#' {
-			continue
-		}
-
-		// strip the newline if one is present
-		if block[len(block)-1] == '\n' {
-			block = block[:len(block)-1]
-		}
-
-		points, err = parsePointsAppend(points, block[start:], mm, defaultTime, precision, rewrite)
+		points, err = parsePointsAppend(points, block, mm, defaultTime, precision, rewrite, nil)
 		if err != nil {
-			failed = append(failed, fmt.Sprintf("unable to parse '%s': %v", string(block[start:]), err))
+			failed = append(failed, parseLineError(block, err))
 		}
 	}
 	if len(failed) > 0 {
-		return points, fmt.Errorf("%s", strings.Join(failed, "\n"))
+		return points, parseLinesError(failed)
 	}
 
 	return points, nil
 }
 
-func parsePointsAppend(points []Point, buf []byte, mm []byte, defaultTime time.Time, precision string, rewrite bool) ([]Point, error) {
+// trimLine returns the point of a line found by scanLine, without its leading
+// whitespace and newline. Empty lines and comments have no point.
+func trimLine(block []byte) []byte {
+	if len(block) == 0 {
+		return nil
+	}
+
+	// lines which start with '#' are comments
+	start := skipWhitespace(block, 0)
+
+	// If line is all whitespace, just skip it
+	if start >= len(block) {
+		return nil
+	}
+
+	if block[start] == '#' {
+		return nil
+	}
+
+	// strip the newline if one is present
+	if block[len(block)-1] == '\n' {
+		block = block[:len(block)-1]
+	}
+	return block[start:]
+}
+
+// parseLineError is the failure of a line which failed to parse.
+func parseLineError(line []byte, err error) string {
+	return fmt.Sprintf("unable to parse '%s': %v", string(line), err)
+}
+
+// parseLinesError is the error of the lines which failed to parse.
+func parseLinesError(failed []string) error {
+	return fmt.Errorf("%s", strings.Join(failed, "\n"))
+}
+
+// parsePointsAppend parses the points of a line, appending them to points.
+// The keys and points are allocated from the arena, when there is one.
+func parsePointsAppend(points []Point, buf []byte, mm []byte, defaultTime time.Time, precision string, rewrite bool, a *pointsArena) ([]Point, error) {
 	// scan the first block which is measurement[,tag1=value1,tag2=value=2...]
-	pos, key, err := scanKey(buf, 0)
+	var (
+		pos int
+		key []byte
+		err error
+	)
+	if a != nil {
+		pos, key, a.indices, err = scanKeyIndices(buf, 0, a.indices)
+		// the indices are returned sliced to the tags when they were sorted
+		a.indices = a.indices[:cap(a.indices)]
+	} else {
+		pos, key, err = scanKey(buf, 0)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -494,17 +527,27 @@ func parsePointsAppend(points []Point, buf []byte, mm []byte, defaultTime time.T
 
 		// Build new key with measurement & field as keys.
 		if rewrite {
-			newKey = newV2Key(key, mm, k)
+			if a != nil {
+				newKey = writeV2Key(a.key(v2KeySize(key, mm, k)), key, mm, k)
+			} else {
+				newKey = newV2Key(key, mm, k)
+			}
 			if sz := seriesKeySizeV2(key, mm, k); sz > MaxKeyLength {
 				maxKeyErr = fmt.Errorf("max key length exceeded: %v > %v", sz, MaxKeyLength)
 				return false
 			}
 		}
 
-		other := pt
+		var other *point
+		if a != nil {
+			other = a.point()
+		} else {
+			other = new(point)
+		}
+		*other = pt
 		other.key = newKey
 		other.fields = fieldBuf
-		points = append(points, &other)
+		points = append(points, other)
 
 		return true
 	}); err != nil {
@@ -518,7 +561,16 @@ func parsePointsAppend(points []Point, buf []byte, mm []byte, defaultTime time.T
 
 // newV2Key returns a new key by converting the old measurement & field into keys.
 func newV2Key(oldKey, mm, field []byte) []byte {
-	newKey := make([]byte, len(mm)+1+len(MeasurementTagKey)+1+len(oldKey)+1+len(FieldKeyTagKey)+1+len(field))
+	return writeV2Key(make([]byte, v2KeySize(oldKey, mm, field)), oldKey, mm, field)
+}
+
+// v2KeySize returns the size of the key newV2Key returns.
+func v2KeySize(oldKey, mm, field []byte) int {
+	return len(mm) + 1 + len(MeasurementTagKey) + 1 + len(oldKey) + 1 + len(FieldKeyTagKey) + 1 + len(field)
+}
+
+// writeV2Key writes the key of newV2Key to newKey, which must be of its size.
+func writeV2Key(newKey, oldKey, mm, field []byte) []byte {
 	buf := newKey
 
 	copy(buf, mm)
@@ -552,6 +604,17 @@ func GetPrecisionMultiplier(precision string) int64 {
 // It returns the ending position and the byte slice of key within buf.  If there
 // are tags, they will be sorted if they are not already.
 func scanKey(buf []byte, i int) (int, []byte, error) {
+	// indices holds the indexes within buf of the start of each tag.  For example,
+	// a buf of 'cpu,host=a,region=b,zone=c' would have indices slice of [4,11,20]
+	// which indicates that the first tag starts at buf[4], seconds at buf[11], and
+	// last at buf[20]
+	i, key, _, err := scanKeyIndices(buf, i, make([]int, 100))
+	return i, key, err
+}
+
+// scanKeyIndices is scanKey with the indices of the tags given, it returns
+// the indices so they can be reused once grown.
+func scanKeyIndices(buf []byte, i int, indices []int) (int, []byte, []int, error) {
 	start := skipWhitespace(buf, i)
 
 	i = start
@@ -559,12 +622,6 @@ func scanKey(buf []byte, i int) (int, []byte, error) {
 	// Determines whether the tags are sort, assume they are
 	sorted := true
 
-	// indices holds the indexes within buf of the start of each tag.  For example,
-	// a buf of 'cpu,host=a,region=b,zone=c' would have indices slice of [4,11,20]
-	// which indicates that the first tag starts at buf[4], seconds at buf[11], and
-	// last at buf[20]
-	indices := make([]int, 100)
-
 	// tracks how many commas we've seen so we know how many values are indices.
 	// Since indices is an arbitrarily large slice,
 	// we need to know how many values in the buffer are in use.
@@ -573,14 +630,14 @@ func scanKey(buf []byte, i int) (int, []byte, error) {
 	// First scan the Point's measurement.
 	state, i, err := scanMeasurement(buf, i)
 	if err != nil {
-		return i, buf[start:i], err
+		return i, buf[start:i], indices, err
 	}
 
 	// Optionally scan tags if needed.
 	if state == tagKeyState {
 		i, commas, indices, err = scanTags(buf, i, indices)
 		if err != nil {
-			return i, buf[start:i], err
+			return i, buf[start:i], indices, err
 		}
 	}
 
@@ -601,7 +658,7 @@ func scanKey(buf []byte, i int) (int, []byte, error) {
 			sorted = false
 			break
 		} else if cmp == 0 {
-			return i, buf[start:i], fmt.Errorf("duplicate tags")
+			return i, buf[start:i], indices, fmt.Errorf("duplicate tags")
 		}
 	}
 
@@ -637,14 +694,14 @@ func scanKey(buf []byte, i int) (int, []byte, error) {
 			// If the tags are not sorted, this pass may not find duplicate tags and we
 			// need to do a more exhaustive search later.
 			if bytes.Equal(left, right) {
-				return i, b, fmt.Errorf("duplicate tags")
+				return i, b, indices, fmt.Errorf("duplicate tags")
 			}
 		}
 
-		return i, b, nil
+		return i, b, indices, nil
 	}
 
-	return i, buf[start:i], nil
+	return i, buf[start:i], indices, nil
 }
 
 // The following constants allow us to specify which state to move to
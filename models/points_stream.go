@@ -0,0 +1,203 @@
+package models
+
+import (
+	"io"
+	"time"
+)
+
+const (
+	// DefaultStreamChunkSize is the size of the chunks a StreamParser reads
+	// its input in.
+	DefaultStreamChunkSize = 64 * 1024
+
+	// DefaultStreamBatchSize is the number of points of the batches a
+	// StreamParser returns.
+	DefaultStreamBatchSize = 5000
+
+	// arenaKeyBytes and arenaPoints are the sizes of the blocks a pointsArena
+	// allocates the keys and points from.
+	arenaKeyBytes = 64 * 1024
+	arenaPoints   = 512
+)
+
+// StreamReadError is the error of a StreamParser failing to read its input.
+type StreamReadError struct {
+	Err error
+}
+
+func (e *StreamReadError) Error() string {
+	return "unable to read line protocol: " + e.Err.Error()
+}
+
+// pointsArena allocates the keys and points of the lines parsed in blocks,
+// rather than one by one. The blocks are never reused, the keys and points
+// allocated from them are owned by the points returned.
+type pointsArena struct {
+	// indices are the tag indices of the key scanned, reused for each line.
+	indices []int
+
+	keys   []byte
+	points []point
+}
+
+func newPointsArena() *pointsArena {
+	return &pointsArena{indices: make([]int, 100)}
+}
+
+// key returns a key of n bytes.
+func (a *pointsArena) key(n int) []byte {
+	if cap(a.keys)-len(a.keys) < n {
+		size := arenaKeyBytes
+		if n > size {
+			size = n
+		}
+		a.keys = make([]byte, 0, size)
+	}
+	start := len(a.keys)
+	a.keys = a.keys[:start+n]
+	return a.keys[start : start+n : start+n]
+}
+
+// point returns a zero point.
+func (a *pointsArena) point() *point {
+	if len(a.points) == cap(a.points) {
+		a.points = make([]point, 0, arenaPoints)
+	}
+	a.points = a.points[:len(a.points)+1]
+	return &a.points[len(a.points)-1]
+}
+
+// StreamParser parses the line protocol of a reader into batches of points,
+// without reading the whole of the input first. The input is read in chunks
+// and the points refer to the chunks they are parsed from, the keys and
+// points are allocated in blocks, so parsing allocates per chunk and block
+// rather than per line. A line spanning chunks, i.e. a long line or a string
+// field with a newline in it, is carried over to the next chunk.
+//
+// For input which parses, the points are those ParsePointsWithPrecision
+// returns for the whole of the input. The error of the lines which fail to
+// parse is the same as its error.
+type StreamParser struct {
+	// ChunkSize is the size of the chunks the input is read in, a chunk is
+	// grown to hold a line longer than it.
+	ChunkSize int
+	// BatchSize is the number of points a batch is returned at, the batch
+	// ends with the line that reaches it.
+	BatchSize int
+
+	r           io.Reader
+	mm          []byte
+	defaultTime time.Time
+	precision   string
+
+	// buf is the chunk being parsed, the lines from pos are yet to be parsed.
+	buf   []byte
+	pos   int
+	eof   bool
+	bytes int
+
+	arena  *pointsArena
+	failed []string
+}
+
+// NewStreamParser returns a parser of the line protocol of r. The mm, default
+// time and precision are those of ParsePointsWithPrecision.
+func NewStreamParser(r io.Reader, mm []byte, defaultTime time.Time, precision string) *StreamParser {
+	return &StreamParser{
+		ChunkSize:   DefaultStreamChunkSize,
+		BatchSize:   DefaultStreamBatchSize,
+		r:           r,
+		mm:          mm,
+		defaultTime: defaultTime,
+		precision:   precision,
+		arena:       newPointsArena(),
+	}
+}
+
+// BytesRead returns the number of bytes of the input read so far.
+func (p *StreamParser) BytesRead() int {
+	return p.bytes
+}
+
+// Next returns the next batch of points. Once the input is parsed it returns
+// io.EOF, or the error of the lines that failed to parse if any did. A failed
+// read of the input is returned as a *StreamReadError.
+func (p *StreamParser) Next() ([]Point, error) {
+	batchSize := p.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultStreamBatchSize
+	}
+
+	points := make([]Point, 0, batchSize)
+	for len(points) < batchSize {
+		if p.pos >= len(p.buf) {
+			if p.eof {
+				break
+			}
+			if err := p.read(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		end, block := scanLine(p.buf, p.pos)
+		// the line is only known to end at its newline when a byte follows
+		// it, scanLine skips a newline escaped by the byte before it only
+		// when there is one.
+		if !p.eof && end >= len(p.buf)-1 {
+			if err := p.read(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		p.pos = end + 1
+
+		block = trimLine(block)
+		if len(block) == 0 {
+			continue
+		}
+
+		var err error
+		points, err = parsePointsAppend(points, block, p.mm, p.defaultTime, p.precision, true, p.arena)
+		if err != nil {
+			p.failed = append(p.failed, parseLineError(block, err))
+		}
+	}
+
+	if len(points) > 0 {
+		return points, nil
+	}
+	if len(p.failed) > 0 {
+		return nil, parseLinesError(p.failed)
+	}
+	return nil, io.EOF
+}
+
+// read reads the next chunk, carrying over the lines of the chunk yet to be
+// parsed. The chunk is a new one, the points parsed refer to the previous one.
+func (p *StreamParser) read() error {
+	rest := p.buf[p.pos:]
+	size := p.ChunkSize
+	if size < 2*len(rest) {
+		size = 2 * len(rest)
+	}
+	if size <= 0 {
+		size = DefaultStreamChunkSize
+	}
+
+	buf := make([]byte, size)
+	copy(buf, rest)
+	n, err := io.ReadFull(p.r, buf[len(rest):])
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		p.eof = true
+	default:
+		return &StreamReadError{Err: err}
+	}
+
+	p.bytes += n
+	p.buf = buf[:len(rest)+n]
+	p.pos = 0
+	return nil
+}
@@ -0,0 +1,254 @@
+package models_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+var streamTestMM = []byte("0000000000000001_0000000000000002")
+
+// parseStream parses buf with a StreamParser reading it in chunks of
+// chunkSize, returning the points of all of its batches.
+func parseStream(buf []byte, chunkSize, batchSize int, defaultTime time.Time, precision string) ([]models.Point, error) {
+	p := models.NewStreamParser(bytes.NewReader(buf), streamTestMM, defaultTime, precision)
+	p.ChunkSize = chunkSize
+	p.BatchSize = batchSize
+
+	var points []models.Point
+	for {
+		batch, err := p.Next()
+		if err == io.EOF {
+			return points, nil
+		}
+		if err != nil {
+			return points, err
+		}
+		points = append(points, batch...)
+	}
+}
+
+// assertStreamEquivalent asserts the stream parser parses buf the way
+// ParsePointsWithPrecision does, whatever the chunk and batch size.
+func assertStreamEquivalent(t *testing.T, buf []byte, chunkSizes ...int) {
+	t.Helper()
+
+	now := time.Unix(0, 1000)
+	exp, expErr := models.ParsePointsWithPrecision(buf, streamTestMM, now, "ns")
+	for _, chunkSize := range chunkSizes {
+		for _, batchSize := range []int{1, 3, models.DefaultStreamBatchSize} {
+			got, err := parseStream(buf, chunkSize, batchSize, now, "ns")
+			if (err == nil) != (expErr == nil) || (err != nil && err.Error() != expErr.Error()) {
+				t.Fatalf("chunk size %d, batch size %d: got error %v, exp %v; input %q", chunkSize, batchSize, err, expErr, buf)
+			}
+			// the points parsed before a line with an invalid key are dropped
+			// by ParsePointsWithPrecision, points are only compared for input
+			// which parses.
+			if expErr != nil {
+				continue
+			}
+			if len(got) != len(exp) {
+				t.Fatalf("chunk size %d, batch size %d: got %d points, exp %d; input %q", chunkSize, batchSize, len(got), len(exp), buf)
+			}
+			for i := range exp {
+				if got, exp := got[i].String(), exp[i].String(); got != exp {
+					t.Fatalf("chunk size %d, batch size %d: point %d: got %q, exp %q", chunkSize, batchSize, i, got, exp)
+				}
+			}
+		}
+	}
+}
+
+func TestStreamParser(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "empty"},
+		{name: "single point", in: "cpu,host=a value=1i 1000000000"},
+		{name: "trailing newline", in: "cpu,host=a value=1i 1000000000\n"},
+		{name: "many fields", in: "cpu,host=a,region=b user=1,system=2i,idle=3u,ok=true,msg=\"hi\" 1000000000\ncpu,host=b user=4 2000000000\n"},
+		{name: "default time", in: "cpu value=1\ncpu value=2\n"},
+		{name: "comments and blank lines", in: "# comment\n\n   \ncpu value=1\n  # indented comment\n\t\ncpu value=2"},
+		{name: "unsorted tags", in: "cpu,zone=c,host=a,region=b value=1 1\n"},
+		{name: "escaped characters", in: "c\\ pu,ho\\,st=a\\ b,reg\\=ion=c value=1 1\ncpu=x,host=a value=2 2\n"},
+		{name: "string with newlines", in: "cpu str=\"line 1\nline 2\nline 3\" 1\ncpu str=\"a\\\"\nb\" 2\n"},
+		{name: "escaped newline", in: "cpu value=1 1\\\ncpu value=2 2\n"},
+		{name: "carriage returns", in: "cpu value=1 1\r\ncpu value=2 2\r\n"},
+		{name: "missing fields", in: "cpu value=1 1\ncpu\ncpu value=2 2\n"},
+		{name: "invalid key", in: "cpu value=1 1\n,host=a value=2 2\ncpu value=3 3\n"},
+		{name: "duplicate tags", in: "cpu,host=a,host=b value=1 1\n"},
+		{name: "invalid timestamp", in: "cpu value=1 1a\ncpu value=2 2\n"},
+		{name: "unterminated string", in: "cpu value=1 1\ncpu str=\"open 2\n"},
+		{name: "long line", in: "cpu,host=" + strings.Repeat("a", 300) + " value=1 1\ncpu value=2 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertStreamEquivalent(t, []byte(tt.in), 1, 2, 3, 7, 16, 64, models.DefaultStreamChunkSize)
+		})
+	}
+}
+
+func TestStreamParser_Batches(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&buf, "cpu,host=a user=%d,system=%d %d\n", i, i, i)
+	}
+
+	p := models.NewStreamParser(&buf, streamTestMM, time.Now(), "ns")
+	p.ChunkSize = 16
+	p.BatchSize = 5
+
+	var sizes []int
+	for {
+		batch, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(batch))
+	}
+
+	// the batch ends with the line that reaches the batch size.
+	if got, exp := fmt.Sprint(sizes), "[6 6 6 2]"; got != exp {
+		t.Fatalf("got batches of %s points, exp %s", got, exp)
+	}
+	if got, exp := p.BytesRead(), 10*len("cpu,host=a user=0,system=0 0\n"); got != exp {
+		t.Fatalf("got %d bytes read, exp %d", got, exp)
+	}
+}
+
+func TestStreamParser_ReadError(t *testing.T) {
+	readErr := errors.New("connection reset")
+	p := models.NewStreamParser(&errAfterReader{r: strings.NewReader("cpu value=1 1\ncpu"), err: readErr}, streamTestMM, time.Now(), "ns")
+	p.ChunkSize = 4
+
+	for {
+		_, err := p.Next()
+		if err == nil {
+			continue
+		}
+		rerr, ok := err.(*models.StreamReadError)
+		if !ok {
+			t.Fatalf("got error %v, exp a *models.StreamReadError", err)
+		}
+		if rerr.Err != readErr {
+			t.Fatalf("got read error %v, exp %v", rerr.Err, readErr)
+		}
+		return
+	}
+}
+
+// errAfterReader returns err once r is read.
+type errAfterReader struct {
+	r   io.Reader
+	err error
+}
+
+func (e *errAfterReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err == io.EOF {
+		return n, e.err
+	}
+	return n, err
+}
+
+// TestStreamParser_Fuzz compares the stream parser with ParsePointsWithPrecision
+// on random line protocol, mutated into invalid line protocol half the time.
+func TestStreamParser_Fuzz(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	pieces := []string{
+		"cpu", "mem", "c\\ pu", "m=1", ",", ",host=a", ",region=b", ",host=a\\ b", ",zone=c",
+		" ", " value=1", " v=1i", " s=\"x\"", " s=\"a\nb\"", " s=\"q\\\"\"", ",f=true", ",g=2.5", ",u=3u",
+		" 1", " 1000000000", " -5", "\n", "\n", "\n", "\r\n", "#", "\\", "\"", "=", "\t",
+	}
+	valid := func() string {
+		var b strings.Builder
+		b.WriteString([]string{"cpu", "mem", "c\\ pu", "m=1"}[rnd.Intn(4)])
+		for _, tag := range []string{",host=a", ",region=b", ",host=a\\ b", ",zone=c"}[:rnd.Intn(3)] {
+			b.WriteString(tag)
+		}
+		b.WriteString([]string{" value=1", " v=1i", " s=\"x\"", " s=\"a\nb\""}[rnd.Intn(4)])
+		b.WriteString([]string{"", ",f=true", ",g=2.5"}[rnd.Intn(3)])
+		b.WriteString([]string{"", " 1", " 1000000000"}[rnd.Intn(3)])
+		return b.String()
+	}
+
+	for i := 0; i < 2000; i++ {
+		var b strings.Builder
+		for n := rnd.Intn(8); n >= 0; n-- {
+			b.WriteString(valid())
+			b.WriteByte('\n')
+		}
+		in := []byte(b.String())
+
+		if rnd.Intn(2) == 0 {
+			for n := rnd.Intn(4); n >= 0; n-- {
+				piece := pieces[rnd.Intn(len(pieces))]
+				at := rnd.Intn(len(in) + 1)
+				in = append(in[:at:at], append([]byte(piece), in[at:]...)...)
+			}
+		}
+
+		assertStreamEquivalent(t, in, 1+rnd.Intn(8), 1+rnd.Intn(64))
+	}
+}
+
+func BenchmarkParsePoints10k(b *testing.B) {
+	buf := streamBenchmarkLines(10000)
+	mm := streamTestMM
+	now := time.Now()
+
+	b.Run("ParsePointsWithPrecision", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(buf)))
+		for i := 0; i < b.N; i++ {
+			// the body is read whole first, as the write handler did.
+			var body bytes.Buffer
+			if _, err := body.ReadFrom(bytes.NewReader(buf)); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := models.ParsePointsWithPrecision(body.Bytes(), mm, now, "ns"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("StreamParser", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(buf)))
+		for i := 0; i < b.N; i++ {
+			p := models.NewStreamParser(bytes.NewReader(buf), mm, now, "ns")
+			for {
+				_, err := p.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+// streamBenchmarkLines returns n lines of line protocol, with the tags and
+// fields of a representative write of telegraf.
+func streamBenchmarkLines(n int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, "cpu,cpu=cpu%d,host=server%02d,region=us-west usage_user=%d.5,usage_system=%d.25,usage_idle=90.5 %d\n",
+			i%8, i%16, i%100, i%50, 1500000000000000000+int64(i)*int64(time.Second))
+	}
+	return buf.Bytes()
+}
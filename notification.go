@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"encoding/json"
+	"time"
 )
 
 // NotificationRule is a *Query* of a *Status Bucket* that returns the *Status*.
@@ -136,3 +137,52 @@ func (n *NotificationRuleUpdate) Valid() error {
 
 	return nil
 }
+
+// NotificationRuleHistoryEntry is the outcome of a single evaluation of a
+// notification rule.
+type NotificationRuleHistoryEntry struct {
+	RuleID ID        `json:"ruleID,omitempty"`
+	TaskID ID        `json:"taskID"`
+	RunID  ID        `json:"runID"`
+	Time   time.Time `json:"time"`
+	// StatusesMatched is the number of statuses that matched the rule, each
+	// matched status results in a notification being attempted.
+	StatusesMatched int                   `json:"statusesMatched"`
+	Error           string                `json:"error,omitempty"`
+	Notifications   []NotificationAttempt `json:"notifications"`
+	// Muted are the maintenance windows that muted notifications of the
+	// evaluation. The statuses muted are not sent, they are recorded as not
+	// sent in the notification log.
+	Muted []NotificationMute `json:"muted,omitempty"`
+}
+
+// NotificationAttempt is a notification sent to an endpoint while evaluating a
+// notification rule. Secrets are redacted from the captured payload.
+type NotificationAttempt struct {
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Payload    string `json:"payload"`
+	Truncated  bool   `json:"truncated,omitempty"`
+}
+
+// NotificationMute is a maintenance window muting notifications of an
+// evaluation of a notification rule.
+type NotificationMute struct {
+	WindowID ID     `json:"windowID"`
+	Reason   string `json:"reason"`
+	// CheckIDs are the checks whose statuses were muted, empty if the window
+	// muted every status of the rule.
+	CheckIDs []ID `json:"checkIDs,omitempty"`
+}
+
+// NotificationRuleHistoryService records and retrieves the outcomes of the
+// evaluations of notification rules.
+type NotificationRuleHistoryService interface {
+	// AddNotificationRuleHistory records the outcome of an evaluation of the
+	// notification rule executed by the entry's task.
+	AddNotificationRuleHistory(ctx context.Context, e *NotificationRuleHistoryEntry) error
+
+	// FindNotificationRuleHistory returns the most recent evaluations of a
+	// notification rule, newest first.
+	FindNotificationRuleHistory(ctx context.Context, ruleID ID) ([]*NotificationRuleHistoryEntry, error)
+}
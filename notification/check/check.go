@@ -132,6 +132,11 @@ func (b Base) GetTaskID() influxdb.ID {
 	return b.TaskID
 }
 
+// GetTags returns the tags added to the statuses of the check.
+func (b Base) GetTags() []influxdb.Tag {
+	return b.Tags
+}
+
 // GetCRUDLog implements influxdb.Getter interface.
 func (b Base) GetCRUDLog() influxdb.CRUDLog {
 	return b.CRUDLog
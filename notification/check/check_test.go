@@ -122,6 +122,16 @@ func TestValidCheck(t *testing.T) {
 				Msg:  "tag must contain a key and a value",
 			},
 		},
+		{
+			name: "deadman without stale time",
+			src: &check.Deadman{
+				Base: goodBase,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Deadman check staleTime is required",
+			},
+		},
 		{
 			name: "bad thredshold",
 			src: &check.Threshold{
@@ -30,6 +30,20 @@ func (c Deadman) Type() string {
 	return "deadman"
 }
 
+// Valid returns err if the check is invalid.
+func (c Deadman) Valid() error {
+	if err := c.Base.Valid(); err != nil {
+		return err
+	}
+	if c.StaleTime == nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Deadman check staleTime is required",
+		}
+	}
+	return nil
+}
+
 // GenerateFlux returns a flux script for the Deadman provided.
 func (c Deadman) GenerateFlux() (string, error) {
 	p, err := c.GenerateFluxAST()
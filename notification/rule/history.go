@@ -0,0 +1,208 @@
+package rule
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/influxdb"
+)
+
+// MaxHistoryPayload is the number of bytes of a notification's payload that are
+// captured in the history of a notification rule.
+const MaxHistoryPayload = 1024
+
+const redacted = "[REDACTED]"
+
+type historyContextKey struct{}
+
+// History collects the notifications attempted during a single evaluation of a
+// notification rule. The flux dependencies used to send notifications report
+// to the History found in the context of the query.
+type History struct {
+	mu       sync.Mutex
+	secrets  []string
+	attempts []influxdb.NotificationAttempt
+	mutes    []influxdb.NotificationMute
+}
+
+// NewHistoryContext returns a context carrying a new History, the returned
+// History collects the notifications sent by queries executed with the context.
+func NewHistoryContext(ctx context.Context) (context.Context, *History) {
+	h := new(History)
+	return context.WithValue(ctx, historyContextKey{}, h), h
+}
+
+// HistoryFromContext returns the History of the context, nil if there is none.
+func HistoryFromContext(ctx context.Context) *History {
+	h, _ := ctx.Value(historyContextKey{}).(*History)
+	return h
+}
+
+// Attempts returns the notifications attempted.
+func (h *History) Attempts() []influxdb.NotificationAttempt {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	attempts := make([]influxdb.NotificationAttempt, len(h.attempts))
+	copy(attempts, h.attempts)
+	return attempts
+}
+
+// Mute records the maintenance windows muting notifications of the
+// evaluation. The notifications are muted by the query of the rule, see
+// MuteNotifications, the History only records what was muted.
+func (h *History) Mute(m *influxdb.NotificationRuleMute) {
+	if !m.Muted() {
+		return
+	}
+
+	var mutes []influxdb.NotificationMute
+	if w := m.Rule; w != nil {
+		mutes = append(mutes, influxdb.NotificationMute{
+			WindowID: w.ID,
+			Reason:   w.MuteReason(),
+		})
+	} else {
+		byWindow := make(map[influxdb.ID]int)
+		for _, id := range sortedCheckIDs(m) {
+			w := m.Checks[id]
+			i, ok := byWindow[w.ID]
+			if !ok {
+				i = len(mutes)
+				byWindow[w.ID] = i
+				mutes = append(mutes, influxdb.NotificationMute{
+					WindowID: w.ID,
+					Reason:   w.MuteReason(),
+				})
+			}
+			mutes[i].CheckIDs = append(mutes[i].CheckIDs, id)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mutes = append(h.mutes, mutes...)
+}
+
+// Mutes returns the maintenance windows that muted notifications.
+func (h *History) Mutes() []influxdb.NotificationMute {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.mutes) == 0 {
+		return nil
+	}
+	mutes := make([]influxdb.NotificationMute, len(h.mutes))
+	copy(mutes, h.mutes)
+	return mutes
+}
+
+func (h *History) addSecret(v string) {
+	if v == "" {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.secrets = append(h.secrets, v)
+}
+
+// addAttempt records a notification. The payload has any secret loaded while
+// evaluating the rule redacted before it is truncated, so that a truncated
+// payload never contains part of a secret.
+func (h *History) addAttempt(payload []byte, statusCode int, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	p := string(payload)
+	for _, s := range h.secrets {
+		p = strings.Replace(p, s, redacted, -1)
+	}
+
+	attempt := influxdb.NotificationAttempt{
+		StatusCode: statusCode,
+		Payload:    p,
+	}
+	if len(p) > MaxHistoryPayload {
+		attempt.Payload, attempt.Truncated = p[:MaxHistoryPayload], true
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	h.attempts = append(h.attempts, attempt)
+}
+
+// HTTPClient is the http client flux uses to send notifications.
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// NewHistoryHTTPClient wraps the http client provided to flux, the requests it
+// sends on behalf of a query with a History are recorded in the History.
+func NewHistoryHTTPClient(c HTTPClient) HTTPClient {
+	return &historyHTTPClient{c: c}
+}
+
+type historyHTTPClient struct {
+	c HTTPClient
+}
+
+func (c *historyHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	h := HistoryFromContext(req.Context())
+	if h == nil {
+		return c.c.Do(req)
+	}
+
+	var payload []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			h.addAttempt(nil, 0, err)
+			return nil, err
+		}
+		payload = b
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	}
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		h.addAttempt(payload, 0, err)
+		return nil, err
+	}
+	h.addAttempt(payload, resp.StatusCode, nil)
+	return resp, nil
+}
+
+// SecretService is the secret service flux uses to load the secrets
+// referenced by a notification endpoint.
+type SecretService interface {
+	LoadSecret(ctx context.Context, k string) (string, error)
+}
+
+// NewHistorySecretService wraps the secret service provided to flux, the secrets
+// it loads on behalf of a query with a History are redacted from the payloads
+// captured by the History.
+func NewHistorySecretService(s SecretService) SecretService {
+	return &historySecretService{s: s}
+}
+
+type historySecretService struct {
+	s SecretService
+}
+
+func (s *historySecretService) LoadSecret(ctx context.Context, k string) (string, error) {
+	v, err := s.s.LoadSecret(ctx, k)
+	if err != nil {
+		return "", err
+	}
+
+	if h := HistoryFromContext(ctx); h != nil {
+		h.addSecret(v)
+	}
+	return v, nil
+}
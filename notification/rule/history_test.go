@@ -0,0 +1,137 @@
+package rule_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification/rule"
+)
+
+type secretsFn func(ctx context.Context, k string) (string, error)
+
+func (fn secretsFn) LoadSecret(ctx context.Context, k string) (string, error) {
+	return fn(ctx, k)
+}
+
+func TestHistory(t *testing.T) {
+	var received string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		received = string(b)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	client := rule.NewHistoryHTTPClient(http.DefaultClient)
+	secrets := rule.NewHistorySecretService(secretsFn(func(ctx context.Context, k string) (string, error) {
+		return "s3cr3t-routing-key", nil
+	}))
+
+	send := func(t *testing.T, ctx context.Context, payload string) {
+		t.Helper()
+
+		key, err := secrets.LoadSecret(ctx, "routing_key")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, receiver.URL, strings.NewReader(strings.Replace(payload, "$KEY", key, -1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	t.Run("captures failed notifications with secrets redacted", func(t *testing.T) {
+		ctx, hist := rule.NewHistoryContext(context.Background())
+
+		send(t, ctx, `{"routing_key":"$KEY","summary":"crit"}`)
+
+		if received != `{"routing_key":"s3cr3t-routing-key","summary":"crit"}` {
+			t.Fatalf("receiver got unexpected payload: %s", received)
+		}
+
+		attempts := hist.Attempts()
+		if len(attempts) != 1 {
+			t.Fatalf("expected 1 attempt, got %d", len(attempts))
+		}
+
+		got := attempts[0]
+		if got.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected status code %d, got %d", http.StatusServiceUnavailable, got.StatusCode)
+		}
+		if expected := `{"routing_key":"[REDACTED]","summary":"crit"}`; got.Payload != expected {
+			t.Errorf("expected payload %s, got %s", expected, got.Payload)
+		}
+		if got.Truncated {
+			t.Error("expected payload to not be truncated")
+		}
+	})
+
+	t.Run("truncates large payloads", func(t *testing.T) {
+		ctx, hist := rule.NewHistoryContext(context.Background())
+
+		send(t, ctx, strings.Repeat("a", rule.MaxHistoryPayload+1))
+
+		attempts := hist.Attempts()
+		if len(attempts) != 1 {
+			t.Fatalf("expected 1 attempt, got %d", len(attempts))
+		}
+		if got := attempts[0]; !got.Truncated || len(got.Payload) != rule.MaxHistoryPayload {
+			t.Errorf("expected payload truncated to %d bytes, got %d", rule.MaxHistoryPayload, len(got.Payload))
+		}
+	})
+
+	t.Run("captures unreachable receivers", func(t *testing.T) {
+		ctx, hist := rule.NewHistoryContext(context.Background())
+
+		req, err := http.NewRequest(http.MethodPost, "http://127.0.0.1:0", strings.NewReader("payload"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Do(req.WithContext(ctx)); err == nil {
+			t.Fatal("expected an error")
+		}
+
+		attempts := hist.Attempts()
+		if len(attempts) != 1 || attempts[0].Error == "" || attempts[0].Payload != "payload" {
+			t.Errorf("unexpected attempts: %+v", attempts)
+		}
+	})
+
+	t.Run("records the windows muting notifications by window", func(t *testing.T) {
+		_, hist := rule.NewHistoryContext(context.Background())
+		patching := &influxdb.MaintenanceWindow{ID: 1, Name: "patching"}
+		upgrade := &influxdb.MaintenanceWindow{ID: 2, Name: "upgrade"}
+		hist.Mute(&influxdb.NotificationRuleMute{
+			Checks: map[influxdb.ID]*influxdb.MaintenanceWindow{
+				12: upgrade,
+				10: patching,
+				11: patching,
+			},
+		})
+
+		want := []influxdb.NotificationMute{
+			{WindowID: 1, Reason: patching.MuteReason(), CheckIDs: []influxdb.ID{10, 11}},
+			{WindowID: 2, Reason: upgrade.MuteReason(), CheckIDs: []influxdb.ID{12}},
+		}
+		if got := hist.Mutes(); !reflect.DeepEqual(got, want) {
+			t.Errorf("unexpected mutes:\n\twant: %+v\n\tgot:  %+v", want, got)
+		}
+	})
+
+	t.Run("ignores requests without a history", func(t *testing.T) {
+		send(t, context.Background(), "payload")
+	})
+}
@@ -0,0 +1,163 @@
+package rule
+
+import (
+	"sort"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification/flux"
+)
+
+// MuteNotifications rewrites the query of a notification rule so that the
+// statuses muted are not sent to the endpoint of the rule. The statuses muted
+// still pass through monitor.notify, with an endpoint that sends nothing, so
+// they are recorded as not sent in the notification log. A rule muted by its
+// own labels has every status muted, otherwise only the statuses of the muted
+// checks are, the statuses of the other checks are sent as usual.
+func MuteNotifications(pkg *ast.Package, m *influxdb.NotificationRuleMute) {
+	if !m.Muted() {
+		return
+	}
+
+	var ids []ast.Expression
+	for _, id := range sortedCheckIDs(m) {
+		ids = append(ids, flux.String(id.String()))
+	}
+
+	for _, f := range pkg.Files {
+		var (
+			body  []ast.Statement
+			muted bool
+		)
+		for _, st := range f.Body {
+			notify, ok := notifyPipe(st)
+			if !ok {
+				body = append(body, st)
+				continue
+			}
+			muted = true
+
+			if m.Rule != nil {
+				body = append(body, flux.ExpressionStatement(
+					flux.Pipe(notify.Argument, mutedNotify(notify.Call)),
+				))
+				continue
+			}
+			body = append(body,
+				flux.ExpressionStatement(flux.Pipe(
+					notify.Argument.Copy().(ast.Expression),
+					filterChecks(ids, false),
+					notify.Call,
+				)),
+				flux.ExpressionStatement(flux.Pipe(
+					notify.Argument.Copy().(ast.Expression),
+					filterChecks(ids, true),
+					mutedNotify(notify.Call),
+				)),
+			)
+		}
+		f.Body = body
+
+		if muted && !hasImport(f, "experimental") {
+			f.Imports = append(f.Imports, flux.ImportDeclaration("experimental"))
+		}
+	}
+}
+
+func sortedCheckIDs(m *influxdb.NotificationRuleMute) []influxdb.ID {
+	ids := make([]influxdb.ID, 0, len(m.Checks))
+	for id := range m.Checks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// notifyPipe returns the pipe of the statuses of the rule into monitor.notify.
+func notifyPipe(st ast.Statement) (*ast.PipeExpression, bool) {
+	es, ok := st.(*ast.ExpressionStatement)
+	if !ok {
+		return nil, false
+	}
+	pe, ok := es.Expression.(*ast.PipeExpression)
+	if !ok || pe.Call == nil {
+		return nil, false
+	}
+	me, ok := pe.Call.Callee.(*ast.MemberExpression)
+	if !ok {
+		return nil, false
+	}
+	obj, ok := me.Object.(*ast.Identifier)
+	if !ok || obj.Name != "monitor" {
+		return nil, false
+	}
+	prop, ok := me.Property.(*ast.Identifier)
+	return pe, ok && prop.Name == "notify"
+}
+
+// filterChecks returns the filter keeping the statuses of the checks, or the
+// statuses of every other check when muted is false.
+func filterChecks(ids []ast.Expression, muted bool) *ast.CallExpression {
+	var test ast.Expression = flux.Call(
+		flux.Identifier("contains"),
+		flux.Object(
+			flux.Property("value", flux.Member("r", "_check_id")),
+			flux.Property("set", flux.Array(ids...)),
+		),
+	)
+	if !muted {
+		test = &ast.UnaryExpression{
+			Operator: ast.NotOperator,
+			Argument: test,
+		}
+	}
+	return flux.Call(
+		flux.Identifier("filter"),
+		flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), test))),
+	)
+}
+
+// mutedNotify returns the call of monitor.notify with its endpoint replaced by
+// one that marks the statuses as not sent without sending them.
+func mutedNotify(call *ast.CallExpression) *ast.CallExpression {
+	muted := call.Copy().(*ast.CallExpression)
+	endpoint := &ast.FunctionExpression{
+		Params: []*ast.Property{{
+			Key:   flux.Identifier("tables"),
+			Value: &ast.PipeLiteral{},
+		}},
+		Body: flux.Pipe(
+			flux.Identifier("tables"),
+			flux.Call(
+				flux.Member("experimental", "set"),
+				flux.Object(flux.Property("o", flux.Object(
+					flux.Property("_sent", flux.String("false")),
+				))),
+			),
+		),
+	}
+
+	for _, arg := range muted.Arguments {
+		obj, ok := arg.(*ast.ObjectExpression)
+		if !ok {
+			continue
+		}
+		for _, p := range obj.Properties {
+			if k, ok := p.Key.(*ast.Identifier); ok && k.Name == "endpoint" {
+				p.Value = endpoint
+			}
+		}
+	}
+	return muted
+}
+
+func hasImport(f *ast.File, path string) bool {
+	for _, imp := range f.Imports {
+		if imp.Path != nil && imp.Path.Value == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package rule_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/rule"
+)
+
+func TestMuteNotifications(t *testing.T) {
+	id := influxdb.ID(2)
+	e := &endpoint.HTTP{
+		Base: endpoint.Base{
+			ID:   &id,
+			Name: "foo",
+		},
+		URL: "http://localhost:7777",
+	}
+	r := &rule.HTTP{
+		Base: rule.Base{
+			ID:         1,
+			Name:       "foo",
+			Every:      mustDuration("1h"),
+			EndpointID: 2,
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Critical,
+				},
+			},
+		},
+	}
+	script, err := r.GenerateFlux(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const notify = `
+all_statuses
+	|> monitor.notify(data: notification, endpoint: endpoint(mapFn: (r) => {
+		body = {r with _version: 1}
+
+		return {headers: headers, data: json.encode(v: body)}
+	}))`
+
+	window := &influxdb.MaintenanceWindow{ID: 3}
+	tests := []struct {
+		name string
+		mute *influxdb.NotificationRuleMute
+		want string
+	}{
+		{
+			name: "nothing muted",
+			want: notify,
+		},
+		{
+			name: "every status muted",
+			mute: &influxdb.NotificationRuleMute{Rule: window},
+			want: `
+all_statuses
+	|> monitor.notify(data: notification, endpoint: (tables=<-) =>
+		(tables
+			|> experimental.set(o: {_sent: "false"})))`,
+		},
+		{
+			name: "statuses of checks muted",
+			mute: &influxdb.NotificationRuleMute{
+				Checks: map[influxdb.ID]*influxdb.MaintenanceWindow{5: window, 4: window},
+			},
+			want: `
+all_statuses
+	|> filter(fn: (r) =>
+		(not contains(value: r._check_id, set: ["0000000000000004", "0000000000000005"])))
+	|> monitor.notify(data: notification, endpoint: endpoint(mapFn: (r) => {
+		body = {r with _version: 1}
+
+		return {headers: headers, data: json.encode(v: body)}
+	}))
+all_statuses
+	|> filter(fn: (r) =>
+		(contains(value: r._check_id, set: ["0000000000000004", "0000000000000005"])))
+	|> monitor.notify(data: notification, endpoint: (tables=<-) =>
+		(tables
+			|> experimental.set(o: {_sent: "false"})))`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg, err := flux.Parse(script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rule.MuteNotifications(pkg, tt.mute)
+
+			if _, err := semantic.New(pkg); err != nil {
+				t.Fatalf("muted script is invalid: %v", err)
+			}
+			f := ast.Format(pkg.Files[0])
+			got := f[strings.Index(f, "\n\nall_statuses\n")+1:]
+			if got != tt.want {
+				t.Errorf("scripts did not match. want:\n%v\n\ngot:\n%v", tt.want, got)
+			}
+		})
+	}
+}
@@ -18,6 +18,13 @@ var typeToRule = map[string](func() influxdb.NotificationRule){
 	"http":      func() influxdb.NotificationRule { return &HTTP{} },
 }
 
+// IsType returns true if the provided type is the type of a notification rule,
+// i.e. the type of the task executing the rule.
+func IsType(typ string) bool {
+	_, ok := typeToRule[typ]
+	return ok
+}
+
 type rawRuleJSON struct {
 	Typ string `json:"type"`
 }
@@ -348,6 +355,28 @@ func (b *Base) HasTag(key, value string) bool {
 	return false
 }
 
+// MatchesTags returns true if the statuses of a check with the tags can match
+// the tag rules of the rule. Only the tag rules with the equal operator are
+// evaluated, a status might match the other operators.
+func (b *Base) MatchesTags(tags []influxdb.Tag) bool {
+	for _, tr := range b.TagRules {
+		if tr.Operator != influxdb.Equal {
+			continue
+		}
+		var matched bool
+		for _, t := range tags {
+			if t.Key == tr.Key && t.Value == tr.Value {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
 // GetOwnerID returns the owner id.
 func (b Base) GetOwnerID() influxdb.ID {
 	return b.OwnerID
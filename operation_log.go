@@ -41,3 +41,9 @@ var DefaultOperationLogFindOptions = FindOptions{
 	Descending: true,
 	Limit:      100,
 }
+
+// OrganizationOperationLogRecorder is an interface for adding an entry to the operation log of an org.
+type OrganizationOperationLogRecorder interface {
+	// AddOrganizationOperationLogEntry adds an entry with the provided description to the operation log of the org.
+	AddOrganizationOperationLogEntry(ctx context.Context, id ID, description string) error
+}
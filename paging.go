@@ -28,6 +28,11 @@ type FindOptions struct {
 	Offset     int
 	SortBy     string
 	Descending bool
+	// After is the ID of the last result of the previous page, the results
+	// start right after it. Unlike an offset it is not thrown off by results
+	// created or deleted between the pages. It is honored by the finds whose
+	// results are ordered by ID.
+	After *ID
 }
 
 // QueryParams returns a map containing url query params.
@@ -45,5 +50,9 @@ func (f FindOptions) QueryParams() map[string][]string {
 		qp["sortBy"] = []string{f.SortBy}
 	}
 
+	if f.After != nil {
+		qp["after"] = []string{f.After.String()}
+	}
+
 	return qp
 }
@@ -0,0 +1,120 @@
+package pkger
+
+import "github.com/influxdata/influxdb"
+
+// ApplyWithOnlyChanged applies only the resources of the pkg that are new or
+// whose dry run diff shows a change, existing resources that match the pkg are
+// left as they are and reported as unchanged.
+//
+// Only the resources whose diff captures all of their applied state can be
+// unchanged: buckets, dashboards, labels, scraper targets, telegrafs and
+// variables. Dashboards and telegrafs are never updated, one that differs from
+// every existing one of its name is created. Checks carry the status of their
+// tasks outside of the diff, a check is compared with its existing check and
+// the status of its task when it is applied. Notification endpoints and rules
+// carry secrets and the status of their tasks outside of the diff, so are
+// always applied.
+func ApplyWithOnlyChanged() ApplyOptFn {
+	return func(opt *ApplyOpt) error {
+		opt.OnlyChanged = true
+		return nil
+	}
+}
+
+// unchangedResources returns the existing resources of the pkg that match it,
+// but for the skipped ones. It relies on the existing state found by the dry
+// run lookups.
+func unchangedResources(pkg *Pkg, skipped collisionSet) []SummaryResourceStatus {
+	var out []SummaryResourceStatus
+	add := func(k Kind, name string, id influxdb.ID) {
+		if skipped.has(k.ResourceType(), name) {
+			return
+		}
+		out = append(out, SummaryResourceStatus{
+			Kind:   k,
+			Name:   name,
+			ID:     SafeID(id),
+			Status: ApplyStatusUnchanged,
+		})
+	}
+
+	for _, b := range pkg.buckets() {
+		if !b.shouldApply() {
+			add(KindBucket, b.Name(), b.ID())
+		}
+	}
+	for _, d := range pkg.dashboards() {
+		if d.existing != nil {
+			add(KindDashboard, d.Name(), d.ID())
+		}
+	}
+	for _, l := range pkg.labels() {
+		if !l.shouldApply() {
+			add(KindLabel, l.Name(), l.ID())
+		}
+	}
+	for _, t := range pkg.scraperTargets() {
+		if !t.shouldApply(pkg.mBuckets) {
+			add(KindScraperTarget, t.Name(), t.ID())
+		}
+	}
+	for _, t := range pkg.telegrafs() {
+		if t.existing != nil {
+			add(KindTelegraf, t.Name(), t.ID())
+		}
+	}
+	for _, v := range pkg.variables() {
+		if !v.shouldApply() {
+			add(KindVariable, v.Name(), v.ID())
+		}
+	}
+	return out
+}
+
+// changedDashboards returns the dashboards without an existing dashboard that
+// matches them. Dashboards may share a name, so they are left out by their
+// existing dashboard rather than by name.
+func changedDashboards(dashboards []*dashboard) []*dashboard {
+	out := make([]*dashboard, 0, len(dashboards))
+	for _, d := range dashboards {
+		if d.existing == nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// changedTelegrafs returns the telegrafs without an existing telegraf that
+// matches them.
+func changedTelegrafs(teles []*telegraf) []*telegraf {
+	out := make([]*telegraf, 0, len(teles))
+	for _, t := range teles {
+		if t.existing == nil {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func newUnchangedSet(statuses []SummaryResourceStatus) collisionSet {
+	set := make(collisionSet, len(statuses))
+	for _, s := range statuses {
+		set[collisionKey{resType: s.Kind.ResourceType(), name: s.Name}] = true
+	}
+	return set
+}
+
+// union returns the set of the resources of either set.
+func (c collisionSet) union(other collisionSet) collisionSet {
+	if len(other) == 0 {
+		return c
+	}
+	out := make(collisionSet, len(c)+len(other))
+	for k := range c {
+		out[k] = true
+	}
+	for k := range other {
+		out[k] = true
+	}
+	return out
+}
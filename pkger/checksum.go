@@ -0,0 +1,105 @@
+package pkger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+// Checksum is the hex encoded sha256 of the resources of the pkg. The resources
+// are canonically encoded and sorted, so neither the order of the resources,
+// the order of their fields, nor the encoding of the pkg changes the checksum.
+// This allows a reviewed pkg to be pinned by its checksum. An empty checksum is
+// returned when a resource cannot be encoded.
+func (p *Pkg) Checksum() string {
+	resources, err := p.canonicalResources()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join(resources, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseWithExpectedChecksum parses the pkg, encoded as either JSON or YAML, and
+// errors when its checksum does not match the checksum wanted.
+func ParseWithExpectedChecksum(data []byte, want string) (*Pkg, error) {
+	encoding := EncodingYAML
+	if b := bytes.TrimSpace(data); len(b) > 0 && (b[0] == '{' || b[0] == '[') {
+		encoding = EncodingJSON
+	}
+
+	pkg, err := Parse(encoding, FromReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	if got := pkg.Checksum(); got == "" || !strings.EqualFold(got, strings.TrimSpace(want)) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("pkg checksum %q does not match expected checksum %q", got, want),
+		}
+	}
+	return pkg, nil
+}
+
+// defaultPkgName returns the name of a pkg created without one. It is derived
+// from the orgs cloned and the checksum of the resources, so creating the pkg
+// again from the same orgs and resources gives it the same name.
+func defaultPkgName(orgIDs map[influxdb.ID]bool, p *Pkg) string {
+	parts := make([]string, 0, len(orgIDs)+1)
+	for id := range orgIDs {
+		parts = append(parts, id.String())
+	}
+	sort.Strings(parts)
+	parts = append(parts, p.Checksum())
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return "new_" + hex.EncodeToString(sum[:])[:12]
+}
+
+// contentHash is the hex encoded sha256 of the canonicalized pkg, its metadata
+// and its canonical resources.
+func (p *Pkg) contentHash() (string, error) {
+	resources, err := p.canonicalResources()
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(struct {
+		APIVersion string   `json:"apiVersion"`
+		Kind       Kind     `json:"kind"`
+		Metadata   Metadata `json:"meta"`
+		Resources  []string `json:"resources"`
+	}{
+		APIVersion: p.APIVersion,
+		Kind:       p.Kind,
+		Metadata:   p.Metadata,
+		Resources:  resources,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalResources encodes each resource of the spec as JSON, with its keys
+// sorted, and sorts the encodings.
+func (p *Pkg) canonicalResources() ([]string, error) {
+	resources := make([]string, 0, len(p.Spec.Resources))
+	for _, r := range p.Spec.Resources {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, string(b))
+	}
+	sort.Strings(resources)
+	return resources, nil
+}
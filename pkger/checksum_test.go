@@ -0,0 +1,106 @@
+package pkger
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPkg_Checksum(t *testing.T) {
+	const pkgYAML = `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Label
+      name: label_1
+      color: "#FFFFFF"
+    - kind: Bucket
+      name: rucket_1
+      description: bucket 1 description
+`
+
+	parse := func(t *testing.T, s string) *Pkg {
+		t.Helper()
+		pkg, err := Parse(EncodingYAML, FromString(s))
+		require.NoError(t, err)
+		return pkg
+	}
+
+	t.Run("reordering resources and fields yields the same checksum", func(t *testing.T) {
+		reordered := parse(t, `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - description: bucket 1 description
+      name: rucket_1
+      kind: Bucket
+    - kind: Label
+      color: "#FFFFFF"
+      name: label_1
+`)
+		sum := parse(t, pkgYAML).Checksum()
+		require.NotEmpty(t, sum)
+		assert.Equal(t, sum, reordered.Checksum())
+	})
+
+	t.Run("changing a field changes the checksum", func(t *testing.T) {
+		changed := parse(t, `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Label
+      name: label_1
+      color: "#000000"
+    - kind: Bucket
+      name: rucket_1
+      description: bucket 1 description
+`)
+		assert.NotEqual(t, parse(t, pkgYAML).Checksum(), changed.Checksum())
+	})
+
+	t.Run("yaml and json encodings of a pkg yield the same checksum", func(t *testing.T) {
+		yml, err := Parse(EncodingYAML, FromFile("testdata/bucket.yml"))
+		require.NoError(t, err)
+		jsn, err := Parse(EncodingJSON, FromFile("testdata/bucket.json"))
+		require.NoError(t, err)
+
+		assert.Equal(t, yml.Checksum(), jsn.Checksum())
+	})
+}
+
+func TestParseWithExpectedChecksum(t *testing.T) {
+	for _, file := range []string{"testdata/bucket.yml", "testdata/bucket.json"} {
+		t.Run(file, func(t *testing.T) {
+			b, err := ioutil.ReadFile(file)
+			require.NoError(t, err)
+
+			pkg, err := Parse(EncodingYAML, FromFile("testdata/bucket.yml"))
+			require.NoError(t, err)
+			want := pkg.Checksum()
+
+			t.Run("parses pkg with the expected checksum", func(t *testing.T) {
+				pkg, err := ParseWithExpectedChecksum(b, want)
+				require.NoError(t, err)
+				assert.Len(t, pkg.Summary().Buckets, 1)
+			})
+
+			t.Run("errors on a mismatched checksum", func(t *testing.T) {
+				_, err := ParseWithExpectedChecksum(b, "deadbeef")
+				require.Error(t, err)
+				assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+			})
+		})
+	}
+}
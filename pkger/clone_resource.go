@@ -2,17 +2,43 @@ package pkger
 
 import (
 	"errors"
+	"regexp"
 	"sort"
 
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	icheck "github.com/influxdata/influxdb/notification/check"
 	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/rule"
 )
 
-// ResourceToClone is a resource that will be cloned.
+// ResourceToClone is a resource that will be cloned. Prefer constructing
+// it with NewResourceToClone, which validates the resource upfront.
 type ResourceToClone struct {
 	Kind Kind        `json:"kind"`
 	ID   influxdb.ID `json:"id"`
 	Name string      `json:"name"`
+
+	// ExcludeVariables opts a dashboard out of having the variables
+	// referenced by its queries cloned along with it.
+	ExcludeVariables bool `json:"excludeVariables,omitempty"`
+}
+
+// NewResourceToClone constructs a validated ResourceToClone. Any error
+// returned is the same that would be returned from calling OK on the
+// resulting resource.
+func NewResourceToClone(kind Kind, id influxdb.ID, name string) (ResourceToClone, error) {
+	r := ResourceToClone{
+		Kind: kind,
+		ID:   id,
+		Name: name,
+	}
+	if err := r.OK(); err != nil {
+		return ResourceToClone{}, err
+	}
+	return r, nil
 }
 
 // OK validates a resource clone is viable.
@@ -35,7 +61,16 @@ func uniqResourcesToClone(resources []ResourceToClone) []ResourceToClone {
 
 	for i := range resources {
 		r := resources[i]
-		rKey := key{kind: r.Kind, id: r.ID}
+		k := r.Kind
+		switch k.ResourceType() {
+		case influxdb.NotificationEndpointResourceType:
+			// endpoints share an ID space across all endpoint kinds
+			k = KindNotificationEndpoint
+		case influxdb.ChecksResourceType:
+			// checks share an ID space across all check kinds
+			k = KindCheck
+		}
+		rKey := key{kind: k, id: r.ID}
 		kr, ok := m[rKey]
 		switch {
 		case ok && kr.Name == r.Name:
@@ -79,6 +114,9 @@ func convertCellView(cell influxdb.Cell) chart {
 		XPos:   int(cell.X),
 		YPos:   int(cell.Y),
 	}
+	if cell.RefreshInterval != nil {
+		ch.RefreshInterval = cell.RefreshInterval.Duration
+	}
 
 	setCommon := func(k chartKind, iColors []influxdb.ViewColor, dec influxdb.DecimalPlaces, iQueries []influxdb.DashboardQuery) {
 		ch.Kind = k
@@ -192,6 +230,10 @@ func convertChartToResource(ch chart) Resource {
 		r[fieldChartLegend] = ch.Legend
 	}
 
+	if ch.RefreshInterval > 0 {
+		r[fieldRefreshInterval] = ch.RefreshInterval.String()
+	}
+
 	assignNonZeroBools(r, map[string]bool{
 		fieldChartNoteOnEmpty: ch.NoteOnEmpty,
 		fieldChartShade:       ch.Shade,
@@ -278,12 +320,137 @@ func dashboardToResource(dash influxdb.Dashboard, name string) Resource {
 		charts = append(charts, convertChartToResource(ch))
 	}
 
-	return Resource{
+	r := Resource{
 		fieldKind:        KindDashboard.title(),
 		fieldName:        name,
 		fieldDescription: dash.Description,
 		fieldDashCharts:  charts,
 	}
+	if ri := dash.Meta.RefreshInterval; ri != nil && ri.Duration > 0 {
+		r[fieldRefreshInterval] = ri.Duration.String()
+	}
+	return r
+}
+
+// cellCharts returns the charts of the cells, ordered by their position. The
+// cells without a view of a kind of chart are left out.
+func cellCharts(cells []*influxdb.Cell) []chart {
+	charts := make([]chart, 0, len(cells))
+	for _, cell := range cells {
+		ch := convertCellView(*cell)
+		if !ch.Kind.ok() {
+			continue
+		}
+		charts = append(charts, ch)
+	}
+
+	sort.Slice(charts, func(i, j int) bool {
+		ic, jc := charts[i], charts[j]
+		if ic.XPos == jc.XPos {
+			return ic.YPos < jc.YPos
+		}
+		return ic.XPos < jc.XPos
+	})
+	return charts
+}
+
+// formatQuery returns the flux query as formatted by the flux formatter, so that
+// queries that differ only in whitespace are the same. A query that does not
+// parse is returned as provided.
+func formatQuery(q string) string {
+	pkg := parser.ParseSource(q)
+	if ast.Check(pkg) > 0 || len(pkg.Files) != 1 {
+		return q
+	}
+	return ast.Format(pkg.Files[0])
+}
+
+// formatDashboardQueries formats the queries of the charts of the dashboard
+// resources provided.
+func formatDashboardQueries(resources []Resource) {
+	for _, r := range resources {
+		if k, _ := r.kind(); !k.is(KindDashboard) {
+			continue
+		}
+		charts, _ := r[fieldDashCharts].([]Resource)
+		for _, ch := range charts {
+			qs, ok := ch[fieldChartQueries].(queries)
+			if !ok {
+				continue
+			}
+			for i := range qs {
+				qs[i].Query = formatQuery(qs[i].Query)
+			}
+		}
+	}
+}
+
+// dashboardVarRefPattern matches the variables referenced from a query, i.e.
+// the bucket in v.bucket. It is only used for queries that do not parse.
+var dashboardVarRefPattern = regexp.MustCompile(`(?:^|[^\w.])v\.([A-Za-z_]\w*)`)
+
+// builtinDashboardVars are the variables the UI provides to every dashboard,
+// they are never cloned.
+var builtinDashboardVars = map[string]bool{
+	"timeRangeStart": true,
+	"timeRangeStop":  true,
+	"windowPeriod":   true,
+}
+
+// dashboardVariableNames returns the names of the variables referenced by the
+// queries of the dashboard's cells, builtin variables are not included.
+func dashboardVariableNames(dash influxdb.Dashboard) []string {
+	mNames := make(map[string]bool)
+	for _, cell := range dash.Cells {
+		if cell == nil || cell.View == nil {
+			continue
+		}
+		for _, q := range convertCellView(*cell).Queries {
+			for _, name := range queryVariableNames(q.Query) {
+				if !builtinDashboardVars[name] {
+					mNames[name] = true
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(mNames))
+	for name := range mNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// queryVariableNames returns the names of the variables the query references
+// as members of v, i.e. v.bucket or v["bucket"].
+func queryVariableNames(q string) []string {
+	pkg := parser.ParseSource(q)
+	if ast.Check(pkg) > 0 {
+		var names []string
+		for _, match := range dashboardVarRefPattern.FindAllStringSubmatch(q, -1) {
+			names = append(names, match[1])
+		}
+		return names
+	}
+
+	var names []string
+	ast.Walk(ast.CreateVisitor(func(node ast.Node) {
+		member, ok := node.(*ast.MemberExpression)
+		if !ok {
+			return
+		}
+		if obj, ok := member.Object.(*ast.Identifier); !ok || obj.Name != "v" {
+			return
+		}
+		switch prop := member.Property.(type) {
+		case *ast.Identifier:
+			names = append(names, prop.Name)
+		case *ast.StringLiteral:
+			names = append(names, prop.Value)
+		}
+	}), pkg)
+	return names
 }
 
 func labelToResource(l influxdb.Label, name string) Resource {
@@ -302,6 +469,103 @@ func labelToResource(l influxdb.Label, name string) Resource {
 	return r
 }
 
+func checkToResource(ch influxdb.Check, name string) Resource {
+	if name == "" {
+		name = ch.GetName()
+	}
+	r := Resource{
+		fieldKind: checkResourceKind(ch).title(),
+		fieldName: name,
+	}
+	assignNonZeroStrings(r, map[string]string{
+		fieldDescription: ch.GetDescription(),
+	})
+
+	assignBase := func(base icheck.Base) {
+		r[fieldQuery] = base.Query.Text
+		assignNonZeroStrings(r, map[string]string{
+			fieldCheckEvery:                 durationToStr(base.Every),
+			fieldCheckOffset:                durationToStr(base.Offset),
+			fieldCheckStatusMessageTemplate: base.StatusMessageTemplate,
+		})
+		if len(base.Query.BuilderConfig.Buckets) > 0 {
+			r[fieldCheckBucket] = base.Query.BuilderConfig.Buckets[0]
+		}
+		for _, tag := range base.Query.BuilderConfig.Tags {
+			if tag.Key == "_field" && len(tag.Values) == 1 {
+				r[fieldCheckField] = tag.Values[0]
+			}
+		}
+
+		var tags []Resource
+		for _, t := range base.Tags {
+			tags = append(tags, Resource{
+				fieldKey:   t.Key,
+				fieldValue: t.Value,
+			})
+		}
+		if len(tags) > 0 {
+			r[fieldCheckTags] = tags
+		}
+	}
+
+	switch actual := ch.(type) {
+	case *icheck.Deadman:
+		assignBase(actual.Base)
+		r[fieldCheckLevel] = actual.Level.String()
+		r[fieldCheckReportZero] = actual.ReportZero
+		assignNonZeroStrings(r, map[string]string{
+			fieldCheckStaleTime: durationToStr(actual.StaleTime),
+			fieldCheckTimeSince: durationToStr(actual.TimeSince),
+		})
+	case *icheck.Threshold:
+		assignBase(actual.Base)
+		var thresholds []Resource
+		for _, th := range actual.Thresholds {
+			thresholds = append(thresholds, thresholdToResource(th))
+		}
+		r[fieldCheckThresholds] = thresholds
+	}
+
+	return r
+}
+
+func thresholdToResource(th icheck.ThresholdConfig) Resource {
+	r := Resource{
+		fieldType:       th.Type(),
+		fieldCheckLevel: th.GetLevel().String(),
+	}
+	switch actual := th.(type) {
+	case *icheck.Greater:
+		r[fieldCheckAllValues] = actual.AllValues
+		r[fieldValue] = actual.Value
+	case *icheck.Lesser:
+		r[fieldCheckAllValues] = actual.AllValues
+		r[fieldValue] = actual.Value
+	case *icheck.Range:
+		r[fieldCheckAllValues] = actual.AllValues
+		r[fieldCheckMin] = actual.Min
+		r[fieldCheckMax] = actual.Max
+		r[fieldCheckWithin] = actual.Within
+	}
+	return r
+}
+
+// checkResourceKind provides the concrete check kind for a check.
+func checkResourceKind(ch influxdb.Check) Kind {
+	if ch.Type() == "deadman" {
+		return KindCheckDeadman
+	}
+	return KindCheckThreshold
+}
+
+func durationToStr(d *notification.Duration) string {
+	if d == nil {
+		return ""
+	}
+	return ast.Format((*ast.DurationLiteral)(d))
+}
+
 func endpointToResource(e influxdb.NotificationEndpoint, name string) Resource {
 	if name == "" {
 		name = e.GetName()
@@ -314,9 +578,10 @@ func endpointToResource(e influxdb.NotificationEndpoint, name string) Resource {
 		fieldStatus:      string(e.GetStatus()),
 	})
 
+	r[fieldKind] = endpointKind(e).title()
+
 	switch actual := e.(type) {
 	case *endpoint.HTTP:
-		r[fieldKind] = KindNotificationEndpointHTTP.title()
 		r[fieldNotificationEndpointHTTPMethod] = actual.Method
 		r[fieldNotificationEndpointURL] = actual.URL
 		r[fieldType] = actual.AuthMethod
@@ -326,13 +591,11 @@ func endpointToResource(e influxdb.NotificationEndpoint, name string) Resource {
 			fieldNotificationEndpointUsername: actual.Username,
 		})
 	case *endpoint.PagerDuty:
-		r[fieldKind] = KindNotificationEndpointPagerDuty.title()
 		r[fieldNotificationEndpointURL] = actual.ClientURL
 		assignNonZeroSecrets(r, map[string]influxdb.SecretField{
 			fieldNotificationEndpointRoutingKey: actual.RoutingKey,
 		})
 	case *endpoint.Slack:
-		r[fieldKind] = KindNotificationEndpointSlack.title()
 		r[fieldNotificationEndpointURL] = actual.URL
 		assignNonZeroSecrets(r, map[string]influxdb.SecretField{
 			fieldNotificationEndpointToken: actual.Token,
@@ -342,6 +605,104 @@ func endpointToResource(e influxdb.NotificationEndpoint, name string) Resource {
 	return r
 }
 
+// endpointKind provides the concrete endpoint kind for an endpoint. Exporting
+// the concrete kind keeps the parser from having to infer it from the fields
+// that are present.
+func endpointKind(e influxdb.NotificationEndpoint) Kind {
+	switch e.Type() {
+	case endpoint.HTTPType:
+		return KindNotificationEndpointHTTP
+	case endpoint.PagerDutyType:
+		return KindNotificationEndpointPagerDuty
+	case endpoint.SlackType:
+		return KindNotificationEndpointSlack
+	default:
+		return KindNotificationEndpoint
+	}
+}
+
+func ruleToResource(iRule influxdb.NotificationRule, endpointName, name string) Resource {
+	if name == "" {
+		name = iRule.GetName()
+	}
+	r := Resource{
+		fieldKind:                         KindNotificationRule.title(),
+		fieldName:                         name,
+		fieldNotificationRuleEndpointName: endpointName,
+	}
+
+	base, msgTemplate, channel := notificationRuleBase(iRule)
+	assignNonZeroStrings(r, map[string]string{
+		fieldDescription:                     iRule.GetDescription(),
+		fieldNotificationRuleChannel:         channel,
+		fieldNotificationRuleEvery:           durationToStr(base.Every),
+		fieldNotificationRuleMessageTemplate: msgTemplate,
+		fieldNotificationRuleOffset:          durationToStr(base.Offset),
+	})
+
+	var statusRules []Resource
+	for _, sr := range base.StatusRules {
+		s := toSummaryStatusRule(sr)
+		statusRule := Resource{fieldNotificationRuleCurrentLevel: s.CurrentLevel}
+		assignNonZeroStrings(statusRule, map[string]string{
+			fieldNotificationRulePreviousLevel: s.PreviousLevel,
+		})
+		statusRules = append(statusRules, statusRule)
+	}
+	if len(statusRules) > 0 {
+		r[fieldNotificationRuleStatusRules] = statusRules
+	}
+
+	var tagRules []Resource
+	for _, tr := range base.TagRules {
+		tagRules = append(tagRules, Resource{
+			fieldKey:                      tr.Key,
+			fieldValue:                    tr.Value,
+			fieldNotificationRuleOperator: tr.Operator.String(),
+		})
+	}
+	if len(tagRules) > 0 {
+		r[fieldNotificationRuleTagRules] = tagRules
+	}
+
+	return r
+}
+
+// notificationRuleBase returns the base of the rule, and its message template
+// and channel where its type has them.
+func notificationRuleBase(iRule influxdb.NotificationRule) (base rule.Base, msgTemplate, channel string) {
+	switch actual := iRule.(type) {
+	case *rule.HTTP:
+		base = actual.Base
+	case *rule.PagerDuty:
+		base, msgTemplate = actual.Base, actual.MessageTemplate
+	case *rule.Slack:
+		base, msgTemplate, channel = actual.Base, actual.MessageTemplate, actual.Channel
+	}
+	return base, msgTemplate, channel
+}
+
+func toSummaryStatusRule(sr notification.StatusRule) SummaryStatusRule {
+	s := SummaryStatusRule{CurrentLevel: sr.CurrentLevel.String()}
+	if sr.PreviousLevel != nil {
+		s.PreviousLevel = sr.PreviousLevel.String()
+	}
+	return s
+}
+
+func scraperTargetToResource(t influxdb.ScraperTarget, bucketName, name string) Resource {
+	if name == "" {
+		name = t.Name
+	}
+	return Resource{
+		fieldKind:                KindScraperTarget.title(),
+		fieldName:                name,
+		fieldType:                string(t.Type),
+		fieldScraperTargetURL:    t.URL,
+		fieldScraperTargetBucket: bucketName,
+	}
+}
+
 func telegrafToResource(t influxdb.TelegrafConfig, name string) Resource {
 	if name == "" {
 		name = t.Name
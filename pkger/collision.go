@@ -0,0 +1,239 @@
+package pkger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+// CollisionStrategy determines how Apply resolves a resource of the pkg whose
+// name collides with an existing resource of the org. Only the resources that
+// are uniquely identified by name, buckets, checks, labels, notification
+// endpoints, notification rules, scraper targets and variables, can collide.
+type CollisionStrategy string
+
+const (
+	// CollisionUpdate updates the existing resource to match the pkg. This is
+	// the default strategy.
+	CollisionUpdate CollisionStrategy = "update"
+	// CollisionFail fails the apply before any resource is applied.
+	CollisionFail CollisionStrategy = "fail"
+	// CollisionSkip leaves the existing resource as it is. The label mappings
+	// of the skipped resource are not applied either.
+	CollisionSkip CollisionStrategy = "skip"
+)
+
+// ApplyWithCollisionStrategy sets how the resources of the pkg whose names collide
+// with existing resources are applied.
+func ApplyWithCollisionStrategy(strategy CollisionStrategy) ApplyOptFn {
+	return func(opt *ApplyOpt) error {
+		switch strategy {
+		case CollisionUpdate, CollisionFail, CollisionSkip:
+		default:
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid collision strategy %q", strategy),
+			}
+		}
+		opt.CollisionStrategy = strategy
+		return nil
+	}
+}
+
+// DiffCollision is a resource of the pkg whose name collides with an existing
+// resource, and the strategy the collision is resolved with when the pkg is
+// applied.
+type DiffCollision struct {
+	Kind     Kind              `json:"kind"`
+	Name     string            `json:"name"`
+	Strategy CollisionStrategy `json:"strategy"`
+}
+
+// collisions returns the resources of the pkg that collide with existing
+// resources. It relies on the existing state found by the dry run lookups.
+func collisions(pkg *Pkg, strategy CollisionStrategy) []DiffCollision {
+	var cs []DiffCollision
+	add := func(k Kind, name string) {
+		cs = append(cs, DiffCollision{
+			Kind:     k,
+			Name:     name,
+			Strategy: strategy,
+		})
+	}
+
+	for _, b := range pkg.buckets() {
+		if b.existing != nil {
+			add(KindBucket, b.Name())
+		}
+	}
+	for _, c := range pkg.checks() {
+		if c.existing != nil {
+			add(KindCheck, c.Name())
+		}
+	}
+	for _, l := range pkg.labels() {
+		if l.existing != nil {
+			add(KindLabel, l.Name())
+		}
+	}
+	for _, e := range pkg.notificationEndpoints() {
+		if e.existing != nil {
+			add(KindNotificationEndpoint, e.Name())
+		}
+	}
+	for _, r := range pkg.notificationRules() {
+		if r.existing != nil {
+			add(KindNotificationRule, r.Name())
+		}
+	}
+	for _, t := range pkg.scraperTargets() {
+		if t.existing != nil {
+			add(KindScraperTarget, t.Name())
+		}
+	}
+	for _, v := range pkg.variables() {
+		if v.existing != nil {
+			add(KindVariable, v.Name())
+		}
+	}
+	return cs
+}
+
+func collisionsErr(cs []DiffCollision) error {
+	resources := make([]string, 0, len(cs))
+	for _, c := range cs {
+		resources = append(resources, fmt.Sprintf("%s %q", c.Kind, c.Name))
+	}
+	return &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "pkg resources collide with existing resources: " + strings.Join(resources, ", "),
+	}
+}
+
+type collisionKey struct {
+	resType influxdb.ResourceType
+	name    string
+}
+
+// collisionSet is the set of colliding resources that are skipped by Apply. A
+// nil collisionSet skips nothing.
+type collisionSet map[collisionKey]bool
+
+func newCollisionSet(cs []DiffCollision) collisionSet {
+	set := make(collisionSet, len(cs))
+	for _, c := range cs {
+		set[collisionKey{resType: c.Kind.ResourceType(), name: c.Name}] = true
+	}
+	return set
+}
+
+func (c collisionSet) has(resType influxdb.ResourceType, name string) bool {
+	return c[collisionKey{resType: resType, name: name}]
+}
+
+func (c collisionSet) buckets(buckets []*bucket) []*bucket {
+	if len(c) == 0 {
+		return buckets
+	}
+	out := make([]*bucket, 0, len(buckets))
+	for _, b := range buckets {
+		if !c.has(b.ResourceType(), b.Name()) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+func (c collisionSet) checks(checks []*check) []*check {
+	if len(c) == 0 {
+		return checks
+	}
+	out := make([]*check, 0, len(checks))
+	for _, ch := range checks {
+		if !c.has(ch.ResourceType(), ch.Name()) {
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+func (c collisionSet) labels(labels []*label) []*label {
+	if len(c) == 0 {
+		return labels
+	}
+	out := make([]*label, 0, len(labels))
+	for _, l := range labels {
+		if !c.has(influxdb.LabelsResourceType, l.Name()) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func (c collisionSet) notificationEndpoints(endpoints []*notificationEndpoint) []*notificationEndpoint {
+	if len(c) == 0 {
+		return endpoints
+	}
+	out := make([]*notificationEndpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		if !c.has(e.ResourceType(), e.Name()) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (c collisionSet) notificationRules(rules []*notificationRule) []*notificationRule {
+	if len(c) == 0 {
+		return rules
+	}
+	out := make([]*notificationRule, 0, len(rules))
+	for _, r := range rules {
+		if !c.has(r.ResourceType(), r.Name()) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (c collisionSet) scraperTargets(targets []*scraperTarget) []*scraperTarget {
+	if len(c) == 0 {
+		return targets
+	}
+	out := make([]*scraperTarget, 0, len(targets))
+	for _, t := range targets {
+		if !c.has(t.ResourceType(), t.Name()) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func (c collisionSet) variables(vars []*variable) []*variable {
+	if len(c) == 0 {
+		return vars
+	}
+	out := make([]*variable, 0, len(vars))
+	for _, v := range vars {
+		if !c.has(v.ResourceType(), v.Name()) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// labelMappings removes the mappings of skipped resources, a skipped label may
+// still be mapped to the resources that are applied.
+func (c collisionSet) labelMappings(mappings []SummaryLabelMapping) []SummaryLabelMapping {
+	if len(c) == 0 {
+		return mappings
+	}
+	out := make([]SummaryLabelMapping, 0, len(mappings))
+	for _, m := range mappings {
+		if !c.has(m.ResourceType, m.ResourceName) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
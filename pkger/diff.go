@@ -0,0 +1,471 @@
+package pkger
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/influxdata/influxdb"
+)
+
+// DiffPkgs compares the current pkg to a proposed pkg without consulting the
+// platform. Resources are matched to one another by kind and name. Resources
+// found only in the proposed pkg are new, resources found in both carry the
+// current pkg's values as their old state, and resources found only in the
+// current pkg are marked for removal. Since neither pkg has been applied,
+// none of the diffs will have IDs.
+//
+// Dashboards, tasks and telegraf configs may share a name, those sharing a name
+// are matched in the order of the pkg. As none is ever updated, those found in
+// both pkgs are included in the diff only when modified. The queries of
+// dashboards are compared formatted, so that a query differing only in
+// formatting is not a modification. Label mappings are matched by the kind and
+// name of the resource and the name of the label.
+func DiffPkgs(current, proposed *Pkg) (Diff, error) {
+	if current == nil || proposed == nil {
+		return Diff{}, errors.New("must provide both a current and proposed pkg")
+	}
+
+	for _, pkg := range []*Pkg{current, proposed} {
+		if pkg.isParsed {
+			continue
+		}
+		if err := pkg.Validate(); err != nil {
+			return Diff{}, err
+		}
+	}
+
+	return Diff{
+		Buckets:               diffPkgBuckets(current, proposed),
+		Checks:                diffPkgChecks(current, proposed),
+		Dashboards:            diffPkgDashboards(current, proposed),
+		Labels:                diffPkgLabels(current, proposed),
+		LabelMappings:         diffPkgLabelMappings(current, proposed),
+		NotificationEndpoints: diffPkgNotificationEndpoints(current, proposed),
+		NotificationRules:     diffPkgNotificationRules(current, proposed),
+		Tasks:                 diffPkgTasks(current, proposed),
+		Telegrafs:             diffPkgTelegrafs(current, proposed),
+		Variables:             diffPkgVariables(current, proposed),
+	}, nil
+}
+
+func diffPkgBuckets(current, proposed *Pkg) []DiffBucket {
+	var diffs []DiffBucket
+	for _, b := range proposed.buckets() {
+		diff := newDiffBucket(b, nil)
+		if cb, ok := current.mBuckets[b.Name()]; ok {
+			diff.Old = &DiffBucketValues{
+				DisplayName:    displayNameDiff(cb.Name(), cb.platformName()),
+				Description:    cb.Description,
+				RetentionRules: cb.RetentionRules,
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, cb := range current.buckets() {
+		if _, ok := proposed.mBuckets[cb.Name()]; ok {
+			continue
+		}
+		diffs = append(diffs, DiffBucket{
+			Name:   cb.Name(),
+			Remove: true,
+			Old: &DiffBucketValues{
+				DisplayName:    displayNameDiff(cb.Name(), cb.platformName()),
+				Description:    cb.Description,
+				RetentionRules: cb.RetentionRules,
+			},
+		})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
+
+func diffPkgChecks(current, proposed *Pkg) []DiffCheck {
+	var diffs []DiffCheck
+	for _, c := range proposed.checks() {
+		var diff DiffCheck
+		if cc, ok := current.mChecks[c.Name()]; ok {
+			diff = newDiffCheck(c, cc.summarize().Check)
+		} else {
+			diff = newDiffCheck(c, nil)
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, cc := range current.checks() {
+		if _, ok := proposed.mChecks[cc.Name()]; ok {
+			continue
+		}
+		diffs = append(diffs, DiffCheck{
+			Name:   cc.Name(),
+			Remove: true,
+			Old:    &DiffCheckValues{Check: cc.summarize().Check},
+		})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
+
+func diffPkgDashboards(current, proposed *Pkg) []DiffDashboard {
+	matched := make(map[*dashboard]bool)
+	match := func(name string) *dashboard {
+		for _, cd := range current.dashboards() {
+			if !matched[cd] && cd.Name() == name {
+				matched[cd] = true
+				return cd
+			}
+		}
+		return nil
+	}
+
+	var diffs []DiffDashboard
+	for _, d := range proposed.dashboards() {
+		diff := newDiffDashboard(d)
+		if cd := match(d.Name()); cd != nil {
+			if dashboardsEqual(cd, d) {
+				continue
+			}
+			diff.Old = &DiffDashboardValues{
+				Desc:   cd.Description,
+				Charts: newDiffCharts(cd.Charts),
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, cd := range current.dashboards() {
+		if matched[cd] {
+			continue
+		}
+		diffs = append(diffs, DiffDashboard{
+			Name:   cd.Name(),
+			Remove: true,
+			Old: &DiffDashboardValues{
+				Desc:   cd.Description,
+				Charts: newDiffCharts(cd.Charts),
+			},
+		})
+	}
+
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
+
+func dashboardsEqual(a, b *dashboard) bool {
+	return a.Description == b.Description &&
+		reflect.DeepEqual(formattedCharts(a.Charts), formattedCharts(b.Charts))
+}
+
+// formattedCharts returns the charts with their queries formatted and the ids
+// of their colors dropped, the ids are generated anew by each parse.
+func formattedCharts(charts []chart) []chart {
+	out := make([]chart, 0, len(charts))
+	for _, c := range charts {
+		qs := make(queries, 0, len(c.Queries))
+		for _, q := range c.Queries {
+			qs = append(qs, query{Query: formatQuery(q.Query)})
+		}
+		c.Queries = qs
+
+		cs := make(colors, 0, len(c.Colors))
+		for _, cl := range c.Colors {
+			cc := *cl
+			cc.id = ""
+			cs = append(cs, &cc)
+		}
+		c.Colors = cs
+		out = append(out, c)
+	}
+	return out
+}
+
+func diffPkgLabels(current, proposed *Pkg) []DiffLabel {
+	var diffs []DiffLabel
+	for _, l := range proposed.labels() {
+		diff := newDiffLabel(l, nil)
+		if cl, ok := current.mLabels[l.Name()]; ok {
+			diff.Old = &DiffLabelValues{
+				DisplayName: displayNameDiff(cl.Name(), cl.platformName()),
+				Color:       cl.Color,
+				Description: cl.Description,
+			}
+			props := newDiffLabelProperties(l, cl.toInfluxLabel())
+			diff.Properties = &props
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, cl := range current.labels() {
+		if _, ok := proposed.mLabels[cl.Name()]; ok {
+			continue
+		}
+		diffs = append(diffs, DiffLabel{
+			Name:   cl.Name(),
+			Remove: true,
+			Old: &DiffLabelValues{
+				DisplayName: displayNameDiff(cl.Name(), cl.platformName()),
+				Color:       cl.Color,
+				Description: cl.Description,
+			},
+		})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
+
+func diffPkgLabelMappings(current, proposed *Pkg) []DiffLabelMapping {
+	type key struct {
+		resType   influxdb.ResourceType
+		resName   string
+		labelName string
+	}
+	newKey := func(m SummaryLabelMapping) key {
+		return key{
+			resType:   m.ResourceType,
+			resName:   m.ResourceName,
+			labelName: m.LabelName,
+		}
+	}
+
+	existing := make(map[key]bool)
+	for _, m := range current.labelMappings() {
+		existing[newKey(m)] = true
+	}
+
+	// resources sharing a name, i.e. dashboards, have a mapping each, they
+	// are diffed once
+	seen := make(map[key]bool)
+	var diffs []DiffLabelMapping
+	for _, m := range proposed.labelMappings() {
+		k := newKey(m)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		diffs = append(diffs, DiffLabelMapping{
+			IsNew:     !existing[k],
+			ResType:   m.ResourceType,
+			ResName:   m.ResourceName,
+			LabelName: m.LabelName,
+		})
+	}
+
+	for _, m := range current.labelMappings() {
+		k := newKey(m)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		diffs = append(diffs, DiffLabelMapping{
+			ResType:   m.ResourceType,
+			ResName:   m.ResourceName,
+			LabelName: m.LabelName,
+			Remove:    true,
+		})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		n, m := diffs[i], diffs[j]
+		if n.ResType != m.ResType {
+			return n.ResType < m.ResType
+		}
+		if n.ResName != m.ResName {
+			return n.ResName < m.ResName
+		}
+		return n.LabelName < m.LabelName
+	})
+	return diffs
+}
+
+func diffPkgNotificationEndpoints(current, proposed *Pkg) []DiffNotificationEndpoint {
+	var diffs []DiffNotificationEndpoint
+	for _, e := range proposed.notificationEndpoints() {
+		var diff DiffNotificationEndpoint
+		if ce, ok := current.mNotificationEndpoints[e.Name()]; ok {
+			diff = newDiffNotificationEndpoint(e, ce.summarize().NotificationEndpoint)
+		} else {
+			diff = newDiffNotificationEndpoint(e, nil)
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, ce := range current.notificationEndpoints() {
+		if _, ok := proposed.mNotificationEndpoints[ce.Name()]; ok {
+			continue
+		}
+		diffs = append(diffs, DiffNotificationEndpoint{
+			Name:   ce.Name(),
+			Remove: true,
+			Old: &DiffNotificationEndpointValues{
+				NotificationEndpoint: ce.summarize().NotificationEndpoint,
+			},
+		})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
+
+func diffPkgNotificationRules(current, proposed *Pkg) []DiffNotificationRule {
+	var diffs []DiffNotificationRule
+	for _, r := range proposed.notificationRules() {
+		diff := newDiffNotificationRule(r, nil, nil)
+		if cr, ok := current.mNotificationRules[r.Name()]; ok {
+			old := newDiffNotificationRuleValues(cr.summarize())
+			diff.Old = &old
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, cr := range current.notificationRules() {
+		if _, ok := proposed.mNotificationRules[cr.Name()]; ok {
+			continue
+		}
+		old := newDiffNotificationRuleValues(cr.summarize())
+		diffs = append(diffs, DiffNotificationRule{
+			Name:   cr.Name(),
+			Remove: true,
+			Old:    &old,
+		})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
+
+func diffPkgTasks(current, proposed *Pkg) []DiffTask {
+	matched := make(map[*task]bool)
+	match := func(name string) *task {
+		for _, ct := range current.tasks() {
+			if !matched[ct] && ct.Name() == name {
+				matched[ct] = true
+				return ct
+			}
+		}
+		return nil
+	}
+
+	var diffs []DiffTask
+	for _, t := range proposed.tasks() {
+		diff := newDiffTask(t)
+		if ct := match(t.Name()); ct != nil {
+			old := ct.diffValues()
+			if old == diff.New {
+				continue
+			}
+			diff.Old = &old
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, ct := range current.tasks() {
+		if matched[ct] {
+			continue
+		}
+		old := ct.diffValues()
+		diffs = append(diffs, DiffTask{
+			Name:   ct.Name(),
+			Old:    &old,
+			Remove: true,
+		})
+	}
+
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
+
+func diffPkgTelegrafs(current, proposed *Pkg) []DiffTelegraf {
+	matched := make(map[*telegraf]bool)
+	match := func(name string) *telegraf {
+		for _, ct := range current.telegrafs() {
+			if !matched[ct] && ct.Name() == name {
+				matched[ct] = true
+				return ct
+			}
+		}
+		return nil
+	}
+
+	var diffs []DiffTelegraf
+	for _, t := range proposed.telegrafs() {
+		diff := newDiffTelegraf(t)
+		if ct := match(t.Name()); ct != nil {
+			if t.matches(ct.config) {
+				continue
+			}
+			old := ct.config
+			diff.Old = &old
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, ct := range current.telegrafs() {
+		if matched[ct] {
+			continue
+		}
+		old := ct.config
+		diffs = append(diffs, DiffTelegraf{
+			TelegrafConfig: influxdb.TelegrafConfig{Name: ct.Name()},
+			Old:            &old,
+			Remove:         true,
+		})
+	}
+
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
+
+func diffPkgVariables(current, proposed *Pkg) []DiffVariable {
+	var diffs []DiffVariable
+	for _, v := range proposed.variables() {
+		diff := newDiffVariable(v, nil)
+		if cv, ok := current.mVariables[v.Name()]; ok {
+			diff.Old = &DiffVariableValues{
+				DisplayName: displayNameDiff(cv.Name(), cv.platformName()),
+				Description: cv.Description,
+				Args:        cv.influxVarArgs(),
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, cv := range current.variables() {
+		if _, ok := proposed.mVariables[cv.Name()]; ok {
+			continue
+		}
+		diffs = append(diffs, DiffVariable{
+			Name:   cv.Name(),
+			Remove: true,
+			Old: &DiffVariableValues{
+				DisplayName: displayNameDiff(cv.Name(), cv.platformName()),
+				Description: cv.Description,
+				Args:        cv.influxVarArgs(),
+			},
+		})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
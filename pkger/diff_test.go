@@ -0,0 +1,400 @@
+package pkger
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffPkgs(t *testing.T) {
+	newPkg := func(t *testing.T, resources string) *Pkg {
+		t.Helper()
+
+		pkgStr := `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+` + resources
+
+		pkg, err := Parse(EncodingYAML, FromString(pkgStr))
+		require.NoError(t, err)
+		return pkg
+	}
+
+	t.Run("buckets", func(t *testing.T) {
+		current := newPkg(t, `
+    - kind: Bucket
+      name: rucket_modified
+      description: old desc
+      retentionRules:
+        - type: expire
+          everySeconds: 3600
+    - kind: Bucket
+      name: rucket_removed
+      description: removed desc
+    - kind: Bucket
+      name: rucket_unchanged
+`)
+		proposed := newPkg(t, `
+    - kind: Bucket
+      name: rucket_added
+      description: added desc
+    - kind: Bucket
+      name: rucket_modified
+      description: new desc
+      retentionRules:
+        - type: expire
+          everySeconds: 7200
+    - kind: Bucket
+      name: rucket_unchanged
+`)
+
+		diff, err := DiffPkgs(current, proposed)
+		require.NoError(t, err)
+
+		expected := []DiffBucket{
+			{
+				Name: "rucket_added",
+				New:  DiffBucketValues{Description: "added desc"},
+			},
+			{
+				Name: "rucket_modified",
+				New: DiffBucketValues{
+					Description:    "new desc",
+					RetentionRules: retentionRules{newRetentionRule(2 * time.Hour)},
+				},
+				Old: &DiffBucketValues{
+					Description:    "old desc",
+					RetentionRules: retentionRules{newRetentionRule(time.Hour)},
+				},
+			},
+			{
+				Name:   "rucket_removed",
+				Old:    &DiffBucketValues{Description: "removed desc"},
+				Remove: true,
+			},
+			{
+				Name: "rucket_unchanged",
+				Old:  &DiffBucketValues{},
+			},
+		}
+		assert.Equal(t, expected, diff.Buckets)
+
+		assert.True(t, diff.Buckets[0].IsNew())
+		assert.False(t, diff.Buckets[1].IsNew())
+		assert.True(t, diff.Buckets[1].hasConflict())
+		assert.False(t, diff.Buckets[3].hasConflict())
+	})
+
+	t.Run("dashboards", func(t *testing.T) {
+		dashStr := func(name, query string) string {
+			return `
+    - kind: Dashboard
+      name: ` + name + `
+      charts:
+        - kind:   Single_Stat
+          name:   single stat
+          width:  6
+          height: 3
+          queries:
+            - query: ` + query + `
+          colors:
+            - name: laser
+              type: text
+              hex: "#8F8AF4"
+              value: 3
+`
+		}
+
+		current := newPkg(t, dashStr("dash_modified", `"from(bucket: \"rucket\") |> range(start: -5m)"`)+
+			dashStr("dash_reformatted", `"from(bucket:\"rucket\")   |>  range(start:-5m)"`)+
+			dashStr("dash_removed", `"from(bucket: \"rucket\") |> range(start: -5m)"`))
+		proposed := newPkg(t, dashStr("dash_added", `"from(bucket: \"rucket\") |> range(start: -5m)"`)+
+			dashStr("dash_modified", `"from(bucket: \"rucket\") |> range(start: -1h)"`)+
+			dashStr("dash_reformatted", `"from(bucket: \"rucket\")\n  |> range(start: -5m)"`))
+
+		diff, err := DiffPkgs(current, proposed)
+		require.NoError(t, err)
+
+		require.Len(t, diff.Dashboards, 3)
+		added, modified, removed := diff.Dashboards[0], diff.Dashboards[1], diff.Dashboards[2]
+
+		assert.Equal(t, "dash_added", added.Name)
+		assert.Nil(t, added.Old)
+		assert.False(t, added.Remove)
+
+		assert.Equal(t, "dash_modified", modified.Name)
+		require.NotNil(t, modified.Old)
+		require.Len(t, modified.Charts, 1)
+		require.Len(t, modified.Old.Charts, 1)
+		assert.NotEqual(t, modified.Old.Charts[0].Properties, modified.Charts[0].Properties)
+		assert.False(t, modified.Remove)
+
+		assert.Equal(t, "dash_removed", removed.Name)
+		assert.True(t, removed.Remove)
+		assert.Empty(t, removed.Charts)
+		require.NotNil(t, removed.Old)
+		assert.Len(t, removed.Old.Charts, 1)
+	})
+
+	t.Run("label mappings", func(t *testing.T) {
+		current := newPkg(t, `
+    - kind: Label
+      name: label_1
+    - kind: Label
+      name: label_2
+    - kind: Bucket
+      name: rucket_1
+      associations:
+        - kind: Label
+          name: label_1
+        - kind: Label
+          name: label_2
+`)
+		proposed := newPkg(t, `
+    - kind: Label
+      name: label_1
+    - kind: Label
+      name: label_2
+    - kind: Bucket
+      name: rucket_1
+      associations:
+        - kind: Label
+          name: label_1
+    - kind: Bucket
+      name: rucket_2
+      associations:
+        - kind: Label
+          name: label_2
+`)
+
+		diff, err := DiffPkgs(current, proposed)
+		require.NoError(t, err)
+
+		expected := []DiffLabelMapping{
+			{
+				ResType:   influxdb.BucketsResourceType,
+				ResName:   "rucket_1",
+				LabelName: "label_1",
+			},
+			{
+				ResType:   influxdb.BucketsResourceType,
+				ResName:   "rucket_1",
+				LabelName: "label_2",
+				Remove:    true,
+			},
+			{
+				IsNew:     true,
+				ResType:   influxdb.BucketsResourceType,
+				ResName:   "rucket_2",
+				LabelName: "label_2",
+			},
+		}
+		assert.Equal(t, expected, diff.LabelMappings)
+	})
+
+	t.Run("tasks", func(t *testing.T) {
+		current := newPkg(t, `
+    - kind: Task
+      name: task_modified
+      every: 1h
+      query: 'from(bucket: "rucket") |> range(start: -1h)'
+    - kind: Task
+      name: task_removed
+      every: 1h
+      query: 'from(bucket: "rucket") |> range(start: -1h)'
+    - kind: Task
+      name: task_unchanged
+      every: 1h
+      query: 'from(bucket: "rucket") |> range(start: -1h)'
+`)
+		proposed := newPkg(t, `
+    - kind: Task
+      name: task_added
+      cron: "15 * * * *"
+      query: 'from(bucket: "rucket") |> range(start: -1h)'
+    - kind: Task
+      name: task_modified
+      every: 2h
+      query: 'from(bucket: "rucket") |> range(start: -2h)'
+    - kind: Task
+      name: task_unchanged
+      every: 1h
+      query: 'from(bucket: "rucket") |> range(start: -1h)'
+`)
+
+		diff, err := DiffPkgs(current, proposed)
+		require.NoError(t, err)
+
+		query := func(rng string) string {
+			return `from(bucket: "rucket") |> range(start: -` + rng + `)`
+		}
+		expected := []DiffTask{
+			{
+				Name: "task_added",
+				New:  DiffTaskValues{Cron: "15 * * * *", Query: query("1h"), Status: influxdb.Active},
+			},
+			{
+				Name: "task_modified",
+				New:  DiffTaskValues{Every: "2h", Query: query("2h"), Status: influxdb.Active},
+				Old:  &DiffTaskValues{Every: "1h", Query: query("1h"), Status: influxdb.Active},
+			},
+			{
+				Name:   "task_removed",
+				Old:    &DiffTaskValues{Every: "1h", Query: query("1h"), Status: influxdb.Active},
+				Remove: true,
+			},
+		}
+		assert.Equal(t, expected, diff.Tasks)
+	})
+
+	t.Run("telegrafs", func(t *testing.T) {
+		teleStr := func(name, desc, interval string) string {
+			return `
+    - kind: Telegraf
+      name: ` + name + `
+      description: ` + desc + `
+      config: |
+        [agent]
+          interval = "` + interval + `"
+        [[inputs.cpu]]
+        [[outputs.influxdb_v2]]
+          urls = ["http://localhost:9999"]
+          token = "$INFLUX_TOKEN"
+          organization = "org"
+          bucket = "rucket"
+`
+		}
+
+		current := newPkg(t, teleStr("tele_modified", "old desc", "10s")+
+			teleStr("tele_removed", "removed desc", "10s")+
+			teleStr("tele_unchanged", "desc", "10s"))
+		proposed := newPkg(t, teleStr("tele_added", "added desc", "10s")+
+			teleStr("tele_modified", "new desc", "20s")+
+			teleStr("tele_unchanged", "desc", "10s"))
+
+		diff, err := DiffPkgs(current, proposed)
+		require.NoError(t, err)
+
+		require.Len(t, diff.Telegrafs, 3)
+		added, modified, removed := diff.Telegrafs[0], diff.Telegrafs[1], diff.Telegrafs[2]
+
+		assert.Equal(t, "tele_added", added.Name)
+		assert.Nil(t, added.Old)
+		assert.False(t, added.Remove)
+
+		assert.Equal(t, "tele_modified", modified.Name)
+		assert.Equal(t, "new desc", modified.Description)
+		require.NotNil(t, modified.Old)
+		assert.Equal(t, "old desc", modified.Old.Description)
+		assert.Equal(t, int64(10000), modified.Old.Agent.Interval)
+		assert.Equal(t, int64(20000), modified.Agent.Interval)
+
+		assert.Equal(t, "tele_removed", removed.Name)
+		assert.True(t, removed.Remove)
+		require.NotNil(t, removed.Old)
+		assert.Equal(t, "removed desc", removed.Old.Description)
+
+		b, err := json.Marshal(diff.Telegrafs)
+		require.NoError(t, err)
+
+		var decoded []DiffTelegraf
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		require.Len(t, decoded, 3)
+		assert.Nil(t, decoded[0].Old)
+		require.NotNil(t, decoded[1].Old)
+		assert.Equal(t, "old desc", decoded[1].Old.Description)
+		assert.Equal(t, "new desc", decoded[1].Description)
+		assert.True(t, decoded[2].Remove)
+		assert.Equal(t, "tele_removed", decoded[2].Name)
+	})
+
+	t.Run("variables", func(t *testing.T) {
+		current := newPkg(t, `
+    - kind: Variable
+      name: var_modified
+      description: old desc
+      type: constant
+      values:
+        - first val
+    - kind: Variable
+      name: var_removed
+      type: constant
+      values:
+        - removed val
+`)
+		proposed := newPkg(t, `
+    - kind: Variable
+      name: var_added
+      type: map
+      values:
+        k1: v1
+    - kind: Variable
+      name: var_modified
+      description: new desc
+      type: constant
+      values:
+        - first val
+        - second val
+`)
+
+		diff, err := DiffPkgs(current, proposed)
+		require.NoError(t, err)
+
+		expected := []DiffVariable{
+			{
+				Name: "var_added",
+				New: DiffVariableValues{
+					Args: &influxdb.VariableArguments{
+						Type:   "map",
+						Values: influxdb.VariableMapValues{"k1": "v1"},
+					},
+				},
+			},
+			{
+				Name: "var_modified",
+				New: DiffVariableValues{
+					Description: "new desc",
+					Args: &influxdb.VariableArguments{
+						Type:   "constant",
+						Values: influxdb.VariableConstantValues{"first val", "second val"},
+					},
+				},
+				Old: &DiffVariableValues{
+					Description: "old desc",
+					Args: &influxdb.VariableArguments{
+						Type:   "constant",
+						Values: influxdb.VariableConstantValues{"first val"},
+					},
+				},
+			},
+			{
+				Name: "var_removed",
+				Old: &DiffVariableValues{
+					Args: &influxdb.VariableArguments{
+						Type:   "constant",
+						Values: influxdb.VariableConstantValues{"removed val"},
+					},
+				},
+				Remove: true,
+			},
+		}
+		assert.Equal(t, expected, diff.Variables)
+	})
+
+	t.Run("requires both pkgs", func(t *testing.T) {
+		_, err := DiffPkgs(nil, newPkg(t, `
+    - kind: Label
+      name: label_1
+`))
+		require.Error(t, err)
+	})
+}
@@ -18,6 +18,12 @@ file and see a summary of its contents:
 	sum := newPkg.Summary()
 	fmt.Println(sum) // do something with the summary
 
+A single family of resources can be inspected with its accessor, i.e.
+newPkg.Buckets() or newPkg.Dashboards(). The accessors, along with the
+Summary, are the supported means of inspecting a pkg. The Resource maps
+of the pkg's Spec are keyed by the fields of the pkg file, which are free
+to change.
+
 The parser will validate all contents of the package and provide any
 and all fields/entries that failed validation.
 
@@ -30,7 +36,7 @@ to validate the package after the raw decoding is done:
 	}
 
 If a validation error is encountered during the validation or parsing then
-the error returned will be of type *parseErr. The parseErr provides a rich
+the error returned will be of type *ParseError. The ParseError provides a rich
 set of validations failures. There can be numerous failures in a package
 and we did our best to inform the caller about them all in a single run.
 
@@ -51,7 +57,10 @@ your organization and the concluding the state after the application of a
 package. All buckets, labels, and variables, when given a name that already
 exists, will not create a new resource, but rather, will edit the existing
 resource. If this is not a desired result, then rename your bucket to something
-else to avoid the imposed changes applying this package would incur. The summary
+else to avoid the imposed changes applying this package would incur. The name
+identifies the resource, a bucket, label, notification endpoint or variable may
+provide a displayName to be written to the platform in its place. Changing the
+displayName renames the existing resource. The summary
 provided is a summary of hte package itself. If a resource exists all IDs will
 be populated for them, if they do not, then they will be zero values. Any zero
 value ID is safe to assume is not populated. All influxdb.ID's must be non zero
@@ -77,15 +86,27 @@ applied, then the changes should have been made known to you. If not, then there
 potential loss of data if the changes to a bucket resulted in the retention period
 being shortened in the package.
 
+To tear down the resources a package was applied with, the package may be
+removed from the organization. Only the existing resources matching the kind
+and name of a package resource are deleted, anything that can not be matched
+with confidence is skipped and reported in the summary.
+
+	summary, err := svc.Remove(ctx, orgID, pkg)
+	if err != nil {
+		panic(err) // handle error as you see fit
+	}
+	// explore the summary.Skipped resources
+
 If you would like to export existing resources into the form of a package, then you
 have the ability to do so using the following:
 
+	bucketToClone, err := NewResourceToClone(KindBucket, Existing_BUCKET_ID, "new bucket name")
+	if err != nil {
+		panic(err) // handle error as you see fit
+	}
+
 	resourcesToClone := []ResourceToClone{
-		{
-			Kind: KindBucket,
-			ID:   Existing_BUCKET_ID,
-			Name: "new bucket name"
-		},
+		bucketToClone,
 		{
 			Kind: KindDashboard,
 			ID:   Existing_Dashboard_ID,
@@ -116,8 +137,26 @@ have the ability to do so using the following:
 
 Things to note about the behavior of exporting existing resources. All label
 associations with existing resources will be included in the new package.
-However, the variables that are used within a dashboard query will not be added
-automatically to the package. Variables will need to be passed in alongside
-the dashboard to be added to the package.
+The variables that are used within a dashboard query are added to the package
+as well, unless the dashboard's ResourceToClone sets ExcludeVariables.
+
+To export a single dashboard into a package that can be applied on its own,
+without listing the labels and variables it depends on:
+
+	newPkg, err := svc.CreatePkg(ctx, CreateWithDashboardAndDeps(Existing_Dashboard_ID))
+
+When exporting over a package exported previously, i.e. one tracked in source
+control, the metadata of the previous package may be provided in place of new
+metadata. The version of the new package is the previous version incremented,
+the previous version must be an integer, a major.minor or a semver, optionally
+prefixed with a v:
+
+	newPkg, err := svc.CreatePkg(ctx,
+		CreateWithPreviousPkg(prevPkg),
+		CreateWithAllOrgResources(orgID),
+	)
+
+An exported package records the time it was exported, and the org it was
+exported from when all the resources of a single org are exported.
 */
 package pkger
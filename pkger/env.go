@@ -0,0 +1,151 @@
+package pkger
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// envRefPattern matches an environment reference, i.e. ${RETENTION_HOURS} or
+// ${RETENTION_HOURS:-72}, where the value following the :- is the default used
+// when the variable is not set.
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// substituteEnv replaces all environment references found in the values of the
+// pkg's resources with the values from the provided env. A value that consists
+// of only a reference takes on the type of the field it is read as, so that a
+// reference may be used where a number or bool is expected. References to
+// variables not set in the env and without a default are returned as a parse
+// error.
+func (p *Pkg) substituteEnv(env map[string]string) error {
+	var pErr ParseError
+	for i, r := range p.Spec.Resources {
+		missing := make(map[string]map[string]bool)
+		for k, v := range r {
+			r[k] = substituteEnvValue(v, env, func(name string) {
+				if missing[k] == nil {
+					missing[k] = make(map[string]bool)
+				}
+				missing[k][name] = true
+			})
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		kind, _ := r.kind()
+		pErr.append(resourceErr{
+			Kind:           kind.String(),
+			Name:           r.Name(),
+			Idx:            intPtr(i),
+			ValidationErrs: envValidationErrs(missing),
+		})
+	}
+
+	if len(pErr.Resources) > 0 {
+		return &pErr
+	}
+	return nil
+}
+
+func substituteEnvValue(v interface{}, env map[string]string, missingFn func(name string)) interface{} {
+	switch t := v.(type) {
+	case string:
+		return substituteEnvStr(t, env, missingFn)
+	case Resource:
+		for k, val := range t {
+			t[k] = substituteEnvValue(val, env, missingFn)
+		}
+	case map[string]interface{}:
+		for k, val := range t {
+			t[k] = substituteEnvValue(val, env, missingFn)
+		}
+	case map[interface{}]interface{}:
+		for k, val := range t {
+			t[k] = substituteEnvValue(val, env, missingFn)
+		}
+	case []Resource:
+		for i := range t {
+			t[i] = substituteEnvValue(t[i], env, missingFn).(Resource)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = substituteEnvValue(t[i], env, missingFn)
+		}
+	}
+	return v
+}
+
+func substituteEnvStr(s string, env map[string]string, missingFn func(name string)) interface{} {
+	if !envRefPattern.MatchString(s) {
+		return s
+	}
+
+	lookup := func(match []string) string {
+		name, hasDefault, defaultVal := match[1], match[2] != "", match[3]
+		if val, ok := env[name]; ok {
+			return val
+		}
+		if !hasDefault {
+			missingFn(name)
+		}
+		return defaultVal
+	}
+
+	if loc := envRefPattern.FindStringSubmatchIndex(s); loc[0] == 0 && loc[1] == len(s) {
+		return envValue(lookup(envRefPattern.FindStringSubmatch(s)))
+	}
+
+	return envRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		return lookup(envRefPattern.FindStringSubmatch(ref))
+	})
+}
+
+// envValue is the value substituted for a reference that makes up an entire
+// value. It is converted to the type of the field it is read as, a string
+// field keeps the value as provided, i.e. a description of "true".
+type envValue string
+
+func (v envValue) bool() (bool, bool) {
+	b, err := strconv.ParseBool(string(v))
+	return b, err == nil
+}
+
+func (v envValue) float64() (float64, bool) {
+	f, err := strconv.ParseFloat(string(v), 64)
+	return f, err == nil
+}
+
+func (v envValue) int() (int, bool) {
+	if i, err := strconv.Atoi(string(v)); err == nil {
+		return i, true
+	}
+	f, ok := v.float64()
+	return int(f), ok
+}
+
+func envValidationErrs(missing map[string]map[string]bool) []validationErr {
+	fields := make([]string, 0, len(missing))
+	for field := range missing {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var errs []validationErr
+	for _, field := range fields {
+		names := make([]string, 0, len(missing[field]))
+		for name := range missing[field] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			errs = append(errs, validationErr{
+				Field: field,
+				Msg:   fmt.Sprintf("environment variable %q is not set and no default was provided", name),
+			})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,139 @@
+package pkger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Env(t *testing.T) {
+	const pkgStr = `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Bucket
+      name: ${BUCKET_NAME:-rucket_1}
+      description: bucket for ${ENV} deployment
+      retentionRules:
+        - type: expire
+          everySeconds: ${RETENTION_SECONDS:-3600}
+    - kind: Label
+      name: label_1
+      color: ${LABEL_COLOR}
+`
+
+	t.Run("substitutes provided values", func(t *testing.T) {
+		env := map[string]string{
+			"BUCKET_NAME":       "rucket_prod",
+			"ENV":               "prod",
+			"LABEL_COLOR":       "#FFFFFF",
+			"RETENTION_SECONDS": "7200",
+		}
+
+		pkg, err := Parse(EncodingYAML, FromString(pkgStr), ValidWithEnv(env))
+		require.NoError(t, err)
+
+		buckets := pkg.buckets()
+		require.Len(t, buckets, 1)
+		assert.Equal(t, "rucket_prod", buckets[0].Name())
+		assert.Equal(t, "bucket for prod deployment", buckets[0].Description)
+		assert.Equal(t, 2*time.Hour, buckets[0].RetentionRules.RP())
+
+		labels := pkg.labels()
+		require.Len(t, labels, 1)
+		assert.Equal(t, "#FFFFFF", labels[0].Color)
+	})
+
+	t.Run("uses defaults for unset values", func(t *testing.T) {
+		env := map[string]string{
+			"ENV":         "dev",
+			"LABEL_COLOR": "#000000",
+		}
+
+		pkg, err := Parse(EncodingYAML, FromString(pkgStr), ValidWithEnv(env))
+		require.NoError(t, err)
+
+		buckets := pkg.buckets()
+		require.Len(t, buckets, 1)
+		assert.Equal(t, "rucket_1", buckets[0].Name())
+		assert.Equal(t, time.Hour, buckets[0].RetentionRules.RP())
+	})
+
+	t.Run("errors for unset values without a default", func(t *testing.T) {
+		_, err := Parse(EncodingYAML, FromString(pkgStr), ValidWithEnv(map[string]string{}))
+		require.Error(t, err)
+		require.True(t, IsParseErr(err))
+
+		pErr := err.(*ParseError)
+		require.Len(t, pErr.Resources, 2)
+
+		assert.Equal(t, KindBucket.String(), pErr.Resources[0].Kind)
+		require.Len(t, pErr.Resources[0].ValidationErrs, 1)
+		assert.Equal(t, fieldDescription, pErr.Resources[0].ValidationErrs[0].Field)
+
+		assert.Equal(t, KindLabel.String(), pErr.Resources[1].Kind)
+		require.Len(t, pErr.Resources[1].ValidationErrs, 1)
+		assert.Equal(t, fieldLabelColor, pErr.Resources[1].ValidationErrs[0].Field)
+	})
+
+	t.Run("keeps the values of string fields as strings", func(t *testing.T) {
+		env := map[string]string{
+			"BUCKET_NAME":       "3",
+			"ENV":               "prod",
+			"LABEL_COLOR":       "true",
+			"RETENTION_SECONDS": "7200",
+		}
+
+		pkg, err := Parse(EncodingYAML, FromString(`apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Bucket
+      name: bucket_${BUCKET_NAME}
+      description: ${LABEL_COLOR}
+      retentionRules:
+        - type: expire
+          everySeconds: ${RETENTION_SECONDS}
+    - kind: Label
+      name: label_1
+      description: ${LABEL_COLOR}
+`), ValidWithEnv(env))
+		require.NoError(t, err)
+
+		buckets := pkg.buckets()
+		require.Len(t, buckets, 1)
+		assert.Equal(t, "true", buckets[0].Description)
+		assert.Equal(t, 2*time.Hour, buckets[0].RetentionRules.RP())
+
+		labels := pkg.labels()
+		require.Len(t, labels, 1)
+		assert.Equal(t, "true", labels[0].Description)
+	})
+
+	t.Run("leaves references untouched without an env", func(t *testing.T) {
+		pkg, err := Parse(EncodingYAML, FromString(`apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Label
+      name: label_1
+      description: ${NOT_SUBSTITUTED}
+`))
+		require.NoError(t, err)
+
+		labels := pkg.labels()
+		require.Len(t, labels, 1)
+		assert.Equal(t, "${NOT_SUBSTITUTED}", labels[0].Description)
+	})
+}
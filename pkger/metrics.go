@@ -0,0 +1,55 @@
+package pkger
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type serviceMetrics struct {
+	orgApplies  *prometheus.CounterVec
+	orgDuration *prometheus.HistogramVec
+}
+
+func newServiceMetrics() *serviceMetrics {
+	const (
+		namespace = "pkger"
+		subsystem = "org_apply"
+	)
+
+	// the orgs are left out of the labels, a pkg applied to every org of an
+	// instance would otherwise make a series of each of them
+	labels := []string{"result"}
+	return &serviceMetrics{
+		orgApplies: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "total",
+			Help:      "Number of orgs a pkg was applied to by many orgs at once, by the result of the apply.",
+		}, labels),
+		orgDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "duration_seconds",
+			Help:      "Duration of applying a pkg to one of many orgs, by the result of the apply.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+		}, labels),
+	}
+}
+
+func (m *serviceMetrics) orgApplied(err error, d time.Duration) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.orgApplies.WithLabelValues(result).Inc()
+	m.orgDuration.WithLabelValues(result).Observe(d.Seconds())
+}
+
+// PrometheusCollectors returns the metrics of the pkgs applied to many orgs.
+func (s *Service) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		s.metrics.orgApplies,
+		s.metrics.orgDuration,
+	}
+}
@@ -4,40 +4,58 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	icheck "github.com/influxdata/influxdb/notification/check"
 	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/rule"
 )
 
 // Package kinds.
 const (
 	KindUnknown                       Kind = ""
 	KindBucket                        Kind = "bucket"
+	KindCheck                         Kind = "check"
+	KindCheckDeadman                  Kind = "check_deadman"
+	KindCheckThreshold                Kind = "check_threshold"
 	KindDashboard                     Kind = "dashboard"
 	KindLabel                         Kind = "label"
 	KindNotificationEndpoint          Kind = "notification_endpoint"
 	KindNotificationEndpointPagerDuty Kind = "notification_endpoint_pager_duty"
 	KindNotificationEndpointHTTP      Kind = "notification_endpoint_http"
 	KindNotificationEndpointSlack     Kind = "notification_endpoint_slack"
+	KindNotificationRule              Kind = "notification_rule"
 	KindPackage                       Kind = "package"
+	KindScraperTarget                 Kind = "scraper_target"
+	KindTask                          Kind = "task"
 	KindTelegraf                      Kind = "telegraf"
 	KindVariable                      Kind = "variable"
 )
 
 var kinds = map[Kind]bool{
 	KindBucket:                        true,
+	KindCheckDeadman:                  true,
+	KindCheckThreshold:                true,
 	KindDashboard:                     true,
 	KindLabel:                         true,
 	KindNotificationEndpoint:          true,
 	KindNotificationEndpointHTTP:      true,
 	KindNotificationEndpointPagerDuty: true,
 	KindNotificationEndpointSlack:     true,
+	KindNotificationRule:              true,
 	KindPackage:                       true,
+	KindScraperTarget:                 true,
+	KindTask:                          true,
 	KindTelegraf:                      true,
 	KindVariable:                      true,
 }
@@ -78,6 +96,8 @@ func (k Kind) ResourceType() influxdb.ResourceType {
 	switch k {
 	case KindBucket:
 		return influxdb.BucketsResourceType
+	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+		return influxdb.ChecksResourceType
 	case KindDashboard:
 		return influxdb.DashboardsResourceType
 	case KindLabel:
@@ -87,6 +107,12 @@ func (k Kind) ResourceType() influxdb.ResourceType {
 		KindNotificationEndpointPagerDuty,
 		KindNotificationEndpointSlack:
 		return influxdb.NotificationEndpointResourceType
+	case KindNotificationRule:
+		return influxdb.NotificationRuleResourceType
+	case KindScraperTarget:
+		return influxdb.ScraperResourceType
+	case KindTask:
+		return influxdb.TasksResourceType
 	case KindTelegraf:
 		return influxdb.TelegrafsResourceType
 	case KindVariable:
@@ -132,23 +158,128 @@ type Metadata struct {
 	Description string `yaml:"description" json:"description"`
 	Name        string `yaml:"pkgName" json:"pkgName"`
 	Version     string `yaml:"pkgVersion" json:"pkgVersion"`
+
+	// ExportedAt is the time the pkg was created from existing resources.
+	ExportedAt *time.Time `yaml:"exportedAt,omitempty" json:"exportedAt,omitempty"`
+	// SourceOrgID is the org the resources of the pkg were exported from. It
+	// is only set for a pkg created from all the resources of a single org.
+	SourceOrgID string `yaml:"sourceOrgID,omitempty" json:"sourceOrgID,omitempty"`
 }
 
 // Diff is the result of a service DryRun call. The diff outlines
 // what is new and or updated from the current state of the platform.
 type Diff struct {
 	Buckets               []DiffBucket               `json:"buckets"`
+	Checks                []DiffCheck                `json:"checks"`
+	Collisions            []DiffCollision            `json:"collisions"`
+	Conflicts             []DiffConflict             `json:"conflicts"`
 	Dashboards            []DiffDashboard            `json:"dashboards"`
 	Labels                []DiffLabel                `json:"labels"`
 	LabelMappings         []DiffLabelMapping         `json:"labelMappings"`
 	NotificationEndpoints []DiffNotificationEndpoint `json:"notificationEndpoints"`
+	NotificationRules     []DiffNotificationRule     `json:"notificationRules"`
+	ScraperTargets        []DiffScraperTarget        `json:"scraperTargets"`
+	Tasks                 []DiffTask                 `json:"tasks"`
 	Telegrafs             []DiffTelegraf             `json:"telegrafConfigs"`
 	Variables             []DiffVariable             `json:"variables"`
+
+	// Warnings are the problems of the pkg that do not stop it from being
+	// applied.
+	Warnings []DiffWarning `json:"warnings"`
+
+	// Snapshot indicates the diff is of the existing resources of a snapshot,
+	// not the platform. See DryRunWithSnapshot.
+	Snapshot bool `json:"snapshot,omitempty"`
+}
+
+// MarshalJSON marshals the diff with an empty array in place of every nil
+// slice, so the diff has the same shape regardless of the resources of the pkg.
+func (d Diff) MarshalJSON() ([]byte, error) {
+	type alias Diff
+	a := alias(d)
+
+	a.Buckets = make([]DiffBucket, 0, len(d.Buckets))
+	for _, b := range d.Buckets {
+		b.New.RetentionRules = emptyRetentionRules(b.New.RetentionRules)
+		if b.Old != nil {
+			old := *b.Old
+			old.RetentionRules = emptyRetentionRules(old.RetentionRules)
+			b.Old = &old
+		}
+		a.Buckets = append(a.Buckets, b)
+	}
+
+	a.Dashboards = make([]DiffDashboard, 0, len(d.Dashboards))
+	for _, dash := range d.Dashboards {
+		dash.Charts = emptyDiffCharts(dash.Charts)
+		if dash.Old != nil {
+			old := *dash.Old
+			old.Charts = emptyDiffCharts(old.Charts)
+			dash.Old = &old
+		}
+		a.Dashboards = append(a.Dashboards, dash)
+	}
+
+	if a.Checks == nil {
+		a.Checks = []DiffCheck{}
+	}
+	if a.Collisions == nil {
+		a.Collisions = []DiffCollision{}
+	}
+	if a.Conflicts == nil {
+		a.Conflicts = []DiffConflict{}
+	}
+	if a.Labels == nil {
+		a.Labels = []DiffLabel{}
+	}
+	if a.LabelMappings == nil {
+		a.LabelMappings = []DiffLabelMapping{}
+	}
+	if a.NotificationEndpoints == nil {
+		a.NotificationEndpoints = []DiffNotificationEndpoint{}
+	}
+	if a.NotificationRules == nil {
+		a.NotificationRules = []DiffNotificationRule{}
+	}
+	if a.ScraperTargets == nil {
+		a.ScraperTargets = []DiffScraperTarget{}
+	}
+	if a.Tasks == nil {
+		a.Tasks = []DiffTask{}
+	}
+	if a.Telegrafs == nil {
+		a.Telegrafs = []DiffTelegraf{}
+	}
+	if a.Variables == nil {
+		a.Variables = []DiffVariable{}
+	}
+	if a.Warnings == nil {
+		a.Warnings = []DiffWarning{}
+	}
+	return json.Marshal(a)
+}
+
+func emptyRetentionRules(rules retentionRules) retentionRules {
+	if rules == nil {
+		return retentionRules{}
+	}
+	return rules
+}
+
+func emptyDiffCharts(charts []DiffChart) []DiffChart {
+	if charts == nil {
+		return []DiffChart{}
+	}
+	return charts
 }
 
 // HasConflicts provides a binary t/f if there are any changes within package
 // after dry run is complete.
 func (d Diff) HasConflicts() bool {
+	if len(d.Conflicts) > 0 {
+		return true
+	}
+
 	for _, b := range d.Buckets {
 		if b.hasConflict() {
 			return true
@@ -170,8 +301,31 @@ func (d Diff) HasConflicts() bool {
 	return false
 }
 
+// DiffConflict describes a resource the service treats specially when the
+// pkg is applied. These are surfaced so they can be reviewed before applying.
+type DiffConflict struct {
+	Kind   Kind   `json:"kind"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// DiffWarning describes a problem of the pkg that does not stop it from being
+// applied, i.e. a chart querying a bucket that will not exist once the pkg is
+// applied. The bucket may still be created by other means, so it is left to
+// be reviewed.
+type DiffWarning struct {
+	Kind    Kind   `json:"kind"`
+	Name    string `json:"name"`
+	Chart   string `json:"chart,omitempty"`
+	Bucket  string `json:"bucket,omitempty"`
+	Message string `json:"message"`
+}
+
 // DiffBucketValues are the varying values for a bucket.
 type DiffBucketValues struct {
+	// DisplayName is the name of the bucket in the platform, when it differs
+	// from the name of the bucket in the pkg.
+	DisplayName    string         `json:"displayName,omitempty"`
 	Description    string         `json:"description"`
 	RetentionRules retentionRules `json:"retentionRules"`
 }
@@ -182,12 +336,17 @@ type DiffBucket struct {
 	Name string            `json:"name"`
 	New  DiffBucketValues  `json:"new"`
 	Old  *DiffBucketValues `json:"old,omitempty"` // using omitempty here to signal there was no prev state with a nil
+
+	// Remove indicates the bucket is in the current pkg only. Old holds the
+	// bucket as the current pkg defines it, the proposed pkg drops it.
+	Remove bool `json:"remove,omitempty"`
 }
 
 func newDiffBucket(b *bucket, i *influxdb.Bucket) DiffBucket {
 	diff := DiffBucket{
 		Name: b.Name(),
 		New: DiffBucketValues{
+			DisplayName:    displayNameDiff(b.Name(), b.platformName()),
 			Description:    b.Description,
 			RetentionRules: b.RetentionRules,
 		},
@@ -195,6 +354,7 @@ func newDiffBucket(b *bucket, i *influxdb.Bucket) DiffBucket {
 	if i != nil {
 		diff.ID = SafeID(i.ID)
 		diff.Old = &DiffBucketValues{
+			DisplayName: displayNameDiff(b.Name(), i.Name),
 			Description: i.Description,
 		}
 		if i.RetentionPeriod > 0 {
@@ -206,35 +366,109 @@ func newDiffBucket(b *bucket, i *influxdb.Bucket) DiffBucket {
 
 // IsNew indicates whether a pkg bucket is going to be new to the platform.
 func (d DiffBucket) IsNew() bool {
-	return d.ID == SafeID(0)
+	return d.Old == nil
 }
 
 func (d DiffBucket) hasConflict() bool {
 	return !d.IsNew() && d.Old != nil && !reflect.DeepEqual(*d.Old, d.New)
 }
 
+// DiffCheckValues are the varying values for a check.
+type DiffCheckValues struct {
+	influxdb.Check
+}
+
+// UnmarshalJSON decodes the check. The check is an interface, its concrete
+// type is decoded from the type of the check.
+func (d *DiffCheckValues) UnmarshalJSON(b []byte) (err error) {
+	d.Check, err = icheck.UnmarshalJSON(b)
+	return err
+}
+
+// DiffCheck is a diff of an individual check.
+type DiffCheck struct {
+	ID   SafeID           `json:"id"`
+	Name string           `json:"name"`
+	New  DiffCheckValues  `json:"new"`
+	Old  *DiffCheckValues `json:"old,omitempty"` // using omitempty here to signal there was no prev state with a nil
+
+	// Remove indicates the check is dropped by the proposed pkg. The check
+	// of the current pkg is kept in Old, New is left empty.
+	Remove bool `json:"remove,omitempty"`
+}
+
+func newDiffCheck(c *check, iCheck influxdb.Check) DiffCheck {
+	diff := DiffCheck{
+		Name: c.Name(),
+		New: DiffCheckValues{
+			Check: c.summarize().Check,
+		},
+	}
+	if iCheck != nil {
+		diff.ID = SafeID(iCheck.GetID())
+		diff.Old = &DiffCheckValues{
+			Check: iCheck,
+		}
+	}
+	return diff
+}
+
+// IsNew indicates whether a pkg check is going to be new to the platform.
+func (d DiffCheck) IsNew() bool {
+	return d.Old == nil
+}
+
 // DiffDashboard is a diff of an individual dashboard.
 type DiffDashboard struct {
+	// ID is the ID of the existing dashboard left unchanged by an apply
+	// with only the changes.
+	ID     SafeID      `json:"id,omitempty"`
 	Name   string      `json:"name"`
 	Desc   string      `json:"description"`
 	Charts []DiffChart `json:"charts"`
+
+	// Old is the prior state of a modified dashboard, provided by a diff of
+	// two pkgs. A dry run provides it for the existing dashboard left
+	// unchanged, any other dashboard is created anew.
+	Old *DiffDashboardValues `json:"old,omitempty"`
+
+	// Remove indicates the dashboard is only in the current pkg of a diff of
+	// two pkgs, its description and charts are in Old.
+	Remove bool `json:"remove,omitempty"`
+}
+
+// DiffDashboardValues are the varying values for a dashboard.
+type DiffDashboardValues struct {
+	Desc   string      `json:"description"`
+	Charts []DiffChart `json:"charts"`
 }
 
 func newDiffDashboard(d *dashboard) DiffDashboard {
 	diff := DiffDashboard{
-		Name: d.Name(),
-		Desc: d.Description,
+		Name:   d.Name(),
+		Desc:   d.Description,
+		Charts: newDiffCharts(d.Charts),
+	}
+	if d.existing != nil {
+		diff.ID = SafeID(d.existing.ID)
+		diff.Old = &DiffDashboardValues{
+			Desc:   d.existing.Description,
+			Charts: newDiffCharts(cellCharts(d.existing.Cells)),
+		}
 	}
+	return diff
+}
 
-	for _, c := range d.Charts {
-		diff.Charts = append(diff.Charts, DiffChart{
+func newDiffCharts(charts []chart) []DiffChart {
+	var diffs []DiffChart
+	for _, c := range charts {
+		diffs = append(diffs, DiffChart{
 			Properties: c.properties(),
 			Height:     c.Height,
 			Width:      c.Width,
 		})
 	}
-
-	return diff
+	return diffs
 }
 
 // DiffChart is a diff of oa chart. Since all charts are new right now.
@@ -243,6 +477,9 @@ type DiffChart SummaryChart
 
 // DiffLabelValues are the varying values for a label.
 type DiffLabelValues struct {
+	// DisplayName is the name of the label in the platform, when it differs
+	// from the name of the label in the pkg.
+	DisplayName string `json:"displayName,omitempty"`
 	Color       string `json:"color"`
 	Description string `json:"description"`
 }
@@ -253,11 +490,55 @@ type DiffLabel struct {
 	Name string           `json:"name"`
 	New  DiffLabelValues  `json:"new"`
 	Old  *DiffLabelValues `json:"old,omitempty"` // using omitempty here to signal there was no prev state with a nil
+
+	// Properties are the old and new values of each property of an existing
+	// label, marking the properties that are changed by the pkg.
+	Properties *DiffLabelProperties `json:"properties,omitempty"`
+
+	// Remove indicates no resource of the proposed pkg is labelled with
+	// the label any longer, it is only defined by the current pkg.
+	Remove bool `json:"remove,omitempty"`
+}
+
+// DiffLabelProperties are the properties of an existing label and how they are
+// changed by a pkg. The name is the name of the label in the platform.
+type DiffLabelProperties struct {
+	Name        DiffLabelProperty `json:"name"`
+	Color       DiffLabelProperty `json:"color"`
+	Description DiffLabelProperty `json:"description"`
+}
+
+func newDiffLabelProperties(l *label, existing influxdb.Label) DiffLabelProperties {
+	return DiffLabelProperties{
+		Name:        newDiffLabelProperty(existing.Name, l.platformName()),
+		Color:       newDiffLabelProperty(existing.Properties["color"], l.Color),
+		Description: newDiffLabelProperty(existing.Properties["description"], l.Description),
+	}
+}
+
+// Changed indicates whether any property of the label is changed.
+func (d DiffLabelProperties) Changed() bool {
+	return d.Name.Changed || d.Color.Changed || d.Description.Changed
+}
+
+// DiffLabelProperty is the old and new value of a label property.
+type DiffLabelProperty struct {
+	Old     string `json:"old"`
+	New     string `json:"new"`
+	Changed bool   `json:"changed"`
+}
+
+func newDiffLabelProperty(oldVal, newVal string) DiffLabelProperty {
+	return DiffLabelProperty{
+		Old:     oldVal,
+		New:     newVal,
+		Changed: oldVal != newVal,
+	}
 }
 
 // IsNew indicates whether a pkg label is going to be new to the platform.
 func (d DiffLabel) IsNew() bool {
-	return d.ID == SafeID(0)
+	return d.Old == nil
 }
 
 func (d DiffLabel) hasConflict() bool {
@@ -268,6 +549,7 @@ func newDiffLabel(l *label, i *influxdb.Label) DiffLabel {
 	diff := DiffLabel{
 		Name: l.Name(),
 		New: DiffLabelValues{
+			DisplayName: displayNameDiff(l.Name(), l.platformName()),
 			Color:       l.Color,
 			Description: l.Description,
 		},
@@ -275,9 +557,12 @@ func newDiffLabel(l *label, i *influxdb.Label) DiffLabel {
 	if i != nil {
 		diff.ID = SafeID(i.ID)
 		diff.Old = &DiffLabelValues{
+			DisplayName: displayNameDiff(l.Name(), i.Name),
 			Color:       i.Properties["color"],
 			Description: i.Properties["description"],
 		}
+		props := newDiffLabelProperties(l, *i)
+		diff.Properties = &props
 	}
 	return diff
 }
@@ -294,6 +579,10 @@ type DiffLabelMapping struct {
 
 	LabelID   SafeID `json:"labelID"`
 	LabelName string `json:"labelName"`
+
+	// Remove indicates the resource is labelled with the label by the
+	// current pkg of a diff of two pkgs, but not by the proposed pkg.
+	Remove bool `json:"remove,omitempty"`
 }
 
 // DiffNotificationEndpointValues are the varying values for a notification endpoint.
@@ -317,6 +606,10 @@ type DiffNotificationEndpoint struct {
 	Name string                          `json:"name"`
 	New  DiffNotificationEndpointValues  `json:"new"`
 	Old  *DiffNotificationEndpointValues `json:"old,omitempty"` // using omitempty here to signal there was no prev state with a nil
+
+	// Remove indicates the endpoint is only in the current pkg. The rules
+	// of the proposed pkg can no longer notify it.
+	Remove bool `json:"remove,omitempty"`
 }
 
 func newDiffNotificationEndpoint(ne *notificationEndpoint, i influxdb.NotificationEndpoint) DiffNotificationEndpoint {
@@ -341,19 +634,244 @@ func (d DiffNotificationEndpoint) IsNew() bool {
 	return d.Old == nil
 }
 
+// DiffNotificationRuleValues are the varying values for a notification rule.
+type DiffNotificationRuleValues struct {
+	Name            string              `json:"name"`
+	Description     string              `json:"description"`
+	EndpointID      SafeID              `json:"endpointID"`
+	EndpointName    string              `json:"endpointName"`
+	EndpointType    string              `json:"endpointType"`
+	Every           string              `json:"every"`
+	Offset          string              `json:"offset"`
+	MessageTemplate string              `json:"messageTemplate"`
+	StatusRules     []SummaryStatusRule `json:"statusRules"`
+	TagRules        []SummaryTagRule    `json:"tagRules"`
+}
+
+// DiffNotificationRule is a diff of an individual notification rule.
+type DiffNotificationRule struct {
+	ID   SafeID                      `json:"id"`
+	Name string                      `json:"name"`
+	New  DiffNotificationRuleValues  `json:"new"`
+	Old  *DiffNotificationRuleValues `json:"old,omitempty"` // using omitempty here to signal there was no prev state with a nil
+
+	// Remove indicates the proposed pkg no longer has the rule, its values
+	// in the current pkg are kept in Old.
+	Remove bool `json:"remove,omitempty"`
+}
+
+func newDiffNotificationRule(r *notificationRule, iRule influxdb.NotificationRule, iEndpoint influxdb.NotificationEndpoint) DiffNotificationRule {
+	diff := DiffNotificationRule{
+		Name: r.Name(),
+		New:  newDiffNotificationRuleValues(r.summarize()),
+	}
+	if iRule == nil {
+		return diff
+	}
+
+	diff.ID = SafeID(iRule.GetID())
+	base, msgTemplate, _ := notificationRuleBase(iRule)
+	diff.Old = &DiffNotificationRuleValues{
+		Name:            iRule.GetName(),
+		Description:     iRule.GetDescription(),
+		EndpointID:      SafeID(iRule.GetEndpointID()),
+		EndpointType:    iRule.Type(),
+		Every:           durationToStr(base.Every),
+		Offset:          durationToStr(base.Offset),
+		MessageTemplate: msgTemplate,
+	}
+	if iEndpoint != nil {
+		diff.Old.EndpointName = iEndpoint.GetName()
+	}
+	for _, sr := range base.StatusRules {
+		diff.Old.StatusRules = append(diff.Old.StatusRules, toSummaryStatusRule(sr))
+	}
+	for _, tr := range base.TagRules {
+		diff.Old.TagRules = append(diff.Old.TagRules, SummaryTagRule{
+			Key:      tr.Key,
+			Value:    tr.Value,
+			Operator: tr.Operator.String(),
+		})
+	}
+	return diff
+}
+
+func newDiffNotificationRuleValues(sum SummaryNotificationRule) DiffNotificationRuleValues {
+	return DiffNotificationRuleValues{
+		Name:            sum.Name,
+		Description:     sum.Description,
+		EndpointID:      sum.EndpointID,
+		EndpointName:    sum.EndpointName,
+		EndpointType:    sum.EndpointType,
+		Every:           sum.Every,
+		Offset:          sum.Offset,
+		MessageTemplate: sum.MessageTemplate,
+		StatusRules:     sum.StatusRules,
+		TagRules:        sum.TagRules,
+	}
+}
+
+// IsNew indicates if the resource will be new to the platform or if it edits
+// an existing resource.
+func (d DiffNotificationRule) IsNew() bool {
+	return d.Old == nil
+}
+
+// DiffScraperTargetValues are the varying values for a scraper target.
+type DiffScraperTargetValues struct {
+	Type   influxdb.ScraperType `json:"type"`
+	URL    string               `json:"url"`
+	Bucket string               `json:"bucket"`
+}
+
+// DiffScraperTarget is a diff of an individual scraper target.
+type DiffScraperTarget struct {
+	ID   SafeID                   `json:"id"`
+	Name string                   `json:"name"`
+	New  DiffScraperTargetValues  `json:"new"`
+	Old  *DiffScraperTargetValues `json:"old,omitempty"` // using omitempty here to signal there was no prev state with a nil
+}
+
+func newDiffScraperTarget(t *scraperTarget, i *influxdb.ScraperTarget, existingBucket string) DiffScraperTarget {
+	diff := DiffScraperTarget{
+		Name: t.Name(),
+		New: DiffScraperTargetValues{
+			Type:   t.typ,
+			URL:    t.url,
+			Bucket: t.bucket,
+		},
+	}
+	if i != nil {
+		diff.ID = SafeID(i.ID)
+		diff.Old = &DiffScraperTargetValues{
+			Type:   i.Type,
+			URL:    i.URL,
+			Bucket: existingBucket,
+		}
+	}
+	return diff
+}
+
+// IsNew indicates whether a pkg scraper target is going to be new to the platform.
+func (d DiffScraperTarget) IsNew() bool {
+	return d.Old == nil
+}
+
+// DiffTaskValues are the varying values for a task.
+type DiffTaskValues struct {
+	Cron        string          `json:"cron,omitempty"`
+	Description string          `json:"description"`
+	Every       string          `json:"every,omitempty"`
+	Offset      string          `json:"offset,omitempty"`
+	Query       string          `json:"query"`
+	Status      influxdb.Status `json:"status"`
+}
+
+// DiffTask is a diff of an individual task. Tasks are never updated, a dry
+// run leaves Old nil, every task of the pkg is created when it is applied.
+type DiffTask struct {
+	ID   SafeID          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	New  DiffTaskValues  `json:"new"`
+	Old  *DiffTaskValues `json:"old,omitempty"` // using omitempty here to signal there was no prev state with a nil
+
+	// Remove indicates the task is only in the current pkg of a diff of two
+	// pkgs. Old holds the task of the current pkg, New is left empty.
+	Remove bool `json:"remove,omitempty"`
+}
+
+func newDiffTask(t *task) DiffTask {
+	return DiffTask{
+		ID:   SafeID(t.ID()),
+		Name: t.Name(),
+		New:  t.diffValues(),
+	}
+}
+
+// IsNew indicates whether a pkg task is going to be new to the platform.
+func (d DiffTask) IsNew() bool {
+	return d.Old == nil
+}
+
 // DiffTelegraf is a diff of an individual telegraf.
 type DiffTelegraf struct {
 	influxdb.TelegrafConfig
+
+	// Old is the config of a modified telegraf in the current pkg of a diff
+	// of two pkgs. Telegrafs are never updated, a dry run leaves it nil.
+	Old *influxdb.TelegrafConfig
+
+	// Remove indicates the telegraf config is only in the current pkg of a
+	// diff of two pkgs. Only its name is set, the config is in Old.
+	Remove bool
+}
+
+// MarshalJSON marshals the telegraf config with the old config and removal
+// alongside its fields. The telegraf config marshals itself, its marshaler
+// would otherwise drop them.
+func (d DiffTelegraf) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(&d.TelegrafConfig)
+	if err != nil {
+		return nil, err
+	}
+	if d.Old == nil && !d.Remove {
+		return b, nil
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	if d.Old != nil {
+		old, err := json.Marshal(d.Old)
+		if err != nil {
+			return nil, err
+		}
+		m["old"] = old
+	}
+	if d.Remove {
+		m["remove"] = json.RawMessage("true")
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decodes the telegraf config along with its old config and
+// removal.
+func (d *DiffTelegraf) UnmarshalJSON(b []byte) error {
+	var cfg influxdb.TelegrafConfig
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return err
+	}
+
+	var diff struct {
+		Old    *influxdb.TelegrafConfig `json:"old"`
+		Remove bool                     `json:"remove"`
+	}
+	if err := json.Unmarshal(b, &diff); err != nil {
+		return err
+	}
+
+	*d = DiffTelegraf{
+		TelegrafConfig: cfg,
+		Old:            diff.Old,
+		Remove:         diff.Remove,
+	}
+	return nil
 }
 
 func newDiffTelegraf(t *telegraf) DiffTelegraf {
+	cfg := t.config
+	cfg.ID = t.ID()
 	return DiffTelegraf{
-		TelegrafConfig: t.config,
+		TelegrafConfig: cfg,
 	}
 }
 
 // DiffVariableValues are the varying values for a variable.
 type DiffVariableValues struct {
+	// DisplayName is the name of the variable in the platform, when it differs
+	// from the name of the variable in the pkg.
+	DisplayName string                      `json:"displayName,omitempty"`
 	Description string                      `json:"description"`
 	Args        *influxdb.VariableArguments `json:"args"`
 }
@@ -364,12 +882,17 @@ type DiffVariable struct {
 	Name string              `json:"name"`
 	New  DiffVariableValues  `json:"new"`
 	Old  *DiffVariableValues `json:"old,omitempty"` // using omitempty here to signal there was no prev state with a nil
+
+	// Remove indicates the variable is defined by the current pkg only, the
+	// arguments it is defined with are in Old.
+	Remove bool `json:"remove,omitempty"`
 }
 
 func newDiffVariable(v *variable, iv *influxdb.Variable) DiffVariable {
 	diff := DiffVariable{
 		Name: v.Name(),
 		New: DiffVariableValues{
+			DisplayName: displayNameDiff(v.Name(), v.platformName()),
 			Description: v.Description,
 			Args:        v.influxVarArgs(),
 		},
@@ -377,8 +900,9 @@ func newDiffVariable(v *variable, iv *influxdb.Variable) DiffVariable {
 	if iv != nil {
 		diff.ID = SafeID(iv.ID)
 		diff.Old = &DiffVariableValues{
+			DisplayName: displayNameDiff(v.Name(), iv.Name),
 			Description: iv.Description,
-			Args:        iv.Arguments,
+			Args:        normalizedVarArgs(iv.Arguments),
 		}
 	}
 
@@ -387,7 +911,7 @@ func newDiffVariable(v *variable, iv *influxdb.Variable) DiffVariable {
 
 // IsNew indicates whether a pkg variable is going to be new to the platform.
 func (d DiffVariable) IsNew() bool {
-	return d.ID == SafeID(0)
+	return d.Old == nil
 }
 
 func (d DiffVariable) hasConflict() bool {
@@ -398,12 +922,117 @@ func (d DiffVariable) hasConflict() bool {
 // will be created from a pkg.
 type Summary struct {
 	Buckets               []SummaryBucket               `json:"buckets"`
+	Checks                []SummaryCheck                `json:"checks"`
 	Dashboards            []SummaryDashboard            `json:"dashboards"`
 	NotificationEndpoints []SummaryNotificationEndpoint `json:"notificationEndpoints"`
+	NotificationRules     []SummaryNotificationRule     `json:"notificationRules"`
 	Labels                []SummaryLabel                `json:"labels"`
 	LabelMappings         []SummaryLabelMapping         `json:"labelMappings"`
+	ScraperTargets        []SummaryScraperTarget        `json:"scraperTargets"`
+	Tasks                 []SummaryTask                 `json:"tasks"`
 	TelegrafConfigs       []SummaryTelegraf             `json:"telegrafConfigs"`
 	Variables             []SummaryVariable             `json:"variables"`
+
+	// Skipped are the resources of the pkg that were not acted on.
+	Skipped []SummarySkippedResource `json:"skipped,omitempty"`
+
+	// Statuses are the status of each resource of an applied pkg, and
+	// StatusCounts the number of resources of each kind by status. Only an
+	// apply returns a summary with statuses.
+	Statuses     []SummaryResourceStatus     `json:"statuses,omitempty"`
+	StatusCounts map[Kind]SummaryStatusCount `json:"statusCounts,omitempty"`
+
+	// Errors are the resources of the pkg that failed to apply. Only a best
+	// effort apply returns a summary with errors.
+	Errors []ApplyError `json:"errors,omitempty"`
+
+	// ContentHash is the hash of the canonicalized pkg that was dry run or
+	// applied. Comparing the hash of a dry run with the hash of an apply
+	// detects a pkg that was modified in between.
+	ContentHash string `json:"contentHash,omitempty"`
+}
+
+// MarshalJSON marshals the summary with an empty array in place of every nil
+// slice, so the summary has the same shape regardless of the resources of the pkg.
+func (s Summary) MarshalJSON() ([]byte, error) {
+	type alias Summary
+	a := alias(s)
+
+	a.Buckets = make([]SummaryBucket, 0, len(s.Buckets))
+	for _, b := range s.Buckets {
+		b.LabelAssociations = emptySummaryLabels(b.LabelAssociations)
+		a.Buckets = append(a.Buckets, b)
+	}
+
+	a.Checks = make([]SummaryCheck, 0, len(s.Checks))
+	for _, c := range s.Checks {
+		c.LabelAssociations = emptySummaryLabels(c.LabelAssociations)
+		a.Checks = append(a.Checks, c)
+	}
+
+	a.Dashboards = make([]SummaryDashboard, 0, len(s.Dashboards))
+	for _, d := range s.Dashboards {
+		if d.Charts == nil {
+			d.Charts = []SummaryChart{}
+		}
+		d.LabelAssociations = emptySummaryLabels(d.LabelAssociations)
+		a.Dashboards = append(a.Dashboards, d)
+	}
+
+	a.NotificationEndpoints = make([]SummaryNotificationEndpoint, 0, len(s.NotificationEndpoints))
+	for _, e := range s.NotificationEndpoints {
+		e.LabelAssociations = emptySummaryLabels(e.LabelAssociations)
+		a.NotificationEndpoints = append(a.NotificationEndpoints, e)
+	}
+
+	a.NotificationRules = make([]SummaryNotificationRule, 0, len(s.NotificationRules))
+	for _, r := range s.NotificationRules {
+		if r.StatusRules == nil {
+			r.StatusRules = []SummaryStatusRule{}
+		}
+		if r.TagRules == nil {
+			r.TagRules = []SummaryTagRule{}
+		}
+		r.LabelAssociations = emptySummaryLabels(r.LabelAssociations)
+		a.NotificationRules = append(a.NotificationRules, r)
+	}
+
+	a.Labels = emptySummaryLabels(s.Labels)
+	if a.LabelMappings == nil {
+		a.LabelMappings = []SummaryLabelMapping{}
+	}
+
+	a.ScraperTargets = make([]SummaryScraperTarget, 0, len(s.ScraperTargets))
+	for _, t := range s.ScraperTargets {
+		t.LabelAssociations = emptySummaryLabels(t.LabelAssociations)
+		a.ScraperTargets = append(a.ScraperTargets, t)
+	}
+
+	a.Tasks = make([]SummaryTask, 0, len(s.Tasks))
+	for _, t := range s.Tasks {
+		t.LabelAssociations = emptySummaryLabels(t.LabelAssociations)
+		a.Tasks = append(a.Tasks, t)
+	}
+
+	a.TelegrafConfigs = make([]SummaryTelegraf, 0, len(s.TelegrafConfigs))
+	for _, t := range s.TelegrafConfigs {
+		t.LabelAssociations = emptySummaryLabels(t.LabelAssociations)
+		a.TelegrafConfigs = append(a.TelegrafConfigs, t)
+	}
+
+	a.Variables = make([]SummaryVariable, 0, len(s.Variables))
+	for _, v := range s.Variables {
+		v.LabelAssociations = emptySummaryLabels(v.LabelAssociations)
+		a.Variables = append(a.Variables, v)
+	}
+	return json.Marshal(a)
+}
+
+func emptySummaryLabels(labels []SummaryLabel) []SummaryLabel {
+	if labels == nil {
+		return []SummaryLabel{}
+	}
+	return labels
 }
 
 // SummaryBucket provides a summary of a pkg bucket.
@@ -417,13 +1046,40 @@ type SummaryBucket struct {
 	LabelAssociations []SummaryLabel `json:"labelAssociations"`
 }
 
+// SummaryCheck provides a summary of a pkg check.
+type SummaryCheck struct {
+	Check             influxdb.Check  `json:"check"`
+	Status            influxdb.Status `json:"status"`
+	LabelAssociations []SummaryLabel  `json:"labelAssociations"`
+}
+
+// UnmarshalJSON unmarshals the check. This is necessary b/c the check is an
+// interface, its concrete type is decoded from the type of the check.
+func (s *SummaryCheck) UnmarshalJSON(b []byte) error {
+	var a struct {
+		Check             json.RawMessage `json:"check"`
+		Status            influxdb.Status `json:"status"`
+		LabelAssociations []SummaryLabel  `json:"labelAssociations"`
+	}
+	if err := json.Unmarshal(b, &a); err != nil {
+		return err
+	}
+	s.Status = a.Status
+	s.LabelAssociations = a.LabelAssociations
+
+	c, err := icheck.UnmarshalJSON(a.Check)
+	s.Check = c
+	return err
+}
+
 // SummaryDashboard provides a summary of a pkg dashboard.
 type SummaryDashboard struct {
-	ID          SafeID         `json:"id"`
-	OrgID       SafeID         `json:"orgID"`
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	Charts      []SummaryChart `json:"charts"`
+	ID              SafeID         `json:"id"`
+	OrgID           SafeID         `json:"orgID"`
+	Name            string         `json:"name"`
+	Description     string         `json:"description"`
+	RefreshInterval time.Duration  `json:"refreshInterval,omitempty"`
+	Charts          []SummaryChart `json:"charts"`
 
 	LabelAssociations []SummaryLabel `json:"labelAssociations"`
 }
@@ -470,6 +1126,8 @@ type SummaryChart struct {
 	YPosition int `json:"yPos"`
 	Height    int `json:"height"`
 	Width     int `json:"width"`
+
+	RefreshInterval time.Duration `json:"refreshInterval,omitempty"`
 }
 
 // MarshalJSON marshals a summary chart.
@@ -534,6 +1192,43 @@ func (s *SummaryNotificationEndpoint) UnmarshalJSON(b []byte) error {
 	return err
 }
 
+// SummaryNotificationRule provides a summary of a pkg notification rule.
+type SummaryNotificationRule struct {
+	ID          SafeID `json:"id,omitempty"`
+	OrgID       SafeID `json:"orgID,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// EndpointID, EndpointName and EndpointType identify the endpoint the
+	// rule notifies. The id and type of an endpoint not in the pkg are
+	// only known after a dry run.
+	EndpointID   SafeID `json:"endpointID,omitempty"`
+	EndpointName string `json:"endpointName"`
+	EndpointType string `json:"endpointType"`
+
+	Channel           string              `json:"channel,omitempty"`
+	Every             string              `json:"every"`
+	Offset            string              `json:"offset"`
+	MessageTemplate   string              `json:"messageTemplate"`
+	Status            influxdb.Status     `json:"status"`
+	StatusRules       []SummaryStatusRule `json:"statusRules"`
+	TagRules          []SummaryTagRule    `json:"tagRules"`
+	LabelAssociations []SummaryLabel      `json:"labelAssociations"`
+}
+
+// SummaryStatusRule provides a summary of a status rule of a notification rule.
+type SummaryStatusRule struct {
+	CurrentLevel  string `json:"currentLevel"`
+	PreviousLevel string `json:"previousLevel,omitempty"`
+}
+
+// SummaryTagRule provides a summary of a tag rule of a notification rule.
+type SummaryTagRule struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Operator string `json:"operator"`
+}
+
 // SummaryLabel provides a summary of a pkg label.
 type SummaryLabel struct {
 	ID         SafeID `json:"id"`
@@ -555,6 +1250,32 @@ type SummaryLabelMapping struct {
 	LabelID      SafeID                `json:"labelID"`
 }
 
+// SummaryScraperTarget provides a summary of a pkg scraper target.
+type SummaryScraperTarget struct {
+	ID                SafeID               `json:"id,omitempty"`
+	OrgID             SafeID               `json:"orgID,omitempty"`
+	Name              string               `json:"name"`
+	Type              influxdb.ScraperType `json:"type"`
+	URL               string               `json:"url"`
+	BucketID          SafeID               `json:"bucketID,omitempty"`
+	Bucket            string               `json:"bucket"`
+	LabelAssociations []SummaryLabel       `json:"labelAssociations"`
+}
+
+// SummaryTask provides a summary of a pkg task.
+type SummaryTask struct {
+	ID          SafeID          `json:"id,omitempty"`
+	Name        string          `json:"name"`
+	Cron        string          `json:"cron,omitempty"`
+	Description string          `json:"description"`
+	Every       string          `json:"every,omitempty"`
+	Offset      string          `json:"offset,omitempty"`
+	Query       string          `json:"query"`
+	Status      influxdb.Status `json:"status"`
+
+	LabelAssociations []SummaryLabel `json:"labelAssociations"`
+}
+
 // SummaryTelegraf provides a summary of a pkg telegraf config.
 type SummaryTelegraf struct {
 	TelegrafConfig    influxdb.TelegrafConfig `json:"telegrafConfig"`
@@ -571,55 +1292,222 @@ type SummaryVariable struct {
 	LabelAssociations []SummaryLabel              `json:"labelAssociations"`
 }
 
-const (
-	fieldAssociations = "associations"
-	fieldDescription  = "description"
-	fieldKey          = "key"
-	fieldKind         = "kind"
-	fieldLanguage     = "language"
-	fieldName         = "name"
-	fieldPrefix       = "prefix"
-	fieldQuery        = "query"
-	fieldSuffix       = "suffix"
-	fieldStatus       = "status"
-	fieldType         = "type"
-	fieldValue        = "value"
-	fieldValues       = "values"
-)
+// SummarySkippedResource provides a summary of a pkg resource that was not
+// acted on, and the reason why.
+type SummarySkippedResource struct {
+	Kind   Kind   `json:"kind"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ApplyStatus is the outcome of applying a single resource of a pkg.
+type ApplyStatus string
 
+// the statuses of an applied resource.
 const (
-	fieldBucketRetentionRules = "retentionRules"
+	ApplyStatusCreated   ApplyStatus = "created"
+	ApplyStatusUpdated   ApplyStatus = "updated"
+	ApplyStatusUnchanged ApplyStatus = "unchanged"
+	ApplyStatusSkipped   ApplyStatus = "skipped"
+	ApplyStatusFailed    ApplyStatus = "failed"
 )
 
-type bucket struct {
-	id             influxdb.ID
-	OrgID          influxdb.ID
-	Description    string
-	name           string
-	RetentionRules retentionRules
-	labels         sortedLabels
-
-	// existing provides context for a resource that already
-	// exists in the platform. If a resource already exists
-	// then it will be referenced here.
-	existing *influxdb.Bucket
+// SummaryResourceStatus is the status of a resource of an applied pkg. The ID
+// is the ID of the resource applied, a resource that failed or was skipped
+// has none.
+type SummaryResourceStatus struct {
+	Kind   Kind        `json:"kind"`
+	Name   string      `json:"name"`
+	ID     SafeID      `json:"id,omitempty"`
+	Status ApplyStatus `json:"status"`
 }
 
-func (b *bucket) ID() influxdb.ID {
-	if b.existing != nil {
-		return b.existing.ID
-	}
-	return b.id
+// SummaryStatusCount is the number of resources of a kind by their status.
+type SummaryStatusCount struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+	Failed    int `json:"failed"`
 }
 
-func (b *bucket) Labels() []*label {
-	return b.labels
+func sortResourceStatuses(statuses []SummaryResourceStatus) {
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Kind != statuses[j].Kind {
+			return statuses[i].Kind < statuses[j].Kind
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+}
+
+func countResourceStatuses(statuses []SummaryResourceStatus) map[Kind]SummaryStatusCount {
+	if len(statuses) == 0 {
+		return nil
+	}
+
+	counts := make(map[Kind]SummaryStatusCount)
+	for _, st := range statuses {
+		c := counts[st.Kind]
+		switch st.Status {
+		case ApplyStatusCreated:
+			c.Created++
+		case ApplyStatusUpdated:
+			c.Updated++
+		case ApplyStatusUnchanged:
+			c.Unchanged++
+		case ApplyStatusSkipped:
+			c.Skipped++
+		case ApplyStatusFailed:
+			c.Failed++
+		}
+		counts[st.Kind] = c
+	}
+	return counts
+}
+
+// ApplyError is a resource of the pkg that failed to apply, and the reason why.
+type ApplyError struct {
+	Resource string `json:"resource"`
+	Name     string `json:"name"`
+	Msg      string `json:"message"`
+}
+
+// Error returns the error message of the failed resource.
+func (e ApplyError) Error() string {
+	return fmt.Sprintf("resource_type=%q name=%q err_msg=%q", e.Resource, e.Name, e.Msg)
+}
+
+// RollbackResult is the outcome of rolling back the resources of a resource
+// type applied by a failed apply.
+type RollbackResult struct {
+	Resource string `json:"resource"`
+	Msg      string `json:"message,omitempty"`
+}
+
+// Failed returns whether the rollback of the resources failed.
+func (r RollbackResult) Failed() bool {
+	return r.Msg != ""
+}
+
+// RollbackReport reports the rollback of a failed apply. The results are in
+// the order the resources were rolled back, the reverse of the order they
+// were applied in.
+type RollbackReport struct {
+	Results []RollbackResult `json:"results"`
+}
+
+// Failures returns the results of the resources which failed to roll back.
+func (r RollbackReport) Failures() []RollbackResult {
+	var failures []RollbackResult
+	for _, res := range r.Results {
+		if res.Failed() {
+			failures = append(failures, res)
+		}
+	}
+	return failures
+}
+
+// RollbackError is the error of an apply which failed and was rolled back,
+// it reports the rollback alongside the error of the apply.
+type RollbackError struct {
+	Err    error
+	Report RollbackReport
+}
+
+// Error returns the error of the apply, followed by the resources which
+// failed to roll back if any.
+func (e *RollbackError) Error() string {
+	failures := e.Report.Failures()
+	if len(failures) == 0 {
+		return e.Err.Error()
+	}
+
+	msg := e.Err.Error() + "\nfailed to roll back:"
+	for _, f := range failures {
+		msg += fmt.Sprintf("\n\tresource_type=%q err_msg=%q", f.Resource, f.Msg)
+	}
+	return msg
+}
+
+// Unwrap returns the error of the apply.
+func (e *RollbackError) Unwrap() error {
+	return e.Err
+}
+
+const (
+	fieldAssociations = "associations"
+	fieldDescription  = "description"
+	fieldDisplayName  = "displayName"
+	fieldKey          = "key"
+	fieldKind         = "kind"
+	fieldLanguage     = "language"
+	fieldName         = "name"
+	fieldPrefix       = "prefix"
+	fieldQuery        = "query"
+	fieldSuffix       = "suffix"
+	fieldStatus       = "status"
+	fieldType         = "type"
+	fieldValue        = "value"
+	fieldValues       = "values"
+)
+
+// platformName is the name a resource is written to the platform with. The
+// name of a resource identifies it within the pkg and is used to look up the
+// existing resource, the optional display name renames it in the platform.
+func platformName(name, displayName string) string {
+	if displayName != "" {
+		return displayName
+	}
+	return name
+}
+
+// displayNameDiff is the name of the resource in the platform when it differs
+// from its name in the pkg. A rename shows as a change of the display name.
+func displayNameDiff(name, platformName string) string {
+	if name == platformName {
+		return ""
+	}
+	return platformName
+}
+
+const (
+	fieldBucketRetentionRules = "retentionRules"
+)
+
+type bucket struct {
+	id             influxdb.ID
+	OrgID          influxdb.ID
+	Description    string
+	name           string
+	displayName    string
+	RetentionRules retentionRules
+	labels         sortedLabels
+
+	// existing provides context for a resource that already
+	// exists in the platform. If a resource already exists
+	// then it will be referenced here.
+	existing *influxdb.Bucket
+}
+
+func (b *bucket) ID() influxdb.ID {
+	if b.existing != nil {
+		return b.existing.ID
+	}
+	return b.id
+}
+
+func (b *bucket) Labels() []*label {
+	return b.labels
 }
 
 func (b *bucket) Name() string {
 	return b.name
 }
 
+func (b *bucket) platformName() string {
+	return platformName(b.name, b.displayName)
+}
+
 func (b *bucket) ResourceType() influxdb.ResourceType {
 	return KindBucket.ResourceType()
 }
@@ -632,7 +1520,7 @@ func (b *bucket) summarize() SummaryBucket {
 	return SummaryBucket{
 		ID:                SafeID(b.ID()),
 		OrgID:             SafeID(b.OrgID),
-		Name:              b.Name(),
+		Name:              b.platformName(),
 		Description:       b.Description,
 		RetentionPeriod:   b.RetentionRules.RP(),
 		LabelAssociations: toSummaryLabels(b.labels...),
@@ -646,7 +1534,7 @@ func (b *bucket) valid() []validationErr {
 func (b *bucket) shouldApply() bool {
 	return b.existing == nil ||
 		b.Description != b.existing.Description ||
-		b.Name() != b.existing.Name ||
+		b.platformName() != b.existing.Name ||
 		b.RetentionRules.RP() != b.existing.RetentionPeriod
 }
 
@@ -667,6 +1555,10 @@ const (
 type retentionRule struct {
 	Type    string `json:"type" yaml:"type"`
 	Seconds int    `json:"everySeconds" yaml:"everySeconds"`
+
+	// secondsUnset is set for a rule parsed without its seconds, it is
+	// not taken as the 0 seconds that retain the data forever.
+	secondsUnset bool
 }
 
 func newRetentionRule(d time.Duration) retentionRule {
@@ -677,12 +1569,31 @@ func newRetentionRule(d time.Duration) retentionRule {
 }
 
 func (r retentionRule) valid() []validationErr {
-	const hour = 3600
+	const (
+		hour       = 3600
+		maxSeconds = int(math.MaxInt64 / int64(time.Second))
+	)
 	var ff []validationErr
-	if r.Seconds < hour {
+	switch {
+	case r.secondsUnset:
+		ff = append(ff, validationErr{
+			Field: fieldRetentionRulesEverySeconds,
+			Msg:   "seconds must be provided, 0 retains the data forever",
+		})
+	case r.Seconds < 0:
+		ff = append(ff, validationErr{
+			Field: fieldRetentionRulesEverySeconds,
+			Msg:   "seconds must not be negative, 0 retains the data forever",
+		})
+	case r.Seconds > maxSeconds:
 		ff = append(ff, validationErr{
 			Field: fieldRetentionRulesEverySeconds,
-			Msg:   "seconds must be a minimum of " + strconv.Itoa(hour),
+			Msg:   "seconds must be a maximum of " + strconv.Itoa(maxSeconds),
+		})
+	case r.Seconds > 0 && r.Seconds < hour:
+		ff = append(ff, validationErr{
+			Field: fieldRetentionRulesEverySeconds,
+			Msg:   "seconds must be a minimum of " + strconv.Itoa(hour) + ", or 0 to retain the data forever",
 		})
 	}
 	if r.Type != retentionRuleTypeExpire {
@@ -784,6 +1695,7 @@ type label struct {
 	id          influxdb.ID
 	OrgID       influxdb.ID
 	name        string
+	displayName string
 	Color       string
 	Description string
 	associationMapping
@@ -798,6 +1710,10 @@ func (l *label) Name() string {
 	return l.name
 }
 
+func (l *label) platformName() string {
+	return platformName(l.name, l.displayName)
+}
+
 func (l *label) ID() influxdb.ID {
 	if l.existing != nil {
 		return l.existing.ID
@@ -806,17 +1722,14 @@ func (l *label) ID() influxdb.ID {
 }
 
 func (l *label) shouldApply() bool {
-	return l.existing == nil ||
-		l.Description != l.existing.Properties["description"] ||
-		l.Name() != l.existing.Name ||
-		l.Color != l.existing.Properties["color"]
+	return l.existing == nil || newDiffLabelProperties(l, *l.existing).Changed()
 }
 
 func (l *label) summarize() SummaryLabel {
 	return SummaryLabel{
 		ID:    SafeID(l.ID()),
 		OrgID: SafeID(l.OrgID),
-		Name:  l.Name(),
+		Name:  l.platformName(),
 		Properties: struct {
 			Color       string `json:"color"`
 			Description string `json:"description"`
@@ -856,246 +1769,1261 @@ func (l *label) toInfluxLabel() influxdb.Label {
 	return influxdb.Label{
 		ID:         l.ID(),
 		OrgID:      l.OrgID,
-		Name:       l.Name(),
+		Name:       l.platformName(),
 		Properties: l.properties(),
 	}
 }
 
-func toSummaryLabels(labels ...*label) []SummaryLabel {
-	var iLabels []SummaryLabel
-	for _, l := range labels {
-		iLabels = append(iLabels, l.summarize())
-	}
-	return iLabels
+func toSummaryLabels(labels ...*label) []SummaryLabel {
+	var iLabels []SummaryLabel
+	for _, l := range labels {
+		iLabels = append(iLabels, l.summarize())
+	}
+	return iLabels
+}
+
+type sortedLabels []*label
+
+func (s sortedLabels) Len() int {
+	return len(s)
+}
+
+func (s sortedLabels) Less(i, j int) bool {
+	return s[i].name < s[j].name
+}
+
+func (s sortedLabels) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+type checkKind int
+
+const (
+	checkKindDeadman checkKind = iota + 1
+	checkKindThreshold
+)
+
+const (
+	fieldCheckAllValues             = "allValues"
+	fieldCheckBucket                = "bucket"
+	fieldCheckEvery                 = "every"
+	fieldCheckField                 = "field"
+	fieldCheckLevel                 = "level"
+	fieldCheckMax                   = "max"
+	fieldCheckMin                   = "min"
+	fieldCheckOffset                = "offset"
+	fieldCheckReportZero            = "reportZero"
+	fieldCheckStaleTime             = "staleTime"
+	fieldCheckStatusMessageTemplate = "statusMessageTemplate"
+	fieldCheckTags                  = "tags"
+	fieldCheckThresholds            = "thresholds"
+	fieldCheckTimeSince             = "timeSince"
+	fieldCheckWithin                = "within"
+)
+
+type check struct {
+	kind          checkKind
+	id            influxdb.ID
+	OrgID         influxdb.ID
+	name          string
+	displayName   string
+	description   string
+	bucket        string
+	every         string
+	field         string
+	level         string
+	offset        string
+	query         string
+	reportZero    bool
+	staleTime     string
+	status        string
+	statusMessage string
+	tags          []influxdb.Tag
+	timeSince     string
+	thresholds    []threshold
+
+	labels sortedLabels
+
+	existing influxdb.Check
+	// existingStatus is the status of the existing check when it is updated,
+	// restored by a rollback.
+	existingStatus influxdb.Status
+}
+
+func (c *check) Exists() bool {
+	return c.existing != nil
+}
+
+func (c *check) ID() influxdb.ID {
+	if c.existing != nil {
+		return c.existing.GetID()
+	}
+	return c.id
+}
+
+func (c *check) Labels() []*label {
+	return c.labels
+}
+
+func (c *check) Name() string {
+	return c.name
+}
+
+func (c *check) platformName() string {
+	return platformName(c.name, c.displayName)
+}
+
+func (c *check) ResourceType() influxdb.ResourceType {
+	return KindCheck.ResourceType()
+}
+
+func (c *check) Status() influxdb.Status {
+	if c.status == "" {
+		return influxdb.Active
+	}
+	return influxdb.Status(c.status)
+}
+
+// shouldApply returns true when the check does not exist or differs from the
+// existing one, either in its definition or in the status of its task.
+func (c *check) shouldApply() bool {
+	if c.existing == nil {
+		return true
+	}
+	if c.existingStatus != c.Status() {
+		return true
+	}
+	return !sameCheck(c.summarize().Check, c.existing)
+}
+
+// sameCheck reports whether the check of a pkg matches an existing check. The
+// ids, owner and timestamps are set by the platform, they are left out of the
+// comparison.
+func sameCheck(pkgCheck, existing influxdb.Check) bool {
+	pkgCheck.SetID(existing.GetID())
+	pkgCheck.SetOrgID(existing.GetOrgID())
+	pkgCheck.SetOwnerID(existing.GetOwnerID())
+	pkgCheck.SetTaskID(existing.GetTaskID())
+	crudLog := existing.GetCRUDLog()
+	pkgCheck.SetCreatedAt(crudLog.CreatedAt)
+	pkgCheck.SetUpdatedAt(crudLog.UpdatedAt)
+
+	want, err := json.Marshal(pkgCheck)
+	if err != nil {
+		return false
+	}
+	got, err := json.Marshal(existing)
+	if err != nil {
+		return false
+	}
+	return string(want) == string(got)
+}
+
+func (c *check) summarize() SummaryCheck {
+	base := icheck.Base{
+		ID:          c.ID(),
+		Name:        c.platformName(),
+		Description: c.description,
+		OrgID:       c.OrgID,
+		Every:       toNotificationDuration(c.every),
+		Offset:      toNotificationDuration(c.offset),
+		Query: influxdb.DashboardQuery{
+			Text:     c.query,
+			EditMode: "advanced",
+			BuilderConfig: influxdb.BuilderConfig{
+				Buckets: []string{c.bucket},
+			},
+		},
+		StatusMessageTemplate: c.statusMessage,
+		Tags:                  c.tags,
+	}
+	if c.field != "" {
+		// a threshold check compares the values of the field selected
+		// by the builder config
+		base.Query.BuilderConfig.Tags = append(base.Query.BuilderConfig.Tags, influxdb.NewBuilderTag("_field", c.field))
+	}
+
+	sum := SummaryCheck{
+		Status:            c.Status(),
+		LabelAssociations: toSummaryLabels(c.labels...),
+	}
+	switch c.kind {
+	case checkKindDeadman:
+		sum.Check = &icheck.Deadman{
+			Base:       base,
+			Level:      notification.ParseCheckLevel(c.level),
+			ReportZero: c.reportZero,
+			StaleTime:  toNotificationDuration(c.staleTime),
+			TimeSince:  toNotificationDuration(c.timeSince),
+		}
+	case checkKindThreshold:
+		sum.Check = &icheck.Threshold{
+			Base:       base,
+			Thresholds: toInfluxThresholds(c.thresholds...),
+		}
+	}
+	return sum
+}
+
+func (c *check) valid() []validationErr {
+	var failures []validationErr
+	if c.bucket == "" {
+		failures = append(failures, validationErr{
+			Field: fieldCheckBucket,
+			Msg:   "must provide the name of the bucket the check queries",
+		})
+	}
+	if c.query == "" {
+		failures = append(failures, validationErr{
+			Field: fieldQuery,
+			Msg:   "must provide a non empty query",
+		})
+	}
+
+	type duration struct {
+		field    string
+		value    string
+		required bool
+	}
+	durations := []duration{
+		{field: fieldCheckEvery, value: c.every, required: true},
+		{field: fieldCheckOffset, value: c.offset},
+	}
+	if c.kind == checkKindDeadman {
+		durations = append(durations,
+			duration{field: fieldCheckStaleTime, value: c.staleTime, required: true},
+			duration{field: fieldCheckTimeSince, value: c.timeSince, required: true},
+		)
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			if d.required {
+				failures = append(failures, validationErr{
+					Field: d.field,
+					Msg:   "must provide a duration",
+				})
+			}
+			continue
+		}
+		if toNotificationDuration(d.value) == nil {
+			failures = append(failures, validationErr{
+				Field: d.field,
+				Msg:   fmt.Sprintf("invalid duration provided %q", d.value),
+			})
+		}
+	}
+	every, offset := toNotificationDuration(c.every), toNotificationDuration(c.offset)
+	if every != nil && offset != nil && offset.TimeDuration() >= every.TimeDuration() {
+		failures = append(failures, validationErr{
+			Field: fieldCheckOffset,
+			Msg:   "offset must be less than the every duration",
+		})
+	}
+
+	if c.status != "" && influxdb.TaskStatusInactive != c.status && influxdb.TaskStatusActive != c.status {
+		failures = append(failures, validationErr{
+			Field: fieldStatus,
+			Msg:   "not a valid status; valid statues are one of [active, inactive]",
+		})
+	}
+
+	for i, tag := range c.tags {
+		if err := tag.Valid(); err != nil {
+			failures = append(failures, validationErr{
+				Field: fieldCheckTags,
+				Index: intPtr(i),
+				Msg:   "must provide a non empty key and value",
+			})
+		}
+	}
+
+	switch c.kind {
+	case checkKindDeadman:
+		if !validCheckLevels[c.level] {
+			failures = append(failures, validationErr{
+				Field: fieldCheckLevel,
+				Msg:   fmt.Sprintf("invalid level provided %q; valid level is 1 in [%s]", c.level, strings.Join(sortedKeys(validCheckLevels), ", ")),
+			})
+		}
+	case checkKindThreshold:
+		if c.field == "" {
+			failures = append(failures, validationErr{
+				Field: fieldCheckField,
+				Msg:   "must provide the field the thresholds are compared against",
+			})
+		}
+		if len(c.thresholds) == 0 {
+			failures = append(failures, validationErr{
+				Field: fieldCheckThresholds,
+				Msg:   "must provide at least 1 threshold",
+			})
+		}
+		for i, th := range c.thresholds {
+			if ff := th.valid(); len(ff) > 0 {
+				failures = append(failures, validationErr{
+					Field:  fieldCheckThresholds,
+					Index:  intPtr(i),
+					Nested: ff,
+				})
+			}
+		}
+	}
+
+	return failures
+}
+
+var validCheckLevels = map[string]bool{
+	"CRIT": true,
+	"INFO": true,
+	"OK":   true,
+	"WARN": true,
+}
+
+const (
+	thresholdTypeGreater = "greater"
+	thresholdTypeLesser  = "lesser"
+	thresholdTypeRange   = "range"
+)
+
+type threshold struct {
+	threshType string
+	allVals    bool
+	level      string
+	val        float64
+	min, max   float64
+	within     bool
+}
+
+func (t threshold) valid() []validationErr {
+	var ff []validationErr
+	if !validCheckLevels[t.level] {
+		ff = append(ff, validationErr{
+			Field: fieldCheckLevel,
+			Msg:   fmt.Sprintf("invalid level provided %q; valid level is 1 in [%s]", t.level, strings.Join(sortedKeys(validCheckLevels), ", ")),
+		})
+	}
+
+	switch t.threshType {
+	case thresholdTypeGreater, thresholdTypeLesser:
+	case thresholdTypeRange:
+		if t.min > t.max {
+			ff = append(ff, validationErr{
+				Field: fieldCheckMin,
+				Msg:   "min must be less than or equal to max",
+			})
+		}
+	default:
+		ff = append(ff, validationErr{
+			Field: fieldType,
+			Msg: fmt.Sprintf(
+				"invalid type provided %q; valid type is 1 in [%s, %s, %s]",
+				t.threshType,
+				thresholdTypeGreater,
+				thresholdTypeLesser,
+				thresholdTypeRange,
+			),
+		})
+	}
+	return ff
+}
+
+func toInfluxThresholds(thresholds ...threshold) []icheck.ThresholdConfig {
+	var iThresh []icheck.ThresholdConfig
+	for _, th := range thresholds {
+		base := icheck.ThresholdConfigBase{
+			AllValues: th.allVals,
+			Level:     notification.ParseCheckLevel(th.level),
+		}
+		switch th.threshType {
+		case thresholdTypeGreater:
+			iThresh = append(iThresh, &icheck.Greater{ThresholdConfigBase: base, Value: th.val})
+		case thresholdTypeLesser:
+			iThresh = append(iThresh, &icheck.Lesser{ThresholdConfigBase: base, Value: th.val})
+		case thresholdTypeRange:
+			iThresh = append(iThresh, &icheck.Range{ThresholdConfigBase: base, Min: th.min, Max: th.max, Within: th.within})
+		}
+	}
+	return iThresh
+}
+
+// toNotificationDuration parses the flux duration literal of a check, i.e.
+// 1m or 1h30m. It returns nil for an empty or invalid duration.
+func toNotificationDuration(s string) *notification.Duration {
+	if s == "" {
+		return nil
+	}
+	dur, err := parser.ParseDuration(s)
+	if err != nil {
+		return nil
+	}
+	return (*notification.Duration)(dur)
+}
+
+type mapperChecks []*check
+
+func (c mapperChecks) Association(i int) labelAssociater {
+	return c[i]
+}
+
+func (c mapperChecks) Len() int {
+	return len(c)
+}
+
+type notificationKind int
+
+const (
+	notificationKindHTTP notificationKind = iota + 1
+	notificationKindPagerDuty
+	notificationKindSlack
+)
+
+const (
+	notificationHTTPAuthTypeBasic  = "basic"
+	notificationHTTPAuthTypeBearer = "bearer"
+	notificationHTTPAuthTypeNone   = "none"
+)
+
+const (
+	fieldNotificationEndpointHTTPMethod = "method"
+	fieldNotificationEndpointPassword   = "password"
+	fieldNotificationEndpointRoutingKey = "routingKey"
+	fieldNotificationEndpointToken      = "token"
+	fieldNotificationEndpointURL        = "url"
+	fieldNotificationEndpointUsername   = "username"
+)
+
+type notificationEndpoint struct {
+	kind        notificationKind
+	id          influxdb.ID
+	OrgID       influxdb.ID
+	name        string
+	displayName string
+	description string
+	method      string
+	password    references
+	routingKey  references
+	status      string
+	token       references
+	httpType    string
+	url         string
+	username    references
+
+	labels sortedLabels
+
+	existing influxdb.NotificationEndpoint
+}
+
+func (n *notificationEndpoint) Exists() bool {
+	return n.existing != nil
+}
+
+func (n *notificationEndpoint) ID() influxdb.ID {
+	if n.existing != nil {
+		return n.existing.GetID()
+	}
+	return n.id
+}
+
+func (n *notificationEndpoint) Labels() []*label {
+	return n.labels
+}
+
+func (n *notificationEndpoint) Name() string {
+	return n.name
+}
+
+func (n *notificationEndpoint) platformName() string {
+	return platformName(n.name, n.displayName)
+}
+
+func (n *notificationEndpoint) ResourceType() influxdb.ResourceType {
+	return KindNotificationEndpointSlack.ResourceType()
+}
+
+// endpointType is the type of the platform endpoint of the endpoint kind.
+func (n *notificationEndpoint) endpointType() string {
+	switch n.kind {
+	case notificationKindHTTP:
+		return endpoint.HTTPType
+	case notificationKindPagerDuty:
+		return endpoint.PagerDutyType
+	case notificationKindSlack:
+		return endpoint.SlackType
+	default:
+		return ""
+	}
+}
+
+func (n *notificationEndpoint) base() endpoint.Base {
+	e := endpoint.Base{
+		Name:        n.platformName(),
+		Description: n.description,
+		Status:      influxdb.TaskStatusActive,
+	}
+	if id := n.ID(); id > 0 {
+		e.ID = &id
+	}
+	if orgID := n.OrgID; orgID > 0 {
+		e.OrgID = &orgID
+	}
+	return e
+}
+
+func (n *notificationEndpoint) summarize() SummaryNotificationEndpoint {
+	base := n.base()
+	if n.status != "" {
+		base.Status = influxdb.Status(n.status)
+	}
+	sum := SummaryNotificationEndpoint{
+		LabelAssociations: toSummaryLabels(n.labels...),
+	}
+
+	switch n.kind {
+	case notificationKindHTTP:
+		e := &endpoint.HTTP{
+			Base:   base,
+			URL:    n.url,
+			Method: n.method,
+		}
+		switch n.httpType {
+		case notificationHTTPAuthTypeBasic:
+			e.AuthMethod = notificationHTTPAuthTypeBasic
+			e.Password = n.password.SecretField()
+			e.Username = n.username.SecretField()
+		case notificationHTTPAuthTypeBearer:
+			e.AuthMethod = notificationHTTPAuthTypeBearer
+			e.Token = n.token.SecretField()
+		case notificationHTTPAuthTypeNone:
+			e.AuthMethod = notificationHTTPAuthTypeNone
+		}
+		sum.NotificationEndpoint = e
+	case notificationKindPagerDuty:
+		sum.NotificationEndpoint = &endpoint.PagerDuty{
+			Base:       base,
+			ClientURL:  n.url,
+			RoutingKey: n.routingKey.SecretField(),
+		}
+	case notificationKindSlack:
+		sum.NotificationEndpoint = &endpoint.Slack{
+			Base:  base,
+			URL:   n.url,
+			Token: n.token.SecretField(),
+		}
+	}
+	return sum
+}
+
+var validEndpointHTTPMethods = map[string]bool{
+	"DELETE":  true,
+	"GET":     true,
+	"HEAD":    true,
+	"OPTIONS": true,
+	"PATCH":   true,
+	"POST":    true,
+	"PUT":     true,
+}
+
+func (n *notificationEndpoint) valid() []validationErr {
+	var failures []validationErr
+	if _, err := url.Parse(n.url); err != nil || n.url == "" {
+		failures = append(failures, validationErr{
+			Field: fieldNotificationEndpointURL,
+			Msg:   "must be valid url",
+		})
+	}
+
+	if n.status != "" && influxdb.TaskStatusInactive != n.status && influxdb.TaskStatusActive != n.status {
+		failures = append(failures, validationErr{
+			Field: fieldStatus,
+			Msg:   "not a valid status; valid statues are one of [active, inactive]",
+		})
+	}
+
+	if n.kind == notificationKindHTTP {
+		if !validEndpointHTTPMethods[n.method] {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointHTTPMethod,
+				Msg:   "http method must be a valid HTTP verb",
+			})
+		}
+
+		switch n.httpType {
+		case notificationHTTPAuthTypeBasic, notificationHTTPAuthTypeBearer, notificationHTTPAuthTypeNone:
+		default:
+			failures = append(failures, validationErr{
+				Field: fieldType,
+				Msg: fmt.Sprintf(
+					"invalid type provided %q; valid type is 1 in [%s, %s, %s]",
+					n.httpType,
+					notificationHTTPAuthTypeBasic,
+					notificationHTTPAuthTypeBearer,
+					notificationHTTPAuthTypeNone,
+				),
+			})
+		}
+	}
+
+	return append(failures, n.validSecrets()...)
+}
+
+// validSecrets verifies the secret fields required by the endpoint's type are
+// provided. Catching these here keeps an incomplete endpoint from failing
+// part way through an apply, which would otherwise trigger a rollback.
+func (n *notificationEndpoint) validSecrets() []validationErr {
+	type requiredSecret struct {
+		field string
+		ref   references
+	}
+
+	var (
+		endpointType string
+		required     []requiredSecret
+	)
+	switch n.kind {
+	case notificationKindPagerDuty:
+		endpointType = endpoint.PagerDutyType
+		required = []requiredSecret{
+			{field: fieldNotificationEndpointRoutingKey, ref: n.routingKey},
+		}
+	case notificationKindHTTP:
+		endpointType = fmt.Sprintf("%s %s auth", endpoint.HTTPType, n.httpType)
+		switch n.httpType {
+		case notificationHTTPAuthTypeBasic:
+			required = []requiredSecret{
+				{field: fieldNotificationEndpointPassword, ref: n.password},
+				{field: fieldNotificationEndpointUsername, ref: n.username},
+			}
+		case notificationHTTPAuthTypeBearer:
+			required = []requiredSecret{
+				{field: fieldNotificationEndpointToken, ref: n.token},
+			}
+		}
+	case notificationKindSlack:
+		// the slack token is optional, a webhook url alone is enough
+	}
+
+	var failures []validationErr
+	for _, r := range required {
+		if r.ref.hasValue() {
+			continue
+		}
+		failures = append(failures, validationErr{
+			Field: r.field,
+			Msg: fmt.Sprintf(
+				"%s endpoint %q must provide a non empty %s value or secretRef",
+				endpointType, n.Name(), r.field,
+			),
+		})
+	}
+	return failures
+}
+
+type mapperNotificationEndpoints []*notificationEndpoint
+
+func (n mapperNotificationEndpoints) Association(i int) labelAssociater {
+	return n[i]
+}
+
+func (n mapperNotificationEndpoints) Len() int {
+	return len(n)
+}
+
+const (
+	fieldNotificationRuleChannel         = "channel"
+	fieldNotificationRuleCurrentLevel    = "currentLevel"
+	fieldNotificationRuleEndpointName    = "endpointName"
+	fieldNotificationRuleEvery           = "every"
+	fieldNotificationRuleMessageTemplate = "messageTemplate"
+	fieldNotificationRuleOffset          = "offset"
+	fieldNotificationRuleOperator        = "operator"
+	fieldNotificationRulePreviousLevel   = "previousLevel"
+	fieldNotificationRuleStatusRules     = "statusRules"
+	fieldNotificationRuleTagRules        = "tagRules"
+)
+
+type notificationRule struct {
+	id          influxdb.ID
+	OrgID       influxdb.ID
+	name        string
+	displayName string
+	description string
+
+	channel     string
+	every       string
+	msgTemplate string
+	offset      string
+	status      string
+	statusRules []ruleStatusRule
+	tagRules    []ruleTagRule
+
+	// endpointName is the name of the endpoint the rule notifies, an
+	// endpoint of the pkg or an existing endpoint of the org. Its id and
+	// type are resolved by the dry run and the apply.
+	endpointName string
+	endpointID   influxdb.ID
+	endpointType string
+
+	labels sortedLabels
+
+	existing influxdb.NotificationRule
+	// existingStatus is the status of the existing rule when it is updated,
+	// restored by a rollback.
+	existingStatus influxdb.Status
+}
+
+type ruleStatusRule struct {
+	curLvl  string
+	prevLvl string
+}
+
+type ruleTagRule struct {
+	key   string
+	value string
+	op    string
+}
+
+func (r *notificationRule) Exists() bool {
+	return r.existing != nil
+}
+
+func (r *notificationRule) ID() influxdb.ID {
+	if r.existing != nil {
+		return r.existing.GetID()
+	}
+	return r.id
+}
+
+func (r *notificationRule) Labels() []*label {
+	return r.labels
+}
+
+func (r *notificationRule) Name() string {
+	return r.name
+}
+
+func (r *notificationRule) platformName() string {
+	return platformName(r.name, r.displayName)
+}
+
+func (r *notificationRule) ResourceType() influxdb.ResourceType {
+	return KindNotificationRule.ResourceType()
+}
+
+func (r *notificationRule) Status() influxdb.Status {
+	if r.status == "" {
+		return influxdb.Active
+	}
+	return influxdb.Status(r.status)
+}
+
+func (r *notificationRule) summarize() SummaryNotificationRule {
+	return SummaryNotificationRule{
+		ID:                SafeID(r.ID()),
+		OrgID:             SafeID(r.OrgID),
+		Name:              r.platformName(),
+		Description:       r.description,
+		EndpointID:        SafeID(r.endpointID),
+		EndpointName:      r.endpointName,
+		EndpointType:      r.endpointType,
+		Channel:           r.channel,
+		Every:             r.every,
+		Offset:            r.offset,
+		MessageTemplate:   r.msgTemplate,
+		Status:            r.Status(),
+		StatusRules:       toSummaryStatusRules(r.statusRules),
+		TagRules:          toSummaryTagRules(r.tagRules),
+		LabelAssociations: toSummaryLabels(r.labels...),
+	}
+}
+
+// toInfluxRule converts the rule to the rule of the type of its endpoint. The
+// endpoint must have been resolved.
+func (r *notificationRule) toInfluxRule() influxdb.NotificationRule {
+	base := rule.Base{
+		ID:          r.ID(),
+		Name:        r.platformName(),
+		Description: r.description,
+		EndpointID:  r.endpointID,
+		OrgID:       r.OrgID,
+		Every:       toNotificationDuration(r.every),
+		Offset:      toNotificationDuration(r.offset),
+	}
+	for _, sr := range r.statusRules {
+		var prevLvl *notification.CheckLevel
+		if sr.prevLvl != "" {
+			lvl := notification.ParseCheckLevel(sr.prevLvl)
+			prevLvl = &lvl
+		}
+		base.StatusRules = append(base.StatusRules, notification.StatusRule{
+			CurrentLevel:  notification.ParseCheckLevel(sr.curLvl),
+			PreviousLevel: prevLvl,
+		})
+	}
+	for _, tr := range r.tagRules {
+		base.TagRules = append(base.TagRules, notification.TagRule{
+			Tag:      influxdb.Tag{Key: tr.key, Value: tr.value},
+			Operator: validTagRuleOperators[tr.op],
+		})
+	}
+
+	switch r.endpointType {
+	case endpoint.HTTPType:
+		return &rule.HTTP{Base: base}
+	case endpoint.PagerDutyType:
+		return &rule.PagerDuty{Base: base, MessageTemplate: r.msgTemplate}
+	case endpoint.SlackType:
+		return &rule.Slack{Base: base, Channel: r.channel, MessageTemplate: r.msgTemplate}
+	}
+	return nil
+}
+
+// toRuleCreate returns the rule to create or update the rule of the pkg with.
+func (r *notificationRule) toRuleCreate() (influxdb.NotificationRuleCreate, error) {
+	ruleCreate := influxdb.NotificationRuleCreate{
+		NotificationRule: r.toInfluxRule(),
+		Status:           r.Status(),
+	}
+	if ruleCreate.NotificationRule == nil {
+		return ruleCreate, fmt.Errorf("unsupported endpoint type %q of endpoint %q", r.endpointType, r.endpointName)
+	}
+	return ruleCreate, nil
+}
+
+var validTagRuleOperators = map[string]influxdb.Operator{
+	"equal":         influxdb.Equal,
+	"equalregex":    influxdb.RegexEqual,
+	"notequal":      influxdb.NotEqual,
+	"notequalregex": influxdb.NotRegexEqual,
+}
+
+func (r *notificationRule) valid() []validationErr {
+	var failures []validationErr
+	if r.endpointName == "" {
+		failures = append(failures, validationErr{
+			Field: fieldNotificationRuleEndpointName,
+			Msg:   "must provide the name of the endpoint the rule notifies",
+		})
+	}
+
+	if r.every == "" {
+		failures = append(failures, validationErr{
+			Field: fieldNotificationRuleEvery,
+			Msg:   "must provide a duration",
+		})
+	} else if toNotificationDuration(r.every) == nil {
+		failures = append(failures, validationErr{
+			Field: fieldNotificationRuleEvery,
+			Msg:   fmt.Sprintf("invalid duration provided %q", r.every),
+		})
+	}
+	if r.offset != "" && toNotificationDuration(r.offset) == nil {
+		failures = append(failures, validationErr{
+			Field: fieldNotificationRuleOffset,
+			Msg:   fmt.Sprintf("invalid duration provided %q", r.offset),
+		})
+	}
+	every, offset := toNotificationDuration(r.every), toNotificationDuration(r.offset)
+	if every != nil && offset != nil && offset.TimeDuration() >= every.TimeDuration() {
+		failures = append(failures, validationErr{
+			Field: fieldNotificationRuleOffset,
+			Msg:   "offset must be less than the every duration",
+		})
+	}
+
+	if r.status != "" && influxdb.TaskStatusInactive != r.status && influxdb.TaskStatusActive != r.status {
+		failures = append(failures, validationErr{
+			Field: fieldStatus,
+			Msg:   "not a valid status; valid statues are one of [active, inactive]",
+		})
+	}
+
+	// the message template is required by the rules of these endpoint
+	// types, the type is only known here for an endpoint of the pkg
+	switch r.endpointType {
+	case endpoint.PagerDutyType, endpoint.SlackType:
+		if r.msgTemplate == "" {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationRuleMessageTemplate,
+				Msg:   fmt.Sprintf("must provide a message template for a %s endpoint", r.endpointType),
+			})
+		}
+	}
+
+	if len(r.statusRules) == 0 {
+		failures = append(failures, validationErr{
+			Field: fieldNotificationRuleStatusRules,
+			Msg:   "must provide at least 1 status rule",
+		})
+	}
+	for i, sr := range r.statusRules {
+		var ff []validationErr
+		if !validCheckLevels[sr.curLvl] {
+			ff = append(ff, validationErr{
+				Field: fieldNotificationRuleCurrentLevel,
+				Msg:   fmt.Sprintf("invalid level provided %q; valid level is 1 in [%s]", sr.curLvl, strings.Join(sortedKeys(validCheckLevels), ", ")),
+			})
+		}
+		if sr.prevLvl != "" && !validCheckLevels[sr.prevLvl] {
+			ff = append(ff, validationErr{
+				Field: fieldNotificationRulePreviousLevel,
+				Msg:   fmt.Sprintf("invalid level provided %q; valid level is 1 in [%s]", sr.prevLvl, strings.Join(sortedKeys(validCheckLevels), ", ")),
+			})
+		}
+		if len(ff) > 0 {
+			failures = append(failures, validationErr{
+				Field:  fieldNotificationRuleStatusRules,
+				Index:  intPtr(i),
+				Nested: ff,
+			})
+		}
+	}
+
+	for i, tr := range r.tagRules {
+		var ff []validationErr
+		if tr.key == "" || tr.value == "" {
+			ff = append(ff, validationErr{
+				Field: fieldKey,
+				Msg:   "must provide a non empty key and value",
+			})
+		}
+		if _, ok := validTagRuleOperators[tr.op]; !ok {
+			ff = append(ff, validationErr{
+				Field: fieldNotificationRuleOperator,
+				Msg:   fmt.Sprintf("invalid operator provided %q; valid operator is 1 in [equal, equalregex, notequal, notequalregex]", tr.op),
+			})
+		}
+		if len(ff) > 0 {
+			failures = append(failures, validationErr{
+				Field:  fieldNotificationRuleTagRules,
+				Index:  intPtr(i),
+				Nested: ff,
+			})
+		}
+	}
+
+	return failures
+}
+
+func toSummaryStatusRules(statusRules []ruleStatusRule) []SummaryStatusRule {
+	var out []SummaryStatusRule
+	for _, sr := range statusRules {
+		out = append(out, SummaryStatusRule{
+			CurrentLevel:  sr.curLvl,
+			PreviousLevel: sr.prevLvl,
+		})
+	}
+	return out
+}
+
+func toSummaryTagRules(tagRules []ruleTagRule) []SummaryTagRule {
+	var out []SummaryTagRule
+	for _, tr := range tagRules {
+		out = append(out, SummaryTagRule{
+			Key:      tr.key,
+			Value:    tr.value,
+			Operator: tr.op,
+		})
+	}
+	return out
+}
+
+type mapperNotificationRules []*notificationRule
+
+func (r mapperNotificationRules) Association(i int) labelAssociater {
+	return r[i]
+}
+
+func (r mapperNotificationRules) Len() int {
+	return len(r)
+}
+
+const (
+	fieldScraperTargetBucket = "bucket"
+	fieldScraperTargetURL    = "url"
+)
+
+type scraperTarget struct {
+	id     influxdb.ID
+	OrgID  influxdb.ID
+	name   string
+	typ    influxdb.ScraperType
+	url    string
+	bucket string
+
+	// bucketID is the id of the bucket the target writes to, resolved from
+	// the buckets of the pkg or the org by its name.
+	bucketID influxdb.ID
+
+	labels sortedLabels
+
+	existing *influxdb.ScraperTarget
+}
+
+func (s *scraperTarget) ID() influxdb.ID {
+	if s.existing != nil {
+		return s.existing.ID
+	}
+	return s.id
+}
+
+func (s *scraperTarget) Labels() []*label {
+	return s.labels
+}
+
+func (s *scraperTarget) Name() string {
+	return s.name
+}
+
+func (s *scraperTarget) ResourceType() influxdb.ResourceType {
+	return KindScraperTarget.ResourceType()
+}
+
+func (s *scraperTarget) Exists() bool {
+	return s.existing != nil
 }
 
-type sortedLabels []*label
+// shouldApply returns true when the target does not exist or differs from the
+// existing one. The bucket of the pkg the target writes to must exist for the
+// target to be unchanged.
+func (s *scraperTarget) shouldApply(pkgBuckets map[string]*bucket) bool {
+	if s.existing == nil {
+		return true
+	}
+	bucketID := s.bucketID
+	if b, ok := pkgBuckets[s.bucket]; ok {
+		bucketID = b.ID()
+	}
+	return bucketID == 0 ||
+		s.existing.BucketID != bucketID ||
+		s.existing.Type != s.typ ||
+		s.existing.URL != s.url
+}
 
-func (s sortedLabels) Len() int {
-	return len(s)
+func (s *scraperTarget) toInfluxTarget() influxdb.ScraperTarget {
+	return influxdb.ScraperTarget{
+		ID:       s.ID(),
+		Name:     s.Name(),
+		Type:     s.typ,
+		URL:      s.url,
+		OrgID:    s.OrgID,
+		BucketID: s.bucketID,
+	}
 }
 
-func (s sortedLabels) Less(i, j int) bool {
-	return s[i].name < s[j].name
+func (s *scraperTarget) summarize() SummaryScraperTarget {
+	return SummaryScraperTarget{
+		ID:                SafeID(s.ID()),
+		OrgID:             SafeID(s.OrgID),
+		Name:              s.Name(),
+		Type:              s.typ,
+		URL:               s.url,
+		BucketID:          SafeID(s.bucketID),
+		Bucket:            s.bucket,
+		LabelAssociations: toSummaryLabels(s.labels...),
+	}
 }
 
-func (s sortedLabels) Swap(i, j int) {
-	s[i], s[j] = s[j], s[i]
+func (s *scraperTarget) valid() []validationErr {
+	var failures []validationErr
+	if !influxdb.ValidScraperType(string(s.typ)) {
+		failures = append(failures, validationErr{
+			Field: fieldType,
+			Msg:   fmt.Sprintf("invalid type provided %q; valid type is 1 in [%s]", s.typ, influxdb.PrometheusScraperType),
+		})
+	}
+	if u, err := url.Parse(s.url); err != nil || s.url == "" || u.Host == "" {
+		failures = append(failures, validationErr{
+			Field: fieldScraperTargetURL,
+			Msg:   "must be valid url",
+		})
+	}
+	if s.bucket == "" {
+		failures = append(failures, validationErr{
+			Field: fieldScraperTargetBucket,
+			Msg:   "must provide the name of the bucket the target writes to",
+		})
+	}
+	return failures
 }
 
-type notificationKind int
+type mapperScraperTargets []*scraperTarget
 
-const (
-	notificationKindHTTP notificationKind = iota + 1
-	notificationKindPagerDuty
-	notificationKindSlack
-)
+func (m mapperScraperTargets) Association(i int) labelAssociater {
+	return m[i]
+}
 
-const (
-	notificationHTTPAuthTypeBasic  = "basic"
-	notificationHTTPAuthTypeBearer = "bearer"
-	notificationHTTPAuthTypeNone   = "none"
-)
+func (m mapperScraperTargets) Len() int {
+	return len(m)
+}
 
 const (
-	fieldNotificationEndpointHTTPMethod = "method"
-	fieldNotificationEndpointPassword   = "password"
-	fieldNotificationEndpointRoutingKey = "routingKey"
-	fieldNotificationEndpointToken      = "token"
-	fieldNotificationEndpointURL        = "url"
-	fieldNotificationEndpointUsername   = "username"
+	fieldTaskCron   = "cron"
+	fieldTaskEvery  = "every"
+	fieldTaskOffset = "offset"
 )
 
-type notificationEndpoint struct {
-	kind        notificationKind
+type task struct {
 	id          influxdb.ID
-	OrgID       influxdb.ID
 	name        string
+	cron        string
 	description string
-	method      string
-	password    references
-	routingKey  references
+	every       string
+	offset      string
+	query       string
 	status      string
-	token       references
-	httpType    string
-	url         string
-	username    references
 
 	labels sortedLabels
-
-	existing influxdb.NotificationEndpoint
 }
 
-func (n *notificationEndpoint) Exists() bool {
-	return n.existing != nil
+func (t *task) ID() influxdb.ID {
+	return t.id
 }
 
-func (n *notificationEndpoint) ID() influxdb.ID {
-	if n.existing != nil {
-		return n.existing.GetID()
-	}
-	return n.id
+// Exists is always false, the existing tasks are never looked up. A task is
+// created each time its pkg is applied.
+func (t *task) Exists() bool {
+	return false
 }
 
-func (n *notificationEndpoint) Labels() []*label {
-	return n.labels
+func (t *task) Labels() []*label {
+	return t.labels
 }
 
-func (n *notificationEndpoint) Name() string {
-	return n.name
+func (t *task) Name() string {
+	return t.name
 }
 
-func (n *notificationEndpoint) ResourceType() influxdb.ResourceType {
-	return KindNotificationEndpointSlack.ResourceType()
+func (t *task) ResourceType() influxdb.ResourceType {
+	return KindTask.ResourceType()
 }
 
-func (n *notificationEndpoint) base() endpoint.Base {
-	e := endpoint.Base{
-		Name:        n.Name(),
-		Description: n.description,
-		Status:      influxdb.TaskStatusActive,
-	}
-	if id := n.ID(); id > 0 {
-		e.ID = &id
-	}
-	if orgID := n.OrgID; orgID > 0 {
-		e.OrgID = &orgID
+func (t *task) Status() influxdb.Status {
+	if t.status == "" {
+		return influxdb.Active
 	}
-	return e
+	return influxdb.Status(t.status)
 }
 
-func (n *notificationEndpoint) summarize() SummaryNotificationEndpoint {
-	base := n.base()
-	if n.status != "" {
-		base.Status = influxdb.Status(n.status)
+// flux returns the script of the task, the query of the task preceded by the
+// task option declaring its name and schedule.
+func (t *task) flux() string {
+	opts := []string{fmt.Sprintf("name: %q", t.name)}
+	if t.cron != "" {
+		opts = append(opts, fmt.Sprintf("cron: %q", t.cron))
+	} else {
+		opts = append(opts, "every: "+t.every)
 	}
-	sum := SummaryNotificationEndpoint{
-		LabelAssociations: toSummaryLabels(n.labels...),
+	if t.offset != "" {
+		opts = append(opts, "offset: "+t.offset)
 	}
+	return fmt.Sprintf("option task = {%s}\n\n%s", strings.Join(opts, ", "), t.query)
+}
 
-	switch n.kind {
-	case notificationKindHTTP:
-		e := &endpoint.HTTP{
-			Base:   base,
-			URL:    n.url,
-			Method: n.method,
-		}
-		switch n.httpType {
-		case notificationHTTPAuthTypeBasic:
-			e.AuthMethod = notificationHTTPAuthTypeBasic
-			e.Password = n.password.SecretField()
-			e.Username = n.username.SecretField()
-		case notificationHTTPAuthTypeBearer:
-			e.AuthMethod = notificationHTTPAuthTypeBearer
-			e.Token = n.token.SecretField()
-		case notificationHTTPAuthTypeNone:
-			e.AuthMethod = notificationHTTPAuthTypeNone
-		}
-		sum.NotificationEndpoint = e
-	case notificationKindPagerDuty:
-		sum.NotificationEndpoint = &endpoint.PagerDuty{
-			Base:       base,
-			ClientURL:  n.url,
-			RoutingKey: n.routingKey.SecretField(),
-		}
-	case notificationKindSlack:
-		sum.NotificationEndpoint = &endpoint.Slack{
-			Base:  base,
-			URL:   n.url,
-			Token: n.token.SecretField(),
-		}
+func (t *task) diffValues() DiffTaskValues {
+	return DiffTaskValues{
+		Cron:        t.cron,
+		Description: t.description,
+		Every:       t.every,
+		Offset:      t.offset,
+		Query:       t.query,
+		Status:      t.Status(),
 	}
-	return sum
 }
 
-var validEndpointHTTPMethods = map[string]bool{
-	"DELETE":  true,
-	"GET":     true,
-	"HEAD":    true,
-	"OPTIONS": true,
-	"PATCH":   true,
-	"POST":    true,
-	"PUT":     true,
+func (t *task) summarize() SummaryTask {
+	return SummaryTask{
+		ID:                SafeID(t.ID()),
+		Name:              t.Name(),
+		Cron:              t.cron,
+		Description:       t.description,
+		Every:             t.every,
+		Offset:            t.offset,
+		Query:             t.query,
+		Status:            t.Status(),
+		LabelAssociations: toSummaryLabels(t.labels...),
+	}
 }
 
-func (n *notificationEndpoint) valid() []validationErr {
+func (t *task) valid() []validationErr {
 	var failures []validationErr
-	if _, err := url.Parse(n.url); err != nil || n.url == "" {
+	switch {
+	case t.cron == "" && t.every == "":
 		failures = append(failures, validationErr{
-			Field: fieldNotificationEndpointURL,
-			Msg:   "must be valid url",
+			Field: fieldTaskEvery,
+			Msg:   "must provide one of the every or cron fields",
 		})
-	}
-
-	if n.status != "" && influxdb.TaskStatusInactive != n.status && influxdb.TaskStatusActive != n.status {
+	case t.cron != "" && t.every != "":
 		failures = append(failures, validationErr{
-			Field: fieldStatus,
-			Msg:   "not a valid status; valid statues are one of [active, inactive]",
+			Field: fieldTaskCron,
+			Msg:   "must provide only one of the every or cron fields",
 		})
 	}
 
-	switch n.kind {
-	case notificationKindPagerDuty:
-		if !n.routingKey.hasValue() {
-			failures = append(failures, validationErr{
-				Field: fieldNotificationEndpointRoutingKey,
-				Msg:   "must be provide",
-			})
-		}
-	case notificationKindHTTP:
-		if !validEndpointHTTPMethods[n.method] {
+	durations := []struct {
+		field string
+		value string
+	}{
+		{field: fieldTaskEvery, value: t.every},
+		{field: fieldTaskOffset, value: t.offset},
+	}
+	for _, d := range durations {
+		if d.value != "" && toNotificationDuration(d.value) == nil {
 			failures = append(failures, validationErr{
-				Field: fieldNotificationEndpointHTTPMethod,
-				Msg:   "http method must be a valid HTTP verb",
+				Field: d.field,
+				Msg:   fmt.Sprintf("invalid duration provided %q", d.value),
 			})
 		}
+	}
 
-		switch n.httpType {
-		case notificationHTTPAuthTypeBasic:
-			if !n.password.hasValue() {
-				failures = append(failures, validationErr{
-					Field: fieldNotificationEndpointPassword,
-					Msg:   "must provide non empty string",
-				})
-			}
-			if !n.username.hasValue() {
-				failures = append(failures, validationErr{
-					Field: fieldNotificationEndpointUsername,
-					Msg:   "must provide non empty string",
-				})
+	if t.query == "" {
+		failures = append(failures, validationErr{
+			Field: fieldQuery,
+			Msg:   "must provide a non empty query",
+		})
+	} else if astPkg := parser.ParseSource(t.query); ast.Check(astPkg) > 0 {
+		failures = append(failures, validationErr{
+			Field: fieldQuery,
+			Msg:   "invalid flux query: " + ast.GetError(astPkg).Error(),
+		})
+	} else if declaresTaskOption(astPkg) {
+		failures = append(failures, validationErr{
+			Field: fieldQuery,
+			Msg:   "must not declare the task option, it is declared from the fields of the task",
+		})
+	}
+
+	if t.status != "" && influxdb.TaskStatusInactive != t.status && influxdb.TaskStatusActive != t.status {
+		failures = append(failures, validationErr{
+			Field: fieldStatus,
+			Msg:   "not a valid status; valid statues are one of [active, inactive]",
+		})
+	}
+	return failures
+}
+
+func declaresTaskOption(pkg *ast.Package) bool {
+	for _, f := range pkg.Files {
+		for _, st := range f.Body {
+			opt, ok := st.(*ast.OptionStatement)
+			if !ok {
+				continue
 			}
-		case notificationHTTPAuthTypeBearer:
-			if !n.token.hasValue() {
-				failures = append(failures, validationErr{
-					Field: fieldNotificationEndpointToken,
-					Msg:   "must provide non empty string",
-				})
+			if va, ok := opt.Assignment.(*ast.VariableAssignment); ok && va.ID.Name == "task" {
+				return true
 			}
-		case notificationHTTPAuthTypeNone:
-		default:
-			failures = append(failures, validationErr{
-				Field: fieldType,
-				Msg: fmt.Sprintf(
-					"invalid type provided %q; valid type is 1 in [%s, %s, %s]",
-					n.httpType,
-					notificationHTTPAuthTypeBasic,
-					notificationHTTPAuthTypeBearer,
-					notificationHTTPAuthTypeNone,
-				),
-			})
 		}
 	}
-	return failures
+	return false
 }
 
-type mapperNotificationEndpoints []*notificationEndpoint
+type mapperTasks []*task
 
-func (n mapperNotificationEndpoints) Association(i int) labelAssociater {
-	return n[i]
+func (m mapperTasks) Association(i int) labelAssociater {
+	return m[i]
 }
 
-func (n mapperNotificationEndpoints) Len() int {
-	return len(n)
+func (m mapperTasks) Len() int {
+	return len(m)
 }
 
 const (
@@ -1106,9 +3034,16 @@ type telegraf struct {
 	config influxdb.TelegrafConfig
 
 	labels sortedLabels
+
+	// existing is the existing telegraf that matches the telegraf, it is
+	// only looked up when applying with only the changes.
+	existing *influxdb.TelegrafConfig
 }
 
 func (t *telegraf) ID() influxdb.ID {
+	if t.existing != nil {
+		return t.existing.ID
+	}
 	return t.config.ID
 }
 
@@ -1125,12 +3060,33 @@ func (t *telegraf) ResourceType() influxdb.ResourceType {
 }
 
 func (t *telegraf) Exists() bool {
-	return false
+	return t.existing != nil
+}
+
+// matches reports whether the existing telegraf has the name, description and
+// config of the telegraf. The plugins are compared regardless of their order,
+// as the order of the plugins parsed from a config is not stable.
+func (t *telegraf) matches(existing influxdb.TelegrafConfig) bool {
+	return t.config.Name == existing.Name &&
+		t.config.Description == existing.Description &&
+		t.config.Agent == existing.Agent &&
+		reflect.DeepEqual(sortedPluginTOMLs(t.config), sortedPluginTOMLs(existing))
+}
+
+func sortedPluginTOMLs(cfg influxdb.TelegrafConfig) []string {
+	tomls := make([]string, 0, len(cfg.Plugins))
+	for _, p := range cfg.Plugins {
+		tomls = append(tomls, p.Config.TOML())
+	}
+	sort.Strings(tomls)
+	return tomls
 }
 
 func (t *telegraf) summarize() SummaryTelegraf {
+	cfg := t.config
+	cfg.ID = t.ID()
 	return SummaryTelegraf{
-		TelegrafConfig:    t.config,
+		TelegrafConfig:    cfg,
 		LabelAssociations: toSummaryLabels(t.labels...),
 	}
 }
@@ -1155,6 +3111,7 @@ type variable struct {
 	id          influxdb.ID
 	OrgID       influxdb.ID
 	name        string
+	displayName string
 	Description string
 	Type        string
 	Query       string
@@ -1186,22 +3143,27 @@ func (v *variable) Name() string {
 	return v.name
 }
 
+func (v *variable) platformName() string {
+	return platformName(v.name, v.displayName)
+}
+
 func (v *variable) ResourceType() influxdb.ResourceType {
 	return KindVariable.ResourceType()
 }
 
 func (v *variable) shouldApply() bool {
 	return v.existing == nil ||
+		v.existing.Name != v.platformName() ||
 		v.existing.Description != v.Description ||
 		v.existing.Arguments == nil ||
-		!reflect.DeepEqual(v.existing.Arguments, v.influxVarArgs())
+		!reflect.DeepEqual(normalizedVarArgs(v.existing.Arguments), v.influxVarArgs())
 }
 
 func (v *variable) summarize() SummaryVariable {
 	return SummaryVariable{
 		ID:                SafeID(v.ID()),
 		OrgID:             SafeID(v.OrgID),
-		Name:              v.Name(),
+		Name:              v.platformName(),
 		Description:       v.Description,
 		Arguments:         v.influxVarArgs(),
 		LabelAssociations: toSummaryLabels(v.labels...),
@@ -1223,9 +3185,21 @@ func (v *variable) influxVarArgs() *influxdb.VariableArguments {
 	case "map":
 		args.Values = influxdb.VariableMapValues(v.MapValues)
 	}
+	args.Normalize()
 	return args
 }
 
+// normalizedVarArgs returns a normalized copy of the arguments of an existing
+// variable, the order of its values is not a change.
+func normalizedVarArgs(args *influxdb.VariableArguments) *influxdb.VariableArguments {
+	if args == nil {
+		return nil
+	}
+	normalized := *args
+	normalized.Normalize()
+	return &normalized
+}
+
 func (v *variable) valid() []validationErr {
 	var failures []validationErr
 	switch v.Type {
@@ -1243,6 +3217,16 @@ func (v *variable) valid() []validationErr {
 				Msg:   "constant variable must have a least 1 value provided",
 			})
 		}
+		constArgs := influxdb.VariableArguments{
+			Type:   "constant",
+			Values: influxdb.VariableConstantValues(v.ConstValues),
+		}
+		if err := constArgs.ValidValues(0); err != nil {
+			failures = append(failures, validationErr{
+				Field: fieldValues,
+				Msg:   influxdb.ErrorMessage(err),
+			})
+		}
 	case "query":
 		if v.Query == "" {
 			failures = append(failures, validationErr{
@@ -1271,20 +3255,29 @@ func (m mapperVariables) Len() int {
 }
 
 const (
-	fieldDashCharts = "charts"
+	fieldDashCharts      = "charts"
+	fieldRefreshInterval = "refreshInterval"
 )
 
 type dashboard struct {
-	id          influxdb.ID
-	OrgID       influxdb.ID
-	name        string
-	Description string
-	Charts      []chart
+	id              influxdb.ID
+	OrgID           influxdb.ID
+	name            string
+	Description     string
+	RefreshInterval time.Duration
+	Charts          []chart
 
 	labels sortedLabels
+
+	// existing is the existing dashboard that matches the dashboard, it is
+	// only looked up when applying with only the changes.
+	existing *influxdb.Dashboard
 }
 
 func (d *dashboard) ID() influxdb.ID {
+	if d.existing != nil {
+		return d.existing.ID
+	}
 	return d.id
 }
 
@@ -1301,7 +3294,24 @@ func (d *dashboard) ResourceType() influxdb.ResourceType {
 }
 
 func (d *dashboard) Exists() bool {
-	return false
+	return d.existing != nil
+}
+
+// matches reports whether the existing dashboard, with the views of its cells,
+// has the description, refresh interval and charts of the dashboard. The charts
+// of both are read back from cells, as the charts of the dashboard are once
+// applied.
+func (d *dashboard) matches(existing influxdb.Dashboard) bool {
+	var refresh time.Duration
+	if existing.Meta.RefreshInterval != nil {
+		refresh = existing.Meta.RefreshInterval.Duration
+	}
+	return d.Description == existing.Description &&
+		d.RefreshInterval == refresh &&
+		reflect.DeepEqual(
+			formattedCharts(cellCharts(convertChartsToCells(d.Charts))),
+			formattedCharts(cellCharts(existing.Cells)),
+		)
 }
 
 func (d *dashboard) summarize() SummaryDashboard {
@@ -1310,15 +3320,17 @@ func (d *dashboard) summarize() SummaryDashboard {
 		OrgID:             SafeID(d.OrgID),
 		Name:              d.Name(),
 		Description:       d.Description,
+		RefreshInterval:   d.RefreshInterval,
 		LabelAssociations: toSummaryLabels(d.labels...),
 	}
 	for _, c := range d.Charts {
 		iDash.Charts = append(iDash.Charts, SummaryChart{
-			Properties: c.properties(),
-			Height:     c.Height,
-			Width:      c.Width,
-			XPosition:  c.XPos,
-			YPosition:  c.YPos,
+			Properties:      c.properties(),
+			Height:          c.Height,
+			Width:           c.Width,
+			XPosition:       c.XPos,
+			YPosition:       c.YPos,
+			RefreshInterval: c.RefreshInterval,
 		})
 	}
 	return iDash
@@ -1378,6 +3390,7 @@ type chart struct {
 	BinCount        int
 	Position        string
 	TimeFormat      string
+	RefreshInterval time.Duration
 }
 
 func (c chart) properties() influxdb.ViewProperties {
@@ -1841,10 +3854,13 @@ type references struct {
 }
 
 func (r references) hasValue() bool {
-	return r.Secret != "" || r.val != nil
+	return r.Secret != "" || r.String() != ""
 }
 
 func (r references) String() string {
+	if v, ok := r.val.(envValue); ok {
+		return string(v)
+	}
 	if r.val != nil {
 		s, _ := r.val.(string)
 		return s
@@ -1862,6 +3878,39 @@ func (r references) SecretField() influxdb.SecretField {
 	return influxdb.SecretField{}
 }
 
+// parseRefreshInterval parses the optional refresh interval of a dashboard or
+// chart resource. A zero duration indicates auto-refresh is disabled.
+func parseRefreshInterval(r Resource) (time.Duration, []validationErr) {
+	s, ok := r.string(fieldRefreshInterval)
+	if !ok || s == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, []validationErr{{
+			Field: fieldRefreshInterval,
+			Msg:   err.Error(),
+		}}
+	}
+
+	if err := influxdb.ValidRefreshInterval(&influxdb.Duration{Duration: d}); err != nil {
+		return 0, []validationErr{{
+			Field: fieldRefreshInterval,
+			Msg:   err.Msg,
+		}}
+	}
+
+	return d, nil
+}
+
+func durationPtr(d time.Duration) *influxdb.Duration {
+	if d == 0 {
+		return nil
+	}
+	return &influxdb.Duration{Duration: d}
+}
+
 func flt64Ptr(f float64) *float64 {
 	if f != 0 {
 		return &f
@@ -1,15 +1,22 @@
 package pkger
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+var updateGolden = flag.Bool("update", false, "update the golden files of the pkg summary and diff JSON")
+
 func TestPkg(t *testing.T) {
 	t.Run("Summary", func(t *testing.T) {
 		t.Run("buckets returned in asc order by name", func(t *testing.T) {
@@ -123,4 +130,91 @@ func TestPkg(t *testing.T) {
 			assert.Equal(t, label1.Name(), mapping1.LabelName)
 		})
 	})
+
+	t.Run("JSON matches golden files", func(t *testing.T) {
+		pkg, err := Parse(EncodingYAML, FromFile("testdata/golden/pkg.yml"))
+		require.NoError(t, err)
+
+		fakeBktSVC := mock.NewBucketService()
+		fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound}
+		}
+		svc := NewService(
+			WithBucketSVC(fakeBktSVC),
+			WithLabelSVC(mock.NewLabelService()),
+			WithVariableSVC(mock.NewVariableService()),
+		)
+
+		sum, diff, err := svc.DryRun(context.TODO(), influxdb.ID(9000), 0, pkg)
+		require.NoError(t, err)
+
+		// the hash is of the contents of the pkg, not of the shape of the summary
+		assert.NotEmpty(t, sum.ContentHash)
+		sum.ContentHash = ""
+
+		tests := []struct {
+			name   string
+			golden string
+			v      interface{}
+		}{
+			{
+				name:   "summary",
+				golden: "testdata/golden/summary.json",
+				v:      sum,
+			},
+			{
+				name:   "diff",
+				golden: "testdata/golden/diff.json",
+				v:      diff,
+			},
+			{
+				name:   "pkg accessors",
+				golden: "testdata/golden/summary.json",
+				v: Summary{
+					Buckets:               pkg.Buckets(),
+					Checks:                pkg.Checks(),
+					Dashboards:            pkg.Dashboards(),
+					Labels:                pkg.Labels(),
+					LabelMappings:         pkg.LabelMappings(),
+					NotificationEndpoints: pkg.NotificationEndpoints(),
+					NotificationRules:     pkg.NotificationRules(),
+					ScraperTargets:        pkg.ScraperTargets(),
+					Tasks:                 pkg.Tasks(),
+					TelegrafConfigs:       pkg.TelegrafConfigs(),
+					Variables:             pkg.Variables(),
+				},
+			},
+			{
+				name:   "empty summary",
+				golden: "testdata/golden/summary_empty.json",
+				v:      Summary{},
+			},
+			{
+				name:   "empty diff",
+				golden: "testdata/golden/diff_empty.json",
+				v:      Diff{},
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				b, err := json.MarshalIndent(tt.v, "", "  ")
+				require.NoError(t, err)
+
+				if *updateGolden {
+					require.NoError(t, ioutil.WriteFile(tt.golden, append(b, '\n'), 0644))
+				}
+
+				golden, err := ioutil.ReadFile(tt.golden)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(golden), string(b))
+
+				// marshaling is stable across calls
+				again, err := json.Marshal(tt.v)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(b), string(again))
+			}
+			t.Run(tt.name, fn)
+		}
+	})
 }
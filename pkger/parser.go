@@ -2,14 +2,17 @@ package pkger
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
@@ -95,20 +98,53 @@ func FromString(s string) ReaderFn {
 }
 
 func parseYAML(r io.Reader, opts ...ValidateOptFn) (*Pkg, error) {
-	return parse(yaml.NewDecoder(r), opts...)
+	dec := yaml.NewDecoder(r)
+
+	// a single yaml file may contain many documents, each separated by
+	// a "---". Each document is its own pkg and are combined together.
+	var pkgs []*Pkg
+	for {
+		var pkg Pkg
+		err := dec.Decode(&pkg)
+		if err == io.EOF && len(pkgs) > 0 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, &pkg)
+	}
+
+	return parse(pkgs, opts...)
 }
 
 func parseJSON(r io.Reader, opts ...ValidateOptFn) (*Pkg, error) {
-	return parse(json.NewDecoder(r), opts...)
-}
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
 
-type decoder interface {
-	Decode(interface{}) error
+	// a json array of pkgs is treated the same as a multi document
+	// yaml file.
+	var pkgs []*Pkg
+	if b := bytes.TrimSpace(raw); len(b) > 0 && b[0] == '[' {
+		if err := json.Unmarshal(b, &pkgs); err != nil {
+			return nil, err
+		}
+	} else {
+		var pkg Pkg
+		if err := json.Unmarshal(raw, &pkg); err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, &pkg)
+	}
+
+	return parse(pkgs, opts...)
 }
 
-func parse(dec decoder, opts ...ValidateOptFn) (*Pkg, error) {
-	var pkg Pkg
-	if err := dec.Decode(&pkg); err != nil {
+func parse(pkgs []*Pkg, opts ...ValidateOptFn) (*Pkg, error) {
+	pkg, err := Combine(pkgs...)
+	if err != nil {
 		return nil, err
 	}
 
@@ -116,7 +152,114 @@ func parse(dec decoder, opts ...ValidateOptFn) (*Pkg, error) {
 		return nil, err
 	}
 
-	return &pkg, nil
+	return pkg, nil
+}
+
+// Combine merges the provided pkgs into a single pkg. The metadata of the
+// first pkg is used for the combined pkg. Resources that are identical
+// across pkgs are deduplicated, the resources of a single pkg never are.
+// Resources of a kind that is uniquely identified by name (buckets, labels,
+// notification endpoints, and variables) that share a name but differ in
+// their definition are a conflict and returned as a parse error. The
+// combined pkg is not validated, that is left to the caller.
+func Combine(pkgs ...*Pkg) (*Pkg, error) {
+	if len(pkgs) == 0 {
+		return nil, errors.New("at least 1 pkg must be provided")
+	}
+
+	newPkg := &Pkg{
+		APIVersion: pkgs[0].APIVersion,
+		Kind:       pkgs[0].Kind,
+		Metadata:   pkgs[0].Metadata,
+	}
+
+	type key struct {
+		kind Kind
+		name string
+	}
+	type origin struct {
+		r      Resource
+		pkgIdx int
+	}
+	var (
+		mUniq   = make(map[key]origin)
+		origins []origin // the origin of each resource of the new pkg
+	)
+
+	var (
+		pErr ParseError
+		idx  int
+	)
+	for pkgIdx, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+
+	ResourceLoop:
+		for _, r := range pkg.Spec.Resources {
+			i := idx
+			idx++
+
+			k, err := r.kind()
+			if err != nil {
+				// unknown kinds are left for the validation to report on
+				newPkg.Spec.Resources = append(newPkg.Spec.Resources, r)
+				origins = append(origins, origin{r: r, pkgIdx: pkgIdx})
+				continue
+			}
+
+			switch {
+			case k.is(KindBucket, KindLabel, KindVariable, KindNotificationEndpoint,
+				KindNotificationEndpointHTTP, KindNotificationEndpointPagerDuty, KindNotificationEndpointSlack,
+				KindNotificationRule, KindCheck, KindCheckDeadman, KindCheckThreshold):
+				switch k.ResourceType() {
+				case influxdb.NotificationEndpointResourceType:
+					// endpoint names are unique across all endpoint kinds
+					k = KindNotificationEndpoint
+				case influxdb.ChecksResourceType:
+					// check names are unique across all check kinds
+					k = KindCheck
+				}
+				rKey := key{kind: k, name: r.Name()}
+				existing, ok := mUniq[rKey]
+				if !ok {
+					mUniq[rKey] = origin{r: r, pkgIdx: pkgIdx}
+					break
+				}
+				if existing.pkgIdx == pkgIdx {
+					// names repeated within a single pkg are left for the
+					// validation to report on
+					break
+				}
+				if !reflect.DeepEqual(existing.r, r) {
+					pErr.append(resourceErr{
+						Kind: k.String(),
+						Name: r.Name(),
+						Idx:  intPtr(i),
+						ValidationErrs: []validationErr{{
+							Field: fieldName,
+							Msg:   "conflicting definitions for name: " + r.Name(),
+						}},
+					})
+				}
+				continue
+			default:
+				for _, existing := range origins {
+					if existing.pkgIdx != pkgIdx && reflect.DeepEqual(existing.r, r) {
+						continue ResourceLoop
+					}
+				}
+			}
+			newPkg.Spec.Resources = append(newPkg.Spec.Resources, r)
+			origins = append(origins, origin{r: r, pkgIdx: pkgIdx})
+		}
+	}
+
+	if len(pErr.Resources) > 0 {
+		return nil, &pErr
+	}
+
+	return newPkg, nil
 }
 
 // Pkg is the model for a package. The resources are more generic that one might
@@ -133,57 +276,161 @@ type Pkg struct {
 		Resources []Resource `yaml:"resources" json:"resources"`
 	} `yaml:"spec" json:"spec"`
 
+	// Warnings describe problems found while creating the pkg from existing
+	// resources or while validating it that did not prevent it from being
+	// created, i.e. a dashboard query referencing a variable that does not
+	// exist or a resource declaring the same label association twice. They
+	// are encoded with the pkg so they reach the clients creating it.
+	Warnings []string `yaml:"warnings,omitempty" json:"warnings,omitempty"`
+
 	mLabels                map[string]*label
 	mBuckets               map[string]*bucket
+	mChecks                map[string]*check
 	mDashboards            []*dashboard
 	mNotificationEndpoints map[string]*notificationEndpoint
+	mNotificationRules     map[string]*notificationRule
+	mScraperTargets        map[string]*scraperTarget
+	mTasks                 []*task
 	mTelegrafs             []*telegraf
 	mVariables             map[string]*variable
 
 	mSecrets map[string]struct{}
 
-	isVerified bool // dry run has verified pkg resources with existing resources
-	isParsed   bool // indicates the pkg has been parsed and all resources graphed accordingly
+	isVerified  bool              // dry run has verified pkg resources with existing resources
+	verifiedSum [sha256.Size]byte // sum of the pkg content hash and apply options the dry run verified
+	isParsed    bool              // indicates the pkg has been parsed and all resources graphed accordingly
+	parsedHash  string            // content hash of the pkg when it was parsed
+
+	// mu serializes the dry runs, applies and removals of the pkg. Each of them
+	// records the existing resources of the org on the resources of the pkg,
+	// i.e. a handler retrying the apply of a pkg it is still applying.
+	mu sync.Mutex
 }
 
 // Summary returns a package Summary that describes all the resources and
 // associations the pkg contains. It is very useful for informing users of
 // the changes that will take place when this pkg would be applied.
 func (p *Pkg) Summary() Summary {
-	var sum Summary
+	return Summary{
+		Buckets:               p.Buckets(),
+		Checks:                p.Checks(),
+		Dashboards:            p.Dashboards(),
+		Labels:                p.Labels(),
+		LabelMappings:         p.LabelMappings(),
+		NotificationEndpoints: p.NotificationEndpoints(),
+		NotificationRules:     p.NotificationRules(),
+		ScraperTargets:        p.ScraperTargets(),
+		Tasks:                 p.Tasks(),
+		TelegrafConfigs:       p.TelegrafConfigs(),
+		Variables:             p.Variables(),
+	}
+}
+
+// The accessors below are the supported means of inspecting the resources of
+// a parsed pkg. Each returns a read-only view of a family of resources,
+// sorted by name, whose shape does not depend on the fields of the pkg file.
+// The pkg must have been parsed or validated.
 
+// Buckets returns the buckets of the pkg.
+func (p *Pkg) Buckets() []SummaryBucket {
+	var out []SummaryBucket
 	for _, b := range p.buckets() {
-		sum.Buckets = append(sum.Buckets, b.summarize())
+		out = append(out, b.summarize())
+	}
+	return out
+}
+
+// Checks returns the checks of the pkg.
+func (p *Pkg) Checks() []SummaryCheck {
+	var out []SummaryCheck
+	for _, c := range p.checks() {
+		out = append(out, c.summarize())
 	}
+	return out
+}
 
+// Dashboards returns the dashboards of the pkg, including their charts.
+func (p *Pkg) Dashboards() []SummaryDashboard {
+	var out []SummaryDashboard
 	for _, d := range p.dashboards() {
-		sum.Dashboards = append(sum.Dashboards, d.summarize())
+		out = append(out, d.summarize())
 	}
+	return out
+}
 
+// Labels returns the labels of the pkg.
+func (p *Pkg) Labels() []SummaryLabel {
+	var out []SummaryLabel
 	for _, l := range p.labels() {
-		sum.Labels = append(sum.Labels, l.summarize())
+		out = append(out, l.summarize())
 	}
+	return out
+}
 
-	sum.LabelMappings = p.labelMappings()
+// LabelMappings returns the associations of the labels of the pkg with its
+// other resources.
+func (p *Pkg) LabelMappings() []SummaryLabelMapping {
+	return p.labelMappings()
+}
 
+// NotificationEndpoints returns the notification endpoints of the pkg.
+func (p *Pkg) NotificationEndpoints() []SummaryNotificationEndpoint {
+	var out []SummaryNotificationEndpoint
 	for _, n := range p.notificationEndpoints() {
-		sum.NotificationEndpoints = append(sum.NotificationEndpoints, n.summarize())
+		out = append(out, n.summarize())
+	}
+	return out
+}
+
+// NotificationRules returns the notification rules of the pkg.
+func (p *Pkg) NotificationRules() []SummaryNotificationRule {
+	var out []SummaryNotificationRule
+	for _, r := range p.notificationRules() {
+		out = append(out, r.summarize())
 	}
+	return out
+}
+
+// ScraperTargets returns the scraper targets of the pkg.
+func (p *Pkg) ScraperTargets() []SummaryScraperTarget {
+	var out []SummaryScraperTarget
+	for _, t := range p.scraperTargets() {
+		out = append(out, t.summarize())
+	}
+	return out
+}
 
+// Tasks returns the tasks of the pkg.
+func (p *Pkg) Tasks() []SummaryTask {
+	var out []SummaryTask
+	for _, t := range p.tasks() {
+		out = append(out, t.summarize())
+	}
+	return out
+}
+
+// TelegrafConfigs returns the telegraf configs of the pkg.
+func (p *Pkg) TelegrafConfigs() []SummaryTelegraf {
+	var out []SummaryTelegraf
 	for _, t := range p.telegrafs() {
-		sum.TelegrafConfigs = append(sum.TelegrafConfigs, t.summarize())
+		out = append(out, t.summarize())
 	}
+	return out
+}
 
+// Variables returns the variables of the pkg.
+func (p *Pkg) Variables() []SummaryVariable {
+	var out []SummaryVariable
 	for _, v := range p.variables() {
-		sum.Variables = append(sum.Variables, v.summarize())
+		out = append(out, v.summarize())
 	}
-
-	return sum
+	return out
 }
 
 type (
 	validateOpt struct {
 		minResources bool
+		env          map[string]string
 	}
 
 	// ValidateOptFn provides a means to disable desired validation checks.
@@ -199,12 +446,36 @@ func ValidWithoutResources() ValidateOptFn {
 	}
 }
 
+// ValidWithEnv substitutes environment references in the values of the
+// pkg's resources, i.e. ${RETENTION_HOURS} or ${RETENTION_HOURS:-72}, with
+// the values from the provided env before the pkg is validated. A reference
+// to a variable that is not set in the env and has no default fails validation.
+func ValidWithEnv(env map[string]string) ValidateOptFn {
+	return func(opt *validateOpt) {
+		if opt.env == nil {
+			opt.env = make(map[string]string, len(env))
+		}
+		for k, v := range env {
+			opt.env[k] = v
+		}
+	}
+}
+
 // Validate will graph all resources and validate every thing is in a useful form.
 func (p *Pkg) Validate(opts ...ValidateOptFn) error {
 	opt := &validateOpt{minResources: true}
 	for _, o := range opts {
 		o(opt)
 	}
+
+	if opt.env != nil {
+		// substitution must happen before anything else so the
+		// validations are run against the concrete values.
+		if err := p.substituteEnv(opt.env); err != nil {
+			return err
+		}
+	}
+
 	setupFns := []func() error{
 		p.validMetadata,
 	}
@@ -213,11 +484,11 @@ func (p *Pkg) Validate(opts ...ValidateOptFn) error {
 	}
 	setupFns = append(setupFns, p.graphResources)
 
-	var pErr parseErr
+	var pErr ParseError
 	for _, fn := range setupFns {
 		if err := fn(); err != nil {
 			if IsParseErr(err) {
-				pErr.append(err.(*parseErr).Resources...)
+				pErr.append(err.(*ParseError).Resources...)
 				continue
 			}
 			return err
@@ -228,10 +499,130 @@ func (p *Pkg) Validate(opts ...ValidateOptFn) error {
 		return &pErr
 	}
 
+	hash, err := p.contentHash()
+	if err != nil {
+		return err
+	}
+	p.parsedHash = hash
 	p.isParsed = true
 	return nil
 }
 
+// clone returns a copy of the graphed pkg that is neither parsed nor validated
+// again. The resources of the copy do not share the existing resources a dry
+// run or apply records on them with the pkg.
+func (p *Pkg) clone() *Pkg {
+	c := &Pkg{
+		APIVersion: p.APIVersion,
+		Kind:       p.Kind,
+		Metadata:   p.Metadata,
+		Warnings:   p.Warnings,
+		mSecrets:   p.mSecrets,
+		isParsed:   p.isParsed,
+		parsedHash: p.parsedHash,
+	}
+	c.Spec.Resources = p.Spec.Resources
+
+	// the resources copied by the resources they are copied from, the label
+	// mappings of the copied labels reference the copied resources.
+	copies := make(map[interface{}]interface{})
+
+	c.mLabels = make(map[string]*label, len(p.mLabels))
+	for name, l := range p.mLabels {
+		cp := *l
+		c.mLabels[name] = &cp
+		copies[l] = &cp
+	}
+	copyLabels := func(labels sortedLabels) sortedLabels {
+		if labels == nil {
+			return nil
+		}
+		out := make(sortedLabels, len(labels))
+		for i, l := range labels {
+			out[i] = copies[l].(*label)
+		}
+		return out
+	}
+
+	c.mBuckets = make(map[string]*bucket, len(p.mBuckets))
+	for name, b := range p.mBuckets {
+		cp := *b
+		cp.labels = copyLabels(b.labels)
+		c.mBuckets[name] = &cp
+		copies[b] = &cp
+	}
+	c.mChecks = make(map[string]*check, len(p.mChecks))
+	for name, ch := range p.mChecks {
+		cp := *ch
+		cp.labels = copyLabels(ch.labels)
+		c.mChecks[name] = &cp
+		copies[ch] = &cp
+	}
+	for _, d := range p.mDashboards {
+		cp := *d
+		cp.labels = copyLabels(d.labels)
+		c.mDashboards = append(c.mDashboards, &cp)
+		copies[d] = &cp
+	}
+	c.mNotificationEndpoints = make(map[string]*notificationEndpoint, len(p.mNotificationEndpoints))
+	for name, e := range p.mNotificationEndpoints {
+		cp := *e
+		cp.labels = copyLabels(e.labels)
+		c.mNotificationEndpoints[name] = &cp
+		copies[e] = &cp
+	}
+	c.mNotificationRules = make(map[string]*notificationRule, len(p.mNotificationRules))
+	for name, r := range p.mNotificationRules {
+		cp := *r
+		cp.labels = copyLabels(r.labels)
+		c.mNotificationRules[name] = &cp
+		copies[r] = &cp
+	}
+	c.mScraperTargets = make(map[string]*scraperTarget, len(p.mScraperTargets))
+	for name, t := range p.mScraperTargets {
+		cp := *t
+		cp.labels = copyLabels(t.labels)
+		c.mScraperTargets[name] = &cp
+		copies[t] = &cp
+	}
+	for _, t := range p.mTasks {
+		cp := *t
+		cp.labels = copyLabels(t.labels)
+		c.mTasks = append(c.mTasks, &cp)
+		copies[t] = &cp
+	}
+	for _, t := range p.mTelegrafs {
+		cp := *t
+		cp.labels = copyLabels(t.labels)
+		c.mTelegrafs = append(c.mTelegrafs, &cp)
+		copies[t] = &cp
+	}
+	c.mVariables = make(map[string]*variable, len(p.mVariables))
+	for name, v := range p.mVariables {
+		cp := *v
+		cp.labels = copyLabels(v.labels)
+		c.mVariables[name] = &cp
+		copies[v] = &cp
+	}
+
+	for _, l := range c.mLabels {
+		if l.mappings == nil {
+			continue
+		}
+		mappings := make(map[assocMapKey][]assocMapVal, len(l.mappings))
+		for k, vals := range l.mappings {
+			cpVals := make([]assocMapVal, len(vals))
+			for i, v := range vals {
+				cpVals[i] = assocMapVal{exists: v.exists, v: copies[v.v]}
+			}
+			mappings[k] = cpVals
+		}
+		l.mappings = mappings
+	}
+
+	return c
+}
+
 func (p *Pkg) buckets() []*bucket {
 	buckets := make([]*bucket, 0, len(p.mBuckets))
 	for _, b := range p.mBuckets {
@@ -243,6 +634,17 @@ func (p *Pkg) buckets() []*bucket {
 	return buckets
 }
 
+func (p *Pkg) checks() []*check {
+	checks := make([]*check, 0, len(p.mChecks))
+	for _, c := range p.mChecks {
+		checks = append(checks, c)
+	}
+
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name() < checks[j].Name() })
+
+	return checks
+}
+
 func (p *Pkg) labels() []*label {
 	labels := make(sortedLabels, 0, len(p.mLabels))
 	for _, b := range p.mLabels {
@@ -256,7 +658,8 @@ func (p *Pkg) labels() []*label {
 
 func (p *Pkg) dashboards() []*dashboard {
 	dashes := p.mDashboards[:]
-	sort.Slice(dashes, func(i, j int) bool { return dashes[i].name < dashes[j].name })
+	// dashboards may share a name, a stable sort keeps them in pkg order
+	sort.SliceStable(dashes, func(i, j int) bool { return dashes[i].name < dashes[j].name })
 	return dashes
 }
 
@@ -275,6 +678,17 @@ func (p *Pkg) notificationEndpoints() []*notificationEndpoint {
 	return endpoints
 }
 
+func (p *Pkg) notificationRules() []*notificationRule {
+	rules := make([]*notificationRule, 0, len(p.mNotificationRules))
+	for _, r := range p.mNotificationRules {
+		rules = append(rules, r)
+	}
+
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name() < rules[j].Name() })
+
+	return rules
+}
+
 func (p *Pkg) secrets() map[string]bool {
 	// copies the secrets map so we can destroy this one without concern
 	secrets := make(map[string]bool, len(p.mSecrets))
@@ -284,9 +698,74 @@ func (p *Pkg) secrets() map[string]bool {
 	return secrets
 }
 
+// verify marks the pkg as verified by a dry run with the provided options.
+func (p *Pkg) verify(opt ApplyOpt) {
+	sum, err := p.verificationSum(opt)
+	if err != nil {
+		p.isVerified = false
+		return
+	}
+	p.isVerified = true
+	p.verifiedSum = sum
+}
+
+// isVerifiedWith returns true when the pkg has been verified by a dry run with the
+// provided options, and the pkg has not been modified since.
+func (p *Pkg) isVerifiedWith(opt ApplyOpt) bool {
+	if !p.isVerified {
+		return false
+	}
+	sum, err := p.verificationSum(opt)
+	return err == nil && sum == p.verifiedSum
+}
+
+func (p *Pkg) verificationSum(opt ApplyOpt) ([sha256.Size]byte, error) {
+	hash, err := p.contentHash()
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	b, err := json.Marshal(struct {
+		Hash string   `json:"hash"`
+		Opt  ApplyOpt `json:"opt"`
+	}{Hash: hash, Opt: opt})
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(b), nil
+}
+
+// isParsedCurrent returns true when the pkg has been parsed and its spec has
+// not been modified since, i.e. by a caller adding resources to a parsed pkg.
+func (p *Pkg) isParsedCurrent() bool {
+	if !p.isParsed {
+		return false
+	}
+	hash, err := p.contentHash()
+	return err == nil && hash == p.parsedHash
+}
+
+func (p *Pkg) scraperTargets() []*scraperTarget {
+	targets := make([]*scraperTarget, 0, len(p.mScraperTargets))
+	for _, t := range p.mScraperTargets {
+		targets = append(targets, t)
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name() < targets[j].Name() })
+
+	return targets
+}
+
+func (p *Pkg) tasks() []*task {
+	tasks := p.mTasks[:]
+	// tasks may share a name, a stable sort keeps them in pkg order
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Name() < tasks[j].Name() })
+	return tasks
+}
+
 func (p *Pkg) telegrafs() []*telegraf {
 	teles := p.mTelegrafs[:]
-	sort.Slice(teles, func(i, j int) bool { return teles[i].Name() < teles[j].Name() })
+	// telegrafs may share a name, a stable sort keeps them in pkg order
+	sort.SliceStable(teles, func(i, j int) bool { return teles[i].Name() < teles[j].Name() })
 	return teles
 }
 
@@ -311,27 +790,69 @@ func (p *Pkg) labelMappings() []SummaryLabelMapping {
 		mappings = append(mappings, l.mappingSummary()...)
 	}
 
-	// sort by res type ASC, then res name ASC, then label name ASC
+	mappings = dedupeLabelMappings(mappings)
+
+	// sort by res type ASC, then res name ASC, then label name ASC. The
+	// mappings come from maps, resources sharing a name, i.e. dashboards,
+	// are ordered by res ID to keep the order stable.
 	sort.Slice(mappings, func(i, j int) bool {
 		n, m := mappings[i], mappings[j]
-		if n.ResourceType < m.ResourceType {
-			return true
-		}
-		if n.ResourceType > m.ResourceType {
-			return false
+		if n.ResourceType != m.ResourceType {
+			return n.ResourceType < m.ResourceType
 		}
-		if n.ResourceName < m.ResourceName {
-			return true
+		if n.ResourceName != m.ResourceName {
+			return n.ResourceName < m.ResourceName
 		}
-		if n.ResourceName > m.ResourceName {
-			return false
+		if n.LabelName != m.LabelName {
+			return n.LabelName < m.LabelName
 		}
-		return n.LabelName < m.LabelName
+		return n.ResourceID < m.ResourceID
 	})
 
 	return mappings
 }
 
+// dedupeLabelMappings removes the mappings of the same label to the same
+// resource, creating the mapping a second time fails and would roll back
+// the entire apply.
+func dedupeLabelMappings(mappings []SummaryLabelMapping) []SummaryLabelMapping {
+	type key struct {
+		labelName    string
+		resourceType influxdb.ResourceType
+		resourceName string
+		resourceID   SafeID
+	}
+
+	seen := make(map[key]bool, len(mappings))
+	deduped := mappings[:0]
+	for _, m := range mappings {
+		k := key{
+			labelName:    m.LabelName,
+			resourceType: m.ResourceType,
+			resourceName: m.ResourceName,
+			resourceID:   m.ResourceID,
+		}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		deduped = append(deduped, m)
+	}
+	return deduped
+}
+
+// warn adds the warning to the pkg, a warning that is already present is not
+// added again as validation may run more than once.
+func (p *Pkg) warn(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	for _, w := range p.Warnings {
+		if w == msg {
+			return
+		}
+	}
+	p.Warnings = append(p.Warnings, msg)
+}
+
 func (p *Pkg) validMetadata() error {
 	var failures []validationErr
 	if p.APIVersion != APIVersion {
@@ -374,7 +895,7 @@ func (p *Pkg) validMetadata() error {
 		return nil
 	}
 
-	var err parseErr
+	var err ParseError
 	err.append(resourceErr{
 		Kind:     KindPackage.String(),
 		RootErrs: failures,
@@ -394,7 +915,7 @@ func (p *Pkg) validResources() error {
 			Msg:   "at least 1 resource must be provided",
 		}},
 	}
-	var err parseErr
+	var err ParseError
 	err.append(res)
 	return &err
 }
@@ -402,17 +923,22 @@ func (p *Pkg) validResources() error {
 func (p *Pkg) graphResources() error {
 	p.mSecrets = make(map[string]struct{})
 
-	graphFns := []func() *parseErr{
+	graphFns := []func() *ParseError{
 		// labels are first, this is to validate associations with other resources
 		p.graphLabels,
 		p.graphVariables,
 		p.graphBuckets,
+		p.graphChecks,
 		p.graphDashboards,
 		p.graphNotificationEndpoints,
+		// rules are after the endpoints, they reference the endpoints
+		p.graphNotificationRules,
+		p.graphScraperTargets,
+		p.graphTasks,
 		p.graphTelegrafs,
 	}
 
-	var pErr parseErr
+	var pErr ParseError
 	for _, fn := range graphFns {
 		if err := fn(); err != nil {
 			pErr.append(err.Resources...)
@@ -430,7 +956,7 @@ func (p *Pkg) graphResources() error {
 	return nil
 }
 
-func (p *Pkg) graphBuckets() *parseErr {
+func (p *Pkg) graphBuckets() *ParseError {
 	p.mBuckets = make(map[string]*bucket)
 	return p.eachResource(KindBucket, 2, func(r Resource) []validationErr {
 		if _, ok := p.mBuckets[r.Name()]; ok {
@@ -442,15 +968,18 @@ func (p *Pkg) graphBuckets() *parseErr {
 
 		bkt := &bucket{
 			name:        r.Name(),
+			displayName: r.stringShort(fieldDisplayName),
 			Description: r.stringShort(fieldDescription),
 		}
 		if rules, ok := r[fieldBucketRetentionRules].(retentionRules); ok {
 			bkt.RetentionRules = rules
 		} else {
 			for _, r := range r.slcResource(fieldBucketRetentionRules) {
+				_, ok := r[fieldRetentionRulesEverySeconds]
 				bkt.RetentionRules = append(bkt.RetentionRules, retentionRule{
-					Type:    r.stringShort(fieldType),
-					Seconds: r.intShort(fieldRetentionRulesEverySeconds),
+					Type:         r.stringShort(fieldType),
+					Seconds:      r.intShort(fieldRetentionRulesEverySeconds),
+					secondsUnset: !ok,
 				})
 			}
 		}
@@ -468,7 +997,7 @@ func (p *Pkg) graphBuckets() *parseErr {
 	})
 }
 
-func (p *Pkg) graphLabels() *parseErr {
+func (p *Pkg) graphLabels() *ParseError {
 	p.mLabels = make(map[string]*label)
 	return p.eachResource(KindLabel, 2, func(r Resource) []validationErr {
 		if _, ok := p.mLabels[r.Name()]; ok {
@@ -479,6 +1008,7 @@ func (p *Pkg) graphLabels() *parseErr {
 		}
 		p.mLabels[r.Name()] = &label{
 			name:        r.Name(),
+			displayName: r.stringShort(fieldDisplayName),
 			Color:       r.stringShort(fieldLabelColor),
 			Description: r.stringShort(fieldDescription),
 		}
@@ -487,19 +1017,22 @@ func (p *Pkg) graphLabels() *parseErr {
 	})
 }
 
-func (p *Pkg) graphDashboards() *parseErr {
+func (p *Pkg) graphDashboards() *ParseError {
 	p.mDashboards = make([]*dashboard, 0)
 	return p.eachResource(KindDashboard, 2, func(r Resource) []validationErr {
+		refreshInterval, failures := parseRefreshInterval(r)
+
 		dash := &dashboard{
-			name:        r.Name(),
-			Description: r.stringShort(fieldDescription),
+			name:            r.Name(),
+			Description:     r.stringShort(fieldDescription),
+			RefreshInterval: refreshInterval,
 		}
 
-		failures := p.parseNestedLabels(r, func(l *label) error {
+		failures = append(failures, p.parseNestedLabels(r, func(l *label) error {
 			dash.labels = append(dash.labels, l)
 			p.mLabels[l.Name()].setMapping(dash, false)
 			return nil
-		})
+		})...)
 		sort.Sort(dash.labels)
 
 		for i, cr := range r.slcResource(fieldDashCharts) {
@@ -521,7 +1054,7 @@ func (p *Pkg) graphDashboards() *parseErr {
 	})
 }
 
-func (p *Pkg) graphNotificationEndpoints() *parseErr {
+func (p *Pkg) graphNotificationEndpoints() *ParseError {
 	p.mNotificationEndpoints = make(map[string]*notificationEndpoint)
 
 	notificationKinds := []struct {
@@ -540,9 +1073,14 @@ func (p *Pkg) graphNotificationEndpoints() *parseErr {
 			kind:             KindNotificationEndpointSlack,
 			notificationKind: notificationKindSlack,
 		},
+		{
+			// the generic kind is accepted, the concrete kind is
+			// inferred from the fields provided
+			kind: KindNotificationEndpoint,
+		},
 	}
 
-	var pErr parseErr
+	var pErr ParseError
 	for _, nk := range notificationKinds {
 		err := p.eachResource(nk.kind, 1, func(r Resource) []validationErr {
 			if _, ok := p.mNotificationEndpoints[r.Name()]; ok {
@@ -552,9 +1090,15 @@ func (p *Pkg) graphNotificationEndpoints() *parseErr {
 				}}
 			}
 
+			kind := nk.notificationKind
+			if kind == 0 {
+				kind = r.notificationEndpointKind()
+			}
+
 			endpoint := &notificationEndpoint{
-				kind:        nk.notificationKind,
+				kind:        kind,
 				name:        r.Name(),
+				displayName: r.stringShort(fieldDisplayName),
 				description: r.stringShort(fieldDescription),
 				method:      strings.TrimSpace(strings.ToUpper(r.stringShort(fieldNotificationEndpointHTTPMethod))),
 				httpType:    normStr(r.stringShort(fieldType)),
@@ -592,7 +1136,140 @@ func (p *Pkg) graphNotificationEndpoints() *parseErr {
 	return nil
 }
 
-func (p *Pkg) graphVariables() *parseErr {
+func (p *Pkg) graphNotificationRules() *ParseError {
+	p.mNotificationRules = make(map[string]*notificationRule)
+	return p.eachResource(KindNotificationRule, 1, func(r Resource) []validationErr {
+		if _, ok := p.mNotificationRules[r.Name()]; ok {
+			return []validationErr{{
+				Field: "name",
+				Msg:   "duplicate name: " + r.Name(),
+			}}
+		}
+
+		rule := &notificationRule{
+			name:         r.Name(),
+			displayName:  r.stringShort(fieldDisplayName),
+			description:  r.stringShort(fieldDescription),
+			channel:      r.stringShort(fieldNotificationRuleChannel),
+			endpointName: r.stringShort(fieldNotificationRuleEndpointName),
+			every:        r.stringShort(fieldNotificationRuleEvery),
+			msgTemplate:  r.stringShort(fieldNotificationRuleMessageTemplate),
+			offset:       r.stringShort(fieldNotificationRuleOffset),
+			status:       normStr(r.stringShort(fieldStatus)),
+		}
+		// the endpoints are graphed before the rules, the type of an
+		// endpoint outside of the pkg is resolved by the dry run
+		if e, ok := p.mNotificationEndpoints[rule.endpointName]; ok {
+			rule.endpointType = e.endpointType()
+		}
+
+		for _, sr := range r.slcResource(fieldNotificationRuleStatusRules) {
+			rule.statusRules = append(rule.statusRules, ruleStatusRule{
+				curLvl:  strings.TrimSpace(strings.ToUpper(sr.stringShort(fieldNotificationRuleCurrentLevel))),
+				prevLvl: strings.TrimSpace(strings.ToUpper(sr.stringShort(fieldNotificationRulePreviousLevel))),
+			})
+		}
+		for _, tr := range r.slcResource(fieldNotificationRuleTagRules) {
+			op := normStr(tr.stringShort(fieldNotificationRuleOperator))
+			if op == "" {
+				op = "equal"
+			}
+			rule.tagRules = append(rule.tagRules, ruleTagRule{
+				key:   tr.stringShort(fieldKey),
+				value: tr.stringShort(fieldValue),
+				op:    op,
+			})
+		}
+
+		failures := p.parseNestedLabels(r, func(l *label) error {
+			rule.labels = append(rule.labels, l)
+			p.mLabels[l.Name()].setMapping(rule, false)
+			return nil
+		})
+		sort.Sort(rule.labels)
+
+		p.mNotificationRules[rule.Name()] = rule
+		return append(failures, rule.valid()...)
+	})
+}
+
+func (p *Pkg) graphChecks() *ParseError {
+	p.mChecks = make(map[string]*check)
+
+	checkKinds := []struct {
+		kind      Kind
+		checkKind checkKind
+	}{
+		{kind: KindCheckDeadman, checkKind: checkKindDeadman},
+		{kind: KindCheckThreshold, checkKind: checkKindThreshold},
+	}
+
+	var pErr ParseError
+	for _, ck := range checkKinds {
+		err := p.eachResource(ck.kind, 1, func(r Resource) []validationErr {
+			if _, ok := p.mChecks[r.Name()]; ok {
+				return []validationErr{{
+					Field: "name",
+					Msg:   "duplicate name: " + r.Name(),
+				}}
+			}
+
+			ch := &check{
+				kind:          ck.checkKind,
+				name:          r.Name(),
+				displayName:   r.stringShort(fieldDisplayName),
+				description:   r.stringShort(fieldDescription),
+				bucket:        r.stringShort(fieldCheckBucket),
+				every:         r.stringShort(fieldCheckEvery),
+				field:         r.stringShort(fieldCheckField),
+				level:         strings.TrimSpace(strings.ToUpper(r.stringShort(fieldCheckLevel))),
+				offset:        r.stringShort(fieldCheckOffset),
+				query:         strings.TrimSpace(r.stringShort(fieldQuery)),
+				reportZero:    r.boolShort(fieldCheckReportZero),
+				staleTime:     r.stringShort(fieldCheckStaleTime),
+				status:        normStr(r.stringShort(fieldStatus)),
+				statusMessage: r.stringShort(fieldCheckStatusMessageTemplate),
+				timeSince:     r.stringShort(fieldCheckTimeSince),
+			}
+			for _, tr := range r.slcResource(fieldCheckTags) {
+				ch.tags = append(ch.tags, influxdb.Tag{
+					Key:   tr.stringShort(fieldKey),
+					Value: tr.stringShort(fieldValue),
+				})
+			}
+			for _, th := range r.slcResource(fieldCheckThresholds) {
+				ch.thresholds = append(ch.thresholds, threshold{
+					threshType: normStr(th.stringShort(fieldType)),
+					allVals:    th.boolShort(fieldCheckAllValues),
+					level:      strings.TrimSpace(strings.ToUpper(th.stringShort(fieldCheckLevel))),
+					val:        th.float64Short(fieldValue),
+					min:        th.float64Short(fieldCheckMin),
+					max:        th.float64Short(fieldCheckMax),
+					within:     th.boolShort(fieldCheckWithin),
+				})
+			}
+
+			failures := p.parseNestedLabels(r, func(l *label) error {
+				ch.labels = append(ch.labels, l)
+				p.mLabels[l.Name()].setMapping(ch, false)
+				return nil
+			})
+			sort.Sort(ch.labels)
+
+			p.mChecks[ch.Name()] = ch
+			return append(failures, ch.valid()...)
+		})
+		if err != nil {
+			pErr.append(err.Resources...)
+		}
+	}
+	if len(pErr.Resources) > 0 {
+		return &pErr
+	}
+	return nil
+}
+
+func (p *Pkg) graphVariables() *ParseError {
 	p.mVariables = make(map[string]*variable)
 	return p.eachResource(KindVariable, 1, func(r Resource) []validationErr {
 		if _, ok := p.mVariables[r.Name()]; ok {
@@ -604,6 +1281,7 @@ func (p *Pkg) graphVariables() *parseErr {
 
 		newVar := &variable{
 			name:        r.Name(),
+			displayName: r.stringShort(fieldDisplayName),
 			Description: r.stringShort(fieldDescription),
 			Type:        normStr(r.stringShort(fieldType)),
 			Query:       strings.TrimSpace(r.stringShort(fieldQuery)),
@@ -626,7 +1304,66 @@ func (p *Pkg) graphVariables() *parseErr {
 	})
 }
 
-func (p *Pkg) graphTelegrafs() *parseErr {
+func (p *Pkg) graphScraperTargets() *ParseError {
+	p.mScraperTargets = make(map[string]*scraperTarget)
+	return p.eachResource(KindScraperTarget, 1, func(r Resource) []validationErr {
+		if _, ok := p.mScraperTargets[r.Name()]; ok {
+			return []validationErr{{
+				Field: "name",
+				Msg:   "duplicate name: " + r.Name(),
+			}}
+		}
+
+		target := &scraperTarget{
+			name:   r.Name(),
+			typ:    influxdb.ScraperType(normStr(r.stringShort(fieldType))),
+			url:    r.stringShort(fieldScraperTargetURL),
+			bucket: r.stringShort(fieldScraperTargetBucket),
+		}
+		if target.typ == "" {
+			target.typ = influxdb.PrometheusScraperType
+		}
+
+		failures := p.parseNestedLabels(r, func(l *label) error {
+			target.labels = append(target.labels, l)
+			p.mLabels[l.Name()].setMapping(target, false)
+			return nil
+		})
+		sort.Sort(target.labels)
+
+		p.mScraperTargets[r.Name()] = target
+
+		return append(failures, target.valid()...)
+	})
+}
+
+func (p *Pkg) graphTasks() *ParseError {
+	p.mTasks = make([]*task, 0)
+	return p.eachResource(KindTask, 1, func(r Resource) []validationErr {
+		t := &task{
+			name:        r.Name(),
+			cron:        r.stringShort(fieldTaskCron),
+			description: r.stringShort(fieldDescription),
+			every:       r.stringShort(fieldTaskEvery),
+			offset:      r.stringShort(fieldTaskOffset),
+			query:       strings.TrimSpace(r.stringShort(fieldQuery)),
+			status:      normStr(r.stringShort(fieldStatus)),
+		}
+
+		failures := p.parseNestedLabels(r, func(l *label) error {
+			t.labels = append(t.labels, l)
+			p.mLabels[l.Name()].setMapping(t, false)
+			return nil
+		})
+		sort.Sort(t.labels)
+
+		p.mTasks = append(p.mTasks, t)
+
+		return append(failures, t.valid()...)
+	})
+}
+
+func (p *Pkg) graphTelegrafs() *ParseError {
 	p.mTelegrafs = make([]*telegraf, 0)
 	return p.eachResource(KindTelegraf, 0, func(r Resource) []validationErr {
 		tele := new(telegraf)
@@ -654,8 +1391,8 @@ func (p *Pkg) graphTelegrafs() *parseErr {
 	})
 }
 
-func (p *Pkg) eachResource(resourceKind Kind, minNameLen int, fn func(r Resource) []validationErr) *parseErr {
-	var pErr parseErr
+func (p *Pkg) eachResource(resourceKind Kind, minNameLen int, fn func(r Resource) []validationErr) *ParseError {
+	var pErr ParseError
 	for i, r := range p.Spec.Resources {
 		k, err := r.kind()
 		if err != nil {
@@ -678,6 +1415,7 @@ func (p *Pkg) eachResource(resourceKind Kind, minNameLen int, fn func(r Resource
 		if len(r.Name()) < minNameLen {
 			pErr.append(resourceErr{
 				Kind: k.String(),
+				Name: r.Name(),
 				Idx:  intPtr(i),
 				ValidationErrs: []validationErr{
 					{
@@ -692,6 +1430,7 @@ func (p *Pkg) eachResource(resourceKind Kind, minNameLen int, fn func(r Resource
 		if failures := fn(r); failures != nil {
 			err := resourceErr{
 				Kind: resourceKind.String(),
+				Name: r.Name(),
 				Idx:  intPtr(i),
 			}
 			for _, f := range failures {
@@ -724,7 +1463,10 @@ func (p *Pkg) parseNestedLabels(r Resource, fn func(lb *label) error) []validati
 	for i, nr := range r.slcResource(fieldAssociations) {
 		fail := p.parseNestedLabel(nr, func(l *label) error {
 			if _, ok := nestedLabels[l.Name()]; ok {
-				return fmt.Errorf("duplicate nested label: %q", l.Name())
+				// the association is already made, declaring it again
+				// is harmless and is not worth failing the pkg over.
+				p.warn("%s %q declares the association with label %q more than once", r.stringShort(fieldKind), r.Name(), l.Name())
+				return nil
 			}
 			nestedLabels[l.Name()] = l
 
@@ -816,7 +1558,9 @@ func parseChart(r Resource) (chart, []validationErr) {
 		c.DecimalPlaces = dp
 	}
 
-	var failures []validationErr
+	refreshInterval, failures := parseRefreshInterval(r)
+	c.RefreshInterval = refreshInterval
+
 	if presentQueries, ok := r[fieldChartQueries].(queries); ok {
 		c.Queries = presentQueries
 	} else {
@@ -903,6 +1647,19 @@ func (r Resource) kind() (Kind, error) {
 	return k, k.OK()
 }
 
+// notificationEndpointKind infers the concrete kind of an endpoint resource
+// provided with the generic notification endpoint kind.
+func (r Resource) notificationEndpointKind() notificationKind {
+	switch {
+	case r[fieldNotificationEndpointRoutingKey] != nil:
+		return notificationKindPagerDuty
+	case r[fieldNotificationEndpointHTTPMethod] != nil, r[fieldType] != nil:
+		return notificationKindHTTP
+	default:
+		return notificationKindSlack
+	}
+}
+
 func (r Resource) chartKind() (chartKind, error) {
 	ck, _ := r.kind()
 	chartKind := chartKind(ck)
@@ -913,6 +1670,9 @@ func (r Resource) chartKind() (chartKind, error) {
 }
 
 func (r Resource) bool(key string) (bool, bool) {
+	if v, ok := r[key].(envValue); ok {
+		return v.bool()
+	}
 	b, ok := r[key].(bool)
 	return b, ok
 }
@@ -923,6 +1683,10 @@ func (r Resource) boolShort(key string) bool {
 }
 
 func (r Resource) float64(key string) (float64, bool) {
+	if v, ok := r[key].(envValue); ok {
+		return v.float64()
+	}
+
 	f, ok := r[key].(float64)
 	if ok {
 		return f, true
@@ -941,6 +1705,10 @@ func (r Resource) float64Short(key string) float64 {
 }
 
 func (r Resource) int(key string) (int, bool) {
+	if v, ok := r[key].(envValue); ok {
+		return v.int()
+	}
+
 	i, ok := r[key].(int)
 	if ok {
 		return i, true
@@ -1108,6 +1876,10 @@ func ifaceToStr(v interface{}) (string, bool) {
 		return s, true
 	}
 
+	if s, ok := v.(envValue); ok {
+		return string(s), true
+	}
+
 	if i, ok := v.(int); ok {
 		return strconv.Itoa(i), true
 	}
@@ -1151,13 +1923,15 @@ func uniqResources(resources []Resource) []Resource {
 	return out
 }
 
-// ParseError is the error from parsing the given package. The ParseError
-// behavior provides a list of resources that failed and all validations
-// that failed for that resource. A resource can multiple errors, and
-// a parseErr can have multiple resources which themselves can have
-// multiple validation failures.
-type ParseError interface {
-	ValidationErrs() []ValidationErr
+// ParseError is the error from parsing the given package. It provides a
+// list of resources that failed and all validations that failed for that
+// resource. A resource can have multiple errors, and a ParseError can have
+// multiple resources which themselves can have multiple validation failures.
+// ValidationErrs flattens them, each one locating the failure by the kind,
+// name and index of its resource and the path of its field.
+type ParseError struct {
+	Resources []resourceErr
+	rawErrs   []ValidationErr
 }
 
 // NewParseError creates a new parse error from existing validation errors.
@@ -1165,19 +1939,15 @@ func NewParseError(errs ...ValidationErr) error {
 	if len(errs) == 0 {
 		return nil
 	}
-	return &parseErr{rawErrs: errs}
+	return &ParseError{rawErrs: errs}
 }
 
 type (
-	parseErr struct {
-		Resources []resourceErr
-		rawErrs   []ValidationErr
-	}
-
 	// resourceErr describes the error for a particular resource. In
 	// which it may have numerous validation and association errors.
 	resourceErr struct {
 		Kind            string
+		Name            string
 		Idx             *int
 		RootErrs        []validationErr
 		AssociationErrs []validationErr
@@ -1194,7 +1964,7 @@ type (
 )
 
 // Error implements the error interface.
-func (e *parseErr) Error() string {
+func (e *ParseError) Error() string {
 	var errMsg []string
 	for _, ve := range append(e.ValidationErrs(), e.rawErrs...) {
 		errMsg = append(errMsg, ve.Error())
@@ -1203,11 +1973,12 @@ func (e *parseErr) Error() string {
 	return strings.Join(errMsg, "\n\t")
 }
 
-func (e *parseErr) ValidationErrs() []ValidationErr {
+func (e *ParseError) ValidationErrs() []ValidationErr {
 	errs := e.rawErrs[:]
 	for _, r := range e.Resources {
 		rootErr := ValidationErr{
 			Kind: r.Kind,
+			Name: r.Name,
 		}
 		for _, v := range r.RootErrs {
 			errs = append(errs, traverseErrs(rootErr, v)...)
@@ -1222,9 +1993,13 @@ func (e *parseErr) ValidationErrs() []ValidationErr {
 	return errs
 }
 
-// ValidationErr represents an error during the parsing of a package.
+// ValidationErr represents an error during the parsing of a package. The
+// Fields are the path to the field that failed from the root of the package,
+// with the index of each field that is an array in Indexes. The Name is the
+// name of the resource that failed, if it has one.
 type ValidationErr struct {
 	Kind    string   `json:"kind" yaml:"kind"`
+	Name    string   `json:"name,omitempty" yaml:"name,omitempty"`
 	Fields  []string `json:"fields" yaml:"fields"`
 	Indexes []*int   `json:"idxs" yaml:"idxs"`
 	Reason  string   `json:"reason" yaml:"reason"`
@@ -1241,6 +2016,9 @@ func (v ValidationErr) Error() string {
 		fieldPairs = append(fieldPairs, fmt.Sprintf("%s[%d]", field, *idx))
 	}
 
+	if v.Name != "" {
+		return fmt.Sprintf("kind=%s name=%s field=%s reason=%q", v.Kind, v.Name, strings.Join(fieldPairs, "."), v.Reason)
+	}
 	return fmt.Sprintf("kind=%s field=%s reason=%q", v.Kind, strings.Join(fieldPairs, "."), v.Reason)
 }
 
@@ -1259,17 +2037,14 @@ func traverseErrs(root ValidationErr, vErr validationErr) []ValidationErr {
 	return errs
 }
 
-func (e *parseErr) append(errs ...resourceErr) {
+func (e *ParseError) append(errs ...resourceErr) {
 	e.Resources = append(e.Resources, errs...)
 }
 
 // IsParseErr inspects a given error to determine if it is
-// a parseErr. If a parseErr it is, it will return it along
-// with the confirmation boolean. If the error is not a parseErr
-// it will return nil values for the parseErr, making it unsafe
-// to use.
+// a *ParseError.
 func IsParseErr(err error) bool {
-	_, ok := err.(*parseErr)
+	_, ok := err.(*ParseError)
 	return ok
 }
 
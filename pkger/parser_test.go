@@ -1,6 +1,7 @@
 package pkger
 
 import (
+	"fmt"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -8,6 +9,8 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	icheck "github.com/influxdata/influxdb/notification/check"
 	"github.com/influxdata/influxdb/notification/endpoint"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -208,6 +211,80 @@ spec:
 				testPkgErrors(t, KindBucket, tt)
 			}
 		})
+
+		t.Run("retention rules", func(t *testing.T) {
+			tests := []struct {
+				name    string
+				rule    string
+				wantRP  time.Duration
+				wantErr bool
+			}{
+				{
+					name:   "valid",
+					rule:   "everySeconds: 7200",
+					wantRP: 2 * time.Hour,
+				},
+				{
+					name:   "zero retains forever",
+					rule:   "everySeconds: 0",
+					wantRP: 0,
+				},
+				{
+					name:    "negative",
+					rule:    "everySeconds: -3600",
+					wantErr: true,
+				},
+				{
+					name:    "less than an hour",
+					rule:    "everySeconds: 60",
+					wantErr: true,
+				},
+				{
+					name:    "too large",
+					rule:    "everySeconds: 9223372036854775807",
+					wantErr: true,
+				},
+				{
+					name:    "unset",
+					wantErr: true,
+				},
+			}
+
+			for _, tt := range tests {
+				fn := func(t *testing.T) {
+					pkgStr := fmt.Sprintf(`apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Bucket
+      name: rucket_1
+      retentionRules:
+        - type: expire
+          %s
+`, tt.rule)
+
+					pkg, err := Parse(EncodingYAML, FromString(pkgStr))
+					if tt.wantErr {
+						require.Error(t, err)
+						require.True(t, IsParseErr(err), err)
+						pErr := err.(*ParseError)
+						require.Len(t, pErr.Resources, 1)
+						assert.Equal(t, "rucket_1", pErr.Resources[0].Name)
+						findErr(t, fieldBucketRetentionRules+"[0]."+fieldRetentionRulesEverySeconds, pErr.Resources[0].ValidationErrs[0])
+						return
+					}
+					require.NoError(t, err)
+
+					buckets := pkg.buckets()
+					require.Len(t, buckets, 1)
+					assert.Equal(t, tt.wantRP, buckets[0].RetentionRules.RP())
+				}
+				t.Run(tt.name, fn)
+			}
+		})
 	})
 
 	t.Run("pkg with a label", func(t *testing.T) {
@@ -348,6 +425,33 @@ spec:
 			}
 		})
 
+		t.Run("with the same label associated twice", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket_associates_label_duplicates.yml", func(t *testing.T, pkg *Pkg) {
+				sum := pkg.Summary()
+				require.Len(t, sum.Buckets, 2)
+				require.Len(t, sum.Buckets[0].LabelAssociations, 1)
+
+				expectedMappings := []SummaryLabelMapping{
+					{
+						ResourceName: "rucket_1",
+						LabelName:    "label_1",
+					},
+					{
+						ResourceName: "rucket_2",
+						LabelName:    "label_1",
+					},
+				}
+
+				require.Len(t, sum.LabelMappings, len(expectedMappings))
+				for i, expected := range expectedMappings {
+					expected.ResourceType = influxdb.BucketsResourceType
+					assert.Equal(t, expected, sum.LabelMappings[i])
+				}
+
+				assert.Equal(t, []string{`Bucket "rucket_1" declares the association with label "label_1" more than once`}, pkg.Warnings)
+			})
+		})
+
 		t.Run("association doesn't exist then provides an error", func(t *testing.T) {
 			tests := []testPkgResourceError{
 				{
@@ -410,28 +514,6 @@ spec:
           name: not found 1
         - kind: Label
           name: unfound label
-`,
-				},
-				{
-					name:    "duplicate valid nested labels",
-					assErrs: 1,
-					assIdxs: []int{1},
-					pkgStr: `apiVersion: 0.1.0
-kind: Package
-meta:
-  pkgName: label_pkg
-  pkgVersion: 1
-spec:
-  resources:
-    - kind: Label
-      name: label_1
-    - kind: Bucket
-      name: buck_1
-      associations:
-        - kind: Label
-          name: label_1
-        - kind: Label
-          name: label_1
 `,
 				},
 			}
@@ -1815,9 +1897,11 @@ spec:
 				actual := sum.Dashboards[0]
 				assert.Equal(t, "dash_1", actual.Name)
 				assert.Equal(t, "desc1", actual.Description)
+				assert.Equal(t, time.Minute, actual.RefreshInterval)
 
 				require.Len(t, actual.Charts, 1)
 				actualChart := actual.Charts[0]
+				assert.Equal(t, 30*time.Second, actualChart.RefreshInterval)
 				assert.Equal(t, 3, actualChart.Height)
 				assert.Equal(t, 6, actualChart.Width)
 				assert.Equal(t, 1, actualChart.XPosition)
@@ -1996,6 +2080,55 @@ spec:
             - name: laser
               type: text
               hex: "#aaa333"
+`,
+					},
+					{
+						name:           "chart refresh interval below minimum",
+						validationErrs: 1,
+						valFields:      []string{"charts[0].refreshInterval"},
+						pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Dashboard
+      name: dash_1
+      description: desc1
+      charts:
+        - kind:   Single_Stat
+          name:   single stat
+          suffix: days
+          width:  6
+          height: 3
+          refreshInterval: 1s
+          queries:
+            - query: >
+                from(bucket: v.bucket) |> range(start: v.timeRangeStart) |> filter(fn: (r) => r._measurement == "system") |> filter(fn: (r) => r._field == "uptime") |> last() |> map(fn: (r) => ({r with _value: r._value / 86400})) |> yield(name: "last")
+          colors:
+            - name: laser
+              type: text
+              hex: "#aaa333"
+`,
+					},
+					{
+						name:           "dashboard refresh interval is not a duration",
+						validationErrs: 1,
+						valFields:      []string{"refreshInterval"},
+						pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Dashboard
+      name: dash_1
+      description: desc1
+      refreshInterval: often
 `,
 					},
 				}
@@ -2670,28 +2803,6 @@ spec:
           name: not found 1
         - kind: Label
           name: unfound label
-`,
-				},
-				{
-					name:    "duplicate valid nested labels",
-					assErrs: 1,
-					assIdxs: []int{1},
-					pkgStr: `apiVersion: 0.1.0
-kind: Package
-meta:
-  pkgName: label_pkg
-  pkgVersion: 1
-spec:
-  resources:
-    - kind: Label
-      name: label_1
-    - kind: Dashboard
-      name: dash_1
-      associations:
-        - kind: Label
-          name: label_1
-        - kind: Label
-          name: label_1
 `,
 				},
 			}
@@ -2788,6 +2899,46 @@ spec:
 			}
 		})
 
+		t.Run("infers the concrete kind of a generic endpoint", func(t *testing.T) {
+			pkgStr := `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Notification_Endpoint
+      name: http_endpoint
+      type: none
+      method: GET
+      url: https://www.example.com/endpoint/noneauth
+    - kind: Notification_Endpoint
+      name: pager_duty_endpoint
+      url: http://localhost:8080/orgs/7167eb6719fa34e5/alert-history
+      routingKey: routing-key
+    - kind: Notification_Endpoint
+      name: slack_endpoint
+      url: https://hooks.slack.com/services/bip/piddy/boppidy
+`
+			pkg, err := Parse(EncodingYAML, FromString(pkgStr))
+			require.NoError(t, err)
+
+			endpoints := pkg.Summary().NotificationEndpoints
+			require.Len(t, endpoints, 3)
+
+			types := make(map[string]string)
+			for _, e := range endpoints {
+				types[e.NotificationEndpoint.GetName()] = e.NotificationEndpoint.Type()
+			}
+			expected := map[string]string{
+				"http_endpoint":       endpoint.HTTPType,
+				"pager_duty_endpoint": endpoint.PagerDutyType,
+				"slack_endpoint":      endpoint.SlackType,
+			}
+			assert.Equal(t, expected, types)
+		})
+
 		t.Run("handles bad config", func(t *testing.T) {
 			tests := []struct {
 				kind   Kind
@@ -3003,6 +3154,74 @@ spec:
       type: bearer
       method: GET
       url: example.com
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointPagerDuty,
+					resErr: testPkgResourceError{
+						name:           "missing pager duty routing key",
+						validationErrs: 1,
+						valFields:      []string{fieldNotificationEndpointRoutingKey},
+						pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Notification_Endpoint_Pager_Duty
+      name: name1
+      url: http://localhost:8080/orgs/7167eb6719fa34e5/alert-history
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointHTTP,
+					resErr: testPkgResourceError{
+						name:           "empty basic username",
+						validationErrs: 1,
+						valFields:      []string{fieldNotificationEndpointUsername},
+						pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Notification_Endpoint_HTTP
+      name: name1
+      type: basic
+      url: example.com
+      method: POST
+      username: ""
+      password: password
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointHTTP,
+					resErr: testPkgResourceError{
+						name:           "bearer token secretRef missing key",
+						validationErrs: 1,
+						valFields:      []string{fieldNotificationEndpointToken},
+						pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Notification_Endpoint_HTTP
+      name: name1
+      type: bearer
+      method: GET
+      url: example.com
+      token:
+        secretRef: {}
 `,
 					},
 				},
@@ -3080,35 +3299,53 @@ spec:
 		})
 	})
 
-	t.Run("pkg with telegraf and label associations", func(t *testing.T) {
+	t.Run("pkg with tasks and label associations", func(t *testing.T) {
 		t.Run("with valid fields", func(t *testing.T) {
-			testfileRunner(t, "testdata/telegraf", func(t *testing.T, pkg *Pkg) {
+			testfileRunner(t, "testdata/task", func(t *testing.T, pkg *Pkg) {
 				sum := pkg.Summary()
-				require.Len(t, sum.TelegrafConfigs, 1)
-
-				actual := sum.TelegrafConfigs[0]
-				assert.Equal(t, "first_tele_config", actual.TelegrafConfig.Name)
-				assert.Equal(t, "desc", actual.TelegrafConfig.Description)
-
+				require.Len(t, sum.Tasks, 2)
+
+				query := "from(bucket: \"rucket\")\n  |> range(start: -1h)\n  |> to(bucket: \"rucket_agg\")"
+
+				actual := sum.Tasks[0]
+				assert.Equal(t, "task_0", actual.Name)
+				assert.Equal(t, "15 * * * *", actual.Cron)
+				assert.Empty(t, actual.Every)
+				assert.Equal(t, query, actual.Query)
+				assert.Equal(t, influxdb.Inactive, actual.Status)
+				assert.Empty(t, actual.LabelAssociations)
+
+				actual = sum.Tasks[1]
+				assert.Equal(t, "task_1", actual.Name)
+				assert.Equal(t, "desc_1", actual.Description)
+				assert.Equal(t, "1h", actual.Every)
+				assert.Equal(t, "5m", actual.Offset)
+				assert.Equal(t, query, actual.Query)
+				assert.Equal(t, influxdb.Active, actual.Status)
 				require.Len(t, actual.LabelAssociations, 1)
 				assert.Equal(t, "label_1", actual.LabelAssociations[0].Name)
 
 				require.Len(t, sum.LabelMappings, 1)
 				expectedMapping := SummaryLabelMapping{
-					ResourceName: "first_tele_config",
+					ResourceName: "task_1",
 					LabelName:    "label_1",
-					ResourceType: influxdb.TelegrafsResourceType,
+					ResourceType: influxdb.TasksResourceType,
 				}
 				assert.Equal(t, expectedMapping, sum.LabelMappings[0])
+
+				tasks := pkg.tasks()
+				require.Len(t, tasks, 2)
+				expectedFlux := "option task = {name: \"task_1\", every: 1h, offset: 5m}\n\n" + query
+				assert.Equal(t, expectedFlux, tasks[1].flux())
 			})
 		})
 
 		t.Run("handles bad config", func(t *testing.T) {
 			tests := []testPkgResourceError{
 				{
-					name:           "config missing",
+					name:           "missing schedule",
 					validationErrs: 1,
-					valFields:      []string{"config"},
+					valFields:      []string{fieldTaskEvery},
 					pkgStr: `apiVersion: 0.1.0
 kind: Package
 meta:
@@ -3117,50 +3354,635 @@ meta:
   description:  pack description
 spec:
   resources:
-    - kind: Telegraf
-      name: tele_name
-      description: desc
+    - kind: Task
+      name: task_1
+      query: |
+        from(bucket: "rucket") |> range(start: -1h)
 `,
 				},
-			}
-
-			for _, tt := range tests {
-				testPkgErrors(t, KindTelegraf, tt)
-			}
-		})
-	})
-
-	t.Run("pkg with a variable", func(t *testing.T) {
-		t.Run("with valid fields should produce summary", func(t *testing.T) {
-			testfileRunner(t, "testdata/variables", func(t *testing.T, pkg *Pkg) {
-				sum := pkg.Summary()
-
-				require.Len(t, sum.Variables, 4)
-
-				varEquals := func(t *testing.T, name, vType string, vals interface{}, v SummaryVariable) {
-					t.Helper()
-
-					assert.Equal(t, name, v.Name)
-					assert.Equal(t, name+" desc", v.Description)
-					require.NotNil(t, v.Arguments)
-					assert.Equal(t, vType, v.Arguments.Type)
-					assert.Equal(t, vals, v.Arguments.Values)
-				}
-
-				// validates we support all known variable types
-				varEquals(t,
-					"var_const_3",
-					"constant",
-					influxdb.VariableConstantValues([]string{"first val"}),
-					sum.Variables[0],
-				)
-
-				varEquals(t,
-					"var_map_4",
-					"map",
-					influxdb.VariableMapValues{"k1": "v1"},
-					sum.Variables[1],
-				)
+				{
+					name:           "every and cron",
+					validationErrs: 1,
+					valFields:      []string{fieldTaskCron},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Task
+      name: task_1
+      every: 1h
+      cron: "15 * * * *"
+      query: |
+        from(bucket: "rucket") |> range(start: -1h)
+`,
+				},
+				{
+					name:           "invalid every duration",
+					validationErrs: 1,
+					valFields:      []string{fieldTaskEvery},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Task
+      name: task_1
+      every: 1 hour
+      query: |
+        from(bucket: "rucket") |> range(start: -1h)
+`,
+				},
+				{
+					name:           "missing query",
+					validationErrs: 1,
+					valFields:      []string{fieldQuery},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Task
+      name: task_1
+      every: 1h
+`,
+				},
+				{
+					name:           "query declaring the task option",
+					validationErrs: 1,
+					valFields:      []string{fieldQuery},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Task
+      name: task_1
+      every: 1h
+      query: |
+        option task = {name: "task_1", every: 1h}
+        from(bucket: "rucket") |> range(start: -1h)
+`,
+				},
+				{
+					name:           "invalid status",
+					validationErrs: 1,
+					valFields:      []string{fieldStatus},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Task
+      name: task_1
+      every: 1h
+      status: paused
+      query: |
+        from(bucket: "rucket") |> range(start: -1h)
+`,
+				},
+			}
+
+			for _, tt := range tests {
+				testPkgErrors(t, KindTask, tt)
+			}
+		})
+	})
+
+	t.Run("pkg with telegraf and label associations", func(t *testing.T) {
+		t.Run("with valid fields", func(t *testing.T) {
+			testfileRunner(t, "testdata/telegraf", func(t *testing.T, pkg *Pkg) {
+				sum := pkg.Summary()
+				require.Len(t, sum.TelegrafConfigs, 1)
+
+				actual := sum.TelegrafConfigs[0]
+				assert.Equal(t, "first_tele_config", actual.TelegrafConfig.Name)
+				assert.Equal(t, "desc", actual.TelegrafConfig.Description)
+
+				require.Len(t, actual.LabelAssociations, 1)
+				assert.Equal(t, "label_1", actual.LabelAssociations[0].Name)
+
+				require.Len(t, sum.LabelMappings, 1)
+				expectedMapping := SummaryLabelMapping{
+					ResourceName: "first_tele_config",
+					LabelName:    "label_1",
+					ResourceType: influxdb.TelegrafsResourceType,
+				}
+				assert.Equal(t, expectedMapping, sum.LabelMappings[0])
+			})
+		})
+
+		t.Run("handles bad config", func(t *testing.T) {
+			tests := []testPkgResourceError{
+				{
+					name:           "config missing",
+					validationErrs: 1,
+					valFields:      []string{"config"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Telegraf
+      name: tele_name
+      description: desc
+`,
+				},
+			}
+
+			for _, tt := range tests {
+				testPkgErrors(t, KindTelegraf, tt)
+			}
+		})
+	})
+
+	t.Run("pkg with scraper targets and label associations", func(t *testing.T) {
+		t.Run("with valid fields", func(t *testing.T) {
+			testfileRunner(t, "testdata/scraper_target", func(t *testing.T, pkg *Pkg) {
+				sum := pkg.Summary()
+				require.Len(t, sum.ScraperTargets, 2)
+
+				actual := sum.ScraperTargets[0]
+				assert.Equal(t, "scraper_1", actual.Name)
+				assert.Equal(t, influxdb.ScraperType(influxdb.PrometheusScraperType), actual.Type)
+				assert.Equal(t, "http://localhost:9100/metrics", actual.URL)
+				assert.Equal(t, "rucket_1", actual.Bucket)
+				require.Len(t, actual.LabelAssociations, 1)
+				assert.Equal(t, "label_1", actual.LabelAssociations[0].Name)
+
+				actual = sum.ScraperTargets[1]
+				assert.Equal(t, "scraper_2", actual.Name)
+				// the type defaults to prometheus
+				assert.Equal(t, influxdb.ScraperType(influxdb.PrometheusScraperType), actual.Type)
+				assert.Equal(t, "https://example.com/metrics", actual.URL)
+				assert.Equal(t, "org_rucket", actual.Bucket)
+				assert.Empty(t, actual.LabelAssociations)
+
+				require.Len(t, sum.LabelMappings, 1)
+				expectedMapping := SummaryLabelMapping{
+					ResourceName: "scraper_1",
+					LabelName:    "label_1",
+					ResourceType: influxdb.ScraperResourceType,
+				}
+				assert.Equal(t, expectedMapping, sum.LabelMappings[0])
+			})
+		})
+
+		t.Run("handles bad config", func(t *testing.T) {
+			tests := []testPkgResourceError{
+				{
+					name:           "missing url and bucket",
+					validationErrs: 2,
+					valFields:      []string{"url", "bucket"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Scraper_Target
+      name: scraper_1
+`,
+				},
+				{
+					name:           "invalid type",
+					validationErrs: 1,
+					valFields:      []string{"type"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Scraper_Target
+      name: scraper_1
+      type: graphite
+      url: http://localhost:9100/metrics
+      bucket: rucket_1
+`,
+				},
+				{
+					name:           "duplicate name",
+					validationErrs: 1,
+					valFields:      []string{"name"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Scraper_Target
+      name: scraper_1
+      url: http://localhost:9100/metrics
+      bucket: rucket_1
+    - kind: Scraper_Target
+      name: scraper_1
+      url: http://localhost:9100/metrics
+      bucket: rucket_1
+`,
+				},
+			}
+
+			for _, tt := range tests {
+				testPkgErrors(t, KindScraperTarget, tt)
+			}
+		})
+	})
+
+	t.Run("pkg with a threshold check and label associations", func(t *testing.T) {
+		t.Run("with valid fields", func(t *testing.T) {
+			testfileRunner(t, "testdata/check_threshold", func(t *testing.T, pkg *Pkg) {
+				sum := pkg.Summary()
+				require.Len(t, sum.Checks, 1)
+
+				actual := sum.Checks[0]
+				assert.Equal(t, influxdb.Inactive, actual.Status)
+				require.Len(t, actual.LabelAssociations, 1)
+				assert.Equal(t, "label_1", actual.LabelAssociations[0].Name)
+
+				thresh, ok := actual.Check.(*icheck.Threshold)
+				require.True(t, ok)
+				assert.Equal(t, "check_1", thresh.Name)
+				assert.Equal(t, "desc_1", thresh.Description)
+				assert.Equal(t, time.Minute, thresh.Every.TimeDuration())
+				assert.Equal(t, 15*time.Second, thresh.Offset.TimeDuration())
+				assert.Equal(t, []string{"rucket_1"}, thresh.Query.BuilderConfig.Buckets)
+				assert.Equal(t, []influxdb.Tag{{Key: "tag_1", Value: "val_1"}}, thresh.Tags)
+				assert.Equal(t, "Check: ${ r._check_name } is: ${ r._level }", thresh.StatusMessageTemplate)
+
+				expectedThresholds := []icheck.ThresholdConfig{
+					&icheck.Greater{
+						ThresholdConfigBase: icheck.ThresholdConfigBase{AllValues: true, Level: notification.Critical},
+						Value:               50,
+					},
+					&icheck.Lesser{
+						ThresholdConfigBase: icheck.ThresholdConfigBase{Level: notification.Ok},
+						Value:               10,
+					},
+					&icheck.Range{
+						ThresholdConfigBase: icheck.ThresholdConfigBase{Level: notification.Warn},
+						Min:                 30,
+						Max:                 45,
+						Within:              true,
+					},
+				}
+				assert.Equal(t, expectedThresholds, thresh.Thresholds)
+
+				// the check generates the flux of its task
+				_, err := thresh.GenerateFlux()
+				require.NoError(t, err)
+
+				require.Len(t, sum.LabelMappings, 1)
+				expectedMapping := SummaryLabelMapping{
+					ResourceName: "check_1",
+					LabelName:    "label_1",
+					ResourceType: influxdb.ChecksResourceType,
+				}
+				assert.Equal(t, expectedMapping, sum.LabelMappings[0])
+			})
+		})
+
+		t.Run("handles bad config", func(t *testing.T) {
+			tests := []testPkgResourceError{
+				{
+					name:           "missing bucket, query and every",
+					validationErrs: 3,
+					valFields:      []string{"bucket", "query", "every"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Check_Threshold
+      name: check_1
+      field: usage_user
+      thresholds:
+        - type: greater
+          level: CRIT
+          value: 50
+`,
+				},
+				{
+					name:           "offset not less than every",
+					validationErrs: 1,
+					valFields:      []string{"offset"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Check_Threshold
+      name: check_1
+      bucket: rucket_1
+      field: usage_user
+      query: 'from(bucket: "rucket_1") |> range(start: -1m)'
+      every: 1m
+      offset: 1m
+      thresholds:
+        - type: greater
+          level: CRIT
+          value: 50
+`,
+				},
+				{
+					name:           "missing field and thresholds",
+					validationErrs: 2,
+					valFields:      []string{"field", "thresholds"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Check_Threshold
+      name: check_1
+      bucket: rucket_1
+      query: 'from(bucket: "rucket_1") |> range(start: -1m)'
+      every: 1m
+`,
+				},
+				{
+					name:           "invalid thresholds",
+					validationErrs: 2,
+					valFields:      []string{"thresholds[0].type", "thresholds[1].min"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Check_Threshold
+      name: check_1
+      bucket: rucket_1
+      field: usage_user
+      query: 'from(bucket: "rucket_1") |> range(start: -1m)'
+      every: 1m
+      thresholds:
+        - type: rando
+          level: CRIT
+        - type: range
+          level: WARN
+          min: 50
+          max: 30
+`,
+				},
+				{
+					name:           "duplicate name",
+					validationErrs: 1,
+					valFields:      []string{"name"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Check_Threshold
+      name: check_1
+      bucket: rucket_1
+      field: usage_user
+      query: 'from(bucket: "rucket_1") |> range(start: -1m)'
+      every: 1m
+      thresholds:
+        - type: greater
+          level: CRIT
+          value: 50
+    - kind: Check_Threshold
+      name: check_1
+      bucket: rucket_1
+      field: usage_user
+      query: 'from(bucket: "rucket_1") |> range(start: -1m)'
+      every: 1m
+      thresholds:
+        - type: greater
+          level: CRIT
+          value: 50
+`,
+				},
+			}
+
+			for _, tt := range tests {
+				testPkgErrors(t, KindCheckThreshold, tt)
+			}
+
+			testPkgErrors(t, KindCheckDeadman, testPkgResourceError{
+				name:           "deadman missing stale time",
+				validationErrs: 1,
+				valFields:      []string{"staleTime"},
+				pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Check_Deadman
+      name: check_1
+      bucket: rucket_1
+      query: 'from(bucket: "rucket_1") |> range(start: -1m)'
+      every: 1m
+      level: CRIT
+      timeSince: 90s
+`,
+			})
+		})
+	})
+
+	t.Run("pkg with a notification rule and label associations", func(t *testing.T) {
+		t.Run("with valid fields", func(t *testing.T) {
+			testfileRunner(t, "testdata/notification_rule", func(t *testing.T, pkg *Pkg) {
+				sum := pkg.Summary()
+				require.Len(t, sum.NotificationRules, 1)
+
+				actual := sum.NotificationRules[0]
+				assert.Equal(t, "rule_1", actual.Name)
+				assert.Equal(t, "desc_1", actual.Description)
+				assert.Equal(t, "endpoint_1", actual.EndpointName)
+				assert.Equal(t, endpoint.SlackType, actual.EndpointType)
+				assert.Equal(t, "#alerts", actual.Channel)
+				assert.Equal(t, "10m", actual.Every)
+				assert.Equal(t, "30s", actual.Offset)
+				assert.Equal(t, "Notification Rule: ${ r._notification_rule_name } triggered by check: ${ r._check_name }: ${ r._message }", actual.MessageTemplate)
+				assert.Equal(t, influxdb.Inactive, actual.Status)
+
+				expectedStatusRules := []SummaryStatusRule{
+					{CurrentLevel: "WARN"},
+					{CurrentLevel: "CRIT", PreviousLevel: "OK"},
+				}
+				assert.Equal(t, expectedStatusRules, actual.StatusRules)
+
+				expectedTagRules := []SummaryTagRule{
+					{Key: "k1", Value: "v1", Operator: "equal"},
+					{Key: "k2", Value: "v2", Operator: "notequal"},
+				}
+				assert.Equal(t, expectedTagRules, actual.TagRules)
+
+				require.Len(t, actual.LabelAssociations, 1)
+				assert.Equal(t, "label_1", actual.LabelAssociations[0].Name)
+
+				require.Len(t, sum.LabelMappings, 1)
+				expectedMapping := SummaryLabelMapping{
+					ResourceName: "rule_1",
+					LabelName:    "label_1",
+					ResourceType: influxdb.NotificationRuleResourceType,
+				}
+				assert.Equal(t, expectedMapping, sum.LabelMappings[0])
+			})
+		})
+
+		t.Run("handles bad config", func(t *testing.T) {
+			tests := []testPkgResourceError{
+				{
+					name:           "missing endpoint name, every and status rules",
+					validationErrs: 3,
+					valFields:      []string{"endpointName", "every", "statusRules"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Notification_Rule
+      name: rule_1
+`,
+				},
+				{
+					name:           "missing message template of a slack endpoint",
+					validationErrs: 1,
+					valFields:      []string{"messageTemplate"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Notification_Endpoint_Slack
+      name: endpoint_1
+      url: https://hooks.slack.com/services/bip/piddy/boppidy
+    - kind: Notification_Rule
+      name: rule_1
+      endpointName: endpoint_1
+      every: 10m
+      statusRules:
+        - currentLevel: CRIT
+`,
+				},
+				{
+					name:           "invalid status and tag rules",
+					validationErrs: 2,
+					valFields:      []string{"statusRules[0].currentLevel", "tagRules[0].operator"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Notification_Rule
+      name: rule_1
+      endpointName: endpoint_1
+      every: 10m
+      statusRules:
+        - currentLevel: RANDO
+      tagRules:
+        - key: k1
+          value: v1
+          operator: rando
+`,
+				},
+				{
+					name:           "duplicate name",
+					validationErrs: 1,
+					valFields:      []string{"name"},
+					pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Notification_Rule
+      name: rule_1
+      endpointName: endpoint_1
+      every: 10m
+      statusRules:
+        - currentLevel: CRIT
+    - kind: Notification_Rule
+      name: rule_1
+      endpointName: endpoint_1
+      every: 10m
+      statusRules:
+        - currentLevel: CRIT
+`,
+				},
+			}
+
+			for _, tt := range tests {
+				testPkgErrors(t, KindNotificationRule, tt)
+			}
+		})
+	})
+
+	t.Run("pkg with a variable", func(t *testing.T) {
+		t.Run("with valid fields should produce summary", func(t *testing.T) {
+			testfileRunner(t, "testdata/variables", func(t *testing.T, pkg *Pkg) {
+				sum := pkg.Summary()
+
+				require.Len(t, sum.Variables, 4)
+
+				varEquals := func(t *testing.T, name, vType string, vals interface{}, v SummaryVariable) {
+					t.Helper()
+
+					assert.Equal(t, name, v.Name)
+					assert.Equal(t, name+" desc", v.Description)
+					require.NotNil(t, v.Arguments)
+					assert.Equal(t, vType, v.Arguments.Type)
+					assert.Equal(t, vals, v.Arguments.Values)
+				}
+
+				// validates we support all known variable types
+				varEquals(t,
+					"var_const_3",
+					"constant",
+					influxdb.VariableConstantValues([]string{"first val"}),
+					sum.Variables[0],
+				)
+
+				varEquals(t,
+					"var_map_4",
+					"map",
+					influxdb.VariableMapValues{"k1": "v1"},
+					sum.Variables[1],
+				)
 
 				varEquals(t,
 					"var_query_1",
@@ -3366,6 +4188,89 @@ spec:
 		})
 	})
 
+	t.Run("pkg with multiple documents", func(t *testing.T) {
+		t.Run("combines all documents into a single pkg", func(t *testing.T) {
+			testfileRunner(t, "testdata/multi_document", func(t *testing.T, pkg *Pkg) {
+				sum := pkg.Summary()
+
+				require.Len(t, sum.Buckets, 1)
+				assert.Equal(t, "rucket_1", sum.Buckets[0].Name)
+
+				labels := sum.Labels
+				require.Len(t, labels, 2)
+				assert.Equal(t, "label_1", labels[0].Name)
+				assert.Equal(t, "label_2", labels[1].Name)
+
+				require.Len(t, sum.LabelMappings, 1)
+				assert.Equal(t, "rucket_1", sum.LabelMappings[0].ResourceName)
+				assert.Equal(t, "label_1", sum.LabelMappings[0].LabelName)
+			})
+		})
+
+		t.Run("handles conflicting resources", func(t *testing.T) {
+			tests := []struct {
+				kind Kind
+				testPkgResourceError
+			}{
+				{
+					kind: KindLabel,
+					testPkgResourceError: testPkgResourceError{
+						name:      "label with different color",
+						valFields: []string{fieldName},
+						pkgStr: `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Label
+      name: label_1
+      color: "#FFFFFF"
+---
+apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Label
+      name: label_1
+      color: "#000000"
+`,
+					},
+				},
+				{
+					kind: KindBucket,
+					testPkgResourceError: testPkgResourceError{
+						name:      "json array of pkgs with different bucket descriptions",
+						encoding:  EncodingJSON,
+						valFields: []string{fieldName},
+						pkgStr: `[
+  {
+    "apiVersion": "0.1.0",
+    "kind": "Package",
+    "meta": {"pkgName": "pkg_name", "pkgVersion": "1"},
+    "spec": {"resources": [{"kind": "Bucket", "name": "rucket_1", "description": "desc 1"}]}
+  },
+  {
+    "apiVersion": "0.1.0",
+    "kind": "Package",
+    "meta": {"pkgName": "pkg_name", "pkgVersion": "1"},
+    "spec": {"resources": [{"kind": "Bucket", "name": "rucket_1", "description": "desc 2"}]}
+  }
+]`,
+					},
+				},
+			}
+
+			for _, tt := range tests {
+				testPkgErrors(t, tt.kind, tt.testPkgResourceError)
+			}
+		})
+	})
+
 	t.Run("referencing secrets", func(t *testing.T) {
 		testfileRunner(t, "testdata/notification_endpoint_secrets.yml", func(t *testing.T, pkg *Pkg) {
 			sum := pkg.Summary()
@@ -3393,6 +4298,31 @@ spec:
 	})
 }
 
+func TestPkg_clone(t *testing.T) {
+	pkg := parsePkgFile(t, "testdata/bucket_associates_label.yml")
+	hash := pkg.parsedHash
+
+	c := pkg.clone()
+	assert.Equal(t, pkg.Summary(), c.Summary())
+	assert.True(t, c.isParsedCurrent())
+	assert.Equal(t, hash, c.parsedHash)
+
+	// the existing resources recorded on the copy are not shared with the pkg
+	bkt := c.mBuckets["rucket_1"]
+	require.True(t, pkg.mBuckets["rucket_1"] != bkt)
+	bkt.existing = &influxdb.Bucket{ID: 1}
+	assert.Nil(t, pkg.mBuckets["rucket_1"].existing)
+
+	lbl := c.mLabels["label_1"]
+	require.True(t, pkg.mLabels["label_1"] != lbl)
+	require.Len(t, bkt.labels, 1)
+	assert.True(t, lbl == bkt.labels[0])
+
+	vals := lbl.mappings[assocMapKey{resType: influxdb.BucketsResourceType, name: "rucket_1"}]
+	require.Len(t, vals, 1)
+	assert.True(t, bkt == vals[0].v)
+}
+
 func Test_PkgValidationErr(t *testing.T) {
 	iPtr := func(i int) *int {
 		return &i
@@ -3414,7 +4344,7 @@ func Test_PkgValidationErr(t *testing.T) {
 		}
 	}
 
-	pErr := &parseErr{
+	pErr := &ParseError{
 		Resources: []resourceErr{
 			{
 				Kind: KindDashboard.String(),
@@ -3458,6 +4388,69 @@ func Test_PkgValidationErr(t *testing.T) {
 	assert.Equal(t, "chart kind must be provided", errs[1].Reason)
 }
 
+func Test_ParseError(t *testing.T) {
+	pkgStr := `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Label
+      name: a
+    - kind: Variable
+      name: var_1
+      type: map
+    - kind: Notification_Rule
+      name: rule_1
+      endpointName: endpoint_1
+      every: 10m
+      statusRules:
+        - currentLevel: RANDO
+`
+	_, err := Parse(EncodingYAML, FromString(pkgStr))
+	require.Error(t, err)
+	require.True(t, IsParseErr(err))
+
+	pErr, ok := err.(*ParseError)
+	require.True(t, ok)
+
+	errs := pErr.ValidationErrs()
+	require.Len(t, errs, 3)
+
+	idxs := func(vErr ValidationErr) []int {
+		var out []int
+		for _, idx := range vErr.Indexes {
+			if idx == nil {
+				out = append(out, -1)
+				continue
+			}
+			out = append(out, *idx)
+		}
+		return out
+	}
+
+	assert.Equal(t, KindLabel.String(), errs[0].Kind)
+	assert.Equal(t, "a", errs[0].Name)
+	assert.Equal(t, []string{"spec.resources", "name"}, errs[0].Fields)
+	assert.Equal(t, []int{0, -1}, idxs(errs[0]))
+	assert.NotEmpty(t, errs[0].Reason)
+
+	assert.Equal(t, KindVariable.String(), errs[1].Kind)
+	assert.Equal(t, "var_1", errs[1].Name)
+	assert.Equal(t, []string{"spec.resources", "values"}, errs[1].Fields)
+	assert.Equal(t, []int{1, -1}, idxs(errs[1]))
+	assert.Equal(t, "map variable must have at least 1 key/val pair", errs[1].Reason)
+
+	assert.Equal(t, KindNotificationRule.String(), errs[2].Kind)
+	assert.Equal(t, "rule_1", errs[2].Name)
+	assert.Equal(t, []string{"spec.resources", "statusRules", "currentLevel"}, errs[2].Fields)
+	assert.Equal(t, []int{2, 0, -1}, idxs(errs[2]))
+	assert.Contains(t, errs[2].Reason, "RANDO")
+
+	assert.Contains(t, err.Error(), "name=rule_1 field=spec.resources[2].statusRules[0].currentLevel")
+}
+
 type testPkgResourceError struct {
 	name           string
 	encoding       Encoding
@@ -3491,7 +4484,7 @@ func testPkgErrors(t *testing.T, k Kind, tt testPkgResourceError) {
 
 		require.True(t, IsParseErr(err), err)
 
-		pErr := err.(*parseErr)
+		pErr := err.(*ParseError)
 		require.Len(t, pErr.Resources, resErrs)
 
 		resErr := pErr.Resources[0]
@@ -0,0 +1,63 @@
+package pkger
+
+import (
+	"context"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+)
+
+// missingPermissions returns the permissions the authorizer of ctx lacks to
+// create and update the resources of the pkg in the org, one write permission
+// for each kind of resource the pkg holds. A ctx without an authorizer is not
+// checked, the services of the Service are not wrapped by an authorizer then.
+func missingPermissions(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]influxdb.Permission, error) {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		// no authorizer to check, the services are not authorized either.
+		return nil, nil
+	}
+
+	kinds := []struct {
+		kind Kind
+		n    int
+	}{
+		{kind: KindBucket, n: len(pkg.buckets())},
+		{kind: KindCheck, n: len(pkg.checks())},
+		{kind: KindDashboard, n: len(pkg.dashboards())},
+		{kind: KindLabel, n: len(pkg.labels())},
+		{kind: KindNotificationEndpoint, n: len(pkg.notificationEndpoints())},
+		{kind: KindNotificationRule, n: len(pkg.notificationRules())},
+		{kind: KindScraperTarget, n: len(pkg.scraperTargets())},
+		{kind: KindTask, n: len(pkg.tasks())},
+		{kind: KindTelegraf, n: len(pkg.telegrafs())},
+		{kind: KindVariable, n: len(pkg.variables())},
+	}
+
+	var missing []influxdb.Permission
+	for _, k := range kinds {
+		if k.n == 0 {
+			continue
+		}
+		p, err := influxdb.NewPermission(influxdb.WriteAction, k.kind.ResourceType(), orgID)
+		if err != nil {
+			return nil, err
+		}
+		if !a.Allowed(*p) {
+			missing = append(missing, *p)
+		}
+	}
+	return missing, nil
+}
+
+func missingPermissionsErr(perms []influxdb.Permission) error {
+	names := make([]string, 0, len(perms))
+	for _, p := range perms {
+		names = append(names, p.String())
+	}
+	return &influxdb.Error{
+		Code: influxdb.EUnauthorized,
+		Msg:  "missing permissions to apply pkg: " + strings.Join(names, ", "),
+	}
+}
@@ -0,0 +1,113 @@
+package pkger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+// maxRemotePkgSize is the largest pkg that is fetched from a remote URL.
+const maxRemotePkgSize = 32 << 20
+
+// FromURL fetches the pkg hosted at the URL and parses it. The encoding of the
+// pkg is determined by the content type of the response, falling back to the
+// extension of the URL path when the content type is neither YAML nor JSON. A
+// nil client uses the http.DefaultClient.
+func FromURL(ctx context.Context, client *http.Client, pkgURL string, opts ...ValidateOptFn) (*Pkg, error) {
+	u, err := url.Parse(pkgURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("pkg URL %q must be an http or https URL", pkgURL),
+		}
+	}
+
+	// the credentials of the URL are never included in an error
+	display := *u
+	display.User = nil
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/x-yaml, application/json;q=0.9, */*;q=0.8")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  fmt.Sprintf("failed to fetch pkg from %s", display.String()),
+			Err:  err,
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  fmt.Sprintf("failed to fetch pkg from %s: unexpected status %s", display.String(), resp.Status),
+		}
+	}
+
+	encoding := remoteEncoding(resp.Header.Get("Content-Type"), u.Path)
+	if encoding == EncodingUnknown {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg: fmt.Sprintf(
+				"unable to determine the encoding of the pkg at %s from content type %q, expected yaml or json",
+				display.String(), resp.Header.Get("Content-Type"),
+			),
+		}
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxRemotePkgSize+1))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  fmt.Sprintf("failed to read pkg from %s", display.String()),
+			Err:  err,
+		}
+	}
+	if len(b) > maxRemotePkgSize {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("pkg at %s exceeds the maximum size of %d bytes", display.String(), maxRemotePkgSize),
+		}
+	}
+
+	return Parse(encoding, FromReader(bytes.NewReader(b)), opts...)
+}
+
+// remoteEncoding determines the encoding of a pkg from the content type it is
+// served with, or the extension of its path when the content type is generic,
+// i.e. text/plain or application/octet-stream.
+func remoteEncoding(contentType, urlPath string) Encoding {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return EncodingJSON
+	case strings.HasSuffix(mediaType, "/yaml") || strings.HasSuffix(mediaType, "/x-yaml"):
+		return EncodingYAML
+	}
+
+	switch strings.ToLower(path.Ext(urlPath)) {
+	case ".json":
+		return EncodingJSON
+	case ".yml", ".yaml":
+		return EncodingYAML
+	default:
+		return EncodingUnknown
+	}
+}
@@ -0,0 +1,80 @@
+package pkger
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromURL(t *testing.T) {
+	serveFile := func(t *testing.T, contentType, path string) http.HandlerFunc {
+		b, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		return func(w http.ResponseWriter, r *http.Request) {
+			if contentType != "" {
+				w.Header().Set("Content-Type", contentType)
+			}
+			w.Write(b)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/bucket", serveFile(t, "application/x-yaml", "testdata/bucket.yml"))
+	mux.Handle("/bucket.yml", serveFile(t, "text/plain; charset=utf-8", "testdata/bucket.yml"))
+	mux.Handle("/bucket.json", serveFile(t, "application/json; charset=utf-8", "testdata/bucket.json"))
+	mux.Handle("/json-by-extension.json", serveFile(t, "application/octet-stream", "testdata/bucket.json"))
+	mux.Handle("/unknown", serveFile(t, "text/plain", "testdata/bucket.yml"))
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	t.Run("parses the pkg by its encoding", func(t *testing.T) {
+		for _, path := range []string{"/bucket", "/bucket.yml", "/bucket.json", "/json-by-extension.json"} {
+			t.Run(path, func(t *testing.T) {
+				pkg, err := FromURL(context.Background(), svr.Client(), svr.URL+path)
+				require.NoError(t, err)
+
+				buckets := pkg.Summary().Buckets
+				require.Len(t, buckets, 1)
+				assert.Equal(t, "rucket_11", buckets[0].Name)
+			})
+		}
+	})
+
+	t.Run("fails for an unknown encoding", func(t *testing.T) {
+		_, err := FromURL(context.Background(), svr.Client(), svr.URL+"/unknown")
+		require.Error(t, err)
+		assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+	})
+
+	t.Run("fails for a pkg that is not found", func(t *testing.T) {
+		_, err := FromURL(context.Background(), svr.Client(), svr.URL+"/missing.yml")
+		require.Error(t, err)
+		assert.Equal(t, influxdb.EUnavailable, influxdb.ErrorCode(err))
+		assert.Contains(t, err.Error(), "404")
+	})
+
+	t.Run("fails for a URL that is not http", func(t *testing.T) {
+		_, err := FromURL(context.Background(), svr.Client(), "file:///etc/passwd")
+		require.Error(t, err)
+		assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+	})
+
+	t.Run("respects the context deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := FromURL(ctx, svr.Client(), svr.URL+"/slow")
+		require.Error(t, err)
+		assert.Equal(t, influxdb.EUnavailable, influxdb.ErrorCode(err))
+	})
+}
@@ -0,0 +1,580 @@
+package pkger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification/endpoint"
+)
+
+// removal is a resource of the org matched to a resource of the pkg, that is
+// to be deleted by Remove.
+type removal struct {
+	kind     Kind
+	id       influxdb.ID
+	name     string
+	deleteFn func(ctx context.Context, id influxdb.ID) error
+	// summarize adds the resource to the summary once it has been deleted.
+	summarize func(sum *Summary)
+}
+
+// Remove deletes the resources of the org described by the pkg, it is the
+// inverse of Apply. The resources of the pkg are matched to the resources of
+// the org by kind and name, resources outside of the pkg are never deleted. A
+// resource that can not be matched with confidence, i.e. a name shared by
+// multiple dashboards, is skipped and reported in the Skipped resources of the
+// Summary. A label is only removed when it is the label the pkg applies, i.e.
+// it has the properties of the label of the pkg, and it is not mapped to
+// resources outside of the pkg. Notification rules and checks are removed
+// ahead of the endpoints and buckets they depend on. The
+// label mappings of the matched resources are removed before any resource is
+// deleted. The returned Summary describes the resources that were removed.
+func (s *Service) Remove(ctx context.Context, orgID influxdb.ID, pkg *Pkg) (Summary, error) {
+	pkg.mu.Lock()
+	defer pkg.mu.Unlock()
+
+	if !pkg.isParsed {
+		if err := pkg.Validate(); err != nil {
+			return Summary{}, err
+		}
+	}
+
+	var sum Summary
+	skip := func(k Kind, name, reason string) {
+		sum.Skipped = append(sum.Skipped, SummarySkippedResource{
+			Kind:   k,
+			Name:   name,
+			Reason: reason,
+		})
+	}
+
+	matchFns := []func(context.Context, influxdb.ID, *Pkg, skipFn) ([]removal, error){
+		s.removalDashboards,
+		s.removalTelegrafs,
+		s.removalScraperTargets,
+		s.removalNotificationRules,
+		s.removalChecks,
+		s.removalNotificationEndpoints,
+		s.removalBuckets,
+		s.removalVariables,
+	}
+
+	var removals []removal
+	for _, matchFn := range matchFns {
+		rs, err := matchFn(ctx, orgID, pkg, skip)
+		if err != nil {
+			return Summary{}, err
+		}
+		removals = append(removals, rs...)
+	}
+
+	labelRemovals, err := s.removalLabels(ctx, orgID, pkg, removals, skip)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	var failures []string
+	for _, r := range removals {
+		if err := s.removeLabelMappings(ctx, r, &sum); err != nil {
+			failures = append(failures, fmt.Sprintf("resource_type=%q name=%q err=%q", r.kind, r.name, err.Error()))
+		}
+	}
+	if len(failures) > 0 {
+		return sum, errors.New("unable to remove label mappings:\n\t" + strings.Join(failures, "\n\t"))
+	}
+
+	for _, r := range append(removals, labelRemovals...) {
+		if err := r.deleteFn(ctx, r.id); err != nil {
+			failures = append(failures, fmt.Sprintf("resource_type=%q name=%q err=%q", r.kind, r.name, err.Error()))
+			continue
+		}
+		r.summarize(&sum)
+	}
+	if len(failures) > 0 {
+		return sum, errors.New("unable to remove resources:\n\t" + strings.Join(failures, "\n\t"))
+	}
+
+	return sum, nil
+}
+
+type skipFn func(k Kind, name, reason string)
+
+// matchesOne reports if exactly one resource of the org has the name of the
+// pkg resource. The pkg resource is skipped when there are many.
+func matchesOne(k Kind, name string, n int, skip skipFn) bool {
+	if n > 1 {
+		skip(k, name, fmt.Sprintf("name matches %d existing resources", n))
+	}
+	return n == 1
+}
+
+func (s *Service) removeLabelMappings(ctx context.Context, r removal, sum *Summary) error {
+	resType := r.kind.ResourceType()
+	labels, err := s.labelSVC.FindResourceLabels(ctx, influxdb.LabelMappingFilter{
+		ResourceID:   r.id,
+		ResourceType: resType,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, l := range labels {
+		err := s.labelSVC.DeleteLabelMapping(ctx, &influxdb.LabelMapping{
+			LabelID:      l.ID,
+			ResourceID:   r.id,
+			ResourceType: resType,
+		})
+		if err != nil {
+			return err
+		}
+		sum.LabelMappings = append(sum.LabelMappings, SummaryLabelMapping{
+			ResourceID:   SafeID(r.id),
+			ResourceName: r.name,
+			ResourceType: resType,
+			LabelID:      SafeID(l.ID),
+			LabelName:    l.Name,
+		})
+	}
+	return nil
+}
+
+func (s *Service) removalBuckets(ctx context.Context, orgID influxdb.ID, pkg *Pkg, skip skipFn) ([]removal, error) {
+	var removals []removal
+	for _, b := range pkg.buckets() {
+		existing, err := s.bucketSVC.FindBucketByName(ctx, orgID, b.Name())
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if existing.Type == influxdb.BucketTypeSystem {
+			skip(KindBucket, b.Name(), "system buckets are never removed")
+			continue
+		}
+
+		removals = append(removals, removal{
+			kind:     KindBucket,
+			id:       existing.ID,
+			name:     existing.Name,
+			deleteFn: s.bucketSVC.DeleteBucket,
+			summarize: func(sum *Summary) {
+				sum.Buckets = append(sum.Buckets, SummaryBucket{
+					ID:              SafeID(existing.ID),
+					OrgID:           SafeID(existing.OrgID),
+					Name:            existing.Name,
+					Description:     existing.Description,
+					RetentionPeriod: existing.RetentionPeriod,
+				})
+			},
+		})
+	}
+	return removals, nil
+}
+
+func (s *Service) removalDashboards(ctx context.Context, orgID influxdb.ID, pkg *Pkg, skip skipFn) ([]removal, error) {
+	dashs := pkg.dashboards()
+	if len(dashs) == 0 {
+		return nil, nil
+	}
+
+	existingDashs, err := s.findAllDashboards(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	mExisting := make(map[string][]*influxdb.Dashboard)
+	for _, d := range existingDashs {
+		mExisting[d.Name] = append(mExisting[d.Name], d)
+	}
+
+	var removals []removal
+	for _, d := range dashs {
+		matches := mExisting[d.Name()]
+		if !matchesOne(KindDashboard, d.Name(), len(matches), skip) {
+			continue
+		}
+
+		existing := matches[0]
+		removals = append(removals, removal{
+			kind:     KindDashboard,
+			id:       existing.ID,
+			name:     existing.Name,
+			deleteFn: s.dashSVC.DeleteDashboard,
+			summarize: func(sum *Summary) {
+				sum.Dashboards = append(sum.Dashboards, SummaryDashboard{
+					ID:          SafeID(existing.ID),
+					OrgID:       SafeID(existing.OrganizationID),
+					Name:        existing.Name,
+					Description: existing.Description,
+				})
+			},
+		})
+	}
+	return removals, nil
+}
+
+func (s *Service) removalChecks(ctx context.Context, orgID influxdb.ID, pkg *Pkg, skip skipFn) ([]removal, error) {
+	checks := pkg.checks()
+	if len(checks) == 0 {
+		return nil, nil
+	}
+
+	existingChecks, _, err := s.checkSVC.FindChecks(ctx, influxdb.CheckFilter{
+		OrgID: &orgID,
+	}) // grab em all
+	if err != nil {
+		return nil, err
+	}
+
+	mExisting := make(map[string][]influxdb.Check)
+	for _, c := range existingChecks {
+		mExisting[c.GetName()] = append(mExisting[c.GetName()], c)
+	}
+
+	var removals []removal
+	for _, c := range checks {
+		matches := mExisting[c.platformName()]
+		if !matchesOne(KindCheck, c.Name(), len(matches), skip) {
+			continue
+		}
+
+		existing := matches[0]
+		removals = append(removals, removal{
+			kind:     checkResourceKind(existing),
+			id:       existing.GetID(),
+			name:     existing.GetName(),
+			deleteFn: s.checkSVC.DeleteCheck,
+			summarize: func(sum *Summary) {
+				sum.Checks = append(sum.Checks, SummaryCheck{
+					Check: existing,
+				})
+			},
+		})
+	}
+	return removals, nil
+}
+
+func (s *Service) removalNotificationRules(ctx context.Context, orgID influxdb.ID, pkg *Pkg, skip skipFn) ([]removal, error) {
+	rules := pkg.notificationRules()
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	existingRules, _, err := s.ruleSVC.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{
+		OrgID: &orgID,
+	}) // grab em all
+	if err != nil {
+		return nil, err
+	}
+
+	mExisting := make(map[string][]influxdb.NotificationRule)
+	for _, r := range existingRules {
+		mExisting[r.GetName()] = append(mExisting[r.GetName()], r)
+	}
+
+	var removals []removal
+	for _, r := range rules {
+		matches := mExisting[r.platformName()]
+		if !matchesOne(KindNotificationRule, r.Name(), len(matches), skip) {
+			continue
+		}
+
+		existing := matches[0]
+		removals = append(removals, removal{
+			kind:     KindNotificationRule,
+			id:       existing.GetID(),
+			name:     existing.GetName(),
+			deleteFn: s.ruleSVC.DeleteNotificationRule,
+			summarize: func(sum *Summary) {
+				sum.NotificationRules = append(sum.NotificationRules, SummaryNotificationRule{
+					ID:          SafeID(existing.GetID()),
+					OrgID:       SafeID(existing.GetOrgID()),
+					Name:        existing.GetName(),
+					Description: existing.GetDescription(),
+					EndpointID:  SafeID(existing.GetEndpointID()),
+				})
+			},
+		})
+	}
+	return removals, nil
+}
+
+func (s *Service) removalNotificationEndpoints(ctx context.Context, orgID influxdb.ID, pkg *Pkg, skip skipFn) ([]removal, error) {
+	endpoints := pkg.notificationEndpoints()
+	if len(endpoints) == 0 {
+		return nil, nil
+	}
+
+	existingEndpoints, _, err := s.endpointSVC.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		OrgID: &orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mExisting := make(map[string][]influxdb.NotificationEndpoint)
+	for _, e := range existingEndpoints {
+		mExisting[e.GetName()] = append(mExisting[e.GetName()], e)
+	}
+
+	var removals []removal
+	for _, e := range endpoints {
+		matches := mExisting[e.Name()]
+		if !matchesOne(KindNotificationEndpoint, e.Name(), len(matches), skip) {
+			continue
+		}
+
+		existing := matches[0]
+		if existing.Type() != e.kind.endpointType() {
+			skip(KindNotificationEndpoint, e.Name(), fmt.Sprintf("existing endpoint is of type %q", existing.Type()))
+			continue
+		}
+
+		removals = append(removals, removal{
+			kind: endpointKind(existing),
+			id:   existing.GetID(),
+			name: existing.GetName(),
+			deleteFn: func(ctx context.Context, id influxdb.ID) error {
+				_, _, err := s.endpointSVC.DeleteNotificationEndpoint(ctx, id)
+				return err
+			},
+			summarize: func(sum *Summary) {
+				sum.NotificationEndpoints = append(sum.NotificationEndpoints, SummaryNotificationEndpoint{
+					NotificationEndpoint: existing,
+				})
+			},
+		})
+	}
+	return removals, nil
+}
+
+func (k notificationKind) endpointType() string {
+	switch k {
+	case notificationKindHTTP:
+		return endpoint.HTTPType
+	case notificationKindPagerDuty:
+		return endpoint.PagerDutyType
+	case notificationKindSlack:
+		return endpoint.SlackType
+	default:
+		return ""
+	}
+}
+
+func (s *Service) removalTelegrafs(ctx context.Context, orgID influxdb.ID, pkg *Pkg, skip skipFn) ([]removal, error) {
+	teles := pkg.telegrafs()
+	if len(teles) == 0 {
+		return nil, nil
+	}
+
+	existingTeles, err := s.findAllTelegrafs(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	mExisting := make(map[string][]*influxdb.TelegrafConfig)
+	for _, t := range existingTeles {
+		mExisting[t.Name] = append(mExisting[t.Name], t)
+	}
+
+	var removals []removal
+	for _, t := range teles {
+		matches := mExisting[t.Name()]
+		if !matchesOne(KindTelegraf, t.Name(), len(matches), skip) {
+			continue
+		}
+
+		existing := matches[0]
+		removals = append(removals, removal{
+			kind:     KindTelegraf,
+			id:       existing.ID,
+			name:     existing.Name,
+			deleteFn: s.teleSVC.DeleteTelegrafConfig,
+			summarize: func(sum *Summary) {
+				sum.TelegrafConfigs = append(sum.TelegrafConfigs, SummaryTelegraf{
+					TelegrafConfig: *existing,
+				})
+			},
+		})
+	}
+	return removals, nil
+}
+
+func (s *Service) removalScraperTargets(ctx context.Context, orgID influxdb.ID, pkg *Pkg, skip skipFn) ([]removal, error) {
+	targets := pkg.scraperTargets()
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	existingTargets, err := s.scraperSVC.ListTargets(ctx, influxdb.ScraperTargetFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	mExisting := make(map[string][]influxdb.ScraperTarget)
+	for _, t := range existingTargets {
+		mExisting[t.Name] = append(mExisting[t.Name], t)
+	}
+
+	var removals []removal
+	for _, t := range targets {
+		matches := mExisting[t.Name()]
+		if !matchesOne(KindScraperTarget, t.Name(), len(matches), skip) {
+			continue
+		}
+
+		existing, bucketName := matches[0], t.bucket
+		removals = append(removals, removal{
+			kind:     KindScraperTarget,
+			id:       existing.ID,
+			name:     existing.Name,
+			deleteFn: s.scraperSVC.RemoveTarget,
+			summarize: func(sum *Summary) {
+				sum.ScraperTargets = append(sum.ScraperTargets, SummaryScraperTarget{
+					ID:       SafeID(existing.ID),
+					OrgID:    SafeID(existing.OrgID),
+					Name:     existing.Name,
+					Type:     existing.Type,
+					URL:      existing.URL,
+					BucketID: SafeID(existing.BucketID),
+					Bucket:   bucketName,
+				})
+			},
+		})
+	}
+	return removals, nil
+}
+
+func (s *Service) removalVariables(ctx context.Context, orgID influxdb.ID, pkg *Pkg, skip skipFn) ([]removal, error) {
+	vars := pkg.variables()
+	if len(vars) == 0 {
+		return nil, nil
+	}
+
+	existingVars, err := s.findAllVariables(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	mExisting := make(map[string][]*influxdb.Variable)
+	for _, v := range existingVars {
+		mExisting[v.Name] = append(mExisting[v.Name], v)
+	}
+
+	var removals []removal
+	for _, v := range vars {
+		matches := mExisting[v.Name()]
+		if !matchesOne(KindVariable, v.Name(), len(matches), skip) {
+			continue
+		}
+
+		existing := matches[0]
+		removals = append(removals, removal{
+			kind:     KindVariable,
+			id:       existing.ID,
+			name:     existing.Name,
+			deleteFn: s.varSVC.DeleteVariable,
+			summarize: func(sum *Summary) {
+				sum.Variables = append(sum.Variables, SummaryVariable{
+					ID:          SafeID(existing.ID),
+					OrgID:       SafeID(existing.OrganizationID),
+					Name:        existing.Name,
+					Description: existing.Description,
+					Arguments:   existing.Arguments,
+				})
+			},
+		})
+	}
+	return removals, nil
+}
+
+// removalLabels matches the labels of the pkg. A label whose properties differ
+// from the label of the pkg is not the label the pkg applies, i.e. it existed
+// before the pkg or has been changed since, and is skipped. Deleting a label
+// removes its mappings to every resource, so a label mapped to a resource of
+// the org that is not being removed is skipped as well.
+func (s *Service) removalLabels(ctx context.Context, orgID influxdb.ID, pkg *Pkg, removals []removal, skip skipFn) ([]removal, error) {
+	var candidates []*influxdb.Label
+	for _, l := range pkg.labels() {
+		existing, err := s.labelSVC.FindLabels(ctx, influxdb.LabelFilter{
+			Name:  l.platformName(),
+			OrgID: &orgID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !matchesOne(KindLabel, l.Name(), len(existing), skip) {
+			continue
+		}
+		if newDiffLabelProperties(l, *existing[0]).Changed() {
+			skip(KindLabel, l.Name(), "label differs from the label of the pkg")
+			continue
+		}
+		candidates = append(candidates, existing[0])
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	type key struct {
+		kind Kind
+		id   influxdb.ID
+	}
+	removed := make(map[key]bool, len(removals))
+	for _, r := range removals {
+		removed[key{kind: r.kind, id: r.id}] = true
+	}
+
+	orgResources, err := s.cloneOrgResources(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	mappedOutside := make(map[influxdb.ID]bool)
+	for _, r := range orgResources {
+		if r.Kind.is(KindLabel) || removed[key{kind: r.Kind, id: r.ID}] {
+			continue
+		}
+		labels, err := s.labelSVC.FindResourceLabels(ctx, influxdb.LabelMappingFilter{
+			ResourceID:   r.ID,
+			ResourceType: r.Kind.ResourceType(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range labels {
+			mappedOutside[l.ID] = true
+		}
+	}
+
+	var labelRemovals []removal
+	for _, existing := range candidates {
+		if mappedOutside[existing.ID] {
+			skip(KindLabel, existing.Name, "label is mapped to resources outside of the pkg")
+			continue
+		}
+
+		existing := existing
+		labelRemovals = append(labelRemovals, removal{
+			kind:     KindLabel,
+			id:       existing.ID,
+			name:     existing.Name,
+			deleteFn: s.labelSVC.DeleteLabel,
+			summarize: func(sum *Summary) {
+				sumLabel := SummaryLabel{
+					ID:    SafeID(existing.ID),
+					OrgID: SafeID(existing.OrgID),
+					Name:  existing.Name,
+				}
+				sumLabel.Properties.Color = existing.Properties["color"]
+				sumLabel.Properties.Description = existing.Properties["description"]
+				sum.Labels = append(sum.Labels, sumLabel)
+			},
+		})
+	}
+	return labelRemovals, nil
+}
@@ -0,0 +1,454 @@
+package pkger
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/influxdata/influxdb"
+)
+
+// jsonSchemaDraft is the JSON Schema draft the package schema conforms to.
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Schema returns a JSON Schema describing the package format. The schema is
+// generated from the same kinds and resource fields the parser uses, so that
+// editor tooling and CI can validate a package without the platform.
+//
+// The schema describes the shape of a package: the kinds available, the fields
+// each kind requires, and the properties of the charts a dashboard is made of.
+// Rules that span multiple fields or resources, i.e. the secrets an http
+// endpoint requires for its auth type or the colors a gauge requires, are left
+// to Validate. A package that does not satisfy the schema fails Validate.
+func Schema() []byte {
+	b, err := json.MarshalIndent(pkgSchema(), "", "  ")
+	if err != nil {
+		// the schema is built entirely from static values, failing to
+		// marshal it is a programming error.
+		panic("unable to marshal pkg schema: " + err.Error())
+	}
+	return b
+}
+
+type jsonSchema map[string]interface{}
+
+func pkgSchema() jsonSchema {
+	s := schemaObject([]string{"apiVersion", fieldKind, "meta", "spec"}, jsonSchema{
+		"apiVersion": jsonSchema{"type": "string", "enum": []string{APIVersion}},
+		fieldKind:    schemaKind(KindPackage),
+		"meta": schemaObject([]string{"pkgName", "pkgVersion"}, jsonSchema{
+			"pkgName":        schemaString(1),
+			"pkgVersion":     schemaScalar(1),
+			fieldDescription: schemaString(0),
+			"exportedAt":     jsonSchema{"type": "string", "format": "date-time"},
+			"sourceOrgID":    schemaString(1),
+		}),
+		"spec": schemaObject([]string{"resources"}, jsonSchema{
+			"resources": schemaArray(jsonSchema{
+				"oneOf": []jsonSchema{
+					schemaBucket(),
+					schemaCheck(KindCheckDeadman, []string{fieldCheckLevel, fieldCheckStaleTime, fieldCheckTimeSince}, jsonSchema{
+						fieldCheckLevel:      schemaEnumInsensitive(sortedKeys(validCheckLevels)...),
+						fieldCheckReportZero: jsonSchema{"type": "boolean"},
+						fieldCheckStaleTime:  schemaString(1),
+						fieldCheckTimeSince:  schemaString(1),
+					}),
+					schemaCheck(KindCheckThreshold, []string{fieldCheckField, fieldCheckThresholds}, jsonSchema{
+						fieldCheckField: schemaString(1),
+						fieldCheckThresholds: jsonSchema{
+							"type":     "array",
+							"minItems": 1,
+							"items": schemaObject([]string{fieldType, fieldCheckLevel}, jsonSchema{
+								fieldType:           schemaEnumInsensitive(thresholdTypeGreater, thresholdTypeLesser, thresholdTypeRange),
+								fieldCheckLevel:     schemaEnumInsensitive(sortedKeys(validCheckLevels)...),
+								fieldCheckAllValues: jsonSchema{"type": "boolean"},
+								fieldValue:          jsonSchema{"type": "number"},
+								fieldCheckMin:       jsonSchema{"type": "number"},
+								fieldCheckMax:       jsonSchema{"type": "number"},
+								fieldCheckWithin:    jsonSchema{"type": "boolean"},
+							}),
+						},
+					}),
+					schemaDashboard(),
+					schemaLabel(),
+					schemaNotificationEndpoint(KindNotificationEndpoint, []string{fieldNotificationEndpointURL}),
+					schemaNotificationEndpoint(KindNotificationEndpointHTTP, []string{fieldNotificationEndpointURL, fieldNotificationEndpointHTTPMethod, fieldType}),
+					schemaNotificationEndpoint(KindNotificationEndpointPagerDuty, []string{fieldNotificationEndpointURL, fieldNotificationEndpointRoutingKey}),
+					schemaNotificationEndpoint(KindNotificationEndpointSlack, []string{fieldNotificationEndpointURL}),
+					schemaNotificationRule(),
+					schemaScraperTarget(),
+					schemaTask(),
+					schemaTelegraf(),
+					schemaVariable(),
+				},
+			}),
+		}),
+	})
+	s["$schema"] = jsonSchemaDraft
+	s["title"] = "Package"
+	return s
+}
+
+func schemaBucket() jsonSchema {
+	return schemaResource(KindBucket, 2, nil, jsonSchema{
+		fieldDisplayName: schemaString(2),
+		fieldBucketRetentionRules: schemaArray(schemaObject([]string{fieldType, fieldRetentionRulesEverySeconds}, jsonSchema{
+			fieldType:                       jsonSchema{"type": "string", "enum": []string{retentionRuleTypeExpire}},
+			fieldRetentionRulesEverySeconds: jsonSchema{"type": "integer", "minimum": 3600},
+		})),
+	})
+}
+
+// schemaCheck is the schema for a check of the provided kind. All checks share
+// the query of a bucket and its schedule, the properties provided describe what
+// is specific to the kind.
+func schemaCheck(kind Kind, required []string, props jsonSchema) jsonSchema {
+	properties := jsonSchema{
+		fieldDisplayName:                schemaString(1),
+		fieldStatus:                     schemaEnumInsensitive(influxdb.TaskStatusActive, influxdb.TaskStatusInactive),
+		fieldCheckBucket:                schemaString(1),
+		fieldQuery:                      schemaString(1),
+		fieldCheckEvery:                 schemaString(1),
+		fieldCheckOffset:                schemaString(0),
+		fieldCheckStatusMessageTemplate: schemaString(0),
+		fieldCheckTags: schemaArray(schemaObject([]string{fieldKey, fieldValue}, jsonSchema{
+			fieldKey:   schemaString(1),
+			fieldValue: schemaString(1),
+		})),
+	}
+	for k, v := range props {
+		properties[k] = v
+	}
+	required = append([]string{fieldCheckBucket, fieldQuery, fieldCheckEvery}, required...)
+	return schemaResource(kind, 1, required, properties)
+}
+
+func schemaDashboard() jsonSchema {
+	return schemaResource(KindDashboard, 2, nil, jsonSchema{
+		fieldRefreshInterval: schemaString(0),
+		fieldDashCharts: schemaArray(jsonSchema{
+			"oneOf": []jsonSchema{
+				schemaChart([]chartKind{chartKindMarkdown}, nil),
+				schemaChart(
+					[]chartKind{
+						chartKindGauge, chartKindHeatMap, chartKindHistogram, chartKindScatter,
+						chartKindSingleStat, chartKindSingleStatPlusLine, chartKindXY,
+					},
+					[]string{fieldChartHeight, fieldChartWidth, fieldChartQueries},
+				),
+			},
+		}),
+	})
+}
+
+func schemaChart(kinds []chartKind, required []string) jsonSchema {
+	names := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		names = append(names, string(k))
+	}
+
+	return schemaObject(append([]string{fieldKind}, required...), jsonSchema{
+		fieldKind:               schemaEnumInsensitive(names...),
+		fieldName:               schemaString(0),
+		fieldPrefix:             schemaString(0),
+		fieldSuffix:             schemaString(0),
+		fieldChartNote:          schemaString(0),
+		fieldChartNoteOnEmpty:   jsonSchema{"type": "boolean"},
+		fieldChartShade:         jsonSchema{"type": "boolean"},
+		fieldChartXCol:          schemaString(0),
+		fieldChartYCol:          schemaString(0),
+		fieldChartXPos:          jsonSchema{"type": "integer", "minimum": 0},
+		fieldChartYPos:          jsonSchema{"type": "integer", "minimum": 0},
+		fieldChartHeight:        jsonSchema{"type": "integer", "minimum": 1},
+		fieldChartWidth:         jsonSchema{"type": "integer", "minimum": 1},
+		fieldChartGeom:          jsonSchema{"type": "string", "enum": sortedKeys(geometryTypes)},
+		fieldChartBinSize:       jsonSchema{"type": "integer"},
+		fieldChartBinCount:      jsonSchema{"type": "integer"},
+		fieldChartPosition:      schemaEnumInsensitive("overlaid", "stacked"),
+		fieldChartDecimalPlaces: jsonSchema{"type": "integer"},
+		fieldRefreshInterval:    schemaString(0),
+		// an empty legend is left unset by the parser
+		fieldChartLegend: schemaNullable(schemaObject(nil, jsonSchema{
+			fieldType:              schemaString(0),
+			fieldLegendOrientation: schemaString(0),
+		})),
+		fieldChartQueries: jsonSchema{
+			"type":     "array",
+			"minItems": 1,
+			"items": schemaObject([]string{fieldQuery}, jsonSchema{
+				fieldQuery: schemaString(1),
+			}),
+		},
+		fieldChartColors: schemaArray(schemaObject([]string{fieldColorHex}, jsonSchema{
+			fieldName:     schemaString(0),
+			fieldType:     jsonSchema{"type": "string", "enum": []string{colorTypeMin, colorTypeMax, colorTypeScale, colorTypeText, colorTypeThreshold}},
+			fieldColorHex: schemaString(1),
+			fieldValue:    jsonSchema{"type": "number"},
+		})),
+		fieldChartAxes: schemaArray(schemaObject([]string{fieldName}, jsonSchema{
+			fieldName:      schemaString(1),
+			fieldAxisBase:  schemaScalar(0),
+			fieldAxisLabel: schemaString(0),
+			fieldAxisScale: schemaString(0),
+			fieldPrefix:    schemaString(0),
+			fieldSuffix:    schemaString(0),
+			fieldChartDomain: jsonSchema{
+				"type":  "array",
+				"items": jsonSchema{"type": "number"},
+			},
+		})),
+	})
+}
+
+func schemaLabel() jsonSchema {
+	return schemaResource(KindLabel, 2, nil, jsonSchema{
+		fieldDisplayName: schemaString(2),
+		fieldLabelColor:  schemaString(0),
+	})
+}
+
+func schemaNotificationEndpoint(kind Kind, required []string) jsonSchema {
+	return schemaResource(kind, 1, required, jsonSchema{
+		fieldDisplayName:                    schemaString(1),
+		fieldStatus:                         schemaEnumInsensitive(influxdb.TaskStatusActive, influxdb.TaskStatusInactive),
+		fieldNotificationEndpointURL:        schemaString(1),
+		fieldNotificationEndpointHTTPMethod: schemaEnumInsensitive(sortedKeys(validEndpointHTTPMethods)...),
+		fieldType: schemaEnumInsensitive(
+			notificationHTTPAuthTypeBasic,
+			notificationHTTPAuthTypeBearer,
+			notificationHTTPAuthTypeNone,
+		),
+		fieldNotificationEndpointPassword:   schemaReference(),
+		fieldNotificationEndpointRoutingKey: schemaReference(),
+		fieldNotificationEndpointToken:      schemaReference(),
+		fieldNotificationEndpointUsername:   schemaReference(),
+	})
+}
+
+func schemaNotificationRule() jsonSchema {
+	levels := schemaEnumInsensitive(sortedKeys(validCheckLevels)...)
+	operators := make([]string, 0, len(validTagRuleOperators))
+	for op := range validTagRuleOperators {
+		operators = append(operators, op)
+	}
+	sort.Strings(operators)
+
+	required := []string{fieldNotificationRuleEndpointName, fieldNotificationRuleEvery, fieldNotificationRuleStatusRules}
+	return schemaResource(KindNotificationRule, 1, required, jsonSchema{
+		fieldDisplayName:                     schemaString(1),
+		fieldStatus:                          schemaEnumInsensitive(influxdb.TaskStatusActive, influxdb.TaskStatusInactive),
+		fieldNotificationRuleChannel:         schemaString(0),
+		fieldNotificationRuleEndpointName:    schemaString(1),
+		fieldNotificationRuleEvery:           schemaString(1),
+		fieldNotificationRuleMessageTemplate: schemaString(0),
+		fieldNotificationRuleOffset:          schemaString(0),
+		fieldNotificationRuleStatusRules: jsonSchema{
+			"type":     "array",
+			"minItems": 1,
+			"items": schemaObject([]string{fieldNotificationRuleCurrentLevel}, jsonSchema{
+				fieldNotificationRuleCurrentLevel:  levels,
+				fieldNotificationRulePreviousLevel: levels,
+			}),
+		},
+		fieldNotificationRuleTagRules: schemaArray(schemaObject([]string{fieldKey, fieldValue}, jsonSchema{
+			fieldKey:                      schemaString(1),
+			fieldValue:                    schemaString(1),
+			fieldNotificationRuleOperator: schemaEnumInsensitive(operators...),
+		})),
+	})
+}
+
+func schemaScraperTarget() jsonSchema {
+	return schemaResource(KindScraperTarget, 1, []string{fieldScraperTargetURL, fieldScraperTargetBucket}, jsonSchema{
+		fieldType:                schemaEnumInsensitive(influxdb.PrometheusScraperType),
+		fieldScraperTargetURL:    schemaString(1),
+		fieldScraperTargetBucket: schemaString(1),
+	})
+}
+
+func schemaTask() jsonSchema {
+	s := schemaResource(KindTask, 1, []string{fieldQuery}, jsonSchema{
+		fieldStatus:     schemaEnumInsensitive(influxdb.TaskStatusActive, influxdb.TaskStatusInactive),
+		fieldQuery:      schemaString(1),
+		fieldTaskCron:   schemaString(1),
+		fieldTaskEvery:  schemaString(1),
+		fieldTaskOffset: schemaString(0),
+	})
+	s["oneOf"] = []jsonSchema{
+		{"required": []string{fieldTaskEvery}},
+		{"required": []string{fieldTaskCron}},
+	}
+	return s
+}
+
+func schemaTelegraf() jsonSchema {
+	return schemaResource(KindTelegraf, 0, []string{fieldTelegrafConfig}, jsonSchema{
+		fieldTelegrafConfig: schemaString(1),
+	})
+}
+
+func schemaVariable() jsonSchema {
+	props := jsonSchema{
+		fieldDisplayName: schemaString(1),
+		fieldType:        schemaString(1),
+		fieldQuery:       schemaString(0),
+		fieldLanguage:    schemaString(0),
+		fieldValues:      jsonSchema{},
+	}
+
+	s := schemaResource(KindVariable, 1, []string{fieldType}, props)
+	s["oneOf"] = []jsonSchema{
+		schemaObject([]string{fieldValues}, jsonSchema{
+			fieldType: schemaEnumInsensitive(fieldArgTypeConstant),
+			fieldValues: jsonSchema{
+				"type":     "array",
+				"minItems": 1,
+				"items":    jsonSchema{"type": "string"},
+			},
+		}),
+		schemaObject([]string{fieldValues}, jsonSchema{
+			fieldType: schemaEnumInsensitive(fieldArgTypeMap),
+			fieldValues: jsonSchema{
+				"type":                 "object",
+				"minProperties":        1,
+				"additionalProperties": jsonSchema{"type": "string"},
+			},
+		}),
+		schemaObject([]string{fieldQuery, fieldLanguage}, jsonSchema{
+			fieldType:     schemaEnumInsensitive(fieldArgTypeQuery),
+			fieldQuery:    jsonSchema{"type": "string", "pattern": `\S`},
+			fieldLanguage: schemaEnumInsensitive("flux", "influxql"),
+		}),
+	}
+	return s
+}
+
+// schemaResource is the schema for a resource of the provided kind. All resources
+// share a kind, name, description and label associations, the properties provided
+// describe what is specific to the kind.
+func schemaResource(kind Kind, minNameLen int, required []string, props jsonSchema) jsonSchema {
+	properties := jsonSchema{
+		fieldKind:        schemaKind(kind),
+		fieldName:        schemaString(minNameLen),
+		fieldDescription: schemaString(0),
+		fieldAssociations: schemaArray(schemaObject([]string{fieldKind, fieldName}, jsonSchema{
+			fieldKind: schemaKind(KindLabel),
+			fieldName: schemaString(2),
+		})),
+	}
+	for k, v := range props {
+		properties[k] = v
+	}
+
+	return schemaObject(append([]string{fieldKind, fieldName}, required...), properties)
+}
+
+// schemaReference is the schema for a field that may be provided as a value or
+// as a reference to a secret.
+func schemaReference() jsonSchema {
+	return jsonSchema{
+		"oneOf": []jsonSchema{
+			schemaString(0),
+			schemaObject([]string{fieldReferencesSecret}, jsonSchema{
+				fieldReferencesSecret: schemaObject([]string{fieldKey}, jsonSchema{
+					fieldKey: schemaString(1),
+				}),
+			}),
+		},
+	}
+}
+
+func schemaKind(k Kind) jsonSchema {
+	return schemaEnumInsensitive(string(k))
+}
+
+// schemaEnumInsensitive is the schema for a string matching one of the provided
+// values. As with the parser, surrounding whitespace and case are ignored.
+func schemaEnumInsensitive(vals ...string) jsonSchema {
+	patterns := make([]string, 0, len(vals))
+	for _, v := range vals {
+		patterns = append(patterns, caseInsensitivePattern(v))
+	}
+	return jsonSchema{
+		"type":    "string",
+		"pattern": `^\s*(` + strings.Join(patterns, "|") + `)\s*$`,
+	}
+}
+
+// schemaScalar is the schema for a field the parser reads as a string, which may
+// be provided as a number as well, i.e. pkgVersion: 1.
+func schemaScalar(minLen int) jsonSchema {
+	return jsonSchema{
+		"oneOf": []jsonSchema{
+			schemaString(minLen),
+			{"type": "number"},
+		},
+	}
+}
+
+func schemaObject(required []string, props jsonSchema) jsonSchema {
+	s := jsonSchema{
+		"type":       "object",
+		"properties": props,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// schemaNullable allows the value of the schema to be null as well. The type
+// is dropped so a JSON Schema validator accepts null, the nullable keyword does
+// the same for an OpenAPI validator.
+func schemaNullable(s jsonSchema) jsonSchema {
+	nullable := jsonSchema{"nullable": true}
+	for k, v := range s {
+		if k == "type" {
+			continue
+		}
+		nullable[k] = v
+	}
+	return nullable
+}
+
+func schemaArray(items jsonSchema) jsonSchema {
+	return jsonSchema{
+		"type":  "array",
+		"items": items,
+	}
+}
+
+func schemaString(minLen int) jsonSchema {
+	s := jsonSchema{"type": "string"}
+	if minLen > 0 {
+		s["minLength"] = minLen
+	}
+	return s
+}
+
+// caseInsensitivePattern returns a regex pattern matching the provided string
+// regardless of case. JSON Schema patterns do not support flags, so each letter
+// is provided as a character class of its upper and lower case forms.
+func caseInsensitivePattern(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		upper, lower := unicode.ToUpper(r), unicode.ToLower(r)
+		if upper == lower {
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			continue
+		}
+		sb.WriteString("[" + string(upper) + string(lower) + "]")
+	}
+	return sb.String()
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,251 @@
+package pkger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchema(t *testing.T) {
+	newSchema := func(t *testing.T) *openapi3.Schema {
+		t.Helper()
+
+		b := Schema()
+
+		var raw map[string]interface{}
+		require.NoError(t, json.Unmarshal(b, &raw))
+		assert.Equal(t, jsonSchemaDraft, raw["$schema"])
+
+		// the draft identifier is the only keyword not shared with the
+		// openapi schema object used to validate the schema.
+		delete(raw, "$schema")
+		b, err := json.Marshal(raw)
+		require.NoError(t, err)
+
+		var schema openapi3.Schema
+		require.NoError(t, json.Unmarshal(b, &schema))
+		require.NoError(t, schema.Validate(context.Background()))
+		return &schema
+	}
+
+	t.Run("validates known good packages", func(t *testing.T) {
+		schema := newSchema(t)
+
+		ymlFiles, err := filepath.Glob("testdata/*.yml")
+		require.NoError(t, err)
+		jsonFiles, err := filepath.Glob("testdata/*.json")
+		require.NoError(t, err)
+
+		for _, f := range append(ymlFiles, jsonFiles...) {
+			fn := func(t *testing.T) {
+				docs := readSchemaDocs(t, f)
+				require.NotEmpty(t, docs)
+
+				for _, pkg := range docs {
+					assert.NoError(t, schema.VisitJSON(pkg))
+				}
+			}
+			t.Run(f, fn)
+		}
+	})
+
+	t.Run("rejects invalid packages", func(t *testing.T) {
+		schema := newSchema(t)
+
+		tests := []struct {
+			name   string
+			pkgStr string
+		}{
+			{
+				name: "unsupported kind",
+				pkgStr: `{
+  "apiVersion": "0.1.0",
+  "kind": "Package",
+  "meta": {"pkgName": "pkg_name", "pkgVersion": "1"},
+  "spec": {"resources": [{"kind": "Rando", "name": "rando_1"}]}
+}`,
+			},
+			{
+				name: "missing package meta",
+				pkgStr: `{
+  "apiVersion": "0.1.0",
+  "kind": "Package",
+  "spec": {"resources": []}
+}`,
+			},
+			{
+				name: "bucket retention below minimum",
+				pkgStr: `{
+  "apiVersion": "0.1.0",
+  "kind": "Package",
+  "meta": {"pkgName": "pkg_name", "pkgVersion": "1"},
+  "spec": {
+    "resources": [
+      {
+        "kind": "Bucket",
+        "name": "rucket_1",
+        "retentionRules": [{"type": "expire", "everySeconds": 1}]
+      }
+    ]
+  }
+}`,
+			},
+			{
+				name: "chart missing queries",
+				pkgStr: `{
+  "apiVersion": "0.1.0",
+  "kind": "Package",
+  "meta": {"pkgName": "pkg_name", "pkgVersion": "1"},
+  "spec": {
+    "resources": [
+      {
+        "kind": "Dashboard",
+        "name": "dash_1",
+        "charts": [{"kind": "Single_Stat", "width": 6, "height": 3}]
+      }
+    ]
+  }
+}`,
+			},
+			{
+				name: "map variable without values",
+				pkgStr: `{
+  "apiVersion": "0.1.0",
+  "kind": "Package",
+  "meta": {"pkgName": "pkg_name", "pkgVersion": "1"},
+  "spec": {"resources": [{"kind": "Variable", "name": "var_1", "type": "map"}]}
+}`,
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				var pkg interface{}
+				require.NoError(t, json.Unmarshal([]byte(tt.pkgStr), &pkg))
+
+				assert.Error(t, schema.VisitJSON(pkg))
+
+				// the schema must never be more lenient than the parser
+				_, err := Parse(EncodingJSON, FromString(tt.pkgStr))
+				assert.Error(t, err)
+			}
+			t.Run(tt.name, fn)
+		}
+	})
+
+	t.Run("describes every kind the parser supports", func(t *testing.T) {
+		var raw map[string]interface{}
+		require.NoError(t, json.Unmarshal(Schema(), &raw))
+
+		resources := schemaKindPatterns(t, raw, "properties", "spec", "properties", "resources", "items", "oneOf")
+		for k := range kinds {
+			if k == KindPackage {
+				continue
+			}
+			assert.Equalf(t, 1, matchingPatterns(resources, string(k)), "kind %q", k)
+			assert.Equalf(t, 1, matchingPatterns(resources, strings.ToUpper(string(k))), "kind %q", k)
+		}
+
+		var dashboard map[string]interface{}
+		for i, s := range schemaPath(t, raw, "properties", "spec", "properties", "resources", "items", "oneOf").([]interface{}) {
+			if resources[i].MatchString(string(KindDashboard)) {
+				dashboard = s.(map[string]interface{})
+			}
+		}
+		require.NotNil(t, dashboard)
+
+		charts := schemaKindPatterns(t, dashboard, "properties", fieldDashCharts, "items", "oneOf")
+		chartKinds := []chartKind{
+			chartKindGauge, chartKindHeatMap, chartKindHistogram, chartKindMarkdown,
+			chartKindScatter, chartKindSingleStat, chartKindSingleStatPlusLine, chartKindXY,
+		}
+		for _, k := range chartKinds {
+			require.True(t, k.ok())
+			assert.Equalf(t, 1, matchingPatterns(charts, string(k)), "chart kind %q", k)
+		}
+	})
+}
+
+// readSchemaDocs reads the pkgs from a testdata file as the plain values a JSON
+// Schema validates. A yml file may contain many documents and a json file may
+// contain an array of pkgs.
+func readSchemaDocs(t *testing.T, file string) []interface{} {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+
+	var docs []interface{}
+	if filepath.Ext(file) == ".json" {
+		var v interface{}
+		require.NoError(t, json.Unmarshal(b, &v))
+		if arr, ok := v.([]interface{}); ok {
+			return arr
+		}
+		return append(docs, v)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		var v interface{}
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		// round trip through json to get the values json would provide
+		jsonB, err := json.Marshal(v)
+		require.NoError(t, err)
+		var doc interface{}
+		require.NoError(t, json.Unmarshal(jsonB, &doc))
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func schemaPath(t *testing.T, v interface{}, path ...string) interface{} {
+	t.Helper()
+
+	for _, p := range path {
+		m, ok := v.(map[string]interface{})
+		require.Truef(t, ok, "expected an object at %q", p)
+		v, ok = m[p]
+		require.Truef(t, ok, "missing %q", p)
+	}
+	return v
+}
+
+// schemaKindPatterns compiles the kind patterns of the schemas in the oneOf
+// found at the provided path.
+func schemaKindPatterns(t *testing.T, v interface{}, path ...string) []*regexp.Regexp {
+	t.Helper()
+
+	var patterns []*regexp.Regexp
+	for _, s := range schemaPath(t, v, path...).([]interface{}) {
+		pattern := schemaPath(t, s, "properties", fieldKind, "pattern").(string)
+		patterns = append(patterns, regexp.MustCompile(pattern))
+	}
+	return patterns
+}
+
+func matchingPatterns(patterns []*regexp.Regexp, s string) int {
+	var n int
+	for _, p := range patterns {
+		if p.MatchString(s) {
+			n++
+		}
+	}
+	return n
+}
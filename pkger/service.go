@@ -2,16 +2,21 @@ package pkger
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"math/rand"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
 	"github.com/influxdata/influxdb"
 	ierrors "github.com/influxdata/influxdb/kit/errors"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/opentracing/opentracing-go"
 	"go.uber.org/zap"
 )
 
@@ -21,21 +26,32 @@ const APIVersion = "0.1.0"
 // SVC is the packages service interface.
 type SVC interface {
 	CreatePkg(ctx context.Context, setters ...CreatePkgSetFn) (*Pkg, error)
-	DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg) (Summary, Diff, error)
-	Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg) (Summary, error)
+	DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) (Summary, Diff, error)
+	Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) (Summary, error)
+	ApplyToOrgs(ctx context.Context, userID influxdb.ID, pkg *Pkg, orgIDs []influxdb.ID, opts ...ApplyOptFn) (map[influxdb.ID]OrgApplyResult, error)
 }
 
 type serviceOpt struct {
 	logger      *zap.Logger
 	labelSVC    influxdb.LabelService
 	bucketSVC   influxdb.BucketService
+	checkSVC    influxdb.CheckService
 	dashSVC     influxdb.DashboardService
 	endpointSVC influxdb.NotificationEndpointService
+	ruleSVC     influxdb.NotificationRuleStore
+	scraperSVC  influxdb.ScraperTargetStoreService
 	secretSVC   influxdb.SecretService
+	taskSVC     influxdb.TaskService
 	teleSVC     influxdb.TelegrafConfigStore
 	varSVC      influxdb.VariableService
 
-	applyReqLimit int
+	usageReporter UsageReporter
+	orgOpLog      influxdb.OrganizationOperationLogRecorder
+	timeGen       influxdb.TimeGenerator
+
+	applyReqLimit        int
+	applyStreamBatchSize int
+	applyOrgsLimit       int
 }
 
 // ServiceSetterFn is a means of setting dependencies on the Service type.
@@ -48,6 +64,22 @@ func WithLogger(log *zap.Logger) ServiceSetterFn {
 	}
 }
 
+// WithTimeGenerator sets the time generator the export time of a pkg is
+// recorded with.
+func WithTimeGenerator(timeGen influxdb.TimeGenerator) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.timeGen = timeGen
+	}
+}
+
+// WithOrganizationOperationLog sets the operation log the pkgs applied to many
+// orgs at once are recorded in, one entry per org.
+func WithOrganizationOperationLog(opLog influxdb.OrganizationOperationLogRecorder) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.orgOpLog = opLog
+	}
+}
+
 // WithBucketSVC sets the bucket service.
 func WithBucketSVC(bktSVC influxdb.BucketService) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -55,6 +87,13 @@ func WithBucketSVC(bktSVC influxdb.BucketService) ServiceSetterFn {
 	}
 }
 
+// WithCheckSVC sets the check service.
+func WithCheckSVC(checkSVC influxdb.CheckService) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.checkSVC = checkSVC
+	}
+}
+
 // WithDashboardSVC sets the dashboard service.
 func WithDashboardSVC(dashSVC influxdb.DashboardService) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -69,6 +108,13 @@ func WithNoticationEndpointSVC(endpointSVC influxdb.NotificationEndpointService)
 	}
 }
 
+// WithNotificationRuleSVC sets the notification rule service.
+func WithNotificationRuleSVC(ruleSVC influxdb.NotificationRuleStore) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.ruleSVC = ruleSVC
+	}
+}
+
 // WithLabelSVC sets the label service.
 func WithLabelSVC(labelSVC influxdb.LabelService) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -76,6 +122,13 @@ func WithLabelSVC(labelSVC influxdb.LabelService) ServiceSetterFn {
 	}
 }
 
+// WithScraperTargetSVC sets the scraper target service.
+func WithScraperTargetSVC(scraperSVC influxdb.ScraperTargetStoreService) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.scraperSVC = scraperSVC
+	}
+}
+
 // WithSecretSVC sets the secret service.
 func WithSecretSVC(secretSVC influxdb.SecretService) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -83,6 +136,15 @@ func WithSecretSVC(secretSVC influxdb.SecretService) ServiceSetterFn {
 	}
 }
 
+// WithTaskSVC sets the task service the tasks of a pkg are created with. The
+// status of the existing checks and notification rules is found with it too,
+// the status is kept by their tasks.
+func WithTaskSVC(taskSVC influxdb.TaskService) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.taskSVC = taskSVC
+	}
+}
+
 // WithTelegrafSVC sets the telegraf service.
 func WithTelegrafSVC(telegrafSVC influxdb.TelegrafConfigStore) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -104,13 +166,24 @@ type Service struct {
 
 	labelSVC    influxdb.LabelService
 	bucketSVC   influxdb.BucketService
+	checkSVC    influxdb.CheckService
 	dashSVC     influxdb.DashboardService
 	endpointSVC influxdb.NotificationEndpointService
+	ruleSVC     influxdb.NotificationRuleStore
+	scraperSVC  influxdb.ScraperTargetStoreService
 	secretSVC   influxdb.SecretService
+	taskSVC     influxdb.TaskService
 	teleSVC     influxdb.TelegrafConfigStore
 	varSVC      influxdb.VariableService
 
-	applyReqLimit int
+	usageReporter UsageReporter
+	orgOpLog      influxdb.OrganizationOperationLogRecorder
+	timeGen       influxdb.TimeGenerator
+	metrics       *serviceMetrics
+
+	applyReqLimit        int
+	applyStreamBatchSize int
+	applyOrgsLimit       int
 }
 
 var _ SVC = (*Service)(nil)
@@ -118,23 +191,36 @@ var _ SVC = (*Service)(nil)
 // NewService is a constructor for a pkger Service.
 func NewService(opts ...ServiceSetterFn) *Service {
 	opt := &serviceOpt{
-		logger:        zap.NewNop(),
-		applyReqLimit: 5,
+		logger:               zap.NewNop(),
+		timeGen:              influxdb.RealTimeGenerator{},
+		applyReqLimit:        5,
+		applyStreamBatchSize: 500,
+		applyOrgsLimit:       5,
 	}
 	for _, o := range opts {
 		o(opt)
 	}
 
 	return &Service{
-		log:           opt.logger,
-		bucketSVC:     opt.bucketSVC,
-		labelSVC:      opt.labelSVC,
-		dashSVC:       opt.dashSVC,
-		endpointSVC:   opt.endpointSVC,
-		secretSVC:     opt.secretSVC,
-		teleSVC:       opt.teleSVC,
-		varSVC:        opt.varSVC,
-		applyReqLimit: opt.applyReqLimit,
+		log:                  opt.logger,
+		bucketSVC:            opt.bucketSVC,
+		checkSVC:             opt.checkSVC,
+		labelSVC:             opt.labelSVC,
+		dashSVC:              opt.dashSVC,
+		endpointSVC:          opt.endpointSVC,
+		ruleSVC:              opt.ruleSVC,
+		scraperSVC:           opt.scraperSVC,
+		secretSVC:            opt.secretSVC,
+		taskSVC:              opt.taskSVC,
+		teleSVC:              opt.teleSVC,
+		varSVC:               opt.varSVC,
+		usageReporter:        opt.usageReporter,
+		orgOpLog:             opt.orgOpLog,
+		timeGen:              opt.timeGen,
+		metrics:              newServiceMetrics(),
+		applyReqLimit:        opt.applyReqLimit,
+		applyStreamBatchSize: opt.applyStreamBatchSize,
+		applyOrgsLimit:       opt.applyOrgsLimit,
 	}
 }
 
@@ -146,6 +232,9 @@ type CreateOpt struct {
 	Metadata  Metadata
 	OrgIDs    map[influxdb.ID]bool
 	Resources []ResourceToClone
+
+	// FormatQueries formats the flux queries of the dashboards cloned.
+	FormatQueries bool
 }
 
 // CreateWithMetadata sets the metadata on the pkg in a CreatePkg call.
@@ -156,6 +245,18 @@ func CreateWithMetadata(meta Metadata) CreatePkgSetFn {
 	}
 }
 
+// CreateWithFormattedQueries formats the flux queries of the charts of cloned
+// dashboards with the flux formatter. Queries exported from the UI vary in
+// their whitespace, formatting them keeps the diffs of a pkg tracked in source
+// control to the changes made. The builder configuration of a query is never
+// exported, only its text.
+func CreateWithFormattedQueries() CreatePkgSetFn {
+	return func(opt *CreateOpt) error {
+		opt.FormatQueries = true
+		return nil
+	}
+}
+
 // CreateWithExistingResources allows the create method to clone existing resources.
 func CreateWithExistingResources(resources ...ResourceToClone) CreatePkgSetFn {
 	return func(opt *CreateOpt) error {
@@ -170,6 +271,22 @@ func CreateWithExistingResources(resources ...ResourceToClone) CreatePkgSetFn {
 	}
 }
 
+// CreateWithDashboardAndDeps allows the create method to clone an existing
+// dashboard along with the resources it depends on, producing a pkg that can
+// be applied on its own. The labels mapped to the dashboard and the variables
+// referenced by its queries are cloned with it.
+func CreateWithDashboardAndDeps(id influxdb.ID) CreatePkgSetFn {
+	return func(opt *CreateOpt) error {
+		if id == 0 {
+			return errors.New("dashboard id provided must not be zero")
+		}
+		return CreateWithExistingResources(ResourceToClone{
+			Kind: KindDashboard,
+			ID:   id,
+		})(opt)
+	}
+}
+
 // CreateWithAllOrgResources allows the create method to clone all existing resources
 // for the given organization.
 func CreateWithAllOrgResources(orgID influxdb.ID) CreatePkgSetFn {
@@ -204,15 +321,20 @@ func (s *Service) CreatePkg(ctx context.Context, setters ...CreatePkgSetFn) (*Pk
 			Resources: make([]Resource, 0, len(opt.Resources)),
 		},
 	}
-	if pkg.Metadata.Name == "" {
-		// sudo randomness, this is not an attempt at making charts unique
-		// that is a problem for the consumer.
-		pkg.Metadata.Name = fmt.Sprintf("new_%7d", rand.Int())
-	}
 	if pkg.Metadata.Version == "" {
 		pkg.Metadata.Version = "v1"
 	}
 
+	// the export fields are set by the export alone, those of the metadata
+	// provided, i.e. the metadata of a previous export, are stale.
+	pkg.Metadata.ExportedAt = nil
+	pkg.Metadata.SourceOrgID = ""
+	if len(opt.OrgIDs) == 1 {
+		for orgID := range opt.OrgIDs {
+			pkg.Metadata.SourceOrgID = orgID.String()
+		}
+	}
+
 	cloneAssFn := s.resourceCloneAssociationsGen()
 	for orgID := range opt.OrgIDs {
 		resourcesToClone, err := s.cloneOrgResources(ctx, orgID)
@@ -223,14 +345,25 @@ func (s *Service) CreatePkg(ctx context.Context, setters ...CreatePkgSetFn) (*Pk
 	}
 
 	for _, r := range uniqResourcesToClone(opt.Resources) {
-		newResources, err := s.resourceCloneToResource(ctx, r, cloneAssFn)
+		newResources, warnings, err := s.resourceCloneToResource(ctx, r, cloneAssFn)
 		if err != nil {
 			return nil, err
 		}
 		pkg.Spec.Resources = append(pkg.Spec.Resources, newResources...)
+		pkg.Warnings = append(pkg.Warnings, warnings...)
 	}
 
 	pkg.Spec.Resources = uniqResources(pkg.Spec.Resources)
+	if len(opt.Resources) > 0 {
+		exportedAt := s.timeGen.Now().UTC()
+		pkg.Metadata.ExportedAt = &exportedAt
+	}
+	if opt.FormatQueries {
+		formatDashboardQueries(pkg.Spec.Resources)
+	}
+	if pkg.Metadata.Name == "" {
+		pkg.Metadata.Name = defaultPkgName(opt.OrgIDs, pkg)
+	}
 
 	if err := pkg.Validate(ValidWithoutResources()); err != nil {
 		return nil, err
@@ -266,6 +399,10 @@ func (s *Service) cloneOrgResources(ctx context.Context, orgID influxdb.ID) ([]R
 			resType: KindBucket.ResourceType(),
 			cloneFn: s.cloneOrgBuckets,
 		},
+		{
+			resType: KindCheck.ResourceType(),
+			cloneFn: s.cloneOrgChecks,
+		},
 		{
 			resType: KindDashboard.ResourceType(),
 			cloneFn: s.cloneOrgDashboards,
@@ -278,6 +415,14 @@ func (s *Service) cloneOrgResources(ctx context.Context, orgID influxdb.ID) ([]R
 			resType: KindNotificationEndpoint.ResourceType(),
 			cloneFn: s.cloneOrgNotificationEndpoints,
 		},
+		{
+			resType: KindNotificationRule.ResourceType(),
+			cloneFn: s.cloneOrgNotificationRules,
+		},
+		{
+			resType: KindScraperTarget.ResourceType(),
+			cloneFn: s.cloneOrgScraperTargets,
+		},
 		{
 			resType: KindTelegraf.ResourceType(),
 			cloneFn: s.cloneOrgTelegrafs,
@@ -322,9 +467,7 @@ func (s *Service) cloneOrgBuckets(ctx context.Context, orgID influxdb.ID) ([]Res
 }
 
 func (s *Service) cloneOrgDashboards(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
-	dashs, _, err := s.dashSVC.FindDashboards(ctx, influxdb.DashboardFilter{
-		OrganizationID: &orgID,
-	}, influxdb.FindOptions{Limit: 100})
+	dashs, err := s.findAllDashboards(ctx, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -357,6 +500,28 @@ func (s *Service) cloneOrgLabels(ctx context.Context, orgID influxdb.ID) ([]Reso
 	return resources, nil
 }
 
+func (s *Service) cloneOrgChecks(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
+	if s.checkSVC == nil {
+		return nil, nil
+	}
+
+	checks, _, err := s.checkSVC.FindChecks(ctx, influxdb.CheckFilter{
+		OrgID: &orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]ResourceToClone, 0, len(checks))
+	for _, c := range checks {
+		resources = append(resources, ResourceToClone{
+			Kind: checkResourceKind(c),
+			ID:   c.GetID(),
+		})
+	}
+	return resources, nil
+}
+
 func (s *Service) cloneOrgNotificationEndpoints(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
 	endpoints, _, err := s.endpointSVC.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
 		OrgID: &orgID,
@@ -368,15 +533,57 @@ func (s *Service) cloneOrgNotificationEndpoints(ctx context.Context, orgID influ
 	resources := make([]ResourceToClone, 0, len(endpoints))
 	for _, e := range endpoints {
 		resources = append(resources, ResourceToClone{
-			Kind: KindNotificationEndpoint,
+			Kind: endpointKind(e),
 			ID:   e.GetID(),
 		})
 	}
 	return resources, nil
 }
 
+func (s *Service) cloneOrgNotificationRules(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
+	if s.ruleSVC == nil {
+		return nil, nil
+	}
+
+	rules, _, err := s.ruleSVC.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{
+		OrgID: &orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]ResourceToClone, 0, len(rules))
+	for _, r := range rules {
+		resources = append(resources, ResourceToClone{
+			Kind: KindNotificationRule,
+			ID:   r.GetID(),
+		})
+	}
+	return resources, nil
+}
+
+func (s *Service) cloneOrgScraperTargets(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
+	if s.scraperSVC == nil {
+		return nil, nil
+	}
+
+	targets, err := s.scraperSVC.ListTargets(ctx, influxdb.ScraperTargetFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]ResourceToClone, 0, len(targets))
+	for _, t := range targets {
+		resources = append(resources, ResourceToClone{
+			Kind: KindScraperTarget,
+			ID:   t.ID,
+		})
+	}
+	return resources, nil
+}
+
 func (s *Service) cloneOrgTelegrafs(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
-	teles, _, err := s.teleSVC.FindTelegrafConfigs(ctx, influxdb.TelegrafConfigFilter{OrgID: &orgID})
+	teles, err := s.findAllTelegrafs(ctx, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -392,9 +599,7 @@ func (s *Service) cloneOrgTelegrafs(ctx context.Context, orgID influxdb.ID) ([]R
 }
 
 func (s *Service) cloneOrgVariables(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
-	vars, err := s.varSVC.FindVariables(ctx, influxdb.VariableFilter{
-		OrganizationID: &orgID,
-	}, influxdb.FindOptions{Limit: 10000})
+	vars, err := s.findAllVariables(ctx, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -410,30 +615,48 @@ func (s *Service) cloneOrgVariables(ctx context.Context, orgID influxdb.ID) ([]R
 	return resources, nil
 }
 
-func (s *Service) resourceCloneToResource(ctx context.Context, r ResourceToClone, cFn cloneAssociationsFn) (newResources []Resource, e error) {
+func (s *Service) resourceCloneToResource(ctx context.Context, r ResourceToClone, cFn cloneAssociationsFn) (newResources []Resource, warnings []string, e error) {
 	defer func() {
 		if e != nil {
 			e = ierrors.Wrap(e, "cloning resource")
 		}
 	}()
-	var newResource Resource
+	var (
+		newResource Resource
+		// resources depended on by the cloned resource that are not
+		// labels, i.e. the variables referenced by a dashboard.
+		depResources []Resource
+	)
 	switch {
 	case r.Kind.is(KindBucket):
 		bkt, err := s.bucketSVC.FindBucketByID(ctx, r.ID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		newResource = bucketToResource(*bkt, r.Name)
+	case r.Kind.is(KindCheckDeadman),
+		r.Kind.is(KindCheckThreshold):
+		c, err := s.checkSVC.FindCheckByID(ctx, r.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		newResource = checkToResource(c, r.Name)
 	case r.Kind.is(KindDashboard):
 		dash, err := s.findDashboardByIDFull(ctx, r.ID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		newResource = dashboardToResource(*dash, r.Name)
+		if !r.ExcludeVariables {
+			depResources, warnings, err = s.cloneDashboardVariables(ctx, *dash, cFn)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
 	case r.Kind.is(KindLabel):
 		l, err := s.labelSVC.FindLabelByID(ctx, r.ID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		newResource = labelToResource(*l, r.Name)
 	case r.Kind.is(KindNotificationEndpoint),
@@ -442,34 +665,105 @@ func (s *Service) resourceCloneToResource(ctx context.Context, r ResourceToClone
 		r.Kind.is(KindNotificationEndpointSlack):
 		e, err := s.endpointSVC.FindNotificationEndpointByID(ctx, r.ID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		newResource = endpointToResource(e, r.Name)
+	case r.Kind.is(KindNotificationRule):
+		rule, err := s.ruleSVC.FindNotificationRuleByID(ctx, r.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		// the endpoint is referenced by name, the name is resolved to the
+		// endpoint of the pkg or the org it is applied to.
+		e, err := s.endpointSVC.FindNotificationEndpointByID(ctx, rule.GetEndpointID())
+		if err != nil {
+			return nil, nil, err
+		}
+		newResource = ruleToResource(rule, e.GetName(), r.Name)
+	case r.Kind.is(KindScraperTarget):
+		t, err := s.scraperSVC.GetTargetByID(ctx, r.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		// the bucket is referenced by name, the name is resolved to the bucket
+		// of the pkg or the org it is applied to.
+		bkt, err := s.bucketSVC.FindBucketByID(ctx, t.BucketID)
+		if err != nil {
+			return nil, nil, err
+		}
+		newResource = scraperTargetToResource(*t, bkt.Name, r.Name)
 	case r.Kind.is(KindTelegraf):
 		t, err := s.teleSVC.FindTelegrafConfigByID(ctx, r.ID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		newResource = telegrafToResource(*t, r.Name)
 	case r.Kind.is(KindVariable):
 		v, err := s.varSVC.FindVariableByID(ctx, r.ID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		newResource = variableToResource(*v, r.Name)
 	default:
-		return nil, errors.New("unsupported kind provided: " + string(r.Kind))
+		return nil, nil, errors.New("unsupported kind provided: " + string(r.Kind))
 	}
 
 	ass, err := cFn(ctx, r)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(ass.associations) > 0 {
 		newResource[fieldAssociations] = ass.associations
 	}
 
-	return append([]Resource{newResource}, ass.newLableResources...), nil
+	newResources = append([]Resource{newResource}, ass.newLableResources...)
+	return append(newResources, depResources...), warnings, nil
+}
+
+// cloneDashboardVariables clones the variables referenced by the queries of
+// the dashboard, along with the labels associated with them. A reference that
+// does not match a variable of the dashboard's org is returned as a warning.
+// Duplicates across dashboards are removed when the resources are combined
+// into the pkg.
+func (s *Service) cloneDashboardVariables(ctx context.Context, dash influxdb.Dashboard, cFn cloneAssociationsFn) ([]Resource, []string, error) {
+	names := dashboardVariableNames(dash)
+	if len(names) == 0 {
+		return nil, nil, nil
+	}
+
+	vars, err := s.findAllVariables(ctx, dash.OrganizationID)
+	if err != nil {
+		return nil, nil, ierrors.Wrap(err, "finding dashboard variables")
+	}
+
+	mVars := make(map[string]*influxdb.Variable, len(vars))
+	for _, v := range vars {
+		mVars[v.Name] = v
+	}
+
+	var (
+		resources []Resource
+		warnings  []string
+	)
+	for _, name := range names {
+		v, ok := mVars[name]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("dashboard %q references variable %q that does not exist", dash.Name, name))
+			continue
+		}
+
+		newResource := variableToResource(*v, "")
+		ass, err := cFn(ctx, ResourceToClone{Kind: KindVariable, ID: v.ID})
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(ass.associations) > 0 {
+			newResource[fieldAssociations] = ass.associations
+		}
+		resources = append(resources, newResource)
+		resources = append(resources, ass.newLableResources...)
+	}
+	return resources, warnings, nil
 }
 
 type (
@@ -493,7 +787,7 @@ func (s *Service) resourceCloneAssociationsGen() cloneAssociationsFn {
 			return associations{}, nil
 		}
 
-		labels, err := s.labelSVC.FindResourceLabels(ctx, influxdb.LabelMappingFilter{
+		labels, err := s.findAllResourceLabels(ctx, influxdb.LabelMappingFilter{
 			ResourceID:   r.ID,
 			ResourceType: r.Kind.ResourceType(),
 		})
@@ -518,16 +812,157 @@ func (s *Service) resourceCloneAssociationsGen() cloneAssociationsFn {
 	}
 }
 
+// findAllResourceLabels reads all the labels of the resource a page at a time,
+// so that a resource with many labels is not read in one go.
+func (s *Service) findAllResourceLabels(ctx context.Context, filter influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+	opts := influxdb.FindOptions{Limit: influxdb.MaxPageSize}
+	var labels []*influxdb.Label
+	for {
+		page, err := s.labelSVC.FindResourceLabels(ctx, filter, opts)
+		if err != nil {
+			return nil, err
+		}
+		if opts.After != nil && len(page) > 0 && page[len(page)-1].ID == *opts.After {
+			// the service does not page after an ID, the page was read
+			return labels, nil
+		}
+		labels = append(labels, page...)
+		if len(page) < opts.Limit {
+			return labels, nil
+		}
+
+		after := page[len(page)-1].ID
+		opts.After = &after
+	}
+}
+
+// ApplyOptFn is a functional input for setting the options of a dry run or
+// apply of a pkg.
+type ApplyOptFn func(opt *ApplyOpt) error
+
+// ApplyOpt are the options for a dry run or apply of a pkg. A pkg verified by
+// a dry run is only trusted by Apply when applied with the same options.
+type ApplyOpt struct {
+	// MissingSecrets are secret values for the secrets referenced by the pkg
+	// that do not exist in the platform. They are created when the pkg is applied.
+	MissingSecrets map[string]string
+
+	// Verify reads back the resources applied and compares them with the pkg,
+	// failing the apply when they do not match. It does not change what is
+	// applied, so has no bearing on whether a dry run verified the pkg.
+	Verify bool `json:"-"`
+
+	// CollisionStrategy determines how the resources of the pkg whose names
+	// collide with existing resources are applied. It defaults to
+	// CollisionUpdate.
+	CollisionStrategy CollisionStrategy `json:",omitempty"`
+
+	// BestEffort keeps the resources applied when others fail to apply. The
+	// failures are returned in the Summary instead of rolling back the apply.
+	// It does not change what a dry run verifies.
+	BestEffort bool `json:"-"`
+
+	// OnlyChanged applies only the resources that are new or changed. A dry
+	// run with it looks up the existing dashboards and telegrafs, which are
+	// otherwise always created.
+	OnlyChanged bool `json:",omitempty"`
+
+	// Snapshot is the snapshot of existing resources a dry run is run against,
+	// instead of the platform. It cannot be applied.
+	Snapshot *Summary `json:"-"`
+}
+
+func (o ApplyOpt) collisionStrategy() CollisionStrategy {
+	if o.CollisionStrategy == "" {
+		return CollisionUpdate
+	}
+	return o.CollisionStrategy
+}
+
+// ApplyWithSecrets provides secrets to the platform that the pkg will need.
+func ApplyWithSecrets(secrets map[string]string) ApplyOptFn {
+	return func(opt *ApplyOpt) error {
+		if opt.MissingSecrets == nil {
+			opt.MissingSecrets = make(map[string]string, len(secrets))
+		}
+		for k, v := range secrets {
+			opt.MissingSecrets[k] = v
+		}
+		return nil
+	}
+}
+
+// ApplyWithVerification verifies the resources created and updated by the apply
+// by reading them back from the platform. When a resource does not match the
+// pkg the apply fails and is rolled back, with the mismatches of each resource
+// provided in the error.
+func ApplyWithVerification() ApplyOptFn {
+	return func(opt *ApplyOpt) error {
+		opt.Verify = true
+		return nil
+	}
+}
+
+// ApplyWithBestEffort applies every resource of the pkg it can, rather than
+// rolling back the entire apply when a resource fails to apply. The resources
+// that fail are provided in the Errors of the Summary, the resources applied
+// successfully are kept. A label mapping is not applied when either its label
+// or its resource failed to apply. It cannot be combined with verification.
+func ApplyWithBestEffort() ApplyOptFn {
+	return func(opt *ApplyOpt) error {
+		opt.BestEffort = true
+		return nil
+	}
+}
+
+func newApplyOpt(opts ...ApplyOptFn) (ApplyOpt, error) {
+	var opt ApplyOpt
+	for _, o := range opts {
+		if err := o(&opt); err != nil {
+			return ApplyOpt{}, err
+		}
+	}
+	if opt.BestEffort && opt.Verify {
+		return ApplyOpt{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "best effort apply cannot be verified",
+		}
+	}
+	return opt, nil
+}
+
 // DryRun provides a dry run of the pkg application. The pkg will be marked verified
-// for later calls to Apply. This func will be run on an Apply if it has not been run
-// already.
-func (s *Service) DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg) (Summary, Diff, error) {
+// for later calls to Apply with the same options. This func will be run on an Apply
+// if it has not been run already, if it was run with different options, or if the pkg
+// has been modified since.
+func (s *Service) DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) (Summary, Diff, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := ctx.Err(); err != nil {
+		return Summary{}, Diff{}, err
+	}
+
+	opt, err := newApplyOpt(opts...)
+	if err != nil {
+		return Summary{}, Diff{}, err
+	}
+
+	pkg.mu.Lock()
+	defer pkg.mu.Unlock()
+	return s.dryRun(ctx, orgID, pkg, opt)
+}
+
+func (s *Service) dryRun(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opt ApplyOpt) (Summary, Diff, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
 	// so here's the deal, when we have issues with the parsing validation, we
 	// continue to do the diff anyhow. any resource that does not have a name
 	// will be skipped, and won't bleed into the dry run here. We can now return
 	// a error (parseErr) and valid diff/summary.
 	var parseErr error
-	if !pkg.isParsed {
+	if !pkg.isParsedCurrent() {
 		err := pkg.Validate()
 		if err != nil && !IsParseErr(err) {
 			return Summary{}, Diff{}, err
@@ -535,7 +970,30 @@ func (s *Service) DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *Pk
 		parseErr = err
 	}
 
-	if err := s.dryRunSecrets(ctx, orgID, pkg); err != nil {
+	// the lookups of the existing resources are the expensive part of a dry
+	// run, a ctx done while parsing stops here.
+	if err := ctx.Err(); err != nil {
+		return Summary{}, Diff{}, err
+	}
+
+	// the permissions are checked up front, all of the permissions missing
+	// are reported at once rather than the first a lookup or apply fails on.
+	// a snapshot is never applied, so is not checked.
+	if opt.Snapshot == nil {
+		missing, err := missingPermissions(ctx, orgID, pkg)
+		if err != nil {
+			return Summary{}, Diff{}, err
+		}
+		if len(missing) > 0 {
+			return Summary{}, Diff{}, missingPermissionsErr(missing)
+		}
+	}
+
+	if opt.Snapshot != nil {
+		s = s.withSnapshot(*opt.Snapshot)
+	}
+
+	if err := s.dryRunSecrets(ctx, orgID, pkg, opt); err != nil {
 		return Summary{}, Diff{}, err
 	}
 
@@ -544,6 +1002,11 @@ func (s *Service) DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *Pk
 		return Summary{}, Diff{}, err
 	}
 
+	diffChecks, err := s.dryRunChecks(ctx, orgID, pkg)
+	if err != nil {
+		return Summary{}, Diff{}, err
+	}
+
 	diffLabels, err := s.dryRunLabels(ctx, orgID, pkg)
 	if err != nil {
 		return Summary{}, Diff{}, err
@@ -554,54 +1017,118 @@ func (s *Service) DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *Pk
 		return Summary{}, Diff{}, err
 	}
 
+	// the rules resolve their endpoints against the endpoints of the pkg,
+	// the existing endpoints are found first
+	diffRules, err := s.dryRunNotificationRules(ctx, orgID, pkg)
+	if err != nil {
+		return Summary{}, Diff{}, err
+	}
+
+	diffScrapers, err := s.dryRunScraperTargets(ctx, orgID, pkg)
+	if err != nil {
+		return Summary{}, Diff{}, err
+	}
+
 	diffVars, err := s.dryRunVariables(ctx, orgID, pkg)
 	if err != nil {
 		return Summary{}, Diff{}, err
 	}
 
+	// the label mappings of the dashboards and telegrafs left unchanged are
+	// those of their existing resources, they are found first
+	diffDashboards, err := s.dryRunDashboards(ctx, orgID, pkg, opt)
+	if err != nil {
+		return Summary{}, Diff{}, err
+	}
+
+	diffTelegrafs, err := s.dryRunTelegraf(ctx, orgID, pkg, opt)
+	if err != nil {
+		return Summary{}, Diff{}, err
+	}
+
+	diffTasks := s.dryRunTasks(pkg)
+
 	diffLabelMappings, err := s.dryRunLabelMappings(ctx, pkg)
 	if err != nil {
 		return Summary{}, Diff{}, err
 	}
 
+	warnings, err := s.dryRunBucketReferences(ctx, orgID, pkg)
+	if err != nil {
+		return Summary{}, Diff{}, err
+	}
+
+	// a ctx done during the lookups may have cut them short, the pkg must not
+	// be verified from an incomplete view of the existing resources.
+	if err := ctx.Err(); err != nil {
+		return Summary{}, Diff{}, err
+	}
+
 	// verify the pkg is verified by a dry run. when calling Service.Apply this
-	// is required to have been run. if it is not true, then apply runs
-	// the Dry run.
-	pkg.isVerified = true
+	// is required to have been run with the same options. if it is not, then
+	// apply runs the dry run. the existing resources of a snapshot are never
+	// trusted by an apply, so a dry run of a snapshot unverifies the pkg.
+	if opt.Snapshot != nil {
+		pkg.isVerified = false
+	} else {
+		pkg.verify(opt)
+	}
 
 	diff := Diff{
 		Buckets:               diffBuckets,
-		Dashboards:            s.dryRunDashboards(pkg),
+		Checks:                diffChecks,
+		Collisions:            collisions(pkg, opt.collisionStrategy()),
+		Conflicts:             s.dryRunConflicts(pkg),
+		Dashboards:            diffDashboards,
 		Labels:                diffLabels,
 		LabelMappings:         diffLabelMappings,
 		NotificationEndpoints: diffEndpoints,
-		Telegrafs:             s.dryRunTelegraf(pkg),
+		NotificationRules:     diffRules,
+		ScraperTargets:        diffScrapers,
+		Tasks:                 diffTasks,
+		Telegrafs:             diffTelegrafs,
 		Variables:             diffVars,
+		Warnings:              warnings,
+		Snapshot:              opt.Snapshot != nil,
+	}
+	sum := pkg.Summary()
+	// the hash is computed from the pkg as is, a pkg with parse errors
+	// has not recorded the hash of a successful parse.
+	if hash, err := pkg.contentHash(); err == nil {
+		sum.ContentHash = hash
 	}
-	return pkg.Summary(), diff, parseErr
+	return sum, diff, parseErr
 }
 
 func (s *Service) dryRunBuckets(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]DiffBucket, error) {
-	mExistingBkts := make(map[string]DiffBucket)
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
 	bkts := pkg.buckets()
-	for i := range bkts {
+	diffs := make([]DiffBucket, len(bkts))
+	err := s.dryRunEach(ctx, len(bkts), func(ctx context.Context, i int) error {
 		b := bkts[i]
 		existingBkt, err := s.bucketSVC.FindBucketByName(ctx, orgID, b.Name())
+		if err != nil && b.platformName() != b.Name() {
+			// a bucket renamed by a previous apply is found by its display name
+			existingBkt, err = s.bucketSVC.FindBucketByName(ctx, orgID, b.platformName())
+		}
+		b.existing = nil
 		switch err {
 		// TODO: case for err not found here and another case handle where
 		//  err isn't a not found (some other error)
 		case nil:
 			b.existing = existingBkt
-			mExistingBkts[b.Name()] = newDiffBucket(b, existingBkt)
+			diffs[i] = newDiffBucket(b, existingBkt)
 		default:
-			mExistingBkts[b.Name()] = newDiffBucket(b, nil)
+			diffs[i] = newDiffBucket(b, nil)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	var diffs []DiffBucket
-	for _, diff := range mExistingBkts {
-		diffs = append(diffs, diff)
-	}
 	sort.Slice(diffs, func(i, j int) bool {
 		return diffs[i].Name < diffs[j].Name
 	})
@@ -609,39 +1136,274 @@ func (s *Service) dryRunBuckets(ctx context.Context, orgID influxdb.ID, pkg *Pkg
 	return diffs, nil
 }
 
-func (s *Service) dryRunDashboards(pkg *Pkg) []DiffDashboard {
-	var diffs []DiffDashboard
-	for _, d := range pkg.dashboards() {
-		diffs = append(diffs, newDiffDashboard(d))
-	}
-	return diffs
-}
+func (s *Service) dryRunChecks(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]DiffCheck, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
 
-func (s *Service) dryRunLabels(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]DiffLabel, error) {
-	mExistingLabels := make(map[string]DiffLabel)
-	labels := pkg.labels()
-	for i := range labels {
-		pkgLabel := labels[i]
-		existingLabels, err := s.labelSVC.FindLabels(ctx, influxdb.LabelFilter{
-			Name:  pkgLabel.Name(),
-			OrgID: &orgID,
-		}, influxdb.FindOptions{Limit: 1})
-		switch {
-		// TODO: case for err not found here and another case handle where
-		//  err isn't a not found (some other error)
-		case err == nil && len(existingLabels) > 0:
-			existingLabel := existingLabels[0]
-			pkgLabel.existing = existingLabel
-			mExistingLabels[pkgLabel.Name()] = newDiffLabel(pkgLabel, existingLabel)
-		default:
-			mExistingLabels[pkgLabel.Name()] = newDiffLabel(pkgLabel, nil)
-		}
+	checks := pkg.checks()
+	if len(checks) == 0 {
+		return nil, nil
 	}
 
-	diffs := make([]DiffLabel, 0, len(mExistingLabels))
-	for _, diff := range mExistingLabels {
-		diffs = append(diffs, diff)
+	existingChecks, _, err := s.checkSVC.FindChecks(ctx, influxdb.CheckFilter{
+		OrgID: &orgID,
+	}) // grab em all
+	if err != nil {
+		return nil, err
+	}
+
+	mExisting := make(map[string]influxdb.Check, len(existingChecks))
+	for _, c := range existingChecks {
+		mExisting[c.GetName()] = c
+	}
+
+	diffs := make([]DiffCheck, 0, len(checks))
+	for _, c := range checks {
+		// the bucket of the pkg is applied before the check, a bucket
+		// outside of the pkg must already exist in the org.
+		if _, ok := pkg.mBuckets[c.bucket]; !ok {
+			if _, err := s.bucketSVC.FindBucketByName(ctx, orgID, c.bucket); err != nil {
+				return nil, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("bucket %q of check %q is not in the pkg or the org", c.bucket, c.Name()),
+					Err:  err,
+				}
+			}
+		}
+
+		existing, ok := mExisting[c.Name()]
+		if !ok {
+			// a check renamed by a previous apply is found by its display name
+			existing, ok = mExisting[c.platformName()]
+		}
+		c.existing = nil
+		if ok {
+			c.existing = existing
+		}
+		diffs = append(diffs, newDiffCheck(c, c.existing))
+	}
+	return diffs, nil
+}
+
+// dryRunConflicts reports the resources of the pkg that collide with resources
+// the platform treats specially. It relies on the existing state found by
+// the other dry run lookups.
+func (s *Service) dryRunConflicts(pkg *Pkg) []DiffConflict {
+	var conflicts []DiffConflict
+	for _, b := range pkg.buckets() {
+		isSystem := b.Name() == influxdb.TasksSystemBucketName ||
+			b.Name() == influxdb.MonitoringSystemBucketName ||
+			(b.existing != nil && b.existing.Type == influxdb.BucketTypeSystem)
+		if !isSystem {
+			continue
+		}
+		conflicts = append(conflicts, DiffConflict{
+			Kind:   KindBucket,
+			Name:   b.Name(),
+			Reason: "bucket name collides with a system bucket",
+		})
+	}
+	return conflicts
+}
+
+// dryRunBucketReferences warns of the buckets queried by the charts and the
+// flux query variables of the pkg that are neither in the pkg nor in the org.
+// Only a bucket named by a string literal is checked, a bucket named by a
+// variable is only known when the query runs.
+func (s *Service) dryRunBucketReferences(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]DiffWarning, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var refs []DiffWarning
+	for _, d := range pkg.dashboards() {
+		for _, c := range d.Charts {
+			for _, q := range c.Queries {
+				for _, bkt := range queryBucketNames(q.Query) {
+					refs = append(refs, DiffWarning{
+						Kind:   KindDashboard,
+						Name:   d.Name(),
+						Chart:  c.Name,
+						Bucket: bkt,
+					})
+				}
+			}
+		}
+	}
+	for _, v := range pkg.variables() {
+		if v.Type != fieldArgTypeQuery || v.Language != "flux" {
+			continue
+		}
+		for _, bkt := range queryBucketNames(v.Query) {
+			refs = append(refs, DiffWarning{
+				Kind:   KindVariable,
+				Name:   v.Name(),
+				Bucket: bkt,
+			})
+		}
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	exists := make(map[string]bool)
+	for _, b := range pkg.buckets() {
+		exists[b.Name()] = true
+		exists[b.platformName()] = true
+	}
+
+	var (
+		warnings []DiffWarning
+		seen     = make(map[DiffWarning]bool)
+	)
+	for _, ref := range refs {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		found, ok := exists[ref.Bucket]
+		if !ok {
+			// as with the buckets of the pkg, a failed lookup is a bucket
+			// that does not exist.
+			_, err := s.bucketSVC.FindBucketByName(ctx, orgID, ref.Bucket)
+			found = err == nil
+			exists[ref.Bucket] = found
+		}
+		if found {
+			continue
+		}
+
+		if ref.Kind.is(KindDashboard) {
+			ref.Message = fmt.Sprintf("chart %q of dashboard %q queries bucket %q that is neither in the pkg nor in the org", ref.Chart, ref.Name, ref.Bucket)
+		} else {
+			ref.Message = fmt.Sprintf("variable %q queries bucket %q that is neither in the pkg nor in the org", ref.Name, ref.Bucket)
+		}
+		warnings = append(warnings, ref)
+	}
+	return warnings, nil
+}
+
+// queryBucketNames returns the buckets the flux query reads from by name, i.e.
+// the bucket of from(bucket: "telegraf"). A query that does not parse reads
+// from none.
+func queryBucketNames(q string) []string {
+	pkg := parser.ParseSource(q)
+	if ast.Check(pkg) > 0 {
+		return nil
+	}
+
+	var names []string
+	ast.Walk(ast.CreateVisitor(func(node ast.Node) {
+		call, ok := node.(*ast.CallExpression)
+		if !ok || len(call.Arguments) != 1 {
+			return
+		}
+		if fn, ok := call.Callee.(*ast.Identifier); !ok || fn.Name != "from" {
+			return
+		}
+		obj, ok := call.Arguments[0].(*ast.ObjectExpression)
+		if !ok {
+			return
+		}
+		for _, p := range obj.Properties {
+			if p.Key.Key() != "bucket" {
+				continue
+			}
+			if lit, ok := p.Value.(*ast.StringLiteral); ok {
+				names = append(names, lit.Value)
+			}
+		}
+	}), pkg)
+	return names
+}
+
+// dryRunDashboards diffs the dashboards of the pkg. A dashboard is never
+// updated, applying it creates a new one, so the existing dashboards are only
+// looked up to leave those that match the pkg unchanged when applying with
+// only the changes.
+func (s *Service) dryRunDashboards(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opt ApplyOpt) ([]DiffDashboard, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	dashboards := pkg.dashboards()
+	for _, d := range dashboards {
+		d.existing = nil
+	}
+
+	if opt.OnlyChanged && len(dashboards) > 0 {
+		existingDashboards, err := s.findAllDashboards(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+
+		mExisting := make(map[string][]*influxdb.Dashboard, len(existingDashboards))
+		for _, d := range existingDashboards {
+			mExisting[d.Name] = append(mExisting[d.Name], d)
+		}
+
+		// dashboards may share a name, each existing dashboard is left
+		// unchanged for one dashboard of the pkg at most.
+		matched := make(map[influxdb.ID]bool)
+		for _, d := range dashboards {
+			for _, existing := range mExisting[d.Name()] {
+				if matched[existing.ID] {
+					continue
+				}
+				if err := s.loadDashboardCellViews(ctx, existing); err != nil {
+					return nil, err
+				}
+				if d.matches(*existing) {
+					matched[existing.ID] = true
+					d.existing = existing
+					break
+				}
+			}
+		}
+	}
+
+	var diffs []DiffDashboard
+	for _, d := range dashboards {
+		diffs = append(diffs, newDiffDashboard(d))
+	}
+	return diffs, nil
+}
+
+func (s *Service) dryRunLabels(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]DiffLabel, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	labels := pkg.labels()
+	diffs := make([]DiffLabel, len(labels))
+	err := s.dryRunEach(ctx, len(labels), func(ctx context.Context, i int) error {
+		pkgLabel := labels[i]
+		existingLabels, err := s.labelSVC.FindLabels(ctx, influxdb.LabelFilter{
+			Name:  pkgLabel.Name(),
+			OrgID: &orgID,
+		}, influxdb.FindOptions{Limit: 1})
+		if (err != nil || len(existingLabels) == 0) && pkgLabel.platformName() != pkgLabel.Name() {
+			// a label renamed by a previous apply is found by its display name
+			existingLabels, err = s.labelSVC.FindLabels(ctx, influxdb.LabelFilter{
+				Name:  pkgLabel.platformName(),
+				OrgID: &orgID,
+			}, influxdb.FindOptions{Limit: 1})
+		}
+		pkgLabel.existing = nil
+		switch {
+		// TODO: case for err not found here and another case handle where
+		//  err isn't a not found (some other error)
+		case err == nil && len(existingLabels) > 0:
+			existingLabel := existingLabels[0]
+			pkgLabel.existing = existingLabel
+			diffs[i] = newDiffLabel(pkgLabel, existingLabel)
+		default:
+			diffs[i] = newDiffLabel(pkgLabel, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+
 	sort.Slice(diffs, func(i, j int) bool {
 		return diffs[i].Name < diffs[j].Name
 	})
@@ -650,6 +1412,14 @@ func (s *Service) dryRunLabels(ctx context.Context, orgID influxdb.ID, pkg *Pkg)
 }
 
 func (s *Service) dryRunNotificationEndpoints(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]DiffNotificationEndpoint, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	endpoints := pkg.notificationEndpoints()
+	if len(endpoints) == 0 {
+		return nil, nil
+	}
+
 	existingEndpoints, _, err := s.endpointSVC.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
 		OrgID: &orgID,
 	}) // grab em all
@@ -664,12 +1434,17 @@ func (s *Service) dryRunNotificationEndpoints(ctx context.Context, orgID influxd
 	}
 
 	mExistingToNew := make(map[string]DiffNotificationEndpoint)
-	endpoints := pkg.notificationEndpoints()
 	for i := range endpoints {
 		newEndpoint := endpoints[i]
 
 		var existing influxdb.NotificationEndpoint
-		if iExisting, ok := mExisting[newEndpoint.Name()]; ok {
+		iExisting, ok := mExisting[newEndpoint.Name()]
+		if !ok {
+			// an endpoint renamed by a previous apply is found by its display name
+			iExisting, ok = mExisting[newEndpoint.platformName()]
+		}
+		newEndpoint.existing = nil
+		if ok {
 			newEndpoint.existing = iExisting
 			existing = iExisting
 		}
@@ -687,8 +1462,14 @@ func (s *Service) dryRunNotificationEndpoints(ctx context.Context, orgID influxd
 	return diffs, nil
 }
 
-func (s *Service) dryRunSecrets(ctx context.Context, orgID influxdb.ID, pkg *Pkg) error {
+func (s *Service) dryRunSecrets(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opt ApplyOpt) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
 	secrets := pkg.secrets()
+	for secret := range opt.MissingSecrets {
+		delete(secrets, secret)
+	}
 	if len(secrets) == 0 {
 		return nil
 	}
@@ -714,20 +1495,177 @@ func (s *Service) dryRunSecrets(ctx context.Context, orgID influxdb.ID, pkg *Pkg
 	return fmt.Errorf("secrets to not exist for secret reference keys: %s", strings.Join(missing, ", "))
 }
 
-func (s *Service) dryRunTelegraf(pkg *Pkg) []DiffTelegraf {
+func (s *Service) dryRunNotificationRules(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]DiffNotificationRule, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	rules := pkg.notificationRules()
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	existingRules, _, err := s.ruleSVC.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{
+		OrgID: &orgID,
+	}) // grab em all
+	if err != nil {
+		return nil, err
+	}
+	mExisting := make(map[string]influxdb.NotificationRule, len(existingRules))
+	for _, r := range existingRules {
+		mExisting[r.GetName()] = r
+	}
+
+	existingEndpoints, _, err := s.endpointSVC.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		OrgID: &orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	mEndpointsByName := make(map[string]influxdb.NotificationEndpoint, len(existingEndpoints))
+	mEndpointsByID := make(map[influxdb.ID]influxdb.NotificationEndpoint, len(existingEndpoints))
+	for _, e := range existingEndpoints {
+		mEndpointsByName[e.GetName()] = e
+		mEndpointsByID[e.GetID()] = e
+	}
+
+	diffs := make([]DiffNotificationRule, 0, len(rules))
+	for _, r := range rules {
+		// the endpoint of the pkg is applied before the rule, the id of a
+		// new endpoint is resolved when the rule is applied.
+		if e, ok := pkg.mNotificationEndpoints[r.endpointName]; ok {
+			r.endpointID = e.ID()
+			r.endpointType = e.endpointType()
+		} else {
+			e, ok := mEndpointsByName[r.endpointName]
+			if !ok {
+				return nil, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("endpoint %q of notification rule %q is not in the pkg or the org", r.endpointName, r.Name()),
+				}
+			}
+			r.endpointID = e.GetID()
+			r.endpointType = e.Type()
+		}
+
+		existing, ok := mExisting[r.Name()]
+		if !ok {
+			// a rule renamed by a previous apply is found by its display name
+			existing, ok = mExisting[r.platformName()]
+		}
+		r.existing = nil
+		var existingEndpoint influxdb.NotificationEndpoint
+		if ok {
+			r.existing = existing
+			existingEndpoint = mEndpointsByID[existing.GetEndpointID()]
+		}
+		diffs = append(diffs, newDiffNotificationRule(r, r.existing, existingEndpoint))
+	}
+	return diffs, nil
+}
+
+func (s *Service) dryRunScraperTargets(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]DiffScraperTarget, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	targets := pkg.scraperTargets()
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	existingTargets, err := s.scraperSVC.ListTargets(ctx, influxdb.ScraperTargetFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	mExisting := make(map[string]*influxdb.ScraperTarget, len(existingTargets))
+	for i := range existingTargets {
+		mExisting[existingTargets[i].Name] = &existingTargets[i]
+	}
+
+	diffs := make([]DiffScraperTarget, 0, len(targets))
+	for _, t := range targets {
+		t.existing = mExisting[t.Name()]
+
+		// the bucket of the pkg is applied before the target, its id is
+		// resolved when the target is applied.
+		t.bucketID = 0
+		if _, ok := pkg.mBuckets[t.bucket]; !ok {
+			bkt, err := s.bucketSVC.FindBucketByName(ctx, orgID, t.bucket)
+			if err != nil {
+				return nil, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("bucket %q of scraper target %q is not in the pkg or the org", t.bucket, t.Name()),
+					Err:  err,
+				}
+			}
+			t.bucketID = bkt.ID
+		}
+
+		var existingBucket string
+		if t.existing != nil {
+			if bkt, err := s.bucketSVC.FindBucketByID(ctx, t.existing.BucketID); err == nil {
+				existingBucket = bkt.Name
+			}
+		}
+		diffs = append(diffs, newDiffScraperTarget(t, t.existing, existingBucket))
+	}
+	return diffs, nil
+}
+
+// dryRunTelegraf diffs the telegrafs of the pkg. Like dashboards, telegrafs
+// are never updated, the existing telegrafs are only looked up when applying
+// with only the changes.
+func (s *Service) dryRunTelegraf(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opt ApplyOpt) ([]DiffTelegraf, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	teles := pkg.telegrafs()
+	for _, t := range teles {
+		t.existing = nil
+	}
+
+	if opt.OnlyChanged && len(teles) > 0 {
+		existingTeles, err := s.findAllTelegrafs(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+
+		matched := make(map[influxdb.ID]bool)
+		for _, t := range teles {
+			for _, existing := range existingTeles {
+				if matched[existing.ID] || !t.matches(*existing) {
+					continue
+				}
+				matched[existing.ID] = true
+				t.existing = existing
+				break
+			}
+		}
+	}
+
 	var diffs []DiffTelegraf
-	for _, t := range pkg.telegrafs() {
+	for _, t := range teles {
 		diffs = append(diffs, newDiffTelegraf(t))
 	}
+	return diffs, nil
+}
+
+// dryRunTasks diffs the tasks of the pkg. Tasks are never updated, nor are
+// the existing tasks looked up, every task is new.
+func (s *Service) dryRunTasks(pkg *Pkg) []DiffTask {
+	var diffs []DiffTask
+	for _, t := range pkg.tasks() {
+		diffs = append(diffs, newDiffTask(t))
+	}
 	return diffs
 }
 
 func (s *Service) dryRunVariables(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]DiffVariable, error) {
-	mExistingLabels := make(map[string]DiffVariable)
-	variables := pkg.variables()
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
 
-VarLoop:
-	for i := range variables {
+	variables := pkg.variables()
+	diffs := make([]DiffVariable, len(variables))
+	err := s.dryRunEach(ctx, len(variables), func(ctx context.Context, i int) error {
 		pkgVar := variables[i]
 		existingLabels, err := s.varSVC.FindVariables(ctx, influxdb.VariableFilter{
 			OrganizationID: &orgID,
@@ -735,29 +1673,26 @@ VarLoop:
 			//  since names are unique for vars within an org, meanwhile, make large limit
 			// 	returned vars, should be more than enough for the time being.
 		}, influxdb.FindOptions{Limit: 100})
+		pkgVar.existing = nil
 		switch {
 		case err == nil && len(existingLabels) > 0:
-			for i := range existingLabels {
-				existingVar := existingLabels[i]
-				if existingVar.Name != pkgVar.Name() {
-					continue
-				}
+			if existingVar := findVariable(existingLabels, pkgVar); existingVar != nil {
 				pkgVar.existing = existingVar
-				mExistingLabels[pkgVar.Name()] = newDiffVariable(pkgVar, existingVar)
-				continue VarLoop
+				diffs[i] = newDiffVariable(pkgVar, existingVar)
+				return nil
 			}
 			// fallthrough here for when the variable is not found, it'll fall to the
 			// default case and add it as new.
 			fallthrough
 		default:
-			mExistingLabels[pkgVar.Name()] = newDiffVariable(pkgVar, nil)
+			diffs[i] = newDiffVariable(pkgVar, nil)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	diffs := make([]DiffVariable, 0, len(mExistingLabels))
-	for _, diff := range mExistingLabels {
-		diffs = append(diffs, diff)
-	}
 	sort.Slice(diffs, func(i, j int) bool {
 		return diffs[i].Name < diffs[j].Name
 	})
@@ -765,6 +1700,21 @@ VarLoop:
 	return diffs, nil
 }
 
+// findVariable finds the existing variable of the pkg variable by its name,
+// or by its display name when it was renamed by a previous apply.
+func findVariable(existing []*influxdb.Variable, v *variable) *influxdb.Variable {
+	var renamed *influxdb.Variable
+	for _, iv := range existing {
+		switch iv.Name {
+		case v.Name():
+			return iv
+		case v.platformName():
+			renamed = iv
+		}
+	}
+	return renamed
+}
+
 type (
 	labelMappingDiffFn func(labelID influxdb.ID, labelName string, isNew bool)
 
@@ -783,19 +1733,36 @@ type (
 )
 
 func (s *Service) dryRunLabelMappings(ctx context.Context, pkg *Pkg) ([]DiffLabelMapping, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
 	mappers := []labelMappers{
 		mapperBuckets(pkg.buckets()),
+		mapperChecks(pkg.checks()),
 		mapperDashboards(pkg.mDashboards),
 		mapperNotificationEndpoints(pkg.notificationEndpoints()),
+		mapperNotificationRules(pkg.notificationRules()),
+		mapperScraperTargets(pkg.scraperTargets()),
+		mapperTasks(pkg.mTasks),
 		mapperTelegrafs(pkg.mTelegrafs),
 		mapperVariables(pkg.variables()),
 	}
 
-	var diffs []DiffLabelMapping
+	var associations []labelAssociater
 	for _, mapper := range mappers {
 		for i := 0; i < mapper.Len(); i++ {
-			la := mapper.Association(i)
-			err := s.dryRunResourceLabelMapping(ctx, la, func(labelID influxdb.ID, labelName string, isNew bool) {
+			associations = append(associations, mapper.Association(i))
+		}
+	}
+
+	// the labels and diffs are shared by all associations, the mutex guards
+	// them while the associations are looked up concurrently.
+	mutex := new(doMutex)
+	var diffs []DiffLabelMapping
+	err := s.dryRunEach(ctx, len(associations), func(ctx context.Context, i int) error {
+		la := associations[i]
+		return s.dryRunResourceLabelMapping(ctx, la, func(labelID influxdb.ID, labelName string, isNew bool) {
+			mutex.Do(func() {
 				pkg.mLabels[labelName].setMapping(la, !isNew)
 				diffs = append(diffs, DiffLabelMapping{
 					IsNew:     isNew,
@@ -806,28 +1773,27 @@ func (s *Service) dryRunLabelMappings(ctx context.Context, pkg *Pkg) ([]DiffLabe
 					LabelName: labelName,
 				})
 			})
-			if err != nil {
-				return nil, err
-			}
-		}
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// sort by res type ASC, then res name ASC, then label name ASC
+	// sort by res type ASC, then res name ASC, then label name ASC, then
+	// res ID ASC for resources sharing a name. The diffs are collected
+	// concurrently, so the order must not depend on the order they are added.
 	sort.Slice(diffs, func(i, j int) bool {
 		n, m := diffs[i], diffs[j]
-		if n.ResType < m.ResType {
-			return true
+		if n.ResType != m.ResType {
+			return n.ResType < m.ResType
 		}
-		if n.ResType > m.ResType {
-			return false
+		if n.ResName != m.ResName {
+			return n.ResName < m.ResName
 		}
-		if n.ResName < m.ResName {
-			return true
+		if n.LabelName != m.LabelName {
+			return n.LabelName < m.LabelName
 		}
-		if n.ResName > m.ResName {
-			return false
-		}
-		return n.LabelName < m.LabelName
+		return n.ResID < m.ResID
 	})
 
 	return diffs, nil
@@ -868,53 +1834,275 @@ func (s *Service) dryRunResourceLabelMapping(ctx context.Context, la labelAssoci
 	return nil
 }
 
+// dryRunEach calls fn for entries [0, n) concurrently, with no more than
+// applyReqLimit calls in flight at once. Once a call fails the remaining
+// calls are canceled and the first error is returned.
+func (s *Service) dryRunEach(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, s.applyReqLimit)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+
+		go func(i int) {
+			defer func() {
+				wg.Done()
+				<-sem
+			}()
+
+			if err := fn(ctx, i); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
 // Apply will apply all the resources identified in the provided pkg. The entire pkg will be applied
 // in its entirety. If a failure happens midway then the entire pkg will be rolled back to the state
-// from before the pkg were applied.
-func (s *Service) Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg) (sum Summary, e error) {
-	if !pkg.isParsed {
-		if err := pkg.Validate(); err != nil {
-			return Summary{}, err
-		}
+// from before the pkg were applied, unless applied with ApplyWithBestEffort.
+// Dry runs and applies of the same pkg are safe to call concurrently, they run
+// one at a time.
+func (s *Service) Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) (Summary, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := ctx.Err(); err != nil {
+		return Summary{}, err
 	}
 
-	if !pkg.isVerified {
-		_, _, err := s.DryRun(ctx, orgID, userID, pkg)
-		if err != nil {
-			return Summary{}, err
-		}
+	opt, err := newApplyOpt(opts...)
+	if err != nil {
+		return Summary{}, err
 	}
 
-	coordinator := &rollbackCoordinator{sem: make(chan struct{}, s.applyReqLimit)}
-	defer coordinator.rollback(s.log, &e)
+	pkg.mu.Lock()
+	sum, err := s.apply(ctx, orgID, userID, pkg, opt)
+	pkg.mu.Unlock()
+	if err != nil {
+		return Summary{}, err
+	}
 
-	// each grouping here runs for its entirety, then returns an error that
-	// is indicative of running all appliers provided. For instance, the labels
-	// may have 1 variable fail and one of the buckets fails. The errors aggregate so
-	// the caller will be informed of both the failed label variable the failed bucket.
-	// the groupings here allow for steps to occur before exiting. The first step is
-	// adding the dependencies, resources that are associated by other resources. Then the
-	// primary resources. Here we get all the errors associated with them.
-	// If those are all good, then we run the secondary(dependent) resources which
-	// rely on the primary resources having been created.
-	appliers := [][]applier{
-		// want to make all dependencies for belwo donezo before moving on to resources
-		// that have dependencies on lables
-		{
-			// deps for primary resources
-			s.applyLabels(pkg.labels()),
-		},
-		{
-			// primary resources
-			s.applyVariables(pkg.variables()),
-			s.applyBuckets(pkg.buckets()),
-			s.applyDashboards(pkg.dashboards()),
-			s.applyNotificationEndpoints(pkg.notificationEndpoints()),
-			s.applyTelegrafs(pkg.telegrafs()),
-		},
+	if s.usageReporter != nil {
+		s.usageReporter.ReportApply(ctx, newApplyEvent(pkg.Metadata, sum))
 	}
+	return sum, nil
+}
 
-	for _, group := range appliers {
+// OrgApplyResult is the outcome of applying a pkg to one of the orgs of
+// ApplyToOrgs.
+type OrgApplyResult struct {
+	Summary Summary
+	Err     error
+}
+
+// ApplyToOrgs applies the pkg to each of the orgs, up to applyOrgsLimit orgs at
+// a time. The pkg is parsed and validated once, each org is dry run and applied
+// with its own copy of it, so the failure and rollback of one org does not
+// affect the others. The opts, i.e. the secrets, apply to every org. The outcome
+// of each org is returned by org and is recorded in the metrics and the
+// operation log of the org, an error is returned only when the pkg itself is
+// invalid.
+func (s *Service) ApplyToOrgs(ctx context.Context, userID influxdb.ID, pkg *Pkg, orgIDs []influxdb.ID, opts ...ApplyOptFn) (map[influxdb.ID]OrgApplyResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	pkg.mu.Lock()
+	var err error
+	if !pkg.isParsedCurrent() {
+		err = pkg.Validate()
+	}
+	pkg.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, s.applyOrgsLimit)
+		seen    = make(map[influxdb.ID]bool, len(orgIDs))
+		results = make(map[influxdb.ID]OrgApplyResult, len(orgIDs))
+	)
+	for _, orgID := range orgIDs {
+		// an org applied twice at once would collide with itself
+		if seen[orgID] {
+			continue
+		}
+		seen[orgID] = true
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(orgID influxdb.ID) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			res := s.applyToOrg(ctx, orgID, userID, pkg, opts...)
+			mu.Lock()
+			results[orgID] = res
+			mu.Unlock()
+		}(orgID)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+const (
+	orgPkgAppliedEvent     = "Pkg Applied"
+	orgPkgApplyFailedEvent = "Pkg Apply Failed"
+)
+
+func (s *Service) applyToOrg(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) OrgApplyResult {
+	log := s.log.With(
+		zap.String("pkg_name", pkg.Metadata.Name),
+		zap.String("org_id", orgID.String()),
+	)
+
+	start := s.timeGen.Now()
+	res := func() OrgApplyResult {
+		sum, err := s.Apply(ctx, orgID, userID, pkg.clone(), opts...)
+		if err != nil {
+			return OrgApplyResult{Err: err}
+		}
+		return OrgApplyResult{Summary: sum}
+	}()
+	s.metrics.orgApplied(res.Err, s.timeGen.Now().Sub(start))
+
+	event := orgPkgAppliedEvent
+	if res.Err != nil {
+		log.Error("Failed to apply pkg to org", zap.Error(res.Err))
+		event = orgPkgApplyFailedEvent
+	} else {
+		log.Info("Applied pkg to org")
+	}
+
+	if s.orgOpLog != nil {
+		desc := fmt.Sprintf("%s: %s", event, pkg.Metadata.Name)
+		if err := s.orgOpLog.AddOrganizationOperationLogEntry(ctx, orgID, desc); err != nil {
+			log.Warn("Failed to record the pkg applied in the operation log of the org", zap.Error(err))
+		}
+	}
+	return res
+}
+
+func (s *Service) apply(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg, opt ApplyOpt) (sum Summary, e error) {
+	coordinator := s.newRollbackCoordinator(opt)
+	defer coordinator.rollback(s.log, &e)
+
+	return s.applyWith(ctx, coordinator, orgID, userID, pkg, opt)
+}
+
+// applyWith applies the pkg, the resources applied are recorded with the
+// coordinator and are rolled back by it, it is up to the caller to do so.
+func (s *Service) applyWith(ctx context.Context, coordinator *rollbackCoordinator, orgID, userID influxdb.ID, pkg *Pkg, opt ApplyOpt) (Summary, error) {
+	if !pkg.isParsedCurrent() {
+		if err := pkg.Validate(); err != nil {
+			return Summary{}, err
+		}
+	}
+
+	if opt.Snapshot != nil {
+		return Summary{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "pkg cannot be applied against a snapshot, a snapshot is only for dry runs",
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return Summary{}, err
+	}
+
+	if !pkg.isVerifiedWith(opt) {
+		_, _, err := s.dryRun(ctx, orgID, pkg, opt)
+		if err != nil {
+			return Summary{}, err
+		}
+	}
+
+	collided := collisions(pkg, opt.collisionStrategy())
+	var skipped collisionSet
+	switch opt.collisionStrategy() {
+	case CollisionFail:
+		if len(collided) > 0 {
+			return Summary{}, collisionsErr(collided)
+		}
+	case CollisionSkip:
+		skipped = newCollisionSet(collided)
+	}
+
+	// the unchanged resources are left out of the appliers, their label
+	// mappings are still applied, the existing mappings are skipped by them.
+	var unchanged []SummaryResourceStatus
+	if opt.OnlyChanged {
+		unchanged = unchangedResources(pkg, skipped)
+	}
+	excluded := skipped.union(newUnchangedSet(unchanged))
+
+	// each grouping here runs for its entirety, then returns an error that
+	// is indicative of running all appliers provided. For instance, the labels
+	// may have 1 variable fail and one of the buckets fails. The errors aggregate so
+	// the caller will be informed of both the failed label variable the failed bucket.
+	// the groupings here allow for steps to occur before exiting. The first step is
+	// adding the dependencies, resources that are associated by other resources. Then the
+	// primary resources. Here we get all the errors associated with them.
+	// If those are all good, then we run the secondary(dependent) resources which
+	// rely on the primary resources having been created.
+	appliers := [][]applier{
+		// want to make all dependencies for belwo donezo before moving on to resources
+		// that have dependencies on lables
+		{
+			// deps for primary resources
+			s.applyLabels(excluded.labels(pkg.labels())),
+			s.applySecrets(opt.MissingSecrets),
+		},
+		{
+			// primary resources
+			s.applyVariables(excluded.variables(pkg.variables())),
+			s.applyBuckets(excluded.buckets(pkg.buckets())),
+			s.applyDashboards(changedDashboards(pkg.dashboards())),
+			s.applyNotificationEndpoints(excluded.notificationEndpoints(pkg.notificationEndpoints())),
+			s.applyTasks(pkg.tasks()),
+			s.applyTelegrafs(changedTelegrafs(pkg.telegrafs())),
+		},
+		{
+			// resources depending on primary resources, scraper targets
+			// write to the buckets of the pkg by id, checks query them and
+			// notification rules notify the endpoints of the pkg by id
+			s.applyChecks(excluded.checks(pkg.checks())),
+			s.applyNotificationRules(excluded.notificationRules(pkg.notificationRules()), pkg.mNotificationEndpoints),
+			s.applyScraperTargets(excluded.scraperTargets(pkg.scraperTargets()), pkg.mBuckets),
+		},
+	}
+
+	for _, group := range appliers {
+		// the groups applied before a ctx is done are rolled back
+		if err := ctx.Err(); err != nil {
+			return Summary{}, err
+		}
 		if err := coordinator.runTilEnd(ctx, orgID, userID, group...); err != nil {
 			return Summary{}, err
 		}
@@ -922,58 +2110,142 @@ func (s *Service) Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg
 
 	// secondary resources
 	// this last grouping relies on the above 2 steps having completely successfully
-	secondary := []applier{s.applyLabelMappings(pkg.labelMappings())}
+	labelMappings := skipped.labelMappings(pkg.labelMappings())
+	if opt.BestEffort {
+		labelMappings = appliedLabelMappings(labelMappings)
+	}
+	secondary := []applier{s.applyLabelMappings(labelMappings)}
+	if err := ctx.Err(); err != nil {
+		return Summary{}, err
+	}
 	if err := coordinator.runTilEnd(ctx, orgID, userID, secondary...); err != nil {
 		return Summary{}, err
 	}
 
-	return pkg.Summary(), nil
+	if opt.Verify {
+		if err := s.verifyApplied(ctx, pkg, skipped); err != nil {
+			return Summary{}, err
+		}
+	}
+
+	sum := pkg.Summary()
+	sum.Statuses = append(coordinator.resourceStatuses(), unchanged...)
+	for _, c := range collided {
+		if skipped.has(c.Kind.ResourceType(), c.Name) {
+			sum.Skipped = append(sum.Skipped, SummarySkippedResource{
+				Kind:   c.Kind,
+				Name:   c.Name,
+				Reason: "name collides with an existing resource",
+			})
+			sum.Statuses = append(sum.Statuses, SummaryResourceStatus{
+				Kind:   c.Kind,
+				Name:   c.Name,
+				Status: ApplyStatusSkipped,
+			})
+		}
+	}
+	sortResourceStatuses(sum.Statuses)
+	sum.StatusCounts = countResourceStatuses(sum.Statuses)
+	sum.Errors = coordinator.failures()
+	sum.ContentHash = pkg.parsedHash
+	return sum, nil
 }
 
-func (s *Service) applyBuckets(buckets []*bucket) applier {
-	const resource = "bucket"
+func (s *Service) applySecrets(secrets map[string]string) applier {
+	const resource = "secrets"
 
-	mutex := new(doMutex)
-	rollbackBuckets := make([]*bucket, 0, len(buckets))
+	var (
+		createdOrgID influxdb.ID
+		created      []string
+	)
+	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) (applyResult, *applyErrBody) {
+		existing, err := s.secretSVC.GetSecretKeys(ctx, orgID)
+		if err != nil {
+			return applyResult{}, &applyErrBody{name: resource, msg: err.Error()}
+		}
 
-	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
-		var b bucket
-		mutex.Do(func() {
-			buckets[i].OrgID = orgID
-			b = *buckets[i]
-		})
-		if !b.shouldApply() {
-			return nil
+		missing := make(map[string]string, len(secrets))
+		for k, v := range secrets {
+			missing[k] = v
+		}
+		for _, k := range existing {
+			delete(missing, k)
+		}
+		if len(missing) == 0 {
+			return applyResult{}, nil
 		}
 
-		influxBucket, err := s.applyBucket(ctx, b)
-		if err != nil {
-			return &applyErrBody{
-				name: b.Name(),
-				msg:  err.Error(),
-			}
+		// patched rather than put, putting the secrets removes those of the
+		// org that are not provided.
+		if err := s.secretSVC.PatchSecrets(ctx, orgID, missing); err != nil {
+			return applyResult{}, &applyErrBody{name: resource, msg: err.Error()}
 		}
 
-		mutex.Do(func() {
-			buckets[i].id = influxBucket.ID
-			rollbackBuckets = append(rollbackBuckets, buckets[i])
-		})
+		createdOrgID = orgID
+		for k := range missing {
+			created = append(created, k)
+		}
+		return applyResult{}, nil
+	}
 
-		return nil
+	// all missing secrets are put in a single request
+	entries := 1
+	if len(secrets) == 0 {
+		entries = 0
 	}
 
 	return applier{
 		creater: creater{
-			entries: len(buckets),
+			entries: entries,
 			fn:      createFn,
 		},
 		rollbacker: rollbacker{
 			resource: resource,
-			fn:       func() error { return s.rollbackBuckets(rollbackBuckets) },
+			fn: func() error {
+				if len(created) == 0 {
+					return nil
+				}
+				return s.secretSVC.DeleteSecret(context.Background(), createdOrgID, created...)
+			},
 		},
 	}
 }
 
+func (s *Service) applyBuckets(buckets []*bucket) applier {
+	copies := make([]bucket, len(buckets))
+
+	return newApplier(applierHooks{
+		kind:     KindBucket,
+		resource: "bucket",
+		entries:  len(buckets),
+		prepare: func(i int, orgID influxdb.ID) {
+			buckets[i].OrgID = orgID
+			copies[i] = *buckets[i]
+		},
+		name:        func(i int) string { return buckets[i].Name() },
+		id:          func(i int) influxdb.ID { return buckets[i].ID() },
+		shouldApply: func(i int) bool { return copies[i].shouldApply() },
+		exists:      func(i int) bool { return copies[i].existing != nil },
+		create: func(ctx context.Context, i int, _ influxdb.ID) (influxdb.ID, bool, error) {
+			return s.createBucket(ctx, &copies[i])
+		},
+		update: func(ctx context.Context, i int, _ influxdb.ID) (influxdb.ID, error) {
+			return s.updateBucket(ctx, &copies[i])
+		},
+		setID: func(i int, id influxdb.ID) {
+			buckets[i].id = id
+			buckets[i].existing = copies[i].existing
+		},
+		rollback: func(applied []int) error {
+			rollbackBuckets := make([]*bucket, 0, len(applied))
+			for _, i := range applied {
+				rollbackBuckets = append(rollbackBuckets, buckets[i])
+			}
+			return s.rollbackBuckets(rollbackBuckets)
+		},
+	})
+}
+
 func (s *Service) rollbackBuckets(buckets []*bucket) error {
 	var errs []string
 	for _, b := range buckets {
@@ -985,10 +2257,10 @@ func (s *Service) rollbackBuckets(buckets []*bucket) error {
 			continue
 		}
 
-		rp := b.RetentionRules.RP()
 		_, err := s.bucketSVC.UpdateBucket(context.Background(), b.ID(), influxdb.BucketUpdate{
-			Description:     &b.Description,
-			RetentionPeriod: &rp,
+			Name:            &b.existing.Name,
+			Description:     &b.existing.Description,
+			RetentionPeriod: &b.existing.RetentionPeriod,
 		})
 		if err != nil {
 			errs = append(errs, b.ID().String())
@@ -1003,91 +2275,94 @@ func (s *Service) rollbackBuckets(buckets []*bucket) error {
 	return nil
 }
 
-func (s *Service) applyBucket(ctx context.Context, b bucket) (influxdb.Bucket, error) {
-	rp := b.RetentionRules.RP()
-	if b.existing != nil {
-		influxBucket, err := s.bucketSVC.UpdateBucket(ctx, b.ID(), influxdb.BucketUpdate{
-			Description:     &b.Description,
-			RetentionPeriod: &rp,
-		})
-		if err != nil {
-			return influxdb.Bucket{}, err
-		}
-		return *influxBucket, nil
-	}
-
+// createBucket creates the bucket, it returns true when the bucket turns out
+// to exist and is to be updated instead.
+func (s *Service) createBucket(ctx context.Context, b *bucket) (influxdb.ID, bool, error) {
 	influxBucket := influxdb.Bucket{
 		OrgID:           b.OrgID,
 		Description:     b.Description,
-		Name:            b.Name(),
-		RetentionPeriod: rp,
-	}
-	err := s.bucketSVC.CreateBucket(ctx, &influxBucket)
+		Name:            b.platformName(),
+		RetentionPeriod: b.RetentionRules.RP(),
+	}
+	exists, err := retryCreateConflict(
+		func() error { return s.bucketSVC.CreateBucket(ctx, &influxBucket) },
+		func() (bool, error) {
+			existing, err := s.bucketSVC.FindBucketByName(ctx, b.OrgID, b.platformName())
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			b.existing = existing
+			return true, nil
+		},
+	)
 	if err != nil {
-		return influxdb.Bucket{}, err
+		return 0, false, err
 	}
+	return influxBucket.ID, exists, nil
+}
 
-	return influxBucket, nil
+func (s *Service) updateBucket(ctx context.Context, b *bucket) (influxdb.ID, error) {
+	name := b.platformName()
+	rp := b.RetentionRules.RP()
+	_, err := s.bucketSVC.UpdateBucket(ctx, b.ID(), influxdb.BucketUpdate{
+		Name:            &name,
+		Description:     &b.Description,
+		RetentionPeriod: &rp,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return b.ID(), nil
 }
 
 func (s *Service) applyDashboards(dashboards []*dashboard) applier {
-	const resource = "dashboard"
+	copies := make([]dashboard, len(dashboards))
 
-	mutex := new(doMutex)
-	rollbackDashboards := make([]*dashboard, 0, len(dashboards))
-
-	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
-		var d dashboard
-		mutex.Do(func() {
+	return newApplier(applierHooks{
+		kind:     KindDashboard,
+		resource: "dashboard",
+		entries:  len(dashboards),
+		prepare: func(i int, orgID influxdb.ID) {
 			dashboards[i].OrgID = orgID
-			d = *dashboards[i]
-		})
-
-		influxBucket, err := s.applyDashboard(ctx, d)
-		if err != nil {
-			return &applyErrBody{
-				name: d.Name(),
-				msg:  err.Error(),
-			}
-		}
-
-		mutex.Do(func() {
-			dashboards[i].id = influxBucket.ID
-			rollbackDashboards = append(rollbackDashboards, dashboards[i])
-		})
-		return nil
-	}
-
-	return applier{
-		creater: creater{
-			entries: len(dashboards),
-			fn:      createFn,
+			copies[i] = *dashboards[i]
 		},
-		rollbacker: rollbacker{
-			resource: resource,
-			fn: func() error {
-				return s.deleteByIDs("dashboard", len(rollbackDashboards), s.dashSVC.DeleteDashboard, func(i int) influxdb.ID {
-					return rollbackDashboards[i].ID()
-				})
-			},
+		name: func(i int) string { return dashboards[i].Name() },
+		id:   func(i int) influxdb.ID { return dashboards[i].ID() },
+		create: func(ctx context.Context, i int, _ influxdb.ID) (influxdb.ID, bool, error) {
+			id, err := s.createDashboard(ctx, copies[i])
+			return id, false, err
 		},
-	}
+		setID: func(i int, id influxdb.ID) {
+			dashboards[i].id = id
+		},
+		rollback: func(applied []int) error {
+			return s.deleteByIDs("dashboard", len(applied), s.dashSVC.DeleteDashboard, func(i int) influxdb.ID {
+				return dashboards[applied[i]].ID()
+			})
+		},
+	})
 }
 
-func (s *Service) applyDashboard(ctx context.Context, d dashboard) (influxdb.Dashboard, error) {
+func (s *Service) createDashboard(ctx context.Context, d dashboard) (influxdb.ID, error) {
 	cells := convertChartsToCells(d.Charts)
 	influxDashboard := influxdb.Dashboard{
 		OrganizationID: d.OrgID,
 		Description:    d.Description,
 		Name:           d.Name(),
 		Cells:          cells,
+		Meta: influxdb.DashboardMeta{
+			RefreshInterval: durationPtr(d.RefreshInterval),
+		},
 	}
 	err := s.dashSVC.CreateDashboard(ctx, &influxDashboard)
 	if err != nil {
-		return influxdb.Dashboard{}, err
+		return 0, err
 	}
 
-	return influxDashboard, nil
+	return influxDashboard.ID, nil
 }
 
 func convertChartsToCells(ch []chart) []*influxdb.Cell {
@@ -1095,10 +2370,11 @@ func convertChartsToCells(ch []chart) []*influxdb.Cell {
 	for _, c := range ch {
 		icell := &influxdb.Cell{
 			CellProperty: influxdb.CellProperty{
-				X: int32(c.XPos),
-				Y: int32(c.YPos),
-				H: int32(c.Height),
-				W: int32(c.Width),
+				X:               int32(c.XPos),
+				Y:               int32(c.YPos),
+				H:               int32(c.Height),
+				W:               int32(c.Width),
+				RefreshInterval: durationPtr(c.RefreshInterval),
 			},
 			View: &influxdb.View{
 				ViewContents: influxdb.ViewContents{Name: c.Name},
@@ -1111,47 +2387,38 @@ func convertChartsToCells(ch []chart) []*influxdb.Cell {
 }
 
 func (s *Service) applyLabels(labels []*label) applier {
-	const resource = "label"
+	copies := make([]label, len(labels))
 
-	mutex := new(doMutex)
-	rollBackLabels := make([]*label, 0, len(labels))
-
-	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
-		var l label
-		mutex.Do(func() {
+	return newApplier(applierHooks{
+		kind:     KindLabel,
+		resource: "label",
+		entries:  len(labels),
+		prepare: func(i int, orgID influxdb.ID) {
 			labels[i].OrgID = orgID
-			l = *labels[i]
-		})
-		if !l.shouldApply() {
-			return nil
-		}
-
-		influxLabel, err := s.applyLabel(ctx, l)
-		if err != nil {
-			return &applyErrBody{
-				name: l.Name(),
-				msg:  err.Error(),
-			}
-		}
-
-		mutex.Do(func() {
-			labels[i].id = influxLabel.ID
-			rollBackLabels = append(rollBackLabels, labels[i])
-		})
-
-		return nil
-	}
-
-	return applier{
-		creater: creater{
-			entries: len(labels),
-			fn:      createFn,
+			copies[i] = *labels[i]
 		},
-		rollbacker: rollbacker{
-			resource: resource,
-			fn:       func() error { return s.rollbackLabels(rollBackLabels) },
+		name:        func(i int) string { return labels[i].Name() },
+		id:          func(i int) influxdb.ID { return labels[i].ID() },
+		shouldApply: func(i int) bool { return copies[i].shouldApply() },
+		exists:      func(i int) bool { return copies[i].existing != nil },
+		create: func(ctx context.Context, i int, _ influxdb.ID) (influxdb.ID, bool, error) {
+			return s.createLabel(ctx, &copies[i])
 		},
-	}
+		update: func(ctx context.Context, i int, _ influxdb.ID) (influxdb.ID, error) {
+			return s.updateLabel(ctx, &copies[i])
+		},
+		setID: func(i int, id influxdb.ID) {
+			labels[i].id = id
+			labels[i].existing = copies[i].existing
+		},
+		rollback: func(applied []int) error {
+			rollBackLabels := make([]*label, 0, len(applied))
+			for _, i := range applied {
+				rollBackLabels = append(rollBackLabels, labels[i])
+			}
+			return s.rollbackLabels(rollBackLabels)
+		},
+	})
 }
 
 func (s *Service) rollbackLabels(labels []*label) error {
@@ -1166,6 +2433,7 @@ func (s *Service) rollbackLabels(labels []*label) error {
 		}
 
 		_, err := s.labelSVC.UpdateLabel(context.Background(), l.ID(), influxdb.LabelUpdate{
+			Name:       l.existing.Name,
 			Properties: l.existing.Properties,
 		})
 		if err != nil {
@@ -1180,50 +2448,226 @@ func (s *Service) rollbackLabels(labels []*label) error {
 	return nil
 }
 
-func (s *Service) applyLabel(ctx context.Context, l label) (influxdb.Label, error) {
-	if l.existing != nil {
-		updatedlabel, err := s.labelSVC.UpdateLabel(ctx, l.ID(), influxdb.LabelUpdate{
-			Properties: l.properties(),
-		})
-		if err != nil {
-			return influxdb.Label{}, err
-		}
-		return *updatedlabel, nil
+// createLabel creates the label, it returns true when the label turns out to
+// exist and is to be updated instead.
+func (s *Service) createLabel(ctx context.Context, l *label) (influxdb.ID, bool, error) {
+	influxLabel := l.toInfluxLabel()
+	exists, err := retryCreateConflict(
+		func() error { return s.labelSVC.CreateLabel(ctx, &influxLabel) },
+		func() (bool, error) {
+			existingLabels, err := s.labelSVC.FindLabels(ctx, influxdb.LabelFilter{
+				Name:  l.platformName(),
+				OrgID: &l.OrgID,
+			}, influxdb.FindOptions{Limit: 1})
+			if err != nil || len(existingLabels) == 0 {
+				return false, err
+			}
+			l.existing = existingLabels[0]
+			return true, nil
+		},
+	)
+	if err != nil {
+		return 0, false, err
 	}
+	return influxLabel.ID, exists, nil
+}
 
-	influxLabel := l.toInfluxLabel()
-	err := s.labelSVC.CreateLabel(ctx, &influxLabel)
+func (s *Service) updateLabel(ctx context.Context, l *label) (influxdb.ID, error) {
+	// only the properties changed by the pkg are updated, the same properties
+	// the diff of the label marks changed.
+	props := newDiffLabelProperties(l, *l.existing)
+	upd := influxdb.LabelUpdate{
+		Properties: make(map[string]string),
+	}
+	if props.Name.Changed {
+		upd.Name = props.Name.New
+	}
+	if props.Color.Changed {
+		upd.Properties["color"] = props.Color.New
+	}
+	if props.Description.Changed {
+		upd.Properties["description"] = props.Description.New
+	}
+
+	updatedlabel, err := s.labelSVC.UpdateLabel(ctx, l.ID(), upd)
 	if err != nil {
-		return influxdb.Label{}, err
+		return 0, err
 	}
+	return updatedlabel.ID, nil
+}
+
+func (s *Service) applyChecks(checks []*check) applier {
+	copies := make([]check, len(checks))
 
-	return influxLabel, nil
+	return newApplier(applierHooks{
+		kind:     KindCheck,
+		resource: "check",
+		entries:  len(checks),
+		prepare: func(i int, orgID influxdb.ID) {
+			checks[i].OrgID = orgID
+			copies[i] = *checks[i]
+		},
+		name: func(i int) string { return checks[i].Name() },
+		id:   func(i int) influxdb.ID { return checks[i].ID() },
+		load: func(ctx context.Context, i int) error {
+			if copies[i].existing == nil {
+				return nil
+			}
+			status, err := s.taskStatus(ctx, copies[i].existing.GetTaskID())
+			if err != nil {
+				return err
+			}
+			copies[i].existingStatus = status
+			return nil
+		},
+		shouldApply: func(i int) bool { return copies[i].shouldApply() },
+		exists:      func(i int) bool { return copies[i].existing != nil },
+		create: func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, bool, error) {
+			return s.createCheck(ctx, &copies[i], userID)
+		},
+		update: func(ctx context.Context, i int, _ influxdb.ID) (influxdb.ID, error) {
+			return s.updateCheck(ctx, &copies[i])
+		},
+		setID: func(i int, id influxdb.ID) {
+			checks[i].id = id
+			checks[i].existing = copies[i].existing
+			checks[i].existingStatus = copies[i].existingStatus
+		},
+		rollback: func(applied []int) error {
+			rollbackChecks := make([]*check, 0, len(applied))
+			for _, i := range applied {
+				rollbackChecks = append(rollbackChecks, checks[i])
+			}
+			return s.rollbackChecks(rollbackChecks)
+		},
+	})
 }
 
-func (s *Service) applyNotificationEndpoints(endpoints []*notificationEndpoint) applier {
-	const resource = "notification_endpoints"
+// createCheck creates the check, it returns true when the check turns out to
+// exist and is to be updated instead.
+func (s *Service) createCheck(ctx context.Context, c *check, userID influxdb.ID) (influxdb.ID, bool, error) {
+	sum := c.summarize()
+	checkStub := influxdb.CheckCreate{
+		Check:  sum.Check,
+		Status: sum.Status,
+	}
+	exists, err := retryCreateConflict(
+		func() error { return s.checkSVC.CreateCheck(ctx, checkStub, userID) },
+		func() (bool, error) {
+			name := c.platformName()
+			existing, err := s.checkSVC.FindCheck(ctx, influxdb.CheckFilter{
+				Name:  &name,
+				OrgID: &c.OrgID,
+			})
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			status, err := s.taskStatus(ctx, existing.GetTaskID())
+			if err != nil {
+				return false, err
+			}
+			c.existing, c.existingStatus = existing, status
+			return true, nil
+		},
+	)
+	if err != nil {
+		return 0, false, err
+	}
+	return checkStub.Check.GetID(), exists, nil
+}
 
-	mutex := new(doMutex)
-	rollbackEndpoints := make([]*notificationEndpoint, 0, len(endpoints))
+func (s *Service) updateCheck(ctx context.Context, c *check) (influxdb.ID, error) {
+	sum := c.summarize()
+	_, err := s.checkSVC.UpdateCheck(ctx, c.ID(), influxdb.CheckCreate{
+		Check:  sum.Check,
+		Status: sum.Status,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return c.ID(), nil
+}
 
-	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
-		var endpoint notificationEndpoint
-		mutex.Do(func() {
-			endpoints[i].OrgID = orgID
-			endpoint = *endpoints[i]
-		})
+// taskStatus returns the status of the task of an existing check or
+// notification rule, their status is kept by their task. Without a task service
+// the status can't be read, and so can't be restored by a rollback.
+func (s *Service) taskStatus(ctx context.Context, taskID influxdb.ID) (influxdb.Status, error) {
+	if s.taskSVC == nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "no task service to read the status of the existing resource with",
+		}
+	}
+	t, err := s.taskSVC.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return "", err
+	}
+	return influxdb.Status(t.Status), nil
+}
 
-		influxEndpoint, err := s.applyNotificationEndpoint(ctx, endpoint, userID)
-		if err != nil {
-			return &applyErrBody{
-				name: endpoint.Name(),
-				msg:  err.Error(),
+func (s *Service) rollbackChecks(checks []*check) error {
+	var errs []string
+	for _, c := range checks {
+		if c.existing == nil {
+			if err := s.checkSVC.DeleteCheck(context.Background(), c.ID()); err != nil {
+				errs = append(errs, c.ID().String())
 			}
+			continue
 		}
 
-		mutex.Do(func() {
-			endpoints[i].id = influxEndpoint.GetID()
-			for _, secret := range influxEndpoint.SecretFields() {
+		_, err := s.checkSVC.UpdateCheck(context.Background(), c.ID(), influxdb.CheckCreate{
+			Check:  c.existing,
+			Status: c.existingStatus,
+		})
+		if err != nil {
+			errs = append(errs, c.ID().String())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(`check_ids=[%s] err="unable to rollback check"`, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+func (s *Service) applyNotificationEndpoints(endpoints []*notificationEndpoint) applier {
+	copies := make([]notificationEndpoint, len(endpoints))
+	influxEndpoints := make([]influxdb.NotificationEndpoint, len(endpoints))
+
+	return newApplier(applierHooks{
+		kind:     KindNotificationEndpoint,
+		resource: "notification_endpoints",
+		entries:  len(endpoints),
+		prepare: func(i int, orgID influxdb.ID) {
+			endpoints[i].OrgID = orgID
+			copies[i] = *endpoints[i]
+		},
+		name:   func(i int) string { return endpoints[i].Name() },
+		id:     func(i int) influxdb.ID { return endpoints[i].ID() },
+		exists: func(i int) bool { return copies[i].existing != nil },
+		create: func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, bool, error) {
+			influxEndpoint, err := s.createNotificationEndpoint(ctx, copies[i], userID)
+			if err != nil {
+				return 0, false, err
+			}
+			influxEndpoints[i] = influxEndpoint
+			return influxEndpoint.GetID(), false, nil
+		},
+		update: func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, error) {
+			influxEndpoint, err := s.updateNotificationEndpoint(ctx, copies[i], userID)
+			if err != nil {
+				return 0, err
+			}
+			influxEndpoints[i] = influxEndpoint
+			return influxEndpoint.GetID(), nil
+		},
+		setID: func(i int, id influxdb.ID) {
+			endpoints[i].id = id
+			for _, secret := range influxEndpoints[i].SecretFields() {
 				switch {
 				case strings.HasSuffix(secret.Key, "-routing-key"):
 					endpoints[i].routingKey.Secret = secret.Key
@@ -1237,156 +2681,349 @@ func (s *Service) applyNotificationEndpoints(endpoints []*notificationEndpoint)
 					fmt.Println("no match for key: ", secret.Key)
 				}
 			}
-			rollbackEndpoints = append(rollbackEndpoints, endpoints[i])
-		})
+		},
+		rollback: func(applied []int) error {
+			rollbackEndpoints := make([]*notificationEndpoint, 0, len(applied))
+			for _, i := range applied {
+				rollbackEndpoints = append(rollbackEndpoints, endpoints[i])
+			}
+			return s.rollbackNotificationEndpoints(rollbackEndpoints)
+		},
+	})
+}
 
-		return nil
+func (s *Service) createNotificationEndpoint(ctx context.Context, e notificationEndpoint, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
+	actual := e.summarize().NotificationEndpoint
+	err := s.endpointSVC.CreateNotificationEndpoint(ctx, actual, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	return applier{
-		creater: creater{
-			entries: len(endpoints),
-			fn:      createFn,
+	return actual, nil
+}
+
+func (s *Service) updateNotificationEndpoint(ctx context.Context, e notificationEndpoint, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
+	// stub out userID since we're always using hte http client which will fill it in for us with the token
+	// feels a bit broken that is required.
+	// TODO: look into this userID requirement
+	update := e.existing
+	if name := e.platformName(); name != e.existing.GetName() {
+		// the existing endpoint is left untouched, rollback restores it.
+		renamed, err := copyEndpoint(e.existing)
+		if err != nil {
+			return nil, err
+		}
+		renamed.SetName(name)
+		update = renamed
+	}
+	updatedEndpoint, err := s.endpointSVC.UpdateNotificationEndpoint(ctx, e.ID(), update, userID)
+	if err != nil {
+		return nil, err
+	}
+	return updatedEndpoint, nil
+}
+
+func (s *Service) rollbackNotificationEndpoints(endpoints []*notificationEndpoint) error {
+	var errs []string
+	for _, e := range endpoints {
+		if e.existing == nil {
+			_, _, err := s.endpointSVC.DeleteNotificationEndpoint(context.Background(), e.ID())
+			if err != nil {
+				errs = append(errs, e.ID().String())
+			}
+			continue
+		}
+
+		_, err := s.endpointSVC.UpdateNotificationEndpoint(context.Background(), e.ID(), e.existing, 0)
+		if err != nil {
+			errs = append(errs, e.ID().String())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(`notication_endpoint_ids=[%s] err="unable to delete"`, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+func copyEndpoint(e influxdb.NotificationEndpoint) (influxdb.NotificationEndpoint, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return endpoint.UnmarshalJSON(b)
+}
+
+func (s *Service) applyNotificationRules(rules []*notificationRule, pkgEndpoints map[string]*notificationEndpoint) applier {
+	copies := make([]notificationRule, len(rules))
+
+	return newApplier(applierHooks{
+		kind:     KindNotificationRule,
+		resource: "notification_rules",
+		entries:  len(rules),
+		prepare: func(i int, orgID influxdb.ID) {
+			rules[i].OrgID = orgID
+			if e, ok := pkgEndpoints[rules[i].endpointName]; ok {
+				rules[i].endpointID = e.ID()
+			}
+			copies[i] = *rules[i]
 		},
-		rollbacker: rollbacker{
-			resource: resource,
-			fn: func() error {
-				return s.rollbackNotificationEndpoints(rollbackEndpoints)
-			},
+		name: func(i int) string { return rules[i].Name() },
+		id:   func(i int) influxdb.ID { return rules[i].ID() },
+		load: func(ctx context.Context, i int) error {
+			if copies[i].existing == nil {
+				return nil
+			}
+			status, err := s.taskStatus(ctx, copies[i].existing.GetTaskID())
+			if err != nil {
+				return err
+			}
+			copies[i].existingStatus = status
+			return nil
+		},
+		exists: func(i int) bool { return copies[i].existing != nil },
+		create: func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, bool, error) {
+			id, err := s.createNotificationRule(ctx, copies[i], userID)
+			return id, false, err
+		},
+		update: func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, error) {
+			return s.updateNotificationRule(ctx, copies[i], userID)
+		},
+		setID: func(i int, id influxdb.ID) {
+			rules[i].id = id
+			rules[i].existingStatus = copies[i].existingStatus
 		},
-	}
+		rollback: func(applied []int) error {
+			rollbackRules := make([]*notificationRule, 0, len(applied))
+			for _, i := range applied {
+				rollbackRules = append(rollbackRules, rules[i])
+			}
+			return s.rollbackNotificationRules(rollbackRules)
+		},
+	})
 }
 
-func (s *Service) applyNotificationEndpoint(ctx context.Context, e notificationEndpoint, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
-	if e.existing != nil {
-		// stub out userID since we're always using hte http client which will fill it in for us with the token
-		// feels a bit broken that is required.
-		// TODO: look into this userID requirement
-		updatedEndpoint, err := s.endpointSVC.UpdateNotificationEndpoint(ctx, e.ID(), e.existing, userID)
-		if err != nil {
-			return nil, err
-		}
-		return updatedEndpoint, nil
+func (s *Service) createNotificationRule(ctx context.Context, r notificationRule, userID influxdb.ID) (influxdb.ID, error) {
+	ruleCreate, err := r.toRuleCreate()
+	if err != nil {
+		return 0, err
 	}
+	if err := s.ruleSVC.CreateNotificationRule(ctx, ruleCreate, userID); err != nil {
+		return 0, err
+	}
+	return ruleCreate.NotificationRule.GetID(), nil
+}
 
-	actual := e.summarize().NotificationEndpoint
-	err := s.endpointSVC.CreateNotificationEndpoint(ctx, actual, userID)
+func (s *Service) updateNotificationRule(ctx context.Context, r notificationRule, userID influxdb.ID) (influxdb.ID, error) {
+	ruleCreate, err := r.toRuleCreate()
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-
-	return actual, nil
+	influxRule, err := s.ruleSVC.UpdateNotificationRule(ctx, r.ID(), ruleCreate, userID)
+	if err != nil {
+		return 0, err
+	}
+	return influxRule.GetID(), nil
 }
 
-func (s *Service) rollbackNotificationEndpoints(endpoints []*notificationEndpoint) error {
+func (s *Service) rollbackNotificationRules(rules []*notificationRule) error {
 	var errs []string
-	for _, e := range endpoints {
-		if e.existing == nil {
-			_, _, err := s.endpointSVC.DeleteNotificationEndpoint(context.Background(), e.ID())
-			if err != nil {
-				errs = append(errs, e.ID().String())
+	for _, r := range rules {
+		if r.existing == nil {
+			if err := s.ruleSVC.DeleteNotificationRule(context.Background(), r.ID()); err != nil {
+				errs = append(errs, r.ID().String())
 			}
 			continue
 		}
 
-		_, err := s.endpointSVC.UpdateNotificationEndpoint(context.Background(), e.ID(), e.existing, 0)
+		_, err := s.ruleSVC.UpdateNotificationRule(context.Background(), r.ID(), influxdb.NotificationRuleCreate{
+			NotificationRule: r.existing,
+			Status:           r.existingStatus,
+		}, r.existing.GetOwnerID())
 		if err != nil {
-			errs = append(errs, e.ID().String())
+			errs = append(errs, r.ID().String())
 		}
 	}
 
 	if len(errs) > 0 {
-		return fmt.Errorf(`notication_endpoint_ids=[%s] err="unable to delete"`, strings.Join(errs, ", "))
+		return fmt.Errorf(`notification_rule_ids=[%s] err="unable to rollback notification rule"`, strings.Join(errs, ", "))
 	}
 
 	return nil
 }
 
-func (s *Service) applyTelegrafs(teles []*telegraf) applier {
-	const resource = "telegrafs"
-
-	mutex := new(doMutex)
-	rollbackTelegrafs := make([]*telegraf, 0, len(teles))
+func (s *Service) applyScraperTargets(targets []*scraperTarget, pkgBuckets map[string]*bucket) applier {
+	copies := make([]scraperTarget, len(targets))
 
-	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
-		var cfg influxdb.TelegrafConfig
-		mutex.Do(func() {
-			teles[i].config.OrgID = orgID
-			cfg = teles[i].config
-		})
+	return newApplier(applierHooks{
+		kind:     KindScraperTarget,
+		resource: "scraper_target",
+		entries:  len(targets),
+		prepare: func(i int, orgID influxdb.ID) {
+			targets[i].OrgID = orgID
+			if b, ok := pkgBuckets[targets[i].bucket]; ok {
+				targets[i].bucketID = b.ID()
+			}
+			copies[i] = *targets[i]
+		},
+		name: func(i int) string { return targets[i].Name() },
+		id:   func(i int) influxdb.ID { return targets[i].ID() },
+		// the bucket of the copy is resolved by prepare.
+		shouldApply: func(i int) bool { return copies[i].shouldApply(nil) },
+		exists:      func(i int) bool { return copies[i].existing != nil },
+		create: func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, bool, error) {
+			influxTarget := copies[i].toInfluxTarget()
+			if err := s.scraperSVC.AddTarget(ctx, &influxTarget, userID); err != nil {
+				return 0, false, err
+			}
+			return influxTarget.ID, false, nil
+		},
+		update: func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, error) {
+			influxTarget := copies[i].toInfluxTarget()
+			if _, err := s.scraperSVC.UpdateTarget(ctx, &influxTarget, userID); err != nil {
+				return 0, err
+			}
+			return influxTarget.ID, nil
+		},
+		setID: func(i int, id influxdb.ID) {
+			targets[i].id = id
+		},
+		rollback: func(applied []int) error {
+			rollbackTargets := make([]*scraperTarget, 0, len(applied))
+			for _, i := range applied {
+				rollbackTargets = append(rollbackTargets, targets[i])
+			}
+			return s.rollbackScraperTargets(rollbackTargets)
+		},
+	})
+}
 
-		err := s.teleSVC.CreateTelegrafConfig(ctx, &cfg, userID)
-		if err != nil {
-			return &applyErrBody{
-				name: cfg.Name,
-				msg:  err.Error(),
+func (s *Service) rollbackScraperTargets(targets []*scraperTarget) error {
+	var errs []string
+	for _, t := range targets {
+		if t.existing == nil {
+			if err := s.scraperSVC.RemoveTarget(context.Background(), t.ID()); err != nil {
+				errs = append(errs, t.ID().String())
 			}
+			continue
 		}
 
-		mutex.Do(func() {
-			teles[i].config = cfg
-			rollbackTelegrafs = append(rollbackTelegrafs, teles[i])
-		})
+		existing := *t.existing
+		if _, err := s.scraperSVC.UpdateTarget(context.Background(), &existing, 0); err != nil {
+			errs = append(errs, t.ID().String())
+		}
+	}
 
-		return nil
+	if len(errs) > 0 {
+		return fmt.Errorf(`scraper_target_ids=[%s] err="unable to rollback scraper target"`, strings.Join(errs, ", "))
 	}
 
-	return applier{
-		creater: creater{
-			entries: len(teles),
-			fn:      createFn,
+	return nil
+}
+
+func (s *Service) applyTasks(tasks []*task) applier {
+	creates := make([]influxdb.TaskCreate, len(tasks))
+
+	return newApplier(applierHooks{
+		kind:     KindTask,
+		resource: "tasks",
+		entries:  len(tasks),
+		prepare: func(i int, orgID influxdb.ID) {
+			creates[i] = influxdb.TaskCreate{
+				Flux:           tasks[i].flux(),
+				Description:    tasks[i].description,
+				Status:         string(tasks[i].Status()),
+				OrganizationID: orgID,
+			}
 		},
-		rollbacker: rollbacker{
-			resource: resource,
-			fn: func() error {
-				return s.deleteByIDs("telegraf", len(rollbackTelegrafs), s.teleSVC.DeleteTelegrafConfig, func(i int) influxdb.ID {
-					return rollbackTelegrafs[i].ID()
-				})
-			},
+		name: func(i int) string { return tasks[i].Name() },
+		id:   func(i int) influxdb.ID { return tasks[i].ID() },
+		create: func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, bool, error) {
+			create := creates[i]
+			create.OwnerID = userID
+			t, err := s.taskSVC.CreateTask(ctx, create)
+			if err != nil {
+				return 0, false, err
+			}
+			return t.ID, false, nil
 		},
-	}
+		setID: func(i int, id influxdb.ID) {
+			tasks[i].id = id
+		},
+		rollback: func(applied []int) error {
+			// the task service is left nil by a service without tasks
+			if len(applied) == 0 {
+				return nil
+			}
+			return s.deleteByIDs("task", len(applied), s.taskSVC.DeleteTask, func(i int) influxdb.ID {
+				return tasks[applied[i]].ID()
+			})
+		},
+	})
 }
 
-func (s *Service) applyVariables(vars []*variable) applier {
-	const resource = "variable"
-
-	mutex := new(doMutex)
-	rollBackVars := make([]*variable, 0, len(vars))
+func (s *Service) applyTelegrafs(teles []*telegraf) applier {
+	configs := make([]influxdb.TelegrafConfig, len(teles))
 
-	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
-		var v variable
-		mutex.Do(func() {
-			vars[i].OrgID = orgID
-			v = *vars[i]
-		})
-		if !v.shouldApply() {
-			return nil
-		}
-		influxVar, err := s.applyVariable(ctx, v)
-		if err != nil {
-			return &applyErrBody{
-				name: v.Name(),
-				msg:  err.Error(),
-			}
-		}
+	return newApplier(applierHooks{
+		kind:     KindTelegraf,
+		resource: "telegrafs",
+		entries:  len(teles),
+		prepare: func(i int, orgID influxdb.ID) {
+			teles[i].config.OrgID = orgID
+			configs[i] = teles[i].config
+		},
+		name: func(i int) string { return teles[i].Name() },
+		id:   func(i int) influxdb.ID { return teles[i].ID() },
+		create: func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, bool, error) {
+			err := s.teleSVC.CreateTelegrafConfig(ctx, &configs[i], userID)
+			return configs[i].ID, false, err
+		},
+		setID: func(i int, id influxdb.ID) {
+			teles[i].config = configs[i]
+		},
+		rollback: func(applied []int) error {
+			return s.deleteByIDs("telegraf", len(applied), s.teleSVC.DeleteTelegrafConfig, func(i int) influxdb.ID {
+				return teles[applied[i]].ID()
+			})
+		},
+	})
+}
 
-		mutex.Do(func() {
-			vars[i].id = influxVar.ID
-			rollBackVars = append(rollBackVars, vars[i])
-		})
-		return nil
-	}
+func (s *Service) applyVariables(vars []*variable) applier {
+	copies := make([]variable, len(vars))
 
-	return applier{
-		creater: creater{
-			entries: len(vars),
-			fn:      createFn,
+	return newApplier(applierHooks{
+		kind:     KindVariable,
+		resource: "variable",
+		entries:  len(vars),
+		prepare: func(i int, orgID influxdb.ID) {
+			vars[i].OrgID = orgID
+			copies[i] = *vars[i]
 		},
-		rollbacker: rollbacker{
-			resource: resource,
-			fn:       func() error { return s.rollbackVariables(rollBackVars) },
+		name:        func(i int) string { return vars[i].Name() },
+		id:          func(i int) influxdb.ID { return vars[i].ID() },
+		shouldApply: func(i int) bool { return copies[i].shouldApply() },
+		exists:      func(i int) bool { return copies[i].existing != nil },
+		create: func(ctx context.Context, i int, _ influxdb.ID) (influxdb.ID, bool, error) {
+			return s.createVariable(ctx, &copies[i])
 		},
-	}
+		update: func(ctx context.Context, i int, _ influxdb.ID) (influxdb.ID, error) {
+			return s.updateVariable(ctx, &copies[i])
+		},
+		setID: func(i int, id influxdb.ID) {
+			vars[i].id = id
+			vars[i].existing = copies[i].existing
+		},
+		rollback: func(applied []int) error {
+			rollBackVars := make([]*variable, 0, len(applied))
+			for _, i := range applied {
+				rollBackVars = append(rollBackVars, vars[i])
+			}
+			return s.rollbackVariables(rollBackVars)
+		},
+	})
 }
 
 func (s *Service) rollbackVariables(variables []*variable) error {
@@ -1401,6 +3038,7 @@ func (s *Service) rollbackVariables(variables []*variable) error {
 		}
 
 		_, err := s.varSVC.UpdateVariable(context.Background(), v.ID(), &influxdb.VariableUpdate{
+			Name:        v.existing.Name,
 			Description: v.existing.Description,
 			Arguments:   v.existing.Arguments,
 		})
@@ -1416,30 +3054,78 @@ func (s *Service) rollbackVariables(variables []*variable) error {
 	return nil
 }
 
-func (s *Service) applyVariable(ctx context.Context, v variable) (influxdb.Variable, error) {
-	if v.existing != nil {
-		updatedVar, err := s.varSVC.UpdateVariable(ctx, v.ID(), &influxdb.VariableUpdate{
-			Description: v.Description,
-			Arguments:   v.influxVarArgs(),
-		})
-		if err != nil {
-			return influxdb.Variable{}, err
-		}
-		return *updatedVar, nil
-	}
-
+// createVariable creates the variable, it returns true when the variable
+// turns out to exist and is to be updated instead.
+func (s *Service) createVariable(ctx context.Context, v *variable) (influxdb.ID, bool, error) {
 	influxVar := influxdb.Variable{
 		OrganizationID: v.OrgID,
-		Name:           v.Name(),
+		Name:           v.platformName(),
 		Description:    v.Description,
 		Arguments:      v.influxVarArgs(),
 	}
-	err := s.varSVC.CreateVariable(ctx, &influxVar)
+	exists, err := retryCreateConflict(
+		func() error { return s.varSVC.CreateVariable(ctx, &influxVar) },
+		func() (bool, error) {
+			existingVars, err := s.varSVC.FindVariables(ctx, influxdb.VariableFilter{
+				OrganizationID: &v.OrgID,
+			}, influxdb.FindOptions{Limit: 100})
+			if err != nil {
+				return false, err
+			}
+			for _, existing := range existingVars {
+				if existing.Name == v.platformName() {
+					v.existing = existing
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	)
+	if err != nil {
+		return 0, false, err
+	}
+	return influxVar.ID, exists, nil
+}
+
+func (s *Service) updateVariable(ctx context.Context, v *variable) (influxdb.ID, error) {
+	updatedVar, err := s.varSVC.UpdateVariable(ctx, v.ID(), &influxdb.VariableUpdate{
+		Name:        v.platformName(),
+		Description: v.Description,
+		Arguments:   v.influxVarArgs(),
+	})
 	if err != nil {
-		return influxdb.Variable{}, err
+		return 0, err
 	}
+	return updatedVar.ID, nil
+}
 
-	return influxVar, nil
+// maxCreateConflictRetries bounds the attempts made to create a resource the
+// dry run reported as new.
+const maxCreateConflictRetries = 3
+
+// retryCreateConflict calls createFn to create a resource the dry run reported
+// as new. A conflict indicates the resource was created after the dry run, i.e.
+// by a concurrent apply of the same pkg. The resource is then looked up with
+// existsFn; when it exists the caller is to update it instead, otherwise the
+// competing create was rolled back and the create is retried.
+func retryCreateConflict(createFn func() error, existsFn func() (bool, error)) (bool, error) {
+	for attempt := 1; ; attempt++ {
+		err := createFn()
+		if err == nil {
+			return false, nil
+		}
+		if influxdb.ErrorCode(err) != influxdb.EConflict || attempt >= maxCreateConflictRetries {
+			return false, err
+		}
+
+		exists, findErr := existsFn()
+		if findErr != nil {
+			return false, findErr
+		}
+		if exists {
+			return true, nil
+		}
+	}
 }
 
 func (s *Service) applyLabelMappings(labelMappings []SummaryLabelMapping) applier {
@@ -1448,11 +3134,12 @@ func (s *Service) applyLabelMappings(labelMappings []SummaryLabelMapping) applie
 	mutex := new(doMutex)
 	rollbackMappings := make([]influxdb.LabelMapping, 0, len(labelMappings))
 
-	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
+	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) (applyResult, *applyErrBody) {
 		var mapping SummaryLabelMapping
 		mutex.Do(func() {
 			mapping = labelMappings[i]
 		})
+		tagResourceName(ctx, fmt.Sprintf("%s:%s:%s", mapping.ResourceType, mapping.ResourceID, mapping.LabelID))
 		if mapping.exists {
 			// this block here does 2 things, it does not write a
 			// mapping when one exists. it also avoids having to worry
@@ -1460,7 +3147,7 @@ func (s *Service) applyLabelMappings(labelMappings []SummaryLabelMapping) applie
 			// passed to the delete function below b/c it is never added
 			// to the list of mappings that is referenced in the delete
 			// call.
-			return nil
+			return applyResult{}, nil
 		}
 
 		m := influxdb.LabelMapping{
@@ -1470,7 +3157,7 @@ func (s *Service) applyLabelMappings(labelMappings []SummaryLabelMapping) applie
 		}
 		err := s.labelSVC.CreateLabelMapping(ctx, &m)
 		if err != nil {
-			return &applyErrBody{
+			return applyResult{}, &applyErrBody{
 				name: fmt.Sprintf("%s:%s:%s", mapping.ResourceType, mapping.ResourceID, mapping.LabelID),
 				msg:  err.Error(),
 			}
@@ -1480,7 +3167,7 @@ func (s *Service) applyLabelMappings(labelMappings []SummaryLabelMapping) applie
 			rollbackMappings = append(rollbackMappings, m)
 		})
 
-		return nil
+		return applyResult{}, nil
 	}
 
 	return applier{
@@ -1534,14 +3221,105 @@ func (s *Service) findDashboardByIDFull(ctx context.Context, id influxdb.ID) (*i
 	if err != nil {
 		return nil, err
 	}
+	if err := s.loadDashboardCellViews(ctx, dash); err != nil {
+		return nil, err
+	}
+	return dash, nil
+}
+
+// loadDashboardCellViews reads the views of the cells of the dashboard. A cell
+// read from the dashboard service carries an empty view of only its name,
+// the properties of the view are read separately.
+func (s *Service) loadDashboardCellViews(ctx context.Context, dash *influxdb.Dashboard) error {
 	for _, cell := range dash.Cells {
-		v, err := s.dashSVC.GetDashboardCellView(ctx, id, cell.ID)
+		if cell.View != nil && cell.View.Properties != nil {
+			continue
+		}
+		v, err := s.dashSVC.GetDashboardCellView(ctx, dash.ID, cell.ID)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		cell.View = v
 	}
-	return dash, nil
+	return nil
+}
+
+// findAllDashboards reads all the dashboards of the org a page at a time.
+func (s *Service) findAllDashboards(ctx context.Context, orgID influxdb.ID) ([]*influxdb.Dashboard, error) {
+	opts := influxdb.FindOptions{Limit: influxdb.MaxPageSize}
+	var dashboards []*influxdb.Dashboard
+	for {
+		page, _, err := s.dashSVC.FindDashboards(ctx, influxdb.DashboardFilter{OrganizationID: &orgID}, opts)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Offset > 0 && len(page) > 0 && page[0].ID == dashboards[0].ID {
+			// the service does not page the dashboards of an org, the
+			// first page held all of them
+			return dashboards, nil
+		}
+		dashboards = append(dashboards, page...)
+		if len(page) < opts.Limit {
+			return dashboards, nil
+		}
+		opts.Offset += len(page)
+	}
+}
+
+// findAllVariables reads all the variables of the org a page at a time.
+func (s *Service) findAllVariables(ctx context.Context, orgID influxdb.ID) ([]*influxdb.Variable, error) {
+	opts := influxdb.FindOptions{Limit: influxdb.MaxPageSize}
+	var vars []*influxdb.Variable
+	for {
+		page, err := s.varSVC.FindVariables(ctx, influxdb.VariableFilter{OrganizationID: &orgID}, opts)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Offset > 0 && len(page) > 0 && page[0].ID == vars[0].ID {
+			// the service does not page the variables, the first page
+			// held all of them
+			return vars, nil
+		}
+		vars = append(vars, page...)
+		if len(page) < opts.Limit {
+			return vars, nil
+		}
+		opts.Offset += len(page)
+	}
+}
+
+// findAllTelegrafs reads all the telegrafs of the org a page at a time.
+func (s *Service) findAllTelegrafs(ctx context.Context, orgID influxdb.ID) ([]*influxdb.TelegrafConfig, error) {
+	filter := influxdb.TelegrafConfigFilter{
+		OrgID: &orgID,
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.TelegrafsResourceType,
+		},
+	}
+	opts := influxdb.FindOptions{Limit: influxdb.MaxPageSize}
+	seen := make(map[influxdb.ID]bool)
+	var teles []*influxdb.TelegrafConfig
+	for {
+		page, _, err := s.teleSVC.FindTelegrafConfigs(ctx, filter, opts)
+		if err != nil {
+			return nil, err
+		}
+		// a telegraf is found once for each of its users, and the service
+		// may not page them, a page of only telegrafs seen is the last.
+		var added int
+		for _, t := range page {
+			if seen[t.ID] {
+				continue
+			}
+			seen[t.ID] = true
+			teles = append(teles, t)
+			added++
+		}
+		if added == 0 || len(page) < opts.Limit {
+			return teles, nil
+		}
+		opts.Offset += len(page)
+	}
 }
 
 type doMutex struct {
@@ -1566,60 +3344,329 @@ type (
 	}
 
 	creater struct {
+		// kind is the kind of the resources created, the statuses of
+		// resources without a kind, i.e. secrets, are not reported.
+		kind    Kind
 		entries int
-		fn      func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody
+		fn      func(ctx context.Context, i int, orgID, userID influxdb.ID) (applyResult, *applyErrBody)
 	}
 )
 
+// applierHooks are the hooks of a kind of resource newApplier builds the
+// applier of the kind from. The hooks are called with the index of the
+// resource of the kind they apply to.
+type applierHooks struct {
+	kind     Kind
+	resource string
+	entries  int
+
+	// prepare sets the org of the resource i and copies it, the copy is
+	// applied without the lock of the applier held. It is called with the
+	// lock held, as are name and id.
+	prepare func(i int, orgID influxdb.ID)
+	// name and id return the name and ID of the resource i.
+	name func(i int) string
+	id   func(i int) influxdb.ID
+	// load reads the state of the existing resource of the copy of the
+	// resource i that is not part of the existing resource found by the dry
+	// run, shouldApply and the rollback rely on it. A nil load reads nothing.
+	load func(ctx context.Context, i int) error
+	// shouldApply reports whether the copy of the resource i differs from
+	// its existing resource, resources left unchanged are not applied. A nil
+	// shouldApply applies every resource.
+	shouldApply func(i int) bool
+	// exists reports whether the copy of the resource i has an existing
+	// resource to update. A nil exists creates every resource.
+	exists func(i int) bool
+	// create creates the copy of the resource i, returning the ID of the
+	// created resource. It returns true when the resource turns out to
+	// exist, the existing resource is then updated instead.
+	create func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, bool, error)
+	// update updates the existing resource of the copy of the resource i.
+	update func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, error)
+	// setID records the ID the resource i was applied with, along with any
+	// state of its copy the apply changed. It is called with the lock held.
+	setID func(i int, id influxdb.ID)
+	// rollback rolls back the resources applied, given by index.
+	rollback func(applied []int) error
+}
+
+// newApplier returns the applier of the kind of resource of the hooks. The
+// resources are applied from copies taken under the lock of the applier, the
+// resources applied are rolled back in the order they were applied in.
+func newApplier(h applierHooks) applier {
+	mutex := new(doMutex)
+	applied := make([]int, 0, h.entries)
+
+	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) (applyResult, *applyErrBody) {
+		var (
+			name string
+			id   influxdb.ID
+		)
+		mutex.Do(func() {
+			h.prepare(i, orgID)
+			name, id = h.name(i), h.id(i)
+		})
+		tagResourceName(ctx, name)
+		if h.load != nil {
+			if err := h.load(ctx, i); err != nil {
+				return applyResult{}, &applyErrBody{
+					name: name,
+					msg:  err.Error(),
+				}
+			}
+		}
+		if h.shouldApply != nil && !h.shouldApply(i) {
+			return applyResult{name: name, id: id, status: ApplyStatusUnchanged}, nil
+		}
+
+		var err error
+		exists := h.exists != nil && h.exists(i)
+		if !exists {
+			id, exists, err = h.create(ctx, i, userID)
+		}
+		if err == nil && exists {
+			id, err = h.update(ctx, i, userID)
+		}
+		if err != nil {
+			return applyResult{}, &applyErrBody{
+				name: name,
+				msg:  err.Error(),
+			}
+		}
+
+		mutex.Do(func() {
+			h.setID(i, id)
+			applied = append(applied, i)
+			id = h.id(i)
+		})
+
+		return newApplyResult(name, id, exists), nil
+	}
+
+	return applier{
+		creater: creater{
+			kind:    h.kind,
+			entries: h.entries,
+			fn:      createFn,
+		},
+		rollbacker: rollbacker{
+			resource: h.resource,
+			fn: func() error {
+				var rollbacks []int
+				mutex.Do(func() {
+					rollbacks = append(rollbacks, applied...)
+				})
+				return h.rollback(rollbacks)
+			},
+		},
+	}
+}
+
+// applyResult is the result of applying a single resource of the pkg.
+type applyResult struct {
+	name   string
+	id     influxdb.ID
+	status ApplyStatus
+}
+
+// newApplyResult is the result of a resource that was applied, an existing
+// resource is updated, otherwise it is created with the id assigned to it.
+func newApplyResult(name string, id influxdb.ID, exists bool) applyResult {
+	status := ApplyStatusCreated
+	if exists {
+		status = ApplyStatusUpdated
+	}
+	return applyResult{name: name, id: id, status: status}
+}
+
+// tagResourceName tags the span of the create call in ctx with the name of
+// the resource it applies.
+func tagResourceName(ctx context.Context, name string) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span.SetTag("name", name)
+	}
+}
+
 type rollbackCoordinator struct {
-	rollbacks []rollbacker
+	// rollbacks are the rollbackers of each group of appliers run, in the
+	// order the groups were run.
+	rollbacks [][]rollbacker
 
 	sem chan struct{}
+
+	// bestEffort collects the failures of the appliers in place of failing
+	// the run and rolling back.
+	bestEffort bool
+	mu         sync.Mutex
+	errs       []ApplyError
+	statuses   []SummaryResourceStatus
+}
+
+func (s *Service) newRollbackCoordinator(opt ApplyOpt) *rollbackCoordinator {
+	return &rollbackCoordinator{
+		sem:        make(chan struct{}, s.applyReqLimit),
+		bestEffort: opt.BestEffort,
+	}
+}
+
+// adopt takes over the rollbacks of the coordinator, they are rolled back
+// ahead of the rollbacks recorded before them.
+func (r *rollbackCoordinator) adopt(other *rollbackCoordinator) {
+	r.rollbacks = append(r.rollbacks, other.rollbacks...)
+	other.rollbacks = nil
 }
 
 func (r *rollbackCoordinator) runTilEnd(ctx context.Context, orgID, userID influxdb.ID, appliers ...applier) error {
 	errStr := newErrStream(ctx)
 
+	group := make([]rollbacker, 0, len(appliers))
+	for _, app := range appliers {
+		group = append(group, app.rollbacker)
+	}
+	r.rollbacks = append(r.rollbacks, group)
+
 	wg := new(sync.WaitGroup)
 	for i := range appliers {
 		// cannot reuse the shared variable from for loop since we're using concurrency b/c
 		// that temp var gets recycled between iterations
 		app := appliers[i]
-		r.rollbacks = append(r.rollbacks, app.rollbacker)
+
+		// each resource group gets its own span, the create calls of its
+		// entries are children of it.
+		groupSpan, groupCtx := tracing.StartSpanFromContextWithOperationName(ctx, "apply "+app.rollbacker.resource)
+		groupSpan.SetTag("resource", app.rollbacker.resource)
+		groupSpan.SetTag("entries", app.creater.entries)
+		groupWG := new(sync.WaitGroup)
+
 		for idx := range make([]struct{}, app.creater.entries) {
 			r.sem <- struct{}{}
 			wg.Add(1)
+			groupWG.Add(1)
 
 			go func(i int, resource string) {
 				defer func() {
+					groupWG.Done()
 					wg.Done()
 					<-r.sem
 				}()
 
+				span, ctx := tracing.StartSpanFromContextWithOperationName(groupCtx, "create "+resource)
+				defer span.Finish()
+				span.SetTag("resource", resource)
+
 				ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 				defer cancel()
 
-				if err := app.creater.fn(ctx, i, orgID, userID); err != nil {
+				res, err := app.creater.fn(ctx, i, orgID, userID)
+				if err != nil {
+					r.addStatus(app.creater.kind, err.name, 0, ApplyStatusFailed)
+					span.SetTag("error", true)
+					span.LogKV("error", err.msg)
+					if r.bestEffort {
+						r.addFailure(resource, *err)
+						return
+					}
 					errStr.add(errMsg{resource: resource, err: *err})
+					return
 				}
+				r.addStatus(app.creater.kind, res.name, res.id, res.status)
 			}(idx, app.rollbacker.resource)
 		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			groupWG.Wait()
+			groupSpan.Finish()
+		}()
 	}
 	wg.Wait()
 
 	return errStr.close()
 }
 
+func (r *rollbackCoordinator) addFailure(resource string, err applyErrBody) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, ApplyError{
+		Resource: resource,
+		Name:     err.name,
+		Msg:      err.msg,
+	})
+}
+
+func (r *rollbackCoordinator) addStatus(kind Kind, name string, id influxdb.ID, status ApplyStatus) {
+	if kind == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, SummaryResourceStatus{
+		Kind:   kind,
+		Name:   name,
+		ID:     SafeID(id),
+		Status: status,
+	})
+}
+
+// resourceStatuses returns the statuses of the resources applied, ordered by
+// kind and name.
+func (r *rollbackCoordinator) resourceStatuses() []SummaryResourceStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := append([]SummaryResourceStatus(nil), r.statuses...)
+	sortResourceStatuses(statuses)
+	return statuses
+}
+
+// failures returns the failures collected by a best effort apply, ordered by
+// resource and name.
+func (r *rollbackCoordinator) failures() []ApplyError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := append([]ApplyError(nil), r.errs...)
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].Resource != errs[j].Resource {
+			return errs[i].Resource < errs[j].Resource
+		}
+		return errs[i].Name < errs[j].Name
+	})
+	return errs
+}
+
+// rollback rolls back the groups of appliers run when the apply failed. The
+// groups are rolled back in reverse, so the resources are removed before the
+// resources they depend on, i.e. the label mappings before their labels. The
+// error of the apply is replaced with a *RollbackError reporting the rollback.
 func (r *rollbackCoordinator) rollback(l *zap.Logger, err *error) {
-	if *err == nil {
+	if *err == nil || r.bestEffort || len(r.rollbacks) == 0 {
 		return
 	}
 
-	for _, r := range r.rollbacks {
-		if err := r.fn(); err != nil {
-			l.Error("failed to delete "+r.resource, zap.Error(err))
+	var (
+		report RollbackReport
+		failed []string
+	)
+	for i := len(r.rollbacks) - 1; i >= 0; i-- {
+		for _, rb := range r.rollbacks[i] {
+			res := RollbackResult{Resource: rb.resource}
+			if rbErr := rb.fn(); rbErr != nil {
+				res.Msg = rbErr.Error()
+				failed = append(failed, rb.resource)
+			}
+			report.Results = append(report.Results, res)
 		}
 	}
+
+	if len(failed) > 0 {
+		l.Error("Failed to roll back pkg apply", zap.Int("resources", len(report.Results)), zap.Strings("failed", failed))
+	} else {
+		l.Info("Rolled back pkg apply", zap.Int("resources", len(report.Results)))
+	}
+	*err = &RollbackError{Err: *err, Report: report}
 }
 
 type errMsg struct {
@@ -1704,6 +3751,19 @@ func (a applyErrs) toError(resType, msg string) error {
 	return errors.New(errMsg)
 }
 
+// appliedLabelMappings removes the mappings of the labels and resources that
+// failed to apply, they have no ID to map.
+func appliedLabelMappings(mappings []SummaryLabelMapping) []SummaryLabelMapping {
+	out := make([]SummaryLabelMapping, 0, len(mappings))
+	for _, m := range mappings {
+		if m.LabelID == 0 || m.ResourceID == 0 {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
 func labelSlcToMap(labels []*label) map[string]*label {
 	m := make(map[string]*label)
 	for i := range labels {
@@ -2,29 +2,51 @@ package pkger
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kit/prom"
+	"github.com/influxdata/influxdb/kit/prom/promtest"
+	"github.com/influxdata/influxdb/kv"
 	"github.com/influxdata/influxdb/mock"
+	icheck "github.com/influxdata/influxdb/notification/check"
 	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/rule"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"gopkg.in/yaml.v3"
 )
 
 func TestService(t *testing.T) {
 	newTestService := func(opts ...ServiceSetterFn) *Service {
 		opt := serviceOpt{
 			bucketSVC:   mock.NewBucketService(),
+			checkSVC:    mock.NewCheckService(),
 			dashSVC:     mock.NewDashboardService(),
 			labelSVC:    mock.NewLabelService(),
 			endpointSVC: mock.NewNotificationEndpointService(),
+			ruleSVC:     mock.NewNotificationRuleStore(),
 			teleSVC:     mock.NewTelegrafConfigStore(),
+			scraperSVC:  mock.NewScraperTargetStoreService(),
 			varSVC:      mock.NewVariableService(),
+			timeGen:     influxdb.RealTimeGenerator{},
 		}
 		for _, o := range opts {
 			o(&opt)
@@ -32,16 +54,108 @@ func TestService(t *testing.T) {
 
 		return NewService(
 			WithBucketSVC(opt.bucketSVC),
+			WithCheckSVC(opt.checkSVC),
 			WithDashboardSVC(opt.dashSVC),
 			WithLabelSVC(opt.labelSVC),
 			WithNoticationEndpointSVC(opt.endpointSVC),
+			WithNotificationRuleSVC(opt.ruleSVC),
+			WithScraperTargetSVC(opt.scraperSVC),
 			WithSecretSVC(opt.secretSVC),
+			WithTaskSVC(opt.taskSVC),
 			WithTelegrafSVC(opt.teleSVC),
 			WithVariableSVC(opt.varSVC),
+			WithOrganizationOperationLog(opt.orgOpLog),
+			WithUsageReporter(opt.usageReporter),
+			WithTimeGenerator(opt.timeGen),
 		)
 	}
 
 	t.Run("DryRun", func(t *testing.T) {
+		t.Run("returns on a canceled context before any lookup", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					t.Error("bucket looked up with a canceled context")
+					return nil, errors.New("unexpected lookup")
+				}
+				svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				_, _, err := svc.DryRun(ctx, influxdb.ID(100), 0, pkg)
+				require.Equal(t, context.Canceled, err)
+				assert.False(t, pkg.isVerified)
+			})
+		})
+
+		t.Run("reports the missing permissions before any lookup", func(t *testing.T) {
+			orgID := influxdb.ID(100)
+			newPerm := func(action influxdb.Action, resType influxdb.ResourceType) influxdb.Permission {
+				p, err := influxdb.NewPermission(action, resType, orgID)
+				require.NoError(t, err)
+				return *p
+			}
+
+			tests := []struct {
+				name     string
+				perms    []influxdb.Permission
+				expected []influxdb.Permission
+			}{
+				{
+					name: "lacking bucket write",
+					perms: []influxdb.Permission{
+						newPerm(influxdb.ReadAction, influxdb.BucketsResourceType),
+						newPerm(influxdb.WriteAction, influxdb.LabelsResourceType),
+					},
+					expected: []influxdb.Permission{
+						newPerm(influxdb.WriteAction, influxdb.BucketsResourceType),
+					},
+				},
+				{
+					name: "lacking bucket and label write",
+					perms: []influxdb.Permission{
+						newPerm(influxdb.ReadAction, influxdb.BucketsResourceType),
+						newPerm(influxdb.ReadAction, influxdb.LabelsResourceType),
+					},
+					expected: []influxdb.Permission{
+						newPerm(influxdb.WriteAction, influxdb.BucketsResourceType),
+						newPerm(influxdb.WriteAction, influxdb.LabelsResourceType),
+					},
+				},
+			}
+
+			for _, tt := range tests {
+				fn := func(t *testing.T) {
+					testfileRunner(t, "testdata/bucket_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+						fakeBktSVC := mock.NewBucketService()
+						fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+							t.Error("bucket looked up without the permissions to apply")
+							return nil, errors.New("unexpected lookup")
+						}
+						svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+						ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+							Status:      influxdb.Active,
+							Permissions: tt.perms,
+						})
+
+						_, _, err := svc.DryRun(ctx, orgID, 0, pkg)
+						require.Error(t, err)
+						assert.Equal(t, influxdb.EUnauthorized, influxdb.ErrorCode(err))
+						for _, p := range tt.expected {
+							assert.Contains(t, err.Error(), p.String())
+						}
+						for _, p := range tt.perms {
+							assert.NotContains(t, err.Error(), p.String())
+						}
+						assert.False(t, pkg.isVerified)
+					})
+				}
+				t.Run(tt.name, fn)
+			}
+		})
+
 		t.Run("buckets", func(t *testing.T) {
 			t.Run("single bucket updated", func(t *testing.T) {
 				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
@@ -78,6 +192,43 @@ func TestService(t *testing.T) {
 				})
 			})
 
+			t.Run("bucket renamed by display name", func(t *testing.T) {
+				testfileRunner(t, "testdata/rename.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+						if name != "telegraf" {
+							return nil, &influxdb.Error{Code: influxdb.ENotFound}
+						}
+						return &influxdb.Bucket{
+							ID:              influxdb.ID(1),
+							OrgID:           orgID,
+							Name:            name,
+							RetentionPeriod: time.Hour,
+						}, nil
+					}
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, diff.Buckets, 1)
+
+					expected := DiffBucket{
+						ID:   SafeID(1),
+						Name: "telegraf",
+						Old: &DiffBucketValues{
+							RetentionRules: retentionRules{newRetentionRule(time.Hour)},
+						},
+						New: DiffBucketValues{
+							DisplayName:    "metrics",
+							RetentionRules: retentionRules{newRetentionRule(time.Hour)},
+						},
+					}
+					assert.Equal(t, expected, diff.Buckets[0])
+					assert.True(t, diff.HasConflicts())
+				})
+			})
+
 			t.Run("single bucket new", func(t *testing.T) {
 				testfileRunner(t, "testdata/bucket.json", func(t *testing.T, pkg *Pkg) {
 					fakeBktSVC := mock.NewBucketService()
@@ -103,6 +254,90 @@ func TestService(t *testing.T) {
 			})
 		})
 
+		t.Run("conflicts", func(t *testing.T) {
+			newPkg := func(t *testing.T, bktName string) *Pkg {
+				t.Helper()
+
+				pkg, err := Parse(EncodingYAML, FromString(`apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Bucket
+      name: `+bktName+`
+    - kind: Bucket
+      name: rucket_1
+`))
+				require.NoError(t, err)
+				return pkg
+			}
+
+			t.Run("bucket colliding with an existing system bucket", func(t *testing.T) {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					if name != "_custom_system" {
+						return nil, errors.New("not found")
+					}
+					return &influxdb.Bucket{
+						ID:    influxdb.ID(1),
+						OrgID: orgID,
+						Name:  name,
+						Type:  influxdb.BucketTypeSystem,
+					}, nil
+				}
+				svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, newPkg(t, "_custom_system"))
+				require.NoError(t, err)
+
+				expected := []DiffConflict{
+					{
+						Kind:   KindBucket,
+						Name:   "_custom_system",
+						Reason: "bucket name collides with a system bucket",
+					},
+				}
+				assert.Equal(t, expected, diff.Conflicts)
+				assert.True(t, diff.HasConflicts())
+			})
+
+			t.Run("bucket colliding with a reserved system bucket name", func(t *testing.T) {
+				names := []string{influxdb.TasksSystemBucketName, influxdb.MonitoringSystemBucketName}
+				for _, name := range names {
+					fn := func(t *testing.T) {
+						fakeBktSVC := mock.NewBucketService()
+						fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+							return nil, errors.New("not found")
+						}
+						svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+						_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, newPkg(t, name))
+						require.NoError(t, err)
+
+						require.Len(t, diff.Conflicts, 1)
+						assert.Equal(t, KindBucket, diff.Conflicts[0].Kind)
+						assert.Equal(t, name, diff.Conflicts[0].Name)
+					}
+					t.Run(name, fn)
+				}
+			})
+
+			t.Run("no conflicts for user buckets", func(t *testing.T) {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					return &influxdb.Bucket{ID: influxdb.ID(1), OrgID: orgID, Name: name}, nil
+				}
+				svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, newPkg(t, "rucket_2"))
+				require.NoError(t, err)
+
+				assert.Empty(t, diff.Conflicts)
+			})
+		})
+
 		t.Run("labels", func(t *testing.T) {
 			t.Run("two labels updated", func(t *testing.T) {
 				testfileRunner(t, "testdata/label.json", func(t *testing.T, pkg *Pkg) {
@@ -137,16 +372,63 @@ func TestService(t *testing.T) {
 							Color:       "#FFFFFF",
 							Description: "label 1 description",
 						},
+						Properties: &DiffLabelProperties{
+							Name:        DiffLabelProperty{Old: "label_1", New: "label_1"},
+							Color:       DiffLabelProperty{Old: "old color", New: "#FFFFFF", Changed: true},
+							Description: DiffLabelProperty{Old: "old description", New: "label 1 description", Changed: true},
+						},
 					}
 					assert.Equal(t, expected, diff.Labels[0])
 
 					expected.Name = "label_2"
 					expected.New.Color = "#000000"
 					expected.New.Description = "label 2 description"
+					expected.Properties = &DiffLabelProperties{
+						Name:        DiffLabelProperty{Old: "label_2", New: "label_2"},
+						Color:       DiffLabelProperty{Old: "old color", New: "#000000", Changed: true},
+						Description: DiffLabelProperty{Old: "old description", New: "label 2 description", Changed: true},
+					}
 					assert.Equal(t, expected, diff.Labels[1])
 				})
 			})
 
+			t.Run("only the color of a label changed", func(t *testing.T) {
+				testfileRunner(t, "testdata/label", func(t *testing.T, pkg *Pkg) {
+					fakeLabelSVC := mock.NewLabelService()
+					fakeLabelSVC.FindLabelsFn = func(_ context.Context, filter influxdb.LabelFilter) ([]*influxdb.Label, error) {
+						if filter.Name != "label_1" {
+							return nil, nil
+						}
+						return []*influxdb.Label{
+							{
+								ID:   influxdb.ID(1),
+								Name: filter.Name,
+								Properties: map[string]string{
+									"color":       "old color",
+									"description": "label 1 description",
+								},
+							},
+						}, nil
+					}
+					svc := newTestService(WithLabelSVC(fakeLabelSVC))
+
+					_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, diff.Labels, 2)
+
+					props := diff.Labels[0].Properties
+					require.NotNil(t, props)
+					assert.True(t, props.Changed())
+					assert.Equal(t, DiffLabelProperty{Old: "old color", New: "#FFFFFF", Changed: true}, props.Color)
+					assert.False(t, props.Name.Changed)
+					assert.False(t, props.Description.Changed)
+
+					// a new label has no existing properties to compare
+					assert.Nil(t, diff.Labels[1].Properties)
+				})
+			})
+
 			t.Run("two labels created", func(t *testing.T) {
 				testfileRunner(t, "testdata/label.yml", func(t *testing.T, pkg *Pkg) {
 					fakeLabelSVC := mock.NewLabelService()
@@ -241,6 +523,42 @@ func TestService(t *testing.T) {
 			})
 		})
 
+		t.Run("notification endpoint missing required secret returns parse error", func(t *testing.T) {
+			pkgStr := `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+  description:  pack description
+spec:
+  resources:
+    - kind: Notification_Endpoint_HTTP
+      name: http_basic
+      type: basic
+      method: POST
+      url: https://www.example.com/endpoint/basicauth
+      username: user
+`
+			pkg := &Pkg{}
+			require.NoError(t, yaml.Unmarshal([]byte(pkgStr), pkg))
+
+			svc := newTestService()
+
+			_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+			require.Error(t, err)
+			require.True(t, IsParseErr(err))
+
+			pErr := err.(*ParseError)
+			require.Len(t, pErr.Resources, 1)
+			resErr := pErr.Resources[0]
+			assert.Equal(t, KindNotificationEndpointHTTP.String(), resErr.Kind)
+			require.Len(t, resErr.ValidationErrs, 1)
+			assert.Equal(t, fieldNotificationEndpointPassword, resErr.ValidationErrs[0].Field)
+			assert.Contains(t, resErr.ValidationErrs[0].Msg, "http_basic")
+
+			require.Len(t, diff.NotificationEndpoints, 1)
+		})
+
 		t.Run("secrets not found returns error", func(t *testing.T) {
 			testfileRunner(t, "testdata/notification_endpoint_secrets.yml", func(t *testing.T, pkg *Pkg) {
 				fakeSecretSVC := mock.NewSecretService()
@@ -254,6 +572,433 @@ func TestService(t *testing.T) {
 			})
 		})
 
+		t.Run("scraper targets", func(t *testing.T) {
+			testfileRunner(t, "testdata/scraper_target", func(t *testing.T, pkg *Pkg) {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					if name != "org_rucket" {
+						return nil, &influxdb.Error{Code: influxdb.ENotFound}
+					}
+					return &influxdb.Bucket{ID: influxdb.ID(7), OrgID: orgID, Name: name}, nil
+				}
+				fakeBktSVC.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+					return &influxdb.Bucket{ID: id, Name: "old_rucket"}, nil
+				}
+				fakeScraperSVC := mock.NewScraperTargetStoreService()
+				fakeScraperSVC.ListTargetsF = func(_ context.Context, filter influxdb.ScraperTargetFilter) ([]influxdb.ScraperTarget, error) {
+					return []influxdb.ScraperTarget{
+						{
+							ID:       influxdb.ID(3),
+							Name:     "scraper_1",
+							Type:     influxdb.PrometheusScraperType,
+							URL:      "http://localhost:9100/old",
+							BucketID: influxdb.ID(9),
+						},
+					}, nil
+				}
+				svc := newTestService(WithBucketSVC(fakeBktSVC), WithScraperTargetSVC(fakeScraperSVC))
+
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.NoError(t, err)
+
+				require.Len(t, diff.ScraperTargets, 2)
+
+				expected := DiffScraperTarget{
+					ID:   SafeID(3),
+					Name: "scraper_1",
+					Old: &DiffScraperTargetValues{
+						Type:   influxdb.PrometheusScraperType,
+						URL:    "http://localhost:9100/old",
+						Bucket: "old_rucket",
+					},
+					New: DiffScraperTargetValues{
+						Type:   influxdb.PrometheusScraperType,
+						URL:    "http://localhost:9100/metrics",
+						Bucket: "rucket_1",
+					},
+				}
+				assert.Equal(t, expected, diff.ScraperTargets[0])
+
+				expected = DiffScraperTarget{
+					// no ID here since this one would be new
+					Name: "scraper_2",
+					New: DiffScraperTargetValues{
+						Type:   influxdb.PrometheusScraperType,
+						URL:    "https://example.com/metrics",
+						Bucket: "org_rucket",
+					},
+				}
+				assert.Equal(t, expected, diff.ScraperTargets[1])
+			})
+
+			t.Run("errors when the bucket is not in the pkg or the org", func(t *testing.T) {
+				testfileRunner(t, "testdata/scraper_target", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+						return nil, &influxdb.Error{Code: influxdb.ENotFound}
+					}
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					_, _, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+					require.Error(t, err)
+					assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+				})
+			})
+		})
+
+		t.Run("checks", func(t *testing.T) {
+			testfileRunner(t, "testdata/check_threshold", func(t *testing.T, pkg *Pkg) {
+				existing := &icheck.Threshold{
+					Base: icheck.Base{
+						ID:   influxdb.ID(3),
+						Name: "check_1",
+					},
+				}
+				fakeCheckSVC := mock.NewCheckService()
+				fakeCheckSVC.FindChecksFn = func(_ context.Context, filter influxdb.CheckFilter, _ ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+					return []influxdb.Check{existing}, 1, nil
+				}
+				svc := newTestService(WithCheckSVC(fakeCheckSVC))
+
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.NoError(t, err)
+
+				require.Len(t, diff.Checks, 1)
+				actual := diff.Checks[0]
+				assert.Equal(t, SafeID(3), actual.ID)
+				assert.Equal(t, "check_1", actual.Name)
+				require.NotNil(t, actual.Old)
+				assert.Equal(t, existing, actual.Old.Check)
+				assert.Equal(t, "check_1", actual.New.Check.GetName())
+				assert.False(t, actual.IsNew())
+			})
+
+			t.Run("errors when the bucket is not in the pkg or the org", func(t *testing.T) {
+				pkgStr := `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Check_Threshold
+      name: check_1
+      bucket: rucket_1
+      field: usage_user
+      query: 'from(bucket: "rucket_1") |> range(start: -1m)'
+      every: 1m
+      thresholds:
+        - type: greater
+          level: CRIT
+          value: 50
+`
+				pkg, err := Parse(EncodingYAML, FromString(pkgStr))
+				require.NoError(t, err)
+
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					return nil, &influxdb.Error{Code: influxdb.ENotFound}
+				}
+				svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+				_, _, err = svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.Error(t, err)
+				assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+			})
+		})
+
+		t.Run("notification rules", func(t *testing.T) {
+			testfileRunner(t, "testdata/notification_rule", func(t *testing.T, pkg *Pkg) {
+				svc := newTestService()
+
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.NoError(t, err)
+
+				require.Len(t, diff.NotificationRules, 1)
+				actual := diff.NotificationRules[0]
+				assert.True(t, actual.IsNew())
+				assert.Equal(t, "rule_1", actual.Name)
+				assert.Equal(t, "endpoint_1", actual.New.EndpointName)
+				assert.Equal(t, endpoint.SlackType, actual.New.EndpointType)
+				assert.Equal(t, "10m", actual.New.Every)
+			})
+
+			t.Run("errors when the endpoint is not in the pkg or the org", func(t *testing.T) {
+				pkgStr := `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Notification_Rule
+      name: rule_1
+      endpointName: endpoint_1
+      every: 10m
+      statusRules:
+        - currentLevel: CRIT
+`
+				pkg, err := Parse(EncodingYAML, FromString(pkgStr))
+				require.NoError(t, err)
+
+				svc := newTestService()
+
+				_, _, err = svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.Error(t, err)
+				assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+			})
+		})
+
+		t.Run("bucket references", func(t *testing.T) {
+			pkgStr := `apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Bucket
+      name: telegraf
+    - kind: Dashboard
+      name: dash_1
+      charts:
+        - kind:   Single_Stat
+          name:   from pkg
+          width:  6
+          height: 3
+          queries:
+            - query: "from(bucket: \"telegraf\") |> range(start: -1h)"
+          colors:
+            - name: laser
+              type: text
+              hex: "#8F8AF4"
+        - kind:   Single_Stat
+          name:   from org
+          width:  6
+          height: 3
+          queries:
+            - query: "from(bucket: \"system\") |> range(start: -1h)"
+            - query: "from(bucket: v.bucket) |> range(start: -1h)"
+          colors:
+            - name: laser
+              type: text
+              hex: "#8F8AF4"
+        - kind:   Single_Stat
+          name:   unknown
+          width:  6
+          height: 3
+          queries:
+            - query: "from(bucket: \"metrics\") |> range(start: -1h)"
+          colors:
+            - name: laser
+              type: text
+              hex: "#8F8AF4"
+    - kind: Variable
+      name: var_1
+      type: query
+      language: flux
+      query: 'from(bucket: "metrics") |> range(start: -1h) |> keep(columns: ["host"])'
+`
+			pkg, err := Parse(EncodingYAML, FromString(pkgStr))
+			require.NoError(t, err)
+
+			fakeBktSVC := mock.NewBucketService()
+			fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+				if name != "system" {
+					return nil, &influxdb.Error{Code: influxdb.ENotFound}
+				}
+				return &influxdb.Bucket{ID: 1, OrgID: orgID, Name: name}, nil
+			}
+			svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+			_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+			require.NoError(t, err)
+
+			require.Len(t, diff.Warnings, 2)
+			assert.Equal(t, KindDashboard, diff.Warnings[0].Kind)
+			assert.Equal(t, "dash_1", diff.Warnings[0].Name)
+			assert.Equal(t, "unknown", diff.Warnings[0].Chart)
+			assert.Equal(t, "metrics", diff.Warnings[0].Bucket)
+			assert.Equal(t, KindVariable, diff.Warnings[1].Kind)
+			assert.Equal(t, "var_1", diff.Warnings[1].Name)
+			assert.Equal(t, "metrics", diff.Warnings[1].Bucket)
+		})
+
+		t.Run("snapshot", func(t *testing.T) {
+			existingBkt := influxdb.Bucket{
+				ID:              influxdb.ID(1),
+				OrgID:           influxdb.ID(100),
+				Name:            "rucket_1",
+				Description:     "old desc",
+				RetentionPeriod: 30 * time.Hour,
+			}
+			existingLabel := influxdb.Label{
+				ID:         influxdb.ID(2),
+				OrgID:      influxdb.ID(100),
+				Name:       "label_1",
+				Properties: map[string]string{"color": "red"},
+			}
+
+			snapshotLabel := SummaryLabel{
+				ID:    SafeID(existingLabel.ID),
+				OrgID: SafeID(existingLabel.OrgID),
+				Name:  existingLabel.Name,
+			}
+			snapshotLabel.Properties.Color = "red"
+
+			snapshot := Summary{
+				Buckets: []SummaryBucket{{
+					ID:              SafeID(existingBkt.ID),
+					OrgID:           SafeID(existingBkt.OrgID),
+					Name:            existingBkt.Name,
+					Description:     existingBkt.Description,
+					RetentionPeriod: existingBkt.RetentionPeriod,
+				}},
+				Labels: []SummaryLabel{snapshotLabel},
+				LabelMappings: []SummaryLabelMapping{{
+					ResourceID:   SafeID(existingBkt.ID),
+					ResourceName: existingBkt.Name,
+					ResourceType: influxdb.BucketsResourceType,
+					LabelID:      SafeID(existingLabel.ID),
+					LabelName:    existingLabel.Name,
+				}},
+			}
+
+			newLiveService := func() *Service {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					if name != existingBkt.Name {
+						return nil, &influxdb.Error{Code: influxdb.ENotFound}
+					}
+					bkt := existingBkt
+					return &bkt, nil
+				}
+				fakeLabelSVC := mock.NewLabelService()
+				fakeLabelSVC.FindLabelsFn = func(_ context.Context, filter influxdb.LabelFilter) ([]*influxdb.Label, error) {
+					if filter.Name != existingLabel.Name {
+						return nil, nil
+					}
+					label := existingLabel
+					return []*influxdb.Label{&label}, nil
+				}
+				fakeLabelSVC.FindResourceLabelsFn = func(_ context.Context, filter influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+					if filter.ResourceID != existingBkt.ID {
+						return nil, nil
+					}
+					label := existingLabel
+					return []*influxdb.Label{&label}, nil
+				}
+				return newTestService(WithBucketSVC(fakeBktSVC), WithLabelSVC(fakeLabelSVC))
+			}
+
+			t.Run("diff is equal to the diff of a live dry run", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket_associates_label", func(t *testing.T, pkg *Pkg) {
+					// the snapshot lookups never reach the services
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(context.Context, influxdb.ID, string) (*influxdb.Bucket, error) {
+						return nil, errors.New("should not be called")
+					}
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					_, snapshotDiff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg, DryRunWithSnapshot(snapshot))
+					require.NoError(t, err)
+					assert.True(t, snapshotDiff.Snapshot)
+					assert.Zero(t, fakeBktSVC.FindBucketByNameCalls.Count())
+
+					_, liveDiff, err := newLiveService().DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+					require.NoError(t, err)
+					assert.False(t, liveDiff.Snapshot)
+
+					snapshotDiff.Snapshot = false
+					assert.Equal(t, liveDiff, snapshotDiff)
+
+					require.Len(t, snapshotDiff.Buckets, 3)
+					assert.Equal(t, SafeID(existingBkt.ID), snapshotDiff.Buckets[0].ID)
+				})
+			})
+
+			t.Run("apply does not trust the dry run of a snapshot", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket_associates_label", func(t *testing.T, pkg *Pkg) {
+					svc := newLiveService()
+
+					_, _, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+					require.NoError(t, err)
+					require.True(t, pkg.isVerifiedWith(ApplyOpt{}))
+
+					_, _, err = svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg, DryRunWithSnapshot(snapshot))
+					require.NoError(t, err)
+					assert.False(t, pkg.isVerifiedWith(ApplyOpt{}))
+				})
+			})
+
+			t.Run("apply errors with a snapshot", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket_associates_label", func(t *testing.T, pkg *Pkg) {
+					_, err := newLiveService().Apply(context.TODO(), influxdb.ID(100), 0, pkg, DryRunWithSnapshot(snapshot))
+					require.Error(t, err)
+					assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+				})
+			})
+
+			t.Run("resolves notification rules from the snapshot", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_rule", func(t *testing.T, pkg *Pkg) {
+					fakeRuleSVC := mock.NewNotificationRuleStore()
+					fakeRuleSVC.FindNotificationRulesF = func(context.Context, influxdb.NotificationRuleFilter, ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+						return nil, 0, errors.New("should not be called")
+					}
+					svc := newTestService(WithNotificationRuleSVC(fakeRuleSVC))
+
+					ruleSnapshot := Summary{
+						NotificationRules: []SummaryNotificationRule{{
+							ID:           SafeID(7),
+							OrgID:        SafeID(100),
+							Name:         "rule_1",
+							EndpointID:   SafeID(3),
+							EndpointName: "endpoint_1",
+							EndpointType: endpoint.SlackType,
+							Every:        "5m",
+							StatusRules:  []SummaryStatusRule{{CurrentLevel: "CRIT"}},
+						}},
+					}
+					_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg, DryRunWithSnapshot(ruleSnapshot))
+					require.NoError(t, err)
+
+					require.Len(t, diff.NotificationRules, 1)
+					actual := diff.NotificationRules[0]
+					assert.Equal(t, SafeID(7), actual.ID)
+					require.NotNil(t, actual.Old)
+					assert.Equal(t, "5m", actual.Old.Every)
+				})
+			})
+
+			t.Run("lookups the snapshot cannot answer error", func(t *testing.T) {
+				svc := newTestService().withSnapshot(snapshot)
+
+				_, err := svc.dashSVC.FindDashboardByID(context.TODO(), influxdb.ID(1))
+				require.Error(t, err)
+				assert.Equal(t, influxdb.EMethodNotAllowed, influxdb.ErrorCode(err))
+
+				_, _, err = svc.bucketSVC.FindBuckets(context.TODO(), influxdb.BucketFilter{})
+				require.Error(t, err)
+				assert.Equal(t, influxdb.EMethodNotAllowed, influxdb.ErrorCode(err))
+			})
+
+			t.Run("a later dry run resets the existing resources of an earlier one", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket_associates_label", func(t *testing.T, pkg *Pkg) {
+					_, _, err := newTestService().DryRun(context.TODO(), influxdb.ID(100), 0, pkg, DryRunWithSnapshot(snapshot))
+					require.NoError(t, err)
+					require.NotNil(t, pkg.mBuckets[existingBkt.Name].existing)
+
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(context.Context, influxdb.ID, string) (*influxdb.Bucket, error) {
+						return nil, &influxdb.Error{Code: influxdb.ENotFound}
+					}
+					_, _, err = newTestService(WithBucketSVC(fakeBktSVC)).DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+					require.NoError(t, err)
+					assert.Nil(t, pkg.mBuckets[existingBkt.Name].existing)
+				})
+			})
+		})
+
 		t.Run("variables", func(t *testing.T) {
 			testfileRunner(t, "testdata/variables", func(t *testing.T, pkg *Pkg) {
 				fakeVarSVC := mock.NewVariableService()
@@ -303,13 +1048,144 @@ func TestService(t *testing.T) {
 				assert.Equal(t, expected, diff.Variables[1])
 			})
 		})
-	})
+		t.Run("resource lookups", func(t *testing.T) {
+			const numBuckets = 20
+
+			newPkg := func(t *testing.T) *Pkg {
+				t.Helper()
+
+				var sb strings.Builder
+				sb.WriteString(`apiVersion: 0.1.0
+kind: Package
+meta:
+  pkgName:      pkg_name
+  pkgVersion:   1
+spec:
+  resources:
+    - kind: Label
+      name: label_1
+    - kind: Label
+      name: label_2
+`)
+				for i := numBuckets; i > 0; i-- {
+					fmt.Fprintf(&sb, `    - kind: Bucket
+      name: rucket_%02d
+      associations:
+        - kind: Label
+          name: label_1
+        - kind: Label
+          name: label_2
+`, i)
+				}
 
-	t.Run("Apply", func(t *testing.T) {
-		t.Run("buckets", func(t *testing.T) {
-			t.Run("successfully creates pkg of buckets", func(t *testing.T) {
-				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
-					fakeBktSVC := mock.NewBucketService()
+				pkg, err := Parse(EncodingYAML, FromString(sb.String()))
+				require.NoError(t, err)
+				return pkg
+			}
+
+			t.Run("run concurrently bounded by the apply request limit", func(t *testing.T) {
+				var inFlight, maxInFlight int64
+				track := func() func() {
+					n := atomic.AddInt64(&inFlight, 1)
+					for {
+						max := atomic.LoadInt64(&maxInFlight)
+						if n <= max || atomic.CompareAndSwapInt64(&maxInFlight, max, n) {
+							break
+						}
+					}
+					return func() { atomic.AddInt64(&inFlight, -1) }
+				}
+
+				const lookupLatency = 20 * time.Millisecond
+				// the mock bucket service serializes its calls to count them,
+				// the lookups are made against one that does not.
+				fakeBktSVC := &concurrentBucketSVC{
+					BucketService: mock.NewBucketService(),
+					findBucketByNameFn: func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+						defer track()()
+						time.Sleep(lookupLatency)
+						return nil, errors.New("not found")
+					},
+				}
+				svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+				start := time.Now()
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, newPkg(t))
+				require.NoError(t, err)
+				elapsed := time.Since(start)
+
+				assert.True(t, elapsed < numBuckets*lookupLatency/2, "dry run took %s", elapsed)
+				assert.True(t, maxInFlight > 1, "lookups were not concurrent")
+				assert.True(t, maxInFlight <= int64(svc.applyReqLimit), "exceeded request limit: %d", maxInFlight)
+
+				require.Len(t, diff.Buckets, numBuckets)
+				for i, b := range diff.Buckets {
+					assert.Equal(t, fmt.Sprintf("rucket_%02d", i+1), b.Name)
+				}
+			})
+
+			t.Run("label mappings of existing resources are safe to diff concurrently", func(t *testing.T) {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					id, err := strconv.Atoi(strings.TrimPrefix(name, "rucket_"))
+					if err != nil {
+						return nil, err
+					}
+					return &influxdb.Bucket{ID: influxdb.ID(id), OrgID: orgID, Name: name}, nil
+				}
+				fakeLabelSVC := mock.NewLabelService()
+				fakeLabelSVC.FindResourceLabelsFn = func(_ context.Context, filter influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+					if filter.ResourceID%2 == 0 {
+						return nil, nil
+					}
+					return []*influxdb.Label{{ID: 1, Name: "label_1"}}, nil
+				}
+				svc := newTestService(WithBucketSVC(fakeBktSVC), WithLabelSVC(fakeLabelSVC))
+
+				pkg := newPkg(t)
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.NoError(t, err)
+
+				require.Len(t, diff.LabelMappings, 2*numBuckets)
+				for i, m := range diff.LabelMappings {
+					bktNum := i/2 + 1
+					assert.Equal(t, fmt.Sprintf("rucket_%02d", bktNum), m.ResName)
+					assert.Equal(t, fmt.Sprintf("label_%d", i%2+1), m.LabelName)
+					assert.Equal(t, m.LabelName == "label_2" || bktNum%2 == 0, m.IsNew)
+				}
+
+				assert.Len(t, pkg.mLabels["label_1"].mappings, numBuckets)
+				assert.Len(t, pkg.mLabels["label_2"].mappings, numBuckets)
+			})
+		})
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		t.Run("returns on a canceled context before any change", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					t.Error("bucket looked up with a canceled context")
+					return nil, errors.New("unexpected lookup")
+				}
+				fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+					t.Error("bucket created with a canceled context")
+					return nil
+				}
+				svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				_, err := svc.Apply(ctx, influxdb.ID(9000), 0, pkg)
+				require.Equal(t, context.Canceled, err)
+			})
+		})
+
+		t.Run("buckets", func(t *testing.T) {
+			t.Run("successfully creates pkg of buckets", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
 					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
 						b.ID = influxdb.ID(b.RetentionPeriod)
 						return nil
@@ -343,7 +1219,7 @@ func TestService(t *testing.T) {
 				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
 					orgID := influxdb.ID(9000)
 
-					pkg.isVerified = true
+					pkg.verify(ApplyOpt{})
 					pkgBkt := pkg.mBuckets["rucket_11"]
 					pkgBkt.existing = &influxdb.Bucket{
 						// makes all pkg changes same as they are on thes existing bucket
@@ -390,8 +1266,11 @@ func TestService(t *testing.T) {
 						return nil
 					}
 
-					pkg.mBuckets["copybuck1"] = pkg.mBuckets["rucket_11"]
-					pkg.mBuckets["copybuck2"] = pkg.mBuckets["rucket_11"]
+					// copies are distinct values, the dry run looks up each
+					// bucket concurrently
+					copyBkt1, copyBkt2 := *pkg.mBuckets["rucket_11"], *pkg.mBuckets["rucket_11"]
+					pkg.mBuckets["copybuck1"] = &copyBkt1
+					pkg.mBuckets["copybuck2"] = &copyBkt2
 
 					svc := newTestService(WithBucketSVC(fakeBktSVC))
 
@@ -403,557 +1282,2674 @@ func TestService(t *testing.T) {
 					assert.GreaterOrEqual(t, fakeBktSVC.DeleteBucketCalls.Count(), 1)
 				})
 			})
-		})
 
-		t.Run("labels", func(t *testing.T) {
-			t.Run("successfully creates pkg of labels", func(t *testing.T) {
-				testfileRunner(t, "testdata/label", func(t *testing.T, pkg *Pkg) {
+			t.Run("rolls back in reverse of the apply order and reports the rollback", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+					var (
+						mu      sync.Mutex
+						deleted []string
+						nextID  uint64
+					)
+					record := func(resource string) {
+						mu.Lock()
+						defer mu.Unlock()
+						deleted = append(deleted, resource)
+					}
+					newID := func() influxdb.ID {
+						return influxdb.ID(atomic.AddUint64(&nextID, 1))
+					}
+
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+						// forces the bucket to be created a new
+						return nil, errors.New("an error")
+					}
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						b.ID = newID()
+						return nil
+					}
+					fakeBktSVC.DeleteBucketFn = func(context.Context, influxdb.ID) error {
+						record("bucket")
+						return nil
+					}
+
 					fakeLabelSVC := mock.NewLabelService()
 					fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
-						i, err := strconv.Atoi(l.Name[len(l.Name)-1:])
-						if err != nil {
-							return err
+						l.ID = newID()
+						return nil
+					}
+					fakeLabelSVC.DeleteLabelFn = func(context.Context, influxdb.ID) error {
+						record("label")
+						return errors.New("label in use")
+					}
+					var failed int32
+					fakeLabelSVC.CreateLabelMappingFn = func(context.Context, *influxdb.LabelMapping) error {
+						// fail exactly one of the mappings, the rest are created
+						if atomic.CompareAndSwapInt32(&failed, 0, 1) {
+							return errors.New("hit failing label")
 						}
-						l.ID = influxdb.ID(i)
+						return nil
+					}
+					fakeLabelSVC.DeleteLabelMappingFn = func(context.Context, *influxdb.LabelMapping) error {
+						record("label_mapping")
 						return nil
 					}
 
-					svc := newTestService(WithLabelSVC(fakeLabelSVC))
+					svc := newTestService(
+						WithBucketSVC(fakeBktSVC),
+						WithLabelSVC(fakeLabelSVC),
+						WithLogger(zaptest.NewLogger(t)),
+					)
 
-					orgID := influxdb.ID(9000)
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
 
-					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.NoError(t, err)
+					// the mappings are removed before the buckets and labels they map
+					expDeleted := []string{
+						"label_mapping", "label_mapping", "label_mapping",
+						"bucket", "bucket", "bucket",
+						"label", "label",
+					}
+					assert.Equal(t, expDeleted, deleted)
 
-					require.Len(t, sum.Labels, 2)
-					label1 := sum.Labels[0]
-					assert.Equal(t, SafeID(1), label1.ID)
-					assert.Equal(t, SafeID(orgID), label1.OrgID)
-					assert.Equal(t, "label_1", label1.Name)
-					assert.Equal(t, "#FFFFFF", label1.Properties.Color)
-					assert.Equal(t, "label 1 description", label1.Properties.Description)
+					rbErr, ok := err.(*RollbackError)
+					require.True(t, ok, "expected a *RollbackError, got %T", err)
+					assert.Contains(t, rbErr.Err.Error(), "hit failing label")
+					assert.Contains(t, err.Error(), "failed to roll back")
 
-					label2 := sum.Labels[1]
-					assert.Equal(t, SafeID(2), label2.ID)
-					assert.Equal(t, SafeID(orgID), label2.OrgID)
-					assert.Equal(t, "label_2", label2.Name)
-					assert.Equal(t, "#000000", label2.Properties.Color)
-					assert.Equal(t, "label 2 description", label2.Properties.Description)
+					var resources []string
+					for _, res := range rbErr.Report.Results {
+						resources = append(resources, res.Resource)
+					}
+					expResources := []string{
+						"label_mapping",
+						"check", "notification_rules", "scraper_target",
+						"variable", "bucket", "dashboard", "notification_endpoints", "tasks", "telegrafs",
+						"label", "secrets",
+					}
+					assert.Equal(t, expResources, resources)
+
+					failures := rbErr.Report.Failures()
+					require.Len(t, failures, 1)
+					assert.Equal(t, "label", failures[0].Resource)
+					assert.Contains(t, failures[0].Msg, "unable to delete label")
 				})
 			})
 
-			t.Run("rolls back all created labels on an error", func(t *testing.T) {
-				testfileRunner(t, "testdata/label", func(t *testing.T, pkg *Pkg) {
+			t.Run("best effort keeps the buckets applied when one fails", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+						// forces the bucket to be created a new
+						return nil, errors.New("an error")
+					}
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						if b.Name == "rucket_2" {
+							return errors.New("blowed up ")
+						}
+						b.ID = influxdb.ID(rand.Int())
+						return nil
+					}
 					fakeLabelSVC := mock.NewLabelService()
 					fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
-						// 3rd/4th label will return the error here, and 2 before should be rolled back
-						if fakeLabelSVC.CreateLabelCalls.Count() == 2 {
-							return errors.New("blowed up ")
+						l.ID = influxdb.ID(rand.Int())
+						return nil
+					}
+					fakeLabelSVC.CreateLabelMappingFn = func(_ context.Context, mapping *influxdb.LabelMapping) error {
+						if mapping.ResourceID == 0 {
+							return errors.New("did not get a resource ID")
 						}
 						return nil
 					}
 
-					pkg.mLabels["copy1"] = pkg.mLabels["label_1"]
-					pkg.mLabels["copy2"] = pkg.mLabels["label_2"]
-
-					svc := newTestService(WithLabelSVC(fakeLabelSVC))
+					svc := newTestService(WithBucketSVC(fakeBktSVC), WithLabelSVC(fakeLabelSVC))
 
 					orgID := influxdb.ID(9000)
 
-					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.Error(t, err)
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg, ApplyWithBestEffort())
+					require.NoError(t, err)
 
-					assert.GreaterOrEqual(t, fakeLabelSVC.DeleteLabelCalls.Count(), 1)
+					require.Len(t, sum.Errors, 1)
+					assert.Equal(t, "bucket", sum.Errors[0].Resource)
+					assert.Equal(t, "rucket_2", sum.Errors[0].Name)
+					assert.Contains(t, sum.Errors[0].Msg, "blowed up")
+
+					assert.Equal(t, 3, fakeBktSVC.CreateBucketCalls.Count())
+					assert.Zero(t, fakeBktSVC.DeleteBucketCalls.Count())
+					assert.Zero(t, fakeLabelSVC.DeleteLabelCalls.Count())
+					// rucket_1 and rucket_3 are mapped to their labels, the failed rucket_2 is not
+					assert.Equal(t, 3, fakeLabelSVC.CreateLabelMappingCalls.Count())
 				})
 			})
 
-			t.Run("will not apply label if no changes to be applied", func(t *testing.T) {
-				testfileRunner(t, "testdata/label", func(t *testing.T, pkg *Pkg) {
-					orgID := influxdb.ID(9000)
+			t.Run("best effort cannot be verified", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					svc := newTestService()
 
-					pkg.isVerified = true
-					pkgLabel := pkg.mLabels["label_1"]
-					pkgLabel.existing = &influxdb.Label{
-						// makes all pkg changes same as they are on the existing
-						ID:    influxdb.ID(1),
-						OrgID: orgID,
-						Name:  pkgLabel.Name(),
-						Properties: map[string]string{
-							"color":       pkgLabel.Color,
-							"description": pkgLabel.Description,
-						},
-					}
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg, ApplyWithBestEffort(), ApplyWithVerification())
+					require.Error(t, err)
+					assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+				})
+			})
 
-					fakeLabelSVC := mock.NewLabelService()
-					fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
-						if l.Name == "label_2" {
-							l.ID = influxdb.ID(2)
-							return nil
-						}
-						return nil
+			t.Run("dry runs again when the pkg is modified after the dry run", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+						return nil, &influxdb.Error{Code: influxdb.ENotFound}
 					}
-					fakeLabelSVC.UpdateLabelFn = func(_ context.Context, id influxdb.ID, l influxdb.LabelUpdate) (*influxdb.Label, error) {
-						if id == influxdb.ID(3) {
-							return nil, errors.New("invalid id provided")
-						}
-						return &influxdb.Label{ID: id}, nil
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						b.ID = influxdb.ID(fakeBktSVC.CreateBucketCalls.Count() + 1)
+						return nil
 					}
 
-					svc := newTestService(WithLabelSVC(fakeLabelSVC))
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
 
-					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					orgID := influxdb.ID(9000)
+					dryRunSum, _, err := svc.DryRun(context.TODO(), orgID, 0, pkg)
 					require.NoError(t, err)
+					require.NotEmpty(t, dryRunSum.ContentHash)
+					require.Len(t, dryRunSum.Buckets, 1)
 
-					require.Len(t, sum.Labels, 2)
-					label1 := sum.Labels[0]
-					assert.Equal(t, SafeID(1), label1.ID)
-					assert.Equal(t, SafeID(orgID), label1.OrgID)
-					assert.Equal(t, "label_1", label1.Name)
-					assert.Equal(t, "#FFFFFF", label1.Properties.Color)
-					assert.Equal(t, "label 1 description", label1.Properties.Description)
+					pkg.Spec.Resources = append(pkg.Spec.Resources, Resource{
+						fieldKind: KindBucket.title(),
+						fieldName: "rucket_22",
+					})
 
-					label2 := sum.Labels[1]
-					assert.Equal(t, SafeID(2), label2.ID)
-					assert.Equal(t, SafeID(orgID), label2.OrgID)
-					assert.Equal(t, "label_2", label2.Name)
-					assert.Equal(t, "#000000", label2.Properties.Color)
-					assert.Equal(t, "label 2 description", label2.Properties.Description)
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
 
-					assert.Equal(t, 1, fakeLabelSVC.CreateLabelCalls.Count()) // only called for second label
+					require.Len(t, sum.Buckets, 2)
+					assert.NotEqual(t, dryRunSum.ContentHash, sum.ContentHash)
+					// one bucket by the first dry run, both by the dry run of the modified pkg
+					assert.Equal(t, 3, fakeBktSVC.FindBucketByNameCalls.Count())
+					assert.Equal(t, 2, fakeBktSVC.CreateBucketCalls.Count())
 				})
 			})
-		})
 
-		t.Run("dashboards", func(t *testing.T) {
-			t.Run("successfully creates a dashboard", func(t *testing.T) {
-				testfileRunner(t, "testdata/dashboard.yml", func(t *testing.T, pkg *Pkg) {
-					fakeDashSVC := mock.NewDashboardService()
-					fakeDashSVC.CreateDashboardF = func(_ context.Context, d *influxdb.Dashboard) error {
-						d.ID = influxdb.ID(1)
-						return nil
+			t.Run("updates bucket created concurrently after the dry run", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					var concurrentlyCreated *influxdb.Bucket
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+						if concurrentlyCreated == nil {
+							return nil, &influxdb.Error{Code: influxdb.ENotFound}
+						}
+						return concurrentlyCreated, nil
 					}
-					fakeDashSVC.UpdateDashboardCellViewF = func(ctx context.Context, dID influxdb.ID, cID influxdb.ID, upd influxdb.ViewUpdate) (*influxdb.View, error) {
-						return &influxdb.View{}, nil
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						// simulates a concurrent apply creating the same bucket
+						// between the dry run and the create
+						concurrentlyCreated = &influxdb.Bucket{
+							ID:              3,
+							OrgID:           orgID,
+							Name:            b.Name,
+							RetentionPeriod: 2 * time.Hour,
+						}
+						return &influxdb.Error{
+							Code: influxdb.EConflict,
+							Msg:  "bucket already exists",
+						}
+					}
+					fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+						if id != 3 {
+							return nil, errors.New("wrong id")
+						}
+						return &influxdb.Bucket{ID: id, OrgID: orgID, Name: "rucket_11"}, nil
 					}
 
-					svc := newTestService(WithDashboardSVC(fakeDashSVC))
-
-					orgID := influxdb.ID(9000)
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
 
 					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
 					require.NoError(t, err)
 
-					require.Len(t, sum.Dashboards, 1)
-					dash1 := sum.Dashboards[0]
-					assert.Equal(t, SafeID(1), dash1.ID)
-					assert.Equal(t, SafeID(orgID), dash1.OrgID)
-					assert.Equal(t, "dash_1", dash1.Name)
-					require.Len(t, dash1.Charts, 1)
+					require.Len(t, sum.Buckets, 1)
+					buck1 := sum.Buckets[0]
+					assert.Equal(t, SafeID(3), buck1.ID)
+					assert.Equal(t, "rucket_11", buck1.Name)
+					assert.Equal(t, 1, fakeBktSVC.CreateBucketCalls.Count())
+					assert.Equal(t, 1, fakeBktSVC.UpdateBucketCalls.Count())
+					assert.Zero(t, fakeBktSVC.DeleteBucketCalls.Count())
 				})
 			})
 
-			t.Run("rolls back created dashboard on an error", func(t *testing.T) {
-				testfileRunner(t, "testdata/dashboard.yml", func(t *testing.T, pkg *Pkg) {
-					fakeDashSVC := mock.NewDashboardService()
-					fakeDashSVC.CreateDashboardF = func(_ context.Context, d *influxdb.Dashboard) error {
-						// error out on second dashboard attempted
-						if fakeDashSVC.CreateDashboardCalls.Count() == 1 {
-							return errors.New("blowed up ")
-						}
-						d.ID = influxdb.ID(1)
-						return nil
+			t.Run("retries create when the conflicting bucket is removed", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+						return nil, &influxdb.Error{Code: influxdb.ENotFound}
 					}
-					deletedDashs := make(map[influxdb.ID]bool)
-					fakeDashSVC.DeleteDashboardF = func(_ context.Context, id influxdb.ID) error {
-						deletedDashs[id] = true
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						if fakeBktSVC.CreateBucketCalls.Count() < maxCreateConflictRetries-1 {
+							return &influxdb.Error{Code: influxdb.EConflict}
+						}
+						b.ID = 4
 						return nil
 					}
 
-					pkg.mDashboards = append(pkg.mDashboards, pkg.mDashboards[0])
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
 
-					svc := newTestService(WithDashboardSVC(fakeDashSVC))
+					sum, err := svc.Apply(context.TODO(), 9000, 0, pkg)
+					require.NoError(t, err)
 
-					orgID := influxdb.ID(9000)
+					require.Len(t, sum.Buckets, 1)
+					assert.Equal(t, SafeID(4), sum.Buckets[0].ID)
+					assert.Equal(t, maxCreateConflictRetries, fakeBktSVC.CreateBucketCalls.Count())
+				})
+			})
+		})
 
-					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.Error(t, err)
+		t.Run("with collision strategy", func(t *testing.T) {
+			newSVC := func(orgID influxdb.ID) (*Service, *mock.BucketService) {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+					return &influxdb.Bucket{
+						ID:              3,
+						OrgID:           orgID,
+						Name:            name,
+						Description:     "existing description",
+						RetentionPeriod: 2 * time.Hour,
+					}, nil
+				}
+				fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+					return &influxdb.Bucket{ID: id}, nil
+				}
+				return newTestService(WithBucketSVC(fakeBktSVC)), fakeBktSVC
+			}
 
-					assert.True(t, deletedDashs[1])
+			t.Run("dry run reports the colliding bucket", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					svc, _ := newSVC(9000)
+
+					_, diff, err := svc.DryRun(context.TODO(), 9000, 0, pkg, ApplyWithCollisionStrategy(CollisionSkip))
+					require.NoError(t, err)
+
+					expected := []DiffCollision{{Kind: KindBucket, Name: "rucket_11", Strategy: CollisionSkip}}
+					assert.Equal(t, expected, diff.Collisions)
 				})
 			})
-		})
 
-		t.Run("label mapping", func(t *testing.T) {
-			testLabelMappingFn := func(t *testing.T, filename string, numExpected int, settersFn func() []ServiceSetterFn) {
-				t.Run("applies successfully", func(t *testing.T) {
-					testfileRunner(t, filename, func(t *testing.T, pkg *Pkg) {
-						fakeLabelSVC := mock.NewLabelService()
-						fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
-							l.ID = influxdb.ID(rand.Int())
-							return nil
-						}
-						fakeLabelSVC.CreateLabelMappingFn = func(_ context.Context, mapping *influxdb.LabelMapping) error {
-							if mapping.ResourceID == 0 {
-								return errors.New("did not get a resource ID")
-							}
-							if mapping.ResourceType == "" {
-								return errors.New("did not get a resource type")
-							}
-							return nil
-						}
-						svc := newTestService(append(settersFn(),
-							WithLabelSVC(fakeLabelSVC),
-							WithLogger(zaptest.NewLogger(t)),
-						)...)
+			t.Run("update updates the existing bucket", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					svc, fakeBktSVC := newSVC(9000)
 
-						orgID := influxdb.ID(9000)
+					sum, err := svc.Apply(context.TODO(), 9000, 0, pkg, ApplyWithCollisionStrategy(CollisionUpdate))
+					require.NoError(t, err)
 
-						_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-						require.NoError(t, err)
+					require.Len(t, sum.Buckets, 1)
+					assert.Equal(t, SafeID(3), sum.Buckets[0].ID)
+					assert.Empty(t, sum.Skipped)
+					assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+					assert.Equal(t, 1, fakeBktSVC.UpdateBucketCalls.Count())
+				})
+			})
 
-						assert.Equal(t, numExpected, fakeLabelSVC.CreateLabelMappingCalls.Count())
+			t.Run("fail fails the apply without applying anything", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					svc, fakeBktSVC := newSVC(9000)
+
+					_, err := svc.Apply(context.TODO(), 9000, 0, pkg, ApplyWithCollisionStrategy(CollisionFail))
+					require.Error(t, err)
+
+					assert.Equal(t, influxdb.EConflict, influxdb.ErrorCode(err))
+					assert.Contains(t, err.Error(), `bucket "rucket_11"`)
+					assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+					assert.Zero(t, fakeBktSVC.UpdateBucketCalls.Count())
+				})
+			})
+
+			t.Run("skip leaves the existing bucket as it is", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					svc, fakeBktSVC := newSVC(9000)
+
+					sum, err := svc.Apply(context.TODO(), 9000, 0, pkg, ApplyWithCollisionStrategy(CollisionSkip))
+					require.NoError(t, err)
+
+					expected := []SummarySkippedResource{{
+						Kind:   KindBucket,
+						Name:   "rucket_11",
+						Reason: "name collides with an existing resource",
+					}}
+					assert.Equal(t, expected, sum.Skipped)
+					assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+					assert.Zero(t, fakeBktSVC.UpdateBucketCalls.Count())
+				})
+			})
+
+			t.Run("unknown strategy is invalid", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					svc, _ := newSVC(9000)
+
+					_, err := svc.Apply(context.TODO(), 9000, 0, pkg, ApplyWithCollisionStrategy("rename"))
+					require.Error(t, err)
+					assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+				})
+			})
+		})
+
+		t.Run("with only changed", func(t *testing.T) {
+			newSVC := func(orgID influxdb.ID, description string) (*Service, *mock.BucketService) {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+					return &influxdb.Bucket{
+						ID:              3,
+						OrgID:           orgID,
+						Name:            name,
+						Description:     description,
+						RetentionPeriod: time.Hour,
+					}, nil
+				}
+				fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+					return &influxdb.Bucket{ID: id}, nil
+				}
+				return newTestService(WithBucketSVC(fakeBktSVC)), fakeBktSVC
+			}
+
+			t.Run("unchanged bucket is not updated", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					svc, fakeBktSVC := newSVC(9000, "bucket 1 description")
+
+					sum, err := svc.Apply(context.TODO(), 9000, 0, pkg, ApplyWithOnlyChanged())
+					require.NoError(t, err)
+
+					require.Len(t, sum.Buckets, 1)
+					assert.Equal(t, SafeID(3), sum.Buckets[0].ID)
+					expected := []SummaryResourceStatus{{Kind: KindBucket, Name: "rucket_11", ID: 3, Status: ApplyStatusUnchanged}}
+					assert.Equal(t, expected, sum.Statuses)
+					assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+					assert.Zero(t, fakeBktSVC.UpdateBucketCalls.Count())
+				})
+			})
+
+			t.Run("changed bucket is updated", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					svc, fakeBktSVC := newSVC(9000, "existing description")
+
+					sum, err := svc.Apply(context.TODO(), 9000, 0, pkg, ApplyWithOnlyChanged())
+					require.NoError(t, err)
+
+					expected := []SummaryResourceStatus{{Kind: KindBucket, Name: "rucket_11", ID: 3, Status: ApplyStatusUpdated}}
+					assert.Equal(t, expected, sum.Statuses)
+					assert.Equal(t, 1, fakeBktSVC.UpdateBucketCalls.Count())
+				})
+			})
+		})
+
+		t.Run("with verification", func(t *testing.T) {
+			newBktSVC := func(readBack func(id influxdb.ID) (*influxdb.Bucket, error)) *mock.BucketService {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+					return nil, &influxdb.Error{Code: influxdb.ENotFound}
+				}
+				fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+					b.ID = 1
+					return nil
+				}
+				fakeBktSVC.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+					return readBack(id)
+				}
+				fakeBktSVC.DeleteBucketFn = func(_ context.Context, id influxdb.ID) error {
+					return nil
+				}
+				return fakeBktSVC
+			}
+
+			t.Run("succeeds when resources read back match the pkg", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := newBktSVC(func(id influxdb.ID) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{
+							ID:              id,
+							Name:            "rucket_11",
+							Description:     "bucket 1 description",
+							RetentionPeriod: time.Hour,
+						}, nil
 					})
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					sum, err := svc.Apply(context.TODO(), 9000, 0, pkg, ApplyWithVerification())
+					require.NoError(t, err)
+
+					require.Len(t, sum.Buckets, 1)
+					assert.Equal(t, 1, fakeBktSVC.FindBucketByIDCalls.Count())
+					assert.Zero(t, fakeBktSVC.DeleteBucketCalls.Count())
 				})
+			})
 
-				t.Run("deletes new label mappings on error", func(t *testing.T) {
-					testfileRunner(t, filename, func(t *testing.T, pkg *Pkg) {
-						for _, l := range pkg.mLabels {
-							for resource, vals := range l.mappings {
-								// create extra label mappings, enough for delete to ahve head room
-								l.mappings[resource] = append(l.mappings[resource], vals...)
-								l.mappings[resource] = append(l.mappings[resource], vals...)
-								l.mappings[resource] = append(l.mappings[resource], vals...)
-							}
+			t.Run("rolls back when resources read back do not match the pkg", func(t *testing.T) {
+				tests := []struct {
+					name     string
+					readBack func(id influxdb.ID) (*influxdb.Bucket, error)
+					errMsg   string
+				}{
+					{
+						name: "missing",
+						readBack: func(id influxdb.ID) (*influxdb.Bucket, error) {
+							// lies about having created the bucket
+							return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+						},
+						errMsg: "unable to read back",
+					},
+					{
+						name: "mismatched",
+						readBack: func(id influxdb.ID) (*influxdb.Bucket, error) {
+							return &influxdb.Bucket{
+								ID:              id,
+								Name:            "rucket_11",
+								Description:     "bucket 1 description",
+								RetentionPeriod: 2 * time.Hour,
+							}, nil
+						},
+						errMsg: "retention period: expected 1h0m0s, got 2h0m0s",
+					},
+				}
+
+				for _, tt := range tests {
+					fn := func(t *testing.T) {
+						testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+							fakeBktSVC := newBktSVC(tt.readBack)
+
+							svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+							_, err := svc.Apply(context.TODO(), 9000, 0, pkg, ApplyWithVerification())
+							require.Error(t, err)
+
+							assert.Contains(t, err.Error(), `resource_type="bucket" err="failed verification"`)
+							assert.Contains(t, err.Error(), `name="rucket_11"`)
+							assert.Contains(t, err.Error(), tt.errMsg)
+							assert.Equal(t, 1, fakeBktSVC.DeleteBucketCalls.Count())
+						})
+					}
+					t.Run(tt.name, fn)
+				}
+			})
+		})
+
+		t.Run("labels", func(t *testing.T) {
+			t.Run("successfully creates pkg of labels", func(t *testing.T) {
+				testfileRunner(t, "testdata/label", func(t *testing.T, pkg *Pkg) {
+					fakeLabelSVC := mock.NewLabelService()
+					fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+						i, err := strconv.Atoi(l.Name[len(l.Name)-1:])
+						if err != nil {
+							return err
+						}
+						l.ID = influxdb.ID(i)
+						return nil
+					}
+
+					svc := newTestService(WithLabelSVC(fakeLabelSVC))
+
+					orgID := influxdb.ID(9000)
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Labels, 2)
+					label1 := sum.Labels[0]
+					assert.Equal(t, SafeID(1), label1.ID)
+					assert.Equal(t, SafeID(orgID), label1.OrgID)
+					assert.Equal(t, "label_1", label1.Name)
+					assert.Equal(t, "#FFFFFF", label1.Properties.Color)
+					assert.Equal(t, "label 1 description", label1.Properties.Description)
+
+					label2 := sum.Labels[1]
+					assert.Equal(t, SafeID(2), label2.ID)
+					assert.Equal(t, SafeID(orgID), label2.OrgID)
+					assert.Equal(t, "label_2", label2.Name)
+					assert.Equal(t, "#000000", label2.Properties.Color)
+					assert.Equal(t, "label 2 description", label2.Properties.Description)
+				})
+			})
+
+			t.Run("rolls back all created labels on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/label", func(t *testing.T, pkg *Pkg) {
+					fakeLabelSVC := mock.NewLabelService()
+					fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+						// 3rd/4th label will return the error here, and 2 before should be rolled back
+						if fakeLabelSVC.CreateLabelCalls.Count() == 2 {
+							return errors.New("blowed up ")
 						}
+						return nil
+					}
 
-						fakeLabelSVC := mock.NewLabelService()
-						fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
-							l.ID = influxdb.ID(fakeLabelSVC.CreateLabelCalls.Count())
+					// copies are distinct values, the dry run looks up each
+					// label concurrently
+					copyLabel1, copyLabel2 := *pkg.mLabels["label_1"], *pkg.mLabels["label_2"]
+					pkg.mLabels["copy1"] = &copyLabel1
+					pkg.mLabels["copy2"] = &copyLabel2
+
+					svc := newTestService(WithLabelSVC(fakeLabelSVC))
+
+					orgID := influxdb.ID(9000)
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.Error(t, err)
+
+					assert.GreaterOrEqual(t, fakeLabelSVC.DeleteLabelCalls.Count(), 1)
+				})
+			})
+
+			t.Run("will not apply label if no changes to be applied", func(t *testing.T) {
+				testfileRunner(t, "testdata/label", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					pkg.verify(ApplyOpt{})
+					pkgLabel := pkg.mLabels["label_1"]
+					pkgLabel.existing = &influxdb.Label{
+						// makes all pkg changes same as they are on the existing
+						ID:    influxdb.ID(1),
+						OrgID: orgID,
+						Name:  pkgLabel.Name(),
+						Properties: map[string]string{
+							"color":       pkgLabel.Color,
+							"description": pkgLabel.Description,
+						},
+					}
+
+					fakeLabelSVC := mock.NewLabelService()
+					fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+						if l.Name == "label_2" {
+							l.ID = influxdb.ID(2)
 							return nil
 						}
-						fakeLabelSVC.CreateLabelMappingFn = func(_ context.Context, mapping *influxdb.LabelMapping) error {
-							if mapping.ResourceID == 0 {
-								return errors.New("did not get a resource ID")
-							}
-							if mapping.ResourceType == "" {
-								return errors.New("did not get a resource type")
-							}
-							if fakeLabelSVC.CreateLabelMappingCalls.Count() > numExpected {
-								return errors.New("hit last label")
-							}
-							return nil
+						return nil
+					}
+					fakeLabelSVC.UpdateLabelFn = func(_ context.Context, id influxdb.ID, l influxdb.LabelUpdate) (*influxdb.Label, error) {
+						if id == influxdb.ID(3) {
+							return nil, errors.New("invalid id provided")
 						}
-						svc := newTestService(append(settersFn(),
-							WithLabelSVC(fakeLabelSVC),
-							WithLogger(zaptest.NewLogger(t)),
-						)...)
+						return &influxdb.Label{ID: id}, nil
+					}
+
+					svc := newTestService(WithLabelSVC(fakeLabelSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Labels, 2)
+					label1 := sum.Labels[0]
+					assert.Equal(t, SafeID(1), label1.ID)
+					assert.Equal(t, SafeID(orgID), label1.OrgID)
+					assert.Equal(t, "label_1", label1.Name)
+					assert.Equal(t, "#FFFFFF", label1.Properties.Color)
+					assert.Equal(t, "label 1 description", label1.Properties.Description)
+
+					label2 := sum.Labels[1]
+					assert.Equal(t, SafeID(2), label2.ID)
+					assert.Equal(t, SafeID(orgID), label2.OrgID)
+					assert.Equal(t, "label_2", label2.Name)
+					assert.Equal(t, "#000000", label2.Properties.Color)
+					assert.Equal(t, "label 2 description", label2.Properties.Description)
+
+					assert.Equal(t, 1, fakeLabelSVC.CreateLabelCalls.Count()) // only called for second label
+				})
+			})
+
+			t.Run("updates only the changed properties of a label", func(t *testing.T) {
+				testfileRunner(t, "testdata/label", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					pkg.verify(ApplyOpt{})
+					pkgLabel := pkg.mLabels["label_1"]
+					pkgLabel.existing = &influxdb.Label{
+						ID:    influxdb.ID(1),
+						OrgID: orgID,
+						Name:  pkgLabel.Name(),
+						Properties: map[string]string{
+							"color":       "old color",
+							"description": pkgLabel.Description,
+						},
+					}
+
+					var updates []influxdb.LabelUpdate
+					fakeLabelSVC := mock.NewLabelService()
+					fakeLabelSVC.UpdateLabelFn = func(_ context.Context, id influxdb.ID, upd influxdb.LabelUpdate) (*influxdb.Label, error) {
+						updates = append(updates, upd)
+						return &influxdb.Label{ID: id}, nil
+					}
+
+					svc := newTestService(WithLabelSVC(fakeLabelSVC))
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					expected := influxdb.LabelUpdate{
+						Properties: map[string]string{"color": "#FFFFFF"},
+					}
+					assert.Equal(t, []influxdb.LabelUpdate{expected}, updates)
+				})
+			})
+		})
+
+		t.Run("reports the status of each resource applied", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket_associates_label", func(t *testing.T, pkg *Pkg) {
+				orgID := influxdb.ID(9000)
+
+				pkg.verify(ApplyOpt{})
+				pkgLabel := pkg.mLabels["label_1"]
+				pkgLabel.existing = &influxdb.Label{
+					ID:    influxdb.ID(1),
+					OrgID: orgID,
+					Name:  pkgLabel.Name(),
+				}
+				pkgBkt := pkg.mBuckets["rucket_1"]
+				pkgBkt.existing = &influxdb.Bucket{
+					ID:          influxdb.ID(2),
+					OrgID:       orgID,
+					Name:        pkgBkt.Name(),
+					Description: "old desc",
+				}
+
+				fakeBktSVC := mock.NewBucketService()
+				bktIDs := map[string]influxdb.ID{"rucket_2": 12, "rucket_3": 13}
+				fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+					b.ID = bktIDs[b.Name]
+					return nil
+				}
+				fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+					return &influxdb.Bucket{ID: id}, nil
+				}
+				fakeLabelSVC := mock.NewLabelService()
+				fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+					l.ID = influxdb.ID(20)
+					return nil
+				}
+
+				svc := newTestService(WithBucketSVC(fakeBktSVC), WithLabelSVC(fakeLabelSVC))
+
+				sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+				require.NoError(t, err)
+
+				expected := []SummaryResourceStatus{
+					{Kind: KindBucket, Name: "rucket_1", ID: 2, Status: ApplyStatusUpdated},
+					{Kind: KindBucket, Name: "rucket_2", ID: 12, Status: ApplyStatusCreated},
+					{Kind: KindBucket, Name: "rucket_3", ID: 13, Status: ApplyStatusCreated},
+					{Kind: KindLabel, Name: "label_1", ID: 1, Status: ApplyStatusUnchanged},
+					{Kind: KindLabel, Name: "label_2", ID: 20, Status: ApplyStatusCreated},
+				}
+				assert.Equal(t, expected, sum.Statuses)
+
+				expectedCounts := map[Kind]SummaryStatusCount{
+					KindBucket: {Created: 2, Updated: 1},
+					KindLabel:  {Created: 1, Unchanged: 1},
+				}
+				assert.Equal(t, expectedCounts, sum.StatusCounts)
+			})
+		})
+
+		t.Run("summary has the IDs of the resources applied", func(t *testing.T) {
+			var lastID uint64
+			nextID := func() influxdb.ID {
+				return influxdb.ID(atomic.AddUint64(&lastID, 1))
+			}
+
+			newSVC := func() *Service {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					if name != "org_rucket" {
+						return nil, &influxdb.Error{Code: influxdb.ENotFound}
+					}
+					return &influxdb.Bucket{ID: nextID(), OrgID: orgID, Name: name}, nil
+				}
+				fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+					b.ID = nextID()
+					return nil
+				}
+				fakeCheckSVC := mock.NewCheckService()
+				fakeCheckSVC.CreateCheckFn = func(_ context.Context, c influxdb.CheckCreate, _ influxdb.ID) error {
+					c.SetID(nextID())
+					return nil
+				}
+				fakeDashSVC := mock.NewDashboardService()
+				fakeDashSVC.CreateDashboardF = func(_ context.Context, d *influxdb.Dashboard) error {
+					d.ID = nextID()
+					return nil
+				}
+				fakeLabelSVC := mock.NewLabelService()
+				fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+					l.ID = nextID()
+					return nil
+				}
+				fakeEndpointSVC := mock.NewNotificationEndpointService()
+				fakeEndpointSVC.CreateNotificationEndpointF = func(_ context.Context, e influxdb.NotificationEndpoint, _ influxdb.ID) error {
+					e.SetID(nextID())
+					return nil
+				}
+				fakeRuleStore := mock.NewNotificationRuleStore()
+				fakeRuleStore.CreateNotificationRuleF = func(_ context.Context, r influxdb.NotificationRuleCreate, _ influxdb.ID) error {
+					r.SetID(nextID())
+					return nil
+				}
+				fakeScraperSVC := mock.NewScraperTargetStoreService()
+				fakeScraperSVC.AddTargetF = func(_ context.Context, st *influxdb.ScraperTarget, _ influxdb.ID) error {
+					st.ID = nextID()
+					return nil
+				}
+				fakeTeleSVC := mock.NewTelegrafConfigStore()
+				fakeTeleSVC.CreateTelegrafConfigF = func(_ context.Context, tc *influxdb.TelegrafConfig, _ influxdb.ID) error {
+					tc.ID = nextID()
+					return nil
+				}
+				fakeVarSVC := mock.NewVariableService()
+				fakeVarSVC.CreateVariableF = func(_ context.Context, v *influxdb.Variable) error {
+					v.ID = nextID()
+					return nil
+				}
+
+				return newTestService(
+					WithBucketSVC(fakeBktSVC),
+					WithCheckSVC(fakeCheckSVC),
+					WithDashboardSVC(fakeDashSVC),
+					WithLabelSVC(fakeLabelSVC),
+					WithNoticationEndpointSVC(fakeEndpointSVC),
+					WithNotificationRuleSVC(fakeRuleStore),
+					WithScraperTargetSVC(fakeScraperSVC),
+					WithTelegrafSVC(fakeTeleSVC),
+					WithVariableSVC(fakeVarSVC),
+				)
+			}
+
+			files := []string{
+				"testdata/bucket",
+				"testdata/check_threshold",
+				"testdata/dashboard",
+				"testdata/label",
+				"testdata/notification_endpoint",
+				"testdata/notification_rule",
+				"testdata/scraper_target",
+				"testdata/telegraf",
+				"testdata/variables",
+			}
+			for _, file := range files {
+				testfileRunner(t, file, func(t *testing.T, pkg *Pkg) {
+					sum, err := newSVC().Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+
+					for _, b := range sum.Buckets {
+						assert.NotZero(t, b.ID, b.Name)
+					}
+					for _, c := range sum.Checks {
+						assert.NotZero(t, c.Check.GetID(), c.Check.GetName())
+					}
+					for _, d := range sum.Dashboards {
+						assert.NotZero(t, d.ID, d.Name)
+					}
+					for _, l := range sum.Labels {
+						assert.NotZero(t, l.ID, l.Name)
+					}
+					for _, m := range sum.LabelMappings {
+						assert.NotZero(t, m.ResourceID, m.ResourceName)
+						assert.NotZero(t, m.LabelID, m.LabelName)
+					}
+					for _, e := range sum.NotificationEndpoints {
+						assert.NotZero(t, e.NotificationEndpoint.GetID(), e.NotificationEndpoint.GetName())
+					}
+					for _, r := range sum.NotificationRules {
+						assert.NotZero(t, r.ID, r.Name)
+					}
+					for _, st := range sum.ScraperTargets {
+						assert.NotZero(t, st.ID, st.Name)
+					}
+					for _, tc := range sum.TelegrafConfigs {
+						assert.NotZero(t, tc.TelegrafConfig.ID, tc.TelegrafConfig.Name)
+					}
+					for _, v := range sum.Variables {
+						assert.NotZero(t, v.ID, v.Name)
+					}
+
+					require.NotEmpty(t, sum.Statuses)
+					for _, status := range sum.Statuses {
+						assert.NotZero(t, status.ID, "%s %s", status.Kind, status.Name)
+					}
+				})
+			}
+		})
+
+		t.Run("dashboards", func(t *testing.T) {
+			t.Run("successfully creates a dashboard", func(t *testing.T) {
+				testfileRunner(t, "testdata/dashboard.yml", func(t *testing.T, pkg *Pkg) {
+					fakeDashSVC := mock.NewDashboardService()
+					fakeDashSVC.CreateDashboardF = func(_ context.Context, d *influxdb.Dashboard) error {
+						require.NotNil(t, d.Meta.RefreshInterval)
+						assert.Equal(t, time.Minute, d.Meta.RefreshInterval.Duration)
+						require.Len(t, d.Cells, 1)
+						require.NotNil(t, d.Cells[0].RefreshInterval)
+						assert.Equal(t, 30*time.Second, d.Cells[0].RefreshInterval.Duration)
+
+						d.ID = influxdb.ID(1)
+						return nil
+					}
+					fakeDashSVC.UpdateDashboardCellViewF = func(ctx context.Context, dID influxdb.ID, cID influxdb.ID, upd influxdb.ViewUpdate) (*influxdb.View, error) {
+						return &influxdb.View{}, nil
+					}
+
+					svc := newTestService(WithDashboardSVC(fakeDashSVC))
+
+					orgID := influxdb.ID(9000)
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Dashboards, 1)
+					dash1 := sum.Dashboards[0]
+					assert.Equal(t, SafeID(1), dash1.ID)
+					assert.Equal(t, SafeID(orgID), dash1.OrgID)
+					assert.Equal(t, "dash_1", dash1.Name)
+					require.Len(t, dash1.Charts, 1)
+				})
+			})
+
+			t.Run("rolls back created dashboard on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/dashboard.yml", func(t *testing.T, pkg *Pkg) {
+					fakeDashSVC := mock.NewDashboardService()
+					fakeDashSVC.CreateDashboardF = func(_ context.Context, d *influxdb.Dashboard) error {
+						// error out on second dashboard attempted
+						if fakeDashSVC.CreateDashboardCalls.Count() == 1 {
+							return errors.New("blowed up ")
+						}
+						d.ID = influxdb.ID(1)
+						return nil
+					}
+					deletedDashs := make(map[influxdb.ID]bool)
+					fakeDashSVC.DeleteDashboardF = func(_ context.Context, id influxdb.ID) error {
+						deletedDashs[id] = true
+						return nil
+					}
+
+					pkg.mDashboards = append(pkg.mDashboards, pkg.mDashboards[0])
+
+					svc := newTestService(WithDashboardSVC(fakeDashSVC))
+
+					orgID := influxdb.ID(9000)
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.Error(t, err)
+
+					assert.True(t, deletedDashs[1])
+				})
+			})
+
+			t.Run("re-applying with only changed leaves the dashboard unchanged", func(t *testing.T) {
+				testfileRunner(t, "testdata/dashboard.yml", func(t *testing.T, pkg *Pkg) {
+					var created []*influxdb.Dashboard
+					fakeDashSVC := mock.NewDashboardService()
+					fakeDashSVC.CreateDashboardF = func(_ context.Context, d *influxdb.Dashboard) error {
+						d.ID = influxdb.ID(len(created) + 1)
+						for i, cell := range d.Cells {
+							cell.ID = influxdb.ID(i + 1)
+						}
+						created = append(created, d)
+						return nil
+					}
+					fakeDashSVC.FindDashboardsF = func(_ context.Context, f influxdb.DashboardFilter, _ influxdb.FindOptions) ([]*influxdb.Dashboard, int, error) {
+						var out []*influxdb.Dashboard
+						for _, d := range created {
+							dash := *d
+							dash.Cells = nil
+							for _, cell := range d.Cells {
+								// the views are read separately, as the store does
+								dash.Cells = append(dash.Cells, &influxdb.Cell{ID: cell.ID, CellProperty: cell.CellProperty})
+							}
+							out = append(out, &dash)
+						}
+						return out, len(out), nil
+					}
+					fakeDashSVC.GetDashboardCellViewF = func(_ context.Context, dashID, cellID influxdb.ID) (*influxdb.View, error) {
+						// the views are stored as JSON, read them back the same
+						b, err := json.Marshal(created[dashID-1].Cells[cellID-1].View)
+						if err != nil {
+							return nil, err
+						}
+						var v influxdb.View
+						return &v, json.Unmarshal(b, &v)
+					}
+
+					svc := newTestService(WithDashboardSVC(fakeDashSVC))
+
+					reapplied := pkg.clone()
+
+					_, err := svc.Apply(context.TODO(), 9000, 0, pkg, ApplyWithOnlyChanged())
+					require.NoError(t, err)
+					require.Equal(t, 1, fakeDashSVC.CreateDashboardCalls.Count())
+
+					sum, err := svc.Apply(context.TODO(), 9000, 0, reapplied, ApplyWithOnlyChanged())
+					require.NoError(t, err)
+
+					assert.Equal(t, 1, fakeDashSVC.CreateDashboardCalls.Count())
+					require.Len(t, sum.Dashboards, 1)
+					assert.Equal(t, SafeID(1), sum.Dashboards[0].ID)
+					expected := []SummaryResourceStatus{{Kind: KindDashboard, Name: "dash_1", ID: 1, Status: ApplyStatusUnchanged}}
+					assert.Equal(t, expected, sum.Statuses)
+				})
+			})
+
+			t.Run("re-applying a changed dashboard with only changed creates it", func(t *testing.T) {
+				testfileRunner(t, "testdata/dashboard.yml", func(t *testing.T, pkg *Pkg) {
+					fakeDashSVC := mock.NewDashboardService()
+					fakeDashSVC.CreateDashboardF = func(_ context.Context, d *influxdb.Dashboard) error {
+						d.ID = influxdb.ID(2)
+						return nil
+					}
+					fakeDashSVC.FindDashboardsF = func(_ context.Context, f influxdb.DashboardFilter, _ influxdb.FindOptions) ([]*influxdb.Dashboard, int, error) {
+						return []*influxdb.Dashboard{{
+							ID:             1,
+							OrganizationID: 9000,
+							Name:           "dash_1",
+							Description:    "existing description",
+						}}, 1, nil
+					}
+
+					svc := newTestService(WithDashboardSVC(fakeDashSVC))
+
+					sum, err := svc.Apply(context.TODO(), 9000, 0, pkg, ApplyWithOnlyChanged())
+					require.NoError(t, err)
+
+					assert.Equal(t, 1, fakeDashSVC.CreateDashboardCalls.Count())
+					expected := []SummaryResourceStatus{{Kind: KindDashboard, Name: "dash_1", ID: 2, Status: ApplyStatusCreated}}
+					assert.Equal(t, expected, sum.Statuses)
+				})
+			})
+		})
+
+		t.Run("label mapping", func(t *testing.T) {
+			testLabelMappingFn := func(t *testing.T, filename string, numExpected int, settersFn func() []ServiceSetterFn) {
+				t.Run("applies successfully", func(t *testing.T) {
+					testfileRunner(t, filename, func(t *testing.T, pkg *Pkg) {
+						fakeLabelSVC := mock.NewLabelService()
+						fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+							l.ID = influxdb.ID(rand.Int())
+							return nil
+						}
+						fakeLabelSVC.CreateLabelMappingFn = func(_ context.Context, mapping *influxdb.LabelMapping) error {
+							if mapping.ResourceID == 0 {
+								return errors.New("did not get a resource ID")
+							}
+							if mapping.ResourceType == "" {
+								return errors.New("did not get a resource type")
+							}
+							return nil
+						}
+						svc := newTestService(append(settersFn(),
+							WithLabelSVC(fakeLabelSVC),
+							WithLogger(zaptest.NewLogger(t)),
+						)...)
+
+						orgID := influxdb.ID(9000)
+
+						_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+						require.NoError(t, err)
+
+						assert.Equal(t, numExpected, fakeLabelSVC.CreateLabelMappingCalls.Count())
+					})
+				})
+
+				t.Run("deletes new label mappings on error", func(t *testing.T) {
+					testfileRunner(t, filename, func(t *testing.T, pkg *Pkg) {
+						fakeLabelSVC := mock.NewLabelService()
+						fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+							l.ID = influxdb.ID(fakeLabelSVC.CreateLabelCalls.Count())
+							return nil
+						}
+						var failed int32
+						fakeLabelSVC.CreateLabelMappingFn = func(_ context.Context, mapping *influxdb.LabelMapping) error {
+							if mapping.ResourceID == 0 {
+								return errors.New("did not get a resource ID")
+							}
+							if mapping.ResourceType == "" {
+								return errors.New("did not get a resource type")
+							}
+							// fail exactly one of the mappings, the rest are created
+							if atomic.CompareAndSwapInt32(&failed, 0, 1) {
+								return errors.New("hit failing label")
+							}
+							return nil
+						}
+						svc := newTestService(append(settersFn(),
+							WithLabelSVC(fakeLabelSVC),
+							WithLogger(zaptest.NewLogger(t)),
+						)...)
+
+						orgID := influxdb.ID(9000)
+
+						_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+						require.Error(t, err)
+
+						assert.Equal(t, numExpected-1, fakeLabelSVC.DeleteLabelMappingCalls.Count())
+					})
+				})
+			}
+
+			t.Run("maps buckets with labels", func(t *testing.T) {
+				testLabelMappingFn(
+					t,
+					"testdata/bucket_associates_label.yml",
+					4,
+					func() []ServiceSetterFn {
+						fakeBktSVC := mock.NewBucketService()
+						fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+							b.ID = influxdb.ID(rand.Int())
+							return nil
+						}
+						fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+							// forces the bucket to be created a new
+							return nil, errors.New("an error")
+						}
+						return []ServiceSetterFn{WithBucketSVC(fakeBktSVC)}
+					},
+				)
+			})
+
+			t.Run("maps buckets declaring the same label twice", func(t *testing.T) {
+				testLabelMappingFn(
+					t,
+					"testdata/bucket_associates_label_duplicates.yml",
+					2,
+					func() []ServiceSetterFn {
+						fakeBktSVC := mock.NewBucketService()
+						fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+							b.ID = influxdb.ID(rand.Int())
+							return nil
+						}
+						fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+							// forces the bucket to be created a new
+							return nil, errors.New("an error")
+						}
+						return []ServiceSetterFn{WithBucketSVC(fakeBktSVC)}
+					},
+				)
+			})
+
+			t.Run("maps dashboards with labels", func(t *testing.T) {
+				testLabelMappingFn(
+					t,
+					"testdata/dashboard_associates_label.yml",
+					1,
+					func() []ServiceSetterFn {
+						fakeDashSVC := mock.NewDashboardService()
+						fakeDashSVC.CreateDashboardF = func(_ context.Context, d *influxdb.Dashboard) error {
+							d.ID = influxdb.ID(rand.Int())
+							return nil
+						}
+						return []ServiceSetterFn{WithDashboardSVC(fakeDashSVC)}
+					},
+				)
+			})
+
+			t.Run("maps telegrafs with labels", func(t *testing.T) {
+				testLabelMappingFn(
+					t,
+					"testdata/telegraf.yml",
+					1,
+					func() []ServiceSetterFn {
+						fakeTeleSVC := mock.NewTelegrafConfigStore()
+						fakeTeleSVC.CreateTelegrafConfigF = func(_ context.Context, cfg *influxdb.TelegrafConfig, _ influxdb.ID) error {
+							cfg.ID = influxdb.ID(rand.Int())
+							return nil
+						}
+						return []ServiceSetterFn{WithTelegrafSVC(fakeTeleSVC)}
+					},
+				)
+			})
+
+			t.Run("maps variables with labels", func(t *testing.T) {
+				testLabelMappingFn(
+					t,
+					"testdata/variable_associates_label.yml",
+					1,
+					func() []ServiceSetterFn {
+						fakeVarSVC := mock.NewVariableService()
+						fakeVarSVC.CreateVariableF = func(_ context.Context, v *influxdb.Variable) error {
+							v.ID = influxdb.ID(rand.Int())
+							return nil
+						}
+						return []ServiceSetterFn{WithVariableSVC(fakeVarSVC)}
+					},
+				)
+			})
+
+		})
+
+		t.Run("notification endpoints", func(t *testing.T) {
+			t.Run("successfully creates pkg of endpoints", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_endpoint.yml", func(t *testing.T, pkg *Pkg) {
+					fakeEndpointSVC := mock.NewNotificationEndpointService()
+					fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, nr influxdb.NotificationEndpoint, userID influxdb.ID) error {
+						nr.SetID(influxdb.ID(fakeEndpointSVC.CreateNotificationEndpointCalls.Count() + 1))
+						return nil
+					}
+
+					svc := newTestService(WithNoticationEndpointSVC(fakeEndpointSVC))
+
+					orgID := influxdb.ID(9000)
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.NotificationEndpoints, 5)
+
+					containsWithID := func(t *testing.T, name string) {
+						for _, actualNotification := range sum.NotificationEndpoints {
+							actual := actualNotification.NotificationEndpoint
+							if actual.GetID() == 0 {
+								assert.NotZero(t, actual.GetID())
+							}
+							if actual.GetName() == name {
+								return
+							}
+						}
+						assert.Fail(t, "did not find notification by name: "+name)
+					}
+
+					expectedNames := []string{
+						"http_basic_auth_notification_endpoint",
+						"http_bearer_auth_notification_endpoint",
+						"http_none_auth_notification_endpoint",
+						"pager_duty_notification_endpoint",
+						"slack_notification_endpoint",
+					}
+					for _, expectedName := range expectedNames {
+						containsWithID(t, expectedName)
+					}
+				})
+			})
+
+			t.Run("rolls back all created notifications on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_endpoint.yml", func(t *testing.T, pkg *Pkg) {
+					fakeEndpointSVC := mock.NewNotificationEndpointService()
+					fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, nr influxdb.NotificationEndpoint, userID influxdb.ID) error {
+						nr.SetID(influxdb.ID(fakeEndpointSVC.CreateNotificationEndpointCalls.Count() + 1))
+						if fakeEndpointSVC.CreateNotificationEndpointCalls.Count() == 5 {
+							return errors.New("hit that kill count")
+						}
+						return nil
+					}
+
+					// create some dupes
+					for name, endpoint := range pkg.mNotificationEndpoints {
+						pkg.mNotificationEndpoints["copy"+name] = endpoint
+					}
+
+					svc := newTestService(WithNoticationEndpointSVC(fakeEndpointSVC))
+
+					orgID := influxdb.ID(9000)
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.Error(t, err)
+
+					assert.GreaterOrEqual(t, fakeEndpointSVC.DeleteNotificationEndpointCalls.Count(), 5)
+				})
+			})
+		})
+
+		t.Run("scraper targets", func(t *testing.T) {
+			newBktSVC := func() *mock.BucketService {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					if name != "org_rucket" {
+						return nil, &influxdb.Error{Code: influxdb.ENotFound}
+					}
+					return &influxdb.Bucket{ID: influxdb.ID(7), OrgID: orgID, Name: name}, nil
+				}
+				fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+					b.ID = influxdb.ID(5)
+					return nil
+				}
+				return fakeBktSVC
+			}
+
+			t.Run("successfully creates targets bound to the pkg and org buckets", func(t *testing.T) {
+				testfileRunner(t, "testdata/scraper_target", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					fakeScraperSVC := mock.NewScraperTargetStoreService()
+					fakeScraperSVC.AddTargetF = func(_ context.Context, st *influxdb.ScraperTarget, userID influxdb.ID) error {
+						st.ID = influxdb.ID(fakeScraperSVC.AddTargetCalls.Count() + 1)
+						return nil
+					}
+
+					svc := newTestService(WithBucketSVC(newBktSVC()), WithScraperTargetSVC(fakeScraperSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.ScraperTargets, 2)
+					mTargets := make(map[string]SummaryScraperTarget)
+					for _, st := range sum.ScraperTargets {
+						mTargets[st.Name] = st
+					}
+
+					scraper1 := mTargets["scraper_1"]
+					assert.NotZero(t, scraper1.ID)
+					assert.Equal(t, SafeID(orgID), scraper1.OrgID)
+					assert.Equal(t, influxdb.ScraperType(influxdb.PrometheusScraperType), scraper1.Type)
+					assert.Equal(t, "http://localhost:9100/metrics", scraper1.URL)
+					assert.Equal(t, SafeID(5), scraper1.BucketID)
+					assert.Equal(t, "rucket_1", scraper1.Bucket)
+
+					scraper2 := mTargets["scraper_2"]
+					assert.Equal(t, SafeID(7), scraper2.BucketID)
+					assert.Equal(t, "org_rucket", scraper2.Bucket)
+				})
+			})
+
+			t.Run("rolls back all created targets on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/scraper_target", func(t *testing.T, pkg *Pkg) {
+					fakeScraperSVC := mock.NewScraperTargetStoreService()
+					fakeScraperSVC.AddTargetF = func(_ context.Context, st *influxdb.ScraperTarget, userID influxdb.ID) error {
+						if st.Name == "scraper_2" {
+							return errors.New("limit hit")
+						}
+						st.ID = influxdb.ID(1)
+						return nil
+					}
+					fakeScraperSVC.RemoveTargetF = func(_ context.Context, id influxdb.ID) error {
+						if id != 1 {
+							return errors.New("wrong id here")
+						}
+						return nil
+					}
+
+					svc := newTestService(WithBucketSVC(newBktSVC()), WithScraperTargetSVC(fakeScraperSVC))
+
+					orgID := influxdb.ID(9000)
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.Error(t, err)
+
+					assert.Equal(t, 1, fakeScraperSVC.RemoveTargetCalls.Count())
+				})
+			})
+		})
+
+		t.Run("checks", func(t *testing.T) {
+			t.Run("successfully creates", func(t *testing.T) {
+				testfileRunner(t, "testdata/check_threshold", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					fakeCheckSVC := mock.NewCheckService()
+					fakeCheckSVC.CreateCheckFn = func(_ context.Context, c influxdb.CheckCreate, userID influxdb.ID) error {
+						c.SetID(influxdb.ID(1))
+						return nil
+					}
+
+					svc := newTestService(WithCheckSVC(fakeCheckSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Checks, 1)
+					actual := sum.Checks[0]
+					assert.Equal(t, influxdb.ID(1), actual.Check.GetID())
+					assert.Equal(t, orgID, actual.Check.GetOrgID())
+					assert.Equal(t, "check_1", actual.Check.GetName())
+					assert.Equal(t, influxdb.Inactive, actual.Status)
+				})
+			})
+
+			t.Run("updates an existing check of the same name", func(t *testing.T) {
+				testfileRunner(t, "testdata/check_threshold", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					fakeCheckSVC := mock.NewCheckService()
+					fakeCheckSVC.FindChecksFn = func(_ context.Context, _ influxdb.CheckFilter, _ ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+						return []influxdb.Check{
+							&icheck.Threshold{Base: icheck.Base{ID: influxdb.ID(3), OrgID: orgID, Name: "check_1"}},
+						}, 1, nil
+					}
+					var updatedID influxdb.ID
+					fakeCheckSVC.UpdateCheckFn = func(_ context.Context, id influxdb.ID, c influxdb.CheckCreate) (influxdb.Check, error) {
+						updatedID = id
+						c.SetID(id)
+						return c.Check, nil
+					}
+					fakeCheckSVC.CreateCheckFn = func(context.Context, influxdb.CheckCreate, influxdb.ID) error {
+						return errors.New("should not be called")
+					}
+					fakeTaskSVC := &mock.TaskService{
+						FindTaskByIDFn: func(_ context.Context, id influxdb.ID) (*influxdb.Task, error) {
+							return &influxdb.Task{ID: id, Status: string(influxdb.Active)}, nil
+						},
+					}
+
+					svc := newTestService(WithCheckSVC(fakeCheckSVC), WithTaskSVC(fakeTaskSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					assert.Equal(t, influxdb.ID(3), updatedID)
+					require.Len(t, sum.Checks, 1)
+					assert.Equal(t, influxdb.ID(3), sum.Checks[0].Check.GetID())
+				})
+			})
+
+			t.Run("does not update an existing check without a task service to read its status", func(t *testing.T) {
+				testfileRunner(t, "testdata/check_threshold", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					fakeCheckSVC := mock.NewCheckService()
+					fakeCheckSVC.FindChecksFn = func(_ context.Context, _ influxdb.CheckFilter, _ ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+						return []influxdb.Check{
+							&icheck.Threshold{Base: icheck.Base{ID: influxdb.ID(3), OrgID: orgID, Name: "check_1"}},
+						}, 1, nil
+					}
+					fakeCheckSVC.UpdateCheckFn = func(context.Context, influxdb.ID, influxdb.CheckCreate) (influxdb.Check, error) {
+						return nil, errors.New("should not be called")
+					}
+
+					svc := newTestService(WithCheckSVC(fakeCheckSVC))
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.Error(t, err)
+				})
+			})
+
+			t.Run("rolls back all created checks on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/check_threshold", func(t *testing.T, pkg *Pkg) {
+					fakeCheckSVC := mock.NewCheckService()
+					fakeCheckSVC.CreateCheckFn = func(_ context.Context, c influxdb.CheckCreate, userID influxdb.ID) error {
+						c.SetID(influxdb.ID(1))
+						return nil
+					}
+					var deletedID influxdb.ID
+					fakeCheckSVC.DeleteCheckFn = func(_ context.Context, id influxdb.ID) error {
+						deletedID = id
+						return nil
+					}
+
+					fakeLabelSVC := mock.NewLabelService()
+					fakeLabelSVC.CreateLabelMappingFn = func(context.Context, *influxdb.LabelMapping) error {
+						return errors.New("limit hit")
+					}
+
+					svc := newTestService(WithCheckSVC(fakeCheckSVC), WithLabelSVC(fakeLabelSVC))
+
+					orgID := influxdb.ID(9000)
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.Error(t, err)
+
+					assert.Equal(t, influxdb.ID(1), deletedID)
+				})
+			})
+
+			t.Run("rolls back an updated check to its existing status", func(t *testing.T) {
+				ctx := context.Background()
+				kvSVC := newKVService(t)
+
+				const userID = influxdb.ID(1)
+
+				// the checks of the org are found through the members of the org.
+				org := &influxdb.Organization{Name: "org"}
+				require.NoError(t, kvSVC.CreateOrganization(ctx, org))
+				require.NoError(t, kvSVC.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+					ResourceType: influxdb.OrgsResourceType,
+					ResourceID:   org.ID,
+					UserID:       userID,
+					UserType:     influxdb.Owner,
+				}))
+
+				newSVC := func(labelSVC influxdb.LabelService) *Service {
+					return newTestService(
+						WithBucketSVC(kvSVC),
+						WithCheckSVC(kvSVC),
+						WithLabelSVC(labelSVC),
+						WithTaskSVC(kvSVC),
+					)
+				}
+
+				// the pkg creates the check inactive, it is activated after.
+				sum, err := newSVC(kvSVC).Apply(ctx, org.ID, userID, parsePkgFile(t, "testdata/check_threshold.yml"))
+				require.NoError(t, err)
+				require.Len(t, sum.Checks, 1)
+				existing, err := kvSVC.FindCheckByID(ctx, sum.Checks[0].Check.GetID())
+				require.NoError(t, err)
+
+				active := string(influxdb.Active)
+				_, err = kvSVC.UpdateTask(ctx, existing.GetTaskID(), influxdb.TaskUpdate{Status: &active})
+				require.NoError(t, err)
+
+				// the label mapping of the check is made again, failing the apply.
+				labels, err := kvSVC.FindResourceLabels(ctx, influxdb.LabelMappingFilter{
+					ResourceID:   existing.GetID(),
+					ResourceType: influxdb.ChecksResourceType,
+				})
+				require.NoError(t, err)
+				require.Len(t, labels, 1)
+				require.NoError(t, kvSVC.DeleteLabelMapping(ctx, &influxdb.LabelMapping{
+					LabelID:      labels[0].ID,
+					ResourceID:   existing.GetID(),
+					ResourceType: influxdb.ChecksResourceType,
+				}))
+
+				failingLabelSVC := &failingLabelMappingService{LabelService: kvSVC}
+				_, err = newSVC(failingLabelSVC).Apply(ctx, org.ID, userID, parsePkgFile(t, "testdata/check_threshold.yml"))
+				require.Error(t, err)
+				assert.NotContains(t, err.Error(), "unable to rollback check")
+
+				chk, err := kvSVC.FindCheckByID(ctx, existing.GetID())
+				require.NoError(t, err)
+				assert.Equal(t, existing.GetTaskID(), chk.GetTaskID())
+
+				task, err := kvSVC.FindTaskByID(ctx, existing.GetTaskID())
+				require.NoError(t, err)
+				assert.Equal(t, active, task.Status)
+			})
+
+			t.Run("leaves a check that matches its existing check unchanged", func(t *testing.T) {
+				ctx := context.Background()
+				kvSVC := newKVService(t)
+
+				const userID = influxdb.ID(1)
+
+				org := &influxdb.Organization{Name: "org"}
+				require.NoError(t, kvSVC.CreateOrganization(ctx, org))
+				require.NoError(t, kvSVC.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+					ResourceType: influxdb.OrgsResourceType,
+					ResourceID:   org.ID,
+					UserID:       userID,
+					UserType:     influxdb.Owner,
+				}))
+
+				svc := newTestService(
+					WithBucketSVC(kvSVC),
+					WithCheckSVC(kvSVC),
+					WithLabelSVC(kvSVC),
+					WithTaskSVC(kvSVC),
+				)
+
+				sum, err := svc.Apply(ctx, org.ID, userID, parsePkgFile(t, "testdata/check_threshold.yml"))
+				require.NoError(t, err)
+				require.Len(t, sum.Checks, 1)
+				existing, err := kvSVC.FindCheckByID(ctx, sum.Checks[0].Check.GetID())
+				require.NoError(t, err)
+
+				sum, err = svc.Apply(ctx, org.ID, userID, parsePkgFile(t, "testdata/check_threshold.yml"))
+				require.NoError(t, err)
+
+				assert.Contains(t, sum.Statuses, SummaryResourceStatus{
+					Kind:   KindCheck,
+					Name:   "check_1",
+					ID:     SafeID(existing.GetID()),
+					Status: ApplyStatusUnchanged,
+				})
+				chk, err := kvSVC.FindCheckByID(ctx, existing.GetID())
+				require.NoError(t, err)
+				assert.Equal(t, existing.GetCRUDLog(), chk.GetCRUDLog())
+			})
+
+			t.Run("updates a check created concurrently after the dry run", func(t *testing.T) {
+				testfileRunner(t, "testdata/check_threshold", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					var concurrentlyCreated influxdb.Check
+					fakeCheckSVC := mock.NewCheckService()
+					fakeCheckSVC.FindCheckFn = func(_ context.Context, f influxdb.CheckFilter) (influxdb.Check, error) {
+						if concurrentlyCreated == nil {
+							return nil, &influxdb.Error{Code: influxdb.ENotFound}
+						}
+						return concurrentlyCreated, nil
+					}
+					fakeCheckSVC.CreateCheckFn = func(_ context.Context, c influxdb.CheckCreate, _ influxdb.ID) error {
+						// simulates a concurrent apply creating the same check
+						// between the dry run and the create
+						concurrentlyCreated = &icheck.Threshold{Base: icheck.Base{ID: 3, OrgID: orgID, Name: c.GetName(), TaskID: 7}}
+						return &influxdb.Error{
+							Code: influxdb.EConflict,
+							Msg:  "check name is not unique",
+						}
+					}
+					var updatedID influxdb.ID
+					fakeCheckSVC.UpdateCheckFn = func(_ context.Context, id influxdb.ID, c influxdb.CheckCreate) (influxdb.Check, error) {
+						updatedID = id
+						return c.Check, nil
+					}
+					fakeTaskSVC := &mock.TaskService{
+						FindTaskByIDFn: func(_ context.Context, id influxdb.ID) (*influxdb.Task, error) {
+							return &influxdb.Task{ID: id, Status: string(influxdb.Active)}, nil
+						},
+					}
+
+					svc := newTestService(WithCheckSVC(fakeCheckSVC), WithTaskSVC(fakeTaskSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					assert.Equal(t, influxdb.ID(3), updatedID)
+					require.Len(t, sum.Checks, 1)
+					assert.Equal(t, influxdb.ID(3), sum.Checks[0].Check.GetID())
+					assert.Contains(t, sum.Statuses, SummaryResourceStatus{
+						Kind:   KindCheck,
+						Name:   "check_1",
+						ID:     3,
+						Status: ApplyStatusUpdated,
+					})
+				})
+			})
+		})
+
+		t.Run("notification rules", func(t *testing.T) {
+			t.Run("successfully creates with the endpoint of the pkg", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_rule", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					fakeEndpointSVC := mock.NewNotificationEndpointService()
+					fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, nr influxdb.NotificationEndpoint, userID influxdb.ID) error {
+						nr.SetID(influxdb.ID(1))
+						return nil
+					}
+					var created influxdb.NotificationRuleCreate
+					fakeRuleStore := mock.NewNotificationRuleStore()
+					fakeRuleStore.CreateNotificationRuleF = func(ctx context.Context, nr influxdb.NotificationRuleCreate, userID influxdb.ID) error {
+						nr.SetID(influxdb.ID(2))
+						created = nr
+						return nil
+					}
+
+					svc := newTestService(
+						WithNoticationEndpointSVC(fakeEndpointSVC),
+						WithNotificationRuleSVC(fakeRuleStore),
+					)
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					slackRule, ok := created.NotificationRule.(*rule.Slack)
+					require.True(t, ok)
+					assert.Equal(t, influxdb.ID(1), slackRule.GetEndpointID())
+					assert.Equal(t, "#alerts", slackRule.Channel)
+					assert.Equal(t, influxdb.Inactive, created.Status)
+
+					require.Len(t, sum.NotificationRules, 1)
+					actual := sum.NotificationRules[0]
+					assert.Equal(t, SafeID(2), actual.ID)
+					assert.Equal(t, SafeID(1), actual.EndpointID)
+					assert.Equal(t, "rule_1", actual.Name)
+				})
+			})
+
+			t.Run("rolls back all created rules on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_rule", func(t *testing.T, pkg *Pkg) {
+					fakeRuleStore := mock.NewNotificationRuleStore()
+					fakeRuleStore.CreateNotificationRuleF = func(ctx context.Context, nr influxdb.NotificationRuleCreate, userID influxdb.ID) error {
+						nr.SetID(influxdb.ID(2))
+						return nil
+					}
+					var deletedID influxdb.ID
+					fakeRuleStore.DeleteNotificationRuleF = func(ctx context.Context, id influxdb.ID) error {
+						deletedID = id
+						return nil
+					}
+
+					fakeLabelSVC := mock.NewLabelService()
+					fakeLabelSVC.CreateLabelMappingFn = func(context.Context, *influxdb.LabelMapping) error {
+						return errors.New("limit hit")
+					}
+
+					svc := newTestService(
+						WithLabelSVC(fakeLabelSVC),
+						WithNotificationRuleSVC(fakeRuleStore),
+					)
+
+					orgID := influxdb.ID(9000)
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.Error(t, err)
+
+					assert.Equal(t, influxdb.ID(2), deletedID)
+				})
+			})
+
+			t.Run("rolls back an updated rule to its existing status", func(t *testing.T) {
+				ctx := context.Background()
+				kvSVC := newKVService(t)
+
+				org := &influxdb.Organization{Name: "org"}
+				require.NoError(t, kvSVC.CreateOrganization(ctx, org))
+
+				newSVC := func(labelSVC influxdb.LabelService) *Service {
+					return newTestService(
+						WithLabelSVC(labelSVC),
+						WithNoticationEndpointSVC(kvSVC),
+						WithNotificationRuleSVC(kvSVC),
+						WithTaskSVC(kvSVC),
+					)
+				}
+				newPkg := func(t *testing.T) *Pkg {
+					b, err := ioutil.ReadFile("testdata/notification_rule.yml")
+					require.NoError(t, err)
+					pkgStr := strings.Replace(string(b), "status: inactive", "status: active", 1)
+					pkg, err := Parse(EncodingYAML, FromString(pkgStr))
+					require.NoError(t, err)
+					return pkg
+				}
+
+				const userID = influxdb.ID(1)
+
+				// the pkg creates the rule active, it is deactivated after.
+				_, err := newSVC(kvSVC).Apply(ctx, org.ID, userID, newPkg(t))
+				require.NoError(t, err)
+				rules, _, err := kvSVC.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{OrgID: &org.ID})
+				require.NoError(t, err)
+				require.Len(t, rules, 1)
+				existing := rules[0]
+
+				inactive := string(influxdb.Inactive)
+				_, err = kvSVC.UpdateTask(ctx, existing.GetTaskID(), influxdb.TaskUpdate{Status: &inactive})
+				require.NoError(t, err)
+
+				// the label mapping of the rule is made again, failing the apply.
+				labels, err := kvSVC.FindResourceLabels(ctx, influxdb.LabelMappingFilter{
+					ResourceID:   existing.GetID(),
+					ResourceType: influxdb.NotificationRuleResourceType,
+				})
+				require.NoError(t, err)
+				require.Len(t, labels, 1)
+				require.NoError(t, kvSVC.DeleteLabelMapping(ctx, &influxdb.LabelMapping{
+					LabelID:      labels[0].ID,
+					ResourceID:   existing.GetID(),
+					ResourceType: influxdb.NotificationRuleResourceType,
+				}))
+
+				failingLabelSVC := &failingLabelMappingService{LabelService: kvSVC}
+				_, err = newSVC(failingLabelSVC).Apply(ctx, org.ID, userID, newPkg(t))
+				require.Error(t, err)
+				assert.NotContains(t, err.Error(), "unable to rollback notification rule")
+
+				task, err := kvSVC.FindTaskByID(ctx, existing.GetTaskID())
+				require.NoError(t, err)
+				assert.Equal(t, inactive, task.Status)
+			})
+		})
+
+		t.Run("tasks", func(t *testing.T) {
+			t.Run("successfuly creates", func(t *testing.T) {
+				testfileRunner(t, "testdata/task.yml", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					var (
+						mu      sync.Mutex
+						creates = make(map[string]influxdb.TaskCreate)
+					)
+					fakeTaskSVC := &mock.TaskService{
+						CreateTaskFn: func(_ context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+							mu.Lock()
+							defer mu.Unlock()
+							creates[tc.Description] = tc
+							return &influxdb.Task{ID: influxdb.ID(len(creates))}, nil
+						},
+					}
+
+					svc := newTestService(WithTaskSVC(fakeTaskSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 3, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Tasks, 2)
+					assert.NotZero(t, sum.Tasks[0].ID)
+					assert.NotZero(t, sum.Tasks[1].ID)
+
+					require.Len(t, creates, 2)
+					query := "from(bucket: \"rucket\")\n  |> range(start: -1h)\n  |> to(bucket: \"rucket_agg\")"
+					assert.Equal(t, influxdb.TaskCreate{
+						Flux:           "option task = {name: \"task_0\", cron: \"15 * * * *\"}\n\n" + query,
+						Status:         influxdb.TaskStatusInactive,
+						OrganizationID: orgID,
+						OwnerID:        3,
+					}, creates[""])
+					assert.Equal(t, influxdb.TaskCreate{
+						Flux:           "option task = {name: \"task_1\", every: 1h, offset: 5m}\n\n" + query,
+						Description:    "desc_1",
+						Status:         influxdb.TaskStatusActive,
+						OrganizationID: orgID,
+						OwnerID:        3,
+					}, creates["desc_1"])
+				})
+			})
+
+			t.Run("rolls back all created tasks on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/task.yml", func(t *testing.T, pkg *Pkg) {
+					var (
+						calls   int32
+						deleted []influxdb.ID
+					)
+					fakeTaskSVC := &mock.TaskService{
+						CreateTaskFn: func(_ context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+							if atomic.AddInt32(&calls, 1) == 2 {
+								return nil, errors.New("limit hit")
+							}
+							return &influxdb.Task{ID: 1}, nil
+						},
+						DeleteTaskFn: func(_ context.Context, id influxdb.ID) error {
+							deleted = append(deleted, id)
+							return nil
+						},
+					}
+
+					svc := newTestService(WithTaskSVC(fakeTaskSVC))
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+
+					assert.Equal(t, []influxdb.ID{1}, deleted)
+				})
+			})
+		})
+
+		t.Run("telegrafs", func(t *testing.T) {
+			t.Run("successfuly creates", func(t *testing.T) {
+				testfileRunner(t, "testdata/telegraf.yml", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					fakeTeleSVC := mock.NewTelegrafConfigStore()
+					fakeTeleSVC.CreateTelegrafConfigF = func(_ context.Context, tc *influxdb.TelegrafConfig, userID influxdb.ID) error {
+						tc.ID = 1
+						return nil
+					}
+
+					svc := newTestService(WithTelegrafSVC(fakeTeleSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.TelegrafConfigs, 1)
+					assert.Equal(t, "first_tele_config", sum.TelegrafConfigs[0].TelegrafConfig.Name)
+					assert.Equal(t, "desc", sum.TelegrafConfigs[0].TelegrafConfig.Description)
+				})
+			})
+
+			t.Run("rolls back all created telegrafs on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/telegraf.yml", func(t *testing.T, pkg *Pkg) {
+					fakeTeleSVC := mock.NewTelegrafConfigStore()
+					fakeTeleSVC.CreateTelegrafConfigF = func(_ context.Context, tc *influxdb.TelegrafConfig, userID influxdb.ID) error {
+						if fakeTeleSVC.CreateTelegrafConfigCalls.Count() == 1 {
+							return errors.New("limit hit")
+						}
+						tc.ID = influxdb.ID(1)
+						return nil
+					}
+					fakeTeleSVC.DeleteTelegrafConfigF = func(_ context.Context, id influxdb.ID) error {
+						if id != 1 {
+							return errors.New("wrong id here")
+						}
+						return nil
+					}
+
+					pkg.mTelegrafs = append(pkg.mTelegrafs, pkg.mTelegrafs[0])
+
+					svc := newTestService(WithTelegrafSVC(fakeTeleSVC))
+
+					orgID := influxdb.ID(9000)
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.Error(t, err)
+
+					assert.Equal(t, 1, fakeTeleSVC.DeleteTelegrafConfigCalls.Count())
+				})
+			})
+		})
+
+		t.Run("variables", func(t *testing.T) {
+			t.Run("successfully creates pkg of variables", func(t *testing.T) {
+				testfileRunner(t, "testdata/variables.yml", func(t *testing.T, pkg *Pkg) {
+					fakeVarSVC := mock.NewVariableService()
+					fakeVarSVC.CreateVariableF = func(_ context.Context, v *influxdb.Variable) error {
+						id, err := strconv.Atoi(v.Name[len(v.Name)-1:])
+						if err != nil {
+							return err
+						}
+						v.ID = influxdb.ID(id)
+						return nil
+					}
+
+					svc := newTestService(WithVariableSVC(fakeVarSVC))
+
+					orgID := influxdb.ID(9000)
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Variables, 4)
+					expected := sum.Variables[0]
+					assert.Equal(t, SafeID(3), expected.ID)
+					assert.Equal(t, SafeID(orgID), expected.OrgID)
+					assert.Equal(t, "var_const_3", expected.Name)
+					assert.Equal(t, "var_const_3 desc", expected.Description)
+					require.NotNil(t, expected.Arguments)
+					assert.Equal(t, influxdb.VariableConstantValues{"first val"}, expected.Arguments.Values)
+
+					for _, actual := range sum.Variables {
+						assert.Contains(t, []SafeID{1, 2, 3, 4}, actual.ID)
+					}
+				})
+			})
+
+			t.Run("rolls back all created variables on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/variables.yml", func(t *testing.T, pkg *Pkg) {
+					fakeVarSVC := mock.NewVariableService()
+					fakeVarSVC.CreateVariableF = func(_ context.Context, l *influxdb.Variable) error {
+						// 4th variable will return the error here, and 3 before should be rolled back
+						if fakeVarSVC.CreateVariableCalls.Count() == 2 {
+							return errors.New("blowed up ")
+						}
+						return nil
+					}
+
+					svc := newTestService(WithVariableSVC(fakeVarSVC))
+
+					orgID := influxdb.ID(9000)
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.Error(t, err)
+
+					assert.GreaterOrEqual(t, fakeVarSVC.DeleteVariableCalls.Count(), 1)
+				})
+			})
+
+			t.Run("will not apply variable if no changes to be applied", func(t *testing.T) {
+				testfileRunner(t, "testdata/variables.yml", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					pkg.verify(ApplyOpt{})
+					pkgLabel := pkg.mVariables["var_const_3"]
+					pkgLabel.existing = &influxdb.Variable{
+						// makes all pkg changes same as they are on the existing
+						ID:             influxdb.ID(1),
+						OrganizationID: orgID,
+						Name:           pkgLabel.Name(),
+						Arguments: &influxdb.VariableArguments{
+							Type:   "constant",
+							Values: influxdb.VariableConstantValues{"first val"},
+						},
+					}
+
+					fakeVarSVC := mock.NewVariableService()
+					fakeVarSVC.CreateVariableF = func(_ context.Context, l *influxdb.Variable) error {
+						if l.Name == "var_const" {
+							return errors.New("shouldn't get here")
+						}
+						return nil
+					}
+					fakeVarSVC.UpdateVariableF = func(_ context.Context, id influxdb.ID, v *influxdb.VariableUpdate) (*influxdb.Variable, error) {
+						if id > influxdb.ID(1) {
+							return nil, errors.New("this id should not be updated")
+						}
+						return &influxdb.Variable{ID: id}, nil
+					}
+
+					svc := newTestService(WithVariableSVC(fakeVarSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Variables, 4)
+					expected := sum.Variables[0]
+					assert.Equal(t, SafeID(1), expected.ID)
+					assert.Equal(t, "var_const_3", expected.Name)
+
+					assert.Equal(t, 3, fakeVarSVC.CreateVariableCalls.Count()) // only called for last 3 labels
+				})
+			})
+
+			t.Run("will not apply constant variable when only the order of its values differs", func(t *testing.T) {
+				testfileRunner(t, "testdata/variables.yml", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					pkg.verify(ApplyOpt{})
+					pkgVar := pkg.mVariables["var_const_3"]
+					pkgVar.ConstValues = []string{"second val", "first val"}
+					pkgVar.existing = &influxdb.Variable{
+						ID:             influxdb.ID(1),
+						OrganizationID: orgID,
+						Name:           pkgVar.Name(),
+						Description:    pkgVar.Description,
+						Arguments: &influxdb.VariableArguments{
+							Type:   "constant",
+							Values: influxdb.VariableConstantValues{"first val", "second val"},
+						},
+					}
+
+					fakeVarSVC := mock.NewVariableService()
+					fakeVarSVC.UpdateVariableF = func(_ context.Context, id influxdb.ID, v *influxdb.VariableUpdate) (*influxdb.Variable, error) {
+						return nil, errors.New("shouldn't get here")
+					}
+
+					svc := newTestService(WithVariableSVC(fakeVarSVC))
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					assert.Zero(t, fakeVarSVC.UpdateVariableCalls.Count())
+				})
+			})
+		})
+
+		t.Run("secrets", func(t *testing.T) {
+			t.Run("creates missing secrets provided", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_endpoint_secrets.yml", func(t *testing.T, pkg *Pkg) {
+					fakeSecretSVC := mock.NewSecretService()
+					fakeSecretSVC.GetSecretKeysFn = func(ctx context.Context, orgID influxdb.ID) ([]string, error) {
+						return []string{"rando-1"}, nil
+					}
+					var patchedSecrets map[string]string
+					fakeSecretSVC.PatchSecretsFn = func(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+						patchedSecrets = m
+						return nil
+					}
+
+					fakeEndpointSVC := mock.NewNotificationEndpointService()
+					fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, nr influxdb.NotificationEndpoint, userID influxdb.ID) error {
+						nr.SetID(influxdb.ID(1))
+						return nil
+					}
+
+					svc := newTestService(WithSecretSVC(fakeSecretSVC), WithNoticationEndpointSVC(fakeEndpointSVC))
+
+					secrets := map[string]string{
+						"rando-1":     "existing",
+						"routing-key": "threeve",
+					}
+					sum, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg, ApplyWithSecrets(secrets))
+					require.NoError(t, err)
+
+					require.Len(t, sum.NotificationEndpoints, 1)
+					assert.Equal(t, map[string]string{"routing-key": "threeve"}, patchedSecrets)
+				})
+			})
+		})
+
+		t.Run("emits spans for each resource group and create call", func(t *testing.T) {
+			oldTracer := opentracing.GlobalTracer()
+			defer opentracing.SetGlobalTracer(oldTracer)
+			tracer := mocktracer.New()
+			opentracing.SetGlobalTracer(tracer)
+
+			testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+				tracer.Reset()
+
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+					b.ID = influxdb.ID(b.RetentionPeriod)
+					return nil
+				}
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+					return nil, &influxdb.Error{Code: influxdb.ENotFound}
+				}
+
+				svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+				_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+				require.NoError(t, err)
+
+				spans := make(map[string]*mocktracer.MockSpan)
+				for _, span := range tracer.FinishedSpans() {
+					spans[span.OperationName] = span
+				}
+
+				applySpan, ok := spans["pkger.(*Service).Apply"]
+				require.True(t, ok, "missing Apply span")
+				_, ok = spans["pkger.(*Service).dryRunBuckets"]
+				require.True(t, ok, "missing dry run span")
+
+				group, ok := spans["apply bucket"]
+				require.True(t, ok, "missing resource group span")
+				assert.Equal(t, applySpan.SpanContext.SpanID, group.ParentID)
+				assert.Equal(t, "bucket", group.Tags()["resource"])
+
+				create, ok := spans["create bucket"]
+				require.True(t, ok, "missing create span")
+				assert.Equal(t, group.SpanContext.SpanID, create.ParentID)
+				assert.Equal(t, "bucket", create.Tags()["resource"])
+				assert.Equal(t, "rucket_11", create.Tags()["name"])
+			})
+		})
+
+		t.Run("renames resources by display name", func(t *testing.T) {
+			newSVC := func(existing map[string]*influxdb.Bucket) (*Service, *mock.BucketService, *mock.LabelService, *mock.VariableService) {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					if b, ok := existing[name]; ok {
+						return b, nil
+					}
+					return nil, &influxdb.Error{Code: influxdb.ENotFound}
+				}
+				fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+					return &influxdb.Bucket{ID: id, Name: *upd.Name}, nil
+				}
+
+				fakeLabelSVC := mock.NewLabelService()
+				fakeLabelSVC.FindLabelsFn = func(_ context.Context, filter influxdb.LabelFilter) ([]*influxdb.Label, error) {
+					if filter.Name != "label_1" {
+						return nil, nil
+					}
+					return []*influxdb.Label{{ID: 2, Name: "label_1"}}, nil
+				}
+				fakeLabelSVC.UpdateLabelFn = func(_ context.Context, id influxdb.ID, upd influxdb.LabelUpdate) (*influxdb.Label, error) {
+					return &influxdb.Label{ID: id, Name: upd.Name}, nil
+				}
+				fakeLabelSVC.CreateLabelMappingFn = func(_ context.Context, mapping *influxdb.LabelMapping) error {
+					return nil
+				}
+
+				fakeVarSVC := mock.NewVariableService()
+				fakeVarSVC.FindVariablesF = func(_ context.Context, filter influxdb.VariableFilter, opts ...influxdb.FindOptions) ([]*influxdb.Variable, error) {
+					return []*influxdb.Variable{{ID: 4, Name: "var_1"}}, nil
+				}
+				fakeVarSVC.UpdateVariableF = func(_ context.Context, id influxdb.ID, upd *influxdb.VariableUpdate) (*influxdb.Variable, error) {
+					return &influxdb.Variable{ID: id, Name: upd.Name}, nil
+				}
+
+				svc := newTestService(WithBucketSVC(fakeBktSVC), WithLabelSVC(fakeLabelSVC), WithVariableSVC(fakeVarSVC))
+				return svc, fakeBktSVC, fakeLabelSVC, fakeVarSVC
+			}
+
+			t.Run("updates the existing resources with the display name", func(t *testing.T) {
+				testfileRunner(t, "testdata/rename.yml", func(t *testing.T, pkg *Pkg) {
+					svc, fakeBktSVC, fakeLabelSVC, fakeVarSVC := newSVC(map[string]*influxdb.Bucket{
+						"telegraf": {ID: 3, Name: "telegraf", RetentionPeriod: time.Hour},
+					})
+
+					var (
+						bktNames   []string
+						labelNames []string
+						varNames   []string
+					)
+					fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+						bktNames = append(bktNames, *upd.Name)
+						return &influxdb.Bucket{ID: id, Name: *upd.Name}, nil
+					}
+					fakeLabelSVC.UpdateLabelFn = func(_ context.Context, id influxdb.ID, upd influxdb.LabelUpdate) (*influxdb.Label, error) {
+						labelNames = append(labelNames, upd.Name)
+						return &influxdb.Label{ID: id, Name: upd.Name}, nil
+					}
+					fakeVarSVC.UpdateVariableF = func(_ context.Context, id influxdb.ID, upd *influxdb.VariableUpdate) (*influxdb.Variable, error) {
+						varNames = append(varNames, upd.Name)
+						return &influxdb.Variable{ID: id, Name: upd.Name}, nil
+					}
+
+					sum, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+
+					assert.Equal(t, []string{"metrics"}, bktNames)
+					assert.Equal(t, []string{"label_renamed"}, labelNames)
+					assert.Equal(t, []string{"var_renamed"}, varNames)
+					assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+
+					require.Len(t, sum.Buckets, 1)
+					assert.Equal(t, SafeID(3), sum.Buckets[0].ID)
+					assert.Equal(t, "metrics", sum.Buckets[0].Name)
+					require.Len(t, sum.Labels, 1)
+					assert.Equal(t, "label_renamed", sum.Labels[0].Name)
+					require.Len(t, sum.Variables, 1)
+					assert.Equal(t, "var_renamed", sum.Variables[0].Name)
+				})
+			})
+
+			t.Run("finds resources renamed by a previous apply", func(t *testing.T) {
+				testfileRunner(t, "testdata/rename.yml", func(t *testing.T, pkg *Pkg) {
+					svc, fakeBktSVC, _, _ := newSVC(map[string]*influxdb.Bucket{
+						"metrics": {ID: 3, Name: "metrics", RetentionPeriod: time.Hour},
+					})
+
+					_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, diff.Buckets, 1)
+					assert.Equal(t, SafeID(3), diff.Buckets[0].ID)
+					assert.False(t, diff.Buckets[0].hasConflict())
+
+					_, err = svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+
+					assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+					assert.Zero(t, fakeBktSVC.UpdateBucketCalls.Count())
+				})
+			})
+
+			t.Run("rollback restores the old names", func(t *testing.T) {
+				testfileRunner(t, "testdata/rename.yml", func(t *testing.T, pkg *Pkg) {
+					svc, fakeBktSVC, fakeLabelSVC, _ := newSVC(map[string]*influxdb.Bucket{
+						"telegraf": {ID: 3, Name: "telegraf", RetentionPeriod: time.Hour},
+					})
+
+					var bktNames []string
+					fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+						bktNames = append(bktNames, *upd.Name)
+						return &influxdb.Bucket{ID: id, Name: *upd.Name}, nil
+					}
+					fakeLabelSVC.CreateLabelMappingFn = func(_ context.Context, mapping *influxdb.LabelMapping) error {
+						return errors.New("failed to map")
+					}
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+
+					assert.Equal(t, []string{"metrics", "telegraf"}, bktNames)
+				})
+			})
+		})
+
+		t.Run("usage reporting", func(t *testing.T) {
+			newBktSVC := func(createErr error) *mock.BucketService {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+					b.ID = influxdb.ID(b.RetentionPeriod)
+					return createErr
+				}
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+					return nil, &influxdb.Error{Code: influxdb.ENotFound}
+				}
+				return fakeBktSVC
+			}
+
+			t.Run("reports an anonymous event when enabled", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					reporter := new(fakeUsageReporter)
+					svc := newTestService(WithBucketSVC(newBktSVC(nil)), WithUsageReporter(reporter))
 
-						orgID := influxdb.ID(9000)
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
 
-						_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-						require.Error(t, err)
+					require.Len(t, reporter.events, 1)
+					event := reporter.events[0]
+					assert.Len(t, event.PkgHash, 64)
+					assert.NotContains(t, event.PkgHash, pkg.Metadata.Name)
+					assert.Equal(t, 1, event.Resources[KindBucket])
+					assert.Zero(t, event.Resources[KindLabel])
+				})
+			})
 
-						assert.GreaterOrEqual(t, fakeLabelSVC.DeleteLabelMappingCalls.Count(), numExpected)
-					})
+			t.Run("counts the resources of every kind", func(t *testing.T) {
+				event := newApplyEvent(Metadata{Name: "pkg_name"}, Summary{
+					Buckets:           []SummaryBucket{{}},
+					Checks:            []SummaryCheck{{}, {}},
+					NotificationRules: []SummaryNotificationRule{{}},
+					TelegrafConfigs:   []SummaryTelegraf{{}},
 				})
-			}
 
-			t.Run("maps buckets with labels", func(t *testing.T) {
-				testLabelMappingFn(
-					t,
-					"testdata/bucket_associates_label.yml",
-					4,
-					func() []ServiceSetterFn {
-						fakeBktSVC := mock.NewBucketService()
-						fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
-							b.ID = influxdb.ID(rand.Int())
-							return nil
-						}
-						fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
-							// forces the bucket to be created a new
-							return nil, errors.New("an error")
-						}
-						return []ServiceSetterFn{WithBucketSVC(fakeBktSVC)}
-					},
-				)
+				assert.Equal(t, 1, event.Resources[KindBucket])
+				assert.Equal(t, 2, event.Resources[KindCheck])
+				assert.Equal(t, 1, event.Resources[KindNotificationRule])
+				assert.Equal(t, 1, event.Resources[KindTelegraf])
+				assert.Zero(t, event.Resources[KindDashboard])
 			})
 
-			t.Run("maps dashboards with labels", func(t *testing.T) {
-				testLabelMappingFn(
-					t,
-					"testdata/dashboard_associates_label.yml",
-					1,
-					func() []ServiceSetterFn {
-						fakeDashSVC := mock.NewDashboardService()
-						fakeDashSVC.CreateDashboardF = func(_ context.Context, d *influxdb.Dashboard) error {
-							d.ID = influxdb.ID(rand.Int())
-							return nil
-						}
-						return []ServiceSetterFn{WithDashboardSVC(fakeDashSVC)}
-					},
-				)
+			t.Run("hashes the pkg name and version", func(t *testing.T) {
+				hash := pkgHash(Metadata{Name: "pkg_name", Version: "1"})
+				assert.Equal(t, hash, pkgHash(Metadata{Name: "pkg_name", Version: "1", Description: "other"}))
+				assert.NotEqual(t, hash, pkgHash(Metadata{Name: "pkg_name", Version: "2"}))
+				assert.NotEqual(t, hash, pkgHash(Metadata{Name: "pkg_name1", Version: ""}))
 			})
 
-			t.Run("maps telegrafs with labels", func(t *testing.T) {
-				testLabelMappingFn(
-					t,
-					"testdata/telegraf.yml",
-					1,
-					func() []ServiceSetterFn {
-						fakeTeleSVC := mock.NewTelegrafConfigStore()
-						fakeTeleSVC.CreateTelegrafConfigF = func(_ context.Context, cfg *influxdb.TelegrafConfig, _ influxdb.ID) error {
-							cfg.ID = influxdb.ID(rand.Int())
-							return nil
-						}
-						return []ServiceSetterFn{WithTelegrafSVC(fakeTeleSVC)}
-					},
-				)
-			})
+			t.Run("does not report a failed apply", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					reporter := new(fakeUsageReporter)
+					svc := newTestService(WithBucketSVC(newBktSVC(errors.New("failed to create"))), WithUsageReporter(reporter))
 
-			t.Run("maps variables with labels", func(t *testing.T) {
-				testLabelMappingFn(
-					t,
-					"testdata/variable_associates_label.yml",
-					1,
-					func() []ServiceSetterFn {
-						fakeVarSVC := mock.NewVariableService()
-						fakeVarSVC.CreateVariableF = func(_ context.Context, v *influxdb.Variable) error {
-							v.ID = influxdb.ID(rand.Int())
-							return nil
-						}
-						return []ServiceSetterFn{WithVariableSVC(fakeVarSVC)}
-					},
-				)
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+
+					assert.Empty(t, reporter.events)
+				})
 			})
 
+			t.Run("applies without reporting when disabled", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					svc := newTestService(WithBucketSVC(newBktSVC(nil)))
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+				})
+			})
 		})
 
-		t.Run("notification endpoints", func(t *testing.T) {
-			t.Run("successfully creates pkg of endpoints", func(t *testing.T) {
-				testfileRunner(t, "testdata/notification_endpoint.yml", func(t *testing.T, pkg *Pkg) {
-					fakeEndpointSVC := mock.NewNotificationEndpointService()
-					fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, nr influxdb.NotificationEndpoint, userID influxdb.ID) error {
-						nr.SetID(influxdb.ID(fakeEndpointSVC.CreateNotificationEndpointCalls.Count() + 1))
-						return nil
-					}
+		t.Run("re-runs the dry run when verification is stale", func(t *testing.T) {
+			newSVC := func() (*Service, *int) {
+				var dryRuns int
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+					dryRuns++
+					return nil, errors.New("not found")
+				}
+				fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+					b.ID = influxdb.ID(1)
+					return nil
+				}
 
-					svc := newTestService(WithNoticationEndpointSVC(fakeEndpointSVC))
+				fakeSecretSVC := mock.NewSecretService()
+				fakeSecretSVC.GetSecretKeysFn = func(ctx context.Context, orgID influxdb.ID) ([]string, error) {
+					return []string{"rando-1"}, nil
+				}
+				fakeSecretSVC.PatchSecretsFn = func(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+					return nil
+				}
+
+				return newTestService(WithBucketSVC(fakeBktSVC), WithSecretSVC(fakeSecretSVC)), &dryRuns
+			}
 
+			t.Run("when verified with the same options", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					svc, dryRuns := newSVC()
 					orgID := influxdb.ID(9000)
 
-					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					_, _, err := svc.DryRun(context.TODO(), orgID, 0, pkg)
 					require.NoError(t, err)
+					require.Equal(t, 1, *dryRuns)
 
-					require.Len(t, sum.NotificationEndpoints, 5)
-
-					containsWithID := func(t *testing.T, name string) {
-						for _, actualNotification := range sum.NotificationEndpoints {
-							actual := actualNotification.NotificationEndpoint
-							if actual.GetID() == 0 {
-								assert.NotZero(t, actual.GetID())
-							}
-							if actual.GetName() == name {
-								return
-							}
-						}
-						assert.Fail(t, "did not find notification by name: "+name)
-					}
-
-					expectedNames := []string{
-						"http_basic_auth_notification_endpoint",
-						"http_bearer_auth_notification_endpoint",
-						"http_none_auth_notification_endpoint",
-						"pager_duty_notification_endpoint",
-						"slack_notification_endpoint",
-					}
-					for _, expectedName := range expectedNames {
-						containsWithID(t, expectedName)
-					}
+					_, err = svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+					assert.Equal(t, 1, *dryRuns)
 				})
 			})
 
-			t.Run("rolls back all created notifications on an error", func(t *testing.T) {
-				testfileRunner(t, "testdata/notification_endpoint.yml", func(t *testing.T, pkg *Pkg) {
-					fakeEndpointSVC := mock.NewNotificationEndpointService()
-					fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, nr influxdb.NotificationEndpoint, userID influxdb.ID) error {
-						nr.SetID(influxdb.ID(fakeEndpointSVC.CreateNotificationEndpointCalls.Count() + 1))
-						if fakeEndpointSVC.CreateNotificationEndpointCalls.Count() == 5 {
-							return errors.New("hit that kill count")
-						}
-						return nil
-					}
+			t.Run("when applied with different options", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					svc, dryRuns := newSVC()
+					orgID := influxdb.ID(9000)
 
-					// create some dupes
-					for name, endpoint := range pkg.mNotificationEndpoints {
-						pkg.mNotificationEndpoints["copy"+name] = endpoint
-					}
+					_, _, err := svc.DryRun(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+					require.Equal(t, 1, *dryRuns)
 
-					svc := newTestService(WithNoticationEndpointSVC(fakeEndpointSVC))
+					_, err = svc.Apply(context.TODO(), orgID, 0, pkg, ApplyWithSecrets(map[string]string{"k": "v"}))
+					require.NoError(t, err)
+					assert.Equal(t, 2, *dryRuns)
+				})
+			})
 
+			t.Run("when pkg is modified after verification", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					svc, dryRuns := newSVC()
 					orgID := influxdb.ID(9000)
 
-					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.Error(t, err)
+					_, _, err := svc.DryRun(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+					require.Equal(t, 1, *dryRuns)
 
-					assert.GreaterOrEqual(t, fakeEndpointSVC.DeleteNotificationEndpointCalls.Count(), 5)
+					pkg.Metadata.Description = "new description"
+
+					_, err = svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+					assert.Equal(t, 2, *dryRuns)
 				})
 			})
 		})
 
-		t.Run("telegrafs", func(t *testing.T) {
-			t.Run("successfuly creates", func(t *testing.T) {
-				testfileRunner(t, "testdata/telegraf.yml", func(t *testing.T, pkg *Pkg) {
-					orgID := influxdb.ID(9000)
+		t.Run("concurrent dry runs and applies of a pkg", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+					return nil, errors.New("not found")
+				}
+				fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+					b.ID = influxdb.ID(rand.Int())
+					return nil
+				}
+				fakeLabelSVC := mock.NewLabelService()
+				fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+					l.ID = influxdb.ID(rand.Int())
+					return nil
+				}
+				fakeLabelSVC.CreateLabelMappingFn = func(_ context.Context, mapping *influxdb.LabelMapping) error {
+					if mapping.ResourceID == 0 || mapping.LabelID == 0 {
+						return errors.New("mapping is missing an ID")
+					}
+					return nil
+				}
 
-					fakeTeleSVC := mock.NewTelegrafConfigStore()
-					fakeTeleSVC.CreateTelegrafConfigF = func(_ context.Context, tc *influxdb.TelegrafConfig, userID influxdb.ID) error {
-						tc.ID = 1
-						return nil
+				svc := newTestService(WithBucketSVC(fakeBktSVC), WithLabelSVC(fakeLabelSVC))
+				orgID := influxdb.ID(9000)
+
+				const n = 10
+				errs := make(chan error, 2*n)
+				var wg sync.WaitGroup
+				for i := 0; i < n; i++ {
+					wg.Add(2)
+					go func() {
+						defer wg.Done()
+						_, _, err := svc.DryRun(context.TODO(), orgID, 0, pkg)
+						errs <- err
+					}()
+					go func() {
+						defer wg.Done()
+						sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+						if err == nil && len(sum.Buckets) != 3 {
+							err = fmt.Errorf("exp 3 buckets applied, got %d", len(sum.Buckets))
+						}
+						errs <- err
+					}()
+				}
+				wg.Wait()
+				close(errs)
+
+				for err := range errs {
+					assert.NoError(t, err)
+				}
+				assert.Equal(t, 3*n, fakeBktSVC.CreateBucketCalls.Count())
+			})
+		})
+	})
+
+	t.Run("ApplyToOrgs", func(t *testing.T) {
+		t.Run("one org failing does not affect the others", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+				const failingOrgID = influxdb.ID(3)
+
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+					return nil, errors.New("not found")
+				}
+				fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+					if b.OrgID == failingOrgID {
+						return errors.New("failed to create bucket")
 					}
+					b.ID = influxdb.ID(rand.Int())
+					return nil
+				}
 
-					svc := newTestService(WithTelegrafSVC(fakeTeleSVC))
+				opLog := &fakeOrgOpLog{entries: make(map[influxdb.ID][]string)}
 
-					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.NoError(t, err)
+				svc := newTestService(
+					WithBucketSVC(fakeBktSVC),
+					WithOrganizationOperationLog(opLog),
+				)
+				svc.applyOrgsLimit = 2
+				reg := prom.NewRegistry(zap.NewNop())
+				reg.MustRegister(svc.PrometheusCollectors()...)
 
-					require.Len(t, sum.TelegrafConfigs, 1)
-					assert.Equal(t, "first_tele_config", sum.TelegrafConfigs[0].TelegrafConfig.Name)
-					assert.Equal(t, "desc", sum.TelegrafConfigs[0].TelegrafConfig.Description)
-				})
-			})
+				orgIDs := []influxdb.ID{1, 2, failingOrgID, 4}
+				results, err := svc.ApplyToOrgs(context.TODO(), 0, pkg, orgIDs)
+				require.NoError(t, err)
+				require.Len(t, results, len(orgIDs))
+
+				mfs := promtest.MustGather(t, reg)
+				succeeded := promtest.MustFindMetric(t, mfs, "pkger_org_apply_total", map[string]string{"result": "success"})
+				assert.Equal(t, float64(len(orgIDs)-1), succeeded.GetCounter().GetValue())
+				failed := promtest.MustFindMetric(t, mfs, "pkger_org_apply_total", map[string]string{"result": "failure"})
+				assert.Equal(t, float64(1), failed.GetCounter().GetValue())
+
+				for _, orgID := range orgIDs {
+					expected := "Pkg Applied: " + pkg.Metadata.Name
+					if orgID == failingOrgID {
+						expected = "Pkg Apply Failed: " + pkg.Metadata.Name
+					}
+					assert.Equal(t, []string{expected}, opLog.entries[orgID])
+				}
 
-			t.Run("rolls back all created telegrafs on an error", func(t *testing.T) {
-				testfileRunner(t, "testdata/telegraf.yml", func(t *testing.T, pkg *Pkg) {
-					fakeTeleSVC := mock.NewTelegrafConfigStore()
-					fakeTeleSVC.CreateTelegrafConfigF = func(_ context.Context, tc *influxdb.TelegrafConfig, userID influxdb.ID) error {
-						if fakeTeleSVC.CreateTelegrafConfigCalls.Count() == 1 {
-							return errors.New("limit hit")
-						}
-						tc.ID = influxdb.ID(1)
-						return nil
+				for _, orgID := range orgIDs {
+					res := results[orgID]
+					if orgID == failingOrgID {
+						require.Error(t, res.Err)
+						continue
 					}
-					fakeTeleSVC.DeleteTelegrafConfigF = func(_ context.Context, id influxdb.ID) error {
-						if id != 1 {
-							return errors.New("wrong id here")
-						}
-						return nil
+					require.NoError(t, res.Err)
+					require.Len(t, res.Summary.Buckets, len(pkg.buckets()))
+					for _, b := range res.Summary.Buckets {
+						assert.Equal(t, SafeID(orgID), b.OrgID)
 					}
+				}
 
-					pkg.mTelegrafs = append(pkg.mTelegrafs, pkg.mTelegrafs[0])
+				// nothing was created in the failing org, none of the
+				// buckets created in the other orgs is rolled back
+				assert.Equal(t, 0, fakeBktSVC.DeleteBucketCalls.Count())
+			})
+		})
 
-					svc := newTestService(WithTelegrafSVC(fakeTeleSVC))
+		t.Run("passes the secrets to each org", func(t *testing.T) {
+			testfileRunner(t, "testdata/notification_endpoint_secrets.yml", func(t *testing.T, pkg *Pkg) {
+				var (
+					mu      sync.Mutex
+					secrets = make(map[influxdb.ID]map[string]string)
+				)
+				fakeSecretSVC := mock.NewSecretService()
+				fakeSecretSVC.GetSecretKeysFn = func(ctx context.Context, orgID influxdb.ID) ([]string, error) {
+					return nil, nil
+				}
+				fakeSecretSVC.PatchSecretsFn = func(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+					mu.Lock()
+					secrets[orgID] = m
+					mu.Unlock()
+					return nil
+				}
+				fakeEndpointSVC := mock.NewNotificationEndpointService()
+				fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, nr influxdb.NotificationEndpoint, userID influxdb.ID) error {
+					nr.SetID(influxdb.ID(rand.Int()))
+					return nil
+				}
 
-					orgID := influxdb.ID(9000)
+				svc := newTestService(
+					WithNoticationEndpointSVC(fakeEndpointSVC),
+					WithSecretSVC(fakeSecretSVC),
+				)
 
-					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.Error(t, err)
+				orgIDs := []influxdb.ID{1, 2}
+				results, err := svc.ApplyToOrgs(context.TODO(), 0, pkg, orgIDs, ApplyWithSecrets(map[string]string{
+					"routing-key": "threeve",
+				}))
+				require.NoError(t, err)
 
-					assert.Equal(t, 1, fakeTeleSVC.DeleteTelegrafConfigCalls.Count())
-				})
+				for _, orgID := range orgIDs {
+					require.NoError(t, results[orgID].Err)
+					assert.Equal(t, map[string]string{"routing-key": "threeve"}, secrets[orgID])
+				}
 			})
 		})
+	})
 
-		t.Run("variables", func(t *testing.T) {
-			t.Run("successfully creates pkg of variables", func(t *testing.T) {
-				testfileRunner(t, "testdata/variables.yml", func(t *testing.T, pkg *Pkg) {
-					fakeVarSVC := mock.NewVariableService()
-					fakeVarSVC.CreateVariableF = func(_ context.Context, v *influxdb.Variable) error {
-						id, err := strconv.Atoi(v.Name[len(v.Name)-1:])
-						if err != nil {
-							return err
-						}
-						v.ID = influxdb.ID(id)
-						return nil
-					}
+	t.Run("ApplyStream", func(t *testing.T) {
+		t.Run("applies resources streamed out of order with labels first", func(t *testing.T) {
+			var (
+				mu     sync.Mutex
+				events []string
+				labels = make(map[string]*influxdb.Label)
+			)
+			record := func(event string) {
+				mu.Lock()
+				events = append(events, event)
+				mu.Unlock()
+			}
 
-					svc := newTestService(WithVariableSVC(fakeVarSVC))
+			fakeBktSVC := mock.NewBucketService()
+			fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+				return nil, errors.New("not found")
+			}
+			fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+				b.ID = influxdb.ID(rand.Int())
+				record("bucket:" + b.Name)
+				return nil
+			}
+			fakeLabelSVC := mock.NewLabelService()
+			fakeLabelSVC.FindLabelsFn = func(_ context.Context, f influxdb.LabelFilter) ([]*influxdb.Label, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if l, ok := labels[f.Name]; ok {
+					return []*influxdb.Label{l}, nil
+				}
+				return nil, nil
+			}
+			fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+				l.ID = influxdb.ID(rand.Int())
+				mu.Lock()
+				labels[l.Name] = l
+				mu.Unlock()
+				record("label:" + l.Name)
+				return nil
+			}
+			fakeLabelSVC.CreateLabelMappingFn = func(_ context.Context, m *influxdb.LabelMapping) error {
+				if m.LabelID == 0 || m.ResourceID == 0 {
+					return errors.New("mapping is missing an ID")
+				}
+				record("mapping:" + string(m.ResourceType))
+				return nil
+			}
+			fakeVarSVC := mock.NewVariableService()
+			fakeVarSVC.CreateVariableF = func(_ context.Context, v *influxdb.Variable) error {
+				v.ID = influxdb.ID(rand.Int())
+				record("variable:" + v.Name)
+				return nil
+			}
 
-					orgID := influxdb.ID(9000)
+			svc := newTestService(WithBucketSVC(fakeBktSVC), WithLabelSVC(fakeLabelSVC), WithVariableSVC(fakeVarSVC))
+			svc.applyStreamBatchSize = 2
 
-					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.NoError(t, err)
+			labelAssociation := []interface{}{
+				map[string]interface{}{"kind": "Label", "name": "label_1"},
+			}
+			variable := func(name string) Resource {
+				return Resource{
+					"kind":   "Variable",
+					"name":   name,
+					"type":   "constant",
+					"values": []interface{}{"first val"},
+				}
+			}
+
+			// the bucket associated with the label arrives ahead of it
+			resources := make(chan Resource)
+			go func() {
+				defer close(resources)
+				resources <- Resource{"kind": "Bucket", "name": "rucket_1", "associations": labelAssociation}
+				resources <- variable("var_1")
+				resources <- Resource{"kind": "Bucket", "name": "rucket_2"}
+				resources <- Resource{"kind": "Label", "name": "label_1"}
+				resources <- Resource{"kind": "Bucket", "name": "rucket_3"}
+				v := variable("var_2")
+				v["associations"] = labelAssociation
+				resources <- v
+			}()
+
+			sum, err := svc.ApplyStream(context.TODO(), influxdb.ID(9000), 0, resources)
+			require.NoError(t, err)
 
-					require.Len(t, sum.Variables, 4)
-					expected := sum.Variables[0]
-					assert.Equal(t, SafeID(3), expected.ID)
-					assert.Equal(t, SafeID(orgID), expected.OrgID)
-					assert.Equal(t, "var_const_3", expected.Name)
-					assert.Equal(t, "var_const_3 desc", expected.Description)
-					require.NotNil(t, expected.Arguments)
-					assert.Equal(t, influxdb.VariableConstantValues{"first val"}, expected.Arguments.Values)
+			require.NotEmpty(t, events)
+			assert.Equal(t, "label:label_1", events[0])
+			assert.Equal(t, 1, fakeLabelSVC.CreateLabelCalls.Count())
+			assert.Equal(t, 2, fakeLabelSVC.CreateLabelMappingCalls.Count())
+			assert.Equal(t, 3, fakeBktSVC.CreateBucketCalls.Count())
+			assert.Equal(t, 2, fakeVarSVC.CreateVariableCalls.Count())
+
+			// var_2 arrives after the first batch is applied, it is applied
+			// with the last one
+			assert.Equal(t, "mapping:"+string(influxdb.VariablesResourceType), events[len(events)-1])
+
+			require.Len(t, sum.Labels, 1)
+			assert.Len(t, sum.Buckets, 3)
+			assert.Len(t, sum.Variables, 2)
+			assert.Len(t, sum.LabelMappings, 2)
+			assert.Equal(t, SummaryStatusCount{Created: 1}, sum.StatusCounts[KindLabel])
+		})
 
-					for _, actual := range sum.Variables {
-						assert.Contains(t, []SafeID{1, 2, 3, 4}, actual.ID)
-					}
-				})
-			})
+		check := func(bucket string) Resource {
+			return Resource{
+				"kind":   "Check_Threshold",
+				"name":   "check_1",
+				"bucket": bucket,
+				"field":  "usage_user",
+				"query":  `from(bucket: "` + bucket + `") |> range(start: -1m)`,
+				"every":  "1m",
+				"thresholds": []interface{}{
+					map[string]interface{}{"type": "greater", "level": "CRIT", "value": 50},
+				},
+			}
+		}
 
-			t.Run("rolls back all created variables on an error", func(t *testing.T) {
-				testfileRunner(t, "testdata/variables.yml", func(t *testing.T, pkg *Pkg) {
-					fakeVarSVC := mock.NewVariableService()
-					fakeVarSVC.CreateVariableF = func(_ context.Context, l *influxdb.Variable) error {
-						// 4th variable will return the error here, and 3 before should be rolled back
-						if fakeVarSVC.CreateVariableCalls.Count() == 2 {
-							return errors.New("blowed up ")
-						}
-						return nil
-					}
+		t.Run("applies a check after the bucket it queries arriving after it", func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				events  []string
+				buckets = make(map[string]*influxdb.Bucket)
+			)
 
-					svc := newTestService(WithVariableSVC(fakeVarSVC))
+			fakeBktSVC := mock.NewBucketService()
+			fakeBktSVC.FindBucketByNameFn = func(_ context.Context, _ influxdb.ID, name string) (*influxdb.Bucket, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if b, ok := buckets[name]; ok {
+					return b, nil
+				}
+				return nil, &influxdb.Error{Code: influxdb.ENotFound}
+			}
+			fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+				b.ID = influxdb.ID(rand.Int())
+				mu.Lock()
+				buckets[b.Name] = b
+				events = append(events, "bucket:"+b.Name)
+				mu.Unlock()
+				return nil
+			}
+			fakeCheckSVC := mock.NewCheckService()
+			fakeCheckSVC.CreateCheckFn = func(_ context.Context, c influxdb.CheckCreate, _ influxdb.ID) error {
+				c.SetID(influxdb.ID(rand.Int()))
+				mu.Lock()
+				events = append(events, "check:"+c.GetName())
+				mu.Unlock()
+				return nil
+			}
 
-					orgID := influxdb.ID(9000)
+			svc := newTestService(WithBucketSVC(fakeBktSVC), WithCheckSVC(fakeCheckSVC))
+			svc.applyStreamBatchSize = 1
 
-					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.Error(t, err)
+			resources := make(chan Resource)
+			go func() {
+				defer close(resources)
+				resources <- check("rucket_1")
+				resources <- Resource{"kind": "Bucket", "name": "rucket_1"}
+			}()
 
-					assert.GreaterOrEqual(t, fakeVarSVC.DeleteVariableCalls.Count(), 1)
-				})
-			})
+			sum, err := svc.ApplyStream(context.TODO(), influxdb.ID(9000), 0, resources)
+			require.NoError(t, err)
 
-			t.Run("will not apply variable if no changes to be applied", func(t *testing.T) {
-				testfileRunner(t, "testdata/variables.yml", func(t *testing.T, pkg *Pkg) {
-					orgID := influxdb.ID(9000)
+			assert.Equal(t, []string{"bucket:rucket_1", "check:check_1"}, events)
+			assert.Len(t, sum.Buckets, 1)
+			assert.Len(t, sum.Checks, 1)
+		})
 
-					pkg.isVerified = true
-					pkgLabel := pkg.mVariables["var_const_3"]
-					pkgLabel.existing = &influxdb.Variable{
-						// makes all pkg changes same as they are on the existing
-						ID:             influxdb.ID(1),
-						OrganizationID: orgID,
-						Name:           pkgLabel.Name(),
-						Arguments: &influxdb.VariableArguments{
-							Type:   "constant",
-							Values: influxdb.VariableConstantValues{"first val"},
-						},
-					}
+		t.Run("rolls back the batches applied on an error", func(t *testing.T) {
+			fakeBktSVC := mock.NewBucketService()
+			fakeBktSVC.FindBucketByNameFn = func(context.Context, influxdb.ID, string) (*influxdb.Bucket, error) {
+				return nil, &influxdb.Error{Code: influxdb.ENotFound}
+			}
+			fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+				b.ID = influxdb.ID(rand.Int())
+				return nil
+			}
+			var deletedIDs []influxdb.ID
+			fakeBktSVC.DeleteBucketFn = func(_ context.Context, id influxdb.ID) error {
+				deletedIDs = append(deletedIDs, id)
+				return nil
+			}
+			fakeCheckSVC := mock.NewCheckService()
+			fakeCheckSVC.CreateCheckFn = func(context.Context, influxdb.CheckCreate, influxdb.ID) error {
+				return errors.New("limit hit")
+			}
 
-					fakeVarSVC := mock.NewVariableService()
-					fakeVarSVC.CreateVariableF = func(_ context.Context, l *influxdb.Variable) error {
-						if l.Name == "var_const" {
-							return errors.New("shouldn't get here")
-						}
-						return nil
-					}
-					fakeVarSVC.UpdateVariableF = func(_ context.Context, id influxdb.ID, v *influxdb.VariableUpdate) (*influxdb.Variable, error) {
-						if id > influxdb.ID(1) {
-							return nil, errors.New("this id should not be updated")
-						}
-						return &influxdb.Variable{ID: id}, nil
-					}
+			svc := newTestService(WithBucketSVC(fakeBktSVC), WithCheckSVC(fakeCheckSVC))
+			svc.applyStreamBatchSize = 1
 
-					svc := newTestService(WithVariableSVC(fakeVarSVC))
+			resources := make(chan Resource)
+			go func() {
+				defer close(resources)
+				resources <- Resource{"kind": "Bucket", "name": "rucket_1"}
+				resources <- Resource{"kind": "Bucket", "name": "rucket_2"}
+				resources <- check("rucket_1")
+			}()
 
-					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.NoError(t, err)
+			_, err := svc.ApplyStream(context.TODO(), influxdb.ID(9000), 0, resources)
+			require.Error(t, err)
 
-					require.Len(t, sum.Variables, 4)
-					expected := sum.Variables[0]
-					assert.Equal(t, SafeID(1), expected.ID)
-					assert.Equal(t, "var_const_3", expected.Name)
+			// both buckets were applied in batches of their own ahead of the check
+			assert.Equal(t, 2, fakeBktSVC.CreateBucketCalls.Count())
+			assert.Len(t, deletedIDs, 2)
+		})
 
-					assert.Equal(t, 3, fakeVarSVC.CreateVariableCalls.Count()) // only called for last 3 labels
-				})
-			})
+		t.Run("errors on an empty stream", func(t *testing.T) {
+			resources := make(chan Resource)
+			close(resources)
+
+			_, err := newTestService().ApplyStream(context.TODO(), influxdb.ID(9000), 0, resources)
+			require.Error(t, err)
 		})
 	})
 
@@ -974,6 +3970,155 @@ func TestService(t *testing.T) {
 			assert.NotNil(t, pkg.Spec.Resources)
 		})
 
+		t.Run("with previous metadata increments the version", func(t *testing.T) {
+			tests := []struct {
+				prev     string
+				expected string
+			}{
+				{prev: "1", expected: "2"},
+				{prev: "v9", expected: "v10"},
+				{prev: "1.2.3", expected: "1.2.4"},
+				{prev: "v0.1.9", expected: "v0.1.10"},
+				{prev: "1.0", expected: "1.1"},
+				{prev: "v2.9", expected: "v2.10"},
+				{prev: "v1.0.0-rc1", expected: "v1.0.1"},
+			}
+
+			for _, tt := range tests {
+				fn := func(t *testing.T) {
+					svc := newTestService()
+
+					prev := Metadata{
+						Description: "desc",
+						Name:        "name",
+						Version:     tt.prev,
+					}
+					pkg, err := svc.CreatePkg(context.TODO(), CreateWithPreviousMetadata(prev))
+					require.NoError(t, err)
+
+					assert.Equal(t, "desc", pkg.Metadata.Description)
+					assert.Equal(t, "name", pkg.Metadata.Name)
+					assert.Equal(t, tt.expected, pkg.Metadata.Version)
+				}
+				t.Run(tt.prev, fn)
+			}
+
+			t.Run("fails for a version that cannot be incremented", func(t *testing.T) {
+				svc := newTestService()
+
+				_, err := svc.CreatePkg(context.TODO(), CreateWithPreviousMetadata(Metadata{
+					Name:    "name",
+					Version: "first",
+				}))
+				require.Error(t, err)
+				assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+			})
+		})
+
+		t.Run("records the export time and source org", func(t *testing.T) {
+			now := time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)
+			orgID := influxdb.ID(9000)
+
+			bktSVC := mock.NewBucketService()
+			bktSVC.FindBucketsFn = func(_ context.Context, f influxdb.BucketFilter, opts ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+				return []*influxdb.Bucket{{ID: 1, OrgID: orgID, Name: "bucket"}}, 1, nil
+			}
+			bktSVC.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				return &influxdb.Bucket{ID: id, OrgID: orgID, Name: "bucket"}, nil
+			}
+
+			svc := newTestService(
+				WithBucketSVC(bktSVC),
+				WithTimeGenerator(mock.TimeGenerator{FakeValue: now}),
+			)
+
+			pkg, err := svc.CreatePkg(context.TODO(),
+				CreateWithPreviousMetadata(Metadata{Name: "name", Version: "v1"}),
+				CreateWithAllOrgResources(orgID),
+			)
+			require.NoError(t, err)
+
+			assert.Equal(t, "v2", pkg.Metadata.Version)
+			require.NotNil(t, pkg.Metadata.ExportedAt)
+			assert.Equal(t, now, *pkg.Metadata.ExportedAt)
+			assert.Equal(t, orgID.String(), pkg.Metadata.SourceOrgID)
+		})
+
+		t.Run("default name is the same for the same org and resources", func(t *testing.T) {
+			bktSVC := mock.NewBucketService()
+			bktSVC.FindBucketsFn = func(_ context.Context, f influxdb.BucketFilter, opts ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+				return []*influxdb.Bucket{{ID: 1, OrgID: *f.OrganizationID, Name: "bucket"}}, 1, nil
+			}
+			bktSVC.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				return &influxdb.Bucket{ID: id, Name: "bucket"}, nil
+			}
+			svc := newTestService(WithBucketSVC(bktSVC))
+
+			create := func(orgID influxdb.ID) string {
+				t.Helper()
+				pkg, err := svc.CreatePkg(context.TODO(), CreateWithAllOrgResources(orgID))
+				require.NoError(t, err)
+				return pkg.Metadata.Name
+			}
+
+			name := create(9000)
+			assert.True(t, strings.HasPrefix(name, "new_"), name)
+			assert.Equal(t, name, create(9000))
+			assert.NotEqual(t, name, create(9001))
+
+			pkg, err := svc.CreatePkg(context.TODO(),
+				CreateWithMetadata(Metadata{Name: "name"}),
+				CreateWithAllOrgResources(9000),
+			)
+			require.NoError(t, err)
+			assert.Equal(t, "name", pkg.Metadata.Name)
+		})
+
+		t.Run("new resource to clone", func(t *testing.T) {
+			t.Run("valid resource", func(t *testing.T) {
+				r, err := NewResourceToClone(KindBucket, influxdb.ID(1), "new name")
+				require.NoError(t, err)
+
+				expected := ResourceToClone{
+					Kind: KindBucket,
+					ID:   influxdb.ID(1),
+					Name: "new name",
+				}
+				assert.Equal(t, expected, r)
+			})
+
+			t.Run("invalid resource", func(t *testing.T) {
+				tests := []struct {
+					name string
+					kind Kind
+					id   influxdb.ID
+				}{
+					{
+						name: "missing kind",
+						id:   influxdb.ID(1),
+					},
+					{
+						name: "unsupported kind",
+						kind: Kind("rando"),
+						id:   influxdb.ID(1),
+					},
+					{
+						name: "zero id",
+						kind: KindBucket,
+					},
+				}
+
+				for _, tt := range tests {
+					fn := func(t *testing.T) {
+						r, err := NewResourceToClone(tt.kind, tt.id, "")
+						require.Error(t, err)
+						assert.Zero(t, r)
+					}
+					t.Run(tt.name, fn)
+				}
+			})
+		})
+
 		t.Run("with existing resources", func(t *testing.T) {
 			t.Run("bucket", func(t *testing.T) {
 				tests := []struct {
@@ -1317,27 +4462,299 @@ func TestService(t *testing.T) {
 						pkg, err := svc.CreatePkg(context.TODO(), CreateWithExistingResources(resToClone))
 						require.NoError(t, err)
 
-						dashs := pkg.Summary().Dashboards
-						require.Len(t, dashs, 1)
+						dashs := pkg.Summary().Dashboards
+						require.Len(t, dashs, 1)
+
+						actual := dashs[0]
+						expectedName := expected.Name
+						if tt.newName != "" {
+							expectedName = tt.newName
+						}
+						assert.Equal(t, expectedName, actual.Name)
+						assert.Equal(t, expected.Description, actual.Description)
+
+						require.Len(t, actual.Charts, 1)
+						ch := actual.Charts[0]
+						assert.Equal(t, int(expectedCell.X), ch.XPosition)
+						assert.Equal(t, int(expectedCell.Y), ch.YPosition)
+						assert.Equal(t, int(expectedCell.H), ch.Height)
+						assert.Equal(t, int(expectedCell.W), ch.Width)
+						assert.Equal(t, tt.expectedView.Properties, ch.Properties)
+					}
+					t.Run(tt.name, fn)
+				}
+			})
+
+			t.Run("dashboard cells read with a stub view", func(t *testing.T) {
+				view := &influxdb.View{
+					ViewContents: influxdb.ViewContents{Name: "view name"},
+					Properties: influxdb.MarkdownViewProperties{
+						Type: influxdb.ViewPropertyTypeMarkdown,
+						Note: "a note",
+					},
+				}
+
+				dashSVC := mock.NewDashboardService()
+				dashSVC.FindDashboardByIDF = func(_ context.Context, id influxdb.ID) (*influxdb.Dashboard, error) {
+					// the dashboard service reads the cells with only the
+					// name of their views
+					return &influxdb.Dashboard{
+						ID:   id,
+						Name: "dash_1",
+						Cells: []*influxdb.Cell{{
+							ID:           5,
+							CellProperty: influxdb.CellProperty{W: 3, H: 4},
+							View:         &influxdb.View{ViewContents: influxdb.ViewContents{Name: "view name"}},
+						}},
+					}, nil
+				}
+				dashSVC.GetDashboardCellViewF = func(_ context.Context, id influxdb.ID, cID influxdb.ID) (*influxdb.View, error) {
+					return view, nil
+				}
+
+				svc := newTestService(WithDashboardSVC(dashSVC), WithLabelSVC(mock.NewLabelService()))
+
+				pkg, err := svc.CreatePkg(context.TODO(), CreateWithExistingResources(ResourceToClone{
+					Kind: KindDashboard,
+					ID:   3,
+				}))
+				require.NoError(t, err)
+
+				dashs := pkg.Summary().Dashboards
+				require.Len(t, dashs, 1)
+				require.Len(t, dashs[0].Charts, 1)
+				assert.Equal(t, view.Properties, dashs[0].Charts[0].Properties)
+			})
+
+			t.Run("dashboard referencing variables", func(t *testing.T) {
+				newDashSVC := func() *mock.DashboardService {
+					view := &influxdb.View{
+						ViewContents: influxdb.ViewContents{Name: "view name"},
+						Properties: influxdb.SingleStatViewProperties{
+							Type: influxdb.ViewPropertyTypeSingleStat,
+							Queries: []influxdb.DashboardQuery{
+								{Text: "from(bucket: v.bucket) |> range(start: v.timeRangeStart) |> filter(fn: (r) => r.host == v.host)"},
+								{Text: "from(bucket: v.bucket) |> range(start: v.timeRangeStart)"},
+							},
+							ViewColors: []influxdb.ViewColor{{Type: "text", Hex: "red"}},
+						},
+					}
+
+					dashSVC := mock.NewDashboardService()
+					dashSVC.FindDashboardByIDF = func(_ context.Context, id influxdb.ID) (*influxdb.Dashboard, error) {
+						return &influxdb.Dashboard{
+							ID:             id,
+							OrganizationID: 9000,
+							Name:           "dash_" + id.String(),
+							Cells: []*influxdb.Cell{
+								{ID: 5, CellProperty: influxdb.CellProperty{W: 3, H: 4}},
+							},
+						}, nil
+					}
+					dashSVC.GetDashboardCellViewF = func(_ context.Context, id influxdb.ID, cID influxdb.ID) (*influxdb.View, error) {
+						return view, nil
+					}
+					return dashSVC
+				}
+
+				newVarSVC := func() *mock.VariableService {
+					varSVC := mock.NewVariableService()
+					varSVC.FindVariablesF = func(_ context.Context, filter influxdb.VariableFilter, _ ...influxdb.FindOptions) ([]*influxdb.Variable, error) {
+						if filter.OrganizationID == nil || *filter.OrganizationID != 9000 {
+							return nil, errors.New("wrong org id")
+						}
+						return []*influxdb.Variable{
+							{
+								ID:   1,
+								Name: "bucket",
+								Arguments: &influxdb.VariableArguments{
+									Type:   "constant",
+									Values: influxdb.VariableConstantValues{"bucket_1", "bucket_2"},
+								},
+							},
+							{
+								ID:   2,
+								Name: "host",
+								Arguments: &influxdb.VariableArguments{
+									Type:   "map",
+									Values: influxdb.VariableMapValues{"k1": "host_1"},
+								},
+							},
+							{
+								ID:   3,
+								Name: "unreferenced",
+								Arguments: &influxdb.VariableArguments{
+									Type:   "constant",
+									Values: influxdb.VariableConstantValues{"val"},
+								},
+							},
+						}, nil
+					}
+					return varSVC
+				}
+
+				t.Run("includes referenced variables once", func(t *testing.T) {
+					svc := newTestService(
+						WithDashboardSVC(newDashSVC()),
+						WithLabelSVC(mock.NewLabelService()),
+						WithVariableSVC(newVarSVC()),
+					)
+
+					resourcesToClone := []ResourceToClone{
+						{Kind: KindDashboard, ID: 1},
+						{Kind: KindDashboard, ID: 2},
+					}
+					pkg, err := svc.CreatePkg(context.TODO(), CreateWithExistingResources(resourcesToClone...))
+					require.NoError(t, err)
+
+					sum := pkg.Summary()
+					require.Len(t, sum.Dashboards, 2)
+
+					vars := sum.Variables
+					require.Len(t, vars, 2)
+					assert.Equal(t, "bucket", vars[0].Name)
+					assert.Equal(t, influxdb.VariableConstantValues{"bucket_1", "bucket_2"}, vars[0].Arguments.Values)
+					assert.Equal(t, "host", vars[1].Name)
+					assert.Equal(t, influxdb.VariableMapValues{"k1": "host_1"}, vars[1].Arguments.Values)
+				})
+
+				t.Run("includes variable labels and warns of unknown variables", func(t *testing.T) {
+					dashSVC := mock.NewDashboardService()
+					dashSVC.FindDashboardByIDF = func(_ context.Context, id influxdb.ID) (*influxdb.Dashboard, error) {
+						return &influxdb.Dashboard{
+							ID:             id,
+							OrganizationID: 9000,
+							Name:           "dash_1",
+							Cells: []*influxdb.Cell{
+								{ID: 5, CellProperty: influxdb.CellProperty{W: 3, H: 4}},
+							},
+						}, nil
+					}
+					dashSVC.GetDashboardCellViewF = func(_ context.Context, id influxdb.ID, cID influxdb.ID) (*influxdb.View, error) {
+						return &influxdb.View{
+							ViewContents: influxdb.ViewContents{Name: "view name"},
+							Properties: influxdb.SingleStatViewProperties{
+								Type: influxdb.ViewPropertyTypeSingleStat,
+								Queries: []influxdb.DashboardQuery{
+									{Text: `from(bucket: v["bucket"]) |> range(start: v.timeRangeStart) |> filter(fn: (r) => r.region == v.region)`},
+								},
+								ViewColors: []influxdb.ViewColor{{Type: "text", Hex: "red"}},
+							},
+						}, nil
+					}
+
+					labelSVC := mock.NewLabelService()
+					labelSVC.FindResourceLabelsFn = func(_ context.Context, f influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+						if f.ResourceType != influxdb.VariablesResourceType || f.ResourceID != 1 {
+							return nil, nil
+						}
+						return []*influxdb.Label{{ID: 7, Name: "label_1"}}, nil
+					}
+
+					svc := newTestService(
+						WithDashboardSVC(dashSVC),
+						WithLabelSVC(labelSVC),
+						WithVariableSVC(newVarSVC()),
+					)
+
+					pkg, err := svc.CreatePkg(context.TODO(), CreateWithExistingResources(ResourceToClone{
+						Kind: KindDashboard,
+						ID:   1,
+					}))
+					require.NoError(t, err)
+
+					sum := pkg.Summary()
+					require.Len(t, sum.Variables, 1)
+					assert.Equal(t, "bucket", sum.Variables[0].Name)
+
+					require.Len(t, sum.Labels, 1)
+					assert.Equal(t, "label_1", sum.Labels[0].Name)
+					require.Len(t, sum.Variables[0].LabelAssociations, 1)
+					assert.Equal(t, "label_1", sum.Variables[0].LabelAssociations[0].Name)
+
+					require.Len(t, pkg.Warnings, 1)
+					assert.Contains(t, pkg.Warnings[0], `"region"`)
+				})
+
+				t.Run("finds variables beyond the first page", func(t *testing.T) {
+					varSVC := mock.NewVariableService()
+					varSVC.FindVariablesF = func(_ context.Context, _ influxdb.VariableFilter, opts ...influxdb.FindOptions) ([]*influxdb.Variable, error) {
+						if len(opts) == 0 || opts[0].Offset > influxdb.MaxPageSize {
+							return nil, nil
+						}
+						if opts[0].Offset == influxdb.MaxPageSize {
+							return []*influxdb.Variable{
+								{
+									ID:   influxdb.ID(influxdb.MaxPageSize + 1),
+									Name: "host",
+									Arguments: &influxdb.VariableArguments{
+										Type:   "constant",
+										Values: influxdb.VariableConstantValues{"host_1"},
+									},
+								},
+							}, nil
+						}
+
+						var vars []*influxdb.Variable
+						for i := 1; i <= influxdb.MaxPageSize; i++ {
+							name := "var_" + strconv.Itoa(i)
+							if i == 1 {
+								name = "bucket"
+							}
+							vars = append(vars, &influxdb.Variable{
+								ID:   influxdb.ID(i),
+								Name: name,
+								Arguments: &influxdb.VariableArguments{
+									Type:   "constant",
+									Values: influxdb.VariableConstantValues{"val"},
+								},
+							})
+						}
+						return vars, nil
+					}
+
+					svc := newTestService(
+						WithDashboardSVC(newDashSVC()),
+						WithLabelSVC(mock.NewLabelService()),
+						WithVariableSVC(varSVC),
+					)
+
+					pkg, err := svc.CreatePkg(context.TODO(), CreateWithExistingResources(ResourceToClone{
+						Kind: KindDashboard,
+						ID:   1,
+					}))
+					require.NoError(t, err)
+
+					vars := pkg.Summary().Variables
+					require.Len(t, vars, 2)
+					assert.Equal(t, "bucket", vars[0].Name)
+					assert.Equal(t, "host", vars[1].Name)
+					assert.Empty(t, pkg.Warnings)
+				})
 
-						actual := dashs[0]
-						expectedName := expected.Name
-						if tt.newName != "" {
-							expectedName = tt.newName
-						}
-						assert.Equal(t, expectedName, actual.Name)
-						assert.Equal(t, expected.Description, actual.Description)
+				t.Run("excludes variables when opted out", func(t *testing.T) {
+					varSVC := mock.NewVariableService()
+					varSVC.FindVariablesF = func(context.Context, influxdb.VariableFilter, ...influxdb.FindOptions) ([]*influxdb.Variable, error) {
+						return nil, errors.New("should not get here")
+					}
+					svc := newTestService(
+						WithDashboardSVC(newDashSVC()),
+						WithLabelSVC(mock.NewLabelService()),
+						WithVariableSVC(varSVC),
+					)
 
-						require.Len(t, actual.Charts, 1)
-						ch := actual.Charts[0]
-						assert.Equal(t, int(expectedCell.X), ch.XPosition)
-						assert.Equal(t, int(expectedCell.Y), ch.YPosition)
-						assert.Equal(t, int(expectedCell.H), ch.Height)
-						assert.Equal(t, int(expectedCell.W), ch.Width)
-						assert.Equal(t, tt.expectedView.Properties, ch.Properties)
+					resToClone := ResourceToClone{
+						Kind:             KindDashboard,
+						ID:               1,
+						ExcludeVariables: true,
 					}
-					t.Run(tt.name, fn)
-				}
+					pkg, err := svc.CreatePkg(context.TODO(), CreateWithExistingResources(resToClone))
+					require.NoError(t, err)
+
+					sum := pkg.Summary()
+					require.Len(t, sum.Dashboards, 1)
+					assert.Empty(t, sum.Variables)
+				})
 			})
 
 			t.Run("label", func(t *testing.T) {
@@ -1401,12 +4818,14 @@ func TestService(t *testing.T) {
 
 			t.Run("notification endpoints", func(t *testing.T) {
 				tests := []struct {
-					name     string
-					newName  string
-					expected influxdb.NotificationEndpoint
+					name         string
+					newName      string
+					expectedKind Kind
+					expected     influxdb.NotificationEndpoint
 				}{
 					{
-						name: "pager duty",
+						name:         "pager duty",
+						expectedKind: KindNotificationEndpointPagerDuty,
 						expected: &endpoint.PagerDuty{
 							Base: endpoint.Base{
 								Name:        "pd-endpoint",
@@ -1418,8 +4837,9 @@ func TestService(t *testing.T) {
 						},
 					},
 					{
-						name:    "pager duty with new name",
-						newName: "new name",
+						name:         "pager duty with new name",
+						newName:      "new name",
+						expectedKind: KindNotificationEndpointPagerDuty,
 						expected: &endpoint.PagerDuty{
 							Base: endpoint.Base{
 								Name:        "pd-endpoint",
@@ -1431,7 +4851,8 @@ func TestService(t *testing.T) {
 						},
 					},
 					{
-						name: "slack",
+						name:         "slack",
+						expectedKind: KindNotificationEndpointSlack,
 						expected: &endpoint.Slack{
 							Base: endpoint.Base{
 								Name:        "pd-endpoint",
@@ -1443,7 +4864,8 @@ func TestService(t *testing.T) {
 						},
 					},
 					{
-						name: "http basic",
+						name:         "http basic",
+						expectedKind: KindNotificationEndpointHTTP,
 						expected: &endpoint.HTTP{
 							Base: endpoint.Base{
 								Name:        "pd-endpoint",
@@ -1458,7 +4880,8 @@ func TestService(t *testing.T) {
 						},
 					},
 					{
-						name: "http bearer",
+						name:         "http bearer",
+						expectedKind: KindNotificationEndpointHTTP,
 						expected: &endpoint.HTTP{
 							Base: endpoint.Base{
 								Name:        "pd-endpoint",
@@ -1472,7 +4895,8 @@ func TestService(t *testing.T) {
 						},
 					},
 					{
-						name: "http none",
+						name:         "http none",
+						expectedKind: KindNotificationEndpointHTTP,
 						expected: &endpoint.HTTP{
 							Base: endpoint.Base{
 								Name:        "pd-endpoint",
@@ -1506,9 +4930,28 @@ func TestService(t *testing.T) {
 							ID:   tt.expected.GetID(),
 							Name: tt.newName,
 						}
-						pkg, err := svc.CreatePkg(context.TODO(), CreateWithExistingResources(resToClone))
+						pkg, err := svc.CreatePkg(context.TODO(),
+							CreateWithMetadata(Metadata{Name: "pkg name", Version: "1"}),
+							CreateWithExistingResources(resToClone),
+						)
 						require.NoError(t, err)
 
+						require.Len(t, pkg.Spec.Resources, 1)
+						actualKind, err := pkg.Spec.Resources[0].kind()
+						require.NoError(t, err)
+						assert.Equal(t, tt.expectedKind, actualKind)
+
+						// the concrete kind survives the export -> parse -> summary round trip
+						b, err := yaml.Marshal(pkg)
+						require.NoError(t, err)
+
+						reparsed, err := Parse(EncodingYAML, FromString(string(b)))
+						require.NoError(t, err)
+
+						reparsedEndpoints := reparsed.Summary().NotificationEndpoints
+						require.Len(t, reparsedEndpoints, 1)
+						assert.Equal(t, tt.expected.Type(), reparsedEndpoints[0].NotificationEndpoint.Type())
+
 						endpoints := pkg.Summary().NotificationEndpoints
 						require.Len(t, endpoints, 1)
 
@@ -1526,6 +4969,73 @@ func TestService(t *testing.T) {
 				}
 			})
 
+			t.Run("scraper target", func(t *testing.T) {
+				tests := []struct {
+					name    string
+					newName string
+				}{
+					{
+						name: "without new name",
+					},
+					{
+						name:    "with new name",
+						newName: "new name",
+					},
+				}
+
+				for _, tt := range tests {
+					fn := func(t *testing.T) {
+						expectedTarget := &influxdb.ScraperTarget{
+							ID:       3,
+							Name:     "scraper name",
+							Type:     influxdb.PrometheusScraperType,
+							URL:      "http://localhost:9100/metrics",
+							BucketID: 4,
+						}
+
+						scraperSVC := mock.NewScraperTargetStoreService()
+						scraperSVC.GetTargetByIDF = func(_ context.Context, id influxdb.ID) (*influxdb.ScraperTarget, error) {
+							if id != expectedTarget.ID {
+								return nil, errors.New("uh ohhh, wrong id here: " + id.String())
+							}
+							return expectedTarget, nil
+						}
+
+						bktSVC := mock.NewBucketService()
+						bktSVC.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+							if id != expectedTarget.BucketID {
+								return nil, errors.New("uh ohhh, wrong id here: " + id.String())
+							}
+							return &influxdb.Bucket{ID: id, Name: "rucket_1"}, nil
+						}
+
+						svc := newTestService(WithScraperTargetSVC(scraperSVC), WithBucketSVC(bktSVC))
+
+						resToClone := ResourceToClone{
+							Kind: KindScraperTarget,
+							ID:   expectedTarget.ID,
+							Name: tt.newName,
+						}
+						pkg, err := svc.CreatePkg(context.TODO(), CreateWithExistingResources(resToClone))
+						require.NoError(t, err)
+
+						newTargets := pkg.Summary().ScraperTargets
+						require.Len(t, newTargets, 1)
+
+						actual := newTargets[0]
+						expectedName := expectedTarget.Name
+						if tt.newName != "" {
+							expectedName = tt.newName
+						}
+						assert.Equal(t, expectedName, actual.Name)
+						assert.Equal(t, expectedTarget.Type, actual.Type)
+						assert.Equal(t, expectedTarget.URL, actual.URL)
+						assert.Equal(t, "rucket_1", actual.Bucket)
+					}
+					t.Run(tt.name, fn)
+				}
+			})
+
 			t.Run("variable", func(t *testing.T) {
 				tests := []struct {
 					name        string
@@ -1741,6 +5251,109 @@ func TestService(t *testing.T) {
 			})
 		})
 
+		t.Run("with dashboard and deps", func(t *testing.T) {
+			dashSVC := mock.NewDashboardService()
+			dashSVC.FindDashboardByIDF = func(_ context.Context, id influxdb.ID) (*influxdb.Dashboard, error) {
+				if id != 1 {
+					return nil, errors.New("wrong id")
+				}
+				return &influxdb.Dashboard{
+					ID:             1,
+					OrganizationID: 9000,
+					Name:           "dash_1",
+					Cells:          []*influxdb.Cell{},
+				}, nil
+			}
+
+			labelSVC := mock.NewLabelService()
+			labelSVC.FindResourceLabelsFn = func(_ context.Context, f influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+				if f.ResourceID != 1 || f.ResourceType != influxdb.DashboardsResourceType {
+					return nil, errors.New("wrong resource")
+				}
+				return []*influxdb.Label{
+					{ID: 2, Name: "label_1"},
+					{ID: 3, Name: "label_2"},
+				}, nil
+			}
+
+			svc := newTestService(
+				WithDashboardSVC(dashSVC),
+				WithLabelSVC(labelSVC),
+				WithVariableSVC(mock.NewVariableService()),
+			)
+
+			pkg, err := svc.CreatePkg(context.TODO(), CreateWithDashboardAndDeps(1))
+			require.NoError(t, err)
+
+			summary := pkg.Summary()
+			dashs := summary.Dashboards
+			require.Len(t, dashs, 1)
+			assert.Equal(t, "dash_1", dashs[0].Name)
+
+			labels := summary.Labels
+			require.Len(t, labels, 2)
+			assert.Equal(t, "label_1", labels[0].Name)
+			assert.Equal(t, "label_2", labels[1].Name)
+
+			mappings := summary.LabelMappings
+			require.Len(t, mappings, 2)
+			for i, labelName := range []string{"label_1", "label_2"} {
+				assert.Equal(t, "dash_1", mappings[i].ResourceName)
+				assert.Equal(t, influxdb.DashboardsResourceType, mappings[i].ResourceType)
+				assert.Equal(t, labelName, mappings[i].LabelName)
+			}
+
+			t.Run("errors for a zero id", func(t *testing.T) {
+				_, err := svc.CreatePkg(context.TODO(), CreateWithDashboardAndDeps(0))
+				require.Error(t, err)
+			})
+		})
+
+		t.Run("with formatted queries", func(t *testing.T) {
+			const rawQuery = `from(bucket:"rucket")   |>  range(start:-5m)`
+
+			dashSVC := mock.NewDashboardService()
+			dashSVC.FindDashboardByIDF = func(_ context.Context, id influxdb.ID) (*influxdb.Dashboard, error) {
+				return &influxdb.Dashboard{
+					ID:    id,
+					Name:  "dash_1",
+					Cells: []*influxdb.Cell{{ID: 2, CellProperty: influxdb.CellProperty{W: 3, H: 4}}},
+				}, nil
+			}
+			dashSVC.GetDashboardCellViewF = func(_ context.Context, id influxdb.ID, cID influxdb.ID) (*influxdb.View, error) {
+				return &influxdb.View{
+					ViewContents: influxdb.ViewContents{Name: "view name"},
+					Properties: influxdb.SingleStatViewProperties{
+						Type:       influxdb.ViewPropertyTypeSingleStat,
+						Queries:    []influxdb.DashboardQuery{{Text: rawQuery, EditMode: "builder"}},
+						ViewColors: []influxdb.ViewColor{{Type: "text", Hex: "red"}},
+					},
+				}, nil
+			}
+
+			svc := newTestService(
+				WithDashboardSVC(dashSVC),
+				WithLabelSVC(mock.NewLabelService()),
+				WithVariableSVC(mock.NewVariableService()),
+			)
+
+			pkg, err := svc.CreatePkg(context.TODO(),
+				CreateWithDashboardAndDeps(1),
+				CreateWithFormattedQueries(),
+			)
+			require.NoError(t, err)
+
+			dashs := pkg.Summary().Dashboards
+			require.Len(t, dashs, 1)
+			require.Len(t, dashs[0].Charts, 1)
+
+			props, ok := dashs[0].Charts[0].Properties.(influxdb.SingleStatViewProperties)
+			require.True(t, ok)
+			require.Len(t, props.Queries, 1)
+			assert.NotEqual(t, rawQuery, props.Queries[0].Text)
+			assert.Equal(t, formatQuery(`from(bucket: "rucket") |> range(start: -5m)`), props.Queries[0].Text)
+		})
+
 		t.Run("with org id", func(t *testing.T) {
 			orgID := influxdb.ID(9000)
 
@@ -1863,4 +5476,482 @@ func TestService(t *testing.T) {
 			assert.Equal(t, "variable", vars[0].Name)
 		})
 	})
+
+	t.Run("Remove", func(t *testing.T) {
+		const orgID influxdb.ID = 9000
+
+		newLabelSVC := func(outsideMappings bool) *mock.LabelService {
+			labelSVC := mock.NewLabelService()
+			labelSVC.FindLabelsFn = func(context.Context, influxdb.LabelFilter) ([]*influxdb.Label, error) {
+				return []*influxdb.Label{{ID: 1, OrgID: orgID, Name: "label_1"}}, nil
+			}
+			labelSVC.FindResourceLabelsFn = func(_ context.Context, f influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+				if f.ResourceID == 3 || outsideMappings {
+					return []*influxdb.Label{{ID: 1, Name: "label_1"}}, nil
+				}
+				return nil, nil
+			}
+			return labelSVC
+		}
+
+		t.Run("removes matching resources and their label mappings", func(t *testing.T) {
+			testfileRunner(t, "testdata/dashboard_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+				dashSVC := mock.NewDashboardService()
+				dashSVC.FindDashboardsF = func(context.Context, influxdb.DashboardFilter, influxdb.FindOptions) ([]*influxdb.Dashboard, int, error) {
+					return []*influxdb.Dashboard{
+						{ID: 3, OrganizationID: orgID, Name: "dash_1"},
+						{ID: 4, OrganizationID: orgID, Name: "dash_outside"},
+					}, 2, nil
+				}
+				var deletedDashIDs []influxdb.ID
+				dashSVC.DeleteDashboardF = func(_ context.Context, id influxdb.ID) error {
+					deletedDashIDs = append(deletedDashIDs, id)
+					return nil
+				}
+
+				labelSVC := newLabelSVC(false)
+				var deletedMappings []influxdb.LabelMapping
+				labelSVC.DeleteLabelMappingFn = func(_ context.Context, m *influxdb.LabelMapping) error {
+					deletedMappings = append(deletedMappings, *m)
+					return nil
+				}
+				var deletedLabelIDs []influxdb.ID
+				labelSVC.DeleteLabelFn = func(_ context.Context, id influxdb.ID) error {
+					deletedLabelIDs = append(deletedLabelIDs, id)
+					return nil
+				}
+
+				svc := newTestService(WithDashboardSVC(dashSVC), WithLabelSVC(labelSVC))
+
+				sum, err := svc.Remove(context.TODO(), orgID, pkg)
+				require.NoError(t, err)
+
+				assert.Equal(t, []influxdb.ID{3}, deletedDashIDs)
+				assert.Equal(t, []influxdb.ID{1}, deletedLabelIDs)
+				assert.Equal(t, []influxdb.LabelMapping{{
+					LabelID:      1,
+					ResourceID:   3,
+					ResourceType: influxdb.DashboardsResourceType,
+				}}, deletedMappings)
+
+				require.Len(t, sum.Dashboards, 1)
+				assert.Equal(t, "dash_1", sum.Dashboards[0].Name)
+				require.Len(t, sum.Labels, 1)
+				assert.Equal(t, "label_1", sum.Labels[0].Name)
+				require.Len(t, sum.LabelMappings, 1)
+				assert.Empty(t, sum.Skipped)
+			})
+		})
+
+		t.Run("skips resources that can not be matched with confidence", func(t *testing.T) {
+			testfileRunner(t, "testdata/dashboard_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+				dashSVC := mock.NewDashboardService()
+				dashSVC.FindDashboardsF = func(context.Context, influxdb.DashboardFilter, influxdb.FindOptions) ([]*influxdb.Dashboard, int, error) {
+					return []*influxdb.Dashboard{
+						{ID: 3, OrganizationID: orgID, Name: "dash_1"},
+						{ID: 4, OrganizationID: orgID, Name: "dash_1"},
+					}, 2, nil
+				}
+				dashSVC.DeleteDashboardF = func(context.Context, influxdb.ID) error {
+					return errors.New("should not get here")
+				}
+
+				labelSVC := newLabelSVC(true)
+				labelSVC.DeleteLabelFn = func(context.Context, influxdb.ID) error {
+					return errors.New("should not get here")
+				}
+				labelSVC.DeleteLabelMappingFn = func(context.Context, *influxdb.LabelMapping) error {
+					return errors.New("should not get here")
+				}
+
+				svc := newTestService(WithDashboardSVC(dashSVC), WithLabelSVC(labelSVC))
+
+				sum, err := svc.Remove(context.TODO(), orgID, pkg)
+				require.NoError(t, err)
+
+				assert.Empty(t, sum.Dashboards)
+				assert.Empty(t, sum.Labels)
+				require.Len(t, sum.Skipped, 2)
+				assert.Equal(t, KindDashboard, sum.Skipped[0].Kind)
+				assert.Equal(t, "dash_1", sum.Skipped[0].Name)
+				assert.Equal(t, KindLabel, sum.Skipped[1].Kind)
+				assert.Equal(t, "label_1", sum.Skipped[1].Name)
+			})
+		})
+
+		t.Run("skips a label that differs from the label of the pkg", func(t *testing.T) {
+			testfileRunner(t, "testdata/dashboard_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+				dashSVC := mock.NewDashboardService()
+				dashSVC.FindDashboardsF = func(context.Context, influxdb.DashboardFilter, influxdb.FindOptions) ([]*influxdb.Dashboard, int, error) {
+					return []*influxdb.Dashboard{{ID: 3, OrganizationID: orgID, Name: "dash_1"}}, 1, nil
+				}
+
+				labelSVC := newLabelSVC(false)
+				labelSVC.FindLabelsFn = func(context.Context, influxdb.LabelFilter) ([]*influxdb.Label, error) {
+					return []*influxdb.Label{{
+						ID:         1,
+						OrgID:      orgID,
+						Name:       "label_1",
+						Properties: map[string]string{"color": "#FFFFFF"},
+					}}, nil
+				}
+				labelSVC.DeleteLabelFn = func(context.Context, influxdb.ID) error {
+					return errors.New("should not get here")
+				}
+
+				svc := newTestService(WithDashboardSVC(dashSVC), WithLabelSVC(labelSVC))
+
+				sum, err := svc.Remove(context.TODO(), orgID, pkg)
+				require.NoError(t, err)
+
+				require.Len(t, sum.Dashboards, 1)
+				assert.Empty(t, sum.Labels)
+				require.Len(t, sum.Skipped, 1)
+				assert.Equal(t, SummarySkippedResource{
+					Kind:   KindLabel,
+					Name:   "label_1",
+					Reason: "label differs from the label of the pkg",
+				}, sum.Skipped[0])
+			})
+		})
+
+		t.Run("reads all the dashboards of the org a page at a time", func(t *testing.T) {
+			testfileRunner(t, "testdata/dashboard_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+				dashSVC := mock.NewDashboardService()
+				dashSVC.FindDashboardsF = func(_ context.Context, _ influxdb.DashboardFilter, opts influxdb.FindOptions) ([]*influxdb.Dashboard, int, error) {
+					if opts.Offset > 0 {
+						return []*influxdb.Dashboard{{ID: 3, OrganizationID: orgID, Name: "dash_1"}}, 1, nil
+					}
+					page := make([]*influxdb.Dashboard, 0, opts.Limit)
+					for i := 0; i < opts.Limit; i++ {
+						page = append(page, &influxdb.Dashboard{
+							ID:             influxdb.ID(100 + i),
+							OrganizationID: orgID,
+							Name:           fmt.Sprintf("dash_outside_%d", i),
+						})
+					}
+					return page, len(page), nil
+				}
+				var deletedDashIDs []influxdb.ID
+				dashSVC.DeleteDashboardF = func(_ context.Context, id influxdb.ID) error {
+					deletedDashIDs = append(deletedDashIDs, id)
+					return nil
+				}
+
+				svc := newTestService(WithDashboardSVC(dashSVC), WithLabelSVC(newLabelSVC(false)))
+
+				_, err := svc.Remove(context.TODO(), orgID, pkg)
+				require.NoError(t, err)
+
+				assert.Equal(t, []influxdb.ID{3}, deletedDashIDs)
+			})
+		})
+
+		t.Run("removes notification rules ahead of their endpoints", func(t *testing.T) {
+			testfileRunner(t, "testdata/notification_rule.yml", func(t *testing.T, pkg *Pkg) {
+				var deleted []string
+
+				endpointSVC := mock.NewNotificationEndpointService()
+				endpointSVC.FindNotificationEndpointsF = func(context.Context, influxdb.NotificationEndpointFilter, ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+					id, org := influxdb.ID(6), orgID
+					return []influxdb.NotificationEndpoint{
+						&endpoint.Slack{Base: endpoint.Base{ID: &id, OrgID: &org, Name: "endpoint_1"}},
+					}, 1, nil
+				}
+				endpointSVC.DeleteNotificationEndpointF = func(_ context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
+					deleted = append(deleted, "endpoint "+id.String())
+					return nil, orgID, nil
+				}
+
+				ruleSVC := mock.NewNotificationRuleStore()
+				ruleSVC.FindNotificationRulesF = func(context.Context, influxdb.NotificationRuleFilter, ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+					return []influxdb.NotificationRule{
+						&rule.Slack{Base: rule.Base{ID: 5, OrgID: orgID, Name: "rule_1", EndpointID: 6}},
+						&rule.Slack{Base: rule.Base{ID: 7, OrgID: orgID, Name: "rule_outside", EndpointID: 6}},
+					}, 2, nil
+				}
+				ruleSVC.DeleteNotificationRuleF = func(_ context.Context, id influxdb.ID) error {
+					deleted = append(deleted, "rule "+id.String())
+					return nil
+				}
+
+				svc := newTestService(
+					WithLabelSVC(newLabelSVC(false)),
+					WithNoticationEndpointSVC(endpointSVC),
+					WithNotificationRuleSVC(ruleSVC),
+				)
+
+				sum, err := svc.Remove(context.TODO(), orgID, pkg)
+				require.NoError(t, err)
+
+				assert.Equal(t, []string{
+					"rule " + influxdb.ID(5).String(),
+					"endpoint " + influxdb.ID(6).String(),
+				}, deleted)
+				require.Len(t, sum.NotificationRules, 1)
+				assert.Equal(t, "rule_1", sum.NotificationRules[0].Name)
+				assert.Equal(t, SafeID(6), sum.NotificationRules[0].EndpointID)
+				require.Len(t, sum.NotificationEndpoints, 1)
+			})
+		})
+
+		t.Run("removes checks", func(t *testing.T) {
+			testfileRunner(t, "testdata/check_threshold.yml", func(t *testing.T, pkg *Pkg) {
+				checkSVC := mock.NewCheckService()
+				checkSVC.FindChecksFn = func(context.Context, influxdb.CheckFilter, ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+					return []influxdb.Check{
+						&icheck.Threshold{Base: icheck.Base{ID: 5, OrgID: orgID, Name: "check_1"}},
+					}, 1, nil
+				}
+				var deletedCheckIDs []influxdb.ID
+				checkSVC.DeleteCheckFn = func(_ context.Context, id influxdb.ID) error {
+					deletedCheckIDs = append(deletedCheckIDs, id)
+					return nil
+				}
+
+				svc := newTestService(WithCheckSVC(checkSVC), WithLabelSVC(newLabelSVC(false)))
+
+				sum, err := svc.Remove(context.TODO(), orgID, pkg)
+				require.NoError(t, err)
+
+				assert.Equal(t, []influxdb.ID{5}, deletedCheckIDs)
+				require.Len(t, sum.Checks, 1)
+				assert.Equal(t, "check_1", sum.Checks[0].Check.GetName())
+			})
+		})
+	})
+}
+
+func TestNewApplier(t *testing.T) {
+	type fakeResource struct {
+		name      string
+		id        influxdb.ID
+		exists    bool
+		unchanged bool
+		// conflict is the create turning out to conflict with an existing
+		// resource, the resource is then updated.
+		conflict  bool
+		loadErr   error
+		createErr error
+		updateErr error
+	}
+
+	// newFakeApplier applies the resources, recording the calls made and the
+	// resources rolled back.
+	newFakeApplier := func(resource string, resources []fakeResource, calls *[]string, rolledBack *[]string, rollbackErr error) applier {
+		var mu sync.Mutex
+		record := func(call string) {
+			mu.Lock()
+			defer mu.Unlock()
+			*calls = append(*calls, call)
+		}
+		copies := make([]fakeResource, len(resources))
+
+		return newApplier(applierHooks{
+			kind:     KindBucket,
+			resource: resource,
+			entries:  len(resources),
+			prepare: func(i int, orgID influxdb.ID) {
+				copies[i] = resources[i]
+			},
+			name:        func(i int) string { return resources[i].name },
+			id:          func(i int) influxdb.ID { return resources[i].id },
+			load:        func(ctx context.Context, i int) error { return copies[i].loadErr },
+			shouldApply: func(i int) bool { return !copies[i].unchanged },
+			exists:      func(i int) bool { return copies[i].exists },
+			create: func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, bool, error) {
+				record("create " + copies[i].name)
+				if err := copies[i].createErr; err != nil {
+					return 0, false, err
+				}
+				return influxdb.ID(100 + i), copies[i].conflict, nil
+			},
+			update: func(ctx context.Context, i int, userID influxdb.ID) (influxdb.ID, error) {
+				record("update " + copies[i].name)
+				if err := copies[i].updateErr; err != nil {
+					return 0, err
+				}
+				return influxdb.ID(200 + i), nil
+			},
+			setID: func(i int, id influxdb.ID) {
+				resources[i].id = id
+			},
+			rollback: func(applied []int) error {
+				for _, i := range applied {
+					*rolledBack = append(*rolledBack, resources[i].name)
+				}
+				return rollbackErr
+			},
+		})
+	}
+
+	t.Run("applies each resource", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			resource      fakeResource
+			expCalls      []string
+			expResult     applyResult
+			expErr        string
+			expRolledBack []string
+		}{
+			{
+				name:          "creates a new resource",
+				resource:      fakeResource{name: "new"},
+				expCalls:      []string{"create new"},
+				expResult:     applyResult{name: "new", id: 100, status: ApplyStatusCreated},
+				expRolledBack: []string{"new"},
+			},
+			{
+				name:          "updates an existing resource",
+				resource:      fakeResource{name: "existing", id: 3, exists: true},
+				expCalls:      []string{"update existing"},
+				expResult:     applyResult{name: "existing", id: 200, status: ApplyStatusUpdated},
+				expRolledBack: []string{"existing"},
+			},
+			{
+				name:          "updates a resource the create conflicts with",
+				resource:      fakeResource{name: "conflict", conflict: true},
+				expCalls:      []string{"create conflict", "update conflict"},
+				expResult:     applyResult{name: "conflict", id: 200, status: ApplyStatusUpdated},
+				expRolledBack: []string{"conflict"},
+			},
+			{
+				name:      "leaves a resource unchanged when shouldApply is false",
+				resource:  fakeResource{name: "unchanged", id: 3, exists: true, unchanged: true},
+				expResult: applyResult{name: "unchanged", id: 3, status: ApplyStatusUnchanged},
+			},
+			{
+				name:     "fails a resource on a load error",
+				resource: fakeResource{name: "failed", id: 3, exists: true, loadErr: errors.New("load failed")},
+				expErr:   "load failed",
+			},
+			{
+				name:     "fails a resource on a create error",
+				resource: fakeResource{name: "failed", createErr: errors.New("create failed")},
+				expCalls: []string{"create failed"},
+				expErr:   "create failed",
+			},
+			{
+				name:     "fails a resource on an update error",
+				resource: fakeResource{name: "failed", id: 3, exists: true, updateErr: errors.New("update failed")},
+				expCalls: []string{"update failed"},
+				expErr:   "update failed",
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				var calls, rolledBack []string
+				app := newFakeApplier("fake", []fakeResource{tt.resource}, &calls, &rolledBack, nil)
+
+				res, errBody := app.creater.fn(context.TODO(), 0, 1, 2)
+				if tt.expErr != "" {
+					require.NotNil(t, errBody)
+					assert.Equal(t, tt.resource.name, errBody.name)
+					assert.Equal(t, tt.expErr, errBody.msg)
+				} else {
+					require.Nil(t, errBody)
+					assert.Equal(t, tt.expResult, res)
+				}
+				assert.Equal(t, tt.expCalls, calls)
+
+				require.NoError(t, app.rollbacker.fn())
+				assert.Equal(t, tt.expRolledBack, rolledBack)
+			}
+			t.Run(tt.name, fn)
+		}
+	})
+
+	t.Run("aggregates the rollback errors of the appliers", func(t *testing.T) {
+		var bucketCalls, labelCalls, rolledBackBuckets, rolledBackLabels []string
+		buckets := newFakeApplier("bucket", []fakeResource{
+			{name: "bkt_1"},
+			{name: "bkt_2", createErr: errors.New("create failed")},
+		}, &bucketCalls, &rolledBackBuckets, errors.New("unable to delete bucket"))
+		labels := newFakeApplier("label", []fakeResource{
+			{name: "label_1"},
+		}, &labelCalls, &rolledBackLabels, errors.New("unable to delete label"))
+
+		coordinator := &rollbackCoordinator{sem: make(chan struct{}, 1)}
+		err := coordinator.runTilEnd(context.TODO(), 1, 2, buckets, labels)
+		require.Error(t, err)
+
+		coordinator.rollback(zaptest.NewLogger(t), &err)
+
+		rbErr, ok := err.(*RollbackError)
+		require.True(t, ok, "expected a *RollbackError, got %T", err)
+		assert.Contains(t, rbErr.Err.Error(), "create failed")
+
+		// the failed bucket was not created, it is not rolled back
+		assert.Equal(t, []string{"bkt_1"}, rolledBackBuckets)
+		assert.Equal(t, []string{"label_1"}, rolledBackLabels)
+
+		failures := rbErr.Report.Failures()
+		require.Len(t, failures, 2)
+		assert.Equal(t, RollbackResult{Resource: "bucket", Msg: "unable to delete bucket"}, failures[0])
+		assert.Equal(t, RollbackResult{Resource: "label", Msg: "unable to delete label"}, failures[1])
+		assert.Contains(t, err.Error(), "unable to delete bucket")
+		assert.Contains(t, err.Error(), "unable to delete label")
+	})
+}
+
+type fakeUsageReporter struct {
+	events []ApplyEvent
+}
+
+func (f *fakeUsageReporter) ReportApply(ctx context.Context, event ApplyEvent) {
+	f.events = append(f.events, event)
+}
+
+type fakeOrgOpLog struct {
+	mu      sync.Mutex
+	entries map[influxdb.ID][]string
+}
+
+func (f *fakeOrgOpLog) AddOrganizationOperationLogEntry(ctx context.Context, id influxdb.ID, description string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries[id] = append(f.entries[id], description)
+	return nil
+}
+
+// newKVService returns a kv service of an in memory store, for the tests of
+// the state a rollback leaves the platform in.
+func newKVService(t *testing.T) *kv.Service {
+	t.Helper()
+
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func parsePkgFile(t *testing.T, path string) *Pkg {
+	t.Helper()
+
+	pkg, err := Parse(EncodingYAML, FromFile(path))
+	require.NoError(t, err)
+	return pkg
+}
+
+// concurrentBucketSVC looks up buckets by name without serializing the
+// lookups, as the mock bucket service does.
+type concurrentBucketSVC struct {
+	influxdb.BucketService
+	findBucketByNameFn func(context.Context, influxdb.ID, string) (*influxdb.Bucket, error)
+}
+
+func (s *concurrentBucketSVC) FindBucketByName(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+	return s.findBucketByNameFn(ctx, orgID, name)
+}
+
+// failingLabelMappingService fails to create the label mappings of a pkg, the
+// apply of the pkg is rolled back when its resources are applied.
+type failingLabelMappingService struct {
+	influxdb.LabelService
+}
+
+func (s *failingLabelMappingService) CreateLabelMapping(context.Context, *influxdb.LabelMapping) error {
+	return errors.New("limit hit")
 }
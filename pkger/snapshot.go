@@ -0,0 +1,695 @@
+package pkger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/influxdb"
+)
+
+// DryRunWithSnapshot dry runs the pkg against a snapshot of the existing resources
+// of the org, instead of the resources of the platform. This allows for planning
+// the application of a pkg without access to the platform, i.e. against the summary
+// of a previous apply kept alongside the pkg. The existing resources of the snapshot
+// are identified by name, the IDs of the snapshot are reported in the diff.
+//
+// A diff of a snapshot is marked as such and does not verify the pkg, the pkg is
+// dry run against the platform when it is applied. The option cannot be provided
+// to Apply.
+func DryRunWithSnapshot(snapshot Summary) ApplyOptFn {
+	return func(opt *ApplyOpt) error {
+		opt.Snapshot = &snapshot
+		return nil
+	}
+}
+
+// withSnapshot returns a copy of the service whose lookups of existing resources
+// are resolved from the snapshot.
+func (s *Service) withSnapshot(snapshot Summary) *Service {
+	snap := newPkgSnapshot(snapshot)
+
+	var unsupported snapshotUnsupportedSVC
+
+	cp := *s
+	cp.bucketSVC = &snapshotBucketSVC{BucketService: unsupported, snap: snap}
+	cp.checkSVC = &snapshotCheckSVC{CheckService: unsupported, snap: snap}
+	cp.dashSVC = unsupported
+	cp.endpointSVC = &snapshotEndpointSVC{NotificationEndpointService: unsupported, snap: snap}
+	cp.labelSVC = &snapshotLabelSVC{LabelService: unsupported, snap: snap}
+	cp.ruleSVC = &snapshotRuleSVC{NotificationRuleStore: unsupported, snap: snap}
+	cp.scraperSVC = &snapshotScraperSVC{ScraperTargetStoreService: unsupported, snap: snap}
+	cp.secretSVC = &snapshotSecretSVC{SecretService: unsupported, snap: snap}
+	cp.taskSVC = unsupported
+	cp.teleSVC = unsupported
+	cp.varSVC = &snapshotVariableSVC{VariableService: unsupported, snap: snap}
+	return &cp
+}
+
+type pkgSnapshot struct {
+	buckets   []influxdb.Bucket
+	checks    []influxdb.Check
+	endpoints []influxdb.NotificationEndpoint
+	labels    []influxdb.Label
+	mappings  []SummaryLabelMapping
+	rules     []influxdb.NotificationRule
+	targets   []influxdb.ScraperTarget
+	variables []influxdb.Variable
+}
+
+func newPkgSnapshot(sum Summary) *pkgSnapshot {
+	snap := &pkgSnapshot{
+		mappings: sum.LabelMappings,
+	}
+	for _, b := range sum.Buckets {
+		snap.buckets = append(snap.buckets, influxdb.Bucket{
+			ID:              influxdb.ID(b.ID),
+			OrgID:           influxdb.ID(b.OrgID),
+			Name:            b.Name,
+			Description:     b.Description,
+			RetentionPeriod: b.RetentionPeriod,
+		})
+	}
+	for _, c := range sum.Checks {
+		if c.Check != nil {
+			snap.checks = append(snap.checks, c.Check)
+		}
+	}
+	for _, e := range sum.NotificationEndpoints {
+		if e.NotificationEndpoint != nil {
+			snap.endpoints = append(snap.endpoints, e.NotificationEndpoint)
+		}
+	}
+	for _, r := range sum.NotificationRules {
+		if iRule := snapshotRule(r); iRule != nil {
+			snap.rules = append(snap.rules, iRule)
+		}
+	}
+	for _, l := range sum.Labels {
+		snap.labels = append(snap.labels, snapshotLabel(l))
+	}
+	for _, t := range sum.ScraperTargets {
+		snap.targets = append(snap.targets, influxdb.ScraperTarget{
+			ID:       influxdb.ID(t.ID),
+			OrgID:    influxdb.ID(t.OrgID),
+			Name:     t.Name,
+			Type:     t.Type,
+			URL:      t.URL,
+			BucketID: influxdb.ID(t.BucketID),
+		})
+	}
+	for _, v := range sum.Variables {
+		snap.variables = append(snap.variables, influxdb.Variable{
+			ID:             influxdb.ID(v.ID),
+			OrganizationID: influxdb.ID(v.OrgID),
+			Name:           v.Name,
+			Description:    v.Description,
+			Arguments:      v.Arguments,
+		})
+	}
+	return snap
+}
+
+func snapshotLabel(l SummaryLabel) influxdb.Label {
+	label := influxdb.Label{
+		ID:         influxdb.ID(l.ID),
+		OrgID:      influxdb.ID(l.OrgID),
+		Name:       l.Name,
+		Properties: make(map[string]string),
+	}
+	if l.Properties.Color != "" {
+		label.Properties["color"] = l.Properties.Color
+	}
+	if l.Properties.Description != "" {
+		label.Properties["description"] = l.Properties.Description
+	}
+	return label
+}
+
+// snapshotRule converts the summary of a rule back to the rule of the type of
+// its endpoint. A rule whose endpoint type is unknown has no rule.
+func snapshotRule(r SummaryNotificationRule) influxdb.NotificationRule {
+	pkgRule := &notificationRule{
+		id:           influxdb.ID(r.ID),
+		OrgID:        influxdb.ID(r.OrgID),
+		name:         r.Name,
+		description:  r.Description,
+		channel:      r.Channel,
+		every:        r.Every,
+		msgTemplate:  r.MessageTemplate,
+		offset:       r.Offset,
+		status:       string(r.Status),
+		endpointName: r.EndpointName,
+		endpointID:   influxdb.ID(r.EndpointID),
+		endpointType: r.EndpointType,
+	}
+	for _, sr := range r.StatusRules {
+		pkgRule.statusRules = append(pkgRule.statusRules, ruleStatusRule{
+			curLvl:  sr.CurrentLevel,
+			prevLvl: sr.PreviousLevel,
+		})
+	}
+	for _, tr := range r.TagRules {
+		pkgRule.tagRules = append(pkgRule.tagRules, ruleTagRule{
+			key:   tr.Key,
+			value: tr.Value,
+			op:    tr.Operator,
+		})
+	}
+	return pkgRule.toInfluxRule()
+}
+
+func snapshotNotFound(kind Kind, ident string) error {
+	return &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  fmt.Sprintf("%s %s not found in snapshot", kind, ident),
+	}
+}
+
+// The snapshot services answer the lookups a dry run makes of the existing
+// resources. The methods a dry run does not call are answered by the
+// snapshotUnsupportedSVC they embed, a dry run never mutates the platform.
+
+type snapshotBucketSVC struct {
+	influxdb.BucketService
+	snap *pkgSnapshot
+}
+
+func (s *snapshotBucketSVC) FindBucketByID(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+	for i := range s.snap.buckets {
+		if b := s.snap.buckets[i]; b.ID == id {
+			return &b, nil
+		}
+	}
+	return nil, snapshotNotFound(KindBucket, "id="+id.String())
+}
+
+func (s *snapshotBucketSVC) FindBucketByName(_ context.Context, _ influxdb.ID, name string) (*influxdb.Bucket, error) {
+	for i := range s.snap.buckets {
+		if b := s.snap.buckets[i]; b.Name == name {
+			return &b, nil
+		}
+	}
+	return nil, snapshotNotFound(KindBucket, "name="+name)
+}
+
+type snapshotCheckSVC struct {
+	influxdb.CheckService
+	snap *pkgSnapshot
+}
+
+func (s *snapshotCheckSVC) FindChecks(_ context.Context, _ influxdb.CheckFilter, _ ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+	return s.snap.checks, len(s.snap.checks), nil
+}
+
+type snapshotEndpointSVC struct {
+	influxdb.NotificationEndpointService
+	snap *pkgSnapshot
+}
+
+func (s *snapshotEndpointSVC) FindNotificationEndpoints(_ context.Context, _ influxdb.NotificationEndpointFilter, _ ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+	return s.snap.endpoints, len(s.snap.endpoints), nil
+}
+
+type snapshotLabelSVC struct {
+	influxdb.LabelService
+	snap *pkgSnapshot
+}
+
+func (s *snapshotLabelSVC) FindLabels(_ context.Context, filter influxdb.LabelFilter, _ ...influxdb.FindOptions) ([]*influxdb.Label, error) {
+	var labels []*influxdb.Label
+	for i := range s.snap.labels {
+		if l := s.snap.labels[i]; filter.Name == "" || l.Name == filter.Name {
+			labels = append(labels, &l)
+		}
+	}
+	return labels, nil
+}
+
+// FindResourceLabels finds the labels mapped to the resource, ordered by ID.
+// The mappings of a snapshot whose resources have no IDs cannot be resolved,
+// its resources have no labels.
+func (s *snapshotLabelSVC) FindResourceLabels(_ context.Context, filter influxdb.LabelMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.Label, error) {
+	if filter.ResourceID == 0 {
+		return nil, nil
+	}
+
+	var opts influxdb.FindOptions
+	if len(opt) > 0 {
+		opts = opt[0]
+	}
+
+	mappings := make([]SummaryLabelMapping, 0, len(s.snap.mappings))
+	for _, m := range s.snap.mappings {
+		if influxdb.ID(m.ResourceID) != filter.ResourceID || m.ResourceType != filter.ResourceType {
+			continue
+		}
+		if opts.After != nil && influxdb.ID(m.LabelID) <= *opts.After {
+			continue
+		}
+		mappings = append(mappings, m)
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].LabelID < mappings[j].LabelID
+	})
+	if opts.Offset >= len(mappings) {
+		return nil, nil
+	}
+	mappings = mappings[opts.Offset:]
+	if opts.Limit > 0 && len(mappings) > opts.Limit {
+		mappings = mappings[:opts.Limit]
+	}
+
+	var labels []*influxdb.Label
+	for _, m := range mappings {
+		label := snapshotLabel(SummaryLabel{ID: m.LabelID, Name: m.LabelName})
+		for _, l := range s.snap.labels {
+			if l.ID == label.ID {
+				label = l
+				break
+			}
+		}
+		labels = append(labels, &label)
+	}
+	return labels, nil
+}
+
+type snapshotRuleSVC struct {
+	influxdb.NotificationRuleStore
+	snap *pkgSnapshot
+}
+
+func (s *snapshotRuleSVC) FindNotificationRules(_ context.Context, _ influxdb.NotificationRuleFilter, _ ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+	return s.snap.rules, len(s.snap.rules), nil
+}
+
+type snapshotScraperSVC struct {
+	influxdb.ScraperTargetStoreService
+	snap *pkgSnapshot
+}
+
+func (s *snapshotScraperSVC) ListTargets(_ context.Context, _ influxdb.ScraperTargetFilter) ([]influxdb.ScraperTarget, error) {
+	return s.snap.targets, nil
+}
+
+type snapshotSecretSVC struct {
+	influxdb.SecretService
+	snap *pkgSnapshot
+}
+
+// GetSecretKeys returns the keys of the secrets referenced by the notification
+// endpoints of the snapshot, the values of secrets are never part of a snapshot.
+func (s *snapshotSecretSVC) GetSecretKeys(_ context.Context, _ influxdb.ID) ([]string, error) {
+	var keys []string
+	for _, e := range s.snap.endpoints {
+		for _, f := range e.SecretFields() {
+			keys = append(keys, f.Key)
+		}
+	}
+	return keys, nil
+}
+
+type snapshotVariableSVC struct {
+	influxdb.VariableService
+	snap *pkgSnapshot
+}
+
+func (s *snapshotVariableSVC) FindVariables(_ context.Context, _ influxdb.VariableFilter, _ ...influxdb.FindOptions) ([]*influxdb.Variable, error) {
+	vars := make([]*influxdb.Variable, 0, len(s.snap.variables))
+	for i := range s.snap.variables {
+		v := s.snap.variables[i]
+		vars = append(vars, &v)
+	}
+	return vars, nil
+}
+
+var errSnapshotUnsupported = &influxdb.Error{
+	Code: influxdb.EMethodNotAllowed,
+	Msg:  "not supported when dry running against a snapshot",
+}
+
+// snapshotUnsupportedSVC answers every call a dry run against a snapshot is not
+// expected to make with an error, instead of reaching the platform.
+type snapshotUnsupportedSVC struct{}
+
+var (
+	_ influxdb.BucketService               = snapshotUnsupportedSVC{}
+	_ influxdb.CheckService                = snapshotUnsupportedSVC{}
+	_ influxdb.DashboardService            = snapshotUnsupportedSVC{}
+	_ influxdb.LabelService                = snapshotUnsupportedSVC{}
+	_ influxdb.NotificationEndpointService = snapshotUnsupportedSVC{}
+	_ influxdb.NotificationRuleStore       = snapshotUnsupportedSVC{}
+	_ influxdb.ScraperTargetStoreService   = snapshotUnsupportedSVC{}
+	_ influxdb.SecretService               = snapshotUnsupportedSVC{}
+	_ influxdb.TaskService                 = snapshotUnsupportedSVC{}
+	_ influxdb.TelegrafConfigStore         = snapshotUnsupportedSVC{}
+	_ influxdb.VariableService             = snapshotUnsupportedSVC{}
+)
+
+func (snapshotUnsupportedSVC) FindUserResourceMappings(context.Context, influxdb.UserResourceMappingFilter, ...influxdb.FindOptions) ([]*influxdb.UserResourceMapping, int, error) {
+	return nil, 0, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CreateUserResourceMapping(context.Context, *influxdb.UserResourceMapping) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteUserResourceMapping(context.Context, influxdb.ID, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindOrganizationByID(context.Context, influxdb.ID) (*influxdb.Organization, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindOrganization(context.Context, influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindOrganizations(context.Context, influxdb.OrganizationFilter, ...influxdb.FindOptions) ([]*influxdb.Organization, int, error) {
+	return nil, 0, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CreateOrganization(context.Context, *influxdb.Organization) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateOrganization(context.Context, influxdb.ID, influxdb.OrganizationUpdate) (*influxdb.Organization, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteOrganization(context.Context, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindBucketByID(context.Context, influxdb.ID) (*influxdb.Bucket, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindBucket(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindBuckets(context.Context, influxdb.BucketFilter, ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+	return nil, 0, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CreateBucket(context.Context, *influxdb.Bucket) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateBucket(context.Context, influxdb.ID, influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteBucket(context.Context, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindBucketByName(context.Context, influxdb.ID, string) (*influxdb.Bucket, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindCheckByID(context.Context, influxdb.ID) (influxdb.Check, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindCheck(context.Context, influxdb.CheckFilter) (influxdb.Check, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindChecks(context.Context, influxdb.CheckFilter, ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+	return nil, 0, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CreateCheck(context.Context, influxdb.CheckCreate, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateCheck(context.Context, influxdb.ID, influxdb.CheckCreate) (influxdb.Check, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) PatchCheck(context.Context, influxdb.ID, influxdb.CheckUpdate) (influxdb.Check, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteCheck(context.Context, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindDashboardByID(context.Context, influxdb.ID) (*influxdb.Dashboard, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindDashboards(context.Context, influxdb.DashboardFilter, influxdb.FindOptions) ([]*influxdb.Dashboard, int, error) {
+	return nil, 0, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CreateDashboard(context.Context, *influxdb.Dashboard) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateDashboard(context.Context, influxdb.ID, influxdb.DashboardUpdate) (*influxdb.Dashboard, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) AddDashboardCell(context.Context, influxdb.ID, *influxdb.Cell, influxdb.AddDashboardCellOptions) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) RemoveDashboardCell(context.Context, influxdb.ID, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateDashboardCell(context.Context, influxdb.ID, influxdb.ID, influxdb.CellUpdate) (*influxdb.Cell, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) GetDashboardCellView(context.Context, influxdb.ID, influxdb.ID) (*influxdb.View, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateDashboardCellView(context.Context, influxdb.ID, influxdb.ID, influxdb.ViewUpdate) (*influxdb.View, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteDashboard(context.Context, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) ReplaceDashboardCells(context.Context, influxdb.ID, []*influxdb.Cell) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindLabelByID(context.Context, influxdb.ID) (*influxdb.Label, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindLabels(context.Context, influxdb.LabelFilter, ...influxdb.FindOptions) ([]*influxdb.Label, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindResourceLabels(context.Context, influxdb.LabelMappingFilter, ...influxdb.FindOptions) ([]*influxdb.Label, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CreateLabel(context.Context, *influxdb.Label) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CreateLabelMapping(context.Context, *influxdb.LabelMapping) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateLabel(context.Context, influxdb.ID, influxdb.LabelUpdate) (*influxdb.Label, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteLabel(context.Context, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteLabelMapping(context.Context, *influxdb.LabelMapping) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindNotificationEndpointByID(context.Context, influxdb.ID) (influxdb.NotificationEndpoint, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindNotificationEndpoints(context.Context, influxdb.NotificationEndpointFilter, ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+	return nil, 0, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CreateNotificationEndpoint(context.Context, influxdb.NotificationEndpoint, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateNotificationEndpoint(context.Context, influxdb.ID, influxdb.NotificationEndpoint, influxdb.ID) (influxdb.NotificationEndpoint, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) PatchNotificationEndpoint(context.Context, influxdb.ID, influxdb.NotificationEndpointUpdate) (influxdb.NotificationEndpoint, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteNotificationEndpoint(context.Context, influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
+	return nil, 0, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindNotificationRuleByID(context.Context, influxdb.ID) (influxdb.NotificationRule, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindNotificationRules(context.Context, influxdb.NotificationRuleFilter, ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+	return nil, 0, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CreateNotificationRule(context.Context, influxdb.NotificationRuleCreate, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateNotificationRule(context.Context, influxdb.ID, influxdb.NotificationRuleCreate, influxdb.ID) (influxdb.NotificationRule, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) PatchNotificationRule(context.Context, influxdb.ID, influxdb.NotificationRuleUpdate) (influxdb.NotificationRule, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteNotificationRule(context.Context, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) ListTargets(context.Context, influxdb.ScraperTargetFilter) ([]influxdb.ScraperTarget, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) AddTarget(context.Context, *influxdb.ScraperTarget, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) GetTargetByID(context.Context, influxdb.ID) (*influxdb.ScraperTarget, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) RemoveTarget(context.Context, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateTarget(context.Context, *influxdb.ScraperTarget, influxdb.ID) (*influxdb.ScraperTarget, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) LoadSecret(context.Context, influxdb.ID, string) (string, error) {
+	return "", errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) GetSecretKeys(context.Context, influxdb.ID) ([]string, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) PutSecret(context.Context, influxdb.ID, string, string) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) PutSecrets(context.Context, influxdb.ID, map[string]string) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) PatchSecrets(context.Context, influxdb.ID, map[string]string) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteSecret(context.Context, influxdb.ID, ...string) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindTaskByID(context.Context, influxdb.ID) (*influxdb.Task, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindTasks(context.Context, influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+	return nil, 0, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CreateTask(context.Context, influxdb.TaskCreate) (*influxdb.Task, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateTask(context.Context, influxdb.ID, influxdb.TaskUpdate) (*influxdb.Task, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteTask(context.Context, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindLogs(context.Context, influxdb.LogFilter) ([]*influxdb.Log, int, error) {
+	return nil, 0, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindRuns(context.Context, influxdb.RunFilter) ([]*influxdb.Run, int, error) {
+	return nil, 0, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindRunByID(context.Context, influxdb.ID, influxdb.ID) (*influxdb.Run, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CancelRun(context.Context, influxdb.ID, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) RetryRun(context.Context, influxdb.ID, influxdb.ID) (*influxdb.Run, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) ForceRun(context.Context, influxdb.ID, int64) (*influxdb.Run, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindTelegrafConfigByID(context.Context, influxdb.ID) (*influxdb.TelegrafConfig, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindTelegrafConfigs(context.Context, influxdb.TelegrafConfigFilter, ...influxdb.FindOptions) ([]*influxdb.TelegrafConfig, int, error) {
+	return nil, 0, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CreateTelegrafConfig(context.Context, *influxdb.TelegrafConfig, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateTelegrafConfig(context.Context, influxdb.ID, *influxdb.TelegrafConfig, influxdb.ID) (*influxdb.TelegrafConfig, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteTelegrafConfig(context.Context, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindVariableByID(context.Context, influxdb.ID) (*influxdb.Variable, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) FindVariables(context.Context, influxdb.VariableFilter, ...influxdb.FindOptions) ([]*influxdb.Variable, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) CreateVariable(context.Context, *influxdb.Variable) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) UpdateVariable(context.Context, influxdb.ID, *influxdb.VariableUpdate) (*influxdb.Variable, error) {
+	return nil, errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) ReplaceVariable(context.Context, *influxdb.Variable) error {
+	return errSnapshotUnsupported
+}
+
+func (snapshotUnsupportedSVC) DeleteVariable(context.Context, influxdb.ID) error {
+	return errSnapshotUnsupported
+}
@@ -0,0 +1,317 @@
+package pkger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+// streamMetadata is the metadata of the pkgs the batches of a stream are
+// applied as, a stream of resources has no metadata of its own.
+var streamMetadata = Metadata{Name: "stream", Version: "1"}
+
+// WithApplyStreamBatchSize sets the number of resources ApplyStream applies at
+// once. A larger batch needs more memory, a smaller one more requests.
+func WithApplyStreamBatchSize(n int) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.applyStreamBatchSize = n
+	}
+}
+
+// ApplyStream applies the resources of a pkg as they arrive on the channel,
+// without holding the entire pkg in memory, i.e. a machine generated pkg of
+// thousands of resources. The resources are applied once the channel is closed
+// or once enough of them have arrived, each batch is validated and applied as
+// a pkg of its own with the options provided.
+//
+// The ordering guarantees of Apply hold across batches. The labels of the
+// stream are held for its duration and applied no later than the first batch
+// associating a resource with them, a resource associated with a label that has
+// not arrived yet is buffered until it does. Checks, notification rules and
+// scraper targets are buffered until the channel is closed, the buckets and
+// endpoints they reference may arrive later. They are applied in batches of
+// their own once every other resource of the stream is applied, their
+// references to the resources of earlier batches are resolved from the org.
+//
+// A failure rolls back the batch it occurs in along with every batch applied
+// before it. The summary returned is that of all batches.
+func (s *Service) ApplyStream(ctx context.Context, orgID, userID influxdb.ID, resources <-chan Resource, opts ...ApplyOptFn) (_ Summary, e error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	opt, err := newApplyOpt(opts...)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	// the rollbacks of every batch applied are held until the stream is
+	// applied in its entirety.
+	coordinator := s.newRollbackCoordinator(opt)
+	defer coordinator.rollback(s.log, &e)
+
+	stream := newResourceStream(s.applyStreamBatchSize)
+	var sum Summary
+	applyBatch := func(batch []Resource) error {
+		pkg, err := stream.pkg(batch)
+		if err != nil {
+			return err
+		}
+
+		batchCoordinator := s.newRollbackCoordinator(opt)
+		batchSum, err := s.applyWith(ctx, batchCoordinator, orgID, userID, pkg, opt)
+		coordinator.adopt(batchCoordinator)
+		if err != nil {
+			return err
+		}
+		sum.merge(batchSum)
+		return nil
+	}
+
+	for done := false; !done; {
+		select {
+		case <-ctx.Done():
+			return Summary{}, ctx.Err()
+		case r, ok := <-resources:
+			if !ok {
+				done = true
+				break
+			}
+			batch, err := stream.add(r)
+			if err != nil {
+				return Summary{}, err
+			}
+			if batch != nil {
+				if err := applyBatch(batch); err != nil {
+					return Summary{}, err
+				}
+			}
+		}
+	}
+
+	for _, batch := range stream.rest() {
+		if err := ctx.Err(); err != nil {
+			return Summary{}, err
+		}
+		if err := applyBatch(batch); err != nil {
+			return Summary{}, err
+		}
+	}
+
+	sortResourceStatuses(sum.Statuses)
+	sum.StatusCounts = countResourceStatuses(sum.Statuses)
+
+	if s.usageReporter != nil {
+		s.usageReporter.ReportApply(ctx, newApplyEvent(streamMetadata, sum))
+	}
+	return sum, nil
+}
+
+// resourceStream buffers the resources of a stream until they can be applied
+// in dependency order.
+type resourceStream struct {
+	batchSize int
+
+	labels        map[string]Resource
+	appliedLabels map[string]bool
+
+	// pending are the resources waiting for a batch to fill or for the labels
+	// they are associated with, deferred the resources referencing others,
+	// waiting for the end of the stream.
+	pending  []Resource
+	deferred []Resource
+
+	// nextCheck is the number of pending resources at which the pending
+	// resources are checked for a full batch.
+	nextCheck int
+	batches   int
+}
+
+func newResourceStream(batchSize int) *resourceStream {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	return &resourceStream{
+		batchSize:     batchSize,
+		labels:        make(map[string]Resource),
+		appliedLabels: make(map[string]bool),
+		nextCheck:     batchSize,
+	}
+}
+
+// add buffers the resource and returns a batch once enough resources are ready
+// to be applied.
+func (s *resourceStream) add(r Resource) ([]Resource, error) {
+	k, _ := r.kind()
+	switch {
+	case k.is(KindLabel):
+		if _, ok := s.labels[r.Name()]; ok {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("label %q is provided more than once", r.Name()),
+			}
+		}
+		s.labels[r.Name()] = r
+		return nil, nil
+	case k.is(KindCheckDeadman, KindCheckThreshold, KindNotificationRule, KindScraperTarget):
+		s.deferred = append(s.deferred, r)
+		return nil, nil
+	default:
+		s.pending = append(s.pending, r)
+	}
+
+	if len(s.pending) < s.nextCheck {
+		return nil, nil
+	}
+
+	var ready, waiting []Resource
+	for _, r := range s.pending {
+		if s.hasLabels(r) {
+			ready = append(ready, r)
+		} else {
+			waiting = append(waiting, r)
+		}
+	}
+	if len(ready) < s.batchSize {
+		// too many resources are waiting on labels, check again once
+		// another batch worth of resources has arrived
+		s.nextCheck = len(s.pending) + s.batchSize
+		return nil, nil
+	}
+
+	s.pending = waiting
+	s.nextCheck = len(waiting) + s.batchSize
+	return ready, nil
+}
+
+// rest returns the batches of the resources remaining once the stream has
+// ended. The resources referencing others are in the batches after the rest of
+// the resources, so the resources they reference are applied ahead of them.
+func (s *resourceStream) rest() [][]Resource {
+	var batches [][]Resource
+	if len(s.pending) > 0 || s.hasUnappliedLabels() || !s.applied() && len(s.deferred) == 0 {
+		batches = append(batches, s.pending)
+	}
+	for len(s.deferred) > 0 {
+		n := s.batchSize
+		if n > len(s.deferred) {
+			n = len(s.deferred)
+		}
+		batches = append(batches, s.deferred[:n])
+		s.deferred = s.deferred[n:]
+	}
+	s.pending = nil
+	return batches
+}
+
+// hasUnappliedLabels returns true if a label of the stream was not applied
+// with a batch.
+func (s *resourceStream) hasUnappliedLabels() bool {
+	return len(s.appliedLabels) < len(s.labels)
+}
+
+// applied returns true if a batch of the stream was applied.
+func (s *resourceStream) applied() bool {
+	return s.batches > 0
+}
+
+// hasLabels returns true if every label the resource is associated with has
+// arrived.
+func (s *resourceStream) hasLabels(r Resource) bool {
+	for _, name := range associatedLabels(r) {
+		if _, ok := s.labels[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// pkg returns the pkg of the batch. The pkg includes the labels the batch is
+// associated with and the labels not applied yet, so that labels are applied
+// ahead of the resources mapped to them.
+func (s *resourceStream) pkg(batch []Resource) (*Pkg, error) {
+	resources := make([]Resource, 0, len(batch))
+	included := make(map[string]bool)
+	includeLabel := func(name string) {
+		if l, ok := s.labels[name]; ok && !included[name] {
+			included[name] = true
+			resources = append(resources, l)
+		}
+	}
+
+	for name := range s.labels {
+		if !s.appliedLabels[name] {
+			includeLabel(name)
+		}
+	}
+	for _, r := range batch {
+		for _, name := range associatedLabels(r) {
+			includeLabel(name)
+		}
+	}
+	resources = append(resources, batch...)
+
+	pkg := &Pkg{
+		APIVersion: APIVersion,
+		Kind:       KindPackage,
+		Metadata:   streamMetadata,
+	}
+	pkg.Spec.Resources = resources
+	if err := pkg.Validate(); err != nil {
+		return nil, err
+	}
+
+	for name := range included {
+		s.appliedLabels[name] = true
+	}
+	s.batches++
+	return pkg, nil
+}
+
+func associatedLabels(r Resource) []string {
+	var names []string
+	for _, nr := range r.slcResource(fieldAssociations) {
+		if k, err := nr.kind(); err == nil && k.is(KindLabel) {
+			names = append(names, nr.Name())
+		}
+	}
+	return names
+}
+
+// merge adds the summary of a batch to the summary of a stream. The labels of
+// a stream are part of every batch associated with them, they are summarized
+// and reported once.
+func (s *Summary) merge(batch Summary) {
+	s.Buckets = append(s.Buckets, batch.Buckets...)
+	s.Checks = append(s.Checks, batch.Checks...)
+	s.Dashboards = append(s.Dashboards, batch.Dashboards...)
+	s.NotificationEndpoints = append(s.NotificationEndpoints, batch.NotificationEndpoints...)
+	s.NotificationRules = append(s.NotificationRules, batch.NotificationRules...)
+	s.LabelMappings = append(s.LabelMappings, batch.LabelMappings...)
+	s.ScraperTargets = append(s.ScraperTargets, batch.ScraperTargets...)
+	s.Tasks = append(s.Tasks, batch.Tasks...)
+	s.TelegrafConfigs = append(s.TelegrafConfigs, batch.TelegrafConfigs...)
+	s.Variables = append(s.Variables, batch.Variables...)
+	s.Skipped = append(s.Skipped, batch.Skipped...)
+	s.Errors = append(s.Errors, batch.Errors...)
+
+	labels := make(map[string]bool, len(s.Labels))
+	for _, l := range s.Labels {
+		labels[l.Name] = true
+	}
+	for _, l := range batch.Labels {
+		if !labels[l.Name] {
+			s.Labels = append(s.Labels, l)
+		}
+	}
+
+	// a label is created or updated by the first batch it is part of, the
+	// batches after it find the label unchanged
+	for _, st := range batch.Statuses {
+		if st.Kind.is(KindLabel) && labels[st.Name] {
+			continue
+		}
+		s.Statuses = append(s.Statuses, st)
+	}
+}
@@ -0,0 +1,56 @@
+package pkger
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// UsageReporter records the anonymous usage of the pkgs applied, i.e. for the
+// telemetry that is reported when reporting is enabled.
+type UsageReporter interface {
+	ReportApply(ctx context.Context, event ApplyEvent)
+}
+
+// ApplyEvent is the anonymous record of a pkg applied. The pkg is identified
+// only by a hash of its metadata name and version. The event never carries the
+// org, the names or the contents of the resources applied.
+type ApplyEvent struct {
+	PkgHash string
+	// Resources is the number of resources applied by kind. The deadman and
+	// threshold checks are counted together as KindCheck.
+	Resources map[Kind]int
+}
+
+// WithUsageReporter sets the reporter the pkgs applied are reported to. No
+// usage is reported without one.
+func WithUsageReporter(r UsageReporter) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.usageReporter = r
+	}
+}
+
+func newApplyEvent(meta Metadata, sum Summary) ApplyEvent {
+	return ApplyEvent{
+		PkgHash: pkgHash(meta),
+		Resources: map[Kind]int{
+			KindBucket:               len(sum.Buckets),
+			KindCheck:                len(sum.Checks),
+			KindDashboard:            len(sum.Dashboards),
+			KindLabel:                len(sum.Labels),
+			KindNotificationEndpoint: len(sum.NotificationEndpoints),
+			KindNotificationRule:     len(sum.NotificationRules),
+			KindScraperTarget:        len(sum.ScraperTargets),
+			KindTask:                 len(sum.Tasks),
+			KindTelegraf:             len(sum.TelegrafConfigs),
+			KindVariable:             len(sum.Variables),
+		},
+	}
+}
+
+// pkgHash identifies the pkg without revealing its name, the name of a pkg
+// written for a single org may well be sensitive.
+func pkgHash(meta Metadata) string {
+	h := sha256.Sum256([]byte(meta.Name + "\x00" + meta.Version))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,268 @@
+package pkger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+// verification reads back a resource applied from a pkg, returning the fields
+// of the resource that do not match the pkg.
+type verification struct {
+	resource string
+	name     string
+	fn       func(ctx context.Context) (mismatches, error)
+}
+
+type mismatches []string
+
+func (m *mismatches) check(field string, expected, actual interface{}) {
+	if expected != actual {
+		*m = append(*m, fmt.Sprintf("%s: expected %v, got %v", field, expected, actual))
+	}
+}
+
+// verifyApplied reads back every resource created or updated by the apply of the
+// pkg and compares it with the pkg. The reads are made concurrently, limited by
+// the apply request limit. All mismatches are reported in the returned error,
+// grouped by resource type. The skipped resources were not applied and are not
+// read back.
+func (s *Service) verifyApplied(ctx context.Context, pkg *Pkg, skipped collisionSet) error {
+	verifications := s.verifications(pkg, skipped)
+
+	failures := make([]*applyErrBody, len(verifications))
+	err := s.dryRunEach(ctx, len(verifications), func(ctx context.Context, i int) error {
+		v := verifications[i]
+		m, err := v.fn(ctx)
+		if err != nil {
+			m = mismatches{"unable to read back: " + err.Error()}
+		}
+		if len(m) > 0 {
+			failures[i] = &applyErrBody{
+				name: v.name,
+				msg:  strings.Join(m, "; "),
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var resources []string
+	mErrs := make(map[string]applyErrs)
+	for i, f := range failures {
+		if f == nil {
+			continue
+		}
+		resource := verifications[i].resource
+		if _, ok := mErrs[resource]; !ok {
+			resources = append(resources, resource)
+		}
+		mErrs[resource] = append(mErrs[resource], f)
+	}
+	if len(resources) == 0 {
+		return nil
+	}
+
+	errs := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		errs = append(errs, mErrs[resource].toError(resource, "failed verification").Error())
+	}
+	return errors.New(strings.Join(errs, "\n"))
+}
+
+func (s *Service) verifications(pkg *Pkg, skipped collisionSet) []verification {
+	var vs []verification
+	for _, l := range skipped.labels(pkg.labels()) {
+		if !l.shouldApply() {
+			continue
+		}
+		l := l
+		vs = append(vs, verification{
+			resource: "label",
+			name:     l.Name(),
+			fn: func(ctx context.Context) (mismatches, error) {
+				existing, err := s.labelSVC.FindLabelByID(ctx, l.ID())
+				if err != nil {
+					return nil, err
+				}
+				var m mismatches
+				m.check("name", l.platformName(), existing.Name)
+				m.check("color", l.Color, existing.Properties["color"])
+				m.check("description", l.Description, existing.Properties["description"])
+				return m, nil
+			},
+		})
+	}
+
+	for _, v := range skipped.variables(pkg.variables()) {
+		if !v.shouldApply() {
+			continue
+		}
+		v := v
+		vs = append(vs, verification{
+			resource: "variable",
+			name:     v.Name(),
+			fn: func(ctx context.Context) (mismatches, error) {
+				existing, err := s.varSVC.FindVariableByID(ctx, v.ID())
+				if err != nil {
+					return nil, err
+				}
+				var m mismatches
+				m.check("name", v.platformName(), existing.Name)
+				m.check("description", v.Description, existing.Description)
+				var existingType string
+				if existing.Arguments != nil {
+					existingType = existing.Arguments.Type
+				}
+				m.check("type", v.Type, existingType)
+				return m, nil
+			},
+		})
+	}
+
+	for _, b := range skipped.buckets(pkg.buckets()) {
+		if !b.shouldApply() {
+			continue
+		}
+		b := b
+		vs = append(vs, verification{
+			resource: "bucket",
+			name:     b.Name(),
+			fn: func(ctx context.Context) (mismatches, error) {
+				existing, err := s.bucketSVC.FindBucketByID(ctx, b.ID())
+				if err != nil {
+					return nil, err
+				}
+				var m mismatches
+				m.check("name", b.platformName(), existing.Name)
+				m.check("description", b.Description, existing.Description)
+				m.check("retention period", b.RetentionRules.RP(), existing.RetentionPeriod)
+				return m, nil
+			},
+		})
+	}
+
+	for _, d := range pkg.dashboards() {
+		d := d
+		vs = append(vs, verification{
+			resource: "dashboard",
+			name:     d.Name(),
+			fn: func(ctx context.Context) (mismatches, error) {
+				existing, err := s.dashSVC.FindDashboardByID(ctx, d.ID())
+				if err != nil {
+					return nil, err
+				}
+				var m mismatches
+				m.check("name", d.Name(), existing.Name)
+				m.check("description", d.Description, existing.Description)
+				m.check("charts", len(d.Charts), len(existing.Cells))
+				return m, nil
+			},
+		})
+	}
+
+	for _, e := range skipped.notificationEndpoints(pkg.notificationEndpoints()) {
+		e := e
+		vs = append(vs, verification{
+			resource: "notification_endpoints",
+			name:     e.Name(),
+			fn: func(ctx context.Context) (mismatches, error) {
+				existing, err := s.endpointSVC.FindNotificationEndpointByID(ctx, e.ID())
+				if err != nil {
+					return nil, err
+				}
+				var m mismatches
+				m.check("name", e.platformName(), existing.GetName())
+				m.check("description", e.description, existing.GetDescription())
+				return m, nil
+			},
+		})
+	}
+
+	for _, t := range skipped.scraperTargets(pkg.scraperTargets()) {
+		t := t
+		vs = append(vs, verification{
+			resource: "scraper_targets",
+			name:     t.Name(),
+			fn: func(ctx context.Context) (mismatches, error) {
+				existing, err := s.scraperSVC.GetTargetByID(ctx, t.ID())
+				if err != nil {
+					return nil, err
+				}
+				var m mismatches
+				m.check("name", t.Name(), existing.Name)
+				m.check("type", t.typ, existing.Type)
+				m.check("url", t.url, existing.URL)
+				m.check("bucketID", t.bucketID, existing.BucketID)
+				return m, nil
+			},
+		})
+	}
+
+	for _, t := range pkg.tasks() {
+		t := t
+		vs = append(vs, verification{
+			resource: "tasks",
+			name:     t.Name(),
+			fn: func(ctx context.Context) (mismatches, error) {
+				existing, err := s.taskSVC.FindTaskByID(ctx, t.ID())
+				if err != nil {
+					return nil, err
+				}
+				var m mismatches
+				m.check("name", t.Name(), existing.Name)
+				m.check("description", t.description, existing.Description)
+				m.check("status", string(t.Status()), existing.Status)
+				return m, nil
+			},
+		})
+	}
+
+	for _, t := range pkg.telegrafs() {
+		t := t
+		vs = append(vs, verification{
+			resource: "telegrafs",
+			name:     t.Name(),
+			fn: func(ctx context.Context) (mismatches, error) {
+				existing, err := s.teleSVC.FindTelegrafConfigByID(ctx, t.ID())
+				if err != nil {
+					return nil, err
+				}
+				var m mismatches
+				m.check("name", t.Name(), existing.Name)
+				m.check("description", t.config.Description, existing.Description)
+				return m, nil
+			},
+		})
+	}
+
+	for _, mapping := range skipped.labelMappings(pkg.labelMappings()) {
+		mapping := mapping
+		vs = append(vs, verification{
+			resource: "label_mapping",
+			name:     mapping.ResourceName + ":" + mapping.LabelName,
+			fn: func(ctx context.Context) (mismatches, error) {
+				labels, err := s.labelSVC.FindResourceLabels(ctx, influxdb.LabelMappingFilter{
+					ResourceID:   influxdb.ID(mapping.ResourceID),
+					ResourceType: mapping.ResourceType,
+				})
+				if err != nil {
+					return nil, err
+				}
+				for _, l := range labels {
+					if l.ID == influxdb.ID(mapping.LabelID) {
+						return nil, nil
+					}
+				}
+				return mismatches{fmt.Sprintf("label %q is not mapped to the resource", mapping.LabelName)}, nil
+			},
+		})
+	}
+
+	return vs
+}
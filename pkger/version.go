@@ -0,0 +1,85 @@
+package pkger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/influxdata/influxdb"
+)
+
+// versionPattern matches the versions of a pkg that can be incremented, either
+// a monotonic integer, a major.minor or a semver, optionally prefixed with a v,
+// i.e. 3, v3, 1.0, 1.2.3 or v1.2.3-rc1.
+var versionPattern = regexp.MustCompile(`^(v?)(?:(\d+)|(\d+)\.(\d+)(?:\.(\d+))?(-[0-9A-Za-z.-]+)?)$`)
+
+// nextVersion increments the version, the integer of a monotonic version, the
+// minor of a major.minor and the patch of a semver. The pre-release of a semver
+// is dropped.
+func nextVersion(version string) (string, error) {
+	m := versionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("version %q is not an integer, major.minor or semver and cannot be incremented", version),
+		}
+	}
+
+	prefix := m[1]
+	if m[2] != "" {
+		n, err := strconv.ParseUint(m[2], 10, 64)
+		if err != nil {
+			return "", err
+		}
+		return prefix + strconv.FormatUint(n+1, 10), nil
+	}
+
+	if m[5] == "" {
+		minor, err := strconv.ParseUint(m[4], 10, 64)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s%s.%d", prefix, m[3], minor+1), nil
+	}
+
+	patch, err := strconv.ParseUint(m[5], 10, 64)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%s.%s.%d", prefix, m[3], m[4], patch+1), nil
+}
+
+// CreateWithPreviousMetadata sets the metadata of the new pkg to the metadata
+// of the pkg previously exported, with its version incremented. This keeps the
+// versions of a pkg exported repeatedly, i.e. to source control, increasing.
+// The previous version must be an integer, a major.minor or a semver,
+// optionally prefixed with a v. A monotonic integer is incremented by 1, a
+// major.minor by its minor and a semver by its patch.
+func CreateWithPreviousMetadata(prev Metadata) CreatePkgSetFn {
+	return func(opt *CreateOpt) error {
+		version, err := nextVersion(prev.Version)
+		if err != nil {
+			return err
+		}
+		opt.Metadata = Metadata{
+			Description: prev.Description,
+			Name:        prev.Name,
+			Version:     version,
+		}
+		return nil
+	}
+}
+
+// CreateWithPreviousPkg is CreateWithPreviousMetadata for the metadata of the
+// previous pkg.
+func CreateWithPreviousPkg(prev *Pkg) CreatePkgSetFn {
+	return func(opt *CreateOpt) error {
+		if prev == nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "previous pkg must be provided",
+			}
+		}
+		return CreateWithPreviousMetadata(prev.Metadata)(opt)
+	}
+}
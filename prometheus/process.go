@@ -0,0 +1,64 @@
+package prometheus
+
+import (
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewProcessCollector returns a collector which exports the CPU, memory and
+// file descriptor usage of the process. The prometheus process collector
+// only collects from a proc filesystem or on windows, on other platforms the
+// collector falls back to the start time and the CPU time of the process.
+func NewProcessCollector() prometheus.Collector {
+	if runtime.GOOS == "windows" || hasProcFS() {
+		return prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{})
+	}
+	return newProcessFallbackCollector()
+}
+
+func hasProcFS() bool {
+	_, err := os.Stat("/proc/self/stat")
+	return err == nil
+}
+
+// processFallbackCollector exports the process metrics that can be collected
+// without a proc filesystem.
+type processFallbackCollector struct {
+	cpuTotalDesc  *prometheus.Desc
+	startTimeDesc *prometheus.Desc
+	start         time.Time
+}
+
+func newProcessFallbackCollector() *processFallbackCollector {
+	return &processFallbackCollector{
+		cpuTotalDesc: prometheus.NewDesc(
+			"process_cpu_seconds_total",
+			"Total user and system CPU time spent in seconds.",
+			nil, nil,
+		),
+		startTimeDesc: prometheus.NewDesc(
+			"process_start_time_seconds",
+			"Start time of the process since unix epoch in seconds.",
+			nil, nil,
+		),
+		start: time.Now(),
+	}
+}
+
+// Describe returns all descriptions of the collector.
+func (c *processFallbackCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.cpuTotalDesc
+	ch <- c.startTimeDesc
+}
+
+// Collect returns the current state of all metrics of the collector.
+func (c *processFallbackCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.startTimeDesc, prometheus.GaugeValue, float64(c.start.UnixNano())/1e9)
+
+	if cpu, ok := processCPUSeconds(); ok {
+		ch <- prometheus.MustNewConstMetric(c.cpuTotalDesc, prometheus.CounterValue, cpu)
+	}
+}
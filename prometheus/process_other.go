@@ -0,0 +1,9 @@
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package prometheus
+
+// processCPUSeconds returns false, the CPU time of the process is not
+// available on this platform.
+func processCPUSeconds() (float64, bool) {
+	return 0, false
+}
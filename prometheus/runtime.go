@@ -0,0 +1,90 @@
+package prometheus
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// runtimeCollector exports the runtime metrics that are too expensive to be
+// collected by default, collecting them stops the world.
+type runtimeCollector struct {
+	mu        sync.Mutex
+	numGC     uint32
+	gcPauses  prometheus.Histogram
+	mutexDesc *prometheus.Desc
+}
+
+// NewRuntimeCollector returns a collector which exports a histogram of each
+// GC pause and the contentions recorded by the mutex profile. The mutex
+// profile only records contentions once its rate is set with
+// runtime.SetMutexProfileFraction.
+func NewRuntimeCollector() prometheus.Collector {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	return &runtimeCollector{
+		numGC: ms.NumGC,
+		gcPauses: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "go_gc_pause_seconds",
+			Help: "Duration of each GC stop-the-world pause.",
+			// 15 buckets spaced exponentially between 10µs and ~160ms
+			Buckets: prometheus.ExponentialBuckets(1e-5, 2, 15),
+		}),
+		mutexDesc: prometheus.NewDesc(
+			"go_mutex_contentions_total",
+			"Number of contentions recorded by the mutex profile.",
+			nil, nil,
+		),
+	}
+}
+
+// Describe returns all descriptions of the collector.
+func (c *runtimeCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.gcPauses.Describe(ch)
+	ch <- c.mutexDesc
+}
+
+// Collect returns the current state of all metrics of the collector.
+func (c *runtimeCollector) Collect(ch chan<- prometheus.Metric) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	c.mu.Lock()
+	// the runtime keeps the last 256 pauses, older pauses are lost.
+	n := ms.NumGC - c.numGC
+	if n > uint32(len(ms.PauseNs)) {
+		n = uint32(len(ms.PauseNs))
+	}
+	for i := uint32(0); i < n; i++ {
+		pause := ms.PauseNs[(ms.NumGC-i+255)%256]
+		c.gcPauses.Observe(float64(pause) / 1e9)
+	}
+	c.numGC = ms.NumGC
+	c.mu.Unlock()
+	c.gcPauses.Collect(ch)
+
+	ch <- prometheus.MustNewConstMetric(c.mutexDesc, prometheus.CounterValue, float64(mutexContentions()))
+}
+
+// mutexContentions returns the number of contentions of the mutex profile.
+func mutexContentions() int64 {
+	var records []runtime.BlockProfileRecord
+	n, _ := runtime.MutexProfile(nil)
+	for {
+		// allow for records added since the profile was sized.
+		records = make([]runtime.BlockProfileRecord, n+50)
+		var ok bool
+		if n, ok = runtime.MutexProfile(records); ok {
+			records = records[:n]
+			break
+		}
+	}
+
+	var count int64
+	for _, r := range records {
+		count += r.Count
+	}
+	return count
+}
@@ -0,0 +1,342 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+// cacheableQuery is a query whose result can be cached.
+type cacheableQuery struct {
+	key string
+	// compiler is the compiler of the request with now quantized.
+	compiler lang.FluxCompiler
+
+	buckets     []influxdb.ID
+	start, stop time.Time
+}
+
+// cacheable returns the cacheable form of the request. Only Flux queries that
+// read the time range of v.timeRangeStart and v.timeRangeStop from buckets the
+// authorization of the request can read, and that do not depend on anything
+// but the data in those buckets, are cacheable.
+func (s *ProxyQueryService) cacheable(ctx context.Context, req *query.ProxyRequest, now time.Time) (*cacheableQuery, bool) {
+	var compiler lang.FluxCompiler
+	switch c := req.Request.Compiler.(type) {
+	case lang.FluxCompiler:
+		compiler = c
+	case *lang.FluxCompiler:
+		if c == nil {
+			return nil, false
+		}
+		compiler = *c
+	default:
+		return nil, false
+	}
+
+	auth := req.Request.Authorization
+	if auth == nil || !auth.IsActive() {
+		return nil, false
+	}
+
+	pkg := parser.ParseSource(compiler.Query)
+	if ast.Check(pkg) > 0 {
+		return nil, false
+	}
+	a, ok := analyze(compiler.Extern, pkg)
+	if !ok {
+		return nil, false
+	}
+
+	if compiler.Now.IsZero() {
+		compiler.Now = now
+	}
+	compiler.Now = compiler.Now.Truncate(s.cache.config.Quantum)
+
+	start, ok := resolveTime(a.start, compiler.Now)
+	if !ok {
+		return nil, false
+	}
+	stop, ok := resolveTime(a.stop, compiler.Now)
+	if !ok || !start.Before(stop) || stop.After(now.Add(-s.cache.config.MinAge)) {
+		return nil, false
+	}
+
+	buckets, ok := s.readableBuckets(ctx, req.Request.OrganizationID, auth, a.sources)
+	if !ok {
+		return nil, false
+	}
+
+	key, err := cacheKey(req, compiler, start, stop)
+	if err != nil {
+		return nil, false
+	}
+
+	return &cacheableQuery{
+		key:      key,
+		compiler: compiler,
+		buckets:  buckets,
+		start:    start,
+		stop:     stop,
+	}, true
+}
+
+func (s *ProxyQueryService) readableBuckets(ctx context.Context, orgID influxdb.ID, auth *influxdb.Authorization, sources []source) ([]influxdb.ID, bool) {
+	seen := make(map[influxdb.ID]bool, len(sources))
+	buckets := make([]influxdb.ID, 0, len(sources))
+	for _, src := range sources {
+		var id influxdb.ID
+		if src.name != "" {
+			b, err := s.buckets.FindBucketByName(ctx, orgID, src.name)
+			if err != nil {
+				return nil, false
+			}
+			id = b.ID
+		} else if err := id.DecodeFromString(src.id); err != nil {
+			return nil, false
+		}
+
+		p, err := influxdb.NewPermissionAtID(id, influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+		if err != nil || !auth.Allowed(*p) {
+			return nil, false
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			buckets = append(buckets, id)
+		}
+	}
+	return buckets, true
+}
+
+// cacheKey identifies the result of the query, the time range the query reads
+// replaces now, a query run with a different now reading the same time range
+// has the same result.
+func cacheKey(req *query.ProxyRequest, compiler lang.FluxCompiler, start, stop time.Time) (string, error) {
+	dialect, err := json.Marshal(req.Dialect)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%d\x00%d\x00", req.Request.OrganizationID, start.UnixNano(), stop.UnixNano())
+	fmt.Fprintf(h, "%T\x00%s\x00", req.Dialect, dialect)
+	if compiler.Extern != nil {
+		fmt.Fprintf(h, "%s\x00", ast.Format(compiler.Extern))
+	}
+	fmt.Fprint(h, compiler.Query)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// source is a bucket read by a query, by name or by ID.
+type source struct {
+	name string
+	id   string
+}
+
+// analysis is what the result of a query depends on.
+type analysis struct {
+	start, stop ast.Expression
+	sources     []source
+}
+
+// uncacheableFuncs are the functions a query can depend on, or have side
+// effects with, that a cached result would not reflect.
+var uncacheableFuncs = map[string]bool{
+	"buckets":    true,
+	"now":        true,
+	"systemTime": true,
+	"to":         true,
+}
+
+// analyze returns what the result of the query depends on. The query is not
+// cacheable when it imports packages, declares options other than v, reads a
+// time range other than v.timeRangeStart to v.timeRangeStop, or reads anything
+// other than buckets by their literal name or ID.
+func analyze(extern *ast.File, pkg *ast.Package) (*analysis, bool) {
+	files := pkg.Files
+	if extern != nil {
+		files = append([]*ast.File{extern}, files...)
+	}
+
+	var a analysis
+	for _, f := range files {
+		if len(f.Imports) > 0 {
+			return nil, false
+		}
+		for _, stmt := range f.Body {
+			opt, ok := stmt.(*ast.OptionStatement)
+			if !ok {
+				continue
+			}
+			assign, ok := opt.Assignment.(*ast.VariableAssignment)
+			if !ok || assign.ID.Name != "v" {
+				return nil, false
+			}
+			obj, ok := assign.Init.(*ast.ObjectExpression)
+			if !ok {
+				return nil, false
+			}
+			if obj.With == nil {
+				a.start, a.stop = nil, nil
+			}
+			for _, p := range obj.Properties {
+				switch propertyKey(p.Key) {
+				case "timeRangeStart":
+					a.start = p.Value
+				case "timeRangeStop":
+					a.stop = p.Value
+				}
+			}
+		}
+	}
+
+	var (
+		ok     = true
+		ranges int
+	)
+	for _, f := range files {
+		ast.Walk(ast.CreateVisitor(func(node ast.Node) {
+			call, isCall := node.(*ast.CallExpression)
+			if !isCall {
+				return
+			}
+			fn, isIdent := call.Callee.(*ast.Identifier)
+			if !isIdent {
+				return
+			}
+
+			switch {
+			case fn.Name == "from":
+				src, valid := fromSource(call)
+				if !valid {
+					ok = false
+					return
+				}
+				a.sources = append(a.sources, src)
+			case fn.Name == "range":
+				ranges++
+				args := callArgs(call)
+				if len(args) != 2 || !isVMember(args["start"], "timeRangeStart") || !isVMember(args["stop"], "timeRangeStop") {
+					ok = false
+				}
+			case uncacheableFuncs[fn.Name]:
+				ok = false
+			}
+		}), f)
+	}
+
+	if !ok || ranges == 0 || len(a.sources) == 0 || a.start == nil || a.stop == nil {
+		return nil, false
+	}
+	return &a, true
+}
+
+func fromSource(call *ast.CallExpression) (source, bool) {
+	args := callArgs(call)
+	if len(args) != 1 {
+		return source{}, false
+	}
+	if lit, ok := args["bucket"].(*ast.StringLiteral); ok {
+		return source{name: lit.Value}, true
+	}
+	if lit, ok := args["bucketID"].(*ast.StringLiteral); ok {
+		return source{id: lit.Value}, true
+	}
+	return source{}, false
+}
+
+func callArgs(call *ast.CallExpression) map[string]ast.Expression {
+	args := make(map[string]ast.Expression)
+	if len(call.Arguments) != 1 {
+		return args
+	}
+	obj, ok := call.Arguments[0].(*ast.ObjectExpression)
+	if !ok {
+		return args
+	}
+	for _, p := range obj.Properties {
+		args[propertyKey(p.Key)] = p.Value
+	}
+	return args
+}
+
+func propertyKey(k ast.PropertyKey) string {
+	switch k := k.(type) {
+	case *ast.Identifier:
+		return k.Name
+	case *ast.StringLiteral:
+		return k.Value
+	}
+	return ""
+}
+
+func isVMember(expr ast.Expression, name string) bool {
+	m, ok := expr.(*ast.MemberExpression)
+	if !ok {
+		return false
+	}
+	if obj, ok := m.Object.(*ast.Identifier); !ok || obj.Name != "v" {
+		return false
+	}
+	return propertyKey(m.Property) == name
+}
+
+// resolveTime returns the time of a time range option, an absolute time, a
+// duration relative to now, or now().
+func resolveTime(expr ast.Expression, now time.Time) (time.Time, bool) {
+	switch e := expr.(type) {
+	case *ast.DateTimeLiteral:
+		return e.Value, true
+	case *ast.DurationLiteral:
+		d, ok := duration(e)
+		return now.Add(d), ok
+	case *ast.UnaryExpression:
+		lit, ok := e.Argument.(*ast.DurationLiteral)
+		if !ok || e.Operator != ast.SubtractionOperator {
+			return time.Time{}, false
+		}
+		d, ok := duration(lit)
+		return now.Add(-d), ok
+	case *ast.CallExpression:
+		if fn, ok := e.Callee.(*ast.Identifier); ok && fn.Name == "now" && len(e.Arguments) == 0 {
+			return now, true
+		}
+	}
+	return time.Time{}, false
+}
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+}
+
+// duration returns the fixed length of the duration, durations of months and
+// years have none.
+func duration(lit *ast.DurationLiteral) (time.Duration, bool) {
+	var d time.Duration
+	for _, v := range lit.Values {
+		unit, ok := durationUnits[v.Unit]
+		if !ok {
+			return 0, false
+		}
+		d += time.Duration(v.Magnitude) * unit
+	}
+	return d, true
+}
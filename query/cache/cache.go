@@ -0,0 +1,189 @@
+// Package cache provides a read-through cache of query results in front of a
+// query.ProxyQueryService.
+//
+// Dashboards on auto refresh run the same Flux again and again, often against
+// time ranges that no longer receive writes. The results of such a query are
+// cached when the time range it reads ends sufficiently in the past. A cached
+// result is keyed by the organization, the query and its externs, the dialect
+// of the results and the time range of the query. Relative time ranges, i.e.
+// v.timeRangeStart of -1h, are resolved against a quantized now so that the
+// same query run moments apart shares a result.
+//
+// Cached results are invalidated by the writes and deletes to the buckets they
+// read, see WrapPointsWriter and WrapDeleteService. The invalidation is coarse, each
+// bucket a write touches has a watermark at the earliest point written to it,
+// the results of the bucket whose time range ends after the watermark are
+// invalidated.
+package cache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/check"
+	"github.com/influxdata/influxdb/query"
+	"go.uber.org/zap"
+)
+
+// Defaults for the Config.
+const (
+	DefaultMaxBytes = 64 * 1024 * 1024
+	DefaultTTL      = 10 * time.Minute
+	DefaultMinAge   = time.Minute
+	DefaultQuantum  = 10 * time.Second
+)
+
+// Config is the configuration of the cache.
+type Config struct {
+	// MaxBytes is the total size of the cached results, the least recently
+	// used results are evicted to stay within it.
+	MaxBytes int
+	// TTL is how long a result is served from the cache. It bounds the
+	// staleness of results that are not invalidated by a write, i.e. data
+	// removed by retention enforcement.
+	TTL time.Duration
+	// MinAge is how far in the past the time range of a query must end for
+	// its result to be cached.
+	MinAge time.Duration
+	// Quantum is the precision of now that relative time ranges are
+	// resolved against.
+	Quantum time.Duration
+}
+
+// NewConfig returns a Config with the defaults.
+func NewConfig() Config {
+	return Config{
+		MaxBytes: DefaultMaxBytes,
+		TTL:      DefaultTTL,
+		MinAge:   DefaultMinAge,
+		Quantum:  DefaultQuantum,
+	}
+}
+
+// BucketFinder finds the buckets read by queries that name them.
+type BucketFinder interface {
+	FindBucketByName(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error)
+}
+
+// Cache holds the cached query results. The writes and deletes to the storage
+// engine must go through WrapPointsWriter and WrapDeleteService for the cached
+// results to be invalidated.
+type Cache struct {
+	config  Config
+	log     *zap.Logger
+	metrics *metrics
+	store   *store
+}
+
+// New constructs a Cache.
+func New(log *zap.Logger, config Config) *Cache {
+	if config.Quantum <= 0 {
+		config.Quantum = DefaultQuantum
+	}
+	m := newMetrics()
+	return &Cache{
+		config:  config,
+		log:     log,
+		metrics: m,
+		store:   newStore(int64(config.MaxBytes), config.TTL, m),
+	}
+}
+
+// ProxyQueryService caches the results of the queries proxied to the
+// underlying query.ProxyQueryService.
+type ProxyQueryService struct {
+	next    query.ProxyQueryService
+	buckets BucketFinder
+	cache   *Cache
+
+	now func() time.Time
+}
+
+var _ query.ProxyQueryService = (*ProxyQueryService)(nil)
+
+// NewProxyQueryService constructs a ProxyQueryService caching the results of
+// next in the cache. The buckets are used to resolve the buckets a query reads
+// by name.
+func NewProxyQueryService(next query.ProxyQueryService, buckets BucketFinder, cache *Cache) *ProxyQueryService {
+	return &ProxyQueryService{
+		next:    next,
+		buckets: buckets,
+		cache:   cache,
+		now:     time.Now,
+	}
+}
+
+// Check returns the status of the underlying query service.
+func (s *ProxyQueryService) Check(ctx context.Context) check.Response {
+	return s.next.Check(ctx)
+}
+
+// Query writes the cached result of the query to w when there is one, otherwise
+// the query is proxied to the underlying service and its result is cached when
+// the query is cacheable.
+func (s *ProxyQueryService) Query(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+	now := s.now()
+
+	c, ok := s.cacheable(ctx, req, now)
+	if !ok {
+		s.cache.metrics.requests.WithLabelValues(resultUncacheable).Inc()
+		return s.next.Query(ctx, w, req)
+	}
+
+	if e, ok := s.cache.store.get(c.key, now); ok {
+		s.cache.metrics.requests.WithLabelValues(resultHit).Inc()
+		s.cache.metrics.staleness.Observe(now.Sub(e.cachedAt).Seconds())
+		_, err := w.Write(e.body)
+		return e.stats, err
+	}
+	s.cache.metrics.requests.WithLabelValues(resultMiss).Inc()
+
+	// a write to the buckets while the query runs may or may not be
+	// in its result, it is only cached when there are none.
+	gens := s.cache.store.snapshot(c.buckets)
+
+	proxied := *req
+	proxied.Request.Compiler = c.compiler
+
+	buf := &limitedBuffer{max: s.cache.config.MaxBytes}
+	stats, err := s.next.Query(ctx, io.MultiWriter(w, buf), &proxied)
+	if err != nil || buf.overflow {
+		return stats, err
+	}
+
+	s.cache.store.add(&entry{
+		key:      c.key,
+		buckets:  c.buckets,
+		start:    c.start,
+		stop:     c.stop,
+		body:     buf.Bytes(),
+		stats:    stats,
+		cachedAt: now,
+	}, gens)
+	return stats, nil
+}
+
+// limitedBuffer buffers up to max bytes, the writes beyond it are discarded and
+// mark the buffer as overflowed. It never fails a write, the result of a query
+// too large to be cached is still written in full.
+type limitedBuffer struct {
+	bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.overflow {
+		return len(p), nil
+	}
+	if b.Len()+len(p) > b.max {
+		b.overflow = true
+		b.Reset()
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,389 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+const (
+	orgID    influxdb.ID = 1
+	bucketID influxdb.ID = 2
+	otherID  influxdb.ID = 3
+
+	dashQuery = `from(bucket: "b1") |> range(start: v.timeRangeStart, stop: v.timeRangeStop) |> mean()`
+)
+
+var (
+	now = time.Date(2019, 12, 1, 12, 0, 0, 0, time.UTC)
+
+	// rangeStart and rangeStop are the absolute time range of the extern
+	// of the queries, well in the past of now.
+	rangeStart = time.Date(2019, 12, 1, 10, 0, 0, 0, time.UTC)
+	rangeStop  = time.Date(2019, 12, 1, 11, 0, 0, 0, time.UTC)
+)
+
+type testCache struct {
+	t     *testing.T
+	cache *Cache
+	svc   *ProxyQueryService
+	calls int32
+	// onQuery is called by the underlying query service while it runs.
+	onQuery func()
+}
+
+func newTestCache(t *testing.T) *testCache {
+	tc := &testCache{t: t}
+
+	next := &querymock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			n := atomic.AddInt32(&tc.calls, 1)
+			if tc.onQuery != nil {
+				tc.onQuery()
+			}
+			_, err := w.Write([]byte{'r', byte('0' + n)})
+			return flux.Statistics{}, err
+		},
+	}
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByNameFn = func(ctx context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+		if name != "b1" {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound}
+		}
+		return &influxdb.Bucket{ID: bucketID, OrgID: orgID, Name: name}, nil
+	}
+
+	tc.cache = New(zap.NewNop(), NewConfig())
+	tc.svc = NewProxyQueryService(next, buckets, tc.cache)
+	tc.svc.now = func() time.Time { return now }
+	return tc
+}
+
+// query runs the query and returns the result.
+func (tc *testCache) query(req *query.ProxyRequest) string {
+	tc.t.Helper()
+
+	var buf bytes.Buffer
+	if _, err := tc.svc.Query(context.Background(), &buf, req); err != nil {
+		tc.t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func (tc *testCache) expectCalls(n int32) {
+	tc.t.Helper()
+
+	if got := atomic.LoadInt32(&tc.calls); got != n {
+		tc.t.Fatalf("expected %d queries to run, got %d", n, got)
+	}
+}
+
+func (tc *testCache) write(bucketID influxdb.ID, times ...time.Time) {
+	tc.t.Helper()
+
+	var points []models.Point
+	for _, t := range times {
+		points = append(points, models.MustNewPoint("m", nil, models.Fields{"f": 1.0}, t))
+	}
+	points, err := tsdb.ExplodePoints(orgID, bucketID, points)
+	if err != nil {
+		tc.t.Fatal(err)
+	}
+
+	w := tc.cache.WrapPointsWriter(&mock.PointsWriter{})
+	if err := w.WritePoints(context.Background(), points); err != nil {
+		tc.t.Fatal(err)
+	}
+}
+
+func newRequest(t *testing.T, q, extern string, perms ...influxdb.Permission) *query.ProxyRequest {
+	t.Helper()
+
+	if len(perms) == 0 {
+		p, err := influxdb.NewPermission(influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		perms = append(perms, *p)
+	}
+
+	var externFile *ast.File
+	if extern != "" {
+		pkg := parser.ParseSource(extern)
+		if ast.Check(pkg) > 0 {
+			t.Fatalf("invalid extern: %v", ast.GetErrors(pkg))
+		}
+		externFile = pkg.Files[0]
+	}
+
+	return &query.ProxyRequest{
+		Request: query.Request{
+			Authorization: &influxdb.Authorization{
+				OrgID:       orgID,
+				Status:      influxdb.Active,
+				Permissions: perms,
+			},
+			OrganizationID: orgID,
+			Compiler: lang.FluxCompiler{
+				Now:    now,
+				Extern: externFile,
+				Query:  q,
+			},
+		},
+		Dialect: csv.DefaultDialect(),
+	}
+}
+
+func absoluteExtern(start, stop time.Time) string {
+	return `option v = {timeRangeStart: ` + start.Format(time.RFC3339Nano) + `, timeRangeStop: ` + stop.Format(time.RFC3339Nano) + `}`
+}
+
+func TestProxyQueryService_Caching(t *testing.T) {
+	t.Run("caches queries of time ranges that ended in the past", func(t *testing.T) {
+		tc := newTestCache(t)
+		req := newRequest(t, dashQuery, absoluteExtern(rangeStart, rangeStop))
+
+		first := tc.query(req)
+		second := tc.query(req)
+		tc.expectCalls(1)
+		if first != second {
+			t.Fatalf("expected cached result %q, got %q", first, second)
+		}
+	})
+
+	t.Run("relative time ranges share a result within the quantum", func(t *testing.T) {
+		tc := newTestCache(t)
+
+		var compilerNow time.Time
+		next := tc.svc.next
+		tc.svc.next = &querymock.ProxyQueryService{
+			QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+				compilerNow = req.Request.Compiler.(lang.FluxCompiler).Now
+				return next.Query(ctx, w, req)
+			},
+		}
+
+		extern := `option v = {timeRangeStart: -2h, timeRangeStop: -1h}`
+		base := now.Add(3 * time.Second)
+		for _, offset := range []time.Duration{0, time.Second, 5 * time.Second} {
+			req := newRequest(t, dashQuery, extern)
+			c := req.Request.Compiler.(lang.FluxCompiler)
+			c.Now = base.Add(offset)
+			req.Request.Compiler = c
+			tc.query(req)
+		}
+		tc.expectCalls(1)
+		if !compilerNow.Equal(now) {
+			t.Fatalf("expected query to run with now quantized to %s, got %s", now, compilerNow)
+		}
+
+		req := newRequest(t, dashQuery, extern)
+		c := req.Request.Compiler.(lang.FluxCompiler)
+		c.Now = now.Add(DefaultQuantum)
+		req.Request.Compiler = c
+		tc.query(req)
+		tc.expectCalls(2)
+	})
+
+	t.Run("does not cache", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  func(t *testing.T) *query.ProxyRequest
+		}{
+			{
+				name: "time range ending within the min age",
+				req: func(t *testing.T) *query.ProxyRequest {
+					return newRequest(t, dashQuery, absoluteExtern(rangeStart, now.Add(-30*time.Second)))
+				},
+			},
+			{
+				name: "time range ending now",
+				req: func(t *testing.T) *query.ProxyRequest {
+					return newRequest(t, dashQuery, `option v = {timeRangeStart: -1h, timeRangeStop: now()}`)
+				},
+			},
+			{
+				name: "range not of the time range options",
+				req: func(t *testing.T) *query.ProxyRequest {
+					return newRequest(t, `from(bucket: "b1") |> range(start: -1h)`, absoluteExtern(rangeStart, rangeStop))
+				},
+			},
+			{
+				name: "imports",
+				req: func(t *testing.T) *query.ProxyRequest {
+					return newRequest(t, `import "experimental"`+"\n"+dashQuery, absoluteExtern(rangeStart, rangeStop))
+				},
+			},
+			{
+				name: "writes",
+				req: func(t *testing.T) *query.ProxyRequest {
+					return newRequest(t, dashQuery+` |> to(bucket: "b2")`, absoluteExtern(rangeStart, rangeStop))
+				},
+			},
+			{
+				name: "bucket that is not a literal",
+				req: func(t *testing.T) *query.ProxyRequest {
+					return newRequest(t, `from(bucket: v.bucket) |> range(start: v.timeRangeStart, stop: v.timeRangeStop)`, absoluteExtern(rangeStart, rangeStop))
+				},
+			},
+			{
+				name: "bucket the authorization cannot read",
+				req: func(t *testing.T) *query.ProxyRequest {
+					p, err := influxdb.NewPermissionAtID(otherID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return newRequest(t, dashQuery, absoluteExtern(rangeStart, rangeStop), *p)
+				},
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				tc := newTestCache(t)
+				req := tt.req(t)
+
+				tc.query(req)
+				tc.query(req)
+				tc.expectCalls(2)
+			}
+			t.Run(tt.name, fn)
+		}
+	})
+
+	t.Run("expires results after the ttl", func(t *testing.T) {
+		tc := newTestCache(t)
+		req := newRequest(t, dashQuery, absoluteExtern(rangeStart, rangeStop))
+
+		tc.query(req)
+		tc.svc.now = func() time.Time { return now.Add(DefaultTTL + time.Second) }
+		tc.query(req)
+		tc.expectCalls(2)
+	})
+}
+
+func TestProxyQueryService_Invalidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		invalidate  func(tc *testCache)
+		invalidated bool
+	}{
+		{
+			name: "write within the time range",
+			invalidate: func(tc *testCache) {
+				tc.write(bucketID, rangeStart.Add(time.Minute))
+			},
+			invalidated: true,
+		},
+		{
+			name: "write at the last nanosecond of the time range",
+			invalidate: func(tc *testCache) {
+				tc.write(bucketID, rangeStop.Add(-time.Nanosecond))
+			},
+			invalidated: true,
+		},
+		{
+			name: "write at the end of the time range",
+			invalidate: func(tc *testCache) {
+				tc.write(bucketID, rangeStop)
+			},
+			invalidated: false,
+		},
+		{
+			name: "write after the time range",
+			invalidate: func(tc *testCache) {
+				tc.write(bucketID, now)
+			},
+			invalidated: false,
+		},
+		{
+			name: "write before the time range",
+			invalidate: func(tc *testCache) {
+				// the watermark is the earliest point of the write, the
+				// range of the write is not considered.
+				tc.write(bucketID, rangeStart.Add(-time.Hour), now)
+			},
+			invalidated: true,
+		},
+		{
+			name: "write to another bucket",
+			invalidate: func(tc *testCache) {
+				tc.write(otherID, rangeStart.Add(time.Minute))
+			},
+			invalidated: false,
+		},
+		{
+			name: "delete overlapping the time range",
+			invalidate: func(tc *testCache) {
+				d := tc.cache.WrapDeleteService(mock.NewDeleteService())
+				err := d.DeleteBucketRangePredicate(context.Background(), orgID, bucketID, rangeStop.Add(-time.Minute).UnixNano(), now.UnixNano(), nil)
+				if err != nil {
+					tc.t.Fatal(err)
+				}
+			},
+			invalidated: true,
+		},
+		{
+			name: "delete after the time range",
+			invalidate: func(tc *testCache) {
+				d := tc.cache.WrapDeleteService(mock.NewDeleteService())
+				err := d.DeleteBucketRangePredicate(context.Background(), orgID, bucketID, rangeStop.UnixNano(), now.UnixNano(), nil)
+				if err != nil {
+					tc.t.Fatal(err)
+				}
+			},
+			invalidated: false,
+		},
+	}
+
+	for _, tt := range tests {
+		fn := func(t *testing.T) {
+			tc := newTestCache(t)
+			req := newRequest(t, dashQuery, absoluteExtern(rangeStart, rangeStop))
+
+			tc.query(req)
+			tt.invalidate(tc)
+			tc.query(req)
+
+			if tt.invalidated {
+				tc.expectCalls(2)
+			} else {
+				tc.expectCalls(1)
+			}
+		}
+		t.Run(tt.name, fn)
+	}
+
+	t.Run("result of a query run during a write is not cached", func(t *testing.T) {
+		tc := newTestCache(t)
+		req := newRequest(t, dashQuery, absoluteExtern(rangeStart, rangeStop))
+
+		tc.onQuery = func() {
+			tc.onQuery = nil
+			tc.write(bucketID, now)
+		}
+		tc.query(req)
+		tc.query(req)
+		tc.expectCalls(2)
+
+		tc.query(req)
+		tc.expectCalls(2)
+	})
+}
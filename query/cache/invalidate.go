@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"math"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// PointsWriter writes points to the storage engine.
+type PointsWriter interface {
+	WritePoints(ctx context.Context, points []models.Point) error
+}
+
+// WrapPointsWriter returns a PointsWriter that invalidates the cached results
+// its writes overlap. The points are expected to have the org and bucket they
+// are written to encoded in their measurement, as they are written to the
+// engine.
+func (c *Cache) WrapPointsWriter(next PointsWriter) PointsWriter {
+	return &pointsWriter{next: next, c: c}
+}
+
+type pointsWriter struct {
+	next PointsWriter
+	c    *Cache
+}
+
+func (w *pointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	// the cache is invalidated once the points are written, whether or
+	// not they all were, so a query run after the write returns sees them.
+	defer w.c.invalidatePoints(points)
+	return w.next.WritePoints(ctx, points)
+}
+
+// invalidatePoints invalidates the results of each bucket written to whose
+// time range ends after the bucket's watermark, the earliest point written to
+// the bucket.
+func (c *Cache) invalidatePoints(points []models.Point) {
+	watermarks := make(map[influxdb.ID]int64)
+	for _, p := range points {
+		name := p.Name()
+		if len(name) < len(tsdb.EncodeName(0, 0)) {
+			c.log.Debug("Invalidating query cache for write to unknown bucket")
+			c.store.invalidateAll()
+			return
+		}
+		_, bucketID := tsdb.DecodeNameSlice(name)
+
+		t := p.UnixNano()
+		if wm, ok := watermarks[bucketID]; !ok || t < wm {
+			watermarks[bucketID] = t
+		}
+	}
+
+	for bucketID, wm := range watermarks {
+		c.store.invalidate(bucketID, wm, math.MaxInt64)
+	}
+}
+
+// WrapDeleteService returns an influxdb.DeleteService that invalidates the
+// cached results its deletes overlap.
+func (c *Cache) WrapDeleteService(next influxdb.DeleteService) influxdb.DeleteService {
+	return &deleteService{next: next, c: c}
+}
+
+type deleteService struct {
+	next influxdb.DeleteService
+	c    *Cache
+}
+
+func (d *deleteService) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+	defer d.c.store.invalidate(bucketID, min, max)
+	return d.next.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
+}
@@ -0,0 +1,74 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const (
+	resultHit         = "hit"
+	resultMiss        = "miss"
+	resultUncacheable = "uncacheable"
+
+	evictExpired     = "expired"
+	evictInvalidated = "invalidated"
+	evictReplaced    = "replaced"
+	evictSize        = "size"
+)
+
+type metrics struct {
+	requests  *prometheus.CounterVec
+	evictions *prometheus.CounterVec
+	entries   prometheus.Gauge
+	bytes     prometheus.Gauge
+	staleness prometheus.Histogram
+}
+
+func newMetrics() *metrics {
+	const (
+		namespace = "query"
+		subsystem = "cache"
+	)
+
+	return &metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_total",
+			Help:      "Number of queries by cache result, one of hit, miss or uncacheable.",
+		}, []string{"result"}),
+		evictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "evictions_total",
+			Help:      "Number of cached results evicted by reason.",
+		}, []string{"reason"}),
+		entries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "entries",
+			Help:      "Number of cached results.",
+		}),
+		bytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "bytes",
+			Help:      "Size of the cached results in bytes.",
+		}),
+		staleness: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "hit_age_seconds",
+			Help:      "Age of the cached results served.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 7),
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (c *Cache) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.metrics.requests,
+		c.metrics.evictions,
+		c.metrics.entries,
+		c.metrics.bytes,
+		c.metrics.staleness,
+	}
+}
@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+)
+
+// entry is a cached query result.
+type entry struct {
+	key         string
+	buckets     []influxdb.ID
+	start, stop time.Time
+
+	body     []byte
+	stats    flux.Statistics
+	cachedAt time.Time
+
+	elem *list.Element
+}
+
+// store is a size and TTL bounded LRU of the cached results, indexed by the
+// buckets they read for the invalidation of writes.
+type store struct {
+	maxBytes int64
+	ttl      time.Duration
+	metrics  *metrics
+
+	mu       sync.Mutex
+	entries  map[string]*entry
+	byBucket map[influxdb.ID]map[*entry]bool
+	lru      *list.List
+	size     int64
+
+	// gens is incremented on each write to a bucket and epoch on each write
+	// to an unknown bucket, a result is only cached when neither changed for
+	// its buckets while its query ran.
+	gens  map[influxdb.ID]uint64
+	epoch uint64
+}
+
+// generations is a snapshot of the write generations of a query's buckets.
+type generations struct {
+	epoch   uint64
+	buckets map[influxdb.ID]uint64
+}
+
+func newStore(maxBytes int64, ttl time.Duration, m *metrics) *store {
+	return &store{
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		metrics:  m,
+		entries:  make(map[string]*entry),
+		byBucket: make(map[influxdb.ID]map[*entry]bool),
+		lru:      list.New(),
+		gens:     make(map[influxdb.ID]uint64),
+	}
+}
+
+func (s *store) get(key string, now time.Time) (*entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if s.ttl > 0 && now.Sub(e.cachedAt) > s.ttl {
+		s.remove(e, evictExpired)
+		return nil, false
+	}
+	s.lru.MoveToFront(e.elem)
+	return e, true
+}
+
+func (s *store) snapshot(buckets []influxdb.ID) generations {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gens := generations{
+		epoch:   s.epoch,
+		buckets: make(map[influxdb.ID]uint64, len(buckets)),
+	}
+	for _, id := range buckets {
+		gens.buckets[id] = s.gens[id]
+	}
+	return gens
+}
+
+// add caches the entry unless a bucket it reads was written to since gens.
+func (s *store) add(e *entry, gens generations) {
+	size := int64(len(e.body))
+	if size > s.maxBytes {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.epoch != gens.epoch {
+		return
+	}
+	for id, gen := range gens.buckets {
+		if s.gens[id] != gen {
+			return
+		}
+	}
+
+	if existing, ok := s.entries[e.key]; ok {
+		s.remove(existing, evictReplaced)
+	}
+	for s.size+size > s.maxBytes && s.lru.Len() > 0 {
+		s.remove(s.lru.Back().Value.(*entry), evictSize)
+	}
+
+	e.elem = s.lru.PushFront(e)
+	s.entries[e.key] = e
+	for _, id := range e.buckets {
+		if s.byBucket[id] == nil {
+			s.byBucket[id] = make(map[*entry]bool)
+		}
+		s.byBucket[id][e] = true
+	}
+	s.size += size
+	s.metrics.entries.Set(float64(len(s.entries)))
+	s.metrics.bytes.Set(float64(s.size))
+}
+
+// invalidate removes the results of the bucket whose time range overlaps
+// [min, max].
+func (s *store) invalidate(bucketID influxdb.ID, min, max int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gens[bucketID]++
+	for e := range s.byBucket[bucketID] {
+		if e.start.UnixNano() <= max && min < e.stop.UnixNano() {
+			s.remove(e, evictInvalidated)
+		}
+	}
+}
+
+// invalidateAll removes every result, it is used for writes to buckets that
+// cannot be determined.
+func (s *store) invalidateAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.epoch++
+	for _, e := range s.entries {
+		s.remove(e, evictInvalidated)
+	}
+}
+
+func (s *store) remove(e *entry, reason string) {
+	delete(s.entries, e.key)
+	for _, id := range e.buckets {
+		delete(s.byBucket[id], e)
+		if len(s.byBucket[id]) == 0 {
+			delete(s.byBucket, id)
+		}
+	}
+	s.lru.Remove(e.elem)
+	s.size -= int64(len(e.body))
+
+	s.metrics.evictions.WithLabelValues(reason).Inc()
+	s.metrics.entries.Set(float64(len(s.entries)))
+	s.metrics.bytes.Set(float64(s.size))
+}
@@ -64,6 +64,7 @@ type Controller struct {
 	log *zap.Logger
 
 	dependencies []flux.Dependency
+	config       Config
 }
 
 type Config struct {
@@ -182,6 +183,7 @@ func New(config Config) (*Controller, error) {
 		metrics:      newControllerMetrics(c.MetricLabelKeys),
 		labelKeys:    c.MetricLabelKeys,
 		dependencies: c.ExecutorDependencies,
+		config:       c,
 	}
 	ctrl.wg.Add(c.ConcurrencyQuota)
 	for i := 0; i < c.ConcurrencyQuota; i++ {
@@ -193,6 +195,11 @@ func New(config Config) (*Controller, error) {
 	return ctrl, nil
 }
 
+// Config returns the config of the controller, with its defaults filled in.
+func (c *Controller) Config() Config {
+	return c.config
+}
+
 // Query satisfies the AsyncQueryService while ensuring the request is propagated on the context.
 func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query, error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/influxdb"
+)
+
+// A BucketIndex lists the buckets an engine holds data for.
+type BucketIndex interface {
+	ForEachBucket(fn func(orgID, bucketID influxdb.ID) error) error
+}
+
+// BucketRef identifies a bucket by its org and ID.
+type BucketRef struct {
+	OrgID    influxdb.ID
+	BucketID influxdb.ID
+}
+
+// BucketConsistency is the difference between the buckets of the kv store and
+// the buckets the engine holds data for, i.e. after restoring backups of the
+// two taken at different times.
+type BucketConsistency struct {
+	// Orphaned are the buckets the engine holds data for that do not exist in
+	// the kv store. Their data is never queried, nor is it removed by the
+	// retention enforcer, so the disk it occupies is never reclaimed.
+	Orphaned []BucketRef
+
+	// Empty are the buckets of the kv store the engine holds no data for. A
+	// bucket that has not been written to is empty, as is a bucket whose data
+	// was lost. The engine creates the data of a bucket on its first write, so
+	// there is nothing to repair.
+	Empty []BucketRef
+}
+
+// Consistent returns true when the engine holds data for no bucket missing
+// from the kv store.
+func (c BucketConsistency) Consistent() bool {
+	return len(c.Orphaned) == 0
+}
+
+// CheckBucketConsistency compares the buckets of the kv store with the buckets
+// the engine holds data for. It reads the bucket metadata of each alone, it is
+// fast enough to run every time the engine is opened.
+func CheckBucketConsistency(ctx context.Context, index BucketIndex, finder BucketFinder) (BucketConsistency, error) {
+	ctx, cancel := context.WithTimeout(ctx, bucketAPITimeout)
+	defer cancel()
+
+	buckets, _, err := finder.FindBuckets(ctx, influxdb.BucketFilter{})
+	if err != nil {
+		return BucketConsistency{}, fmt.Errorf("finding buckets: %v", err)
+	}
+
+	existing := make(map[BucketRef]bool, len(buckets))
+	for _, b := range buckets {
+		existing[BucketRef{OrgID: b.OrgID, BucketID: b.ID}] = true
+	}
+
+	var c BucketConsistency
+	stored := make(map[BucketRef]bool)
+	err = index.ForEachBucket(func(orgID, bucketID influxdb.ID) error {
+		ref := BucketRef{OrgID: orgID, BucketID: bucketID}
+		stored[ref] = true
+		if !existing[ref] && !isSystemBucketID(bucketID) {
+			c.Orphaned = append(c.Orphaned, ref)
+		}
+		return nil
+	})
+	if err != nil {
+		return BucketConsistency{}, fmt.Errorf("listing engine buckets: %v", err)
+	}
+
+	for ref := range existing {
+		if !stored[ref] && !isSystemBucketID(ref.BucketID) {
+			c.Empty = append(c.Empty, ref)
+		}
+	}
+
+	sortBucketRefs(c.Orphaned)
+	sortBucketRefs(c.Empty)
+	return c, nil
+}
+
+// isSystemBucketID returns true for the fixed IDs the bucket service resolves
+// the system buckets of an org to when it has no bucket documents of its own,
+// as the legacy orgs do. The data of their system buckets is kept under these
+// IDs, it is never orphaned, nor are the mocked buckets ever empty.
+func isSystemBucketID(id influxdb.ID) bool {
+	return id == influxdb.TasksSystemBucketID || id == influxdb.MonitoringSystemBucketID
+}
+
+// DeleteOrphanedBuckets deletes the data of the orphaned buckets from the
+// engine. The buckets are deleted one at a time, a bucket that fails to be
+// deleted does not prevent the others from being deleted.
+func DeleteOrphanedBuckets(ctx context.Context, deleter BucketDeleter, orphaned []BucketRef) error {
+	var failed int
+	var lastErr error
+	for _, ref := range orphaned {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := deleter.DeleteBucket(ctx, ref.OrgID, ref.BucketID); err != nil {
+			failed++
+			lastErr = err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d orphaned buckets: %v", failed, len(orphaned), lastErr)
+	}
+	return nil
+}
+
+func sortBucketRefs(refs []BucketRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].OrgID != refs[j].OrgID {
+			return refs[i].OrgID < refs[j].OrgID
+		}
+		return refs[i].BucketID < refs[j].BucketID
+	})
+}
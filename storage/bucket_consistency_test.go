@@ -0,0 +1,127 @@
+package storage_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func TestCheckBucketConsistency(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	orgID := influxdb.ID(1)
+	stored := influxdb.ID(10)   // in the kv store and the engine
+	orphaned := influxdb.ID(20) // only in the engine
+	empty := influxdb.ID(30)    // only in the kv store
+
+	for _, bucketID := range []influxdb.ID{stored, orphaned} {
+		err := engine.Engine.WritePoints(context.Background(), []models.Point{models.MustNewPoint(
+			tsdb.EncodeNameString(orgID, bucketID),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu"}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bucketSVC := mock.NewBucketService()
+	bucketSVC.FindBucketsFn = func(context.Context, influxdb.BucketFilter, ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+		return []*influxdb.Bucket{
+			{ID: stored, OrgID: orgID},
+			{ID: empty, OrgID: orgID},
+		}, 2, nil
+	}
+
+	c, err := storage.CheckBucketConsistency(context.Background(), engine.Engine, bucketSVC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := c.Orphaned, []storage.BucketRef{{OrgID: orgID, BucketID: orphaned}}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got orphaned buckets %v, expected %v", got, exp)
+	}
+	if got, exp := c.Empty, []storage.BucketRef{{OrgID: orgID, BucketID: empty}}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got empty buckets %v, expected %v", got, exp)
+	}
+	if c.Consistent() {
+		t.Fatal("expected the buckets to be inconsistent")
+	}
+
+	if err := storage.DeleteOrphanedBuckets(context.Background(), engine.Engine, c.Orphaned); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = storage.CheckBucketConsistency(context.Background(), engine.Engine, bucketSVC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Consistent() {
+		t.Fatalf("expected the orphaned buckets to be deleted, got %v", c.Orphaned)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(1); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+}
+
+func TestCheckBucketConsistency_LegacySystemBuckets(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	// a legacy org has no documents for its system buckets, their data is
+	// kept under the fixed system bucket IDs.
+	legacyOrgID := influxdb.ID(1)
+	bucketID := influxdb.ID(20)
+
+	refs := []storage.BucketRef{
+		{OrgID: legacyOrgID, BucketID: bucketID},
+		{OrgID: legacyOrgID, BucketID: influxdb.TasksSystemBucketID},
+		{OrgID: legacyOrgID, BucketID: influxdb.MonitoringSystemBucketID},
+		{OrgID: 0, BucketID: influxdb.TasksSystemBucketID},
+		{OrgID: 0, BucketID: influxdb.MonitoringSystemBucketID},
+	}
+	for _, ref := range refs {
+		err := engine.Engine.WritePoints(context.Background(), []models.Point{models.MustNewPoint(
+			tsdb.EncodeNameString(ref.OrgID, ref.BucketID),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu"}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the bucket service mocks the system buckets without an org, as the kv
+	// service does for the orgs without system bucket documents.
+	bucketSVC := mock.NewBucketService()
+	bucketSVC.FindBucketsFn = func(context.Context, influxdb.BucketFilter, ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+		return []*influxdb.Bucket{
+			{ID: bucketID, OrgID: legacyOrgID},
+			{ID: influxdb.TasksSystemBucketID, Type: influxdb.BucketTypeSystem, Name: influxdb.TasksSystemBucketName},
+			{ID: influxdb.MonitoringSystemBucketID, Type: influxdb.BucketTypeSystem, Name: influxdb.MonitoringSystemBucketName},
+		}, 3, nil
+	}
+
+	c, err := storage.CheckBucketConsistency(context.Background(), engine.Engine, bucketSVC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Consistent() {
+		t.Fatalf("expected the system buckets not to be orphaned, got %v", c.Orphaned)
+	}
+	if len(c.Empty) != 0 {
+		t.Fatalf("expected no empty buckets, got %v", c.Empty)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/limiter"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/storage/wal"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/tsi1"
@@ -651,6 +652,31 @@ func (e *Engine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID
 	return e.deleteBucketRangeLocked(ctx, orgID, bucketID, min, max, pred)
 }
 
+// DeleteMeasurementRange deletes data of a single measurement within a bucket
+// from the storage engine. Any data deleted must be in [min, max].
+func (e *Engine) DeleteMeasurementRange(ctx context.Context, orgID, bucketID platform.ID, measurement string, min, max int64) error {
+	pred, err := tsm1.NewProtobufPredicate(&datatypes.Predicate{
+		Root: &datatypes.Node{
+			NodeType: datatypes.NodeTypeComparisonExpression,
+			Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+			Children: []*datatypes.Node{
+				{
+					NodeType: datatypes.NodeTypeTagRef,
+					Value:    &datatypes.Node_TagRefValue{TagRefValue: models.MeasurementTagKey},
+				},
+				{
+					NodeType: datatypes.NodeTypeLiteral,
+					Value:    &datatypes.Node_StringValue{StringValue: measurement},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return e.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
+}
+
 // deleteBucketRangeLocked does the work of deleting a bucket range and must be called under
 // some sort of lock.
 func (e *Engine) deleteBucketRangeLocked(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred tsm1.Predicate) error {
@@ -688,6 +714,26 @@ func (e *Engine) ApplyFnToSeriesIDSet(fn func(*tsdb.SeriesIDSet)) {
 	fn(e.index.SeriesIDSet())
 }
 
+// ForEachBucket calls fn with the org and bucket of every bucket the engine
+// holds series for. Only the measurements of the index are read, the data of
+// the buckets is not.
+func (e *Engine) ForEachBucket(fn func(orgID, bucketID platform.ID) error) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	return e.index.ForEachMeasurementName(func(name []byte) error {
+		// every series of the engine is named by its encoded org and bucket
+		if len(name) != 16 {
+			return nil
+		}
+		orgID, bucketID := tsdb.DecodeNameSlice(name)
+		return fn(orgID, bucketID)
+	})
+}
+
 // MeasurementCardinalityStats returns cardinality stats for all measurements.
 func (e *Engine) MeasurementCardinalityStats() (tsi1.MeasurementCardinalityStats, error) {
 	return e.index.MeasurementCardinalityStats()
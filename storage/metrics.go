@@ -32,11 +32,23 @@ const namespace = "storage"
 
 const retentionSubsystem = "retention" // sub-system associated with metrics for writing points.
 
+// maxMeasurementDeletesSeries bounds the number of bucket and measurement
+// label pairs of the measurement deletes metric. Deletes of any further pair
+// are counted under the otherLabelValue bucket and measurement.
+const maxMeasurementDeletesSeries = 100
+
+// otherLabelValue is the label value of the deletes beyond the bound.
+const otherLabelValue = "other"
+
 // retentionMetrics is a set of metrics concerned with tracking data about retention policies.
 type retentionMetrics struct {
-	labels        prometheus.Labels
-	Checks        *prometheus.CounterVec
-	CheckDuration *prometheus.HistogramVec
+	labels             prometheus.Labels
+	Checks             *prometheus.CounterVec
+	CheckDuration      *prometheus.HistogramVec
+	MeasurementDeletes *prometheus.CounterVec
+
+	mu           sync.Mutex
+	measurements map[[2]string]struct{} // bucket and measurement pairs labelled
 }
 
 func newRetentionMetrics(labels prometheus.Labels) *retentionMetrics {
@@ -52,8 +64,12 @@ func newRetentionMetrics(labels prometheus.Labels) *retentionMetrics {
 	checkDurationNames := append(append([]string(nil), names...), "status")
 	sort.Strings(checkDurationNames)
 
+	measurementDeletesNames := append(append([]string(nil), names...), "bucket_id", "measurement", "status")
+	sort.Strings(measurementDeletesNames)
+
 	return &retentionMetrics{
-		labels: labels,
+		labels:       labels,
+		measurements: make(map[[2]string]struct{}),
 		Checks: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: retentionSubsystem,
@@ -69,7 +85,32 @@ func newRetentionMetrics(labels prometheus.Labels) *retentionMetrics {
 			// 25 buckets spaced exponentially between 10s and ~2h
 			Buckets: prometheus.ExponentialBuckets(10, 1.32, 25),
 		}, checkDurationNames),
+
+		MeasurementDeletes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: retentionSubsystem,
+			Name:      "measurement_deletes_total",
+			Help:      "Number of deletes performed to enforce measurement retention policies.",
+		}, measurementDeletesNames),
+	}
+}
+
+// measurementLabels returns the bucket and measurement label values of a
+// delete. Once maxMeasurementDeletesSeries pairs are labelled, the deletes of
+// any other pair are labelled as otherLabelValue.
+func (m *retentionMetrics) measurementLabels(bucketID, measurement string) (string, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := [2]string{bucketID, measurement}
+	if _, ok := m.measurements[key]; ok {
+		return bucketID, measurement
+	}
+	if len(m.measurements) >= maxMeasurementDeletesSeries {
+		return otherLabelValue, otherLabelValue
 	}
+	m.measurements[key] = struct{}{}
+	return bucketID, measurement
 }
 
 // Labels returns a copy of labels for use with retention metrics.
@@ -86,5 +127,6 @@ func (rm *retentionMetrics) PrometheusCollectors() []prometheus.Collector {
 	return []prometheus.Collector{
 		rm.Checks,
 		rm.CheckDuration,
+		rm.MeasurementDeletes,
 	}
 }
@@ -22,6 +22,7 @@ const (
 // A Deleter implementation is capable of deleting data from a storage engine.
 type Deleter interface {
 	DeleteBucketRange(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64) error
+	DeleteMeasurementRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error
 }
 
 // A Snapshotter implementation can take snapshots of the entire engine.
@@ -136,7 +137,7 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 			zap.String("system_type", b.Type.String()),
 		}
 
-		if b.RetentionPeriod == 0 {
+		if b.RetentionPeriod == 0 && len(b.MeasurementRetentionPolicies) == 0 {
 			logger.Debug("Skipping bucket with infinite retention", bucketFields...)
 			skipInf++
 			continue
@@ -146,28 +147,13 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 			continue
 		}
 
-		min := int64(math.MinInt64)
-		max := now.Add(-b.RetentionPeriod).UnixNano()
-
-		span, ctx := tracing.StartSpanFromContext(ctx)
-		span.LogKV(
-			"bucket_id", b.ID,
-			"org_id", b.OrgID,
-			"system_type", b.Type,
-			"retention_period", b.RetentionPeriod,
-			"retention_policy", b.RetentionPolicyName,
-			"from", time.Unix(0, min).UTC(),
-			"to", time.Unix(0, max).UTC(),
-		)
-
-		err := s.Engine.DeleteBucketRange(ctx, b.OrgID, b.ID, min, max)
-		if err != nil {
-			logger.Info("Unable to delete bucket range",
-				append(bucketFields, zap.Time("min", time.Unix(0, min)), zap.Time("max", time.Unix(0, max)), zap.Error(err))...)
-			tracing.LogError(span, err)
+		if b.RetentionPeriod != 0 {
+			s.expireBucket(ctx, logger, b, bucketFields, now)
+		}
+
+		for _, p := range b.MeasurementRetentionPolicies {
+			s.expireMeasurement(ctx, logger, b, p, bucketFields, now)
 		}
-		s.tracker.IncChecks(err == nil)
-		span.Finish()
 	}
 
 	if skipInf > 0 || skipInvalid > 0 {
@@ -175,6 +161,65 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 	}
 }
 
+// expireBucket deletes all data in the bucket that is older than the bucket's
+// retention period.
+func (s *retentionEnforcer) expireBucket(ctx context.Context, logger *zap.Logger, b *influxdb.Bucket, bucketFields []zapcore.Field, now time.Time) {
+	min := int64(math.MinInt64)
+	max := now.Add(-b.RetentionPeriod).UnixNano()
+
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+	span.LogKV(
+		"bucket_id", b.ID,
+		"org_id", b.OrgID,
+		"system_type", b.Type,
+		"retention_period", b.RetentionPeriod,
+		"retention_policy", b.RetentionPolicyName,
+		"from", time.Unix(0, min).UTC(),
+		"to", time.Unix(0, max).UTC(),
+	)
+
+	err := s.Engine.DeleteBucketRange(ctx, b.OrgID, b.ID, min, max)
+	if err != nil {
+		logger.Info("Unable to delete bucket range",
+			append(bucketFields, zap.Time("min", time.Unix(0, min)), zap.Time("max", time.Unix(0, max)), zap.Error(err))...)
+		tracing.LogError(span, err)
+	}
+	s.tracker.IncChecks(err == nil)
+}
+
+// expireMeasurement deletes all data of the policy's measurement in the bucket
+// that is older than the policy's retention period.
+func (s *retentionEnforcer) expireMeasurement(ctx context.Context, logger *zap.Logger, b *influxdb.Bucket, p influxdb.MeasurementRetentionPolicy, bucketFields []zapcore.Field, now time.Time) {
+	min := int64(math.MinInt64)
+	max := now.Add(-p.RetentionPeriod).UnixNano()
+
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+	span.LogKV(
+		"bucket_id", b.ID,
+		"org_id", b.OrgID,
+		"measurement", p.Measurement,
+		"retention_period", p.RetentionPeriod,
+		"from", time.Unix(0, min).UTC(),
+		"to", time.Unix(0, max).UTC(),
+	)
+
+	err := s.Engine.DeleteMeasurementRange(ctx, b.OrgID, b.ID, p.Measurement, min, max)
+	if err != nil {
+		logger.Info("Unable to delete measurement range",
+			append(bucketFields,
+				zap.String("measurement", p.Measurement),
+				zap.Duration("measurement_retention_period", p.RetentionPeriod),
+				zap.Time("min", time.Unix(0, min)),
+				zap.Time("max", time.Unix(0, max)),
+				zap.Error(err),
+			)...)
+		tracing.LogError(span, err)
+	}
+	s.tracker.IncMeasurementDeletes(b.ID, p.Measurement, err == nil)
+}
+
 // getBucketInformation returns a slice of buckets to run retention on.
 func (s *retentionEnforcer) getBucketInformation(ctx context.Context) ([]*influxdb.Bucket, error) {
 	ctx, cancel := context.WithTimeout(ctx, bucketAPITimeout)
@@ -219,6 +264,22 @@ func (t *retentionTracker) IncChecks(success bool) {
 	t.metrics.Checks.With(labels).Inc()
 }
 
+// IncMeasurementDeletes signals that a delete was performed for the retention
+// policy of a measurement within a bucket. The number of buckets and
+// measurements labelled is bounded, see maxMeasurementDeletesSeries.
+func (t *retentionTracker) IncMeasurementDeletes(bucketID influxdb.ID, measurement string, success bool) {
+	labels := t.Labels()
+	labels["bucket_id"], labels["measurement"] = t.metrics.measurementLabels(bucketID.String(), measurement)
+
+	if success {
+		labels["status"] = "ok"
+	} else {
+		labels["status"] = "error"
+	}
+
+	t.metrics.MeasurementDeletes.With(labels).Inc()
+}
+
 // CheckDuration records the overall duration of a full retention check.
 func (t *retentionTracker) CheckDuration(dur time.Duration, success bool) {
 	labels := t.Labels()
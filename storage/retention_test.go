@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/prom/promtest"
 	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
@@ -240,6 +241,77 @@ func TestRetentionService(t *testing.T) {
 	})
 }
 
+func TestRetentionService_MeasurementRetention(t *testing.T) {
+	t.Parallel()
+
+	path := MustTempDir()
+	defer os.RemoveAll(path)
+
+	engine := NewEngine(path, NewConfig(), WithNodeID(102), WithEngineID(33))
+	if err := engine.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+
+	orgID, bucketID := influxdb.ID(1), influxdb.ID(2)
+	now := time.Now().UTC()
+
+	p := func(m, host string, ts time.Time) models.Point {
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(orgID, bucketID),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: m, "host": host}),
+			map[string]interface{}{"value": 1.0},
+			ts,
+		)
+	}
+
+	err := engine.WritePoints(context.Background(), []models.Point{
+		p("cpu", "expired", now.Add(-10*24*time.Hour)),
+		p("cpu", "retained", now.Add(-time.Hour)),
+		p("events", "retained", now.Add(-10*24*time.Hour)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := engine.SeriesCardinality(), int64(3); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	service := newRetentionEnforcer(engine, engine.engine, NewTestBucketFinder())
+	service.expireData(context.Background(), []*influxdb.Bucket{
+		{
+			OrgID: orgID,
+			ID:    bucketID,
+			MeasurementRetentionPolicies: []influxdb.MeasurementRetentionPolicy{
+				{Measurement: "cpu", RetentionPeriod: 7 * 24 * time.Hour},
+				{Measurement: "events", RetentionPeriod: 365 * 24 * time.Hour},
+			},
+		},
+	}, now)
+
+	// Only the expired cpu series should have been removed.
+	if got, exp := engine.SeriesCardinality(), int64(2); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(service.tracker.metrics.PrometheusCollectors()...)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := namespace + "_" + retentionSubsystem + "_measurement_deletes_total"
+	for _, m := range []string{"cpu", "events"} {
+		labels := prometheus.Labels{"bucket_id": bucketID.String(), "measurement": m, "status": "ok"}
+		metric := promtest.MustFindMetric(t, mfs, name, labels)
+		if got, exp := metric.GetCounter().GetValue(), float64(1); got != exp {
+			t.Errorf("[%s %v] got %v, expected %v", name, labels, got, exp)
+		}
+	}
+}
+
 func TestMetrics_Retention(t *testing.T) {
 	t.Parallel()
 	// metrics to be shared by multiple file stores.
@@ -292,6 +364,59 @@ func TestMetrics_Retention(t *testing.T) {
 	}
 }
 
+func TestMetrics_RetentionMeasurementDeletesBounded(t *testing.T) {
+	t.Parallel()
+	metrics := newRetentionMetrics(prometheus.Labels{"engine_id": "", "node_id": ""})
+	tracker := newRetentionTracker(metrics, prometheus.Labels{"engine_id": "0", "node_id": "0"})
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics.PrometheusCollectors()...)
+
+	bucketID := influxdb.ID(1)
+	for i := 0; i < maxMeasurementDeletesSeries+10; i++ {
+		tracker.IncMeasurementDeletes(bucketID, fmt.Sprintf("m%d", i), true)
+	}
+	// A measurement labelled before the bound is reached keeps its labels.
+	tracker.IncMeasurementDeletes(bucketID, "m0", true)
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := namespace + "_" + retentionSubsystem + "_measurement_deletes_total"
+	mf := promtest.MustFindMetric(t, mfs, name, prometheus.Labels{
+		"engine_id":   "0",
+		"node_id":     "0",
+		"bucket_id":   bucketID.String(),
+		"measurement": "m0",
+		"status":      "ok",
+	})
+	if got, exp := mf.GetCounter().GetValue(), float64(2); got != exp {
+		t.Errorf("got %v deletes of m0, expected %v", got, exp)
+	}
+
+	other := promtest.MustFindMetric(t, mfs, name, prometheus.Labels{
+		"engine_id":   "0",
+		"node_id":     "0",
+		"bucket_id":   otherLabelValue,
+		"measurement": otherLabelValue,
+		"status":      "ok",
+	})
+	if got, exp := other.GetCounter().GetValue(), float64(10); got != exp {
+		t.Errorf("got %v deletes beyond the bound, expected %v", got, exp)
+	}
+
+	for _, fam := range mfs {
+		if fam.GetName() != name {
+			continue
+		}
+		if got, exp := len(fam.GetMetric()), maxMeasurementDeletesSeries+1; got != exp {
+			t.Errorf("got %d series, expected %d", got, exp)
+		}
+	}
+}
+
 // genMeasurementName generates a random measurement name or panics.
 func genMeasurementName() []byte {
 	b := make([]byte, 16)
@@ -314,12 +439,16 @@ func (r *MockRunner) run() {
 }
 
 type TestEngine struct {
-	DeleteBucketRangeFn func(context.Context, influxdb.ID, influxdb.ID, int64, int64) error
+	DeleteBucketRangeFn      func(context.Context, influxdb.ID, influxdb.ID, int64, int64) error
+	DeleteMeasurementRangeFn func(context.Context, influxdb.ID, influxdb.ID, string, int64, int64) error
 }
 
 func NewTestEngine() *TestEngine {
 	return &TestEngine{
 		DeleteBucketRangeFn: func(context.Context, influxdb.ID, influxdb.ID, int64, int64) error { return nil },
+		DeleteMeasurementRangeFn: func(context.Context, influxdb.ID, influxdb.ID, string, int64, int64) error {
+			return nil
+		},
 	}
 }
 
@@ -327,6 +456,10 @@ func (e *TestEngine) DeleteBucketRange(ctx context.Context, orgID, bucketID infl
 	return e.DeleteBucketRangeFn(ctx, orgID, bucketID, min, max)
 }
 
+func (e *TestEngine) DeleteMeasurementRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error {
+	return e.DeleteMeasurementRangeFn(ctx, orgID, bucketID, measurement, min, max)
+}
+
 type TestSnapshotter struct{}
 
 func (s *TestSnapshotter) WriteSnapshot(ctx context.Context, status tsm1.CacheStatus) error {
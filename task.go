@@ -62,6 +62,11 @@ type Task struct {
 	CreatedAt       time.Time              `json:"createdAt,omitempty"`
 	UpdatedAt       time.Time              `json:"updatedAt,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+
+	// Warnings are the problems found with the options of the task when it
+	// was created or its script last updated, that do not prevent it from
+	// running.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // EffectiveCron returns the effective cron string of the options.
@@ -0,0 +1,180 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/options"
+)
+
+// fmtChunkCheckpoint is the run log message written after each chunk of a run
+// completes. It is parsed back out of the run log to resume a run from the last
+// completed chunk.
+const fmtChunkCheckpoint = "Completed chunk %d of %d"
+
+// chunk is a sub range of a run's time range that is executed as its own query.
+type chunk struct {
+	start, stop time.Time
+}
+
+// runChunks splits the time range of a run, [scheduledFor-every, scheduledFor),
+// into sequential chunks of the provided interval. The final chunk is truncated
+// to the end of the run's time range.
+func runChunks(scheduledFor time.Time, every, interval time.Duration) []chunk {
+	if every <= 0 || interval <= 0 {
+		return nil
+	}
+
+	var chunks []chunk
+	for start := scheduledFor.Add(-every); start.Before(scheduledFor); start = start.Add(interval) {
+		stop := start.Add(interval)
+		if stop.After(scheduledFor) {
+			stop = scheduledFor
+		}
+		chunks = append(chunks, chunk{start: start, stop: stop})
+	}
+	return chunks
+}
+
+// runRange is the time range of a run.
+type runRange struct {
+	// now is the now of the queries of the run, the time the run is
+	// scheduled for shifted back by the latency option.
+	now time.Time
+	// chunks are the sub ranges the run is split into by the chunkInterval
+	// option. When nil the run is executed as a single query.
+	chunks []chunk
+	// shifted is the time range of a run executed as a single query when
+	// the latency option shifts it, it is injected into the script.
+	shifted *chunk
+}
+
+// newRunRange returns the time range of a run of a task with the options,
+// scheduled for the time. The latency option shifts both the start and the
+// stop of the range back. The range of a cron task is not known, only the now
+// of its queries is shifted.
+func newRunRange(scheduledFor time.Time, opts options.Options) runRange {
+	r := runRange{now: scheduledFor}
+	if opts.Latency != nil {
+		if latency, err := opts.Latency.DurationFrom(scheduledFor); err == nil && latency > 0 {
+			r.now = scheduledFor.Add(-latency)
+		}
+	}
+
+	every, err := opts.Every.DurationFrom(scheduledFor)
+	if err != nil || every <= 0 {
+		return r
+	}
+
+	if opts.ChunkInterval != nil {
+		if interval, err := opts.ChunkInterval.DurationFrom(scheduledFor); err == nil {
+			r.chunks = runChunks(r.now, every, interval)
+		}
+		return r
+	}
+
+	if !r.now.Equal(scheduledFor) {
+		r.shifted = &chunk{start: r.now.Add(-every), stop: r.now}
+	}
+	return r
+}
+
+// chunkCheckpoint is the run log message for the ith, zero based, chunk of total chunks.
+func chunkCheckpoint(i, total int, c chunk) string {
+	return fmt.Sprintf(fmtChunkCheckpoint+": [%s, %s)", i+1, total, c.start.Format(time.RFC3339), c.stop.Format(time.RFC3339))
+}
+
+// completedChunks returns the number of chunks a run has already completed,
+// as checkpointed in its run log. Checkpoints written for a different number
+// of chunks, i.e. the task's every or chunkInterval changed, are ignored.
+func completedChunks(logs []influxdb.Log, total int) int {
+	var completed int
+	for _, l := range logs {
+		var i, n int
+		if _, err := fmt.Sscanf(l.Message, fmtChunkCheckpoint, &i, &n); err != nil {
+			continue
+		}
+		if n == total && i > completed && i <= total {
+			completed = i
+		}
+	}
+	return completed
+}
+
+// usesTimeRange reports whether the script reads v.timeRangeStart or
+// v.timeRangeStop. The chunks of a script that reads neither would each query
+// the full range the script queries for itself, such a run is not chunked.
+func usesTimeRange(script string) bool {
+	pkg, err := flux.Parse(script)
+	if err != nil {
+		return false
+	}
+
+	var uses bool
+	ast.Walk(ast.CreateVisitor(func(node ast.Node) {
+		me, ok := node.(*ast.MemberExpression)
+		if !ok {
+			return
+		}
+		obj, ok := me.Object.(*ast.Identifier)
+		if !ok || obj.Name != "v" {
+			return
+		}
+		switch prop := me.Property.(type) {
+		case *ast.Identifier:
+			uses = uses || prop.Name == "timeRangeStart" || prop.Name == "timeRangeStop"
+		case *ast.StringLiteral:
+			uses = uses || prop.Value == "timeRangeStart" || prop.Value == "timeRangeStop"
+		}
+	}), pkg)
+	return uses
+}
+
+// chunkScript returns the script with the chunk's time range injected as the
+// v.timeRangeStart and v.timeRangeStop options. The option is declared after
+// the last option statement of the script, overriding any default time range
+// the script declares for itself.
+func chunkScript(script string, c chunk) (string, error) {
+	pkg, err := flux.Parse(script)
+	if err != nil {
+		return "", err
+	}
+	if len(pkg.Files) == 0 {
+		return "", fmt.Errorf("script contains no files")
+	}
+
+	opt := &ast.OptionStatement{
+		Assignment: &ast.VariableAssignment{
+			ID: &ast.Identifier{Name: "v"},
+			Init: &ast.ObjectExpression{
+				Properties: []*ast.Property{
+					{
+						Key:   &ast.Identifier{Name: "timeRangeStart"},
+						Value: &ast.DateTimeLiteral{Value: c.start.UTC()},
+					},
+					{
+						Key:   &ast.Identifier{Name: "timeRangeStop"},
+						Value: &ast.DateTimeLiteral{Value: c.stop.UTC()},
+					},
+				},
+			},
+		},
+	}
+
+	file := pkg.Files[0]
+	idx := 0
+	for i, stmt := range file.Body {
+		if _, ok := stmt.(*ast.OptionStatement); ok {
+			idx = i + 1
+		}
+	}
+
+	body := make([]ast.Statement, 0, len(file.Body)+1)
+	body = append(body, file.Body[:idx]...)
+	body = append(body, opt)
+	file.Body = append(body, file.Body[idx:]...)
+	return ast.Format(file), nil
+}
@@ -13,6 +13,7 @@ import (
 	icontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/notification/rule"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/task/backend"
 	"go.uber.org/zap"
@@ -50,6 +51,41 @@ func AddTaskService(e backend.Executor, ts influxdb.TaskService) {
 	}
 }
 
+// AddMaintenanceWindowMuter sets the service consulted for the maintenance
+// windows muting the notifications of notification rule tasks, for executors
+// that support it.
+func AddMaintenanceWindowMuter(e backend.Executor, mws influxdb.MaintenanceWindowMuter) {
+	if ae, ok := e.(*asyncQueryServiceExecutor); ok {
+		ae.mws = mws
+	}
+}
+
+// muteRule returns what the maintenance windows active for a notification rule
+// task mute of its run, recorded in the history of the run. The run is not
+// muted when the windows cannot be found, a missed notification is worse than
+// an extra one.
+func muteRule(ctx context.Context, log *zap.Logger, mws influxdb.MaintenanceWindowMuter, taskID influxdb.ID, hist *rule.History) *influxdb.NotificationRuleMute {
+	if mws == nil {
+		return nil
+	}
+
+	m, err := mws.FindNotificationRuleMute(ctx, taskID, time.Now().UTC())
+	if err != nil {
+		log.Error("Failed to find maintenance windows muting notification rule", zap.Error(err))
+		return nil
+	}
+	hist.Mute(m)
+	return m
+}
+
+// AddNotificationRuleHistoryService sets the service the outcomes of the runs of
+// notification rule tasks are recorded to, for executors that support it.
+func AddNotificationRuleHistoryService(e backend.Executor, nrhs influxdb.NotificationRuleHistoryService) {
+	if ae, ok := e.(*asyncQueryServiceExecutor); ok {
+		ae.nrhs = nrhs
+	}
+}
+
 func (e *queryServiceExecutor) Execute(ctx context.Context, run backend.QueuedRun) (backend.RunPromise, error) {
 	t, err := e.ts.FindTaskByID(ctx, run.TaskID)
 	if err != nil {
@@ -146,6 +182,31 @@ func (p *syncRunPromise) finish(res *runResult, err error) {
 	})
 }
 
+// recordRuleHistory records the outcome of a run of a notification rule task.
+// Each status matched by the rule results in a notification being sent, so the
+// statuses matched are the notifications attempted.
+func (p *asyncRunPromise) recordRuleHistory(res *runResult, err error) {
+	attempts := p.hist.Attempts()
+	e := &influxdb.NotificationRuleHistoryEntry{
+		TaskID:          p.qr.TaskID,
+		RunID:           p.qr.RunID,
+		Time:            time.Now().UTC(),
+		StatusesMatched: len(attempts),
+		Notifications:   attempts,
+		Muted:           p.hist.Mutes(),
+	}
+	if err == nil && res != nil {
+		err = res.err
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	if err := p.nrhs.AddNotificationRuleHistory(p.ctx, e); err != nil {
+		p.log.Error("Failed to record notification rule history", zap.Error(err))
+	}
+}
+
 func (p *syncRunPromise) doQuery(wg *sync.WaitGroup) {
 	defer wg.Done()
 
@@ -208,11 +269,13 @@ func (p *syncRunPromise) cancelOnContextDone(wg *sync.WaitGroup) {
 
 // asyncQueryServiceExecutor is an implementation of backend.Executor that depends on an AsyncQueryService.
 type asyncQueryServiceExecutor struct {
-	qs  query.AsyncQueryService
-	as  influxdb.AuthorizationService
-	ts  influxdb.TaskService
-	log *zap.Logger
-	wg  sync.WaitGroup
+	qs   query.AsyncQueryService
+	as   influxdb.AuthorizationService
+	ts   influxdb.TaskService
+	nrhs influxdb.NotificationRuleHistoryService
+	mws  influxdb.MaintenanceWindowMuter
+	log  *zap.Logger
+	wg   sync.WaitGroup
 }
 
 var _ backend.Executor = (*asyncQueryServiceExecutor)(nil)
@@ -246,6 +309,13 @@ type asyncRunPromise struct {
 	log    *zap.Logger
 	logEnd func() // Called to log the end of the run operation.
 
+	// hist collects the notifications sent when the task executes a
+	// notification rule, recorded to nrhs once the run finishes.
+	hist *rule.History
+	nrhs influxdb.NotificationRuleHistoryService
+	// mute is what the maintenance windows mute of the notification rule.
+	mute *influxdb.NotificationRuleMute
+
 	finishOnce sync.Once     // Ensure we set the values only once.
 	ready      chan struct{} // Closed inside finish. Indicates Wait will no longer block.
 	res        *runResult
@@ -271,6 +341,11 @@ func newAsyncRunPromise(ctx context.Context, auth *influxdb.Authorization, qr ba
 		ctx:    ctx,
 		ready:  make(chan struct{}),
 	}
+	if (e.nrhs != nil || e.mws != nil) && rule.IsType(t.Type) {
+		p.ctx, p.hist = rule.NewHistoryContext(ctx)
+		p.nrhs = e.nrhs
+		p.mute = muteRule(p.ctx, log, e.mws, qr.TaskID, p.hist)
+	}
 
 	e.wg.Add(1)
 	go p.doQuery(&e.wg)
@@ -306,6 +381,7 @@ func (p *asyncRunPromise) doQuery(wg *sync.WaitGroup) {
 		p.finish(nil, err)
 		return
 	}
+	rule.MuteNotifications(pkg, p.mute)
 
 	req := &query.Request{
 		Authorization:  p.t.Authorization,
@@ -377,6 +453,10 @@ func (p *asyncRunPromise) finish(res *runResult, err error) {
 		} else {
 			p.log.Debug("Completed successfully")
 		}
+
+		if p.nrhs != nil {
+			p.recordRuleHistory(res, err)
+		}
 	})
 }
 
@@ -18,6 +18,7 @@ type ExecutorMetrics struct {
 	resumeRunsCounter    *prometheus.CounterVec
 	unrecoverableCounter *prometheus.CounterVec
 	runLatency           *prometheus.HistogramVec
+	chunksComplete       *prometheus.CounterVec
 }
 
 type runCollector struct {
@@ -91,6 +92,13 @@ func NewExecutorMetrics(te *TaskExecutor) *ExecutorMetrics {
 			Name:      "run_latency_seconds",
 			Help:      "Records the latency between the time the run was due to run and the time the task started execution, by task type",
 		}, []string{"task_type"}),
+
+		chunksComplete: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "chunks_complete",
+			Help:      "Total number of run chunks completed for tasks that split their runs with the chunkInterval option.",
+		}, []string{"task_type", "taskID"}),
 	}
 }
 
@@ -131,6 +139,7 @@ func (em *ExecutorMetrics) PrometheusCollectors() []prometheus.Collector {
 		em.resumeRunsCounter,
 		em.unrecoverableCounter,
 		em.runLatency,
+		em.chunksComplete,
 	}
 }
 
@@ -151,6 +160,12 @@ func (em *ExecutorMetrics) FinishRun(task *influxdb.Task, status backend.RunStat
 	em.runDuration.WithLabelValues("", task.ID.String()).Observe(runDuration.Seconds())
 }
 
+// ChunkCompleted increments the count of run chunks completed for the given task.
+func (em *ExecutorMetrics) ChunkCompleted(task *influxdb.Task) {
+	em.chunksComplete.WithLabelValues(task.Type, "all").Inc()
+	em.chunksComplete.WithLabelValues("", task.ID.String()).Inc()
+}
+
 // LogError increments the count of errors by error code.
 func (em *ExecutorMetrics) LogError(taskType string, err error) {
 	switch e := err.(type) {
@@ -8,13 +8,17 @@ import (
 	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/influxdb"
 	icontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/notification/rule"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/snowflake"
 	"github.com/influxdata/influxdb/task/backend"
 	"github.com/influxdata/influxdb/task/backend/scheduler"
+	"github.com/influxdata/influxdb/task/options"
 	"go.uber.org/zap"
 )
 
@@ -51,6 +55,8 @@ func NewExecutor(log *zap.Logger, qs query.QueryService, as influxdb.Authorizati
 		qs:  qs,
 		as:  as,
 
+		idGen: snowflake.NewDefaultIDGenerator(),
+
 		currentPromises: sync.Map{},
 		promiseQueue:    make(chan *promise, 1000),                                //TODO(lh): make this configurable
 		workerLimit:     make(chan struct{}, 100),                                 //TODO(lh): make this configurable
@@ -76,6 +82,9 @@ type TaskExecutor struct {
 	qs query.QueryService
 	as influxdb.AuthorizationService
 
+	// idGen generates the IDs of the runs of scripts not saved as tasks.
+	idGen influxdb.IDGenerator
+
 	metrics *ExecutorMetrics
 
 	// currentPromises are all the promises we are made that have not been fulfilled
@@ -86,6 +95,9 @@ type TaskExecutor struct {
 
 	limitFunc LimitFunc
 
+	nrhs influxdb.NotificationRuleHistoryService
+	mws  influxdb.MaintenanceWindowMuter
+
 	// keep a pool of execution workers.
 	workerPool  sync.Pool
 	workerLimit chan struct{}
@@ -96,6 +108,18 @@ func (e *TaskExecutor) SetLimitFunc(l LimitFunc) {
 	e.limitFunc = l
 }
 
+// SetNotificationRuleHistoryService sets the service the outcomes of the runs of
+// notification rule tasks are recorded to.
+func (e *TaskExecutor) SetNotificationRuleHistoryService(nrhs influxdb.NotificationRuleHistoryService) {
+	e.nrhs = nrhs
+}
+
+// SetMaintenanceWindowMuter sets the service consulted for the maintenance
+// windows muting the notifications of notification rule tasks.
+func (e *TaskExecutor) SetMaintenanceWindowMuter(mws influxdb.MaintenanceWindowMuter) {
+	e.mws = mws
+}
+
 // Execute is a executor to satisfy the needs of tasks
 func (e *TaskExecutor) Execute(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) error {
 	_, err := e.PromisedExecute(ctx, id, scheduledFor, runAt)
@@ -119,6 +143,88 @@ func (e *TaskExecutor) PromisedExecute(ctx context.Context, id scheduler.ID, sch
 	return p, nil
 }
 
+// RunResult is the outcome of a run executed synchronously.
+type RunResult struct {
+	RunID  influxdb.ID
+	Status backend.RunStatus
+	// Statistics are those of each query of the run, a run split in chunks
+	// queries once per chunk.
+	Statistics []flux.Statistics
+}
+
+// ExecuteSync runs the task for the scheduledFor time and waits for the run to
+// finish. The run is recorded as any scheduled run is. The run is canceled
+// when the ctx is done before it finishes, the error of the ctx is returned
+// then. The error of a run that fails is returned along with its result.
+func (e *TaskExecutor) ExecuteSync(ctx context.Context, id influxdb.ID, scheduledFor time.Time, runAt time.Time) (RunResult, error) {
+	p, err := e.createRun(ctx, id, scheduledFor, runAt)
+	if err != nil {
+		return RunResult{}, err
+	}
+	e.startWorker()
+
+	return e.wait(ctx, p)
+}
+
+// ExecuteScriptSync is ExecuteSync for a script not saved as a task, i.e. a
+// task being written or a preview of one. The script runs within the org with
+// the authorization of the ctx. Neither a task nor the run is persisted, the
+// run is only counted towards the metrics of the executor.
+func (e *TaskExecutor) ExecuteScriptSync(ctx context.Context, orgID influxdb.ID, script string, scheduledFor time.Time, runAt time.Time) (RunResult, error) {
+	if script == "" {
+		return RunResult{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "script to execute is empty",
+		}
+	}
+
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return RunResult{}, err
+	}
+	auth, ok := a.(*influxdb.Authorization)
+	if !ok {
+		return RunResult{}, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "a script is executed with the authorization of a token",
+		}
+	}
+
+	run := &influxdb.Run{
+		ID:           e.idGen.ID(),
+		Status:       backend.RunScheduled.String(),
+		ScheduledFor: scheduledFor.UTC(),
+		RunAt:        runAt.UTC(),
+	}
+	task := &influxdb.Task{
+		OrganizationID: orgID,
+		OwnerID:        auth.GetUserID(),
+		Flux:           script,
+		Authorization:  auth,
+	}
+	p := e.queuePromise(ctx, run, task, discardRuns{})
+	e.startWorker()
+
+	return e.wait(ctx, p)
+}
+
+// wait waits for the run of the promise to finish, the run is canceled when
+// the ctx is done first.
+func (e *TaskExecutor) wait(ctx context.Context, p *promise) (RunResult, error) {
+	select {
+	case <-p.Done():
+	case <-ctx.Done():
+		p.Cancel(ctx)
+		return RunResult{RunID: p.ID(), Status: backend.RunCanceled}, ctx.Err()
+	}
+
+	return RunResult{
+		RunID:      p.ID(),
+		Status:     p.status,
+		Statistics: p.stats,
+	}, p.err
+}
+
 func (e *TaskExecutor) ManualRun(ctx context.Context, id influxdb.ID, runID influxdb.ID) (Promise, error) {
 	// create promises for any manual runs
 	r, err := e.tcs.StartManualRun(ctx, id, runID)
@@ -210,12 +316,19 @@ func (e *TaskExecutor) createPromise(ctx context.Context, run *influxdb.Run) (*p
 		return nil, err
 	}
 
+	return e.queuePromise(ctx, run, t, e.tcs), nil
+}
+
+// queuePromise queues the run of the task, the progress of the run is recorded
+// with runs.
+func (e *TaskExecutor) queuePromise(ctx context.Context, run *influxdb.Run, t *influxdb.Task, runs runRecorder) *promise {
 	ctx, cancel := context.WithCancel(ctx)
 	// create promise
 	p := &promise{
 		run:        run,
 		task:       t,
 		auth:       t.Authorization,
+		runs:       runs,
 		createdAt:  time.Now().UTC(),
 		done:       make(chan struct{}),
 		ctx:        ctx,
@@ -228,7 +341,7 @@ func (e *TaskExecutor) createPromise(ctx context.Context, run *influxdb.Run) (*p
 
 	// insert the promise into the registry
 	e.currentPromises.Store(run.ID, p)
-	return p, nil
+	return p
 }
 
 type workerMaker struct {
@@ -273,14 +386,15 @@ func (w *worker) work() {
 			}
 
 			// add to the run log
-			w.te.tcs.AddRunLog(prom.ctx, prom.task.ID, prom.run.ID, time.Now().UTC(), fmt.Sprintf("Task limit reached: %s", err.Error()))
+			prom.runs.AddRunLog(prom.ctx, prom.task.ID, prom.run.ID, time.Now().UTC(), fmt.Sprintf("Task limit reached: %s", err.Error()))
 
 			// sleep
 			select {
 			// If done the promise was canceled
 			case <-prom.ctx.Done():
-				w.te.tcs.AddRunLog(prom.ctx, prom.task.ID, prom.run.ID, time.Now().UTC(), "Run canceled")
-				w.te.tcs.UpdateRunState(prom.ctx, prom.task.ID, prom.run.ID, time.Now().UTC(), backend.RunCanceled)
+				prom.runs.AddRunLog(prom.ctx, prom.task.ID, prom.run.ID, time.Now().UTC(), "Run canceled")
+				prom.runs.UpdateRunState(prom.ctx, prom.task.ID, prom.run.ID, time.Now().UTC(), backend.RunCanceled)
+				prom.status = backend.RunCanceled
 				prom.err = influxdb.ErrRunCanceled
 				close(prom.done)
 				return
@@ -305,9 +419,9 @@ func (w *worker) start(p *promise) {
 	defer span.Finish()
 
 	// add to run log
-	w.te.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Started task from script: %q", p.task.Flux))
+	p.runs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Started task from script: %q", p.task.Flux))
 	// update run status
-	w.te.tcs.UpdateRunState(ctx, p.task.ID, p.run.ID, time.Now().UTC(), backend.RunStarted)
+	p.runs.UpdateRunState(ctx, p.task.ID, p.run.ID, time.Now().UTC(), backend.RunStarted)
 
 	// add to metrics
 	w.te.metrics.StartRun(p.task, time.Since(p.createdAt), time.Since(p.run.RunAt))
@@ -321,17 +435,18 @@ func (w *worker) finish(p *promise, rs backend.RunStatus, err error) {
 	defer span.Finish()
 
 	// add to run log
-	w.te.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Completed(%s)", rs.String()))
+	p.runs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Completed(%s)", rs.String()))
 	// update run status
-	w.te.tcs.UpdateRunState(ctx, p.task.ID, p.run.ID, time.Now().UTC(), rs)
+	p.runs.UpdateRunState(ctx, p.task.ID, p.run.ID, time.Now().UTC(), rs)
 
 	// add to metrics
 	rd := time.Since(p.startedAt)
 	w.te.metrics.FinishRun(p.task, rs, rd)
+	p.status = rs
 
 	// log error
 	if err != nil {
-		w.te.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), err.Error())
+		p.runs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), err.Error())
 		w.te.log.Debug("Execution failed", zap.Error(err), zap.String("taskID", p.task.ID.String()))
 		w.te.metrics.LogError(p.task.Type, err)
 
@@ -342,7 +457,7 @@ func (w *worker) finish(p *promise, rs backend.RunStatus, err error) {
 			// w.te.ts.UpdateTask(p.ctx, p.task.ID, influxdb.TaskUpdate{Status: &inactive})
 
 			// and add to run logs
-			w.te.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Task encountered unrecoverable error, requires admin action: %v", err.Error()))
+			p.runs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Task encountered unrecoverable error, requires admin action: %v", err.Error()))
 			// add to metrics
 			w.te.metrics.LogUnrecoverableError(p.task.ID, err)
 		}
@@ -352,7 +467,7 @@ func (w *worker) finish(p *promise, rs backend.RunStatus, err error) {
 		w.te.log.Debug("Completed successfully", zap.String("taskID", p.task.ID.String()))
 	}
 
-	if _, err := w.te.tcs.FinishRun(p.ctx, p.task.ID, p.run.ID); err != nil {
+	if _, err := p.runs.FinishRun(p.ctx, p.task.ID, p.run.ID); err != nil {
 		w.te.log.Error("Failed to finish run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
 	}
 }
@@ -364,28 +479,127 @@ func (w *worker) executeQuery(p *promise) {
 	// start
 	w.start(p)
 
-	pkg, err := flux.Parse(p.task.Flux)
-	if err != nil {
-		w.finish(p, backend.RunFail, influxdb.ErrFluxParseError(err))
+	ctx = icontext.SetAuthorizer(ctx, p.task.Authorization)
+
+	var mute *influxdb.NotificationRuleMute
+	if (w.te.nrhs != nil || w.te.mws != nil) && rule.IsType(p.task.Type) {
+		var hist *rule.History
+		ctx, hist = rule.NewHistoryContext(ctx)
+		mute = muteRule(ctx, w.te.log.With(zap.String("taskID", p.task.ID.String())), w.te.mws, p.task.ID, hist)
+		if w.te.nrhs != nil {
+			defer w.recordRuleHistory(p, hist)
+		}
+	}
+
+	rr := w.runRange(p)
+	chunks := rr.chunks
+	if len(chunks) == 0 {
+		script := p.task.Flux
+		if rr.shifted != nil {
+			var err error
+			if script, err = chunkScript(script, *rr.shifted); err != nil {
+				w.finish(p, backend.RunFail, influxdb.ErrFluxParseError(err))
+				return
+			}
+		}
+
+		pkg, err := flux.Parse(script)
+		if err != nil {
+			w.finish(p, backend.RunFail, influxdb.ErrFluxParseError(err))
+			return
+		}
+		rule.MuteNotifications(pkg, mute)
+
+		if err := w.query(ctx, p, pkg, rr.now); err != nil {
+			w.finish(p, backend.RunFail, err)
+			return
+		}
+
+		w.finish(p, backend.RunSuccess, nil)
 		return
 	}
 
-	sf := p.run.ScheduledFor
+	// resume from the last checkpointed chunk, if the run has any
+	for i := completedChunks(p.run.Log, len(chunks)); i < len(chunks); i++ {
+		script, err := chunkScript(p.task.Flux, chunks[i])
+		if err != nil {
+			w.finish(p, backend.RunFail, influxdb.ErrFluxParseError(err))
+			return
+		}
+
+		pkg, err := flux.Parse(script)
+		if err != nil {
+			w.finish(p, backend.RunFail, influxdb.ErrFluxParseError(err))
+			return
+		}
+		rule.MuteNotifications(pkg, mute)
 
+		if err := w.query(ctx, p, pkg, rr.now); err != nil {
+			w.finish(p, backend.RunFail, err)
+			return
+		}
+
+		// checkpoint the completed chunk so a retry picks up where this left off
+		p.runs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), chunkCheckpoint(i, len(chunks), chunks[i]))
+		w.te.metrics.ChunkCompleted(p.task)
+	}
+
+	w.finish(p, backend.RunSuccess, nil)
+}
+
+// recordRuleHistory records the outcome of a run of a notification rule task.
+// Each status matched by the rule results in a notification being sent, so the
+// statuses matched are the notifications attempted.
+func (w *worker) recordRuleHistory(p *promise, hist *rule.History) {
+	attempts := hist.Attempts()
+	e := &influxdb.NotificationRuleHistoryEntry{
+		TaskID:          p.task.ID,
+		RunID:           p.run.ID,
+		Time:            time.Now().UTC(),
+		StatusesMatched: len(attempts),
+		Notifications:   attempts,
+		Muted:           hist.Mutes(),
+	}
+	if p.err != nil {
+		e.Error = p.err.Error()
+	}
+
+	if err := w.te.nrhs.AddNotificationRuleHistory(p.ctx, e); err != nil {
+		w.te.log.Error("Failed to record notification rule history", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
+	}
+}
+
+// runRange returns the time range of the run, as derived from the options of
+// its task.
+func (w *worker) runRange(p *promise) runRange {
+	opts, err := options.FromScript(p.task.Flux)
+	if err != nil {
+		return runRange{now: p.run.ScheduledFor}
+	}
+	rr := newRunRange(p.run.ScheduledFor, opts)
+	if len(rr.chunks) > 0 && !usesTimeRange(p.task.Flux) {
+		w.te.log.Debug("Running chunked task unchunked, its script does not read the run time range", zap.String("taskID", p.task.ID.String()))
+		rr.chunks = nil
+	}
+	return rr
+}
+
+// query executes the provided flux AST for the run at the provided now,
+// draining its results. The error returned is suitable for finishing the run
+// with.
+func (w *worker) query(ctx context.Context, p *promise, pkg *ast.Package, now time.Time) error {
 	req := &query.Request{
 		Authorization:  p.auth,
 		OrganizationID: p.task.OrganizationID,
 		Compiler: lang.ASTCompiler{
 			AST: pkg,
-			Now: sf,
+			Now: now,
 		},
 	}
-	ctx = icontext.SetAuthorizer(ctx, p.task.Authorization)
 	it, err := w.te.qs.Query(ctx, req)
 	if err != nil {
 		// Assume the error should not be part of the runResult.
-		w.finish(p, backend.RunFail, influxdb.ErrQueryError(err))
-		return
+		return influxdb.ErrQueryError(err)
 	}
 
 	var runErr error
@@ -402,23 +616,22 @@ func (w *worker) executeQuery(p *promise) {
 
 	// log the statistics on the run
 	stats := it.Statistics()
+	p.stats = append(p.stats, stats)
 
 	b, err := json.Marshal(stats)
 	if err == nil {
-		w.te.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), string(b))
+		p.runs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), string(b))
 	}
 
 	if runErr != nil {
-		w.finish(p, backend.RunFail, influxdb.ErrRunExecutionError(runErr))
-		return
+		return influxdb.ErrRunExecutionError(runErr)
 	}
 
 	if it.Err() != nil {
-		w.finish(p, backend.RunFail, influxdb.ErrResultIteratorError(it.Err()))
-		return
+		return influxdb.ErrResultIteratorError(it.Err())
 	}
 
-	w.finish(p, backend.RunSuccess, nil)
+	return nil
 }
 
 // RunsActive returns the current number of workers, which is equivalent to
@@ -437,15 +650,43 @@ func (e *TaskExecutor) PromiseQueueUsage() float64 {
 	return float64(len(e.promiseQueue)) / float64(cap(e.promiseQueue))
 }
 
+// runRecorder records the progress of a run.
+type runRecorder interface {
+	AddRunLog(ctx context.Context, taskID, runID influxdb.ID, when time.Time, log string) error
+	UpdateRunState(ctx context.Context, taskID, runID influxdb.ID, when time.Time, state backend.RunStatus) error
+	FinishRun(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error)
+}
+
+// discardRuns records nothing, the runs of a script not saved as a task have no
+// task to be recorded against.
+type discardRuns struct{}
+
+func (discardRuns) AddRunLog(context.Context, influxdb.ID, influxdb.ID, time.Time, string) error {
+	return nil
+}
+
+func (discardRuns) UpdateRunState(context.Context, influxdb.ID, influxdb.ID, time.Time, backend.RunStatus) error {
+	return nil
+}
+
+func (discardRuns) FinishRun(context.Context, influxdb.ID, influxdb.ID) (*influxdb.Run, error) {
+	return nil, nil
+}
+
 // promise represents a promise the executor makes to finish a run's execution asynchronously.
 type promise struct {
 	run  *influxdb.Run
 	task *influxdb.Task
 	auth *influxdb.Authorization
+	runs runRecorder
 
 	done chan struct{}
 	err  error
 
+	// status and stats are set by the worker before done is closed.
+	status backend.RunStatus
+	stats  []flux.Statistics
+
 	createdAt time.Time
 	startedAt time.Time
 
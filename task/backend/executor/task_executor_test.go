@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -19,6 +20,7 @@ import (
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/task/backend"
 	"github.com/influxdata/influxdb/task/backend/scheduler"
+	"github.com/influxdata/influxdb/task/options"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -58,6 +60,11 @@ func TestTaskExecutor(t *testing.T) {
 	t.Run("Metrics", testMetrics)
 	t.Run("IteratorFailure", testIteratorFailure)
 	t.Run("ErrorHandling", testErrorHandling)
+	t.Run("ChunkedRun", testChunkedRun)
+	t.Run("ChunkedRunWithoutTimeRange", testChunkedRunWithoutTimeRange)
+	t.Run("ExecuteSync", testExecuteSync)
+	t.Run("ExecuteSyncCanceled", testExecuteSyncCanceled)
+	t.Run("ExecuteScriptSync", testExecuteScriptSync)
 }
 
 func testQuerySuccess(t *testing.T) {
@@ -488,6 +495,360 @@ func testErrorHandling(t *testing.T) {
 	*/
 }
 
+const fmtChunkedTestScript = `
+option task = {
+			name: %q,
+			every: 1m,
+			chunkInterval: 15s,
+}
+
+option v = {timeRangeStart: -1m, timeRangeStop: now()}
+
+from(bucket: "one") |> range(start: v.timeRangeStart, stop: v.timeRangeStop) |> to(bucket: "two", orgID: "0000000000000000")`
+
+func testChunkedRun(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	metrics := tes.metrics
+	reg := prom.NewRegistry(zaptest.NewLogger(t))
+	reg.MustRegister(metrics.PrometheusCollectors()...)
+
+	script := fmt.Sprintf(fmtChunkedTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheduledFor := time.Unix(123, 0)
+	chunks := runChunks(scheduledFor, time.Minute, 15*time.Second)
+	if len(chunks) != 4 {
+		t.Fatalf("expected 4 chunks, got %d", len(chunks))
+	}
+
+	chunkScripts := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		cs, err := chunkScript(script, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunkScripts = append(chunkScripts, cs)
+	}
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), scheduledFor, time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first two chunks succeed and the third fails, failing the run
+	for _, cs := range chunkScripts[:2] {
+		tes.svc.WaitForQueryLive(t, cs)
+		tes.svc.SucceedQuery(cs)
+	}
+	tes.svc.WaitForQueryLive(t, chunkScripts[2])
+
+	failedRun, err := tes.i.FindRunByID(context.Background(), task.ID, promise.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := completedChunks(failedRun.Log, len(chunks)); got != 2 {
+		t.Fatalf("expected 2 checkpointed chunks, got %d", got)
+	}
+
+	tes.svc.FailQuery(chunkScripts[2], errors.New("blargyblargblarg"))
+	<-promise.Done()
+
+	if got := promise.Error(); got == nil {
+		t.Fatal("got no error when I should have")
+	}
+
+	// retry the run, carrying over the checkpoints from the failed run's log
+	retryRun, err := tes.i.CreateRun(ctx, task.ID, scheduledFor, time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range failedRun.Log {
+		if err := tes.i.AddRunLog(ctx, task.ID, retryRun.ID, time.Now(), l.Message); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	promise, err = tes.ex.ResumeCurrentRun(ctx, task.ID, retryRun.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the retry resumes with the third chunk, the first chunk is never queried again
+	for _, cs := range chunkScripts[2:] {
+		tes.svc.WaitForQueryLive(t, cs)
+		tes.svc.SucceedQuery(cs)
+	}
+	<-promise.Done()
+
+	if got := promise.Error(); got != nil {
+		t.Fatal(got)
+	}
+
+	mg := promtest.MustGather(t, reg)
+	m := promtest.MustFindMetric(t, mg, "task_executor_chunks_complete", map[string]string{"task_type": "", "taskID": task.ID.String()})
+	if got := *m.Counter.Value; got != 4 {
+		t.Fatalf("expected 4 chunks completed, got %v", got)
+	}
+}
+
+func testChunkedRunWithoutTimeRange(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	// every chunk of the script would query the same minute, it is queried
+	// once as is, no chunk of it is ever queried
+	script := fmt.Sprintf(`
+option task = {
+			name: %q,
+			every: 1m,
+			chunkInterval: 15s,
+}
+
+from(bucket: "one") |> range(start: -1m) |> to(bucket: "two", orgID: "0000000000000000")`, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.SucceedQuery(script)
+	<-promise.Done()
+
+	if got := promise.Error(); got != nil {
+		t.Fatal(got)
+	}
+}
+
+func testExecuteSync(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		res RunResult
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		res, err := tes.ex.ExecuteSync(ctx, task.ID, time.Unix(123, 0), time.Unix(126, 0))
+		done <- result{res, err}
+	}()
+
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.SucceedQuery(script)
+
+	got := <-done
+	if got.err != nil {
+		t.Fatal(got.err)
+	}
+	if !got.res.RunID.Valid() {
+		t.Fatal("expected the id of the run")
+	}
+	if got.res.Status != backend.RunSuccess {
+		t.Fatalf("expected the run to succeed, got %s", got.res.Status)
+	}
+	if len(got.res.Statistics) != 1 {
+		t.Fatalf("expected the statistics of the query, got %d", len(got.res.Statistics))
+	}
+
+	// the run is finished as a scheduled run is
+	if _, err := tes.i.FindRunByID(context.Background(), task.ID, got.res.RunID); err == nil {
+		t.Fatal("run was returned when it should have been removed from kv")
+	}
+}
+
+func testExecuteSyncCanceled(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		res RunResult
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		res, err := tes.ex.ExecuteSync(ctx, task.ID, time.Unix(123, 0), time.Unix(126, 0))
+		done <- result{res, err}
+	}()
+
+	// the query never finishes, the wait ends with the ctx
+	tes.svc.WaitForQueryLive(t, script)
+	cancel()
+
+	select {
+	case got := <-done:
+		if got.err != context.Canceled {
+			t.Fatalf("expected the error of the ctx, got %v", got.err)
+		}
+		if got.res.Status != backend.RunCanceled {
+			t.Fatalf("expected the run to be canceled, got %s", got.res.Status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ExecuteSync did not return once its ctx was canceled")
+	}
+}
+
+func testExecuteScriptSync(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	if _, err := tes.ex.ExecuteScriptSync(ctx, tes.tc.OrgID, "", time.Unix(123, 0), time.Unix(126, 0)); err == nil {
+		t.Fatal("expected an error for an empty script")
+	}
+
+	// a saved task of the org, the script is a preview of an edit of it
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: fmt.Sprintf(fmtTestScript, t.Name())})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	script := fmt.Sprintf(fmtTestScript, t.Name()+"-edited")
+	type result struct {
+		res RunResult
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		res, err := tes.ex.ExecuteScriptSync(ctx, tes.tc.OrgID, script, time.Unix(123, 0), time.Unix(126, 0))
+		done <- result{res, err}
+	}()
+
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.SucceedQuery(script)
+
+	got := <-done
+	if got.err != nil {
+		t.Fatal(got.err)
+	}
+	if !got.res.RunID.Valid() {
+		t.Fatal("expected the id of the run")
+	}
+	if got.res.Status != backend.RunSuccess {
+		t.Fatalf("expected the run to succeed, got %s", got.res.Status)
+	}
+
+	// the run is not recorded against the tasks of the org
+	saved, err := tes.i.FindTaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.LatestCompleted != task.LatestCompleted {
+		t.Fatalf("expected the task to be left as is, latest completed %s", saved.LatestCompleted)
+	}
+
+	failing := fmt.Sprintf(fmtTestScript, t.Name()+"-failing")
+	errs := make(chan error, 1)
+	go func() {
+		_, err := tes.ex.ExecuteScriptSync(ctx, tes.tc.OrgID, failing, time.Unix(123, 0), time.Unix(126, 0))
+		errs <- err
+	}()
+
+	tes.svc.WaitForQueryLive(t, failing)
+	tes.svc.FailQuery(failing, errors.New("forced"))
+
+	if err := <-errs; err == nil {
+		t.Fatal("expected the error of the run")
+	}
+}
+
+func TestNewRunRange(t *testing.T) {
+	scheduledFor := time.Unix(3600, 0)
+	mustOpts := func(script string) options.Options {
+		opts, err := options.FromScript(script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return opts
+	}
+
+	t.Run("without latency", func(t *testing.T) {
+		rr := newRunRange(scheduledFor, mustOpts(`option task = {name: "a", every: 1m} from(bucket: "b") |> range(start: -task.every)`))
+		if !rr.now.Equal(scheduledFor) {
+			t.Fatalf("expected now %s, got %s", scheduledFor, rr.now)
+		}
+		if rr.shifted != nil || rr.chunks != nil {
+			t.Fatalf("expected the script to run as is, got %+v", rr)
+		}
+	})
+
+	t.Run("with latency", func(t *testing.T) {
+		script := `option task = {name: "a", every: 1m, latency: 30s} from(bucket: "b") |> range(start: -task.every)`
+		rr := newRunRange(scheduledFor, mustOpts(script))
+
+		shifted := scheduledFor.Add(-30 * time.Second)
+		if !rr.now.Equal(shifted) {
+			t.Fatalf("expected now %s, got %s", shifted, rr.now)
+		}
+		expected := chunk{start: shifted.Add(-time.Minute), stop: shifted}
+		if rr.shifted == nil || *rr.shifted != expected {
+			t.Fatalf("expected range %+v, got %+v", expected, rr.shifted)
+		}
+
+		injected, err := chunkScript(script, *rr.shifted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(injected, "timeRangeStart: 1970-01-01T00:58:30Z") || !strings.Contains(injected, "timeRangeStop: 1970-01-01T00:59:30Z") {
+			t.Fatalf("expected the shifted range to be injected, got %s", injected)
+		}
+	})
+
+	t.Run("with latency and chunks", func(t *testing.T) {
+		rr := newRunRange(scheduledFor, mustOpts(`option task = {name: "a", every: 1m, chunkInterval: 30s, latency: 30s} from(bucket: "b") |> range(start: -task.every)`))
+
+		shifted := scheduledFor.Add(-30 * time.Second)
+		expected := []chunk{
+			{start: shifted.Add(-time.Minute), stop: shifted.Add(-30 * time.Second)},
+			{start: shifted.Add(-30 * time.Second), stop: shifted},
+		}
+		if !reflect.DeepEqual(rr.chunks, expected) {
+			t.Fatalf("expected chunks %+v, got %+v", expected, rr.chunks)
+		}
+		if rr.shifted != nil {
+			t.Fatalf("expected no shifted range for a chunked run, got %+v", rr.shifted)
+		}
+	})
+
+	t.Run("cron with latency", func(t *testing.T) {
+		rr := newRunRange(scheduledFor, mustOpts(`option task = {name: "a", cron: "* * * * *", latency: 30s} from(bucket: "b") |> range(start: -1m)`))
+		if !rr.now.Equal(scheduledFor.Add(-30 * time.Second)) {
+			t.Fatalf("expected now to be shifted, got %s", rr.now)
+		}
+		if rr.shifted != nil {
+			t.Fatalf("expected no range for a cron task, got %+v", rr.shifted)
+		}
+	})
+}
+
 type taskControlService struct {
 	backend.TaskControlService
 }
@@ -0,0 +1,120 @@
+// Package cq translates InfluxDB 1.x continuous queries into Flux tasks.
+//
+// A continuous query is translated into a task that runs on the schedule of
+// the continuous query and writes the result of an aggregateWindow over the
+// resampled time range into the bucket of its target. The bucket of a 1.x
+// database and retention policy is named db/rp.
+//
+// Only a subset of continuous queries can be translated. A continuous query
+// using a feature that has no equivalent in the generated task is reported in
+// its Result rather than failing the translation of the others.
+package cq
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/influxdata/influxql"
+)
+
+// DefaultRetentionPolicy is the retention policy assumed for sources and
+// targets of a continuous query that do not name one.
+const DefaultRetentionPolicy = "autogen"
+
+// ContinuousQuery is the definition of a 1.x continuous query.
+type ContinuousQuery struct {
+	Name     string
+	Database string
+	// Query is the CREATE CONTINUOUS QUERY statement.
+	Query string
+}
+
+// Parse reads the continuous queries from r. The continuous queries are either
+// CREATE CONTINUOUS QUERY statements, separated by semicolons, or the JSON
+// output of SHOW CONTINUOUS QUERIES.
+func Parse(r io.Reader) ([]ContinuousQuery, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '{' {
+		return parseShowOutput(b)
+	}
+	return parseStatements(string(b))
+}
+
+func parseStatements(s string) ([]ContinuousQuery, error) {
+	q, err := influxql.ParseQuery(s)
+	if err != nil {
+		return nil, err
+	}
+
+	cqs := make([]ContinuousQuery, 0, len(q.Statements))
+	for _, stmt := range q.Statements {
+		create, ok := stmt.(*influxql.CreateContinuousQueryStatement)
+		if !ok {
+			return nil, fmt.Errorf("statement is not a continuous query: %s", stmt)
+		}
+		cqs = append(cqs, ContinuousQuery{
+			Name:     create.Name,
+			Database: create.Database,
+			Query:    create.String(),
+		})
+	}
+	return cqs, nil
+}
+
+// showOutput is the JSON output of SHOW CONTINUOUS QUERIES, there is a series
+// for each database with a name and query column.
+type showOutput struct {
+	Results []struct {
+		Series []struct {
+			Name    string     `json:"name"`
+			Columns []string   `json:"columns"`
+			Values  [][]string `json:"values"`
+		} `json:"series"`
+	} `json:"results"`
+}
+
+func parseShowOutput(b []byte) ([]ContinuousQuery, error) {
+	var out showOutput
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("invalid SHOW CONTINUOUS QUERIES output: %v", err)
+	}
+
+	var cqs []ContinuousQuery
+	for _, res := range out.Results {
+		for _, series := range res.Series {
+			nameIdx, queryIdx := -1, -1
+			for i, col := range series.Columns {
+				switch col {
+				case "name":
+					nameIdx = i
+				case "query":
+					queryIdx = i
+				}
+			}
+			if len(series.Values) > 0 && (nameIdx < 0 || queryIdx < 0) {
+				return nil, errors.New("invalid SHOW CONTINUOUS QUERIES output: missing name or query column")
+			}
+
+			for _, v := range series.Values {
+				if len(v) <= nameIdx || len(v) <= queryIdx {
+					return nil, errors.New("invalid SHOW CONTINUOUS QUERIES output: missing value")
+				}
+				cqs = append(cqs, ContinuousQuery{
+					Name:     v[nameIdx],
+					Database: series.Name,
+					Query:    v[queryIdx],
+				})
+			}
+		}
+	}
+	return cqs, nil
+}
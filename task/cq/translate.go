@@ -0,0 +1,358 @@
+package cq
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxql"
+)
+
+// Task is a Flux task translated from a continuous query.
+type Task struct {
+	Name string
+	// Every is the Flux duration literal of the schedule of the task.
+	Every string
+	// Query is the script of the task without its task option.
+	Query string
+	// Flux is the script of the task, the query preceded by the task option.
+	Flux string
+}
+
+// Result is the outcome of translating a continuous query, either the Task or
+// the reason the continuous query could not be translated.
+type Result struct {
+	ContinuousQuery ContinuousQuery
+	Task            *Task
+	Err             error
+}
+
+// UnsupportedError is returned when a continuous query uses a feature that
+// has no equivalent in the translated task.
+type UnsupportedError struct {
+	Feature string
+}
+
+func (e *UnsupportedError) Error() string {
+	return "unsupported continuous query feature: " + e.Feature
+}
+
+func unsupported(format string, args ...interface{}) error {
+	return &UnsupportedError{Feature: fmt.Sprintf(format, args...)}
+}
+
+// aggregates are the 1.x functions that have a Flux function of the same
+// name that aggregateWindow can use.
+var aggregates = map[string]bool{
+	"count":  true,
+	"first":  true,
+	"last":   true,
+	"max":    true,
+	"mean":   true,
+	"median": true,
+	"min":    true,
+	"spread": true,
+	"stddev": true,
+	"sum":    true,
+}
+
+// TranslateAll translates each of the continuous queries. A continuous query
+// that cannot be translated does not prevent the others from being translated,
+// its Result has the error instead.
+func TranslateAll(cqs []ContinuousQuery) []Result {
+	results := make([]Result, 0, len(cqs))
+	for _, cq := range cqs {
+		t, err := Translate(cq)
+		results = append(results, Result{
+			ContinuousQuery: cq,
+			Task:            t,
+			Err:             err,
+		})
+	}
+	return results
+}
+
+// Translate translates the continuous query into a Flux task. An
+// *UnsupportedError is returned when the continuous query cannot be
+// translated.
+func Translate(cq ContinuousQuery) (*Task, error) {
+	stmt, err := influxql.ParseStatement(cq.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	create, ok := stmt.(*influxql.CreateContinuousQueryStatement)
+	if !ok {
+		return nil, errors.New("statement is not a continuous query")
+	}
+
+	t, err := newTranslation(create)
+	if err != nil {
+		return nil, err
+	}
+
+	every, query := fluxDuration(t.every), t.query()
+	return &Task{
+		Name:  create.Name,
+		Every: every,
+		Query: query,
+		Flux:  fmt.Sprintf("option task = {name: %q, every: %s}\n\n%s", t.name, every, query),
+	}, nil
+}
+
+// translation is the parts of a continuous query used to generate its task.
+type translation struct {
+	name  string
+	every time.Duration
+
+	srcBucket   string
+	measurement string
+	field       string
+	predicate   string
+
+	// groupBy is nil when the series are kept, i.e. GROUP BY *.
+	groupBy  []string
+	interval time.Duration
+	rng      time.Duration
+	fn       string
+
+	dstBucket      string
+	dstMeasurement string
+	dstField       string
+}
+
+func newTranslation(create *influxql.CreateContinuousQueryStatement) (*translation, error) {
+	sel := create.Source
+	if sel == nil {
+		return nil, errors.New("continuous query is missing a select statement")
+	}
+
+	t := &translation{name: create.Name}
+
+	if len(sel.Fields) != 1 {
+		return nil, unsupported("selecting %d fields", len(sel.Fields))
+	}
+	f := sel.Fields[0]
+	call, ok := f.Expr.(*influxql.Call)
+	if !ok {
+		return nil, unsupported("field expression %s", f.Expr)
+	}
+	if !aggregates[call.Name] {
+		return nil, unsupported("function %s()", call.Name)
+	}
+	if len(call.Args) != 1 {
+		return nil, unsupported("%s() with %d arguments", call.Name, len(call.Args))
+	}
+	ref, ok := call.Args[0].(*influxql.VarRef)
+	if !ok {
+		return nil, unsupported("%s() of %s", call.Name, call.Args[0])
+	}
+	t.fn, t.field, t.dstField = call.Name, ref.Val, f.Name()
+
+	if len(sel.Sources) != 1 {
+		return nil, unsupported("selecting from %d sources", len(sel.Sources))
+	}
+	src, ok := sel.Sources[0].(*influxql.Measurement)
+	if !ok {
+		return nil, unsupported("subqueries")
+	}
+	if src.Regex != nil {
+		return nil, unsupported("regex sources")
+	}
+	t.srcBucket = bucketName(src.Database, src.RetentionPolicy, create.Database)
+	t.measurement = src.Name
+
+	if sel.Target == nil || sel.Target.Measurement == nil {
+		return nil, errors.New("continuous query is missing an INTO clause")
+	}
+	dst := sel.Target.Measurement
+	if dst.Name == "" {
+		// INTO db.rp.:MEASUREMENT leaves the name of the measurement empty
+		return nil, unsupported("backreference :MEASUREMENT")
+	}
+	t.dstBucket = bucketName(dst.Database, dst.RetentionPolicy, create.Database)
+	t.dstMeasurement = dst.Name
+
+	var (
+		groupBy  = []string{}
+		wildcard bool
+	)
+	for _, d := range sel.Dimensions {
+		switch expr := d.Expr.(type) {
+		case *influxql.Call:
+			if expr.Name != "time" || len(expr.Args) == 0 {
+				return nil, unsupported("GROUP BY %s", expr)
+			}
+			if len(expr.Args) > 1 {
+				return nil, unsupported("GROUP BY time() offsets")
+			}
+			interval, ok := expr.Args[0].(*influxql.DurationLiteral)
+			if !ok {
+				return nil, unsupported("GROUP BY %s", expr)
+			}
+			t.interval = interval.Val
+		case *influxql.VarRef:
+			groupBy = append(groupBy, expr.Val)
+		case *influxql.Wildcard:
+			wildcard = true
+		default:
+			return nil, unsupported("GROUP BY %s", d.Expr)
+		}
+	}
+	if t.interval <= 0 {
+		return nil, errors.New("continuous query is missing a GROUP BY time() interval")
+	}
+	if !wildcard {
+		t.groupBy = groupBy
+	}
+
+	switch sel.Fill {
+	case influxql.NullFill, influxql.NoFill:
+	default:
+		return nil, unsupported("fill options other than fill(null) and fill(none)")
+	}
+
+	if sel.Limit > 0 || sel.Offset > 0 || sel.SLimit > 0 || sel.SOffset > 0 {
+		return nil, unsupported("LIMIT and OFFSET clauses")
+	}
+
+	if sel.Condition != nil {
+		p, err := predicate(sel.Condition)
+		if err != nil {
+			return nil, err
+		}
+		t.predicate = p
+	}
+
+	t.every, t.rng = t.interval, t.interval
+	if create.ResampleEvery > 0 {
+		t.every = create.ResampleEvery
+	}
+	if create.ResampleFor > 0 {
+		t.rng = create.ResampleFor
+	}
+
+	return t, nil
+}
+
+func (t *translation) query() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "from(bucket: %q)\n", t.srcBucket)
+	fmt.Fprintf(&b, "\t|> range(start: -%s)\n", fluxDuration(t.rng))
+	fmt.Fprintf(&b, "\t|> filter(fn: (r) => r._measurement == %q and r._field == %q)\n", t.measurement, t.field)
+	if t.predicate != "" {
+		fmt.Fprintf(&b, "\t|> filter(fn: (r) => %s)\n", t.predicate)
+	}
+	if t.groupBy != nil {
+		columns := make([]string, 0, len(t.groupBy))
+		for _, c := range t.groupBy {
+			columns = append(columns, fmt.Sprintf("%q", c))
+		}
+		if len(columns) == 0 {
+			b.WriteString("\t|> group()\n")
+		} else {
+			fmt.Fprintf(&b, "\t|> group(columns: [%s])\n", strings.Join(columns, ", "))
+		}
+	}
+	// 1.x writes the points of a continuous query at the start of each interval
+	fmt.Fprintf(&b, "\t|> aggregateWindow(every: %s, fn: %s, timeSrc: \"_start\", createEmpty: false)\n", fluxDuration(t.interval), t.fn)
+	fmt.Fprintf(&b, "\t|> set(key: \"_measurement\", value: %q)\n", t.dstMeasurement)
+	fmt.Fprintf(&b, "\t|> set(key: \"_field\", value: %q)\n", t.dstField)
+	fmt.Fprintf(&b, "\t|> to(bucket: %q)\n", t.dstBucket)
+
+	return b.String()
+}
+
+// predicate translates a WHERE clause into the body of a filter function. Only
+// comparisons of tags to strings are supported.
+func predicate(expr influxql.Expr) (string, error) {
+	switch e := expr.(type) {
+	case *influxql.ParenExpr:
+		p, err := predicate(e.Expr)
+		if err != nil {
+			return "", err
+		}
+		return "(" + p + ")", nil
+	case *influxql.BinaryExpr:
+		switch e.Op {
+		case influxql.AND, influxql.OR:
+			lhs, err := predicate(e.LHS)
+			if err != nil {
+				return "", err
+			}
+			rhs, err := predicate(e.RHS)
+			if err != nil {
+				return "", err
+			}
+			return lhs + " " + strings.ToLower(e.Op.String()) + " " + rhs, nil
+		case influxql.EQ, influxql.NEQ:
+			ref, ok := e.LHS.(*influxql.VarRef)
+			if !ok {
+				break
+			}
+			lit, ok := e.RHS.(*influxql.StringLiteral)
+			if !ok {
+				break
+			}
+			op := "=="
+			if e.Op == influxql.NEQ {
+				op = "!="
+			}
+			return fmt.Sprintf("%s %s %q", recordMember(ref.Val), op, lit.Val), nil
+		}
+	}
+	return "", unsupported("WHERE %s", expr)
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func recordMember(name string) string {
+	if identifierPattern.MatchString(name) {
+		return "r." + name
+	}
+	return fmt.Sprintf("r[%q]", name)
+}
+
+func bucketName(db, rp, defaultDB string) string {
+	if db == "" {
+		db = defaultDB
+	}
+	if rp == "" {
+		rp = DefaultRetentionPolicy
+	}
+	return db + "/" + rp
+}
+
+// fluxDuration formats d as a Flux duration literal, using the largest units
+// that represent d exactly.
+func fluxDuration(d time.Duration) string {
+	units := []struct {
+		unit string
+		d    time.Duration
+	}{
+		{"w", 7 * 24 * time.Hour},
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+		{"ms", time.Millisecond},
+		{"us", time.Microsecond},
+		{"ns", time.Nanosecond},
+	}
+
+	if d == 0 {
+		return "0s"
+	}
+
+	var b strings.Builder
+	for _, u := range units {
+		if n := d / u.d; n > 0 {
+			fmt.Fprintf(&b, "%d%s", n, u.unit)
+			d -= n * u.d
+		}
+	}
+	return b.String()
+}
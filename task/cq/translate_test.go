@@ -0,0 +1,158 @@
+package cq_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/task/cq"
+)
+
+func TestTranslate_Golden(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.influxql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no testdata found")
+	}
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".influxql")
+		t.Run(name, func(t *testing.T) {
+			b, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := ioutil.ReadFile(strings.TrimSuffix(file, ".influxql") + ".flux")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cqs, err := cq.Parse(strings.NewReader(string(b)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(cqs) != 1 {
+				t.Fatalf("expected 1 continuous query, got %d", len(cqs))
+			}
+
+			task, err := cq.Translate(cqs[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if task.Flux != string(expected) {
+				t.Errorf("unexpected flux:\n-- expected --\n%s\n-- got --\n%s", expected, task.Flux)
+			}
+		})
+	}
+}
+
+func TestTranslateAll_Unsupported(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{
+			name:  "multiple fields",
+			query: `CREATE CONTINUOUS QUERY cq ON db BEGIN SELECT mean(a), mean(b) INTO m2 FROM m GROUP BY time(1h) END`,
+		},
+		{
+			name:  "transformation",
+			query: `CREATE CONTINUOUS QUERY cq ON db BEGIN SELECT derivative(mean(a)) INTO m2 FROM m GROUP BY time(1h) END`,
+		},
+		{
+			name:  "regex source",
+			query: `CREATE CONTINUOUS QUERY cq ON db BEGIN SELECT mean(a) INTO m2 FROM /cpu.*/ GROUP BY time(1h) END`,
+		},
+		{
+			name:  "backreference",
+			query: `CREATE CONTINUOUS QUERY cq ON db BEGIN SELECT mean(a) INTO db.rp.:MEASUREMENT FROM m GROUP BY time(1h) END`,
+		},
+		{
+			name:  "group by time offset",
+			query: `CREATE CONTINUOUS QUERY cq ON db BEGIN SELECT mean(a) INTO m2 FROM m GROUP BY time(1h, 15m) END`,
+		},
+		{
+			name:  "fill value",
+			query: `CREATE CONTINUOUS QUERY cq ON db BEGIN SELECT mean(a) INTO m2 FROM m GROUP BY time(1h) fill(0) END`,
+		},
+		{
+			name:  "field condition",
+			query: `CREATE CONTINUOUS QUERY cq ON db BEGIN SELECT mean(a) INTO m2 FROM m WHERE a > 1 GROUP BY time(1h) END`,
+		},
+	}
+
+	var cqs []cq.ContinuousQuery
+	for _, tt := range tests {
+		cqs = append(cqs, cq.ContinuousQuery{Name: tt.name, Database: "db", Query: tt.query})
+	}
+	// a supported query alongside the unsupported ones is still translated
+	cqs = append(cqs, cq.ContinuousQuery{
+		Name:     "supported",
+		Database: "db",
+		Query:    `CREATE CONTINUOUS QUERY cq ON db BEGIN SELECT mean(a) INTO m2 FROM m GROUP BY time(1h) END`,
+	})
+
+	results := cq.TranslateAll(cqs)
+	if len(results) != len(cqs) {
+		t.Fatalf("expected %d results, got %d", len(cqs), len(results))
+	}
+
+	for i, tt := range tests {
+		res := results[i]
+		if _, ok := res.Err.(*cq.UnsupportedError); !ok {
+			t.Errorf("%s: expected unsupported error, got %v", tt.name, res.Err)
+		}
+		if res.Task != nil {
+			t.Errorf("%s: expected no task", tt.name)
+		}
+	}
+
+	if last := results[len(results)-1]; last.Err != nil || last.Task == nil {
+		t.Errorf("expected supported query to be translated, got %v", last.Err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Run("statements", func(t *testing.T) {
+		src := `
+CREATE CONTINUOUS QUERY cq1 ON db1 BEGIN SELECT mean(a) INTO m2 FROM m GROUP BY time(1h) END;
+CREATE CONTINUOUS QUERY cq2 ON db2 BEGIN SELECT sum(a) INTO m3 FROM m GROUP BY time(1m) END
+`
+		cqs, err := cq.Parse(strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cqs) != 2 {
+			t.Fatalf("expected 2 continuous queries, got %d", len(cqs))
+		}
+		if cqs[0].Name != "cq1" || cqs[0].Database != "db1" || cqs[1].Name != "cq2" || cqs[1].Database != "db2" {
+			t.Errorf("unexpected continuous queries: %+v", cqs)
+		}
+	})
+
+	t.Run("show continuous queries output", func(t *testing.T) {
+		src := `{"results":[{"statement_id":0,"series":[
+			{"name":"_internal","columns":["name","query"]},
+			{"name":"db1","columns":["name","query"],"values":[["cq1","CREATE CONTINUOUS QUERY cq1 ON db1 BEGIN SELECT mean(a) INTO db1.autogen.m2 FROM db1.autogen.m GROUP BY time(1h) END"]]}
+		]}]}`
+		cqs, err := cq.Parse(strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cqs) != 1 || cqs[0].Name != "cq1" || cqs[0].Database != "db1" {
+			t.Fatalf("unexpected continuous queries: %+v", cqs)
+		}
+		if _, err := cq.Translate(cqs[0]); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("other statements are rejected", func(t *testing.T) {
+		if _, err := cq.Parse(strings.NewReader("SHOW DATABASES")); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
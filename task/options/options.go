@@ -35,6 +35,16 @@ type Options struct {
 	// this can be unmarshaled from json as a string i.e.: "1d" will unmarshal as 1 day
 	Offset *Duration `json:"offset,omitempty"`
 
+	// ChunkInterval splits the time range of a run into sequential sub-queries of this length.
+	// A script that reads neither v.timeRangeStart nor v.timeRangeStop is run as a single query.
+	// this can be unmarshaled from json as a string i.e.: "1h" will unmarshal as 1 hour
+	ChunkInterval *Duration `json:"chunkInterval,omitempty"`
+
+	// Latency shifts the time range of each run back, so data arriving late is
+	// still in the range of the run it belongs to.
+	// this can be unmarshaled from json as a string i.e.: "1m" will unmarshal as 1 minute
+	Latency *Duration `json:"latency,omitempty"`
+
 	Concurrency *int64 `json:"concurrency,omitempty"`
 
 	Retry *int64 `json:"retry,omitempty"`
@@ -132,6 +142,8 @@ func (o *Options) Clear() {
 	o.Cron = ""
 	o.Every = Duration{}
 	o.Offset = nil
+	o.ChunkInterval = nil
+	o.Latency = nil
 	o.Concurrency = nil
 	o.Retry = nil
 }
@@ -142,18 +154,22 @@ func (o *Options) IsZero() bool {
 		o.Cron == "" &&
 		o.Every.IsZero() &&
 		(o.Offset == nil || o.Offset.IsZero()) &&
+		(o.ChunkInterval == nil || o.ChunkInterval.IsZero()) &&
+		(o.Latency == nil || o.Latency.IsZero()) &&
 		o.Concurrency == nil &&
 		o.Retry == nil
 }
 
 // All the task option names we accept.
 const (
-	optName        = "name"
-	optCron        = "cron"
-	optEvery       = "every"
-	optOffset      = "offset"
-	optConcurrency = "concurrency"
-	optRetry       = "retry"
+	optName          = "name"
+	optCron          = "cron"
+	optEvery         = "every"
+	optOffset        = "offset"
+	optChunkInterval = "chunkInterval"
+	optLatency       = "latency"
+	optConcurrency   = "concurrency"
+	optRetry         = "retry"
 )
 
 // contains is a helper function to see if an array of strings contains a string
@@ -167,7 +183,7 @@ func contains(s []string, e string) bool {
 }
 
 func grabTaskOptionAST(p *ast.Package, keys ...string) map[string]ast.Expression {
-	res := make(map[string]ast.Expression, 2) // we preallocate two keys for the map, as that is how many we will use at maximum (offset and every)
+	res := make(map[string]ast.Expression, 4) // we preallocate four keys for the map, as that is how many we will use at maximum (offset, every, chunkInterval and latency)
 	for i := range p.Files {
 		for j := range p.Files[i].Body {
 			if p.Files[i].Body[j].Type() != "OptionStatement" {
@@ -217,7 +233,7 @@ func FromScript(script string) (Options, error) {
 	if err != nil {
 		return opt, err
 	}
-	durTypes := grabTaskOptionAST(fluxAST, optEvery, optOffset)
+	durTypes := grabTaskOptionAST(fluxAST, optEvery, optOffset, optChunkInterval, optLatency)
 	// TODO(desa): should be dependencies.NewEmpty(), but for now we'll hack things together
 	ctx := newDeps().Inject(context.Background())
 	_, scope, err := flux.EvalAST(ctx, fluxAST)
@@ -312,6 +328,46 @@ func FromScript(script string) (Options, error) {
 		opt.Offset.Node = *durNode
 	}
 
+	if chunkVal, ok := optObject.Get(optChunkInterval); ok {
+		if err := checkNature(chunkVal.PolyType().Nature(), semantic.Duration); err != nil {
+			return opt, err
+		}
+		dur, ok := durTypes[optChunkInterval]
+		if !ok || dur == nil {
+			return opt, ErrParseTaskOptionField(optChunkInterval)
+		}
+		durNode, err := parseSignedDuration(dur.Location().Source)
+		if err != nil {
+			return opt, err
+		}
+		if _, err := time.ParseDuration(dur.Location().Source); err != nil { // TODO(docmerlin): remove this once tasks fully supports all flux duration units.
+			return opt, ErrParseTaskOptionField(optChunkInterval)
+		}
+		durNode.BaseNode = ast.BaseNode{}
+		opt.ChunkInterval = &Duration{}
+		opt.ChunkInterval.Node = *durNode
+	}
+
+	if latencyVal, ok := optObject.Get(optLatency); ok {
+		if err := checkNature(latencyVal.PolyType().Nature(), semantic.Duration); err != nil {
+			return opt, err
+		}
+		dur, ok := durTypes[optLatency]
+		if !ok || dur == nil {
+			return opt, ErrParseTaskOptionField(optLatency)
+		}
+		durNode, err := parseSignedDuration(dur.Location().Source)
+		if err != nil {
+			return opt, err
+		}
+		if _, err := time.ParseDuration(dur.Location().Source); err != nil { // TODO(docmerlin): remove this once tasks fully supports all flux duration units.
+			return opt, ErrParseTaskOptionField(optLatency)
+		}
+		durNode.BaseNode = ast.BaseNode{}
+		opt.Latency = &Duration{}
+		opt.Latency.Node = *durNode
+	}
+
 	if concurrencyVal, ok := optObject.Get(optConcurrency); ok {
 		if err := checkNature(concurrencyVal.PolyType().Nature(), semantic.Int); err != nil {
 			return opt, err
@@ -368,10 +424,37 @@ func (o *Options) Validate() error {
 			return err
 		}
 		if offset.Truncate(time.Second) != offset {
-			// For now, allowing negative offset delays. Maybe they're useful for forecasting?
+			// Negative offsets parse, tasks stored with one are rejected by the task service.
 			errs = append(errs, "offset option must be expressible as whole seconds")
 		}
 	}
+	if o.Latency != nil {
+		latency, err := o.Latency.DurationFrom(now)
+		if err != nil {
+			return err
+		}
+		if latency < 0 {
+			errs = append(errs, "latency option must not be negative")
+		} else if latency.Truncate(time.Second) != latency {
+			errs = append(errs, "latency option must be expressible as whole seconds")
+		}
+	}
+	if o.ChunkInterval != nil {
+		chunk, err := o.ChunkInterval.DurationFrom(now)
+		if err != nil {
+			return err
+		}
+		if !everyPresent {
+			errs = append(errs, "chunkInterval option requires the every option")
+		} else if every, err := o.Every.DurationFrom(now); err == nil && chunk > every {
+			errs = append(errs, "chunkInterval option must not exceed every")
+		}
+		if chunk < time.Second {
+			errs = append(errs, "chunkInterval option must be at least 1 second")
+		} else if chunk.Truncate(time.Second) != chunk {
+			errs = append(errs, "chunkInterval option must be expressible as whole seconds")
+		}
+	}
 	if o.Concurrency != nil {
 		if *o.Concurrency < 1 {
 			errs = append(errs, "concurrency must be at least 1")
@@ -427,7 +510,7 @@ func validateOptionNames(o values.Object) error {
 	var unexpected []string
 	o.Range(func(name string, _ values.Value) {
 		switch name {
-		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry:
+		case optName, optCron, optEvery, optOffset, optChunkInterval, optLatency, optConcurrency, optRetry:
 			// Known option. Nothing to do.
 		default:
 			unexpected = append(unexpected, name)
@@ -436,7 +519,7 @@ func validateOptionNames(o values.Object) error {
 
 	if len(unexpected) > 0 {
 		u := strings.Join(unexpected, ", ")
-		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry}, ", ")
+		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optChunkInterval, optLatency, optConcurrency, optRetry}, ", ")
 		return fmt.Errorf("unknown task option(s): %s. valid options are %s", u, v)
 	}
 
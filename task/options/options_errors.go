@@ -12,7 +12,7 @@ func ErrMissingRequiredTaskOption(opt string) error {
 	return fmt.Errorf("missing required option: %s", opt)
 }
 
-// ErrTaskInvalidDuration is returned when an "every" or "offset" option is invalid in a task.
+// ErrTaskInvalidDuration is returned when an "every", "offset" or "chunkInterval" option is invalid in a task.
 func ErrTaskInvalidDuration(err error) error {
 	return fmt.Errorf("invalid duration in task %s", err)
 }
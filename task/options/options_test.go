@@ -27,6 +27,12 @@ func scriptGenerator(opt options.Options, body string) string {
 	if opt.Offset != nil && !(*opt.Offset).IsZero() {
 		taskData = fmt.Sprintf("%s  offset: %s,\n", taskData, opt.Offset.String())
 	}
+	if opt.ChunkInterval != nil && !(*opt.ChunkInterval).IsZero() {
+		taskData = fmt.Sprintf("%s  chunkInterval: %s,\n", taskData, opt.ChunkInterval.String())
+	}
+	if opt.Latency != nil && !(*opt.Latency).IsZero() {
+		taskData = fmt.Sprintf("%s  latency: %s,\n", taskData, opt.Latency.String())
+	}
 	if opt.Concurrency != nil && *opt.Concurrency != 0 {
 		taskData = fmt.Sprintf("%s  concurrency: %d,\n", taskData, *opt.Concurrency)
 	}
@@ -77,6 +83,21 @@ func TestFromScript(t *testing.T) {
 		{script: scriptGenerator(options.Options{Name: "name7", Retry: pointer.Int64(20), Every: *(options.MustParseDuration("1h"))}, ""), shouldErr: true},
 		{script: "option task = {\n  name: \"name8\",\n  retry: 0,\n  every: 1m0s,\n\n}\n\nfrom(bucket: \"test\")\n    |> range(start:-1h)", shouldErr: true},
 		{script: scriptGenerator(options.Options{Name: "name9"}, ""), shouldErr: true},
+		{script: scriptGenerator(options.Options{Name: "name10", Every: *(options.MustParseDuration("1h")), ChunkInterval: options.MustParseDuration("15m")}, ""),
+			exp: options.Options{Name: "name10",
+				Every:         *(options.MustParseDuration("1h")),
+				ChunkInterval: options.MustParseDuration("15m"),
+				Concurrency:   pointer.Int64(1),
+				Retry:         pointer.Int64(1)}},
+		{script: scriptGenerator(options.Options{Name: "name11", Cron: "* * * * *", ChunkInterval: options.MustParseDuration("15m")}, ""), shouldErr: true},
+		{script: scriptGenerator(options.Options{Name: "name12", Every: *(options.MustParseDuration("1h")), ChunkInterval: options.MustParseDuration("2h")}, ""), shouldErr: true},
+		{script: scriptGenerator(options.Options{Name: "name13", Every: *(options.MustParseDuration("1h")), Latency: options.MustParseDuration("30s")}, ""),
+			exp: options.Options{Name: "name13",
+				Every:       *(options.MustParseDuration("1h")),
+				Latency:     options.MustParseDuration("30s"),
+				Concurrency: pointer.Int64(1),
+				Retry:       pointer.Int64(1)}},
+		{script: scriptGenerator(options.Options{Name: "name14", Every: *(options.MustParseDuration("1h")), Latency: options.MustParseDuration("-30s")}, ""), shouldErr: true},
 		{script: scriptGenerator(options.Options{}, ""), shouldErr: true},
 		{script: `option task = {
 			name: "test",
@@ -143,7 +164,7 @@ func TestFromScriptWithUnknownOptions(t *testing.T) {
 		t.Errorf("expected error to mention unrecognized options, but it said: %v", err)
 	}
 
-	validOpts := []string{"name", "cron", "every", "offset", "concurrency", "retry"}
+	validOpts := []string{"name", "cron", "every", "offset", "chunkInterval", "latency", "concurrency", "retry"}
 	for _, o := range validOpts {
 		if !strings.Contains(msg, o) {
 			t.Errorf("expected error to mention valid option %q but it said: %v", o, err)
@@ -195,6 +216,32 @@ func TestValidate(t *testing.T) {
 		t.Error("expected error for sub-second delay resolution")
 	}
 
+	*bad = good
+	bad.Latency = options.MustParseDuration("-1m")
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for negative latency")
+	}
+
+	*bad = good
+	bad.Latency = options.MustParseDuration("1500ms")
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for sub-second latency resolution")
+	}
+
+	*bad = good
+	bad.ChunkInterval = options.MustParseDuration("1m")
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for chunkInterval without every")
+	}
+
+	*bad = good
+	bad.Cron = ""
+	bad.Every = *options.MustParseDuration("1m")
+	bad.ChunkInterval = options.MustParseDuration("1500ms")
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for sub-second chunkInterval resolution")
+	}
+
 	*bad = good
 	bad.Concurrency = pointer.Int64(0)
 	if err := bad.Validate(); err == nil {
@@ -302,3 +349,38 @@ func TestDurationMath(t *testing.T) {
 		t.Fatalf("expected duration to be 10s but it was %s", d)
 	}
 }
+
+func TestWarnings(t *testing.T) {
+	const body = `from(bucket: "b") |> range(start: -task.every) |> to(bucket: %q, orgID: "0000000000000000")`
+
+	for _, c := range []struct {
+		name  string
+		opt   options.Options
+		to    string
+		warns bool
+	}{
+		{name: "same bucket without offset", opt: options.Options{Name: "a", Every: *options.MustParseDuration("1m")}, to: "b", warns: true},
+		{name: "other bucket without offset", opt: options.Options{Name: "a", Every: *options.MustParseDuration("1m")}, to: "c"},
+		{name: "same bucket with offset", opt: options.Options{Name: "a", Every: *options.MustParseDuration("1m"), Offset: options.MustParseDuration("10s")}, to: "b"},
+		{name: "same bucket with latency", opt: options.Options{Name: "a", Every: *options.MustParseDuration("1m"), Latency: options.MustParseDuration("10s")}, to: "b"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			script := scriptGenerator(c.opt, fmt.Sprintf(body, c.to))
+			opt, err := options.FromScript(script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			warnings := options.Warnings(script, opt)
+			if !c.warns {
+				if len(warnings) != 0 {
+					t.Fatalf("expected no warnings, got %v", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], `"b"`) {
+				t.Fatalf("expected a warning for bucket \"b\", got %v", warnings)
+			}
+		})
+	}
+}
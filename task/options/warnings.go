@@ -0,0 +1,77 @@
+package options
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+)
+
+// Warnings returns the warnings of a valid task script and its options. A task
+// that reads the bucket it writes with neither an offset nor a latency runs
+// as soon as its time range ends, so data arriving late for the range is
+// never read.
+func Warnings(script string, opt Options) []string {
+	if (opt.Offset != nil && !opt.Offset.IsZero()) || (opt.Latency != nil && !opt.Latency.IsZero()) {
+		return nil
+	}
+
+	pkg := parser.ParseSource(script)
+	if ast.Check(pkg) > 0 {
+		return nil
+	}
+
+	reads := make(map[string]bool)
+	writes := make(map[string]bool)
+	ast.Walk(ast.CreateVisitor(func(node ast.Node) {
+		call, ok := node.(*ast.CallExpression)
+		if !ok {
+			return
+		}
+		fn, ok := call.Callee.(*ast.Identifier)
+		if !ok || (fn.Name != "from" && fn.Name != "to") {
+			return
+		}
+		bucket, ok := callBucket(call)
+		if !ok {
+			return
+		}
+		if fn.Name == "from" {
+			reads[bucket] = true
+		} else {
+			writes[bucket] = true
+		}
+	}), pkg)
+
+	var warnings []string
+	for bucket := range reads {
+		if writes[bucket] {
+			warnings = append(warnings, fmt.Sprintf("task reads and writes bucket %q with no offset, data arriving late is missed; set the offset or latency option", bucket))
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// callBucket returns the bucket argument of a call, when it is a string literal.
+func callBucket(call *ast.CallExpression) (string, bool) {
+	if len(call.Arguments) != 1 {
+		return "", false
+	}
+	obj, ok := call.Arguments[0].(*ast.ObjectExpression)
+	if !ok {
+		return "", false
+	}
+	for _, p := range obj.Properties {
+		if p.Key.Key() != "bucket" {
+			continue
+		}
+		lit, ok := p.Value.(*ast.StringLiteral)
+		if !ok {
+			return "", false
+		}
+		return lit.Value, true
+	}
+	return "", false
+}
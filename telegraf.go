@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb/telegraf/plugins"
@@ -64,11 +65,86 @@ type TelegrafConfig struct {
 	OrgID       ID
 	Name        string
 	Description string
+	// TokenID is the authorization last minted for the agents of the config,
+	// to be rotated or revoked.
+	TokenID ID
 
 	Agent   TelegrafAgentConfig
 	Plugins []TelegrafPlugin
 }
 
+// Placeholders of a telegraf config, substituted when the config is rendered
+// for an agent. A placeholder left in the config is read by telegraf from the
+// env of the agent.
+const (
+	TelegrafHostPlaceholder   = "$INFLUX_HOST"
+	TelegrafTokenPlaceholder  = "$INFLUX_TOKEN"
+	TelegrafOrgPlaceholder    = "$INFLUX_ORG"
+	TelegrafBucketPlaceholder = "$INFLUX_BUCKET"
+)
+
+// Render returns the toml of the config with its placeholders substituted by
+// the values, by placeholder. A placeholder without a value is left as is. The
+// placeholders are within toml strings, the values are escaped so that a value
+// can never end the string and add config of its own.
+func (tc TelegrafConfig) Render(values map[string]string) string {
+	var oldnew []string
+	for placeholder, v := range values {
+		if v != "" {
+			oldnew = append(oldnew, placeholder, escapeTOMLString(v))
+		}
+	}
+	return strings.NewReplacer(oldnew...).Replace(tc.TOML())
+}
+
+// escapeTOMLString escapes s to be placed within a toml basic string.
+func escapeTOMLString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// OutputBuckets returns the buckets the influxdb_v2 outputs of the config write
+// to, with the bucket placeholder substituted by the bucket provided.
+func (tc TelegrafConfig) OutputBuckets(bucket string) []string {
+	var buckets []string
+	for _, p := range tc.Plugins {
+		o, ok := p.Config.(*outputs.InfluxDBV2)
+		if !ok {
+			continue
+		}
+		name := o.Bucket
+		if bucket != "" {
+			name = strings.Replace(name, TelegrafBucketPlaceholder, bucket, -1)
+		}
+		buckets = append(buckets, name)
+	}
+	return buckets
+}
+
 // TOML returns the telegraf toml config string.
 func (tc TelegrafConfig) TOML() string {
 	plugins := ""
@@ -139,6 +215,7 @@ type telegrafConfigEncode struct {
 	OrgID       *ID    `json:"orgID,omitempty"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	TokenID     *ID    `json:"tokenID,omitempty"`
 
 	Agent TelegrafAgentConfig `json:"agent"`
 
@@ -161,6 +238,7 @@ type telegrafConfigDecode struct {
 	OrgID          *ID    `json:"orgID,omitempty"`
 	Name           string `json:"name"`
 	Description    string `json:"description"`
+	TokenID        *ID    `json:"tokenID,omitempty"`
 
 	Agent TelegrafAgentConfig `json:"agent"`
 
@@ -210,6 +288,9 @@ func (tc *TelegrafConfig) MarshalJSON() ([]byte, error) {
 	if tc.ID != 0 {
 		tce.ID = &tc.ID
 	}
+	if tc.TokenID != 0 {
+		tce.TokenID = &tc.TokenID
+	}
 	for k, p := range tc.Plugins {
 		tce.Plugins[k] = telegrafPluginEncode{
 			Name:    p.Config.PluginName(),
@@ -318,6 +399,9 @@ func (tc *TelegrafConfig) UnmarshalJSON(b []byte) error {
 	if tcd.ID != nil {
 		tc.ID = *tcd.ID
 	}
+	if tcd.TokenID != nil {
+		tc.TokenID = *tcd.TokenID
+	}
 	if orgID := tcd.OrgID; orgID != nil && orgID.Valid() {
 		tc.OrgID = *orgID
 	} else if tcd.OrganizationID != nil {
@@ -460,3 +460,48 @@ func TestTOML(t *testing.T) {
 		t.Fatalf("telegraf toml parsing issue, want %q, got %q", tc, tcr)
 	}
 }
+
+func TestTelegrafConfig_Render(t *testing.T) {
+	tc := &TelegrafConfig{
+		Agent: TelegrafAgentConfig{
+			Interval: 10000,
+		},
+		Plugins: []TelegrafPlugin{
+			{
+				Config: &outputs.InfluxDBV2{
+					URLs:         []string{TelegrafHostPlaceholder},
+					Token:        TelegrafTokenPlaceholder,
+					Organization: TelegrafOrgPlaceholder,
+					Bucket:       TelegrafBucketPlaceholder,
+				},
+			},
+		},
+	}
+
+	bucket := "b1\"\n[[outputs.file]]\n  files = [\"/tmp/exfil\"]\n#\\"
+	result := tc.Render(map[string]string{
+		TelegrafHostPlaceholder:   "http://localhost:9999",
+		TelegrafOrgPlaceholder:    "org1",
+		TelegrafBucketPlaceholder: bucket,
+	})
+
+	var cfg struct {
+		Outputs map[string][]map[string]interface{} `toml:"outputs"`
+	}
+	if _, err := toml.Decode(result, &cfg); err != nil {
+		t.Fatalf("rendered config is not valid toml: %v\n%s", err, result)
+	}
+	if _, ok := cfg.Outputs["file"]; ok {
+		t.Fatalf("rendered value added an output of its own:\n%s", result)
+	}
+	outs := cfg.Outputs["influxdb_v2"]
+	if len(outs) != 1 {
+		t.Fatalf("exp 1 influxdb_v2 output, got %d", len(outs))
+	}
+	if got := outs[0]["bucket"]; got != bucket {
+		t.Errorf("exp bucket %q, got %q", bucket, got)
+	}
+	if got := outs[0]["token"]; got != TelegrafTokenPlaceholder {
+		t.Errorf("exp token placeholder to be left as is, got %q", got)
+	}
+}
@@ -20,7 +20,15 @@ var telemetryMatcher = pr.NewMatcher().
 	Family("influxdb_dashboards_total").
 	Family("influxdb_scrapers_total").
 	Family("influxdb_telegrafs_total").
+	Family("influxdb_org_buckets_total"). // Resource counts of each org, anonymized by AnonymizeOrgs.
+	Family("influxdb_org_dashboards_total").
+	Family("influxdb_org_tasks_total").
 	Family("task_scheduler_claims_active"). // Count of currently active tasks
+	/*
+	 * Template usage, anonymous by the hash of the template name and version
+	 */
+	Family("influxdb_templates_applied_total").
+	Family("influxdb_template_resources_applied_total").
 	/*
 	 * Count of API requests including success and failure
 	 */
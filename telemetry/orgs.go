@@ -0,0 +1,69 @@
+package telemetry
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/influxdata/influxdb/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	// orgIDLabel labels the resource counts of each org with the ID of the org.
+	orgIDLabel = "org_id"
+	// orgLabel replaces orgIDLabel in the telemetry with the ordinal of the org.
+	orgLabel = "org"
+)
+
+var _ prometheus.Transformer = (*AnonymizeOrgs)(nil)
+
+// AnonymizeOrgs replaces the org ID label of metrics with the ordinal of the
+// org, so the counts of each org are reported without the ID of the org. The
+// ordinals follow the order of the org IDs, an org has the same ordinal in
+// every family of a single report.
+type AnonymizeOrgs struct{}
+
+// Transform replaces the org IDs of the metrics with ordinals.
+func (AnonymizeOrgs) Transform(mfs []*dto.MetricFamily) []*dto.MetricFamily {
+	var ids []string
+	ordinals := make(map[string]string)
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			for _, l := range m.Label {
+				if l.GetName() != orgIDLabel {
+					continue
+				}
+				if _, ok := ordinals[l.GetValue()]; !ok {
+					ordinals[l.GetValue()] = ""
+					ids = append(ids, l.GetValue())
+				}
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return mfs
+	}
+
+	sort.Strings(ids)
+	for i, id := range ids {
+		ordinals[id] = strconv.Itoa(i + 1)
+	}
+
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			var anonymized bool
+			for j, l := range m.Label {
+				if l.GetName() == orgIDLabel {
+					m.Label[j] = prometheus.L(orgLabel, ordinals[l.GetValue()])
+					anonymized = true
+				}
+			}
+			if anonymized {
+				sort.Slice(m.Label, func(i, j int) bool {
+					return m.Label[i].GetName() < m.Label[j].GetName()
+				})
+			}
+		}
+	}
+	return mfs
+}
@@ -0,0 +1,53 @@
+package telemetry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	pr "github.com/influxdata/influxdb/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func orgGauge(name string, metrics ...*dto.Metric) *dto.MetricFamily {
+	return &dto.MetricFamily{
+		Name:   proto.String(name),
+		Type:   dto.MetricType_GAUGE.Enum(),
+		Metric: metrics,
+	}
+}
+
+func orgCount(label, org string, n float64) *dto.Metric {
+	return &dto.Metric{
+		Label: []*dto.LabelPair{pr.L(label, org)},
+		Gauge: &dto.Gauge{Value: proto.Float64(n)},
+	}
+}
+
+func TestAnonymizeOrgs(t *testing.T) {
+	mfs := []*dto.MetricFamily{
+		orgGauge("influxdb_org_buckets_total",
+			orgCount("org_id", "000000000000000a", 3),
+			orgCount("org_id", "0000000000000002", 1),
+		),
+		orgGauge("influxdb_org_tasks_total",
+			orgCount("org_id", "000000000000000a", 5),
+		),
+		goodMetric(),
+	}
+
+	got := AnonymizeOrgs{}.Transform(mfs)
+	want := []*dto.MetricFamily{
+		orgGauge("influxdb_org_buckets_total",
+			orgCount("org", "2", 3),
+			orgCount("org", "1", 1),
+		),
+		orgGauge("influxdb_org_tasks_total",
+			orgCount("org", "2", 5),
+		),
+		goodMetric(),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AnonymizeOrgs.Transform() = %v, want %v", got, want)
+	}
+}
@@ -11,9 +11,13 @@ import (
 
 	pr "github.com/influxdata/influxdb/prometheus"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
 )
 
+// DefaultURL is the push gateway usage metrics are sent to by default.
+const DefaultURL = "https://telemetry.influxdata.com/metrics/job/influxdb"
+
 // Pusher pushes metrics to a prometheus push gateway.
 type Pusher struct {
 	URL        string
@@ -25,10 +29,13 @@ type Pusher struct {
 // NewPusher sends usage metrics to a prometheus push gateway.
 func NewPusher(g prometheus.Gatherer) *Pusher {
 	return &Pusher{
-		URL: "https://telemetry.influxdata.com/metrics/job/influxdb",
-		Gather: &pr.Filter{
-			Gatherer: g,
-			Matcher:  telemetryMatcher,
+		URL: DefaultURL,
+		Gather: &transformGatherer{
+			Gatherer: &pr.Filter{
+				Gatherer: g,
+				Matcher:  telemetryMatcher,
+			},
+			Transformer: AnonymizeOrgs{},
 		},
 		Client: &http.Client{
 			Transport: http.DefaultTransport,
@@ -38,6 +45,21 @@ func NewPusher(g prometheus.Gatherer) *Pusher {
 	}
 }
 
+// transformGatherer transforms the metrics gathered before they are pushed.
+type transformGatherer struct {
+	prometheus.Gatherer
+	pr.Transformer
+}
+
+// Gather gathers the metrics and transforms them.
+func (g *transformGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+	return g.Transform(mfs), nil
+}
+
 // Push POSTs prometheus metrics in protobuf delimited format to a push gateway.
 func (p *Pusher) Push(ctx context.Context) error {
 	if p.PushFormat == "" {
@@ -0,0 +1,78 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/influxdata/influxdb/pkger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+var _ pkger.UsageReporter = (*TemplateUsage)(nil)
+
+// TemplateUsage counts the templates, pkgs, applied. The counts are reported
+// with the rest of the telemetry and are labeled only by the hash of the pkg
+// and the kind of the resources applied.
+type TemplateUsage struct {
+	applies   *prometheus.CounterVec
+	resources *prometheus.CounterVec
+}
+
+// NewTemplateUsage constructs a TemplateUsage. Its collectors must be registered
+// with the registry the Reporter gathers from.
+func NewTemplateUsage() *TemplateUsage {
+	const namespace = "influxdb"
+
+	return &TemplateUsage{
+		applies: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "templates_applied_total",
+			Help:      "Number of times a template was applied by the hash of the template name and version.",
+		}, []string{"template"}),
+		resources: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "template_resources_applied_total",
+			Help:      "Number of resources applied from templates by the hash of the template name and version and the kind of resource.",
+		}, []string{"template", "kind"}),
+	}
+}
+
+// ReportApply counts the pkg applied.
+func (u *TemplateUsage) ReportApply(ctx context.Context, event pkger.ApplyEvent) {
+	u.applies.WithLabelValues(event.PkgHash).Inc()
+	for kind, n := range event.Resources {
+		if n == 0 {
+			continue
+		}
+		u.resources.WithLabelValues(event.PkgHash, string(kind)).Add(float64(n))
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (u *TemplateUsage) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		u.applies,
+		u.resources,
+	}
+}
+
+// DebugHandler serves the telemetry the next report pushes, in the text format.
+func DebugHandler(g prometheus.Gatherer) http.Handler {
+	pusher := NewPusher(g)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := pusher.encode()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", string(expfmt.FmtText))
+		w.WriteHeader(http.StatusOK)
+		if body == nil {
+			return
+		}
+		_, _ = io.Copy(w, body)
+	})
+}
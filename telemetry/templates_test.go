@@ -0,0 +1,68 @@
+package telemetry
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestTemplateUsage(t *testing.T) {
+	debug := func(t *testing.T, g prometheus.Gatherer) string {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		DebugHandler(g).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/telemetry", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: %d", w.Code)
+		}
+		body, err := ioutil.ReadAll(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	t.Run("pending events are reported", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		usage := NewTemplateUsage()
+		reg.MustRegister(usage.PrometheusCollectors()...)
+
+		event := pkger.ApplyEvent{
+			PkgHash: "abc123",
+			Resources: map[pkger.Kind]int{
+				pkger.KindBucket: 2,
+				pkger.KindLabel:  0,
+			},
+		}
+		usage.ReportApply(context.Background(), event)
+		usage.ReportApply(context.Background(), event)
+
+		body := debug(t, reg)
+		for _, want := range []string{
+			`influxdb_templates_applied_total{template="abc123"} 2`,
+			`influxdb_template_resources_applied_total{kind="bucket",template="abc123"} 4`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected %q in telemetry:\n%s", want, body)
+			}
+		}
+		if strings.Contains(body, `kind="label"`) {
+			t.Errorf("unexpected kinds without resources in telemetry:\n%s", body)
+		}
+	})
+
+	t.Run("nothing is reported without events", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(NewTemplateUsage().PrometheusCollectors()...)
+
+		if body := debug(t, reg); body != "" {
+			t.Errorf("unexpected telemetry:\n%s", body)
+		}
+	})
+}
@@ -1152,6 +1152,8 @@ func UpdateBucket(
 		id          influxdb.ID
 		retention   int
 		description *string
+
+		measurementRetention *[]influxdb.MeasurementRetentionPolicy
 	}
 	type wants struct {
 		err    error
@@ -1424,6 +1426,44 @@ func UpdateBucket(
 				},
 			},
 		},
+		{
+			name: "update measurement retention policies",
+			fields: BucketFields{
+				TimeGenerator: mock.TimeGenerator{FakeValue: time.Date(2006, 5, 4, 1, 2, 3, 0, time.UTC)},
+				Organizations: []*influxdb.Organization{
+					{
+						Name: "theorg",
+						ID:   MustIDBase16(orgOneID),
+					},
+				},
+				Buckets: []*influxdb.Bucket{
+					{
+						ID:    MustIDBase16(bucketOneID),
+						OrgID: MustIDBase16(orgOneID),
+						Name:  "bucket1",
+					},
+				},
+			},
+			args: args{
+				id: MustIDBase16(bucketOneID),
+				measurementRetention: &[]influxdb.MeasurementRetentionPolicy{
+					{Measurement: "cpu", RetentionPeriod: 7 * 24 * time.Hour},
+				},
+			},
+			wants: wants{
+				bucket: &influxdb.Bucket{
+					ID:    MustIDBase16(bucketOneID),
+					OrgID: MustIDBase16(orgOneID),
+					Name:  "bucket1",
+					MeasurementRetentionPolicies: []influxdb.MeasurementRetentionPolicy{
+						{Measurement: "cpu", RetentionPeriod: 7 * 24 * time.Hour},
+					},
+					CRUDLog: influxdb.CRUDLog{
+						UpdatedAt: time.Date(2006, 5, 4, 1, 2, 3, 0, time.UTC),
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1442,6 +1482,7 @@ func UpdateBucket(
 			}
 
 			upd.Description = tt.args.description
+			upd.MeasurementRetentionPolicies = tt.args.measurementRetention
 
 			bucket, err := s.UpdateBucket(ctx, tt.args.id, upd)
 			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
@@ -1041,9 +1041,10 @@ func UpdateDashboard(
 	t *testing.T,
 ) {
 	type args struct {
-		name        string
-		description string
-		id          platform.ID
+		name            string
+		description     string
+		refreshInterval *platform.Duration
+		id              platform.ID
 	}
 	type wants struct {
 		err       error
@@ -1155,6 +1156,88 @@ func UpdateDashboard(
 				},
 			},
 		},
+		{
+			name: "update refresh interval",
+			fields: DashboardFields{
+				TimeGenerator: mock.TimeGenerator{FakeValue: time.Date(2009, time.November, 10, 24, 0, 0, 0, time.UTC)},
+				Dashboards: []*platform.Dashboard{
+					{
+						ID:             MustIDBase16(dashOneID),
+						OrganizationID: 1,
+						Name:           "dashboard1",
+					},
+				},
+			},
+			args: args{
+				id:              MustIDBase16(dashOneID),
+				refreshInterval: &platform.Duration{Duration: 10 * time.Second},
+			},
+			wants: wants{
+				dashboard: &platform.Dashboard{
+					ID:             MustIDBase16(dashOneID),
+					OrganizationID: 1,
+					Name:           "dashboard1",
+					Meta: platform.DashboardMeta{
+						UpdatedAt:       time.Date(2009, time.November, 10, 24, 0, 0, 0, time.UTC),
+						RefreshInterval: &platform.Duration{Duration: 10 * time.Second},
+					},
+				},
+			},
+		},
+		{
+			name: "update refresh interval to zero disables auto-refresh",
+			fields: DashboardFields{
+				TimeGenerator: mock.TimeGenerator{FakeValue: time.Date(2009, time.November, 10, 24, 0, 0, 0, time.UTC)},
+				Dashboards: []*platform.Dashboard{
+					{
+						ID:             MustIDBase16(dashOneID),
+						OrganizationID: 1,
+						Name:           "dashboard1",
+						Meta: platform.DashboardMeta{
+							RefreshInterval: &platform.Duration{Duration: time.Minute},
+						},
+					},
+				},
+			},
+			args: args{
+				id:              MustIDBase16(dashOneID),
+				refreshInterval: &platform.Duration{},
+			},
+			wants: wants{
+				dashboard: &platform.Dashboard{
+					ID:             MustIDBase16(dashOneID),
+					OrganizationID: 1,
+					Name:           "dashboard1",
+					Meta: platform.DashboardMeta{
+						UpdatedAt: time.Date(2009, time.November, 10, 24, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+		},
+		{
+			name: "update with refresh interval below minimum",
+			fields: DashboardFields{
+				TimeGenerator: mock.TimeGenerator{FakeValue: time.Date(2009, time.November, 10, 24, 0, 0, 0, time.UTC)},
+				Dashboards: []*platform.Dashboard{
+					{
+						ID:             MustIDBase16(dashOneID),
+						OrganizationID: 1,
+						Name:           "dashboard1",
+					},
+				},
+			},
+			args: args{
+				id:              MustIDBase16(dashOneID),
+				refreshInterval: &platform.Duration{Duration: time.Second},
+			},
+			wants: wants{
+				err: &platform.Error{
+					Code: platform.EInvalid,
+					Op:   platform.OpUpdateDashboard,
+					Msg:  "refresh interval must be at least 5s",
+				},
+			},
+		},
 		{
 			name: "update with id not exist",
 			fields: DashboardFields{
@@ -1200,6 +1283,7 @@ func UpdateDashboard(
 			if tt.args.description != "" {
 				upd.Description = &tt.args.description
 			}
+			upd.RefreshInterval = tt.args.refreshInterval
 
 			dashboard, err := s.UpdateDashboard(ctx, tt.args.id, upd)
 			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
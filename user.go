@@ -2,6 +2,7 @@ package influxdb
 
 import (
 	"context"
+	"time"
 )
 
 // UserStatus indicates whether a user is active or inactive
@@ -63,6 +64,17 @@ type UserService interface {
 	DeleteUser(ctx context.Context, id ID) error
 }
 
+// UserActivityService records the last time users were seen.
+type UserActivityService interface {
+	// FindUsersLastSeen returns the last time each of the users was seen. The
+	// users that were never seen are not in the map returned.
+	FindUsersLastSeen(ctx context.Context, ids []ID) (map[ID]time.Time, error)
+
+	// SetUsersLastSeen records the last time each of the users was seen. A time
+	// older than the one recorded for the user is ignored.
+	SetUsersLastSeen(ctx context.Context, seen map[ID]time.Time) error
+}
+
 // UserUpdate represents updates to a user.
 // Only fields which are set are updated.
 type UserUpdate struct {
@@ -1,15 +1,21 @@
 package influxdb
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"sort"
 )
 
 // ErrVariableNotFound is the error msg for a missing variable.
 const ErrVariableNotFound = "variable not found"
 
+// DefaultVariableMaxValues is the default maximum number of values of a
+// constant or map variable.
+const DefaultVariableMaxValues = 1000
+
 // ops for variable error.
 const (
 	OpFindVariableByID = "FindVariableByID"
@@ -160,6 +166,64 @@ func (u *VariableUpdate) Apply(m *Variable) error {
 	return nil
 }
 
+// ValidValues returns an error when the values of a constant or map variable
+// hold a duplicate or exceed maxValues entries. A maxValues of zero or less
+// does not limit the number of entries.
+func (a *VariableArguments) ValidValues(maxValues int) error {
+	var n int
+	switch values := a.Values.(type) {
+	case VariableConstantValues:
+		seen := make(map[string]bool, len(values))
+		for _, v := range values {
+			if seen[v] {
+				return &Error{
+					Code: EUnprocessableEntity,
+					Msg:  fmt.Sprintf("duplicate variable constant value %q", v),
+				}
+			}
+			seen[v] = true
+		}
+		n = len(values)
+	case VariableMapValues:
+		n = len(values)
+	default:
+		return nil
+	}
+
+	if maxValues > 0 && n > maxValues {
+		return &Error{
+			Code: EUnprocessableEntity,
+			Msg:  fmt.Sprintf("variable has %d values, exceeding the maximum of %d", n, maxValues),
+		}
+	}
+	return nil
+}
+
+// Normalize sorts the values of a constant variable and removes the duplicates.
+// The values of a map variable are ordered by key when encoded, a normalized
+// variable encodes the same regardless of the order its values were provided in.
+func (a *VariableArguments) Normalize() {
+	values, ok := a.Values.(VariableConstantValues)
+	if !ok {
+		return
+	}
+
+	normalized := make(VariableConstantValues, len(values))
+	copy(normalized, values)
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return normalized[i] < normalized[j]
+	})
+
+	deduped := normalized[:0]
+	for i, v := range normalized {
+		if i > 0 && v == normalized[i-1] {
+			continue
+		}
+		deduped = append(deduped, v)
+	}
+	a.Values = deduped
+}
+
 // UnmarshalJSON unmarshals json into a VariableArguments struct, using the `Type`
 // field to assign the approriate struct to the `Values` field
 func (a *VariableArguments) UnmarshalJSON(data []byte) error {
@@ -194,6 +258,20 @@ func (a *VariableArguments) UnmarshalJSON(data []byte) error {
 			return fmt.Errorf("error parsing %v as VariableMapArguments", aux.Values)
 		}
 
+		// a key provided more than once is lost in the decoded map
+		var raw struct {
+			Values json.RawMessage `json:"values"`
+		}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		if key, ok := duplicateObjectKey(raw.Values); ok {
+			return &Error{
+				Code: EUnprocessableEntity,
+				Msg:  fmt.Sprintf("duplicate variable map key %q", key),
+			}
+		}
+
 		variableValues := VariableMapValues{}
 		for k, v := range values {
 			if _, ok := v.(string); !ok {
@@ -236,3 +314,34 @@ func (a *VariableArguments) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// duplicateObjectKey returns the first key provided more than once in the JSON
+// object.
+func duplicateObjectKey(data []byte) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return "", false
+	}
+
+	seen := make(map[string]bool)
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return "", false
+		}
+		key, ok := t.(string)
+		if !ok {
+			return "", false
+		}
+		if seen[key] {
+			return key, true
+		}
+		seen[key] = true
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return "", false
+		}
+	}
+	return "", false
+}
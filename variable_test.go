@@ -140,3 +140,99 @@ func TestVariable_UnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestVariableArguments_UnmarshalJSON_DuplicateMapKey(t *testing.T) {
+	var args platform.VariableArguments
+	err := json.Unmarshal([]byte(`{"type": "map", "values": {"a": "1", "b": "2", "a": "3"}}`), &args)
+	if err == nil {
+		t.Fatal("expected error for duplicate map key")
+	}
+	if code := platform.ErrorCode(err); code != platform.EUnprocessableEntity {
+		t.Errorf("unexpected error code: got=%q want=%q", code, platform.EUnprocessableEntity)
+	}
+}
+
+func TestVariableArguments_ValidValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      platform.VariableArguments
+		maxValues int
+		wantErr   bool
+	}{
+		{
+			name: "valid constant values",
+			args: platform.VariableArguments{
+				Type:   "constant",
+				Values: platform.VariableConstantValues{"b", "a"},
+			},
+			maxValues: 2,
+		},
+		{
+			name: "duplicate constant values",
+			args: platform.VariableArguments{
+				Type:   "constant",
+				Values: platform.VariableConstantValues{"a", "b", "a"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many constant values",
+			args: platform.VariableArguments{
+				Type:   "constant",
+				Values: platform.VariableConstantValues{"a", "b", "c"},
+			},
+			maxValues: 2,
+			wantErr:   true,
+		},
+		{
+			name: "too many map values",
+			args: platform.VariableArguments{
+				Type:   "map",
+				Values: platform.VariableMapValues{"a": "1", "b": "2"},
+			},
+			maxValues: 1,
+			wantErr:   true,
+		},
+		{
+			name: "query values are not limited",
+			args: platform.VariableArguments{
+				Type:   "query",
+				Values: platform.VariableQueryValues{Query: "howdy", Language: "flux"},
+			},
+			maxValues: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.args.ValidValues(tt.maxValues)
+			if tt.wantErr {
+				if code := platform.ErrorCode(err); code != platform.EUnprocessableEntity {
+					t.Errorf("unexpected error code: got=%q want=%q", code, platform.EUnprocessableEntity)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestVariableArguments_Normalize(t *testing.T) {
+	values := platform.VariableConstantValues{"c", "a", "b", "a"}
+	args := platform.VariableArguments{
+		Type:   "constant",
+		Values: values,
+	}
+
+	args.Normalize()
+
+	want := platform.VariableConstantValues{"a", "b", "c"}
+	if !reflect.DeepEqual(args.Values, want) {
+		t.Errorf("got = %v, want %v", args.Values, want)
+	}
+	if !reflect.DeepEqual(values, platform.VariableConstantValues{"c", "a", "b", "a"}) {
+		t.Errorf("original values were modified: %v", values)
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"time"
 
 	platform "github.com/influxdata/influxdb"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/log"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -31,12 +33,34 @@ const (
 type Tracer struct {
 	log         *zap.Logger
 	idGenerator platform.IDGenerator
+	sampleRate  float64
 }
 
+// NewTracer returns a tracer logging every span.
 func NewTracer(log *zap.Logger, idGenerator platform.IDGenerator) *Tracer {
+	return NewSampledTracer(log, idGenerator, 1)
+}
+
+// NewSampledTracer returns a tracer logging the spans of the sampleRate
+// fraction of the traces. The traces are sampled at their root span, the
+// spans of a trace extracted from a carrier are always logged.
+func NewSampledTracer(log *zap.Logger, idGenerator platform.IDGenerator, sampleRate float64) *Tracer {
 	return &Tracer{
 		log:         log,
 		idGenerator: idGenerator,
+		sampleRate:  sampleRate,
+	}
+}
+
+// sample returns whether a new trace is sampled.
+func (t *Tracer) sample() bool {
+	switch {
+	case t.sampleRate >= 1:
+		return true
+	case t.sampleRate <= 0:
+		return false
+	default:
+		return rand.Float64() < t.sampleRate
 	}
 }
 
@@ -53,11 +77,13 @@ func (t *Tracer) StartSpan(operationName string, opts ...opentracing.StartSpanOp
 		refCtx, ok := ref.ReferencedContext.(SpanContext)
 		if ok {
 			ctx.traceID = refCtx.traceID
+			ctx.sampled = refCtx.sampled
 			break
 		}
 	}
 	if !ctx.traceID.Valid() {
 		ctx.traceID = t.idGenerator.ID()
+		ctx.sampled = t.sample()
 	}
 	return &Span{
 		tracer: t,
@@ -128,6 +154,8 @@ func (t *Tracer) Extract(format interface{}, carrier interface{}) (opentracing.S
 	if !ctx.spanID.Valid() {
 		return nil, errors.New("no span ID found in carrier")
 	}
+	// the trace was started by the caller, it is not broken mid-chain.
+	ctx.sampled = true
 	return ctx, err
 }
 
@@ -166,6 +194,9 @@ func (s *Span) Finish() {
 }
 
 func (s *Span) FinishWithOptions(opts opentracing.FinishOptions) {
+	if !s.ctx.sampled {
+		return
+	}
 	if opts.FinishTime.IsZero() {
 		opts.FinishTime = time.Now()
 	}
@@ -208,6 +239,11 @@ func (s *Span) SetOperationName(operationName string) opentracing.Span {
 }
 
 func (s *Span) SetTag(key string, value interface{}) opentracing.Span {
+	if key == string(ext.SamplingPriority) {
+		if v, ok := value.(uint16); ok {
+			s.ctx.sampled = v > 0
+		}
+	}
 	s.tags[key] = value
 	return s
 }
@@ -268,6 +304,7 @@ type SpanContext struct {
 	traceID platform.ID
 	spanID  platform.ID
 	baggage map[string]string
+	sampled bool
 }
 
 func newSpanContext() SpanContext {